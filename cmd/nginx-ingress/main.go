@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
@@ -21,6 +22,7 @@ import (
 	"github.com/nginxinc/kubernetes-ingress/internal/metrics"
 	"github.com/nginxinc/kubernetes-ingress/internal/metrics/collectors"
 	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
 	cr_validation "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/validation"
 	k8s_nginx "github.com/nginxinc/kubernetes-ingress/pkg/client/clientset/versioned"
 	conf_scheme "github.com/nginxinc/kubernetes-ingress/pkg/client/clientset/versioned/scheme"
@@ -29,6 +31,7 @@ import (
 	api_v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -36,6 +39,15 @@ import (
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
+// openTelemetryModulePath is the path of the OpenTelemetry module in NGINX builds that include the module.
+const openTelemetryModulePath = "/etc/nginx/modules/ngx_otel_module.so"
+
+// brotliModulePath is the path of the brotli filter module in NGINX builds that include the module.
+const brotliModulePath = "/etc/nginx/modules/ngx_http_brotli_filter_module.so"
+
+// njsModulePath is the path of the njs module in NGINX Plus builds that include the module.
+const njsModulePath = "/etc/nginx/modules/ngx_http_js_module.so"
+
 var (
 	// Set during build
 	version   string
@@ -52,16 +64,48 @@ var (
 		`Use a proxy server to connect to Kubernetes API started by "kubectl proxy" command. For testing purposes only.
 	The Ingress controller does not start NGINX and does not write any generated NGINX configuration files to disk`)
 
+	kubeAPIQPS = flag.Float64("kube-api-qps", float64(rest.DefaultQPS),
+		"The maximum number of queries per second of the Kubernetes API client. The value must be positive")
+
+	kubeAPIBurst = flag.Int("kube-api-burst", rest.DefaultBurst,
+		"The maximum burst of queries above kube-api-qps of the Kubernetes API client. The value must be positive")
+
+	kubeAPITimeout = flag.Duration("kube-api-timeout", 0,
+		`The timeout of the requests of the Kubernetes API client, including the watches of the informers, which are restarted
+	when they time out. For example, 30s. The value must not be negative. By default, the requests have no timeout`)
+
 	watchNamespace = flag.String("watch-namespace", api_v1.NamespaceAll,
-		`Namespace to watch for Ingress resources. By default the Ingress controller watches all namespaces`)
+		`Comma-separated list of namespaces to watch for Ingress resources. By default the Ingress controller watches all namespaces`)
 
 	nginxConfigMaps = flag.String("nginx-configmaps", "",
 		`A ConfigMap resource for customizing NGINX configuration. If a ConfigMap is set,
 	but the Ingress controller is not able to fetch it from Kubernetes API, the Ingress controller will fail to start.
 	Format: <namespace>/<name>`)
 
+	namespaceConfigMapName = flag.String("namespace-configmap-name", "",
+		`The name of the per-namespace ConfigMap resources that override the proxy timeouts and the client max body size
+	of the ConfigMap for all Ingress resources of their namespaces. The annotations of an Ingress resource take precedence over
+	the per-namespace ConfigMap. By default, per-namespace ConfigMaps are not used`)
+
 	nginxPlus = flag.Bool("nginx-plus", false, "Enable support for NGINX Plus")
 
+	syncWorkers = flag.Int("sync-workers", 1,
+		`The number of workers that process the changes of resources concurrently. The same resource is never processed
+	by two workers at the same time, including the changes of the resources that affect its configuration, and the generation of the NGINX configuration and the reloads of NGINX stay serialized`)
+
+	ingressDeleteGracePeriod = flag.Duration("ingress-delete-grace-period", 0,
+		`The period during which NGINX keeps serving the configuration of a deleted Ingress resource before removing it,
+	so that in-flight and long-lived connections can complete. For example, 30s. By default, the configuration is removed immediately`)
+
+	readyStatus = flag.Bool("ready-status", true, "Enables the readiness endpoint '/nginx-ready'. The endpoint returns a success code when the Ingress Controller has started and a failure code once it starts shutting down")
+
+	readyStatusPort = flag.Int("ready-status-port", 8081, "Set the port where the readiness endpoint is exposed. [1023 - 65535]")
+
+	shutdownDelay = flag.Duration("shutdown-delay", 0,
+		`The time the Ingress Controller waits after receiving SIGTERM before it stops processing resources and shuts down NGINX.
+	During the delay, the readiness endpoint reports the Ingress Controller as not ready, so that the load balancers can stop
+	sending traffic to it. For example, 15s. By default, the Ingress Controller shuts down immediately`)
+
 	ingressClass = flag.String("ingress-class", "nginx",
 		`A class of the Ingress controller. The Ingress controller only processes Ingress resources that belong to its class
 	- i.e. have the annotation "kubernetes.io/ingress.class" or the "ingressClassName" field in VirtualServer/VirtualServerRoute equal to the class. Additionally,
@@ -72,10 +116,15 @@ var (
 		`Ignore Ingress resources without the "kubernetes.io/ingress.class" annotation or the "ingressClassName" field in VirtualServer/VirtualServerRoute`)
 
 	defaultServerSecret = flag.String("default-server-tls-secret", "",
-		`A Secret with a TLS certificate and key for TLS termination of the default server. Format: <namespace>/<name>.
+		`A Secret of the type kubernetes.io/tls with a TLS certificate and key for TLS termination of the default server. Format: <namespace>/<name>.
+	Updates of the secret are applied, so the certificate can be rotated.
 	If not set, certificate and key in the file "/etc/nginx/secrets/default" are used. If a secret is set,
 	but the Ingress controller is not able to fetch it from Kubernetes API or a secret is not set and
-	the file "/etc/nginx/secrets/default" does not exist, the Ingress controller will fail to start`)
+	the file "/etc/nginx/secrets/default" does not exist and -generate-default-server-tls-cert is disabled, the Ingress controller will fail to start`)
+
+	generateDefaultServerTLSCert = flag.Bool("generate-default-server-tls-cert", true,
+		`Generate a self-signed TLS certificate and key for the default server if -default-server-tls-secret is not set
+	and the file "/etc/nginx/secrets/default" does not exist. The certificate is regenerated before it expires.`)
 
 	versionFlag = flag.Bool("version", false, "Print the version and git-commit hash and exit")
 
@@ -118,28 +167,90 @@ var (
 	nginxDebug = flag.Bool("nginx-debug", false,
 		"Enable debugging for NGINX. Uses the nginx-debug binary. Requires 'error-log-level: debug' in the ConfigMap.")
 
+	verifyConfigBeforeReload = flag.Bool("verify-config-before-reload", true,
+		`Test the NGINX configuration with nginx -t before every reload. If the test fails, NGINX is not reloaded and keeps running with the last valid configuration.`)
+
 	wildcardTLSSecret = flag.String("wildcard-tls-secret", "",
 		`A Secret with a TLS certificate and key for TLS termination of every Ingress host for which TLS termination is enabled but the Secret is not specified.
 		Format: <namespace>/<name>. If the argument is not set, for such Ingress hosts NGINX will break any attempt to establish a TLS connection.
 		If the argument is set, but the Ingress controller is not able to fetch the Secret from Kubernetes API, the Ingress controller will fail to start.`)
 
+	secretsNamespace = flag.String("secrets-namespace", "",
+		`A namespace with Secrets that VirtualServer and Policy resources of other namespaces can reference in the <namespace>/<name> format.
+	The Secrets in the namespace are watched even if the namespace is not in -watch-namespace.
+	If the argument is not set, resources can only reference the Secrets of their own namespace`)
+
+	missingTLSSecretPolicy = flag.String("missing-tls-secret-policy", configs.MissingTLSSecretPolicyIgnore,
+		`Specifies how to handle VirtualServers that reference a TLS Secret that doesn't exist or is invalid. Possible values:
+		'error' - reject the VirtualServer; 'ignore' - configure the VirtualServer, but NGINX will break any attempt to establish a TLS connection;
+		'self-signed' - configure the VirtualServer with a temporary self-signed certificate.`)
+
 	enablePrometheusMetrics = flag.Bool("enable-prometheus-metrics", false,
 		"Enable exposing NGINX or NGINX Plus metrics in the Prometheus format")
 
 	prometheusMetricsListenPort = flag.Int("prometheus-metrics-listen-port", 9113,
 		"Set the port where the Prometheus metrics are exposed. [1023 - 65535]")
 
+	enableResyncEndpoint = flag.Bool("enable-resync-endpoint", false,
+		`Enable the endpoint that triggers a full resync of all resources without restarting the Ingress Controller.
+	A POST request to the /resync path must include the token from -resync-endpoint-token-file as a Bearer token in the Authorization header`)
+
+	resyncEndpointListenPort = flag.Int("resync-endpoint-listen-port", 8082,
+		"Set the port where the resync endpoint is exposed. Requires -enable-resync-endpoint. [1023 - 65535]")
+
+	resyncEndpointTokenFile = flag.String("resync-endpoint-token-file", "",
+		"A path to a file with the token that authenticates the requests to the resync endpoint. Requires -enable-resync-endpoint")
+
+	enableCachePurgeEndpoint = flag.Bool("enable-cache-purge-endpoint", false,
+		`Enable the endpoint that purges the cache entries of the VirtualServer upstreams with caching. Requires -nginx-plus.
+	A POST request to the /purge-cache path with the zone and the key query parameters must include the token from -cache-purge-endpoint-token-file as a Bearer token in the Authorization header`)
+
+	cachePurgeEndpointListenPort = flag.Int("cache-purge-endpoint-listen-port", 8083,
+		"Set the port where the cache purge endpoint is exposed. Requires -enable-cache-purge-endpoint. [1023 - 65535]")
+
+	cachePurgeEndpointTokenFile = flag.String("cache-purge-endpoint-token-file", "",
+		"A path to a file with the token that authenticates the requests to the cache purge endpoint. Requires -enable-cache-purge-endpoint")
+
 	enableCustomResources = flag.Bool("enable-custom-resources", true,
 		"Enable custom resources")
 
 	globalConfiguration = flag.String("global-configuration", "",
 		`A GlobalConfiguration resource for global configuration of the Ingress Controller. Requires -enable-custom-resources. If the flag is set,
 		but the Ingress controller is not able to fetch the corresponding resource from Kubernetes API, the Ingress Controller 
-		will fail to start. Format: <namespace>/<name>`)
+		will fail to start. Format: <namespace>/<name>. A comma-separated list of several resources, for example, one per team, merges
+		their listeners in the order of the list: a listener that has the same name or uses the same port and protocol as a listener
+		of a preceding resource is ignored`)
 
 	enableTLSPassthrough = flag.Bool("enable-tls-passthrough", false,
 		"Enable TLS Passthrough on port 443. Requires -enable-custom-resources")
 
+	enableOpenTelemetry = flag.Bool("enable-opentelemetry", false,
+		"Enable the OpenTelemetry module. Requires an NGINX build that includes the OpenTelemetry module (ngx_otel_module)")
+
+	enableBrotli = flag.Bool("enable-brotli", false,
+		"Enable the brotli module for the compression of VirtualServer responses. Requires an NGINX build that includes the brotli filter module (ngx_http_brotli_filter_module)")
+
+	enableOIDC = flag.Bool("enable-oidc", false,
+		"Enable OpenID Connect authentication for VirtualServer resources. Requires -nginx-plus, -enable-custom-resources and an NGINX Plus build that includes the njs module (ngx_http_js_module)")
+
+	dynamicSSLCertificatesDirectory = flag.String("dynamic-ssl-certificates-directory", "",
+		`The directory of the TLS certificates of the VirtualServer resources with dynamic certificates, which NGINX loads by SNI during the TLS handshake instead of loading all certificates on every reload. The directory must contain a file <server name>.pem with the certificate and the key for every host, for example, mounted from a secret. Requires -nginx-plus, -enable-custom-resources and an NGINX Plus build that includes the njs module (ngx_http_js_module)`)
+
+	maxVirtualServersPerNamespace = flag.Int("max-virtual-servers-per-namespace", 0,
+		"The maximum number of VirtualServer resources of a namespace. The VirtualServers created after the limit is reached are rejected. For use in multi-tenant clusters. 0 means no limit")
+
+	allowSnippets = flag.Bool("allow-snippets", true,
+		"Allow the snippets of Ingress, VirtualServer and VirtualServerRoute resources, which insert NGINX configuration as is. If disabled, the resources with snippets are rejected. Disabling is recommended for multi-tenant clusters")
+
+	enableCertManager = flag.Bool("enable-cert-manager", false,
+		"Enable the support for cert-manager Certificate resources, which VirtualServer resources can reference in the certificate field of the TLS. Requires -enable-custom-resources and cert-manager installed in the cluster")
+
+	enableConfigSourceComments = flag.Bool("enable-config-source-comments", false,
+		"Enable the comments that mark the server and location blocks of the generated NGINX config with the Ingress, VirtualServer or VirtualServerRoute resources and the paths that produced them")
+
+	enableStructuredEventLog = flag.Bool("enable-structured-event-log", false,
+		"Enable the structured event log: the Ingress Controller writes its decisions about the resources, like the rejections and the configuration updates, as JSON lines to stdout in addition to Kubernetes Events")
+
 	spireAgentAddress = flag.String("spire-agent-address", "",
 		`Specifies the address of the running Spire agent. For use with NGINX Service Mesh only. If the flag is set,
 			but the Ingress Controller is not able to connect with the Spire Agent, the Ingress Controller will fail to start.`)
@@ -168,6 +279,41 @@ func main() {
 		glog.Fatalf("Invalid value for leader-election-lock-name: %v", statusLockNameValidationError)
 	}
 
+	if *namespaceConfigMapName != "" {
+		namespaceConfigMapNameValidationError := validateResourceName(*namespaceConfigMapName)
+		if namespaceConfigMapNameValidationError != nil {
+			glog.Fatalf("Invalid value for namespace-configmap-name: %v", namespaceConfigMapNameValidationError)
+		}
+	}
+
+	watchNamespaces, err := parseWatchNamespaces(*watchNamespace)
+	if err != nil {
+		glog.Fatalf("Invalid value for watch-namespace: %v", err)
+	}
+
+	if *secretsNamespace != "" {
+		if errs := validation.IsDNS1123Label(*secretsNamespace); len(errs) > 0 {
+			glog.Fatalf("Invalid value for secrets-namespace: %v", errs)
+		}
+	}
+
+	if *syncWorkers < 1 {
+		glog.Fatalf("Invalid value for sync-workers: %v. It must be a positive number", *syncWorkers)
+	}
+
+	if *ingressDeleteGracePeriod < 0 {
+		glog.Fatalf("Invalid value for ingress-delete-grace-period: %v. It must not be negative", *ingressDeleteGracePeriod)
+	}
+
+	if *shutdownDelay < 0 {
+		glog.Fatalf("Invalid value for shutdown-delay: %v. It must not be negative", *shutdownDelay)
+	}
+
+	readyStatusPortValidationError := validatePort(*readyStatusPort)
+	if readyStatusPortValidationError != nil {
+		glog.Fatalf("Invalid value for ready-status-port: %v", readyStatusPortValidationError)
+	}
+
 	statusPortValidationError := validatePort(*nginxStatusPort)
 	if statusPortValidationError != nil {
 		glog.Fatalf("Invalid value for nginx-status-port: %v", statusPortValidationError)
@@ -178,6 +324,40 @@ func main() {
 		glog.Fatalf("Invalid value for prometheus-metrics-listen-port: %v", metricsPortValidationError)
 	}
 
+	resyncPortValidationError := validatePort(*resyncEndpointListenPort)
+	if resyncPortValidationError != nil {
+		glog.Fatalf("Invalid value for resync-endpoint-listen-port: %v", resyncPortValidationError)
+	}
+
+	var resyncEndpointToken string
+	if *enableResyncEndpoint {
+		resyncEndpointToken, err = readEndpointToken(*resyncEndpointTokenFile)
+		if err != nil {
+			glog.Fatalf("Invalid value for resync-endpoint-token-file: %v", err)
+		}
+	}
+
+	cachePurgePortValidationError := validatePort(*cachePurgeEndpointListenPort)
+	if cachePurgePortValidationError != nil {
+		glog.Fatalf("Invalid value for cache-purge-endpoint-listen-port: %v", cachePurgePortValidationError)
+	}
+
+	var cachePurgeEndpointToken string
+	if *enableCachePurgeEndpoint {
+		if !*nginxPlus {
+			glog.Fatalf("enable-cache-purge-endpoint flag requires -nginx-plus")
+		}
+		cachePurgeEndpointToken, err = readEndpointToken(*cachePurgeEndpointTokenFile)
+		if err != nil {
+			glog.Fatalf("Invalid value for cache-purge-endpoint-token-file: %v", err)
+		}
+	}
+
+	missingTLSSecretPolicyValidationError := validateMissingTLSSecretPolicy(*missingTLSSecretPolicy)
+	if missingTLSSecretPolicyValidationError != nil {
+		glog.Fatalf("Invalid value for missing-tls-secret-policy: %v", missingTLSSecretPolicyValidationError)
+	}
+
 	allowedCIDRs, err := parseNginxStatusAllowCIDRs(*nginxStatusAllowCIDRs)
 	if err != nil {
 		glog.Fatalf(`Invalid value for nginx-status-allow-cidrs: %v`, err)
@@ -187,6 +367,31 @@ func main() {
 		glog.Fatalf("enable-tls-passthrough flag requires -enable-custom-resources")
 	}
 
+	if *enableOIDC && (!*nginxPlus || !*enableCustomResources) {
+		glog.Fatalf("enable-oidc flag requires -nginx-plus and -enable-custom-resources")
+	}
+
+	if *dynamicSSLCertificatesDirectory != "" {
+		if !*nginxPlus || !*enableCustomResources {
+			glog.Fatalf("dynamic-ssl-certificates-directory flag requires -nginx-plus and -enable-custom-resources")
+		}
+		if err := validateDynamicSSLCertificatesDirectory(*dynamicSSLCertificatesDirectory); err != nil {
+			glog.Fatalf("Invalid value for dynamic-ssl-certificates-directory: %v", err)
+		}
+	}
+
+	if *maxVirtualServersPerNamespace < 0 {
+		glog.Fatalf("Invalid value for max-virtual-servers-per-namespace: %v. It must not be negative", *maxVirtualServersPerNamespace)
+	}
+
+	if *enableCertManager && !*enableCustomResources {
+		glog.Fatalf("enable-cert-manager flag requires -enable-custom-resources")
+	}
+
+	if err := validateKubeAPIClientParameters(*kubeAPIQPS, *kubeAPIBurst, *kubeAPITimeout); err != nil {
+		glog.Fatalf("Invalid value for the Kubernetes API client: %v", err)
+	}
+
 	glog.Infof("Starting NGINX Ingress controller Version=%v GitCommit=%v\n", version, gitCommit)
 
 	var config *rest.Config
@@ -206,6 +411,7 @@ func main() {
 			glog.Fatalf("error creating client configuration: %v", err)
 		}
 	}
+	configureKubeAPIClient(config, float32(*kubeAPIQPS), *kubeAPIBurst, *kubeAPITimeout)
 
 	kubeClient, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -226,6 +432,14 @@ func main() {
 		}
 	}
 
+	var dynClient dynamic.Interface
+	if *enableCertManager {
+		dynClient, err = dynamic.NewForConfig(config)
+		if err != nil {
+			glog.Fatalf("Failed to create a dynamic client: %v", err)
+		}
+	}
+
 	nginxConfTemplatePath := "nginx.tmpl"
 	nginxIngressTemplatePath := "nginx.ingress.tmpl"
 	nginxVirtualServerTemplatePath := "nginx.virtualserver.tmpl"
@@ -293,21 +507,63 @@ func main() {
 	if useFakeNginxManager {
 		nginxManager = nginx.NewFakeManager("/etc/nginx")
 	} else {
-		nginxManager = nginx.NewLocalManager("/etc/nginx/", nginxBinaryPath, managerCollector)
+		nginxManager = nginx.NewLocalManager("/etc/nginx/", nginxBinaryPath, managerCollector, *verifyConfigBeforeReload)
 	}
 
+	isDefaultServerSecretSelfSigned := false
 	if *defaultServerSecret != "" {
 		secret, err := getAndValidateSecret(kubeClient, *defaultServerSecret)
 		if err != nil {
 			glog.Fatalf("Error trying to get the default server TLS secret %v: %v", *defaultServerSecret, err)
 		}
+		err = k8s.ValidateDefaultServerTLSSecret(secret)
+		if err != nil {
+			glog.Fatalf("Error trying to get the default server TLS secret %v: %v", *defaultServerSecret, err)
+		}
 
 		bytes := configs.GenerateCertAndKeyFileContent(secret)
 		nginxManager.CreateSecret(configs.DefaultServerSecretName, bytes, nginx.TLSSecretFileMode)
 	} else {
 		_, err = os.Stat("/etc/nginx/secrets/default")
 		if os.IsNotExist(err) {
-			glog.Fatalf("A TLS cert and key for the default server is not found")
+			if !*generateDefaultServerTLSCert {
+				glog.Fatalf("A TLS cert and key for the default server is not found")
+			}
+
+			fileName, err := configs.CreateSelfSignedDefaultServerSecret(nginxManager)
+			if err != nil {
+				glog.Fatalf("Error generating a self-signed TLS cert and key for the default server: %v", err)
+			}
+			glog.Infof("A TLS cert and key for the default server is not found, using a generated self-signed certificate %v", fileName)
+			isDefaultServerSecretSelfSigned = true
+		}
+	}
+
+	if *enableOpenTelemetry {
+		_, err = os.Stat(openTelemetryModulePath)
+		if os.IsNotExist(err) {
+			glog.Fatalf("enable-opentelemetry flag requires an NGINX build with the OpenTelemetry module: %v is not found", openTelemetryModulePath)
+		}
+	}
+
+	if *enableBrotli {
+		_, err = os.Stat(brotliModulePath)
+		if os.IsNotExist(err) {
+			glog.Fatalf("enable-brotli flag requires an NGINX build with the brotli module: %v is not found", brotliModulePath)
+		}
+	}
+
+	if *enableOIDC {
+		_, err = os.Stat(njsModulePath)
+		if os.IsNotExist(err) {
+			glog.Fatalf("enable-oidc flag requires an NGINX Plus build with the njs module: %v is not found", njsModulePath)
+		}
+	}
+
+	if *dynamicSSLCertificatesDirectory != "" {
+		_, err = os.Stat(njsModulePath)
+		if os.IsNotExist(err) {
+			glog.Fatalf("dynamic-ssl-certificates-directory flag requires an NGINX Plus build with the njs module: %v is not found", njsModulePath)
 		}
 	}
 
@@ -329,7 +585,7 @@ func main() {
 	}
 
 	if *globalConfiguration != "" {
-		ns, name, err := k8s.ParseNamespaceName(*globalConfiguration)
+		keys, err := k8s.ParseGlobalConfigurations(*globalConfiguration)
 		if err != nil {
 			glog.Fatalf("Error parsing the global-configuration argument: %v", err)
 		}
@@ -338,17 +594,29 @@ func main() {
 			glog.Fatal("global-configuration flag requires -enable-custom-resources")
 		}
 
-		gc, err := confClient.K8sV1alpha1().GlobalConfigurations(ns).Get(context.TODO(), name, meta_v1.GetOptions{})
-		if err != nil {
-			glog.Fatalf("Error when getting %s: %v", *globalConfiguration, err)
+		var gcs []*conf_v1alpha1.GlobalConfiguration
+		for _, key := range keys {
+			ns, name, _ := k8s.ParseNamespaceName(key)
+
+			gc, err := confClient.K8sV1alpha1().GlobalConfigurations(ns).Get(context.TODO(), name, meta_v1.GetOptions{})
+			if err != nil {
+				glog.Fatalf("Error when getting %s: %v", key, err)
+			}
+
+			err = globalConfigurationValidator.ValidateGlobalConfiguration(gc)
+			if err != nil {
+				glog.Fatalf("GlobalConfiguration %s is invalid: %v", key, err)
+			}
+
+			gcs = append(gcs, gc)
 		}
 
-		err = globalConfigurationValidator.ValidateGlobalConfiguration(gc)
-		if err != nil {
-			glog.Fatalf("GlobalConfiguration %s is invalid: %v", *globalConfiguration, err)
+		mergedGC, conflicts := configs.MergeGlobalConfigurations(gcs)
+		for _, c := range conflicts {
+			glog.Warningf("Listener %v of GlobalConfiguration %v/%v was ignored: %v", c.Listener, c.GlobalConfiguration.Namespace, c.GlobalConfiguration.Name, c.Reason)
 		}
 
-		globalCfgParams = configs.ParseGlobalConfiguration(gc, *enableTLSPassthrough)
+		globalCfgParams = configs.ParseGlobalConfiguration(mergedGC, *enableTLSPassthrough)
 	}
 
 	cfgParams := configs.NewDefaultConfigParams()
@@ -362,6 +630,10 @@ func main() {
 			glog.Fatalf("Error when getting %v: %v", *nginxConfigMaps, err)
 		}
 		cfgParams = configs.ParseConfigMap(cfm, *nginxPlus)
+		if cfgParams.MainErrorLogLevel == "" {
+			// there is no last valid error-log-level at the start
+			cfgParams.MainErrorLogLevel = configs.NewDefaultConfigParams().MainErrorLogLevel
+		}
 		if cfgParams.MainServerSSLDHParamFileContent != nil {
 			fileName, err := nginxManager.CreateDHParam(*cfgParams.MainServerSSLDHParamFileContent)
 			if err != nil {
@@ -393,6 +665,12 @@ func main() {
 		StubStatusOverUnixSocketForOSS: *enablePrometheusMetrics,
 		TLSPassthrough:                 *enableTLSPassthrough,
 		SpiffeCerts:                    *spireAgentAddress != "",
+		MissingTLSSecretPolicy:         *missingTLSSecretPolicy,
+		EnableOpenTelemetry:            *enableOpenTelemetry,
+		EnableBrotli:                   *enableBrotli,
+		EnableOIDC:                     *enableOIDC,
+		ConfigSourceComments:           *enableConfigSourceComments,
+		DynamicSSLCertsDirectory:       *dynamicSSLCertificatesDirectory,
 	}
 
 	ngxConfig := configs.GenerateNginxMainConfig(staticCfgParams, cfgParams)
@@ -451,33 +729,56 @@ func main() {
 	transportServerValidator := cr_validation.NewTransportServerValidator(*enableTLSPassthrough)
 
 	lbcInput := k8s.NewLoadBalancerControllerInput{
-		KubeClient:                   kubeClient,
-		ConfClient:                   confClient,
-		ResyncPeriod:                 30 * time.Second,
-		Namespace:                    *watchNamespace,
-		NginxConfigurator:            cnf,
-		DefaultServerSecret:          *defaultServerSecret,
-		IsNginxPlus:                  *nginxPlus,
-		IngressClass:                 *ingressClass,
-		UseIngressClassOnly:          *useIngressClassOnly,
-		ExternalServiceName:          *externalService,
-		ControllerNamespace:          controllerNamespace,
-		ReportIngressStatus:          *reportIngressStatus,
-		IsLeaderElectionEnabled:      *leaderElectionEnabled,
-		LeaderElectionLockName:       *leaderElectionLockName,
-		WildcardTLSSecret:            *wildcardTLSSecret,
-		ConfigMaps:                   *nginxConfigMaps,
-		GlobalConfiguration:          *globalConfiguration,
-		AreCustomResourcesEnabled:    *enableCustomResources,
-		MetricsCollector:             controllerCollector,
-		GlobalConfigurationValidator: globalConfigurationValidator,
-		TransportServerValidator:     transportServerValidator,
-		SpireAgentAddress:            *spireAgentAddress,
+		KubeClient:                      kubeClient,
+		ConfClient:                      confClient,
+		DynamicClient:                   dynClient,
+		ResyncPeriod:                    30 * time.Second,
+		Namespaces:                      watchNamespaces,
+		NginxConfigurator:               cnf,
+		DefaultServerSecret:             *defaultServerSecret,
+		IsNginxPlus:                     *nginxPlus,
+		IngressClass:                    *ingressClass,
+		UseIngressClassOnly:             *useIngressClassOnly,
+		ExternalServiceName:             *externalService,
+		ControllerNamespace:             controllerNamespace,
+		ReportIngressStatus:             *reportIngressStatus,
+		IsLeaderElectionEnabled:         *leaderElectionEnabled,
+		LeaderElectionLockName:          *leaderElectionLockName,
+		WildcardTLSSecret:               *wildcardTLSSecret,
+		SecretsNamespace:                *secretsNamespace,
+		ConfigMaps:                      *nginxConfigMaps,
+		NamespaceConfigMapName:          *namespaceConfigMapName,
+		GlobalConfiguration:             *globalConfiguration,
+		AreCustomResourcesEnabled:       *enableCustomResources,
+		MetricsCollector:                controllerCollector,
+		GlobalConfigurationValidator:    globalConfigurationValidator,
+		TransportServerValidator:        transportServerValidator,
+		SpireAgentAddress:               *spireAgentAddress,
+		MissingTLSSecretPolicy:          *missingTLSSecretPolicy,
+		IsDefaultServerSecretSelfSigned: isDefaultServerSecretSelfSigned,
+		SyncWorkers:                     *syncWorkers,
+		IngressDeleteGracePeriod:        *ingressDeleteGracePeriod,
+		IsCertManagerEnabled:            *enableCertManager,
+		AllowSnippets:                   *allowSnippets,
+		MaxVirtualServersPerNamespace:   *maxVirtualServersPerNamespace,
+		IsStructuredEventLogEnabled:     *enableStructuredEventLog,
 	}
 
 	lbc := k8s.NewLoadBalancerController(lbcInput)
 
-	go handleTermination(lbc, nginxManager, nginxDone)
+	if *enableResyncEndpoint {
+		go k8s.RunResyncListener(*resyncEndpointListenPort, resyncEndpointToken, lbc)
+	}
+
+	if *enableCachePurgeEndpoint {
+		go k8s.RunCachePurgeListener(*cachePurgeEndpointListenPort, cachePurgeEndpointToken, lbc)
+	}
+
+	if *readyStatus {
+		go k8s.RunReadyStatusListener(*readyStatusPort, lbc)
+	}
+
+	go handleTermination(lbc, nginxManager, nginxDone, *shutdownDelay, *nginxPlus)
 	lbc.Run()
 
 	for {
@@ -487,22 +788,31 @@ func main() {
 }
 
 func createGlobalConfigurationValidator() *cr_validation.GlobalConfigurationValidator {
-	forbiddenListenerPorts := map[int]bool{
-		80:  true,
-		443: true,
+	forbiddenListenerPorts := map[int]string{
+		80:  "the HTTP listener",
+		443: "the HTTPS listener",
 	}
 
 	if *nginxStatus {
-		forbiddenListenerPorts[*nginxStatusPort] = true
+		forbiddenListenerPorts[*nginxStatusPort] = "the NGINX status listener"
 	}
 	if *enablePrometheusMetrics {
-		forbiddenListenerPorts[*prometheusMetricsListenPort] = true
+		forbiddenListenerPorts[*prometheusMetricsListenPort] = "the Prometheus metrics listener"
+	}
+	if *enableResyncEndpoint {
+		forbiddenListenerPorts[*resyncEndpointListenPort] = "the resync endpoint listener"
+	}
+	if *enableCachePurgeEndpoint {
+		forbiddenListenerPorts[*cachePurgeEndpointListenPort] = "the cache purge endpoint listener"
+	}
+	if *readyStatus {
+		forbiddenListenerPorts[*readyStatusPort] = "the readiness probe listener"
 	}
 
 	return cr_validation.NewGlobalConfigurationValidator(forbiddenListenerPorts)
 }
 
-func handleTermination(lbc *k8s.LoadBalancerController, nginxManager nginx.Manager, nginxDone chan error) {
+func handleTermination(lbc *k8s.LoadBalancerController, nginxManager nginx.Manager, nginxDone chan error, shutdownDelay time.Duration, isPlus bool) {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGTERM)
 
@@ -523,7 +833,19 @@ func handleTermination(lbc *k8s.LoadBalancerController, nginxManager nginx.Manag
 	}
 
 	glog.Infof("Shutting down the controller")
-	lbc.Stop()
+	if exited {
+		lbc.Stop()
+	} else {
+		lbc.ShutdownWithDelay(shutdownDelay)
+	}
+
+	if !exited && isPlus {
+		glog.Infof("Draining the servers of the upstreams")
+		err := nginxManager.DrainServersInPlus()
+		if err != nil {
+			glog.Warningf("Error draining the servers of the upstreams: %v", err)
+		}
+	}
 
 	if !exited {
 		glog.Infof("Shutting down NGINX")
@@ -555,6 +877,31 @@ func validateResourceName(lock string) error {
 	return nil
 }
 
+// parseWatchNamespaces parses a comma-separated list of namespaces to watch.
+// An empty list means all namespaces.
+func parseWatchNamespaces(value string) ([]string, error) {
+	if value == api_v1.NamespaceAll {
+		return []string{api_v1.NamespaceAll}, nil
+	}
+
+	var namespaces []string
+	seen := make(map[string]bool)
+
+	for _, ns := range strings.Split(value, ",") {
+		ns = strings.TrimSpace(ns)
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid namespace %q: %v", ns, errs)
+		}
+		if seen[ns] {
+			return nil, fmt.Errorf("duplicate namespace %q", ns)
+		}
+		seen[ns] = true
+		namespaces = append(namespaces, ns)
+	}
+
+	return namespaces, nil
+}
+
 // validatePort makes sure a given port is inside the valid port range for its usage
 func validatePort(port int) error {
 	if port < 1023 || port > 65535 {
@@ -563,6 +910,80 @@ func validatePort(port int) error {
 	return nil
 }
 
+// readEndpointToken reads the token of the resync or the cache purge endpoint from a file.
+// Leading and trailing whitespace is ignored, so that the file can end with a newline.
+func readEndpointToken(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("the argument is required when the endpoint is enabled")
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("the file %v doesn't include a token", path)
+	}
+
+	return token, nil
+}
+
+// validateKubeAPIClientParameters makes sure the rate limit and the timeout of the Kubernetes API client are valid.
+func validateKubeAPIClientParameters(qps float64, burst int, timeout time.Duration) error {
+	if qps <= 0 {
+		return fmt.Errorf("kube-api-qps must be positive, got %v", qps)
+	}
+	if burst <= 0 {
+		return fmt.Errorf("kube-api-burst must be positive, got %v", burst)
+	}
+	if timeout < 0 {
+		return fmt.Errorf("kube-api-timeout must not be negative, got %v", timeout)
+	}
+	return nil
+}
+
+// configureKubeAPIClient applies the rate limit and the timeout to the configuration of the Kubernetes API clients.
+func configureKubeAPIClient(config *rest.Config, qps float32, burst int, timeout time.Duration) {
+	config.QPS = qps
+	config.Burst = burst
+	config.Timeout = timeout
+}
+
+// validateMissingTLSSecretPolicy makes sure a given policy for missing TLS Secrets is supported.
+func validateMissingTLSSecretPolicy(policy string) error {
+	switch policy {
+	case configs.MissingTLSSecretPolicyError, configs.MissingTLSSecretPolicyIgnore, configs.MissingTLSSecretPolicySelfSigned:
+		return nil
+	}
+	return fmt.Errorf("unsupported policy %q, must be one of %q, %q or %q", policy,
+		configs.MissingTLSSecretPolicyError, configs.MissingTLSSecretPolicyIgnore, configs.MissingTLSSecretPolicySelfSigned)
+}
+
+const dynamicSSLCertificatesDirectoryFmt = `/[^\s"'{};$\\]*`
+const dynamicSSLCertificatesDirectoryErrMsg = "must be an absolute path without whitespace characters, quotes, `{`, `}`, `;`, `$` or `\\`"
+
+var dynamicSSLCertificatesDirectoryRegexp = regexp.MustCompile("^" + dynamicSSLCertificatesDirectoryFmt + "$")
+
+// validateDynamicSSLCertificatesDirectory makes sure the directory of the dynamic certificates is an existing directory
+// whose path can be used in the NGINX configuration as is.
+func validateDynamicSSLCertificatesDirectory(directory string) error {
+	if !dynamicSSLCertificatesDirectoryRegexp.MatchString(directory) {
+		return fmt.Errorf("%q %s", directory, dynamicSSLCertificatesDirectoryErrMsg)
+	}
+
+	info, err := os.Stat(directory)
+	if err != nil {
+		return fmt.Errorf("failed to access %v: %v", directory, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%v is not a directory", directory)
+	}
+
+	return nil
+}
+
 // parseNginxStatusAllowCIDRs converts a comma separated CIDR/IP address string into an array of CIDR/IP addresses.
 // It returns an array of the valid CIDR/IP addresses or an error if given an invalid address.
 func parseNginxStatusAllowCIDRs(input string) (cidrs []string, err error) {
@@ -2,8 +2,14 @@ package main
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
 )
 
 func TestValidatePort(t *testing.T) {
@@ -25,6 +31,88 @@ func TestValidatePort(t *testing.T) {
 
 }
 
+func TestValidateMissingTLSSecretPolicy(t *testing.T) {
+	validPolicies := []string{"error", "ignore", "self-signed"}
+	for _, policy := range validPolicies {
+		err := validateMissingTLSSecretPolicy(policy)
+		if err != nil {
+			t.Errorf("validateMissingTLSSecretPolicy(%q) returned unexpected error: %v", policy, err)
+		}
+	}
+
+	invalidPolicies := []string{"", "fail", "Ignore"}
+	for _, policy := range invalidPolicies {
+		err := validateMissingTLSSecretPolicy(policy)
+		if err == nil {
+			t.Errorf("validateMissingTLSSecretPolicy(%q) returned no error", policy)
+		}
+	}
+}
+
+func TestValidateKubeAPIClientParameters(t *testing.T) {
+	err := validateKubeAPIClientParameters(5, 10, 0)
+	if err != nil {
+		t.Errorf("validateKubeAPIClientParameters() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		qps     float64
+		burst   int
+		timeout time.Duration
+		msg     string
+	}{
+		{
+			qps:     0,
+			burst:   10,
+			timeout: 0,
+			msg:     "zero qps",
+		},
+		{
+			qps:     -1,
+			burst:   10,
+			timeout: 0,
+			msg:     "negative qps",
+		},
+		{
+			qps:     5,
+			burst:   0,
+			timeout: 0,
+			msg:     "zero burst",
+		},
+		{
+			qps:     5,
+			burst:   10,
+			timeout: -time.Second,
+			msg:     "negative timeout",
+		},
+	}
+
+	for _, test := range tests {
+		err := validateKubeAPIClientParameters(test.qps, test.burst, test.timeout)
+		if err == nil {
+			t.Errorf("validateKubeAPIClientParameters() returned no error for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestConfigureKubeAPIClient(t *testing.T) {
+	config := &rest.Config{
+		Host: "https://10.0.0.1:443",
+	}
+
+	configureKubeAPIClient(config, 20, 40, 30*time.Second)
+
+	expected := &rest.Config{
+		Host:    "https://10.0.0.1:443",
+		QPS:     20,
+		Burst:   40,
+		Timeout: 30 * time.Second,
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("configureKubeAPIClient() configured the client with %+v but expected %+v", config, expected)
+	}
+}
+
 func TestParseNginxStatusAllowCIDRs(t *testing.T) {
 	var badCIDRs = []struct {
 		input         string
@@ -124,3 +212,116 @@ func TestValidateLocation(t *testing.T) {
 		}
 	}
 }
+
+func TestParseWatchNamespaces(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input:    "",
+			expected: []string{""},
+		},
+		{
+			input:    "default",
+			expected: []string{"default"},
+		},
+		{
+			input:    "default,nginx-ingress",
+			expected: []string{"default", "nginx-ingress"},
+		},
+		{
+			input:    "default, nginx-ingress",
+			expected: []string{"default", "nginx-ingress"},
+		},
+	}
+
+	for _, test := range tests {
+		result, err := parseWatchNamespaces(test.input)
+		if err != nil {
+			t.Errorf("parseWatchNamespaces(%q) returned unexpected error: %v", test.input, err)
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("parseWatchNamespaces(%q) returned %v but expected %v", test.input, result, test.expected)
+		}
+	}
+
+	invalidInputs := []string{"default,", ",default", "default,default", "Default", "default/name"}
+	for _, input := range invalidInputs {
+		_, err := parseWatchNamespaces(input)
+		if err == nil {
+			t.Errorf("parseWatchNamespaces(%q) returned no error", input)
+		}
+	}
+}
+
+func TestReadEndpointToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resync-token")
+	if err != nil {
+		t.Fatalf("Failed to create a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	validFile := filepath.Join(dir, "token")
+	err = ioutil.WriteFile(validFile, []byte("secret-token\n"), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write the token file: %v", err)
+	}
+
+	emptyFile := filepath.Join(dir, "empty")
+	err = ioutil.WriteFile(emptyFile, []byte("  \n"), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write the token file: %v", err)
+	}
+
+	token, err := readEndpointToken(validFile)
+	if err != nil {
+		t.Errorf("readEndpointToken(%q) returned unexpected error: %v", validFile, err)
+	}
+	if token != "secret-token" {
+		t.Errorf("readEndpointToken(%q) returned %q but expected %q", validFile, token, "secret-token")
+	}
+
+	invalidPaths := []string{"", emptyFile, filepath.Join(dir, "non-existing")}
+	for _, path := range invalidPaths {
+		_, err := readEndpointToken(path)
+		if err == nil {
+			t.Errorf("readEndpointToken(%q) returned no error", path)
+		}
+	}
+}
+
+func TestValidateDynamicSSLCertificatesDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dynamic-certificates")
+	if err != nil {
+		t.Fatalf("Failed to create a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "cafe.example.com.pem")
+	err = ioutil.WriteFile(file, []byte("cert"), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write the certificate file: %v", err)
+	}
+
+	err = validateDynamicSSLCertificatesDirectory(dir)
+	if err != nil {
+		t.Errorf("validateDynamicSSLCertificatesDirectory(%q) returned unexpected error: %v", dir, err)
+	}
+
+	invalidDirectories := []string{
+		"",
+		"certificates",
+		"/etc/nginx/dynamic certificates",
+		"/etc/nginx/$certificates",
+		"/etc/nginx/certificates;",
+		file,
+		filepath.Join(dir, "non-existing"),
+	}
+	for _, directory := range invalidDirectories {
+		err := validateDynamicSSLCertificatesDirectory(directory)
+		if err == nil {
+			t.Errorf("validateDynamicSSLCertificatesDirectory(%q) returned no error", directory)
+		}
+	}
+}
@@ -13,6 +13,7 @@ const JWTKeyAnnotation = "nginx.com/jwt-key"
 
 var masterBlacklist = map[string]bool{
 	"nginx.org/rewrites":                      true,
+	"nginx.org/path-timeouts":                 true,
 	"nginx.org/ssl-services":                  true,
 	"nginx.org/grpc-services":                 true,
 	"nginx.org/websocket-services":            true,
@@ -37,26 +38,31 @@ var minionBlacklist = map[string]bool{
 }
 
 var minionInheritanceList = map[string]bool{
-	"nginx.org/proxy-connect-timeout":    true,
-	"nginx.org/proxy-read-timeout":       true,
-	"nginx.org/proxy-send-timeout":       true,
-	"nginx.org/client-max-body-size":     true,
-	"nginx.org/proxy-buffering":          true,
-	"nginx.org/proxy-buffers":            true,
-	"nginx.org/proxy-buffer-size":        true,
-	"nginx.org/proxy-max-temp-file-size": true,
-	"nginx.org/upstream-zone-size":       true,
-	"nginx.org/location-snippets":        true,
-	"nginx.org/lb-method":                true,
-	"nginx.org/keepalive":                true,
-	"nginx.org/max-fails":                true,
-	"nginx.org/max-conns":                true,
-	"nginx.org/fail-timeout":             true,
+	"nginx.org/proxy-connect-timeout":       true,
+	"nginx.org/proxy-read-timeout":          true,
+	"nginx.org/proxy-send-timeout":          true,
+	"nginx.org/client-max-body-size":        true,
+	"nginx.org/proxy-buffering":             true,
+	"nginx.org/proxy-buffers":               true,
+	"nginx.org/proxy-buffer-size":           true,
+	"nginx.org/proxy-max-temp-file-size":    true,
+	"nginx.org/upstream-zone-size":          true,
+	"nginx.org/location-snippets":           true,
+	"nginx.org/lb-method":                   true,
+	"nginx.org/keepalive":                   true,
+	"nginx.org/max-fails":                   true,
+	"nginx.org/max-conns":                   true,
+	"nginx.org/fail-timeout":                true,
+	"nginx.org/proxy-next-upstream-tries":   true,
+	"nginx.org/proxy-next-upstream-timeout": true,
 }
 
 func parseAnnotations(ingEx *IngressEx, baseCfgParams *ConfigParams, isPlus bool) ConfigParams {
 	cfgParams := *baseCfgParams
 
+	// the parameters of the per-namespace ConfigMap take precedence over the global ones, but not over the annotations
+	applyNamespaceConfigParams(&cfgParams, ingEx.NamespaceCfgParams)
+
 	if lbMethod, exists := ingEx.Ingress.Annotations["nginx.org/lb-method"]; exists {
 		if isPlus {
 			if parsedMethod, err := ParseLBMethodForPlus(lbMethod); err != nil {
@@ -256,6 +262,24 @@ func parseAnnotations(ingEx *IngressEx, baseCfgParams *ConfigParams, isPlus bool
 		cfgParams.ProxyMaxTempFileSize = proxyMaxTempFileSize
 	}
 
+	if proxyNextUpstreamTries, exists, err := GetMapKeyAsInt(ingEx.Ingress.Annotations, "nginx.org/proxy-next-upstream-tries", ingEx.Ingress); exists {
+		if err != nil {
+			glog.Error(err)
+		} else if proxyNextUpstreamTries < 0 {
+			glog.Errorf("Ingress %s/%s: Invalid value nginx.org/proxy-next-upstream-tries: got %d: must be a non-negative integer", ingEx.Ingress.GetNamespace(), ingEx.Ingress.GetName(), proxyNextUpstreamTries)
+		} else {
+			cfgParams.ProxyNextUpstreamTries = proxyNextUpstreamTries
+		}
+	}
+
+	if proxyNextUpstreamTimeout, exists := ingEx.Ingress.Annotations["nginx.org/proxy-next-upstream-timeout"]; exists {
+		if parsedTimeout, err := ParseTime(proxyNextUpstreamTimeout); err != nil {
+			glog.Errorf("Ingress %s/%s: Invalid value nginx.org/proxy-next-upstream-timeout: got %q: %v", ingEx.Ingress.GetNamespace(), ingEx.Ingress.GetName(), proxyNextUpstreamTimeout, err)
+		} else {
+			cfgParams.ProxyNextUpstreamTimeout = parsedTimeout
+		}
+	}
+
 	if isPlus {
 		if jwtRealm, exists := ingEx.Ingress.Annotations["nginx.com/jwt-realm"]; exists {
 			cfgParams.JWTRealm = jwtRealm
@@ -339,6 +363,29 @@ func getRewrites(ingEx *IngressEx) map[string]string {
 	return rewrites
 }
 
+// pathTimeouts are the proxy timeouts of a path of an Ingress that override the timeouts of the Ingress.
+type pathTimeouts struct {
+	ConnectTimeout string
+	ReadTimeout    string
+	SendTimeout    string
+}
+
+func getPathTimeouts(ingEx *IngressEx) map[string]pathTimeouts {
+	timeouts := make(map[string]pathTimeouts)
+
+	if paths, exists := ingEx.Ingress.Annotations["nginx.org/path-timeouts"]; exists {
+		for _, p := range strings.Split(paths, ";") {
+			if path, t, err := parsePathTimeouts(p); err != nil {
+				glog.Errorf("In %v nginx.org/path-timeouts contains invalid declaration: %v, ignoring", ingEx.Ingress.Name, err)
+			} else {
+				timeouts[path] = t
+			}
+		}
+	}
+
+	return timeouts
+}
+
 func getSSLServices(ingEx *IngressEx) map[string]bool {
 	sslServices := make(map[string]bool)
 
@@ -496,3 +543,57 @@ func parseRewrites(service string) (serviceName string, rewrite string, err erro
 
 	return svcNameParts[1], rwPathParts[1], nil
 }
+
+// parsePathTimeouts parses a declaration of the timeouts of a path, for example,
+// "path=/upload connect=10s read=300s send=300s".
+func parsePathTimeouts(declaration string) (path string, timeouts pathTimeouts, err error) {
+	fields := strings.Fields(declaration)
+	if len(fields) < 2 {
+		return "", pathTimeouts{}, fmt.Errorf("Invalid path timeouts format: %s", declaration)
+	}
+
+	seen := make(map[string]bool)
+
+	for _, field := range fields {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return "", pathTimeouts{}, fmt.Errorf("Invalid path timeouts format: %s", field)
+		}
+
+		key, value := parts[0], parts[1]
+		if seen[key] {
+			return "", pathTimeouts{}, fmt.Errorf("Duplicate key %s in path timeouts: %s", key, declaration)
+		}
+		seen[key] = true
+
+		if key == "path" {
+			if !strings.HasPrefix(value, "/") {
+				return "", pathTimeouts{}, fmt.Errorf("Invalid path %s in path timeouts: must start with /", value)
+			}
+			path = value
+			continue
+		}
+
+		t, err := ParseTime(value)
+		if err != nil {
+			return "", pathTimeouts{}, fmt.Errorf("Invalid %s timeout %s in path timeouts: %v", key, value, err)
+		}
+
+		switch key {
+		case "connect":
+			timeouts.ConnectTimeout = t
+		case "read":
+			timeouts.ReadTimeout = t
+		case "send":
+			timeouts.SendTimeout = t
+		default:
+			return "", pathTimeouts{}, fmt.Errorf("Invalid key %s in path timeouts: %s", key, declaration)
+		}
+	}
+
+	if path == "" {
+		return "", pathTimeouts{}, fmt.Errorf("Missing path in path timeouts: %s", declaration)
+	}
+
+	return path, timeouts, nil
+}
@@ -4,6 +4,9 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+
+	"k8s.io/api/extensions/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestParseRewrites(t *testing.T) {
@@ -41,6 +44,62 @@ func TestParseRewritesInvalidFormat(t *testing.T) {
 	}
 }
 
+func TestParsePathTimeouts(t *testing.T) {
+	tests := []struct {
+		declaration      string
+		expectedPath     string
+		expectedTimeouts pathTimeouts
+	}{
+		{
+			declaration:  "path=/upload connect=10s read=300s send=300s",
+			expectedPath: "/upload",
+			expectedTimeouts: pathTimeouts{
+				ConnectTimeout: "10s",
+				ReadTimeout:    "300s",
+				SendTimeout:    "300s",
+			},
+		},
+		{
+			declaration:  " read=1m path=/api ",
+			expectedPath: "/api",
+			expectedTimeouts: pathTimeouts{
+				ReadTimeout: "1m",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		path, timeouts, err := parsePathTimeouts(test.declaration)
+		if err != nil {
+			t.Errorf("parsePathTimeouts(%q) returned an unexpected error: %v", test.declaration, err)
+		}
+		if path != test.expectedPath || timeouts != test.expectedTimeouts {
+			t.Errorf("parsePathTimeouts(%q) returned %q, %+v but expected %q, %+v", test.declaration, path, timeouts, test.expectedPath, test.expectedTimeouts)
+		}
+	}
+}
+
+func TestParsePathTimeoutsFails(t *testing.T) {
+	declarations := []string{
+		"",
+		"path=/upload",
+		"read=300s",
+		"path=upload read=300s",
+		"path=/upload read=5 minutes",
+		"path=/upload read=300x",
+		"path=/upload read=300s read=10s",
+		"path=/upload next=300s",
+		"path=/upload read",
+	}
+
+	for _, declaration := range declarations {
+		_, _, err := parsePathTimeouts(declaration)
+		if err == nil {
+			t.Errorf("parsePathTimeouts(%q) returned no error", declaration)
+		}
+	}
+}
+
 func TestParseStickyService(t *testing.T) {
 	serviceName := "coffee-svc"
 	serviceNamePart := "serviceName=" + serviceName
@@ -151,3 +210,169 @@ func TestMergeMasterAnnotationsIntoMinion(t *testing.T) {
 		t.Errorf("mergeMasterAnnotationsIntoMinion returned %v, but expected %v", minionAnnotations, expectedMergedAnnotations)
 	}
 }
+
+func TestParseAnnotationsWithNamespaceConfigParams(t *testing.T) {
+	baseCfgParams := NewDefaultConfigParams()
+	baseCfgParams.ProxyConnectTimeout = "10s"
+	baseCfgParams.ProxyReadTimeout = "20s"
+	baseCfgParams.ProxySendTimeout = "30s"
+	baseCfgParams.ClientMaxBodySize = "2m"
+
+	tests := []struct {
+		annotations         map[string]string
+		nsCfgParams         *NamespaceConfigParams
+		expectedConnect     string
+		expectedRead        string
+		expectedSend        string
+		expectedMaxBodySize string
+		msg                 string
+	}{
+		{
+			annotations:         map[string]string{},
+			nsCfgParams:         nil,
+			expectedConnect:     "10s",
+			expectedRead:        "20s",
+			expectedSend:        "30s",
+			expectedMaxBodySize: "2m",
+			msg:                 "global values",
+		},
+		{
+			annotations: map[string]string{},
+			nsCfgParams: &NamespaceConfigParams{
+				ProxyConnectTimeout: "11s",
+				ClientMaxBodySize:   "3m",
+			},
+			expectedConnect:     "11s",
+			expectedRead:        "20s",
+			expectedSend:        "30s",
+			expectedMaxBodySize: "3m",
+			msg:                 "per-namespace values override global values",
+		},
+		{
+			annotations: map[string]string{
+				"nginx.org/proxy-connect-timeout": "12s",
+				"nginx.org/proxy-read-timeout":    "22s",
+			},
+			nsCfgParams: &NamespaceConfigParams{
+				ProxyConnectTimeout: "11s",
+				ProxyReadTimeout:    "21s",
+				ProxySendTimeout:    "31s",
+				ClientMaxBodySize:   "3m",
+			},
+			expectedConnect:     "12s",
+			expectedRead:        "22s",
+			expectedSend:        "31s",
+			expectedMaxBodySize: "3m",
+			msg:                 "annotations override per-namespace values",
+		},
+		{
+			annotations: map[string]string{
+				"nginx.org/client-max-body-size": "4m",
+			},
+			nsCfgParams:         nil,
+			expectedConnect:     "10s",
+			expectedRead:        "20s",
+			expectedSend:        "30s",
+			expectedMaxBodySize: "4m",
+			msg:                 "annotations override global values",
+		},
+	}
+
+	for _, test := range tests {
+		ingEx := &IngressEx{
+			Ingress: &v1beta1.Ingress{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:        "cafe-ingress",
+					Namespace:   "default",
+					Annotations: test.annotations,
+				},
+			},
+			NamespaceCfgParams: test.nsCfgParams,
+		}
+
+		result := parseAnnotations(ingEx, baseCfgParams, false)
+
+		if result.ProxyConnectTimeout != test.expectedConnect {
+			t.Errorf("parseAnnotations() returned ProxyConnectTimeout %q but expected %q for the case of %s", result.ProxyConnectTimeout, test.expectedConnect, test.msg)
+		}
+		if result.ProxyReadTimeout != test.expectedRead {
+			t.Errorf("parseAnnotations() returned ProxyReadTimeout %q but expected %q for the case of %s", result.ProxyReadTimeout, test.expectedRead, test.msg)
+		}
+		if result.ProxySendTimeout != test.expectedSend {
+			t.Errorf("parseAnnotations() returned ProxySendTimeout %q but expected %q for the case of %s", result.ProxySendTimeout, test.expectedSend, test.msg)
+		}
+		if result.ClientMaxBodySize != test.expectedMaxBodySize {
+			t.Errorf("parseAnnotations() returned ClientMaxBodySize %q but expected %q for the case of %s", result.ClientMaxBodySize, test.expectedMaxBodySize, test.msg)
+		}
+	}
+
+	if baseCfgParams.ProxyConnectTimeout != "10s" {
+		t.Errorf("parseAnnotations() modified the base ConfigParams")
+	}
+}
+
+func TestParseAnnotationsWithProxyNextUpstream(t *testing.T) {
+	tests := []struct {
+		annotations     map[string]string
+		expectedTries   int
+		expectedTimeout string
+		msg             string
+	}{
+		{
+			annotations: map[string]string{
+				"nginx.org/proxy-next-upstream-tries":   "3",
+				"nginx.org/proxy-next-upstream-timeout": "5s",
+			},
+			expectedTries:   3,
+			expectedTimeout: "5s",
+			msg:             "valid values",
+		},
+		{
+			annotations: map[string]string{
+				"nginx.org/proxy-next-upstream-tries":   "0",
+				"nginx.org/proxy-next-upstream-timeout": "0",
+			},
+			expectedTries:   0,
+			expectedTimeout: "0",
+			msg:             "zero values",
+		},
+		{
+			annotations: map[string]string{
+				"nginx.org/proxy-next-upstream-tries":   "-1",
+				"nginx.org/proxy-next-upstream-timeout": "5x",
+			},
+			expectedTries:   0,
+			expectedTimeout: "",
+			msg:             "invalid values are ignored",
+		},
+		{
+			annotations: map[string]string{
+				"nginx.org/proxy-next-upstream-tries": "three",
+			},
+			expectedTries:   0,
+			expectedTimeout: "",
+			msg:             "non-numeric tries is ignored",
+		},
+	}
+
+	for _, test := range tests {
+		ingEx := &IngressEx{
+			Ingress: &v1beta1.Ingress{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:        "cafe-ingress",
+					Namespace:   "default",
+					Annotations: test.annotations,
+				},
+			},
+		}
+
+		result := parseAnnotations(ingEx, NewDefaultConfigParams(), false)
+
+		if result.ProxyNextUpstreamTries != test.expectedTries {
+			t.Errorf("parseAnnotations() returned ProxyNextUpstreamTries %d but expected %d for the case of %s", result.ProxyNextUpstreamTries, test.expectedTries, test.msg)
+		}
+		if result.ProxyNextUpstreamTimeout != test.expectedTimeout {
+			t.Errorf("parseAnnotations() returned ProxyNextUpstreamTimeout %q but expected %q for the case of %s", result.ProxyNextUpstreamTimeout, test.expectedTimeout, test.msg)
+		}
+	}
+}
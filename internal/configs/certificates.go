@@ -0,0 +1,105 @@
+package configs
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
+)
+
+// selfSignedCertificateValidity is the validity period of the self-signed certificates generated by the Ingress Controller.
+const selfSignedCertificateValidity = 30 * 24 * time.Hour
+
+// SelfSignedCertificateRotationPeriod is the period of regenerating the self-signed certificate of the default server,
+// so that the certificate is replaced well before it expires.
+const SelfSignedCertificateRotationPeriod = selfSignedCertificateValidity / 2
+
+// SelfSignedCertificateRenewalCheckPeriod is the period of checking whether the self-signed certificates
+// of the VirtualServers with missing TLS Secrets are due for renewal.
+const SelfSignedCertificateRenewalCheckPeriod = time.Hour
+
+// selfSignedCertificate is a temporary self-signed certificate of a VirtualServer with a missing TLS Secret.
+type selfSignedCertificate struct {
+	secretName  string
+	pemFileName string
+	host        string
+	notAfter    time.Time
+}
+
+// needsRenewal returns true if the certificate expires within half of its validity period.
+func (cert selfSignedCertificate) needsRenewal(now time.Time) bool {
+	return cert.notAfter.Sub(now) < selfSignedCertificateValidity/2
+}
+
+// CreateSelfSignedDefaultServerSecret generates a self-signed TLS certificate and a key for the default server
+// and writes them to the file of the default server Secret. It returns the name of the file.
+func CreateSelfSignedDefaultServerSecret(nginxManager nginx.Manager) (string, error) {
+	data, err := generateSelfSignedCertificate("", time.Now(), selfSignedCertificateValidity)
+	if err != nil {
+		return "", err
+	}
+
+	return nginxManager.CreateSecret(DefaultServerSecretName, data, nginx.TLSSecretFileMode), nil
+}
+
+// generateSelfSignedCertificate generates a self-signed certificate and a key for the host.
+// The result has the same format as the content of the files of TLS Secrets: a PEM-encoded cert followed by a PEM-encoded key.
+func generateSelfSignedCertificate(host string, now time.Time, validity time.Duration) ([]byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate a private key: %v", err)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate a serial number: %v", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName:   host,
+			Organization: []string{"NGINX Ingress Controller"},
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	if host != "" {
+		template.DNSNames = []string{host}
+	}
+
+	cert, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a certificate: %v", err)
+	}
+
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the private key: %v", err)
+	}
+
+	var res bytes.Buffer
+
+	err = pem.Encode(&res, &pem.Block{Type: "CERTIFICATE", Bytes: cert})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the certificate: %v", err)
+	}
+
+	err = pem.Encode(&res, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the private key: %v", err)
+	}
+
+	return res.Bytes(), nil
+}
@@ -0,0 +1,85 @@
+package configs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nginxinc/kubernetes-ingress/internal/metrics/collectors"
+	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
+)
+
+func TestGenerateSelfSignedCertificate(t *testing.T) {
+	now := time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+	host := "cafe.example.com"
+
+	data, err := generateSelfSignedCertificate(host, now, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("generateSelfSignedCertificate() returned unexpected error: %v", err)
+	}
+
+	pair, err := tls.X509KeyPair(data, data)
+	if err != nil {
+		t.Fatalf("generateSelfSignedCertificate() returned an invalid cert and key: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatalf("Failed to parse the certificate: %v", err)
+	}
+
+	expectedDNSNames := []string{host}
+	if !reflect.DeepEqual(cert.DNSNames, expectedDNSNames) {
+		t.Errorf("generateSelfSignedCertificate() returned a cert with DNS names %v but expected %v", cert.DNSNames, expectedDNSNames)
+	}
+
+	expectedNotAfter := now.Add(24 * time.Hour)
+	if !cert.NotAfter.Equal(expectedNotAfter) {
+		t.Errorf("generateSelfSignedCertificate() returned a cert with NotAfter %v but expected %v", cert.NotAfter, expectedNotAfter)
+	}
+
+	err = cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature)
+	if err != nil {
+		t.Errorf("generateSelfSignedCertificate() returned a cert that is not self-signed: %v", err)
+	}
+}
+
+func TestCreateSelfSignedDefaultServerSecret(t *testing.T) {
+	confPath, err := ioutil.TempDir("", "nginx")
+	if err != nil {
+		t.Fatalf("Failed to create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(confPath)
+
+	err = os.Mkdir(path.Join(confPath, "secrets"), 0755)
+	if err != nil {
+		t.Fatalf("Failed to create the secrets dir: %v", err)
+	}
+
+	manager := nginx.NewLocalManager(confPath, "nginx", collectors.NewManagerFakeCollector(), true)
+
+	fileName, err := CreateSelfSignedDefaultServerSecret(manager)
+	if err != nil {
+		t.Fatalf("CreateSelfSignedDefaultServerSecret() returned unexpected error: %v", err)
+	}
+
+	expectedFileName := path.Join(confPath, "secrets", DefaultServerSecretName)
+	if fileName != expectedFileName {
+		t.Errorf("CreateSelfSignedDefaultServerSecret() returned %v but expected %v", fileName, expectedFileName)
+	}
+
+	data, err := ioutil.ReadFile(expectedFileName)
+	if err != nil {
+		t.Fatalf("Failed to read the default server secret: %v", err)
+	}
+
+	_, err = tls.X509KeyPair(data, data)
+	if err != nil {
+		t.Errorf("CreateSelfSignedDefaultServerSecret() wrote an invalid cert and key: %v", err)
+	}
+}
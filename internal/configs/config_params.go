@@ -5,76 +5,106 @@ import conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configurat
 // ConfigParams holds NGINX configuration parameters that affect the main NGINX config
 // as well as configs for Ingress resources.
 type ConfigParams struct {
-	ClientMaxBodySize             string
-	DefaultServerAccessLogOff     bool
-	FailTimeout                   string
-	HealthCheckEnabled            bool
-	HealthCheckMandatory          bool
-	HealthCheckMandatoryQueue     int64
-	HSTS                          bool
-	HSTSBehindProxy               bool
-	HSTSIncludeSubdomains         bool
-	HSTSMaxAge                    int64
-	HTTP2                         bool
-	Keepalive                     int
-	LBMethod                      string
-	LocationSnippets              []string
-	MainAccessLogOff              bool
-	MainErrorLogLevel             string
-	MainHTTPSnippets              []string
-	MainKeepaliveRequests         int64
-	MainKeepaliveTimeout          string
-	MainLogFormat                 []string
-	MainLogFormatEscaping         string
-	MainMainSnippets              []string
-	MainOpenTracingEnabled        bool
-	MainOpenTracingLoadModule     bool
-	MainOpenTracingTracer         string
-	MainOpenTracingTracerConfig   string
-	MainServerNamesHashBucketSize string
-	MainServerNamesHashMaxSize    string
-	MainStreamLogFormat           []string
-	MainStreamLogFormatEscaping   string
-	MainStreamSnippets            []string
-	MainWorkerConnections         string
-	MainWorkerCPUAffinity         string
-	MainWorkerProcesses           string
-	MainWorkerRlimitNofile        string
-	MainWorkerShutdownTimeout     string
-	MaxConns                      int
-	MaxFails                      int
-	ProxyBuffering                bool
-	ProxyBuffers                  string
-	ProxyBufferSize               string
-	ProxyConnectTimeout           string
-	ProxyHideHeaders              []string
-	ProxyMaxTempFileSize          string
-	ProxyPassHeaders              []string
-	ProxyProtocol                 bool
-	ProxyReadTimeout              string
-	ProxySendTimeout              string
-	RedirectToHTTPS               bool
-	ResolverAddresses             []string
-	ResolverIPV6                  bool
-	ResolverTimeout               string
-	ResolverValid                 string
-	ServerSnippets                []string
-	ServerTokens                  string
-	SlowStart                     string
-	SSLRedirect                   bool
-	UpstreamZoneSize              string
-	VariablesHashBucketSize       uint64
-	VariablesHashMaxSize          uint64
+	ClientMaxBodySize                 string
+	DefaultServerAccessLogOff         bool
+	FailTimeout                       string
+	HealthCheckEnabled                bool
+	HealthCheckMandatory              bool
+	HealthCheckMandatoryQueue         int64
+	HSTS                              bool
+	HSTSBehindProxy                   bool
+	HSTSIncludeSubdomains             bool
+	HSTSMaxAge                        int64
+	HTTP2                             bool
+	Keepalive                         int
+	LBMethod                          string
+	LocationSnippets                  []string
+	MainAccessLogOff                  bool
+	MainAccessLogSampleRate           int
+	MainAccessLogNon2xxOnly           bool
+	MainClientBodyTimeout             string
+	MainClientHeaderBufferSize        string
+	MainClientHeaderTimeout           string
+	MainErrorLogLevel                 string
+	MainHTTPSnippets                  []string
+	MainKeepaliveRequests             int64
+	MainKeepaliveTimeout              string
+	MainLargeClientHeaderBuffers      string
+	MainLogFormat                     []string
+	MainLogFormatEscaping             string
+	MainMainSnippets                  []string
+	MainOpenTelemetryEnabled          bool
+	MainOpenTelemetryExporterEndpoint string
+	MainOpenTelemetryServiceName      string
+	MainOpenTelemetrySamplerRatio     float64
+	MainOpenTracingEnabled            bool
+	MainOpenTracingLoadModule         bool
+	MainOpenTracingTracer             string
+	MainOpenTracingTracerConfig       string
+	MainServerNamesHashBucketSize     string
+	MainServerNamesHashMaxSize        string
+	MainStreamLogFormat               []string
+	MainStreamLogFormatEscaping       string
+	MainStreamSnippets                []string
+	MainWorkerConnections             string
+	MainWorkerCPUAffinity             string
+	MainWorkerProcesses               string
+	MainWorkerRlimitNofile            string
+	MainWorkerShutdownTimeout         string
+	MaxConns                          int
+	MaxFails                          int
+	ProxyBuffering                    bool
+	ProxyBuffers                      string
+	ProxyBufferSize                   string
+	ProxyConnectTimeout               string
+	ProxyHideHeaders                  []string
+	ProxyMaxTempFileSize              string
+	ProxyNextUpstreamTimeout          string
+	ProxyNextUpstreamTries            int
+	ProxyPassHeaders                  []string
+	ProxyProtocol                     bool
+	ListenBacklog                     int
+	ListenReuseport                   bool
+	ListenFastopen                    int
+	ListenDeferred                    bool
+	ListenSoKeepalive                 string
+	ProxyReadTimeout                  string
+	ProxySendTimeout                  string
+	RedirectToHTTPS                   bool
+	ResolverAddresses                 []string
+	ConnectionLimitPolicies           []ConnectionLimitPolicy
+	LimitReqZoneSize                  string
+	LimitConnZoneSize                 string
+	ResolverIPV6                      bool
+	ResolverTimeout                   string
+	ResolverValid                     string
+	ResolverUpstreamValid             string
+	ServerSnippets                    []string
+	ServerTokens                      string
+	SlowStart                         string
+	SSLRedirect                       bool
+	UpstreamZoneSize                  string
+	VariablesHashBucketSize           uint64
+	VariablesHashMaxSize              uint64
+	MapHashBucketSize                 uint64
+	MapHashMaxSize                    uint64
+	ProxyHeadersHashBucketSize        uint64
+	ProxyHeadersHashMaxSize           uint64
 
 	RealIPHeader    string
 	RealIPRecursive bool
 	SetRealIPFrom   []string
 
+	ForwardedHeadersPolicy string
+
 	MainServerSSLCiphers             string
 	MainServerSSLDHParam             string
 	MainServerSSLDHParamFileContent  *string
 	MainServerSSLPreferServerCiphers bool
 	MainServerSSLProtocols           string
+	MainServerSSLSessionCache        string
+	MainServerSSLSessionTimeout      string
+	MainServerSSLSessionTickets      string
 
 	IngressTemplate *string
 	MainTemplate    *string
@@ -100,25 +130,71 @@ type StaticConfigParams struct {
 	StubStatusOverUnixSocketForOSS bool
 	TLSPassthrough                 bool
 	SpiffeCerts                    bool
+	MissingTLSSecretPolicy         string
+	EnableOpenTelemetry            bool
+	EnableBrotli                   bool
+	EnableOIDC                     bool
+	ConfigSourceComments           bool
+	DynamicSSLCertsDirectory       string
 }
 
+// Policies for the X-Forwarded-For, X-Forwarded-Host, X-Forwarded-Port and X-Forwarded-Proto headers
+// that NGINX passes to the backends.
+const (
+	// ForwardedHeadersPolicyAppend appends the client address to the X-Forwarded-For header of the request
+	// and sets the other headers.
+	ForwardedHeadersPolicyAppend = "append"
+	// ForwardedHeadersPolicyReplace replaces the X-Forwarded-For header of the request with the client address
+	// and sets the other headers.
+	ForwardedHeadersPolicyReplace = "replace"
+	// ForwardedHeadersPolicyPass doesn't set the headers, so the headers of the request are passed unchanged.
+	ForwardedHeadersPolicyPass = "pass"
+)
+
 // GlobalConfigParams holds global configuration parameters. For now, it only holds listeners.
 // GlobalConfigParams should replace ConfigParams in the future.
 type GlobalConfigParams struct {
 	Listeners map[string]Listener
 }
 
+// NamespaceConfigParams holds the configuration parameters of a per-namespace ConfigMap.
+// They override the corresponding ConfigParams for all Ingress resources of the namespace.
+// An empty value means the parameter is not overridden.
+type NamespaceConfigParams struct {
+	ProxyConnectTimeout string
+	ProxyReadTimeout    string
+	ProxySendTimeout    string
+	ClientMaxBodySize   string
+}
+
+// ConnectionLimitPolicy defines a named limit of the number of concurrent connections to upstreams.
+// The limit is shared by all the upstreams that reference the policy, even across VirtualServers.
+type ConnectionLimitPolicy struct {
+	Name  string
+	Limit int
+}
+
 // Listener represents a listener that can be used in a TransportServer resource.
 type Listener struct {
-	Port     int
-	Protocol string
+	Port        int
+	Protocol    string
+	IPv4        string
+	IPv6        string
+	Backlog     int
+	Reuseport   bool
+	Fastopen    int
+	Deferred    bool
+	SoKeepalive string
 }
 
 // NewDefaultConfigParams creates a ConfigParams with default values.
 func NewDefaultConfigParams() *ConfigParams {
 	return &ConfigParams{
 		ServerTokens:                  "on",
+		MainOpenTelemetryServiceName:  "nginx-ingress",
+		MainOpenTelemetrySamplerRatio: 1,
 		ProxyConnectTimeout:           "60s",
+		ForwardedHeadersPolicy:        ForwardedHeadersPolicyAppend,
 		ProxyReadTimeout:              "60s",
 		ProxySendTimeout:              "60s",
 		ClientMaxBodySize:             "1m",
@@ -1,13 +1,75 @@
 package configs
 
 import (
+	"fmt"
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/golang/glog"
 	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
+// ParseNamespaceConfigMap parses a per-namespace ConfigMap into NamespaceConfigParams.
+func ParseNamespaceConfigMap(cfgm *v1.ConfigMap) *NamespaceConfigParams {
+	nsCfgParams := &NamespaceConfigParams{}
+
+	if proxyConnectTimeout, exists := cfgm.Data["proxy-connect-timeout"]; exists {
+		nsCfgParams.ProxyConnectTimeout = proxyConnectTimeout
+	}
+
+	if proxyReadTimeout, exists := cfgm.Data["proxy-read-timeout"]; exists {
+		nsCfgParams.ProxyReadTimeout = proxyReadTimeout
+	}
+
+	if proxySendTimeout, exists := cfgm.Data["proxy-send-timeout"]; exists {
+		nsCfgParams.ProxySendTimeout = proxySendTimeout
+	}
+
+	if clientMaxBodySize, exists := cfgm.Data["client-max-body-size"]; exists {
+		nsCfgParams.ClientMaxBodySize = clientMaxBodySize
+	}
+
+	return nsCfgParams
+}
+
+// applyNamespaceConfigParams overrides the cfgParams with the non-empty parameters of the per-namespace ConfigMap.
+func applyNamespaceConfigParams(cfgParams *ConfigParams, nsCfgParams *NamespaceConfigParams) {
+	if nsCfgParams == nil {
+		return
+	}
+
+	if nsCfgParams.ProxyConnectTimeout != "" {
+		cfgParams.ProxyConnectTimeout = nsCfgParams.ProxyConnectTimeout
+	}
+
+	if nsCfgParams.ProxyReadTimeout != "" {
+		cfgParams.ProxyReadTimeout = nsCfgParams.ProxyReadTimeout
+	}
+
+	if nsCfgParams.ProxySendTimeout != "" {
+		cfgParams.ProxySendTimeout = nsCfgParams.ProxySendTimeout
+	}
+
+	if nsCfgParams.ClientMaxBodySize != "" {
+		cfgParams.ClientMaxBodySize = nsCfgParams.ClientMaxBodySize
+	}
+}
+
+// validErrorLogLevels includes the levels of the error_log directive.
+var validErrorLogLevels = map[string]bool{
+	"debug":  true,
+	"info":   true,
+	"notice": true,
+	"warn":   true,
+	"error":  true,
+	"crit":   true,
+	"alert":  true,
+	"emerg":  true,
+}
+
 // ParseConfigMap parses ConfigMap into ConfigParams.
 func ParseConfigMap(cfgm *v1.ConfigMap, nginxPlus bool) *ConfigParams {
 	cfgParams := NewDefaultConfigParams()
@@ -83,6 +145,42 @@ func ParseConfigMap(cfgm *v1.ConfigMap, nginxPlus bool) *ConfigParams {
 		cfgParams.MainServerNamesHashMaxSize = serverNamesHashMaxSize
 	}
 
+	if clientHeaderBufferSize, exists := cfgm.Data["client-header-buffer-size"]; exists {
+		size, err := ParseSize(clientHeaderBufferSize)
+		if err != nil {
+			glog.Errorf("ConfigMap %s/%s: Invalid value for the client-header-buffer-size key: got %q: %v", cfgm.GetNamespace(), cfgm.GetName(), clientHeaderBufferSize, err)
+		} else {
+			cfgParams.MainClientHeaderBufferSize = size
+		}
+	}
+
+	if largeClientHeaderBuffers, exists := cfgm.Data["large-client-header-buffers"]; exists {
+		buffers, err := ParseBuffers(largeClientHeaderBuffers)
+		if err != nil {
+			glog.Errorf("ConfigMap %s/%s: Invalid value for the large-client-header-buffers key: got %q: %v", cfgm.GetNamespace(), cfgm.GetName(), largeClientHeaderBuffers, err)
+		} else {
+			cfgParams.MainLargeClientHeaderBuffers = buffers
+		}
+	}
+
+	if clientHeaderTimeout, exists := cfgm.Data["client-header-timeout"]; exists {
+		timeout, err := ParseTime(clientHeaderTimeout)
+		if err != nil {
+			glog.Errorf("ConfigMap %s/%s: Invalid value for the client-header-timeout key: got %q: %v", cfgm.GetNamespace(), cfgm.GetName(), clientHeaderTimeout, err)
+		} else {
+			cfgParams.MainClientHeaderTimeout = timeout
+		}
+	}
+
+	if clientBodyTimeout, exists := cfgm.Data["client-body-timeout"]; exists {
+		timeout, err := ParseTime(clientBodyTimeout)
+		if err != nil {
+			glog.Errorf("ConfigMap %s/%s: Invalid value for the client-body-timeout key: got %q: %v", cfgm.GetNamespace(), cfgm.GetName(), clientBodyTimeout, err)
+		} else {
+			cfgParams.MainClientBodyTimeout = timeout
+		}
+	}
+
 	if HTTP2, exists, err := GetMapKeyAsBool(cfgm.Data, "http2", cfgm); exists {
 		if err != nil {
 			glog.Error(err)
@@ -154,6 +252,50 @@ func ParseConfigMap(cfgm *v1.ConfigMap, nginxPlus bool) *ConfigParams {
 		}
 	}
 
+	if listenBacklog, exists, err := GetMapKeyAsInt(cfgm.Data, "listen-backlog", cfgm); exists {
+		if err != nil {
+			glog.Error(err)
+		} else if listenBacklog <= 0 {
+			glog.Errorf("Configmap %s/%s: Invalid value for listen-backlog key: must be a positive integer, got %d", cfgm.GetNamespace(), cfgm.GetName(), listenBacklog)
+		} else {
+			cfgParams.ListenBacklog = listenBacklog
+		}
+	}
+
+	if listenReuseport, exists, err := GetMapKeyAsBool(cfgm.Data, "listen-reuseport", cfgm); exists {
+		if err != nil {
+			glog.Error(err)
+		} else {
+			cfgParams.ListenReuseport = listenReuseport
+		}
+	}
+
+	if listenFastopen, exists, err := GetMapKeyAsInt(cfgm.Data, "listen-fastopen", cfgm); exists {
+		if err != nil {
+			glog.Error(err)
+		} else if listenFastopen <= 0 {
+			glog.Errorf("Configmap %s/%s: Invalid value for listen-fastopen key: must be a positive integer, got %d", cfgm.GetNamespace(), cfgm.GetName(), listenFastopen)
+		} else {
+			cfgParams.ListenFastopen = listenFastopen
+		}
+	}
+
+	if listenDeferred, exists, err := GetMapKeyAsBool(cfgm.Data, "listen-deferred", cfgm); exists {
+		if err != nil {
+			glog.Error(err)
+		} else {
+			cfgParams.ListenDeferred = listenDeferred
+		}
+	}
+
+	if listenSoKeepalive, exists := cfgm.Data["listen-so-keepalive"]; exists {
+		if soKeepalive, err := ParseSoKeepalive(listenSoKeepalive); err != nil {
+			glog.Errorf("Configmap %s/%s: Invalid value for listen-so-keepalive key: got %q: %v", cfgm.GetNamespace(), cfgm.GetName(), listenSoKeepalive, err)
+		} else {
+			cfgParams.ListenSoKeepalive = soKeepalive
+		}
+	}
+
 	if realIPHeader, exists := cfgm.Data["real-ip-header"]; exists {
 		cfgParams.RealIPHeader = realIPHeader
 	}
@@ -174,6 +316,19 @@ func ParseConfigMap(cfgm *v1.ConfigMap, nginxPlus bool) *ConfigParams {
 		}
 	}
 
+	if forwardedHeadersPolicy, exists := cfgm.Data["forwarded-headers-policy"]; exists {
+		policy, err := parseForwardedHeadersPolicy(forwardedHeadersPolicy, cfgParams.RealIPHeader)
+		if err != nil {
+			glog.Errorf("ConfigMap %s/%s: Invalid value for the forwarded-headers-policy key: got %q: %v", cfgm.GetNamespace(), cfgm.GetName(), forwardedHeadersPolicy, err)
+		}
+		if policy != "" {
+			cfgParams.ForwardedHeadersPolicy = policy
+		}
+		if policy == ForwardedHeadersPolicyPass && len(cfgParams.SetRealIPFrom) == 0 {
+			glog.Warningf("ConfigMap %s/%s: the forwarded-headers-policy key is %q, but the set-real-ip-from key is not set: clients can set the X-Forwarded-* headers to any values", cfgm.GetNamespace(), cfgm.GetName(), policy)
+		}
+	}
+
 	if sslProtocols, exists := cfgm.Data["ssl-protocols"]; exists {
 		cfgParams.MainServerSSLProtocols = sslProtocols
 	}
@@ -195,8 +350,43 @@ func ParseConfigMap(cfgm *v1.ConfigMap, nginxPlus bool) *ConfigParams {
 		cfgParams.MainServerSSLDHParamFileContent = &sslDHParamFile
 	}
 
+	if sslSessionCache, exists := cfgm.Data["ssl-session-cache"]; exists {
+		cache, err := ParseSSLSessionCache(sslSessionCache)
+		if err != nil {
+			glog.Errorf("ConfigMap %s/%s: Invalid value for the ssl-session-cache key: got %q: %v", cfgm.GetNamespace(), cfgm.GetName(), sslSessionCache, err)
+		} else {
+			cfgParams.MainServerSSLSessionCache = cache
+		}
+	}
+
+	if sslSessionTimeout, exists := cfgm.Data["ssl-session-timeout"]; exists {
+		timeout, err := ParseTime(sslSessionTimeout)
+		if err != nil {
+			glog.Errorf("ConfigMap %s/%s: Invalid value for the ssl-session-timeout key: got %q: %v", cfgm.GetNamespace(), cfgm.GetName(), sslSessionTimeout, err)
+		} else {
+			cfgParams.MainServerSSLSessionTimeout = timeout
+		}
+	}
+
+	if sslSessionTickets, exists, err := GetMapKeyAsBool(cfgm.Data, "ssl-session-tickets", cfgm); exists {
+		if err != nil {
+			glog.Error(err)
+		} else {
+			cfgParams.MainServerSSLSessionTickets = "off"
+			if sslSessionTickets {
+				cfgParams.MainServerSSLSessionTickets = "on"
+			}
+		}
+	}
+
 	if errorLogLevel, exists := cfgm.Data["error-log-level"]; exists {
-		cfgParams.MainErrorLogLevel = errorLogLevel
+		if validErrorLogLevels[errorLogLevel] {
+			cfgParams.MainErrorLogLevel = errorLogLevel
+		} else {
+			glog.Errorf("ConfigMap %s/%s: Invalid value for the error-log-level key: got %q: must be one of debug, info, notice, warn, error, crit, alert or emerg, keeping the current level", cfgm.GetNamespace(), cfgm.GetName(), errorLogLevel)
+			// an empty level makes the Configurator keep the level of the current configuration
+			cfgParams.MainErrorLogLevel = ""
+		}
 	}
 
 	if accessLogOff, exists, err := GetMapKeyAsBool(cfgm.Data, "access-log-off", cfgm); exists {
@@ -207,6 +397,24 @@ func ParseConfigMap(cfgm *v1.ConfigMap, nginxPlus bool) *ConfigParams {
 		}
 	}
 
+	if accessLogSampleRate, exists, err := GetMapKeyAsInt(cfgm.Data, "access-log-sample-rate", cfgm); exists {
+		if err != nil {
+			glog.Error(err)
+		} else if accessLogSampleRate < 1 || accessLogSampleRate > maxAccessLogSampleRate {
+			glog.Errorf("Configmap %s/%s: Invalid value for access-log-sample-rate key: must be within the range [1-%d], got %d", cfgm.GetNamespace(), cfgm.GetName(), maxAccessLogSampleRate, accessLogSampleRate)
+		} else {
+			cfgParams.MainAccessLogSampleRate = accessLogSampleRate
+		}
+	}
+
+	if accessLogNon2xxOnly, exists, err := GetMapKeyAsBool(cfgm.Data, "access-log-non-2xx-only", cfgm); exists {
+		if err != nil {
+			glog.Error(err)
+		} else {
+			cfgParams.MainAccessLogNon2xxOnly = accessLogNon2xxOnly
+		}
+	}
+
 	if logFormat, exists, err := GetMapKeyAsStringSlice(cfgm.Data, "log-format", cfgm, "\n"); exists {
 		if err != nil {
 			glog.Error(err)
@@ -393,6 +601,18 @@ func ParseConfigMap(cfgm *v1.ConfigMap, nginxPlus bool) *ConfigParams {
 		}
 	}
 
+	if resolverUpstreamValid, exists := cfgm.Data["resolver-upstream-valid"]; exists {
+		if nginxPlus {
+			if _, err := ParseTime(resolverUpstreamValid); err != nil {
+				glog.Errorf("Configmap %s/%s: Invalid value for resolver-upstream-valid key: got %q: %v", cfgm.GetNamespace(), cfgm.GetName(), resolverUpstreamValid, err)
+			} else {
+				cfgParams.ResolverUpstreamValid = resolverUpstreamValid
+			}
+		} else {
+			glog.Warning("ConfigMap key 'resolver-upstream-valid' requires NGINX Plus")
+		}
+	}
+
 	if resolverTimeout, exists := cfgm.Data["resolver-timeout"]; exists {
 		if nginxPlus {
 			cfgParams.ResolverTimeout = resolverTimeout
@@ -401,6 +621,43 @@ func ParseConfigMap(cfgm *v1.ConfigMap, nginxPlus bool) *ConfigParams {
 		}
 	}
 
+	if connectionLimitPolicies, exists, err := GetMapKeyAsStringSlice(cfgm.Data, "connection-limit-policies", cfgm, ","); exists {
+		if err != nil {
+			glog.Error(err)
+		} else {
+			policies, err := parseConnectionLimitPolicies(connectionLimitPolicies)
+			if err != nil {
+				glog.Errorf("ConfigMap %s/%s: invalid value for 'connection-limit-policies': %v, ignoring", cfgm.GetNamespace(), cfgm.GetName(), err)
+			} else {
+				cfgParams.ConnectionLimitPolicies = policies
+			}
+		}
+	}
+
+	if limitReqZoneSize, exists := cfgm.Data["limit-req-zone-size"]; exists {
+		size, err := parseLimitZoneSize(limitReqZoneSize)
+		if err != nil {
+			glog.Errorf("ConfigMap %s/%s: invalid value for 'limit-req-zone-size': %q: %v, ignoring", cfgm.GetNamespace(), cfgm.GetName(), limitReqZoneSize, err)
+		} else {
+			if isSmallLimitZoneSize(size) {
+				glog.Warningf("ConfigMap %s/%s: the value %q of 'limit-req-zone-size' is small. When a zone is full, NGINX rejects the requests with new keys", cfgm.GetNamespace(), cfgm.GetName(), size)
+			}
+			cfgParams.LimitReqZoneSize = size
+		}
+	}
+
+	if limitConnZoneSize, exists := cfgm.Data["limit-conn-zone-size"]; exists {
+		size, err := parseLimitZoneSize(limitConnZoneSize)
+		if err != nil {
+			glog.Errorf("ConfigMap %s/%s: invalid value for 'limit-conn-zone-size': %q: %v, ignoring", cfgm.GetNamespace(), cfgm.GetName(), limitConnZoneSize, err)
+		} else {
+			if isSmallLimitZoneSize(size) {
+				glog.Warningf("ConfigMap %s/%s: the value %q of 'limit-conn-zone-size' is small. When a zone is full, NGINX rejects the connections with new keys", cfgm.GetNamespace(), cfgm.GetName(), size)
+			}
+			cfgParams.LimitConnZoneSize = size
+		}
+	}
+
 	if keepaliveTimeout, exists := cfgm.Data["keepalive-timeout"]; exists {
 		cfgParams.MainKeepaliveTimeout = keepaliveTimeout
 	}
@@ -429,6 +686,38 @@ func ParseConfigMap(cfgm *v1.ConfigMap, nginxPlus bool) *ConfigParams {
 		}
 	}
 
+	if mapHashBucketSize, exists, err := GetMapKeyAsUint64(cfgm.Data, "map-hash-bucket-size", cfgm, true); exists {
+		if err != nil {
+			glog.Error(err)
+		} else {
+			cfgParams.MapHashBucketSize = mapHashBucketSize
+		}
+	}
+
+	if mapHashMaxSize, exists, err := GetMapKeyAsUint64(cfgm.Data, "map-hash-max-size", cfgm, true); exists {
+		if err != nil {
+			glog.Error(err)
+		} else {
+			cfgParams.MapHashMaxSize = mapHashMaxSize
+		}
+	}
+
+	if proxyHeadersHashBucketSize, exists, err := GetMapKeyAsUint64(cfgm.Data, "proxy-headers-hash-bucket-size", cfgm, true); exists {
+		if err != nil {
+			glog.Error(err)
+		} else {
+			cfgParams.ProxyHeadersHashBucketSize = proxyHeadersHashBucketSize
+		}
+	}
+
+	if proxyHeadersHashMaxSize, exists, err := GetMapKeyAsUint64(cfgm.Data, "proxy-headers-hash-max-size", cfgm, true); exists {
+		if err != nil {
+			glog.Error(err)
+		} else {
+			cfgParams.ProxyHeadersHashMaxSize = proxyHeadersHashMaxSize
+		}
+	}
+
 	if openTracingTracer, exists := cfgm.Data["opentracing-tracer"]; exists {
 		cfgParams.MainOpenTracingTracer = openTracingTracer
 	}
@@ -453,13 +742,140 @@ func ParseConfigMap(cfgm *v1.ConfigMap, nginxPlus bool) *ConfigParams {
 		}
 	}
 
+	if openTelemetryExporterEndpoint, exists := cfgm.Data["opentelemetry-exporter-endpoint"]; exists {
+		cfgParams.MainOpenTelemetryExporterEndpoint = openTelemetryExporterEndpoint
+	}
+
+	if openTelemetryServiceName, exists := cfgm.Data["opentelemetry-service-name"]; exists {
+		cfgParams.MainOpenTelemetryServiceName = openTelemetryServiceName
+	}
+
+	if openTelemetrySamplerRatio, exists, err := GetMapKeyAsFloat64(cfgm.Data, "opentelemetry-sampler-ratio", cfgm); exists {
+		if err != nil {
+			glog.Error(err)
+		} else if openTelemetrySamplerRatio < 0 || openTelemetrySamplerRatio > 1 {
+			glog.Errorf("ConfigMap %s/%s: Invalid value for the opentelemetry-sampler-ratio key: got %v: must be between 0 and 1, ignoring", cfgm.GetNamespace(), cfgm.GetName(), openTelemetrySamplerRatio)
+		} else {
+			cfgParams.MainOpenTelemetrySamplerRatio = openTelemetrySamplerRatio
+		}
+	}
+
+	if openTelemetry, exists, err := GetMapKeyAsBool(cfgm.Data, "opentelemetry", cfgm); exists {
+		if err != nil {
+			glog.Error(err)
+		} else {
+			if cfgParams.MainOpenTelemetryExporterEndpoint != "" {
+				cfgParams.MainOpenTelemetryEnabled = openTelemetry
+			} else {
+				glog.Error("ConfigMap Key 'opentelemetry' requires the 'opentelemetry-exporter-endpoint' Key configured, OpenTelemetry will be disabled")
+			}
+		}
+	}
+
 	return cfgParams
 }
 
+// parseConnectionLimitPolicies parses connection limit policies in the format "name=limit".
+// parseForwardedHeadersPolicy parses the policy for the X-Forwarded-* headers.
+// If the policy is invalid, an empty policy is returned along with the error, so that the current policy is kept.
+// If the policy can't be used together with the real IP header, the replace policy is returned along with the error.
+func parseForwardedHeadersPolicy(policy string, realIPHeader string) (string, error) {
+	switch policy {
+	case ForwardedHeadersPolicyAppend:
+		// the real_ip module sets $remote_addr to the client address from X-Forwarded-For,
+		// so appending it to X-Forwarded-For would include the client address twice
+		if strings.EqualFold(realIPHeader, "X-Forwarded-For") {
+			return ForwardedHeadersPolicyReplace, fmt.Errorf("%q can't be used together with the X-Forwarded-For real-ip-header, using %q", policy, ForwardedHeadersPolicyReplace)
+		}
+		return policy, nil
+	case ForwardedHeadersPolicyReplace, ForwardedHeadersPolicyPass:
+		return policy, nil
+	}
+
+	return "", fmt.Errorf("must be one of %q, %q or %q", ForwardedHeadersPolicyAppend, ForwardedHeadersPolicyReplace, ForwardedHeadersPolicyPass)
+}
+
+func parseConnectionLimitPolicies(values []string) ([]ConnectionLimitPolicy, error) {
+	var policies []ConnectionLimitPolicy
+	names := make(map[string]bool)
+
+	for _, v := range values {
+		parts := strings.Split(strings.TrimSpace(v), "=")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q must be in the format name=limit", v)
+		}
+
+		name := strings.TrimSpace(parts[0])
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid name %q: %v", name, strings.Join(errs, ", "))
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate name %q", name)
+		}
+		names[name] = true
+
+		limit, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("invalid limit %q for %q: must be a positive number", parts[1], name)
+		}
+
+		policies = append(policies, ConnectionLimitPolicy{
+			Name:  name,
+			Limit: limit,
+		})
+	}
+
+	return policies, nil
+}
+
+// getConnectionLimitZoneName returns the name of the shared memory zone of a connection limit policy.
+func getConnectionLimitZoneName(policyName string) string {
+	return "conn_limit_" + policyName
+}
+
+// maxAccessLogSampleRate is the maximum sample rate of the access log. The percentages of split_clients
+// have at most two decimal places, so the smallest share of the logged requests is 0.01%.
+const maxAccessLogSampleRate = 10000
+
+// generateAccessLogSamplePercentage generates the percentage of the requests that are logged when one in
+// sampleRate requests is logged. An empty string is returned if all requests are logged.
+func generateAccessLogSamplePercentage(sampleRate int) string {
+	if sampleRate <= 1 {
+		return ""
+	}
+
+	percentage := math.Floor(10000/float64(sampleRate)) / 100
+
+	return strconv.FormatFloat(percentage, 'f', -1, 64) + "%"
+}
+
+// generateAccessLogCondition generates the variable of the if parameter of the access_log directive.
+// The variables are defined in the http context of the main config. An empty string is returned if all requests are logged.
+func generateAccessLogCondition(sampleRate int, non2xxOnly bool) string {
+	sampled := sampleRate > 1
+
+	switch {
+	case sampled && non2xxOnly:
+		return "$access_log_sampled_non_2xx"
+	case sampled:
+		return "$access_log_sampled"
+	case non2xxOnly:
+		return "$access_log_non_2xx"
+	}
+
+	return ""
+}
+
 // GenerateNginxMainConfig generates MainConfig.
 func GenerateNginxMainConfig(staticCfgParams *StaticConfigParams, config *ConfigParams) *version1.MainConfig {
 	nginxCfg := &version1.MainConfig{
 		AccessLogOff:                   config.MainAccessLogOff,
+		AccessLogSamplePercentage:      generateAccessLogSamplePercentage(config.MainAccessLogSampleRate),
+		AccessLogNon2xxOnly:            config.MainAccessLogNon2xxOnly,
+		AccessLogCondition:             generateAccessLogCondition(config.MainAccessLogSampleRate, config.MainAccessLogNon2xxOnly),
+		BrotliLoadModule:               staticCfgParams.EnableBrotli,
+		OIDC:                           staticCfgParams.EnableOIDC,
+		DynamicSSLCertsDirectory:       staticCfgParams.DynamicSSLCertsDirectory,
 		DefaultServerAccessLogOff:      config.DefaultServerAccessLogOff,
 		ErrorLogLevel:                  config.MainErrorLogLevel,
 		HealthStatus:                   staticCfgParams.HealthStatus,
@@ -474,15 +890,31 @@ func GenerateNginxMainConfig(staticCfgParams *StaticConfigParams, config *Config
 		NginxStatus:                    staticCfgParams.NginxStatus,
 		NginxStatusAllowCIDRs:          staticCfgParams.NginxStatusAllowCIDRs,
 		NginxStatusPort:                staticCfgParams.NginxStatusPort,
+		OpenTelemetryEnabled:           staticCfgParams.EnableOpenTelemetry && config.MainOpenTelemetryEnabled,
+		OpenTelemetryExporterEndpoint:  config.MainOpenTelemetryExporterEndpoint,
+		OpenTelemetryLoadModule:        staticCfgParams.EnableOpenTelemetry,
+		OpenTelemetrySamplerPercentage: generateOpenTelemetrySamplerPercentage(config.MainOpenTelemetrySamplerRatio),
+		OpenTelemetryServiceName:       config.MainOpenTelemetryServiceName,
+		OpenTelemetryTrace:             generateOpenTelemetryTrace(config.MainOpenTelemetrySamplerRatio),
 		OpenTracingEnabled:             config.MainOpenTracingEnabled,
 		OpenTracingLoadModule:          config.MainOpenTracingLoadModule,
 		OpenTracingTracer:              config.MainOpenTracingTracer,
 		OpenTracingTracerConfig:        config.MainOpenTracingTracerConfig,
 		ProxyProtocol:                  config.ProxyProtocol,
+		ListenBacklog:                  config.ListenBacklog,
+		ListenReuseport:                config.ListenReuseport,
+		ListenFastopen:                 config.ListenFastopen,
+		ListenDeferred:                 config.ListenDeferred,
+		ListenSoKeepalive:              config.ListenSoKeepalive,
 		ResolverAddresses:              config.ResolverAddresses,
 		ResolverIPV6:                   config.ResolverIPV6,
 		ResolverTimeout:                config.ResolverTimeout,
 		ResolverValid:                  config.ResolverValid,
+		ConnectionLimitZones:           generateConnectionLimitZones(config.ConnectionLimitPolicies, generateString(config.LimitConnZoneSize, defaultRateLimitZoneSize)),
+		ClientHeaderBufferSize:         config.MainClientHeaderBufferSize,
+		LargeClientHeaderBuffers:       config.MainLargeClientHeaderBuffers,
+		ClientHeaderTimeout:            config.MainClientHeaderTimeout,
+		ClientBodyTimeout:              config.MainClientBodyTimeout,
 		ServerNamesHashBucketSize:      config.MainServerNamesHashBucketSize,
 		ServerNamesHashMaxSize:         config.MainServerNamesHashMaxSize,
 		ServerTokens:                   config.ServerTokens,
@@ -490,6 +922,9 @@ func GenerateNginxMainConfig(staticCfgParams *StaticConfigParams, config *Config
 		SSLDHParam:                     config.MainServerSSLDHParam,
 		SSLPreferServerCiphers:         config.MainServerSSLPreferServerCiphers,
 		SSLProtocols:                   config.MainServerSSLProtocols,
+		SSLSessionCache:                config.MainServerSSLSessionCache,
+		SSLSessionTimeout:              config.MainServerSSLSessionTimeout,
+		SSLSessionTickets:              config.MainServerSSLSessionTickets,
 		TLSPassthrough:                 staticCfgParams.TLSPassthrough,
 		StreamLogFormat:                config.MainStreamLogFormat,
 		StreamLogFormatEscaping:        config.MainStreamLogFormatEscaping,
@@ -497,6 +932,10 @@ func GenerateNginxMainConfig(staticCfgParams *StaticConfigParams, config *Config
 		StubStatusOverUnixSocketForOSS: staticCfgParams.StubStatusOverUnixSocketForOSS,
 		VariablesHashBucketSize:        config.VariablesHashBucketSize,
 		VariablesHashMaxSize:           config.VariablesHashMaxSize,
+		MapHashBucketSize:              config.MapHashBucketSize,
+		MapHashMaxSize:                 config.MapHashMaxSize,
+		ProxyHeadersHashBucketSize:     config.ProxyHeadersHashBucketSize,
+		ProxyHeadersHashMaxSize:        config.ProxyHeadersHashMaxSize,
 		WorkerConnections:              config.MainWorkerConnections,
 		WorkerCPUAffinity:              config.MainWorkerCPUAffinity,
 		WorkerProcesses:                config.MainWorkerProcesses,
@@ -505,3 +944,46 @@ func GenerateNginxMainConfig(staticCfgParams *StaticConfigParams, config *Config
 	}
 	return nginxCfg
 }
+
+// generateConnectionLimitZones generates the shared memory zones of the connection limit policies with the zone size.
+// The key of a zone is the name of its policy, so that all the connections of the policy are counted together.
+func generateConnectionLimitZones(policies []ConnectionLimitPolicy, zoneSize string) []version1.ConnectionLimitZone {
+	var zones []version1.ConnectionLimitZone
+	for _, p := range policies {
+		zones = append(zones, version1.ConnectionLimitZone{
+			Name: getConnectionLimitZoneName(p.Name),
+			Key:  p.Name,
+			Size: zoneSize,
+		})
+	}
+	return zones
+}
+
+// generateOpenTelemetryTrace generates the value of the otel_trace directive for the sampler ratio.
+func generateOpenTelemetryTrace(samplerRatio float64) string {
+	percentage := roundOpenTelemetrySamplerPercentage(samplerRatio)
+	if percentage >= 100 {
+		return "on"
+	}
+	if percentage <= 0 {
+		return "off"
+	}
+	return "$otel_trace_sampled"
+}
+
+// generateOpenTelemetrySamplerPercentage generates the percentage of the sampled requests for the split_clients block.
+// An empty string is returned if the sampling is not required.
+func generateOpenTelemetrySamplerPercentage(samplerRatio float64) string {
+	percentage := roundOpenTelemetrySamplerPercentage(samplerRatio)
+	if percentage <= 0 || percentage >= 100 {
+		return ""
+	}
+
+	return strconv.FormatFloat(percentage, 'f', -1, 64) + "%"
+}
+
+// roundOpenTelemetrySamplerPercentage converts the sampler ratio to a percentage with two decimal places,
+// which is the precision supported by the split_clients directive.
+func roundOpenTelemetrySamplerPercentage(samplerRatio float64) float64 {
+	return math.Round(samplerRatio*10000) / 100
+}
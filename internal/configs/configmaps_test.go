@@ -0,0 +1,938 @@
+package configs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseConfigMapWithOpenTelemetry(t *testing.T) {
+	tests := []struct {
+		data             map[string]string
+		expectedEnabled  bool
+		expectedEndpoint string
+		expectedName     string
+		expectedRatio    float64
+		msg              string
+	}{
+		{
+			data:             map[string]string{},
+			expectedEnabled:  false,
+			expectedEndpoint: "",
+			expectedName:     "nginx-ingress",
+			expectedRatio:    1,
+			msg:              "default values",
+		},
+		{
+			data: map[string]string{
+				"opentelemetry":                   "true",
+				"opentelemetry-exporter-endpoint": "otel-collector:4317",
+				"opentelemetry-service-name":      "cafe",
+				"opentelemetry-sampler-ratio":     "0.25",
+			},
+			expectedEnabled:  true,
+			expectedEndpoint: "otel-collector:4317",
+			expectedName:     "cafe",
+			expectedRatio:    0.25,
+			msg:              "all keys",
+		},
+		{
+			data: map[string]string{
+				"opentelemetry": "true",
+			},
+			expectedEnabled:  false,
+			expectedEndpoint: "",
+			expectedName:     "nginx-ingress",
+			expectedRatio:    1,
+			msg:              "missing endpoint",
+		},
+		{
+			data: map[string]string{
+				"opentelemetry":                   "true",
+				"opentelemetry-exporter-endpoint": "otel-collector:4317",
+				"opentelemetry-sampler-ratio":     "1.5",
+			},
+			expectedEnabled:  true,
+			expectedEndpoint: "otel-collector:4317",
+			expectedName:     "nginx-ingress",
+			expectedRatio:    1,
+			msg:              "sampler ratio above 1 is ignored",
+		},
+		{
+			data: map[string]string{
+				"opentelemetry":                   "true",
+				"opentelemetry-exporter-endpoint": "otel-collector:4317",
+				"opentelemetry-sampler-ratio":     "-0.1",
+			},
+			expectedEnabled:  true,
+			expectedEndpoint: "otel-collector:4317",
+			expectedName:     "nginx-ingress",
+			expectedRatio:    1,
+			msg:              "negative sampler ratio is ignored",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = test.data
+
+		result := ParseConfigMap(&cfgm, false)
+
+		if result.MainOpenTelemetryEnabled != test.expectedEnabled {
+			t.Errorf("ParseConfigMap() returned MainOpenTelemetryEnabled %v but expected %v for the case of %s", result.MainOpenTelemetryEnabled, test.expectedEnabled, test.msg)
+		}
+		if result.MainOpenTelemetryExporterEndpoint != test.expectedEndpoint {
+			t.Errorf("ParseConfigMap() returned MainOpenTelemetryExporterEndpoint %q but expected %q for the case of %s", result.MainOpenTelemetryExporterEndpoint, test.expectedEndpoint, test.msg)
+		}
+		if result.MainOpenTelemetryServiceName != test.expectedName {
+			t.Errorf("ParseConfigMap() returned MainOpenTelemetryServiceName %q but expected %q for the case of %s", result.MainOpenTelemetryServiceName, test.expectedName, test.msg)
+		}
+		if result.MainOpenTelemetrySamplerRatio != test.expectedRatio {
+			t.Errorf("ParseConfigMap() returned MainOpenTelemetrySamplerRatio %v but expected %v for the case of %s", result.MainOpenTelemetrySamplerRatio, test.expectedRatio, test.msg)
+		}
+	}
+}
+
+func TestParseConfigMapWithErrorLogLevel(t *testing.T) {
+	tests := []struct {
+		data     map[string]string
+		expected string
+		msg      string
+	}{
+		{
+			data:     map[string]string{},
+			expected: "notice",
+			msg:      "default level",
+		},
+		{
+			data:     map[string]string{"error-log-level": "warn"},
+			expected: "warn",
+			msg:      "valid level",
+		},
+		{
+			data:     map[string]string{"error-log-level": "debug"},
+			expected: "debug",
+			msg:      "debug level",
+		},
+		{
+			data:     map[string]string{"error-log-level": "verbose"},
+			expected: "",
+			msg:      "invalid level",
+		},
+		{
+			data:     map[string]string{"error-log-level": "warn;"},
+			expected: "",
+			msg:      "level with a semicolon",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = test.data
+
+		result := ParseConfigMap(&cfgm, false)
+		if result.MainErrorLogLevel != test.expected {
+			t.Errorf("ParseConfigMap() returned MainErrorLogLevel %q but expected %q for the case of %s", result.MainErrorLogLevel, test.expected, test.msg)
+		}
+	}
+}
+
+func TestParseConfigMapWithSSLSession(t *testing.T) {
+	tests := []struct {
+		data            map[string]string
+		expectedCache   string
+		expectedTimeout string
+		expectedTickets string
+		msg             string
+	}{
+		{
+			data:            map[string]string{},
+			expectedCache:   "",
+			expectedTimeout: "",
+			expectedTickets: "",
+			msg:             "no keys",
+		},
+		{
+			data: map[string]string{
+				"ssl-session-cache":   "shared:SSL:10m",
+				"ssl-session-timeout": "1h",
+				"ssl-session-tickets": "False",
+			},
+			expectedCache:   "shared:SSL:10m",
+			expectedTimeout: "1h",
+			expectedTickets: "off",
+			msg:             "valid keys",
+		},
+		{
+			data: map[string]string{
+				"ssl-session-cache":   "builtin:1000  shared:SSL:10m",
+				"ssl-session-tickets": "true",
+			},
+			expectedCache:   "builtin:1000 shared:SSL:10m",
+			expectedTimeout: "",
+			expectedTickets: "on",
+			msg:             "builtin and shared cache",
+		},
+		{
+			data: map[string]string{
+				"ssl-session-cache":   "shared:SSL:10g",
+				"ssl-session-timeout": "1 hour",
+				"ssl-session-tickets": "no",
+			},
+			expectedCache:   "",
+			expectedTimeout: "",
+			expectedTickets: "",
+			msg:             "invalid keys",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = test.data
+
+		result := ParseConfigMap(&cfgm, false)
+		if result.MainServerSSLSessionCache != test.expectedCache {
+			t.Errorf("ParseConfigMap() returned MainServerSSLSessionCache %q but expected %q for the case of %s", result.MainServerSSLSessionCache, test.expectedCache, test.msg)
+		}
+		if result.MainServerSSLSessionTimeout != test.expectedTimeout {
+			t.Errorf("ParseConfigMap() returned MainServerSSLSessionTimeout %q but expected %q for the case of %s", result.MainServerSSLSessionTimeout, test.expectedTimeout, test.msg)
+		}
+		if result.MainServerSSLSessionTickets != test.expectedTickets {
+			t.Errorf("ParseConfigMap() returned MainServerSSLSessionTickets %q but expected %q for the case of %s", result.MainServerSSLSessionTickets, test.expectedTickets, test.msg)
+		}
+	}
+}
+
+func TestParseConfigMapWithClientHeaderBuffers(t *testing.T) {
+	tests := []struct {
+		data                 map[string]string
+		expectedBufferSize   string
+		expectedLargeBuffers string
+		msg                  string
+	}{
+		{
+			data:                 map[string]string{},
+			expectedBufferSize:   "",
+			expectedLargeBuffers: "",
+			msg:                  "no keys",
+		},
+		{
+			data: map[string]string{
+				"client-header-buffer-size":   "2k",
+				"large-client-header-buffers": "4  16k",
+			},
+			expectedBufferSize:   "2k",
+			expectedLargeBuffers: "4 16k",
+			msg:                  "valid keys",
+		},
+		{
+			data: map[string]string{
+				"client-header-buffer-size":   "2kb",
+				"large-client-header-buffers": "16k",
+			},
+			expectedBufferSize:   "",
+			expectedLargeBuffers: "",
+			msg:                  "invalid keys",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = test.data
+
+		result := ParseConfigMap(&cfgm, false)
+		if result.MainClientHeaderBufferSize != test.expectedBufferSize {
+			t.Errorf("ParseConfigMap() returned MainClientHeaderBufferSize %q but expected %q for the case of %s", result.MainClientHeaderBufferSize, test.expectedBufferSize, test.msg)
+		}
+		if result.MainLargeClientHeaderBuffers != test.expectedLargeBuffers {
+			t.Errorf("ParseConfigMap() returned MainLargeClientHeaderBuffers %q but expected %q for the case of %s", result.MainLargeClientHeaderBuffers, test.expectedLargeBuffers, test.msg)
+		}
+	}
+}
+
+func TestParseConfigMapWithClientTimeouts(t *testing.T) {
+	tests := []struct {
+		data                  map[string]string
+		expectedHeaderTimeout string
+		expectedBodyTimeout   string
+		msg                   string
+	}{
+		{
+			data:                  map[string]string{},
+			expectedHeaderTimeout: "",
+			expectedBodyTimeout:   "",
+			msg:                   "no keys",
+		},
+		{
+			data: map[string]string{
+				"client-header-timeout": "10s",
+				"client-body-timeout":   "1m",
+			},
+			expectedHeaderTimeout: "10s",
+			expectedBodyTimeout:   "1m",
+			msg:                   "valid keys",
+		},
+		{
+			data: map[string]string{
+				"client-header-timeout": "10x",
+				"client-body-timeout":   "1 minute",
+			},
+			expectedHeaderTimeout: "",
+			expectedBodyTimeout:   "",
+			msg:                   "invalid keys",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = test.data
+
+		result := ParseConfigMap(&cfgm, false)
+		if result.MainClientHeaderTimeout != test.expectedHeaderTimeout {
+			t.Errorf("ParseConfigMap() returned MainClientHeaderTimeout %q but expected %q for the case of %s", result.MainClientHeaderTimeout, test.expectedHeaderTimeout, test.msg)
+		}
+		if result.MainClientBodyTimeout != test.expectedBodyTimeout {
+			t.Errorf("ParseConfigMap() returned MainClientBodyTimeout %q but expected %q for the case of %s", result.MainClientBodyTimeout, test.expectedBodyTimeout, test.msg)
+		}
+	}
+}
+
+func TestParseConfigMapWithResolverUpstreamValid(t *testing.T) {
+	tests := []struct {
+		data     map[string]string
+		isPlus   bool
+		expected string
+		msg      string
+	}{
+		{
+			data:     map[string]string{"resolver-upstream-valid": "30s"},
+			isPlus:   true,
+			expected: "30s",
+			msg:      "valid time",
+		},
+		{
+			data:     map[string]string{"resolver-upstream-valid": "30 seconds"},
+			isPlus:   true,
+			expected: "",
+			msg:      "invalid time",
+		},
+		{
+			data:     map[string]string{"resolver-upstream-valid": "30s"},
+			isPlus:   false,
+			expected: "",
+			msg:      "NGINX OSS",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = test.data
+
+		result := ParseConfigMap(&cfgm, test.isPlus)
+		if result.ResolverUpstreamValid != test.expected {
+			t.Errorf("ParseConfigMap() returned ResolverUpstreamValid %q but expected %q for the case of %s", result.ResolverUpstreamValid, test.expected, test.msg)
+		}
+	}
+}
+
+func TestParseConfigMapWithAccessLogSampling(t *testing.T) {
+	tests := []struct {
+		data               map[string]string
+		expectedRate       int
+		expectedNon2xxOnly bool
+		msg                string
+	}{
+		{
+			data:         map[string]string{},
+			expectedRate: 0,
+			msg:          "no sampling",
+		},
+		{
+			data:               map[string]string{"access-log-sample-rate": "10", "access-log-non-2xx-only": "true"},
+			expectedRate:       10,
+			expectedNon2xxOnly: true,
+			msg:                "valid sampling",
+		},
+		{
+			data:         map[string]string{"access-log-sample-rate": "0"},
+			expectedRate: 0,
+			msg:          "zero sample rate",
+		},
+		{
+			data:         map[string]string{"access-log-sample-rate": "10001"},
+			expectedRate: 0,
+			msg:          "too big sample rate",
+		},
+		{
+			data:         map[string]string{"access-log-sample-rate": "one"},
+			expectedRate: 0,
+			msg:          "invalid sample rate",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = test.data
+
+		result := ParseConfigMap(&cfgm, false)
+		if result.MainAccessLogSampleRate != test.expectedRate {
+			t.Errorf("ParseConfigMap() returned MainAccessLogSampleRate %d but expected %d for the case of %s", result.MainAccessLogSampleRate, test.expectedRate, test.msg)
+		}
+		if result.MainAccessLogNon2xxOnly != test.expectedNon2xxOnly {
+			t.Errorf("ParseConfigMap() returned MainAccessLogNon2xxOnly %v but expected %v for the case of %s", result.MainAccessLogNon2xxOnly, test.expectedNon2xxOnly, test.msg)
+		}
+	}
+}
+
+func TestGenerateAccessLogSamplePercentage(t *testing.T) {
+	tests := []struct {
+		sampleRate int
+		expected   string
+	}{
+		{
+			sampleRate: 0,
+			expected:   "",
+		},
+		{
+			sampleRate: 1,
+			expected:   "",
+		},
+		{
+			sampleRate: 2,
+			expected:   "50%",
+		},
+		{
+			sampleRate: 3,
+			expected:   "33.33%",
+		},
+		{
+			sampleRate: 10000,
+			expected:   "0.01%",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateAccessLogSamplePercentage(test.sampleRate)
+		if result != test.expected {
+			t.Errorf("generateAccessLogSamplePercentage(%d) returned %q but expected %q", test.sampleRate, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateAccessLogCondition(t *testing.T) {
+	tests := []struct {
+		sampleRate int
+		non2xxOnly bool
+		expected   string
+	}{
+		{
+			sampleRate: 1,
+			non2xxOnly: false,
+			expected:   "",
+		},
+		{
+			sampleRate: 10,
+			non2xxOnly: false,
+			expected:   "$access_log_sampled",
+		},
+		{
+			sampleRate: 0,
+			non2xxOnly: true,
+			expected:   "$access_log_non_2xx",
+		},
+		{
+			sampleRate: 10,
+			non2xxOnly: true,
+			expected:   "$access_log_sampled_non_2xx",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateAccessLogCondition(test.sampleRate, test.non2xxOnly)
+		if result != test.expected {
+			t.Errorf("generateAccessLogCondition(%d, %v) returned %q but expected %q", test.sampleRate, test.non2xxOnly, result, test.expected)
+		}
+	}
+}
+
+func TestParseConfigMapWithConnectionLimitPolicies(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []ConnectionLimitPolicy
+		msg      string
+	}{
+		{
+			value: "shared-backend=100",
+			expected: []ConnectionLimitPolicy{
+				{Name: "shared-backend", Limit: 100},
+			},
+			msg: "one policy",
+		},
+		{
+			value: "shared-backend=100, legacy=10",
+			expected: []ConnectionLimitPolicy{
+				{Name: "shared-backend", Limit: 100},
+				{Name: "legacy", Limit: 10},
+			},
+			msg: "two policies",
+		},
+		{
+			value:    "shared-backend",
+			expected: nil,
+			msg:      "missing limit",
+		},
+		{
+			value:    "shared-backend=0",
+			expected: nil,
+			msg:      "zero limit",
+		},
+		{
+			value:    "Shared_Backend=100",
+			expected: nil,
+			msg:      "invalid name",
+		},
+		{
+			value:    "shared-backend=100,shared-backend=10",
+			expected: nil,
+			msg:      "duplicate name",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = map[string]string{"connection-limit-policies": test.value}
+
+		result := ParseConfigMap(&cfgm, false)
+		if !reflect.DeepEqual(result.ConnectionLimitPolicies, test.expected) {
+			t.Errorf("ParseConfigMap() returned ConnectionLimitPolicies %v but expected %v for the case of %s", result.ConnectionLimitPolicies, test.expected, test.msg)
+		}
+	}
+}
+
+func TestParseConfigMapWithLimitZoneSizes(t *testing.T) {
+	tests := []struct {
+		reqValue     string
+		connValue    string
+		expectedReq  string
+		expectedConn string
+		msg          string
+	}{
+		{
+			reqValue:     "20m",
+			connValue:    "512k",
+			expectedReq:  "20m",
+			expectedConn: "512k",
+			msg:          "valid sizes",
+		},
+		{
+			reqValue:     "32k",
+			connValue:    "32768",
+			expectedReq:  "32k",
+			expectedConn: "32768",
+			msg:          "smallest sizes",
+		},
+		{
+			reqValue:     "16k",
+			connValue:    "1000",
+			expectedReq:  "",
+			expectedConn: "",
+			msg:          "sizes smaller than the minimum",
+		},
+		{
+			reqValue:     "10g",
+			connValue:    "large",
+			expectedReq:  "",
+			expectedConn: "",
+			msg:          "invalid sizes",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = map[string]string{
+			"limit-req-zone-size":  test.reqValue,
+			"limit-conn-zone-size": test.connValue,
+		}
+
+		result := ParseConfigMap(&cfgm, false)
+		if result.LimitReqZoneSize != test.expectedReq {
+			t.Errorf("ParseConfigMap() returned LimitReqZoneSize %q but expected %q for the case of %s", result.LimitReqZoneSize, test.expectedReq, test.msg)
+		}
+		if result.LimitConnZoneSize != test.expectedConn {
+			t.Errorf("ParseConfigMap() returned LimitConnZoneSize %q but expected %q for the case of %s", result.LimitConnZoneSize, test.expectedConn, test.msg)
+		}
+	}
+}
+
+func TestGenerateNginxMainConfigWithConnectionLimitPolicies(t *testing.T) {
+	cfgParams := NewDefaultConfigParams()
+	cfgParams.ConnectionLimitPolicies = []ConnectionLimitPolicy{
+		{Name: "shared-backend", Limit: 100},
+	}
+
+	expected := []version1.ConnectionLimitZone{
+		{Name: "conn_limit_shared-backend", Key: "shared-backend", Size: "10m"},
+	}
+
+	result := GenerateNginxMainConfig(&StaticConfigParams{}, cfgParams)
+	if !reflect.DeepEqual(result.ConnectionLimitZones, expected) {
+		t.Errorf("GenerateNginxMainConfig() returned ConnectionLimitZones %v but expected %v", result.ConnectionLimitZones, expected)
+	}
+
+	cfgParams.LimitConnZoneSize = "20m"
+	expected[0].Size = "20m"
+
+	result = GenerateNginxMainConfig(&StaticConfigParams{}, cfgParams)
+	if !reflect.DeepEqual(result.ConnectionLimitZones, expected) {
+		t.Errorf("GenerateNginxMainConfig() returned ConnectionLimitZones %v but expected %v for the limit-conn-zone-size key", result.ConnectionLimitZones, expected)
+	}
+}
+
+func TestParseConfigMapWithListenParameters(t *testing.T) {
+	tests := []struct {
+		data              map[string]string
+		expectedBacklog   int
+		expectedReuseport bool
+		msg               string
+	}{
+		{
+			data: map[string]string{
+				"listen-backlog":   "1024",
+				"listen-reuseport": "True",
+			},
+			expectedBacklog:   1024,
+			expectedReuseport: true,
+			msg:               "valid parameters",
+		},
+		{
+			data: map[string]string{
+				"listen-backlog": "0",
+			},
+			expectedBacklog:   0,
+			expectedReuseport: false,
+			msg:               "zero backlog",
+		},
+		{
+			data: map[string]string{
+				"listen-backlog":   "-1",
+				"listen-reuseport": "yes",
+			},
+			expectedBacklog:   0,
+			expectedReuseport: false,
+			msg:               "invalid parameters",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = test.data
+
+		result := ParseConfigMap(&cfgm, false)
+		if result.ListenBacklog != test.expectedBacklog {
+			t.Errorf("ParseConfigMap() returned ListenBacklog %v but expected %v for the case of %s", result.ListenBacklog, test.expectedBacklog, test.msg)
+		}
+		if result.ListenReuseport != test.expectedReuseport {
+			t.Errorf("ParseConfigMap() returned ListenReuseport %v but expected %v for the case of %s", result.ListenReuseport, test.expectedReuseport, test.msg)
+		}
+
+		mainCfg := GenerateNginxMainConfig(&StaticConfigParams{}, result)
+		if mainCfg.ListenBacklog != test.expectedBacklog || mainCfg.ListenReuseport != test.expectedReuseport {
+			t.Errorf("GenerateNginxMainConfig() returned ListenBacklog %v and ListenReuseport %v for the case of %s",
+				mainCfg.ListenBacklog, mainCfg.ListenReuseport, test.msg)
+		}
+	}
+}
+
+func TestParseConfigMapWithTCPListenParameters(t *testing.T) {
+	tests := []struct {
+		data                map[string]string
+		expectedFastopen    int
+		expectedDeferred    bool
+		expectedSoKeepalive string
+		msg                 string
+	}{
+		{
+			data: map[string]string{
+				"listen-fastopen":     "256",
+				"listen-deferred":     "True",
+				"listen-so-keepalive": "30m::10",
+			},
+			expectedFastopen:    256,
+			expectedDeferred:    true,
+			expectedSoKeepalive: "30m::10",
+			msg:                 "valid parameters",
+		},
+		{
+			data: map[string]string{
+				"listen-fastopen":     "0",
+				"listen-deferred":     "yes",
+				"listen-so-keepalive": "30m",
+			},
+			expectedFastopen:    0,
+			expectedDeferred:    false,
+			expectedSoKeepalive: "",
+			msg:                 "invalid parameters",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = test.data
+
+		result := ParseConfigMap(&cfgm, false)
+		if result.ListenFastopen != test.expectedFastopen {
+			t.Errorf("ParseConfigMap() returned ListenFastopen %v but expected %v for the case of %s", result.ListenFastopen, test.expectedFastopen, test.msg)
+		}
+		if result.ListenDeferred != test.expectedDeferred {
+			t.Errorf("ParseConfigMap() returned ListenDeferred %v but expected %v for the case of %s", result.ListenDeferred, test.expectedDeferred, test.msg)
+		}
+		if result.ListenSoKeepalive != test.expectedSoKeepalive {
+			t.Errorf("ParseConfigMap() returned ListenSoKeepalive %q but expected %q for the case of %s", result.ListenSoKeepalive, test.expectedSoKeepalive, test.msg)
+		}
+
+		mainCfg := GenerateNginxMainConfig(&StaticConfigParams{}, result)
+		if mainCfg.ListenFastopen != test.expectedFastopen || mainCfg.ListenDeferred != test.expectedDeferred || mainCfg.ListenSoKeepalive != test.expectedSoKeepalive {
+			t.Errorf("GenerateNginxMainConfig() returned ListenFastopen %v, ListenDeferred %v and ListenSoKeepalive %q for the case of %s",
+				mainCfg.ListenFastopen, mainCfg.ListenDeferred, mainCfg.ListenSoKeepalive, test.msg)
+		}
+	}
+}
+
+func TestGenerateNginxMainConfigWithOpenTelemetry(t *testing.T) {
+	cfgParams := NewDefaultConfigParams()
+	cfgParams.MainOpenTelemetryEnabled = true
+	cfgParams.MainOpenTelemetryExporterEndpoint = "otel-collector:4317"
+	cfgParams.MainOpenTelemetrySamplerRatio = 0.1
+
+	tests := []struct {
+		staticCfgParams    *StaticConfigParams
+		expectedLoadModule bool
+		expectedEnabled    bool
+	}{
+		{
+			staticCfgParams:    &StaticConfigParams{EnableOpenTelemetry: true},
+			expectedLoadModule: true,
+			expectedEnabled:    true,
+		},
+		{
+			staticCfgParams:    &StaticConfigParams{EnableOpenTelemetry: false},
+			expectedLoadModule: false,
+			expectedEnabled:    false,
+		},
+	}
+
+	for _, test := range tests {
+		result := GenerateNginxMainConfig(test.staticCfgParams, cfgParams)
+
+		if result.OpenTelemetryLoadModule != test.expectedLoadModule {
+			t.Errorf("GenerateNginxMainConfig() returned OpenTelemetryLoadModule %v but expected %v", result.OpenTelemetryLoadModule, test.expectedLoadModule)
+		}
+		if result.OpenTelemetryEnabled != test.expectedEnabled {
+			t.Errorf("GenerateNginxMainConfig() returned OpenTelemetryEnabled %v but expected %v", result.OpenTelemetryEnabled, test.expectedEnabled)
+		}
+		if result.OpenTelemetrySamplerPercentage != "10%" {
+			t.Errorf("GenerateNginxMainConfig() returned OpenTelemetrySamplerPercentage %q but expected %q", result.OpenTelemetrySamplerPercentage, "10%")
+		}
+		if result.OpenTelemetryTrace != "$otel_trace_sampled" {
+			t.Errorf("GenerateNginxMainConfig() returned OpenTelemetryTrace %q but expected %q", result.OpenTelemetryTrace, "$otel_trace_sampled")
+		}
+	}
+}
+
+func TestGenerateOpenTelemetryTrace(t *testing.T) {
+	tests := []struct {
+		samplerRatio float64
+		expected     string
+	}{
+		{
+			samplerRatio: 1,
+			expected:     "on",
+		},
+		{
+			samplerRatio: 0,
+			expected:     "off",
+		},
+		{
+			samplerRatio: 0.00001,
+			expected:     "off",
+		},
+		{
+			samplerRatio: 0.5,
+			expected:     "$otel_trace_sampled",
+		},
+		{
+			samplerRatio: 0.99999,
+			expected:     "on",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateOpenTelemetryTrace(test.samplerRatio)
+		if result != test.expected {
+			t.Errorf("generateOpenTelemetryTrace(%v) returned %q but expected %q", test.samplerRatio, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateOpenTelemetrySamplerPercentage(t *testing.T) {
+	tests := []struct {
+		samplerRatio float64
+		expected     string
+	}{
+		{
+			samplerRatio: 1,
+			expected:     "",
+		},
+		{
+			samplerRatio: 0,
+			expected:     "",
+		},
+		{
+			samplerRatio: 0.5,
+			expected:     "50%",
+		},
+		{
+			samplerRatio: 0.07,
+			expected:     "7%",
+		},
+		{
+			samplerRatio: 0.12345,
+			expected:     "12.35%",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateOpenTelemetrySamplerPercentage(test.samplerRatio)
+		if result != test.expected {
+			t.Errorf("generateOpenTelemetrySamplerPercentage(%v) returned %q but expected %q", test.samplerRatio, result, test.expected)
+		}
+	}
+}
+
+func TestParseNamespaceConfigMap(t *testing.T) {
+	tests := []struct {
+		data     map[string]string
+		expected *NamespaceConfigParams
+		msg      string
+	}{
+		{
+			data:     map[string]string{},
+			expected: &NamespaceConfigParams{},
+			msg:      "no keys",
+		},
+		{
+			data: map[string]string{
+				"proxy-connect-timeout": "10s",
+				"proxy-read-timeout":    "20s",
+				"proxy-send-timeout":    "30s",
+				"client-max-body-size":  "5m",
+			},
+			expected: &NamespaceConfigParams{
+				ProxyConnectTimeout: "10s",
+				ProxyReadTimeout:    "20s",
+				ProxySendTimeout:    "30s",
+				ClientMaxBodySize:   "5m",
+			},
+			msg: "all keys",
+		},
+		{
+			data: map[string]string{
+				"proxy-read-timeout": "20s",
+				"server-tokens":      "off",
+			},
+			expected: &NamespaceConfigParams{
+				ProxyReadTimeout: "20s",
+			},
+			msg: "unsupported keys are ignored",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := &v1.ConfigMap{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "nginx-config-overrides",
+				Namespace: "default",
+			},
+			Data: test.data,
+		}
+
+		result := ParseNamespaceConfigMap(cfgm)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("ParseNamespaceConfigMap() returned %+v but expected %+v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestParseConfigMapWithForwardedHeadersPolicy(t *testing.T) {
+	tests := []struct {
+		data     map[string]string
+		expected string
+		msg      string
+	}{
+		{
+			data:     map[string]string{},
+			expected: ForwardedHeadersPolicyAppend,
+			msg:      "default policy",
+		},
+		{
+			data: map[string]string{
+				"forwarded-headers-policy": "append",
+			},
+			expected: ForwardedHeadersPolicyAppend,
+			msg:      "append policy",
+		},
+		{
+			data: map[string]string{
+				"forwarded-headers-policy": "replace",
+			},
+			expected: ForwardedHeadersPolicyReplace,
+			msg:      "replace policy",
+		},
+		{
+			data: map[string]string{
+				"forwarded-headers-policy": "pass",
+				"set-real-ip-from":         "10.0.0.0/8",
+			},
+			expected: ForwardedHeadersPolicyPass,
+			msg:      "pass policy",
+		},
+		{
+			data: map[string]string{
+				"forwarded-headers-policy": "append",
+				"real-ip-header":           "X-Forwarded-For",
+				"set-real-ip-from":         "10.0.0.0/8",
+			},
+			expected: ForwardedHeadersPolicyReplace,
+			msg:      "append policy with the X-Forwarded-For real IP header",
+		},
+		{
+			data: map[string]string{
+				"forwarded-headers-policy": "pass",
+				"real-ip-header":           "X-Forwarded-For",
+				"set-real-ip-from":         "10.0.0.0/8",
+			},
+			expected: ForwardedHeadersPolicyPass,
+			msg:      "pass policy with the X-Forwarded-For real IP header",
+		},
+		{
+			data: map[string]string{
+				"forwarded-headers-policy": "override",
+			},
+			expected: ForwardedHeadersPolicyAppend,
+			msg:      "invalid policy",
+		},
+	}
+
+	for _, test := range tests {
+		cfgm := configMap
+		cfgm.Data = test.data
+
+		result := ParseConfigMap(&cfgm, false)
+		if result.ForwardedHeadersPolicy != test.expected {
+			t.Errorf("ParseConfigMap() returned ForwardedHeadersPolicy %q but expected %q for the case of %s", result.ForwardedHeadersPolicy, test.expected, test.msg)
+		}
+	}
+}
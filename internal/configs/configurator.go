@@ -7,8 +7,11 @@ import (
 	"encoding/pem"
 	"fmt"
 	"os"
+	"reflect"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spiffe/go-spiffe/workload"
 
@@ -19,6 +22,7 @@ import (
 	api_v1 "k8s.io/api/core/v1"
 	extensions "k8s.io/api/extensions/v1beta1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
 	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
@@ -28,6 +32,20 @@ import (
 const pemFileNameForMissingTLSSecret = "/etc/nginx/secrets/default"
 const pemFileNameForWildcardTLSSecret = "/etc/nginx/secrets/wildcard"
 
+// dynamicSSLCertificateVariable is the variable with the file of the TLS certificate and key that njs looks up by SNI
+// in the directory of the dynamic certificates.
+const dynamicSSLCertificateVariable = "$dynamic_ssl_certificate"
+
+// Policies for VirtualServers that reference a TLS Secret that doesn't exist or is invalid.
+const (
+	// MissingTLSSecretPolicyError rejects such VirtualServers.
+	MissingTLSSecretPolicyError = "error"
+	// MissingTLSSecretPolicyIgnore configures such VirtualServers so that NGINX breaks any attempt to establish a TLS connection.
+	MissingTLSSecretPolicyIgnore = "ignore"
+	// MissingTLSSecretPolicySelfSigned configures such VirtualServers with a temporary self-signed certificate.
+	MissingTLSSecretPolicySelfSigned = "self-signed"
+)
+
 // DefaultServerSecretName is the filename of the Secret with a TLS cert and a key for the default server.
 const DefaultServerSecretName = "default"
 
@@ -37,6 +55,12 @@ const WildcardSecretName = "wildcard"
 // JWTKeyKey is the key of the data field of a Secret where the JWK must be stored.
 const JWTKeyKey = "jwk"
 
+// OIDCClientSecretKey is the key of the data field of a Secret where the OIDC client secret must be stored.
+const OIDCClientSecretKey = "client-secret"
+
+// SessionTicketKeyKey is the key of the data field of a Secret where the TLS session ticket key must be stored.
+const SessionTicketKeyKey = "ticket-key"
+
 // SPIFFE filenames and modes
 const (
 	spiffeCertFileName   = "spiffe_cert.pem"
@@ -60,40 +84,73 @@ type Configurator struct {
 	templateExecutor    *version1.TemplateExecutor
 	templateExecutorV2  *version2.TemplateExecutor
 	ingresses           map[string]*IngressEx
-	minions             map[string]map[string]bool
+	minions             map[string]map[string]*IngressEx
 	virtualServers      map[string]*VirtualServerEx
 	tlsPassthroughPairs map[string]tlsPassthroughPair
-	isWildcardEnabled   bool
-	isPlus              bool
+	// configContents are the contents of the config files of the Ingress resources and VirtualServers, so that the last
+	// applied config of a resource can be restored when its update breaks the configuration.
+	configContents map[string][]byte
+	// selfSignedCertificates are the temporary self-signed certificates of the VirtualServers with missing TLS Secrets
+	// generated for the self-signed policy, keyed by the names of the config files of the VirtualServers.
+	selfSignedCertificates map[string]selfSignedCertificate
+	// cacheZones are the names of the cache zones in the applied configs of the VirtualServers,
+	// keyed by the names of the config files of the VirtualServers.
+	cacheZones map[string][]string
+	// streamConfigs are the contents of the stream config files of the TransportServers and tlsPassthroughHostsConfig
+	// is the content of the TLS Passthrough hosts config file. A TransportServer change that doesn't change them
+	// neither rewrites the files nor reloads NGINX, so that the HTTP traffic is not affected by a needless reload.
+	streamConfigs             map[string][]byte
+	tlsPassthroughHostsConfig []byte
+	isWildcardEnabled         bool
+	isPlus                    bool
+	// hashBucketSize is the smaller of the server names and map hash bucket sizes of the current main config
+	hashBucketSize uint64
+	// proxyHeadersHashSizes are the proxy headers hash sizes of the current main config
+	proxyHeadersHashSizes proxyHeadersHashSizes
+	// mux serializes the generation of the config and the reloads of NGINX when the resources are synced concurrently
+	mux sync.Mutex
 }
 
 // NewConfigurator creates a new Configurator.
 func NewConfigurator(nginxManager nginx.Manager, staticCfgParams *StaticConfigParams, config *ConfigParams, globalCfgParams *GlobalConfigParams,
 	templateExecutor *version1.TemplateExecutor, templateExecutorV2 *version2.TemplateExecutor, isPlus bool, isWildcardEnabled bool) *Configurator {
+	mainCfg := GenerateNginxMainConfig(staticCfgParams, config)
 	cnf := Configurator{
-		nginxManager:        nginxManager,
-		staticCfgParams:     staticCfgParams,
-		cfgParams:           config,
-		globalCfgParams:     globalCfgParams,
-		ingresses:           make(map[string]*IngressEx),
-		virtualServers:      make(map[string]*VirtualServerEx),
-		templateExecutor:    templateExecutor,
-		templateExecutorV2:  templateExecutorV2,
-		minions:             make(map[string]map[string]bool),
-		tlsPassthroughPairs: make(map[string]tlsPassthroughPair),
-		isPlus:              isPlus,
-		isWildcardEnabled:   isWildcardEnabled,
+		nginxManager:           nginxManager,
+		staticCfgParams:        staticCfgParams,
+		cfgParams:              config,
+		globalCfgParams:        globalCfgParams,
+		ingresses:              make(map[string]*IngressEx),
+		virtualServers:         make(map[string]*VirtualServerEx),
+		templateExecutor:       templateExecutor,
+		templateExecutorV2:     templateExecutorV2,
+		minions:                make(map[string]map[string]*IngressEx),
+		tlsPassthroughPairs:    make(map[string]tlsPassthroughPair),
+		configContents:         make(map[string][]byte),
+		selfSignedCertificates: make(map[string]selfSignedCertificate),
+		cacheZones:             make(map[string][]string),
+		streamConfigs:          make(map[string][]byte),
+		isPlus:                 isPlus,
+		isWildcardEnabled:      isWildcardEnabled,
+		hashBucketSize:         getMinHashBucketSize(mainCfg),
+		proxyHeadersHashSizes:  getProxyHeadersHashSizes(mainCfg),
 	}
 	return &cnf
 }
 
 // AddOrUpdateDHParam creates a dhparam file with the content of the string.
 func (cnf *Configurator) AddOrUpdateDHParam(content string) (string, error) {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	return cnf.nginxManager.CreateDHParam(content)
 }
 
 // AddOrUpdateIngress adds or updates NGINX configuration for the Ingress resource.
 func (cnf *Configurator) AddOrUpdateIngress(ingEx *IngressEx) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	if err := cnf.addOrUpdateIngress(ingEx); err != nil {
 		return fmt.Errorf("Error adding or updating ingress %v/%v: %v", ingEx.Ingress.Namespace, ingEx.Ingress.Name, err)
 	}
@@ -106,25 +163,50 @@ func (cnf *Configurator) AddOrUpdateIngress(ingEx *IngressEx) error {
 }
 
 func (cnf *Configurator) addOrUpdateIngress(ingEx *IngressEx) error {
+	update, err := cnf.generateIngressConfigUpdate(ingEx)
+	if err != nil {
+		return err
+	}
+
+	if err := cnf.applyConfigUpdates([]configUpdate{update})[0]; err != nil {
+		return err
+	}
+
+	return cnf.updateMainConfigForHashSizes()
+}
+
+// generateIngressConfigUpdate generates the config of the Ingress resource.
+func (cnf *Configurator) generateIngressConfigUpdate(ingEx *IngressEx) (configUpdate, error) {
 	pems := cnf.updateTLSSecrets(ingEx)
 	jwtKeyFileName := cnf.updateJWKSecret(ingEx)
 
 	isMinion := false
-	nginxCfg := generateNginxCfg(ingEx, pems, isMinion, cnf.cfgParams, cnf.isPlus, cnf.IsResolverConfigured(), jwtKeyFileName, cnf.staticCfgParams)
+	nginxCfg := generateNginxCfg(ingEx, pems, isMinion, cnf.cfgParams, cnf.isPlus, cnf.isResolverConfigured(), jwtKeyFileName, cnf.staticCfgParams)
 	name := objectMetaToFileName(&ingEx.Ingress.ObjectMeta)
 	content, err := cnf.templateExecutor.ExecuteIngressConfigTemplate(&nginxCfg)
 	if err != nil {
-		return fmt.Errorf("Error generating Ingress Config %v: %v", name, err)
+		return configUpdate{}, fmt.Errorf("Error generating Ingress Config %v: %v", name, err)
 	}
-	cnf.nginxManager.CreateConfig(name, content)
 
-	cnf.ingresses[name] = ingEx
+	update := configUpdate{
+		kind:    "Ingress",
+		name:    name,
+		content: content,
+		result: func(err error) {
+			if err == nil {
+				cnf.ingresses[name] = ingEx
+			}
+		},
+	}
 
-	return nil
+	return update, nil
 }
 
 // AddOrUpdateMergeableIngress adds or updates NGINX configuration for the Ingress resources with Mergeable Types.
 func (cnf *Configurator) AddOrUpdateMergeableIngress(mergeableIngs *MergeableIngresses) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	if err := cnf.addOrUpdateMergeableIngress(mergeableIngs); err != nil {
 		return fmt.Errorf("Error when adding or updating ingress %v/%v: %v", mergeableIngs.Master.Ingress.Namespace, mergeableIngs.Master.Ingress.Name, err)
 	}
@@ -137,6 +219,20 @@ func (cnf *Configurator) AddOrUpdateMergeableIngress(mergeableIngs *MergeableIng
 }
 
 func (cnf *Configurator) addOrUpdateMergeableIngress(mergeableIngs *MergeableIngresses) error {
+	update, err := cnf.generateMergeableIngressConfigUpdate(mergeableIngs)
+	if err != nil {
+		return err
+	}
+
+	if err := cnf.applyConfigUpdates([]configUpdate{update})[0]; err != nil {
+		return err
+	}
+
+	return cnf.updateMainConfigForHashSizes()
+}
+
+// generateMergeableIngressConfigUpdate generates the config of the Ingress resources with Mergeable Types.
+func (cnf *Configurator) generateMergeableIngressConfigUpdate(mergeableIngs *MergeableIngresses) (configUpdate, error) {
 	masterPems := cnf.updateTLSSecrets(mergeableIngs.Master)
 	masterJwtKeyFileName := cnf.updateJWKSecret(mergeableIngs.Master)
 	minionJwtKeyFileNames := make(map[string]string)
@@ -146,27 +242,39 @@ func (cnf *Configurator) addOrUpdateMergeableIngress(mergeableIngs *MergeableIng
 	}
 
 	nginxCfg := generateNginxCfgForMergeableIngresses(mergeableIngs, masterPems, masterJwtKeyFileName, minionJwtKeyFileNames,
-		cnf.cfgParams, cnf.isPlus, cnf.IsResolverConfigured(), cnf.staticCfgParams)
+		cnf.cfgParams, cnf.isPlus, cnf.isResolverConfigured(), cnf.staticCfgParams)
 
 	name := objectMetaToFileName(&mergeableIngs.Master.Ingress.ObjectMeta)
 	content, err := cnf.templateExecutor.ExecuteIngressConfigTemplate(&nginxCfg)
 	if err != nil {
-		return fmt.Errorf("Error generating Ingress Config %v: %v", name, err)
+		return configUpdate{}, fmt.Errorf("Error generating Ingress Config %v: %v", name, err)
 	}
-	cnf.nginxManager.CreateConfig(name, content)
 
-	cnf.ingresses[name] = mergeableIngs.Master
-	cnf.minions[name] = make(map[string]bool)
-	for _, minion := range mergeableIngs.Minions {
-		minionName := objectMetaToFileName(&minion.Ingress.ObjectMeta)
-		cnf.minions[name][minionName] = true
+	update := configUpdate{
+		kind:    "Ingress",
+		name:    name,
+		content: content,
+		result: func(err error) {
+			if err != nil {
+				return
+			}
+			cnf.ingresses[name] = mergeableIngs.Master
+			cnf.minions[name] = make(map[string]*IngressEx)
+			for _, minion := range mergeableIngs.Minions {
+				minionName := objectMetaToFileName(&minion.Ingress.ObjectMeta)
+				cnf.minions[name][minionName] = minion
+			}
+		},
 	}
 
-	return nil
+	return update, nil
 }
 
 // AddOrUpdateVirtualServer adds or updates NGINX configuration for the VirtualServer resource.
 func (cnf *Configurator) AddOrUpdateVirtualServer(virtualServerEx *VirtualServerEx) (Warnings, error) {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	warnings, err := cnf.addOrUpdateVirtualServer(virtualServerEx)
 	if err != nil {
 		return warnings, fmt.Errorf("Error adding or updating VirtualServer %v/%v: %v", virtualServerEx.VirtualServer.Namespace, virtualServerEx.VirtualServer.Name, err)
@@ -179,58 +287,334 @@ func (cnf *Configurator) AddOrUpdateVirtualServer(virtualServerEx *VirtualServer
 	return warnings, nil
 }
 
+// GetSecretKeyForReference returns the key (<namespace>/<name>) of the secret referenced by a resource in the namespace.
+// The reference is either the name of a secret in the namespace of the resource or the key of a secret in another namespace.
+func GetSecretKeyForReference(namespace string, secretRef string) string {
+	if strings.Contains(secretRef, "/") {
+		return secretRef
+	}
+	return namespace + "/" + secretRef
+}
+
+// HasMissingTLSSecret checks if the VirtualServer references a TLS Secret that doesn't exist or is invalid.
+// For a VirtualServer that references a cert-manager Certificate, the Secret is also missing when the Certificate
+// doesn't exist or is not ready.
+func HasMissingTLSSecret(virtualServerEx *VirtualServerEx) bool {
+	tls := virtualServerEx.VirtualServer.Spec.TLS
+	return tls != nil && (tls.Secret != "" || tls.Certificate != "") && virtualServerEx.TLSSecret == nil
+}
+
+// GetMissingTLSSecretDescription returns the description of the TLS Secret of a VirtualServer with a missing TLS Secret
+// for the warnings and the events.
+func GetMissingTLSSecretDescription(virtualServer *conf_v1.VirtualServer) string {
+	if virtualServer.Spec.TLS.Certificate != "" {
+		return fmt.Sprintf("TLS secret of the Certificate %s/%s", virtualServer.Namespace, virtualServer.Spec.TLS.Certificate)
+	}
+	return fmt.Sprintf("TLS secret %s", GetSecretKeyForReference(virtualServer.Namespace, virtualServer.Spec.TLS.Secret))
+}
+
+// applyMissingTLSSecretPolicy returns the pem file name to use for a VirtualServer with a missing TLS Secret
+// along with a warning that describes the applied policy.
+func (cnf *Configurator) applyMissingTLSSecretPolicy(virtualServer *conf_v1.VirtualServer) (string, string, error) {
+	secretDescription := GetMissingTLSSecretDescription(virtualServer)
+
+	if cnf.staticCfgParams.MissingTLSSecretPolicy != MissingTLSSecretPolicySelfSigned {
+		return "", fmt.Sprintf("%s is invalid or doesn't exist; the %s policy was applied: NGINX will reject TLS connections", secretDescription, MissingTLSSecretPolicyIgnore), nil
+	}
+
+	name := getFileNameForVirtualServer(virtualServer)
+	now := time.Now()
+
+	cert, exists := cnf.selfSignedCertificates[name]
+	if !exists || cert.host != virtualServer.Spec.Host || cert.needsRenewal(now) {
+		var err error
+		cert, err = cnf.createSelfSignedCertificate(getFileNameForSelfSignedSecret(virtualServer), virtualServer.Spec.Host, now)
+		if err != nil {
+			return "", "", fmt.Errorf("Error generating a self-signed certificate for %v/%v: %v", virtualServer.Namespace, virtualServer.Name, err)
+		}
+		cnf.selfSignedCertificates[name] = cert
+	}
+
+	return cert.pemFileName, fmt.Sprintf("%s is invalid or doesn't exist; the %s policy was applied: NGINX will use a temporary self-signed certificate", secretDescription, MissingTLSSecretPolicySelfSigned), nil
+}
+
+// createSelfSignedCertificate generates a self-signed certificate for the host and writes it to the file of the secret.
+func (cnf *Configurator) createSelfSignedCertificate(secretName string, host string, now time.Time) (selfSignedCertificate, error) {
+	data, err := generateSelfSignedCertificate(host, now, selfSignedCertificateValidity)
+	if err != nil {
+		return selfSignedCertificate{}, err
+	}
+
+	return selfSignedCertificate{
+		secretName:  secretName,
+		pemFileName: cnf.nginxManager.CreateSecret(secretName, data, nginx.TLSSecretFileMode),
+		host:        host,
+		notAfter:    now.Add(selfSignedCertificateValidity),
+	}, nil
+}
+
+// deleteSelfSignedCertificate deletes the temporary self-signed certificate of the VirtualServer, if any.
+func (cnf *Configurator) deleteSelfSignedCertificate(name string) {
+	if cert, exists := cnf.selfSignedCertificates[name]; exists {
+		cnf.nginxManager.DeleteSecret(cert.secretName)
+		delete(cnf.selfSignedCertificates, name)
+	}
+}
+
 func (cnf *Configurator) addOrUpdateOpenTracingTracerConfig(content string) error {
 	err := cnf.nginxManager.CreateOpenTracingTracerConfig(content)
 	return err
 }
 
 func (cnf *Configurator) addOrUpdateVirtualServer(virtualServerEx *VirtualServerEx) (Warnings, error) {
+	update, warnings, err := cnf.generateVirtualServerConfigUpdate(virtualServerEx)
+	if err != nil {
+		return warnings, err
+	}
+
+	if err := cnf.applyConfigUpdates([]configUpdate{update})[0]; err != nil {
+		return warnings, err
+	}
+
+	return warnings, cnf.updateMainConfigForHashSizes()
+}
+
+// generateVirtualServerConfigUpdate generates the config of the VirtualServer resource.
+func (cnf *Configurator) generateVirtualServerConfigUpdate(virtualServerEx *VirtualServerEx) (configUpdate, Warnings, error) {
+	name := getFileNameForVirtualServer(virtualServerEx.VirtualServer)
+
 	tlsPemFileName := ""
+	missingTLSSecretWarning := ""
 	if virtualServerEx.TLSSecret != nil {
 		tlsPemFileName = cnf.addOrUpdateTLSSecret(virtualServerEx.TLSSecret)
+	} else if HasMissingTLSSecret(virtualServerEx) {
+		var err error
+		tlsPemFileName, missingTLSSecretWarning, err = cnf.applyMissingTLSSecretPolicy(virtualServerEx.VirtualServer)
+		if err != nil {
+			return configUpdate{}, nil, err
+		}
+	}
+
+	vsc := newVirtualServerConfigurator(cnf.cfgParams, cnf.isPlus, cnf.isResolverConfigured(), cnf.staticCfgParams)
+	sessionTicketKeyFileName := ""
+	if virtualServerEx.SessionTicketKeySecret != nil {
+		sessionTicketKeyFileName = cnf.addOrUpdateSessionTicketKeySecret(virtualServerEx.SessionTicketKeySecret)
+	}
+	jwtKeyFileNames := cnf.addOrUpdateJWKSecretsForVirtualServer(virtualServerEx)
+	vsCfg, warnings := vsc.GenerateVirtualServerConfig(virtualServerEx, tlsPemFileName, sessionTicketKeyFileName, jwtKeyFileNames)
+	if missingTLSSecretWarning != "" {
+		warnings[virtualServerEx.VirtualServer] = append(warnings[virtualServerEx.VirtualServer], missingTLSSecretWarning)
 	}
-	vsc := newVirtualServerConfigurator(cnf.cfgParams, cnf.isPlus, cnf.IsResolverConfigured(), cnf.staticCfgParams)
-	vsCfg, warnings := vsc.GenerateVirtualServerConfig(virtualServerEx, tlsPemFileName)
 
-	name := getFileNameForVirtualServer(virtualServerEx.VirtualServer)
 	content, err := cnf.templateExecutorV2.ExecuteVirtualServerTemplate(&vsCfg)
 	if err != nil {
-		return warnings, fmt.Errorf("Error generating VirtualServer config: %v: %v", name, err)
+		cnf.cleanUpSelfSignedCertificate(name)
+		return configUpdate{}, warnings, fmt.Errorf("Error generating VirtualServer config: %v: %v", name, err)
+	}
+
+	update := configUpdate{
+		kind:    "VirtualServer",
+		name:    name,
+		content: content,
+		result: func(err error) {
+			if err == nil {
+				cnf.virtualServers[name] = virtualServerEx
+				cnf.cacheZones[name] = getCacheZoneNames(vsCfg.CacheZones)
+			}
+			cnf.cleanUpSelfSignedCertificate(name)
+		},
 	}
-	cnf.nginxManager.CreateConfig(name, content)
 
-	cnf.virtualServers[name] = virtualServerEx
+	return update, warnings, nil
+}
 
-	return warnings, nil
+// cleanUpSelfSignedCertificate deletes the temporary self-signed certificate of the VirtualServer
+// unless the applied config of the VirtualServer uses it.
+func (cnf *Configurator) cleanUpSelfSignedCertificate(name string) {
+	if vsEx, exists := cnf.virtualServers[name]; !exists || !HasMissingTLSSecret(vsEx) {
+		cnf.deleteSelfSignedCertificate(name)
+	}
+}
+
+// deleteConfig deletes the config file of an Ingress resource or a VirtualServer.
+func (cnf *Configurator) deleteConfig(name string) {
+	cnf.nginxManager.DeleteConfig(name)
+	delete(cnf.configContents, name)
+}
+
+// configUpdate is a generated config file of an Ingress resource or a VirtualServer.
+type configUpdate struct {
+	kind    string
+	name    string
+	content []byte
+	// result records the result of the update of the config: nil if the config was applied
+	// or the error of the test of the configuration with the config.
+	result func(err error)
+}
+
+// applyConfigUpdates writes the config files of the updates and tests the configuration once for all of them.
+// Only if the test fails, the configs are tested one by one: a config that fails the test is replaced with the last
+// applied config of its resource or, for a new resource, removed, and its error is returned at the index of its update.
+// A failure that remains without the new configs is caused by another resource, so the new configs are kept.
+func (cnf *Configurator) applyConfigUpdates(updates []configUpdate) []error {
+	errs := make([]error, len(updates))
+
+	for _, u := range updates {
+		cnf.nginxManager.CreateConfig(u.name, u.content)
+	}
+
+	if len(updates) > 0 {
+		if err := cnf.nginxManager.TestConfig(); err != nil {
+			cnf.findFailedConfigUpdates(updates, err, errs)
+		}
+	}
+
+	for i, u := range updates {
+		if errs[i] != nil {
+			errs[i] = fmt.Errorf("Error testing the config of %v %v, the last applied config of the %v is kept: %v", u.kind, u.name, u.kind, errs[i])
+		} else {
+			cnf.configContents[u.name] = u.content
+		}
+		u.result(errs[i])
+	}
+
+	return errs
+}
+
+// findFailedConfigUpdates finds the updates whose configs fail the test of the configuration and sets their errors.
+// The configs of the failed updates are restored to the last applied configs.
+func (cnf *Configurator) findFailedConfigUpdates(updates []configUpdate, testErr error, errs []error) {
+	for _, u := range updates {
+		cnf.restoreConfig(u.name)
+	}
+
+	if err := cnf.nginxManager.TestConfig(); err != nil {
+		glog.Warningf("The configuration is invalid without the updates of %v resources, so the failure is not attributed to them: %v", len(updates), err)
+		for _, u := range updates {
+			cnf.nginxManager.CreateConfig(u.name, u.content)
+		}
+		return
+	}
+
+	if len(updates) == 1 {
+		errs[0] = testErr
+		return
+	}
+
+	for i, u := range updates {
+		cnf.nginxManager.CreateConfig(u.name, u.content)
+		if err := cnf.nginxManager.TestConfig(); err != nil {
+			errs[i] = err
+			cnf.restoreConfig(u.name)
+		}
+	}
+}
+
+// restoreConfig restores the last applied config of an Ingress resource or a VirtualServer
+// or, for a new resource, removes its config file.
+func (cnf *Configurator) restoreConfig(name string) {
+	if prevContent, exists := cnf.configContents[name]; exists {
+		cnf.nginxManager.CreateConfig(name, prevContent)
+	} else {
+		cnf.nginxManager.DeleteConfig(name)
+	}
+}
+
+// updateResources updates the configs of the Ingress resources and the VirtualServers and tests the configuration
+// once for all of them. A resource that fails to update keeps its last applied config and its error is returned
+// in ResourceErrors, so that the configs of the other resources are applied.
+func (cnf *Configurator) updateResources(ingExes []*IngressEx, mergeableIngs []*MergeableIngresses, virtualServerExes []*VirtualServerEx) (Warnings, ResourceErrors, error) {
+	allWarnings := newWarnings()
+	resourceErrors := newResourceErrors()
+
+	var updates []configUpdate
+	var resources []runtime.Object
+
+	for _, ingEx := range ingExes {
+		update, err := cnf.generateIngressConfigUpdate(ingEx)
+		if err != nil {
+			resourceErrors[ingEx.Ingress] = err
+			continue
+		}
+		updates = append(updates, update)
+		resources = append(resources, ingEx.Ingress)
+	}
+
+	for _, mergeableIng := range mergeableIngs {
+		update, err := cnf.generateMergeableIngressConfigUpdate(mergeableIng)
+		if err != nil {
+			resourceErrors[mergeableIng.Master.Ingress] = err
+			continue
+		}
+		updates = append(updates, update)
+		resources = append(resources, mergeableIng.Master.Ingress)
+	}
+
+	for _, vsEx := range virtualServerExes {
+		update, warnings, err := cnf.generateVirtualServerConfigUpdate(vsEx)
+		if err != nil {
+			resourceErrors[vsEx.VirtualServer] = err
+			continue
+		}
+		allWarnings.Add(warnings)
+		updates = append(updates, update)
+		resources = append(resources, vsEx.VirtualServer)
+	}
+
+	for i, err := range cnf.applyConfigUpdates(updates) {
+		if err != nil {
+			resourceErrors[resources[i]] = err
+			delete(allWarnings, resources[i])
+		}
+	}
+
+	return allWarnings, resourceErrors, cnf.updateMainConfigForHashSizes()
 }
 
 // AddOrUpdateTransportServer adds or updates NGINX configuration for the TransportServer resource.
 // It is a responsibility of the caller to check that the TransportServer references an existing listener.
 func (cnf *Configurator) AddOrUpdateTransportServer(transportServerEx *TransportServerEx) error {
-	err := cnf.addOrUpdateTransportServer(transportServerEx)
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	changed, err := cnf.addOrUpdateTransportServer(transportServerEx)
 	if err != nil {
 		return fmt.Errorf("Error adding or updating TransportServer %v/%v: %v", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name, err)
 	}
 
+	if !changed {
+		glog.V(3).Infof("Config for TransportServer %v/%v didn't change, no need to reload nginx", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name)
+		return nil
+	}
+
 	if err := cnf.nginxManager.Reload(); err != nil {
+		// the config is not applied, so the next update of the TransportServer must reload NGINX even if the config doesn't change
+		delete(cnf.streamConfigs, getFileNameForTransportServer(transportServerEx.TransportServer))
 		return fmt.Errorf("Error reloading NGINX for TransportServer %v/%v: %v", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name, err)
 	}
 
 	return nil
 }
 
-func (cnf *Configurator) addOrUpdateTransportServer(transportServerEx *TransportServerEx) error {
+// addOrUpdateTransportServer writes the stream config of the TransportServer. It returns true if the stream config files
+// changed. The HTTP config files are never written, as a TransportServer doesn't affect them.
+func (cnf *Configurator) addOrUpdateTransportServer(transportServerEx *TransportServerEx) (bool, error) {
 	name := getFileNameForTransportServer(transportServerEx.TransportServer)
 
 	listener := cnf.globalCfgParams.Listeners[transportServerEx.TransportServer.Spec.Listener.Name]
-	tsCfg := generateTransportServerConfig(transportServerEx, listener.Port, cnf.isPlus)
+	tsCfg := generateTransportServerConfig(transportServerEx, listener, cnf.isPlus)
 
 	content, err := cnf.templateExecutorV2.ExecuteTransportServerTemplate(&tsCfg)
 	if err != nil {
-		return fmt.Errorf("Error generating TransportServer config %v: %v", name, err)
+		return false, fmt.Errorf("Error generating TransportServer config %v: %v", name, err)
 	}
 
-	cnf.nginxManager.CreateStreamConfig(name, content)
+	changed := false
+	if previous, exists := cnf.streamConfigs[name]; !exists || !bytes.Equal(previous, content) {
+		cnf.nginxManager.CreateStreamConfig(name, content)
+		cnf.streamConfigs[name] = content
+		changed = true
+	}
 
 	// update TLS Passhrough Hosts config in case we have a TLS Passthrough TransportServer
 	// only TLS Passthrough TransportServers have non-empty hosts
@@ -241,15 +625,19 @@ func (cnf *Configurator) addOrUpdateTransportServer(transportServerEx *Transport
 			UnixSocket: generateUnixSocket(transportServerEx),
 		}
 
-		return cnf.updateTLSPassthroughHostsConfig()
+		hostsChanged, err := cnf.updateTLSPassthroughHostsConfig()
+		return changed || hostsChanged, err
 	}
 
-	return nil
+	return changed, nil
 }
 
 // GetVirtualServerRoutesForVirtualServer returns the virtualServerRoutes that a virtualServer
 // references, if that virtualServer exists
 func (cnf *Configurator) GetVirtualServerRoutesForVirtualServer(key string) []*conf_v1.VirtualServerRoute {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	vsFileName := getFileNameForVirtualServerFromKey(key)
 	if cnf.virtualServers[vsFileName] != nil {
 		return cnf.virtualServers[vsFileName].VirtualServerRoutes
@@ -257,7 +645,20 @@ func (cnf *Configurator) GetVirtualServerRoutesForVirtualServer(key string) []*c
 	return nil
 }
 
-func (cnf *Configurator) updateTLSPassthroughHostsConfig() error {
+// GetVirtualServer returns the VirtualServer of the current configuration, if that virtualServer exists
+func (cnf *Configurator) GetVirtualServer(key string) *conf_v1.VirtualServer {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	vsFileName := getFileNameForVirtualServerFromKey(key)
+	if cnf.virtualServers[vsFileName] != nil {
+		return cnf.virtualServers[vsFileName].VirtualServer
+	}
+	return nil
+}
+
+// updateTLSPassthroughHostsConfig writes the TLS Passthrough hosts config. It returns true if the config changed.
+func (cnf *Configurator) updateTLSPassthroughHostsConfig() (bool, error) {
 	cfg, duplicatedHosts := generateTLSPassthroughHostsConfig(cnf.tlsPassthroughPairs)
 
 	for _, host := range duplicatedHosts {
@@ -266,12 +667,17 @@ func (cnf *Configurator) updateTLSPassthroughHostsConfig() error {
 
 	content, err := cnf.templateExecutorV2.ExecuteTLSPassthroughHostsTemplate(cfg)
 	if err != nil {
-		return fmt.Errorf("Error generating config for TLS Passthrough Unix Sockets map: %v", err)
+		return false, fmt.Errorf("Error generating config for TLS Passthrough Unix Sockets map: %v", err)
+	}
+
+	if cnf.tlsPassthroughHostsConfig != nil && bytes.Equal(cnf.tlsPassthroughHostsConfig, content) {
+		return false, nil
 	}
 
 	cnf.nginxManager.CreateTLSPassthroughHostsConfig(content)
+	cnf.tlsPassthroughHostsConfig = content
 
-	return nil
+	return true, nil
 }
 
 func generateTLSPassthroughHostsConfig(tlsPassthroughPairs map[string]tlsPassthroughPair) (*version2.TLSPassthroughHostsConfig, []string) {
@@ -342,33 +748,44 @@ func (cnf *Configurator) addOrUpdateJWKSecret(secret *api_v1.Secret) string {
 	return cnf.nginxManager.CreateSecret(name, data, nginx.JWKSecretFileMode)
 }
 
+// addOrUpdateJWKSecretsForVirtualServer adds or updates the files with the JWK secrets of the policies of a VirtualServer.
+// It returns the names of the files of the secrets, keyed by the namespace/name of the secrets.
+func (cnf *Configurator) addOrUpdateJWKSecretsForVirtualServer(virtualServerEx *VirtualServerEx) map[string]string {
+	jwtKeyFileNames := make(map[string]string)
+
+	if !cnf.isPlus {
+		return jwtKeyFileNames
+	}
+
+	for key, secret := range virtualServerEx.JWTKeys {
+		jwtKeyFileNames[key] = cnf.addOrUpdateJWKSecret(secret)
+	}
+
+	return jwtKeyFileNames
+}
+
+func (cnf *Configurator) addOrUpdateSessionTicketKeySecret(secret *api_v1.Secret) string {
+	name := objectMetaToFileName(&secret.ObjectMeta)
+	data := secret.Data[SessionTicketKeyKey]
+	return cnf.nginxManager.CreateSecret(name, data, nginx.TLSSecretFileMode)
+}
+
 func (cnf *Configurator) AddOrUpdateJWKSecret(secret *api_v1.Secret) {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	cnf.addOrUpdateJWKSecret(secret)
 }
 
 // AddOrUpdateTLSSecret adds or updates a file with the content of the TLS secret.
 func (cnf *Configurator) AddOrUpdateTLSSecret(secret *api_v1.Secret, ingExes []IngressEx, mergeableIngresses []MergeableIngresses, virtualServerExes []*VirtualServerEx) error {
-	cnf.addOrUpdateTLSSecret(secret)
-	for i := range ingExes {
-		err := cnf.addOrUpdateIngress(&ingExes[i])
-		if err != nil {
-			return fmt.Errorf("Error adding or updating ingress %v/%v: %v", ingExes[i].Ingress.Namespace, ingExes[i].Ingress.Name, err)
-		}
-	}
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
 
-	for i := range mergeableIngresses {
-		err := cnf.addOrUpdateMergeableIngress(&mergeableIngresses[i])
-		if err != nil {
-			return fmt.Errorf("Error adding or updating mergeableIngress %v/%v: %v", mergeableIngresses[i].Master.Ingress.Namespace, mergeableIngresses[i].Master.Ingress.Name, err)
-		}
-	}
-
-	for _, vsEx := range virtualServerExes {
-		// It is safe to ignore warnings here as no new warnings should appear when adding or updating a secret
-		_, err := cnf.addOrUpdateVirtualServer(vsEx)
-		if err != nil {
-			return fmt.Errorf("Error adding or updating VirtualServer %v/%v: %v", vsEx.VirtualServer.Namespace, vsEx.VirtualServer.Name, err)
-		}
+	cnf.addOrUpdateTLSSecret(secret)
+	// It is safe to ignore warnings here as no new warnings should appear when adding or updating a secret
+	if err := cnf.updateResourcesOrFail(getIngressExPointers(ingExes), getMergeableIngressesPointers(mergeableIngresses), virtualServerExes); err != nil {
+		return err
 	}
 
 	if err := cnf.nginxManager.Reload(); err != nil {
@@ -381,11 +798,14 @@ func (cnf *Configurator) AddOrUpdateTLSSecret(secret *api_v1.Secret, ingExes []I
 func (cnf *Configurator) addOrUpdateTLSSecret(secret *api_v1.Secret) string {
 	name := objectMetaToFileName(&secret.ObjectMeta)
 	data := GenerateCertAndKeyFileContent(secret)
-	return cnf.nginxManager.CreateSecret(name, data, nginx.TLSSecretFileMode)
+	return cnf.nginxManager.CreateVersionedSecret(name, data, nginx.TLSSecretFileMode)
 }
 
 // AddOrUpdateSpecialTLSSecrets adds or updates a file with a TLS cert and a key from a Special TLS Secret (eg. DefaultServerSecret, WildcardTLSSecret).
 func (cnf *Configurator) AddOrUpdateSpecialTLSSecrets(secret *api_v1.Secret, secretNames []string) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	data := GenerateCertAndKeyFileContent(secret)
 
 	for _, secretName := range secretNames {
@@ -413,28 +833,14 @@ func GenerateCertAndKeyFileContent(secret *api_v1.Secret) []byte {
 // DeleteSecret deletes the file associated with the secret and the configuration files for Ingress and VirtualServer resources.
 // NGINX is reloaded only when the total number of the resources > 0.
 func (cnf *Configurator) DeleteSecret(key string, ingExes []IngressEx, mergeableIngresses []MergeableIngresses, virtualServerExes []*VirtualServerEx) error {
-	cnf.nginxManager.DeleteSecret(keyToFileName(key))
-
-	for i := range ingExes {
-		err := cnf.addOrUpdateIngress(&ingExes[i])
-		if err != nil {
-			return fmt.Errorf("Error adding or updating ingress %v/%v: %v", ingExes[i].Ingress.Namespace, ingExes[i].Ingress.Name, err)
-		}
-	}
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
 
-	for i := range mergeableIngresses {
-		err := cnf.addOrUpdateMergeableIngress(&mergeableIngresses[i])
-		if err != nil {
-			return fmt.Errorf("Error adding or updating mergeableIngress %v/%v: %v", mergeableIngresses[i].Master.Ingress.Namespace, mergeableIngresses[i].Master.Ingress.Name, err)
-		}
-	}
+	cnf.nginxManager.DeleteSecret(keyToFileName(key))
 
-	for _, vsEx := range virtualServerExes {
-		// It is safe to ignore warnings here as no new warnings should appear when deleting a secret
-		_, err := cnf.addOrUpdateVirtualServer(vsEx)
-		if err != nil {
-			return fmt.Errorf("Error adding or updating VirtualServer %v/%v: %v", vsEx.VirtualServer.Namespace, vsEx.VirtualServer.Name, err)
-		}
+	// It is safe to ignore warnings here as no new warnings should appear when deleting a secret
+	if err := cnf.updateResourcesOrFail(getIngressExPointers(ingExes), getMergeableIngressesPointers(mergeableIngresses), virtualServerExes); err != nil {
+		return err
 	}
 
 	if len(ingExes)+len(mergeableIngresses)+len(virtualServerExes) > 0 {
@@ -448,8 +854,11 @@ func (cnf *Configurator) DeleteSecret(key string, ingExes []IngressEx, mergeable
 
 // DeleteIngress deletes NGINX configuration for the Ingress resource.
 func (cnf *Configurator) DeleteIngress(key string) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	name := keyToFileName(key)
-	cnf.nginxManager.DeleteConfig(name)
+	cnf.deleteConfig(name)
 
 	delete(cnf.ingresses, name)
 	delete(cnf.minions, name)
@@ -463,10 +872,15 @@ func (cnf *Configurator) DeleteIngress(key string) error {
 
 // DeleteVirtualServer deletes NGINX configuration for the VirtualServer resource.
 func (cnf *Configurator) DeleteVirtualServer(key string) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	name := getFileNameForVirtualServerFromKey(key)
-	cnf.nginxManager.DeleteConfig(name)
+	cnf.deleteConfig(name)
 
+	cnf.deleteSelfSignedCertificate(name)
 	delete(cnf.virtualServers, name)
+	delete(cnf.cacheZones, name)
 
 	if err := cnf.nginxManager.Reload(); err != nil {
 		return fmt.Errorf("Error when removing VirtualServer %v: %v", key, err)
@@ -477,6 +891,9 @@ func (cnf *Configurator) DeleteVirtualServer(key string) error {
 
 // DeleteTransportServer deletes NGINX configuration for the TransportServer resource.
 func (cnf *Configurator) DeleteTransportServer(key string) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	err := cnf.deleteTransportServer(key)
 	if err != nil {
 		return fmt.Errorf("Error when removing TransportServer %v: %v", key, err)
@@ -493,12 +910,14 @@ func (cnf *Configurator) DeleteTransportServer(key string) error {
 func (cnf *Configurator) deleteTransportServer(key string) error {
 	name := getFileNameForTransportServerFromKey(key)
 	cnf.nginxManager.DeleteStreamConfig(name)
+	delete(cnf.streamConfigs, name)
 
 	// update TLS Passhrough Hosts config in case we have a TLS Passthrough TransportServer
 	if _, exists := cnf.tlsPassthroughPairs[key]; exists {
 		delete(cnf.tlsPassthroughPairs, key)
 
-		return cnf.updateTLSPassthroughHostsConfig()
+		_, err := cnf.updateTLSPassthroughHostsConfig()
+		return err
 	}
 
 	return nil
@@ -506,14 +925,16 @@ func (cnf *Configurator) deleteTransportServer(key string) error {
 
 // UpdateEndpoints updates endpoints in NGINX configuration for the Ingress resources.
 func (cnf *Configurator) UpdateEndpoints(ingExes []*IngressEx) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	reloadPlus := false
 
-	for _, ingEx := range ingExes {
-		err := cnf.addOrUpdateIngress(ingEx)
-		if err != nil {
-			return fmt.Errorf("Error adding or updating ingress %v/%v: %v", ingEx.Ingress.Namespace, ingEx.Ingress.Name, err)
-		}
+	if err := cnf.updateResourcesOrFail(ingExes, nil, nil); err != nil {
+		return err
+	}
 
+	for _, ingEx := range ingExes {
 		if cnf.isPlus {
 			err := cnf.updatePlusEndpoints(ingEx)
 			if err != nil {
@@ -537,17 +958,19 @@ func (cnf *Configurator) UpdateEndpoints(ingExes []*IngressEx) error {
 
 // UpdateEndpointsMergeableIngress updates endpoints in NGINX configuration for a mergeable Ingress resource.
 func (cnf *Configurator) UpdateEndpointsMergeableIngress(mergeableIngresses []*MergeableIngresses) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	reloadPlus := false
 
-	for i := range mergeableIngresses {
-		err := cnf.addOrUpdateMergeableIngress(mergeableIngresses[i])
-		if err != nil {
-			return fmt.Errorf("Error adding or updating mergeableIngress %v/%v: %v", mergeableIngresses[i].Master.Ingress.Namespace, mergeableIngresses[i].Master.Ingress.Name, err)
-		}
+	if err := cnf.updateResourcesOrFail(nil, mergeableIngresses, nil); err != nil {
+		return err
+	}
 
+	for i := range mergeableIngresses {
 		if cnf.isPlus {
 			for _, ing := range mergeableIngresses[i].Minions {
-				err = cnf.updatePlusEndpoints(ing)
+				err := cnf.updatePlusEndpoints(ing)
 				if err != nil {
 					glog.Warningf("Couldn't update the endpoints via the API: %v; reloading configuration instead", err)
 					reloadPlus = true
@@ -570,13 +993,27 @@ func (cnf *Configurator) UpdateEndpointsMergeableIngress(mergeableIngresses []*M
 
 // UpdateEndpointsForVirtualServers updates endpoints in NGINX configuration for the VirtualServer resources.
 func (cnf *Configurator) UpdateEndpointsForVirtualServers(virtualServerExes []*VirtualServerEx) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	reloadPlus := false
 
+	previousConnections := make(map[string]int)
 	for _, vs := range virtualServerExes {
-		// It is safe to ignore warnings here as no new warnings should appear when updating Endpoints for VirtualServers
-		_, err := cnf.addOrUpdateVirtualServer(vs)
-		if err != nil {
-			return fmt.Errorf("Error adding or updating VirtualServer %v/%v: %v", vs.VirtualServer.Namespace, vs.VirtualServer.Name, err)
+		if previous, exists := cnf.virtualServers[getFileNameForVirtualServer(vs.VirtualServer)]; exists {
+			previousConnections[getFileNameForVirtualServer(vs.VirtualServer)] = generateConnectionLimitConnections(previous)
+		}
+	}
+
+	// It is safe to ignore warnings here as no new warnings should appear when updating Endpoints for VirtualServers
+	if err := cnf.updateResourcesOrFail(nil, nil, virtualServerExes); err != nil {
+		return err
+	}
+
+	for _, vs := range virtualServerExes {
+		// the connection limit derived from the number of the endpoints can't be updated via the API
+		if generateConnectionLimitConnections(vs) != previousConnections[getFileNameForVirtualServer(vs.VirtualServer)] {
+			reloadPlus = true
 		}
 
 		if cnf.isPlus {
@@ -618,13 +1055,18 @@ func (cnf *Configurator) updatePlusEndpointsForVirtualServer(virtualServerEx *Vi
 
 // UpdateEndpointsForTransportServers updates endpoints in NGINX configuration for the TransportServer resources.
 func (cnf *Configurator) UpdateEndpointsForTransportServers(transportServerExes []*TransportServerEx) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	reloadPlus := false
+	changed := false
 
 	for _, tsEx := range transportServerExes {
-		err := cnf.addOrUpdateTransportServer(tsEx)
+		tsChanged, err := cnf.addOrUpdateTransportServer(tsEx)
 		if err != nil {
 			return fmt.Errorf("Error adding or updating TransportServer %v/%v: %v", tsEx.TransportServer.Namespace, tsEx.TransportServer.Name, err)
 		}
+		changed = changed || tsChanged
 
 		if cnf.isPlus {
 			err := cnf.updatePlusEndpointsForTransportServer(tsEx)
@@ -635,12 +1077,15 @@ func (cnf *Configurator) UpdateEndpointsForTransportServers(transportServerExes
 		}
 	}
 
-	if cnf.isPlus && !reloadPlus {
+	if (cnf.isPlus && !reloadPlus) || (!cnf.isPlus && !changed) {
 		glog.V(3).Info("No need to reload nginx")
 		return nil
 	}
 
 	if err := cnf.nginxManager.Reload(); err != nil {
+		for _, tsEx := range transportServerExes {
+			delete(cnf.streamConfigs, getFileNameForTransportServer(tsEx.TransportServer))
+		}
 		return fmt.Errorf("Error reloading NGINX when updating endpoints: %v", err)
 	}
 
@@ -657,7 +1102,11 @@ func (cnf *Configurator) updatePlusEndpointsForTransportServer(transportServerEx
 		endpointsKey := GenerateEndpointsKey(transportServerEx.TransportServer.Namespace, u.Service, nil, uint16(u.Port))
 		endpoints := transportServerEx.Endpoints[endpointsKey]
 
-		err := cnf.nginxManager.UpdateStreamServersInPlus(name, endpoints)
+		serverCfg := nginx.StreamServerConfig{
+			Weights: generateStreamUpstreamServerWeights(endpoints, u.Weights),
+		}
+
+		err := cnf.nginxManager.UpdateStreamServersInPlus(name, endpoints, serverCfg)
 		if err != nil {
 			return fmt.Errorf("Couldn't update the endpoints for %v: %v", u.Name, err)
 		}
@@ -717,14 +1166,26 @@ func (cnf *Configurator) updatePlusEndpoints(ingEx *IngressEx) error {
 }
 
 // UpdateConfig updates NGINX configuration parameters.
-func (cnf *Configurator) UpdateConfig(cfgParams *ConfigParams, ingExes []*IngressEx, mergeableIngs map[string]*MergeableIngresses, virtualServerExes []*VirtualServerEx) (Warnings, error) {
+// A resource that fails to update keeps its last applied config and its error is returned in ResourceErrors,
+// while NGINX is reloaded with the configuration of the other resources.
+func (cnf *Configurator) UpdateConfig(cfgParams *ConfigParams, ingExes []*IngressEx, mergeableIngs map[string]*MergeableIngresses, virtualServerExes []*VirtualServerEx) (Warnings, ResourceErrors, error) {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	// the ConfigMap has an invalid error-log-level, so the last valid level is kept
+	if cfgParams.MainErrorLogLevel == "" {
+		cfgParams.MainErrorLogLevel = cnf.cfgParams.MainErrorLogLevel
+	}
+
+	prevCfgParams := cnf.cfgParams
 	cnf.cfgParams = cfgParams
 	allWarnings := newWarnings()
+	resourceErrors := newResourceErrors()
 
 	if cnf.cfgParams.MainServerSSLDHParamFileContent != nil {
 		fileName, err := cnf.nginxManager.CreateDHParam(*cnf.cfgParams.MainServerSSLDHParamFileContent)
 		if err != nil {
-			return allWarnings, fmt.Errorf("Error when updating dhparams: %v", err)
+			return allWarnings, resourceErrors, fmt.Errorf("Error when updating dhparams: %v", err)
 		}
 		cfgParams.MainServerSSLDHParam = fileName
 	}
@@ -732,54 +1193,160 @@ func (cnf *Configurator) UpdateConfig(cfgParams *ConfigParams, ingExes []*Ingres
 	if cfgParams.MainTemplate != nil {
 		err := cnf.templateExecutor.UpdateMainTemplate(cfgParams.MainTemplate)
 		if err != nil {
-			return allWarnings, fmt.Errorf("Error when parsing the main template: %v", err)
+			return allWarnings, resourceErrors, fmt.Errorf("Error when parsing the main template: %v", err)
 		}
 	}
 
 	if cfgParams.IngressTemplate != nil {
 		err := cnf.templateExecutor.UpdateIngressTemplate(cfgParams.IngressTemplate)
 		if err != nil {
-			return allWarnings, fmt.Errorf("Error when parsing the ingress template: %v", err)
+			return allWarnings, resourceErrors, fmt.Errorf("Error when parsing the ingress template: %v", err)
+		}
+	}
+
+	mainCfg := cnf.generateMainConfig(cfgParams)
+	mainCfgContent, err := cnf.templateExecutor.ExecuteMainConfigTemplate(mainCfg)
+	if err != nil {
+		return allWarnings, resourceErrors, fmt.Errorf("Error when writing main Config")
+	}
+
+	// an invalid snippet in the main config breaks the whole configuration, so the changes of the snippets
+	// are tested before they are applied. If the test fails, the last valid configuration is kept.
+	if areMainSnippetsChanged(prevCfgParams, cfgParams) {
+		if err := cnf.nginxManager.TestMainConfig(mainCfgContent); err != nil {
+			cnf.cfgParams = prevCfgParams
+			return allWarnings, resourceErrors, fmt.Errorf("Error when testing the main config with the updated snippets, the last valid configuration is kept: %v", err)
 		}
 	}
 
+	cnf.nginxManager.CreateMainConfig(mainCfgContent)
+
+	warnings, updateErrors, err := cnf.updateResources(ingExes, getMergeableIngressesSlice(mergeableIngs), virtualServerExes)
+	allWarnings.Add(warnings)
+	resourceErrors.Add(updateErrors)
+	if err != nil {
+		return allWarnings, resourceErrors, err
+	}
+
+	if mainCfg.OpenTracingLoadModule {
+		if err := cnf.addOrUpdateOpenTracingTracerConfig(mainCfg.OpenTracingTracerConfig); err != nil {
+			return allWarnings, resourceErrors, fmt.Errorf("Error when updating OpenTracing tracer config: %v", err)
+		}
+	}
+
+	cnf.nginxManager.SetOpenTracing(mainCfg.OpenTracingLoadModule)
+	if err := cnf.nginxManager.Reload(); err != nil {
+		return allWarnings, resourceErrors, fmt.Errorf("Error when updating config from ConfigMap: %v", err)
+	}
+
+	return allWarnings, resourceErrors, nil
+}
+
+// generateMainConfig generates the main config with the hash bucket sizes tuned for the longest server name
+// and the proxy headers hash sizes tuned for the headers of the resources.
+func (cnf *Configurator) generateMainConfig(cfgParams *ConfigParams) *version1.MainConfig {
 	mainCfg := GenerateNginxMainConfig(cnf.staticCfgParams, cfgParams)
+	tuneHashBucketSizes(mainCfg, getLongestServerNameLength(cnf.ingresses, cnf.virtualServers))
+	cnf.hashBucketSize = getMinHashBucketSize(mainCfg)
+	tuneProxyHeadersHashSizes(mainCfg, getProxyHeaders(cnf.virtualServers, cfgParams.ProxyHideHeaders))
+	cnf.proxyHeadersHashSizes = getProxyHeadersHashSizes(mainCfg)
+
+	return mainCfg
+}
+
+// updateMainConfigForHashSizes updates the main config if its hash bucket sizes are too small
+// for the longest server name or its proxy headers hash sizes are too small for the headers of the resources.
+func (cnf *Configurator) updateMainConfigForHashSizes() error {
+	required := getHashBucketSizeForKey(getLongestServerNameLength(cnf.ingresses, cnf.virtualServers))
+	requiredProxyHeaders := getRequiredProxyHeadersHashSizes(getProxyHeaders(cnf.virtualServers, cnf.cfgParams.ProxyHideHeaders))
+	if required <= cnf.hashBucketSize && cnf.proxyHeadersHashSizes.fits(requiredProxyHeaders) {
+		return nil
+	}
+
+	mainCfg := cnf.generateMainConfig(cnf.cfgParams)
 	mainCfgContent, err := cnf.templateExecutor.ExecuteMainConfigTemplate(mainCfg)
 	if err != nil {
-		return allWarnings, fmt.Errorf("Error when writing main Config")
+		return fmt.Errorf("Error when writing main Config: %v", err)
 	}
 	cnf.nginxManager.CreateMainConfig(mainCfgContent)
 
+	return nil
+}
+
+// updateResourcesOrFail updates the configs of the Ingress resources and the VirtualServers like updateResources,
+// but returns the error of the first resource that fails to update.
+func (cnf *Configurator) updateResourcesOrFail(ingExes []*IngressEx, mergeableIngs []*MergeableIngresses, virtualServerExes []*VirtualServerEx) error {
+	_, resourceErrors, err := cnf.updateResources(ingExes, mergeableIngs, virtualServerExes)
+
 	for _, ingEx := range ingExes {
-		if err := cnf.addOrUpdateIngress(ingEx); err != nil {
-			return allWarnings, err
+		if resErr, exists := resourceErrors[ingEx.Ingress]; exists {
+			return fmt.Errorf("Error adding or updating ingress %v/%v: %v", ingEx.Ingress.Namespace, ingEx.Ingress.Name, resErr)
 		}
 	}
+
 	for _, mergeableIng := range mergeableIngs {
-		if err := cnf.addOrUpdateMergeableIngress(mergeableIng); err != nil {
-			return allWarnings, err
+		if resErr, exists := resourceErrors[mergeableIng.Master.Ingress]; exists {
+			return fmt.Errorf("Error adding or updating mergeableIngress %v/%v: %v", mergeableIng.Master.Ingress.Namespace, mergeableIng.Master.Ingress.Name, resErr)
 		}
 	}
+
 	for _, vsEx := range virtualServerExes {
-		warnings, err := cnf.addOrUpdateVirtualServer(vsEx)
-		if err != nil {
-			return allWarnings, err
+		if resErr, exists := resourceErrors[vsEx.VirtualServer]; exists {
+			return fmt.Errorf("Error adding or updating VirtualServer %v/%v: %v", vsEx.VirtualServer.Namespace, vsEx.VirtualServer.Name, resErr)
 		}
-		allWarnings.Add(warnings)
 	}
 
-	if mainCfg.OpenTracingLoadModule {
-		if err := cnf.addOrUpdateOpenTracingTracerConfig(mainCfg.OpenTracingTracerConfig); err != nil {
-			return allWarnings, fmt.Errorf("Error when updating OpenTracing tracer config: %v", err)
-		}
+	return err
+}
+
+func getIngressExPointers(ingExes []IngressEx) []*IngressEx {
+	var result []*IngressEx
+	for i := range ingExes {
+		result = append(result, &ingExes[i])
+	}
+	return result
+}
+
+func getMergeableIngressesPointers(mergeableIngs []MergeableIngresses) []*MergeableIngresses {
+	var result []*MergeableIngresses
+	for i := range mergeableIngs {
+		result = append(result, &mergeableIngs[i])
+	}
+	return result
+}
+
+func getMergeableIngressesSlice(mergeableIngs map[string]*MergeableIngresses) []*MergeableIngresses {
+	var result []*MergeableIngresses
+	for _, mergeableIng := range mergeableIngs {
+		result = append(result, mergeableIng)
+	}
+	return result
+}
+
+// areMainSnippetsChanged returns true if the snippets of the main config differ between the ConfigParams.
+func areMainSnippetsChanged(prev *ConfigParams, cur *ConfigParams) bool {
+	return !reflect.DeepEqual(prev.MainMainSnippets, cur.MainMainSnippets) ||
+		!reflect.DeepEqual(prev.MainHTTPSnippets, cur.MainHTTPSnippets) ||
+		!reflect.DeepEqual(prev.MainStreamSnippets, cur.MainStreamSnippets)
+}
+
+// UpdateNamespaceConfig updates NGINX config of the Ingress resources affected by a change of a per-namespace ConfigMap.
+// An Ingress resource that fails to update keeps its last applied config and its error is returned in ResourceErrors,
+// while NGINX is reloaded with the configuration of the other resources.
+func (cnf *Configurator) UpdateNamespaceConfig(ingExes []*IngressEx, mergeableIngs map[string]*MergeableIngresses) (ResourceErrors, error) {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	_, resourceErrors, err := cnf.updateResources(ingExes, getMergeableIngressesSlice(mergeableIngs), nil)
+	if err != nil {
+		return resourceErrors, err
 	}
 
-	cnf.nginxManager.SetOpenTracing(mainCfg.OpenTracingLoadModule)
 	if err := cnf.nginxManager.Reload(); err != nil {
-		return allWarnings, fmt.Errorf("Error when updating config from ConfigMap: %v", err)
+		return resourceErrors, fmt.Errorf("Error when updating config from per-namespace ConfigMap: %v", err)
 	}
 
-	return allWarnings, nil
+	return resourceErrors, nil
 }
 
 // UpdateGlobalConfiguration updates NGINX config based on the changes to the GlobalConfiguration resource.
@@ -788,14 +1355,16 @@ func (cnf *Configurator) UpdateConfig(cfgParams *ConfigParams, ingExes []*Ingres
 // might be removed from NGINX.
 func (cnf *Configurator) UpdateGlobalConfiguration(globalConfiguration *conf_v1alpha1.GlobalConfiguration,
 	transportServerExes []*TransportServerEx) (updatedTransportServerExes []*TransportServerEx, deletedTransportServerExes []*TransportServerEx, err error) {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
 
 	cnf.globalCfgParams = ParseGlobalConfiguration(globalConfiguration, cnf.staticCfgParams.TLSPassthrough)
 
 	for _, tsEx := range transportServerExes {
-		if cnf.CheckIfListenerExists(&tsEx.TransportServer.Spec.Listener) {
+		if cnf.checkIfListenerExists(&tsEx.TransportServer.Spec.Listener) {
 			updatedTransportServerExes = append(updatedTransportServerExes, tsEx)
 
-			err := cnf.addOrUpdateTransportServer(tsEx)
+			_, err := cnf.addOrUpdateTransportServer(tsEx)
 			if err != nil {
 				return updatedTransportServerExes, deletedTransportServerExes, fmt.Errorf("Error when updating global configuration: %v", err)
 			}
@@ -831,6 +1400,10 @@ func getFileNameForVirtualServer(virtualServer *conf_v1.VirtualServer) string {
 	return fmt.Sprintf("vs_%s_%s", virtualServer.Namespace, virtualServer.Name)
 }
 
+func getFileNameForSelfSignedSecret(virtualServer *conf_v1.VirtualServer) string {
+	return fmt.Sprintf("%s_self_signed", getFileNameForVirtualServer(virtualServer))
+}
+
 func getFileNameForTransportServer(transportServer *conf_v1alpha1.TransportServer) string {
 	return fmt.Sprintf("ts_%s_%s", transportServer.Namespace, transportServer.Name)
 }
@@ -845,8 +1418,22 @@ func getFileNameForTransportServerFromKey(key string) string {
 	return fmt.Sprintf("ts_%s", replaced)
 }
 
+// GetIngress returns a copy of the regular or master Ingress resource of the current configuration, if that Ingress exists.
+func (cnf *Configurator) GetIngress(key string) *extensions.Ingress {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	if ingEx, exists := cnf.ingresses[keyToFileName(key)]; exists {
+		return ingEx.Ingress.DeepCopy()
+	}
+	return nil
+}
+
 // HasIngress checks if the Ingress resource is present in NGINX configuration.
 func (cnf *Configurator) HasIngress(ing *extensions.Ingress) bool {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	name := objectMetaToFileName(&ing.ObjectMeta)
 	_, exists := cnf.ingresses[name]
 	return exists
@@ -854,22 +1441,48 @@ func (cnf *Configurator) HasIngress(ing *extensions.Ingress) bool {
 
 // HasMinion checks if the minion Ingress resource of the master is present in NGINX configuration.
 func (cnf *Configurator) HasMinion(master *extensions.Ingress, minion *extensions.Ingress) bool {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	masterName := objectMetaToFileName(&master.ObjectMeta)
 
 	if _, exists := cnf.minions[masterName]; !exists {
 		return false
 	}
 
-	return cnf.minions[masterName][objectMetaToFileName(&minion.ObjectMeta)]
+	_, exists := cnf.minions[masterName][objectMetaToFileName(&minion.ObjectMeta)]
+	return exists
+}
+
+// GetMinions returns copies of the minion Ingress resources of the master in the current configuration.
+func (cnf *Configurator) GetMinions(masterKey string) []*extensions.Ingress {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	var minions []*extensions.Ingress
+	for _, minion := range cnf.minions[keyToFileName(masterKey)] {
+		minions = append(minions, minion.Ingress.DeepCopy())
+	}
+	return minions
 }
 
 // IsResolverConfigured checks if a DNS resolver is present in NGINX configuration.
 func (cnf *Configurator) IsResolverConfigured() bool {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	return cnf.isResolverConfigured()
+}
+
+func (cnf *Configurator) isResolverConfigured() bool {
 	return len(cnf.cfgParams.ResolverAddresses) != 0
 }
 
 // GetIngressCounts returns the total count of Ingress resources that are handled by the Ingress Controller grouped by their type
 func (cnf *Configurator) GetIngressCounts() map[string]int {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	counters := map[string]int{
 		"master":  0,
 		"regular": 0,
@@ -894,6 +1507,9 @@ func (cnf *Configurator) GetIngressCounts() map[string]int {
 
 // GetVirtualServerCounts returns the total count of VS/VSR resources that are handled by the Ingress Controller
 func (cnf *Configurator) GetVirtualServerCounts() (vsCount int, vsrCount int) {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	vsCount = len(cnf.virtualServers)
 	for _, vs := range cnf.virtualServers {
 		vsrCount += len(vs.VirtualServerRoutes)
@@ -903,6 +1519,13 @@ func (cnf *Configurator) GetVirtualServerCounts() (vsCount int, vsrCount int) {
 }
 
 func (cnf *Configurator) CheckIfListenerExists(transportServerListener *conf_v1alpha1.TransportServerListener) bool {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	return cnf.checkIfListenerExists(transportServerListener)
+}
+
+func (cnf *Configurator) checkIfListenerExists(transportServerListener *conf_v1alpha1.TransportServerListener) bool {
 	listener, exists := cnf.globalCfgParams.Listeners[transportServerListener.Name]
 
 	if !exists {
@@ -914,6 +1537,9 @@ func (cnf *Configurator) CheckIfListenerExists(transportServerListener *conf_v1a
 
 // AddOrUpdateSpiffeCerts writes Spiffe certs and keys to disk and reloads NGINX
 func (cnf *Configurator) AddOrUpdateSpiffeCerts(svidResponse *workload.X509SVIDs) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
 	svid := svidResponse.Default()
 	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(svid.PrivateKey.(crypto.PrivateKey))
 	if err != nil {
@@ -931,6 +1557,55 @@ func (cnf *Configurator) AddOrUpdateSpiffeCerts(svidResponse *workload.X509SVIDs
 	return nil
 }
 
+// RotateSelfSignedDefaultServerSecret regenerates the self-signed TLS certificate and key of the default server and reloads NGINX.
+func (cnf *Configurator) RotateSelfSignedDefaultServerSecret() error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	_, err := CreateSelfSignedDefaultServerSecret(cnf.nginxManager)
+	if err != nil {
+		return fmt.Errorf("error when generating the self-signed certificate for the default server: %v", err)
+	}
+
+	err = cnf.nginxManager.Reload()
+	if err != nil {
+		return fmt.Errorf("error when reloading NGINX when updating the self-signed certificate for the default server: %v", err)
+	}
+	return nil
+}
+
+// RenewSelfSignedCertificates regenerates the temporary self-signed certificates of the VirtualServers with missing
+// TLS Secrets that are due for renewal and reloads NGINX if any certificate was regenerated.
+func (cnf *Configurator) RenewSelfSignedCertificates() error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	now := time.Now()
+	renewed := false
+
+	for name, cert := range cnf.selfSignedCertificates {
+		if !cert.needsRenewal(now) {
+			continue
+		}
+
+		newCert, err := cnf.createSelfSignedCertificate(cert.secretName, cert.host, now)
+		if err != nil {
+			return fmt.Errorf("error when generating the self-signed certificate for %v: %v", cert.host, err)
+		}
+		cnf.selfSignedCertificates[name] = newCert
+		renewed = true
+	}
+
+	if !renewed {
+		return nil
+	}
+
+	if err := cnf.nginxManager.Reload(); err != nil {
+		return fmt.Errorf("error when reloading NGINX when renewing the self-signed certificates: %v", err)
+	}
+	return nil
+}
+
 func createSpiffeKey(content []byte) []byte {
 	return pem.EncodeToMemory(&pem.Block{
 		Type:  "EC PRIVATE KEY",
@@ -949,3 +1624,50 @@ func createSpiffeCert(certs []*x509.Certificate) []byte {
 	}
 	return pemData
 }
+
+func getCacheZoneNames(zones []version2.CacheZone) []string {
+	var names []string
+	for _, z := range zones {
+		names = append(names, z.Name)
+	}
+	return names
+}
+
+// HasCacheZone checks if the cache zone exists in the applied config of a VirtualServer.
+func (cnf *Configurator) HasCacheZone(zone string) bool {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	return cnf.hasCacheZone(zone)
+}
+
+func (cnf *Configurator) hasCacheZone(zone string) bool {
+	for _, names := range cnf.cacheZones {
+		for _, name := range names {
+			if name == zone {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PurgeCache removes the entries with the key from the cache zone of a VirtualServer through NGINX Plus.
+// A key ending with an asterisk removes all entries with the keys that start with the rest of the key.
+func (cnf *Configurator) PurgeCache(zone string, key string) error {
+	cnf.mux.Lock()
+	defer cnf.mux.Unlock()
+
+	if !cnf.isPlus {
+		return fmt.Errorf("purging caches is only supported in NGINX Plus")
+	}
+
+	if !cnf.hasCacheZone(zone) {
+		return fmt.Errorf("cache zone %v doesn't exist", zone)
+	}
+
+	if err := cnf.nginxManager.PurgeCacheInPlus(zone, key); err != nil {
+		return fmt.Errorf("error when purging the entries with key %v from cache zone %v: %v", key, zone, err)
+	}
+	return nil
+}
@@ -1,15 +1,20 @@
 package configs
 
 import (
+	"bytes"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
 	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
 	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
 	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
 	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	api_v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 func createTestStaticConfigParams() *StaticConfigParams {
@@ -191,6 +196,341 @@ func TestUpdateEndpointsMergeableIngressFailsWithInvalidTemplate(t *testing.T) {
 	}
 }
 
+func TestAddOrUpdateVirtualServerWithMissingTLSSecret(t *testing.T) {
+	tests := []struct {
+		policy          string
+		expectedWarning string
+	}{
+		{
+			policy:          MissingTLSSecretPolicyIgnore,
+			expectedWarning: "TLS secret default/cafe-secret is invalid or doesn't exist; the ignore policy was applied: NGINX will reject TLS connections",
+		},
+		{
+			policy:          MissingTLSSecretPolicySelfSigned,
+			expectedWarning: "TLS secret default/cafe-secret is invalid or doesn't exist; the self-signed policy was applied: NGINX will use a temporary self-signed certificate",
+		},
+	}
+
+	for _, test := range tests {
+		cnf, err := createTestConfigurator()
+		if err != nil {
+			t.Fatalf("Failed to create a test configurator: %v", err)
+		}
+		cnf.staticCfgParams.MissingTLSSecretPolicy = test.policy
+
+		vsEx := &VirtualServerEx{
+			VirtualServer: &conf_v1.VirtualServer{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "cafe",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerSpec{
+					Host: "cafe.example.com",
+					TLS: &conf_v1.TLS{
+						Secret: "cafe-secret",
+					},
+				},
+			},
+		}
+
+		warnings, err := cnf.AddOrUpdateVirtualServer(vsEx)
+		if err != nil {
+			t.Errorf("AddOrUpdateVirtualServer() returned unexpected error %v for the %s policy", err, test.policy)
+		}
+
+		expectedWarnings := []string{test.expectedWarning}
+		if !reflect.DeepEqual(warnings[vsEx.VirtualServer], expectedWarnings) {
+			t.Errorf("AddOrUpdateVirtualServer() returned warnings %v but expected %v for the %s policy", warnings[vsEx.VirtualServer], expectedWarnings, test.policy)
+		}
+	}
+}
+
+func TestAddOrUpdateVirtualServerReusesSelfSignedCertificate(t *testing.T) {
+	cnf, err := createTestConfigurator()
+	if err != nil {
+		t.Fatalf("Failed to create a test configurator: %v", err)
+	}
+	cnf.staticCfgParams.MissingTLSSecretPolicy = MissingTLSSecretPolicySelfSigned
+
+	vsEx := &VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				TLS: &conf_v1.TLS{
+					Secret: "cafe-secret",
+				},
+			},
+		},
+	}
+	name := getFileNameForVirtualServer(vsEx.VirtualServer)
+
+	_, err = cnf.AddOrUpdateVirtualServer(vsEx)
+	if err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned unexpected error %v", err)
+	}
+	cert, exists := cnf.selfSignedCertificates[name]
+	if !exists {
+		t.Fatalf("AddOrUpdateVirtualServer() didn't generate a self-signed certificate for the VirtualServer with a missing TLS secret")
+	}
+
+	// an update of the VirtualServer, for example, after an endpoints change, reuses the certificate
+	_, err = cnf.AddOrUpdateVirtualServer(vsEx)
+	if err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned unexpected error %v", err)
+	}
+	if result := cnf.selfSignedCertificates[name]; result != cert {
+		t.Errorf("AddOrUpdateVirtualServer() regenerated the self-signed certificate %+v but expected to reuse %+v", result, cert)
+	}
+
+	// the certificate is regenerated when the host changes
+	vsEx.VirtualServer.Spec.Host = "tea.example.com"
+	_, err = cnf.AddOrUpdateVirtualServer(vsEx)
+	if err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned unexpected error %v", err)
+	}
+	if result := cnf.selfSignedCertificates[name]; result.host != "tea.example.com" {
+		t.Errorf("AddOrUpdateVirtualServer() kept the self-signed certificate for the host %v but expected tea.example.com", result.host)
+	}
+
+	// the certificate is removed when the TLS secret shows up
+	vsEx.TLSSecret = &api_v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe-secret",
+			Namespace: "default",
+		},
+	}
+	_, err = cnf.AddOrUpdateVirtualServer(vsEx)
+	if err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned unexpected error %v", err)
+	}
+	if _, exists := cnf.selfSignedCertificates[name]; exists {
+		t.Errorf("AddOrUpdateVirtualServer() kept the self-signed certificate after the TLS secret showed up")
+	}
+}
+
+func TestRenewSelfSignedCertificates(t *testing.T) {
+	cnf, err := createTestConfigurator()
+	if err != nil {
+		t.Fatalf("Failed to create a test configurator: %v", err)
+	}
+
+	now := time.Now()
+	expiring := selfSignedCertificate{
+		secretName:  "default-cafe-self-signed",
+		pemFileName: "/etc/nginx/secrets/default-cafe-self-signed",
+		host:        "cafe.example.com",
+		notAfter:    now.Add(time.Hour),
+	}
+	valid := selfSignedCertificate{
+		secretName:  "default-tea-self-signed",
+		pemFileName: "/etc/nginx/secrets/default-tea-self-signed",
+		host:        "tea.example.com",
+		notAfter:    now.Add(selfSignedCertificateValidity),
+	}
+	cnf.selfSignedCertificates["vs_default_cafe"] = expiring
+	cnf.selfSignedCertificates["vs_default_tea"] = valid
+
+	err = cnf.RenewSelfSignedCertificates()
+	if err != nil {
+		t.Fatalf("RenewSelfSignedCertificates() returned unexpected error %v", err)
+	}
+
+	renewed := cnf.selfSignedCertificates["vs_default_cafe"]
+	if renewed.needsRenewal(time.Now()) || renewed.secretName != expiring.secretName || renewed.host != expiring.host {
+		t.Errorf("RenewSelfSignedCertificates() returned the certificate %+v but expected a renewed %+v", renewed, expiring)
+	}
+	if result := cnf.selfSignedCertificates["vs_default_tea"]; result != valid {
+		t.Errorf("RenewSelfSignedCertificates() changed the certificate %+v that is not due for renewal to %+v", valid, result)
+	}
+}
+
+// cachePurgeTestingManager is a fake manager that records the cache purges in NGINX Plus.
+type cachePurgeTestingManager struct {
+	*nginx.FakeManager
+	purges [][2]string
+}
+
+func (m *cachePurgeTestingManager) PurgeCacheInPlus(zone string, key string) error {
+	m.purges = append(m.purges, [2]string{zone, key})
+	return nil
+}
+
+func createVirtualServerExWithCache() *VirtualServerEx {
+	return &VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+						Cache:   &conf_v1.UpstreamCache{},
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPurgeCache(t *testing.T) {
+	cnf, err := createTestConfigurator()
+	if err != nil {
+		t.Fatalf("Failed to create a test configurator: %v", err)
+	}
+	manager := &cachePurgeTestingManager{FakeManager: nginx.NewFakeManager("/etc/nginx")}
+	cnf.nginxManager = manager
+	cnf.isPlus = true
+
+	if _, err := cnf.AddOrUpdateVirtualServer(createVirtualServerExWithCache()); err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned unexpected error %v", err)
+	}
+
+	if !cnf.HasCacheZone("vs_default_cafe_tea") {
+		t.Errorf("HasCacheZone() returned false for the cache zone of the VirtualServer upstream")
+	}
+
+	err = cnf.PurgeCache("vs_default_cafe_tea", "httpvs_default_cafe_tea/tea*")
+	if err != nil {
+		t.Errorf("PurgeCache() returned unexpected error %v", err)
+	}
+
+	expected := [][2]string{{"vs_default_cafe_tea", "httpvs_default_cafe_tea/tea*"}}
+	if !reflect.DeepEqual(manager.purges, expected) {
+		t.Errorf("PurgeCache() purged %v but expected %v", manager.purges, expected)
+	}
+}
+
+func TestPurgeCacheFails(t *testing.T) {
+	cnf, err := createTestConfigurator()
+	if err != nil {
+		t.Fatalf("Failed to create a test configurator: %v", err)
+	}
+	manager := &cachePurgeTestingManager{FakeManager: nginx.NewFakeManager("/etc/nginx")}
+	cnf.nginxManager = manager
+
+	if _, err := cnf.AddOrUpdateVirtualServer(createVirtualServerExWithCache()); err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned unexpected error %v", err)
+	}
+
+	if err := cnf.PurgeCache("vs_default_cafe_tea", "*"); err == nil {
+		t.Errorf("PurgeCache() returned no error for NGINX")
+	}
+
+	cnf.isPlus = true
+
+	if cnf.HasCacheZone("vs_default_cafe_coffee") {
+		t.Errorf("HasCacheZone() returned true for a cache zone that doesn't exist")
+	}
+	if err := cnf.PurgeCache("vs_default_cafe_coffee", "*"); err == nil {
+		t.Errorf("PurgeCache() returned no error for a cache zone that doesn't exist")
+	}
+
+	if err := cnf.DeleteVirtualServer("default/cafe"); err != nil {
+		t.Fatalf("DeleteVirtualServer() returned unexpected error %v", err)
+	}
+	if err := cnf.PurgeCache("vs_default_cafe_tea", "*"); err == nil {
+		t.Errorf("PurgeCache() returned no error for the cache zone of a deleted VirtualServer")
+	}
+
+	if len(manager.purges) != 0 {
+		t.Errorf("PurgeCache() purged %v but expected no purges", manager.purges)
+	}
+}
+
+func TestHasMissingTLSSecret(t *testing.T) {
+	tests := []struct {
+		vsEx     *VirtualServerEx
+		expected bool
+		msg      string
+	}{
+		{
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{},
+			},
+			expected: false,
+			msg:      "no TLS",
+		},
+		{
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					Spec: conf_v1.VirtualServerSpec{
+						TLS: &conf_v1.TLS{
+							Secret: "cafe-secret",
+						},
+					},
+				},
+				TLSSecret: &api_v1.Secret{},
+			},
+			expected: false,
+			msg:      "existing TLS secret",
+		},
+		{
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					Spec: conf_v1.VirtualServerSpec{
+						TLS: &conf_v1.TLS{
+							Secret: "cafe-secret",
+						},
+					},
+				},
+			},
+			expected: true,
+			msg:      "missing TLS secret",
+		},
+		{
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					Spec: conf_v1.VirtualServerSpec{
+						TLS: &conf_v1.TLS{
+							Certificate: "cafe-certificate",
+						},
+					},
+				},
+				TLSSecret: &api_v1.Secret{},
+			},
+			expected: false,
+			msg:      "existing TLS secret of a certificate",
+		},
+		{
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					Spec: conf_v1.VirtualServerSpec{
+						TLS: &conf_v1.TLS{
+							Certificate: "cafe-certificate",
+						},
+					},
+				},
+			},
+			expected: true,
+			msg:      "missing TLS secret of a certificate",
+		},
+	}
+
+	for _, test := range tests {
+		result := HasMissingTLSSecret(test.vsEx)
+		if result != test.expected {
+			t.Errorf("HasMissingTLSSecret() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
 func TestGetVirtualServerConfigFileName(t *testing.T) {
 	vs := conf_v1.VirtualServer{
 		ObjectMeta: meta_v1.ObjectMeta{
@@ -397,34 +737,915 @@ func TestUpdateGlobalConfiguration(t *testing.T) {
 	}
 }
 
-func TestGenerateTLSPassthroughHostsConfig(t *testing.T) {
-	tlsPassthroughPairs := map[string]tlsPassthroughPair{
-		"default/ts-1": {
-			Host:       "app.example.com",
-			UnixSocket: "socket1.sock",
+func TestUpdateGlobalConfigurationRebuildsListeners(t *testing.T) {
+	tsEx := &TransportServerEx{
+		TransportServer: &conf_v1alpha1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.TransportServerSpec{
+				Listener: conf_v1alpha1.TransportServerListener{
+					Name:     "tcp-listener",
+					Protocol: "TCP",
+				},
+				Upstreams: []conf_v1alpha1.Upstream{
+					{
+						Name:    "tcp-app",
+						Service: "tcp-app-svc",
+						Port:    5001,
+					},
+				},
+				Action: &conf_v1alpha1.Action{
+					Pass: "tcp-app",
+				},
+			},
 		},
-		"default/ts-2": {
-			Host:       "app.example.com",
-			UnixSocket: "socket2.sock",
+	}
+
+	cnf, err := createTestConfigurator()
+	if err != nil {
+		t.Fatalf("Failed to create a test configurator: %v", err)
+	}
+
+	tests := []struct {
+		listener conf_v1alpha1.Listener
+		expected Listener
+		msg      string
+	}{
+		{
+			listener: conf_v1alpha1.Listener{
+				Name:     "tcp-listener",
+				Port:     53,
+				Protocol: "TCP",
+				IPv4:     "10.0.0.1",
+				IPv6:     "fd00::1",
+			},
+			expected: Listener{
+				Port:     53,
+				Protocol: "TCP",
+				IPv4:     "10.0.0.1",
+				IPv6:     "fd00::1",
+			},
+			msg: "bind addresses added",
 		},
-		"default/ts-3": {
-			Host:       "some.example.com",
-			UnixSocket: "socket3.sock",
+		{
+			listener: conf_v1alpha1.Listener{
+				Name:     "tcp-listener",
+				Port:     54,
+				Protocol: "TCP",
+				IPv4:     "10.0.0.2",
+			},
+			expected: Listener{
+				Port:     54,
+				Protocol: "TCP",
+				IPv4:     "10.0.0.2",
+			},
+			msg: "bind addresses changed",
+		},
+		{
+			listener: conf_v1alpha1.Listener{
+				Name:     "tcp-listener",
+				Port:     54,
+				Protocol: "TCP",
+			},
+			expected: Listener{
+				Port:     54,
+				Protocol: "TCP",
+			},
+			msg: "bind addresses removed",
 		},
 	}
 
-	expectedCfg := &version2.TLSPassthroughHostsConfig{
-		"app.example.com":  "socket2.sock",
-		"some.example.com": "socket3.sock",
+	for _, test := range tests {
+		globalConfiguration := &conf_v1alpha1.GlobalConfiguration{
+			Spec: conf_v1alpha1.GlobalConfigurationSpec{
+				Listeners: []conf_v1alpha1.Listener{test.listener},
+			},
+		}
+
+		updated, deleted, err := cnf.UpdateGlobalConfiguration(globalConfiguration, []*TransportServerEx{tsEx})
+		if err != nil {
+			t.Errorf("UpdateGlobalConfiguration() returned an unexpected error %v for the case of %s", err, test.msg)
+		}
+		if len(updated) != 1 || len(deleted) != 0 {
+			t.Errorf("UpdateGlobalConfiguration() returned %v updated and %v deleted TransportServers but expected 1 updated for the case of %s", len(updated), len(deleted), test.msg)
+		}
+
+		result := cnf.globalCfgParams.Listeners["tcp-listener"]
+		if result != test.expected {
+			t.Errorf("UpdateGlobalConfiguration() set the listener %+v but expected %+v for the case of %s", result, test.expected, test.msg)
+		}
 	}
-	expectedDuplicatedHosts := []string{"app.example.com"}
+}
 
-	resultCfg, resultDuplicatedHosts := generateTLSPassthroughHostsConfig(tlsPassthroughPairs)
-	if !reflect.DeepEqual(resultCfg, expectedCfg) {
-		t.Errorf("generateTLSPassthroughHostsConfig() returned %v but expected %v", resultCfg, expectedCfg)
+func TestUpdateConfigKeepsLastValidErrorLogLevel(t *testing.T) {
+	cnf, err := createTestConfigurator()
+	if err != nil {
+		t.Fatalf("Failed to create a test configurator: %v", err)
 	}
 
-	if !reflect.DeepEqual(resultDuplicatedHosts, expectedDuplicatedHosts) {
-		t.Errorf("generateTLSPassthroughHostsConfig() returned %v but expected %v", resultDuplicatedHosts, expectedDuplicatedHosts)
+	validCfgParams := NewDefaultConfigParams()
+	validCfgParams.MainErrorLogLevel = "warn"
+
+	_, _, err = cnf.UpdateConfig(validCfgParams, nil, map[string]*MergeableIngresses{}, nil)
+	if err != nil {
+		t.Fatalf("UpdateConfig() returned an unexpected error: %v", err)
+	}
+
+	// ParseConfigMap returns an empty level for an invalid error-log-level
+	invalidCfgParams := NewDefaultConfigParams()
+	invalidCfgParams.MainErrorLogLevel = ""
+
+	_, _, err = cnf.UpdateConfig(invalidCfgParams, nil, map[string]*MergeableIngresses{}, nil)
+	if err != nil {
+		t.Fatalf("UpdateConfig() returned an unexpected error: %v", err)
+	}
+
+	if cnf.cfgParams.MainErrorLogLevel != "warn" {
+		t.Errorf("UpdateConfig() set the error log level to %q but expected the last valid level %q", cnf.cfgParams.MainErrorLogLevel, "warn")
+	}
+}
+
+// snippetTestingManager is a fake manager that fails the test of a config with an unknown directive.
+type snippetTestingManager struct {
+	*nginx.FakeManager
+	mainConfig []byte
+	configs    map[string][]byte
+	// configTests is the number of the tests of the configuration
+	configTests int
+}
+
+func newSnippetTestingManager() *snippetTestingManager {
+	return &snippetTestingManager{
+		FakeManager: nginx.NewFakeManager("/etc/nginx"),
+		configs:     make(map[string][]byte),
+	}
+}
+
+func (m *snippetTestingManager) TestMainConfig(content []byte) error {
+	if bytes.Contains(content, []byte("unknown_directive")) {
+		return errors.New(`unknown directive "unknown_directive"`)
+	}
+	return nil
+}
+
+func (m *snippetTestingManager) TestConfig() error {
+	m.configTests++
+	for _, content := range m.configs {
+		if bytes.Contains(content, []byte("unknown_directive")) {
+			return errors.New(`unknown directive "unknown_directive"`)
+		}
+	}
+	return nil
+}
+
+func (m *snippetTestingManager) CreateMainConfig(content []byte) {
+	m.mainConfig = content
+}
+
+func (m *snippetTestingManager) CreateConfig(name string, content []byte) {
+	m.configs[name] = content
+}
+
+func (m *snippetTestingManager) DeleteConfig(name string) {
+	delete(m.configs, name)
+}
+
+func TestUpdateConfigWithInvalidSnippetsKeepsLastValidConfig(t *testing.T) {
+	templateExecutor, err := version1.NewTemplateExecutor("version1/nginx-plus.tmpl", "version1/nginx-plus.ingress.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create a template executor: %v", err)
+	}
+
+	manager := newSnippetTestingManager()
+	cnf := NewConfigurator(manager, createTestStaticConfigParams(), NewDefaultConfigParams(), NewDefaultGlobalConfigParams(), templateExecutor, &version2.TemplateExecutor{}, false, false)
+
+	validCfgParams := NewDefaultConfigParams()
+	validCfgParams.MainHTTPSnippets = []string{"map_hash_bucket_size 128;"}
+
+	_, _, err = cnf.UpdateConfig(validCfgParams, nil, map[string]*MergeableIngresses{}, nil)
+	if err != nil {
+		t.Fatalf("UpdateConfig() returned an unexpected error for valid snippets: %v", err)
+	}
+
+	invalidCfgParams := NewDefaultConfigParams()
+	invalidCfgParams.MainHTTPSnippets = []string{"unknown_directive on;"}
+
+	_, _, err = cnf.UpdateConfig(invalidCfgParams, nil, map[string]*MergeableIngresses{}, nil)
+	if err == nil {
+		t.Errorf("UpdateConfig() returned no error for invalid snippets")
+	}
+
+	if cnf.cfgParams != validCfgParams {
+		t.Errorf("UpdateConfig() didn't keep the last valid ConfigParams for invalid snippets")
+	}
+	if !bytes.Contains(manager.mainConfig, []byte("map_hash_bucket_size 128;")) || bytes.Contains(manager.mainConfig, []byte("unknown_directive")) {
+		t.Errorf("UpdateConfig() didn't keep the last valid main config for invalid snippets:\n%s", manager.mainConfig)
+	}
+}
+
+func createSnippetTestingConfigurator(t *testing.T) (*Configurator, *snippetTestingManager) {
+	templateExecutor, err := version1.NewTemplateExecutor("version1/nginx-plus.tmpl", "version1/nginx-plus.ingress.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create a template executor: %v", err)
+	}
+
+	templateExecutorV2, err := version2.NewTemplateExecutor("version2/nginx-plus.virtualserver.tmpl", "version2/nginx-plus.transportserver.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create a template executor: %v", err)
+	}
+
+	manager := newSnippetTestingManager()
+	cnf := NewConfigurator(manager, createTestStaticConfigParams(), NewDefaultConfigParams(), NewDefaultGlobalConfigParams(), templateExecutor, templateExecutorV2, true, false)
+
+	return cnf, manager
+}
+
+// reloadCountingManager is a fake manager that counts the reloads of NGINX and the writes of the config files.
+type reloadCountingManager struct {
+	*nginx.FakeManager
+	reloads      int
+	httpWrites   int
+	streamWrites int
+}
+
+func (m *reloadCountingManager) Reload() error {
+	m.reloads++
+	return nil
+}
+
+func (m *reloadCountingManager) CreateMainConfig(content []byte) {
+	m.httpWrites++
+}
+
+func (m *reloadCountingManager) CreateConfig(name string, content []byte) {
+	m.httpWrites++
+}
+
+func (m *reloadCountingManager) CreateStreamConfig(name string, content []byte) {
+	m.streamWrites++
+}
+
+func (m *reloadCountingManager) CreateTLSPassthroughHostsConfig(content []byte) {
+	m.streamWrites++
+}
+
+func TestTransportServerChangesDoNotWriteHTTPConfig(t *testing.T) {
+	templateExecutor, err := version1.NewTemplateExecutor("version1/nginx.tmpl", "version1/nginx.ingress.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create a template executor: %v", err)
+	}
+
+	templateExecutorV2, err := version2.NewTemplateExecutor("version2/nginx.virtualserver.tmpl", "version2/nginx.transportserver.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create a template executor: %v", err)
+	}
+
+	manager := &reloadCountingManager{FakeManager: nginx.NewFakeManager("/etc/nginx")}
+	cnf := NewConfigurator(manager, createTestStaticConfigParams(), NewDefaultConfigParams(), NewDefaultGlobalConfigParams(), templateExecutor, templateExecutorV2, false, false)
+
+	newTransportServerEx := func(endpoints ...string) *TransportServerEx {
+		return &TransportServerEx{
+			TransportServer: &conf_v1alpha1.TransportServer{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "secure-app",
+					Namespace: "default",
+				},
+				Spec: conf_v1alpha1.TransportServerSpec{
+					Listener: conf_v1alpha1.TransportServerListener{
+						Name:     "tls-passthrough",
+						Protocol: "TLS_PASSTHROUGH",
+					},
+					Host: "app.example.com",
+					Upstreams: []conf_v1alpha1.Upstream{
+						{
+							Name:    "secure-app",
+							Service: "secure-app",
+							Port:    8443,
+						},
+					},
+					Action: &conf_v1alpha1.Action{
+						Pass: "secure-app",
+					},
+				},
+			},
+			Endpoints: map[string][]string{
+				"default/secure-app:8443": endpoints,
+			},
+		}
+	}
+
+	tests := []struct {
+		update               func() error
+		expectedStreamWrites int
+		expectedReloads      int
+		msg                  string
+	}{
+		{
+			update: func() error {
+				return cnf.AddOrUpdateTransportServer(newTransportServerEx("10.0.0.1:8443"))
+			},
+			expectedStreamWrites: 2,
+			expectedReloads:      1,
+			msg:                  "new TransportServer",
+		},
+		{
+			update: func() error {
+				return cnf.AddOrUpdateTransportServer(newTransportServerEx("10.0.0.1:8443"))
+			},
+			expectedStreamWrites: 0,
+			expectedReloads:      0,
+			msg:                  "unchanged TransportServer",
+		},
+		{
+			update: func() error {
+				return cnf.UpdateEndpointsForTransportServers([]*TransportServerEx{newTransportServerEx("10.0.0.1:8443", "10.0.0.2:8443")})
+			},
+			expectedStreamWrites: 1,
+			expectedReloads:      1,
+			msg:                  "changed endpoints",
+		},
+		{
+			update: func() error {
+				return cnf.DeleteTransportServer("default/secure-app")
+			},
+			expectedStreamWrites: 1,
+			expectedReloads:      1,
+			msg:                  "deleted TransportServer",
+		},
+	}
+
+	for _, test := range tests {
+		manager.streamWrites = 0
+		manager.reloads = 0
+
+		if err := test.update(); err != nil {
+			t.Fatalf("Failed to update the TransportServer for the case of %s: %v", test.msg, err)
+		}
+
+		if manager.httpWrites != 0 {
+			t.Errorf("The HTTP config files were written %d times for the case of %s", manager.httpWrites, test.msg)
+		}
+		if manager.streamWrites != test.expectedStreamWrites {
+			t.Errorf("The stream config files were written %d times but expected %d for the case of %s", manager.streamWrites, test.expectedStreamWrites, test.msg)
+		}
+		if manager.reloads != test.expectedReloads {
+			t.Errorf("NGINX was reloaded %d times but expected %d for the case of %s", manager.reloads, test.expectedReloads, test.msg)
+		}
+	}
+}
+
+func TestUpdateEndpointsForVirtualServersWithConnectionLimitPerEndpoint(t *testing.T) {
+	templateExecutor, err := version1.NewTemplateExecutor("version1/nginx-plus.tmpl", "version1/nginx-plus.ingress.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create a template executor: %v", err)
+	}
+
+	templateExecutorV2, err := version2.NewTemplateExecutor("version2/nginx-plus.virtualserver.tmpl", "version2/nginx-plus.transportserver.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create a template executor: %v", err)
+	}
+
+	manager := &reloadCountingManager{FakeManager: nginx.NewFakeManager("/etc/nginx")}
+	cnf := NewConfigurator(manager, createTestStaticConfigParams(), NewDefaultConfigParams(), NewDefaultGlobalConfigParams(), templateExecutor, templateExecutorV2, true, false)
+
+	newVirtualServerEx := func(endpoints ...string) *VirtualServerEx {
+		return &VirtualServerEx{
+			VirtualServer: &conf_v1.VirtualServer{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "cafe",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerSpec{
+					Host: "cafe.example.com",
+					ConnectionLimit: &conf_v1.ConnectionLimit{
+						Key:                    "${binary_remote_addr}",
+						ConnectionsPerEndpoint: 10,
+						Upstream:               "tea",
+					},
+					Upstreams: []conf_v1.Upstream{
+						{
+							Name:    "tea",
+							Service: "tea-svc",
+							Port:    80,
+						},
+					},
+				},
+			},
+			Endpoints: map[string][]string{
+				"default/tea-svc:80": endpoints,
+			},
+		}
+	}
+
+	if _, err := cnf.AddOrUpdateVirtualServer(newVirtualServerEx("10.0.0.1:80")); err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned an unexpected error: %v", err)
+	}
+	reloads := manager.reloads
+
+	tests := []struct {
+		virtualServerEx *VirtualServerEx
+		expectedReloads int
+		msg             string
+	}{
+		{
+			virtualServerEx: newVirtualServerEx("10.0.0.2:80"),
+			expectedReloads: 0,
+			msg:             "the same number of endpoints is updated via the API",
+		},
+		{
+			virtualServerEx: newVirtualServerEx("10.0.0.1:80", "10.0.0.2:80"),
+			expectedReloads: 1,
+			msg:             "the scaled up endpoints change the connection limit",
+		},
+	}
+
+	for _, test := range tests {
+		err := cnf.UpdateEndpointsForVirtualServers([]*VirtualServerEx{test.virtualServerEx})
+		if err != nil {
+			t.Fatalf("UpdateEndpointsForVirtualServers() returned an unexpected error for the case of %s: %v", test.msg, err)
+		}
+		if manager.reloads-reloads != test.expectedReloads {
+			t.Errorf("UpdateEndpointsForVirtualServers() reloaded NGINX %d times but expected %d for the case of %s", manager.reloads-reloads, test.expectedReloads, test.msg)
+		}
+		reloads = manager.reloads
+	}
+}
+
+// serverUpdatingManager is a fake manager that records the servers of the upstreams updated via the NGINX Plus API.
+type serverUpdatingManager struct {
+	reloadCountingManager
+	servers map[string][]string
+	configs map[string]nginx.ServerConfig
+}
+
+func (m *serverUpdatingManager) UpdateServersInPlus(upstream string, servers []string, config nginx.ServerConfig) error {
+	m.servers[upstream] = servers
+	m.configs[upstream] = config
+	return nil
+}
+
+func TestUpdateEndpointsForVirtualServersWithConsistentHash(t *testing.T) {
+	templateExecutor, err := version1.NewTemplateExecutor("version1/nginx-plus.tmpl", "version1/nginx-plus.ingress.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create a template executor: %v", err)
+	}
+
+	templateExecutorV2, err := version2.NewTemplateExecutor("version2/nginx-plus.virtualserver.tmpl", "version2/nginx-plus.transportserver.tmpl")
+	if err != nil {
+		t.Fatalf("Failed to create a template executor: %v", err)
+	}
+
+	manager := &serverUpdatingManager{
+		reloadCountingManager: reloadCountingManager{FakeManager: nginx.NewFakeManager("/etc/nginx")},
+		servers:               make(map[string][]string),
+		configs:               make(map[string]nginx.ServerConfig),
+	}
+	cnf := NewConfigurator(manager, createTestStaticConfigParams(), NewDefaultConfigParams(), NewDefaultGlobalConfigParams(), templateExecutor, templateExecutorV2, true, false)
+
+	newVirtualServerEx := func(endpoints ...string) *VirtualServerEx {
+		return &VirtualServerEx{
+			VirtualServer: &conf_v1.VirtualServer{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "cafe",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerSpec{
+					Host: "cafe.example.com",
+					Upstreams: []conf_v1.Upstream{
+						{
+							Name:      "tea",
+							Service:   "tea-svc",
+							Port:      80,
+							LBMethod:  "hash $request_uri consistent",
+							SlowStart: "10s",
+						},
+					},
+				},
+			},
+			Endpoints: map[string][]string{
+				"default/tea-svc:80": endpoints,
+			},
+		}
+	}
+
+	if _, err := cnf.AddOrUpdateVirtualServer(newVirtualServerEx("10.0.0.1:80", "10.0.0.2:80")); err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned an unexpected error: %v", err)
+	}
+	reloads := manager.reloads
+
+	tests := []struct {
+		endpoints []string
+		msg       string
+	}{
+		{
+			endpoints: []string{"10.0.0.1:80", "10.0.0.2:80", "10.0.0.3:80"},
+			msg:       "added endpoint",
+		},
+		{
+			endpoints: []string{"10.0.0.1:80", "10.0.0.3:80"},
+			msg:       "removed endpoint",
+		},
+	}
+
+	for _, test := range tests {
+		err := cnf.UpdateEndpointsForVirtualServers([]*VirtualServerEx{newVirtualServerEx(test.endpoints...)})
+		if err != nil {
+			t.Fatalf("UpdateEndpointsForVirtualServers() returned an unexpected error for the case of %s: %v", test.msg, err)
+		}
+
+		// the servers are added and removed via the API, so the ring of the other servers is preserved
+		if manager.reloads != reloads {
+			t.Errorf("UpdateEndpointsForVirtualServers() reloaded NGINX %d times but expected no reloads for the case of %s", manager.reloads-reloads, test.msg)
+		}
+		if servers := manager.servers["vs_default_cafe_tea"]; !reflect.DeepEqual(servers, test.endpoints) {
+			t.Errorf("UpdateEndpointsForVirtualServers() updated the servers %v but expected %v for the case of %s", servers, test.endpoints, test.msg)
+		}
+		// NGINX rejects the servers with slow start in the hash upstreams, which would make the update fall back to a reload
+		if slowStart := manager.configs["vs_default_cafe_tea"].SlowStart; slowStart != "" {
+			t.Errorf("UpdateEndpointsForVirtualServers() updated the servers with slow start %q for the case of %s", slowStart, test.msg)
+		}
+	}
+}
+
+func createVirtualServerExWithSnippets(name string, serverSnippets string, locationSnippets string) *VirtualServerEx {
+	return &VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host:           name + ".example.com",
+				ServerSnippets: serverSnippets,
+				Routes: []conf_v1.Route{
+					{
+						Path: "/",
+						Action: &conf_v1.Action{
+							Return: &conf_v1.ActionReturn{
+								Body: "hello",
+							},
+						},
+						LocationSnippets: locationSnippets,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestAddOrUpdateVirtualServerWithInvalidSnippetsIsIsolated(t *testing.T) {
+	cnf, manager := createSnippetTestingConfigurator(t)
+
+	validVsEx := createVirtualServerExWithSnippets("cafe", "add_header X-Server cafe;", "add_header X-Location cafe;")
+	if _, err := cnf.AddOrUpdateVirtualServer(validVsEx); err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned an unexpected error for valid snippets: %v", err)
+	}
+
+	validVsName := getFileNameForVirtualServer(validVsEx.VirtualServer)
+	if !bytes.Contains(manager.configs[validVsName], []byte("add_header X-Server cafe;")) ||
+		!bytes.Contains(manager.configs[validVsName], []byte("add_header X-Location cafe;")) {
+		t.Errorf("AddOrUpdateVirtualServer() generated a config without the snippets:\n%s", manager.configs[validVsName])
+	}
+
+	tests := []struct {
+		vsEx *VirtualServerEx
+		msg  string
+	}{
+		{
+			vsEx: createVirtualServerExWithSnippets("tea", "unknown_directive on;", ""),
+			msg:  "invalid server snippets",
+		},
+		{
+			vsEx: createVirtualServerExWithSnippets("tea", "", "unknown_directive on;"),
+			msg:  "invalid location snippets",
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := cnf.AddOrUpdateVirtualServer(test.vsEx); err == nil {
+			t.Errorf("AddOrUpdateVirtualServer() returned no error for the case of %s", test.msg)
+		}
+
+		invalidVsName := getFileNameForVirtualServer(test.vsEx.VirtualServer)
+		if _, exists := manager.configs[invalidVsName]; exists {
+			t.Errorf("AddOrUpdateVirtualServer() kept the config of the invalid VirtualServer for the case of %s", test.msg)
+		}
+		if _, exists := cnf.virtualServers[invalidVsName]; exists {
+			t.Errorf("AddOrUpdateVirtualServer() kept the invalid VirtualServer for the case of %s", test.msg)
+		}
+
+		if _, exists := manager.configs[validVsName]; !exists {
+			t.Errorf("AddOrUpdateVirtualServer() removed the config of the valid VirtualServer for the case of %s", test.msg)
+		}
+		if _, exists := cnf.virtualServers[validVsName]; !exists {
+			t.Errorf("AddOrUpdateVirtualServer() removed the valid VirtualServer for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestAddOrUpdateVirtualServerWithInvalidSnippetsKeepsLastAppliedConfig(t *testing.T) {
+	cnf, manager := createSnippetTestingConfigurator(t)
+
+	vsEx := createVirtualServerExWithSnippets("cafe", "add_header X-Server cafe;", "")
+	if _, err := cnf.AddOrUpdateVirtualServer(vsEx); err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned an unexpected error for valid snippets: %v", err)
+	}
+
+	name := getFileNameForVirtualServer(vsEx.VirtualServer)
+	appliedContent := manager.configs[name]
+
+	invalidVsEx := createVirtualServerExWithSnippets("cafe", "unknown_directive on;", "")
+	if _, err := cnf.AddOrUpdateVirtualServer(invalidVsEx); err == nil {
+		t.Errorf("AddOrUpdateVirtualServer() returned no error for invalid snippets")
+	}
+
+	if !bytes.Equal(manager.configs[name], appliedContent) {
+		t.Errorf("AddOrUpdateVirtualServer() didn't restore the last applied config of the VirtualServer:\n%s", manager.configs[name])
+	}
+	if cnf.virtualServers[name] != vsEx {
+		t.Errorf("AddOrUpdateVirtualServer() didn't keep the last applied VirtualServer")
+	}
+}
+
+func TestAddOrUpdateVirtualServerWithSnippetsNextToInvalidConfig(t *testing.T) {
+	cnf, manager := createSnippetTestingConfigurator(t)
+
+	// the configuration is already broken by another resource
+	manager.configs["broken"] = []byte("unknown_directive on;")
+
+	vsEx := createVirtualServerExWithSnippets("cafe", "add_header X-Server cafe;", "")
+	if _, err := cnf.AddOrUpdateVirtualServer(vsEx); err != nil {
+		t.Errorf("AddOrUpdateVirtualServer() returned the error %v caused by another resource", err)
+	}
+
+	name := getFileNameForVirtualServer(vsEx.VirtualServer)
+	if _, exists := manager.configs[name]; !exists {
+		t.Errorf("AddOrUpdateVirtualServer() didn't create the config of the VirtualServer")
+	}
+	if _, exists := cnf.virtualServers[name]; !exists {
+		t.Errorf("AddOrUpdateVirtualServer() didn't add the VirtualServer")
+	}
+}
+
+func TestUpdateConfigSkipsInvalidResources(t *testing.T) {
+	cnf, manager := createSnippetTestingConfigurator(t)
+
+	validIngEx := createCafeIngressEx()
+	invalidIngEx := createCafeIngressEx()
+	invalidIngEx.Ingress.Name = "invalid-cafe-ingress"
+	invalidIngEx.Ingress.Annotations["nginx.org/server-snippets"] = "unknown_directive on;"
+
+	mergeableIngs := createMergeableCafeIngress()
+	invalidMergeableIngs := createMergeableCafeIngress()
+	invalidMergeableIngs.Master.Ingress.Name = "invalid-cafe-ingress-master"
+	invalidMergeableIngs.Minions[0].Ingress.Annotations["nginx.org/location-snippets"] = "unknown_directive on;"
+
+	validVsExes := []*VirtualServerEx{
+		createVirtualServerExWithSnippets("cafe", "", ""),
+		createVirtualServerExWithSnippets("coffee", "add_header X-Server coffee;", ""),
+	}
+	invalidVsEx := createVirtualServerExWithSnippets("tea", "", "unknown_directive on;")
+
+	ingExes := []*IngressEx{&validIngEx, &invalidIngEx}
+	mergeableIngsMap := map[string]*MergeableIngresses{
+		"cafe-ingress-master":         mergeableIngs,
+		"invalid-cafe-ingress-master": invalidMergeableIngs,
+	}
+	vsExes := []*VirtualServerEx{validVsExes[0], invalidVsEx, validVsExes[1]}
+
+	_, resourceErrors, err := cnf.UpdateConfig(NewDefaultConfigParams(), ingExes, mergeableIngsMap, vsExes)
+	if err != nil {
+		t.Fatalf("UpdateConfig() returned an unexpected error: %v", err)
+	}
+
+	if len(resourceErrors) != 3 {
+		t.Errorf("UpdateConfig() returned %d resource errors but expected 3: %v", len(resourceErrors), resourceErrors)
+	}
+	for _, obj := range []runtime.Object{invalidIngEx.Ingress, invalidMergeableIngs.Master.Ingress, invalidVsEx.VirtualServer} {
+		if _, exists := resourceErrors[obj]; !exists {
+			t.Errorf("UpdateConfig() returned no error for the invalid resource %v", obj)
+		}
+	}
+
+	invalidNames := []string{
+		objectMetaToFileName(&invalidIngEx.Ingress.ObjectMeta),
+		objectMetaToFileName(&invalidMergeableIngs.Master.Ingress.ObjectMeta),
+		getFileNameForVirtualServer(invalidVsEx.VirtualServer),
+	}
+	for _, name := range invalidNames {
+		if _, exists := manager.configs[name]; exists {
+			t.Errorf("UpdateConfig() kept the config %v of an invalid resource", name)
+		}
+	}
+
+	validNames := []string{
+		objectMetaToFileName(&validIngEx.Ingress.ObjectMeta),
+		objectMetaToFileName(&mergeableIngs.Master.Ingress.ObjectMeta),
+		getFileNameForVirtualServer(validVsExes[0].VirtualServer),
+		getFileNameForVirtualServer(validVsExes[1].VirtualServer),
+	}
+	for _, name := range validNames {
+		if _, exists := manager.configs[name]; !exists {
+			t.Errorf("UpdateConfig() didn't create the config %v of a valid resource", name)
+		}
+	}
+
+	if len(cnf.ingresses) != 2 || len(cnf.virtualServers) != 2 {
+		t.Errorf("UpdateConfig() kept %d Ingresses and %d VirtualServers but expected 2 and 2", len(cnf.ingresses), len(cnf.virtualServers))
+	}
+}
+
+func TestInvalidIngressWithoutSnippetsDoesNotBreakVirtualServerWithSnippets(t *testing.T) {
+	// an invalid annotation value breaks the config of the Ingress without any snippets
+	invalidIngEx := createCafeIngressEx()
+	invalidIngEx.Ingress.Annotations["nginx.org/proxy-buffer-size"] = "unknown_directive"
+	invalidIngName := objectMetaToFileName(&invalidIngEx.Ingress.ObjectMeta)
+
+	vsEx := createVirtualServerExWithSnippets("coffee", "add_header X-Server coffee;", "add_header X-Location coffee;")
+	vsName := getFileNameForVirtualServer(vsEx.VirtualServer)
+
+	checkConfigs := func(cnf *Configurator, manager *snippetTestingManager, path string) {
+		if _, exists := manager.configs[invalidIngName]; exists {
+			t.Errorf("%s kept the config of the invalid Ingress", path)
+		}
+		if _, exists := cnf.ingresses[invalidIngName]; exists {
+			t.Errorf("%s kept the invalid Ingress", path)
+		}
+		if _, exists := manager.configs[vsName]; !exists {
+			t.Errorf("%s didn't create the config of the valid VirtualServer", path)
+		}
+		if _, exists := cnf.virtualServers[vsName]; !exists {
+			t.Errorf("%s didn't add the valid VirtualServer", path)
+		}
+	}
+
+	cnf, manager := createSnippetTestingConfigurator(t)
+
+	_, resourceErrors, err := cnf.UpdateConfig(NewDefaultConfigParams(), []*IngressEx{&invalidIngEx}, map[string]*MergeableIngresses{}, []*VirtualServerEx{vsEx})
+	if err != nil {
+		t.Fatalf("UpdateConfig() returned an unexpected error: %v", err)
+	}
+	if _, exists := resourceErrors[invalidIngEx.Ingress]; !exists || len(resourceErrors) != 1 {
+		t.Errorf("UpdateConfig() returned the resource errors %v but expected only an error for the invalid Ingress", resourceErrors)
+	}
+	checkConfigs(cnf, manager, "UpdateConfig()")
+
+	cnf, manager = createSnippetTestingConfigurator(t)
+
+	if err := cnf.AddOrUpdateIngress(&invalidIngEx); err == nil {
+		t.Errorf("AddOrUpdateIngress() returned no error for the invalid Ingress")
+	}
+	if _, err := cnf.AddOrUpdateVirtualServer(vsEx); err != nil {
+		t.Errorf("AddOrUpdateVirtualServer() returned an unexpected error for the valid VirtualServer: %v", err)
+	}
+	checkConfigs(cnf, manager, "AddOrUpdateIngress() and AddOrUpdateVirtualServer()")
+}
+
+func TestUpdateConfigTestsConfigurationOnce(t *testing.T) {
+	var vsExes []*VirtualServerEx
+	for _, name := range []string{"cafe", "tea", "coffee", "juice"} {
+		vsExes = append(vsExes, createVirtualServerExWithSnippets(name, "add_header X-Server "+name+";", ""))
+	}
+
+	cnf, manager := createSnippetTestingConfigurator(t)
+
+	_, resourceErrors, err := cnf.UpdateConfig(NewDefaultConfigParams(), []*IngressEx{}, map[string]*MergeableIngresses{}, vsExes)
+	if err != nil {
+		t.Fatalf("UpdateConfig() returned an unexpected error: %v", err)
+	}
+	if len(resourceErrors) != 0 {
+		t.Errorf("UpdateConfig() returned unexpected resource errors %v", resourceErrors)
+	}
+	if manager.configTests != 1 {
+		t.Errorf("UpdateConfig() tested the configuration %d times for %d valid VirtualServers but expected 1", manager.configTests, len(vsExes))
+	}
+
+	// only when the test fails, the configs are tested one by one to find the invalid one
+	vsExes[1] = createVirtualServerExWithSnippets("tea", "unknown_directive;", "")
+	manager.configTests = 0
+
+	_, resourceErrors, err = cnf.UpdateConfig(NewDefaultConfigParams(), []*IngressEx{}, map[string]*MergeableIngresses{}, vsExes)
+	if err != nil {
+		t.Fatalf("UpdateConfig() returned an unexpected error: %v", err)
+	}
+	if _, exists := resourceErrors[vsExes[1].VirtualServer]; !exists || len(resourceErrors) != 1 {
+		t.Errorf("UpdateConfig() returned the resource errors %v but expected only an error for the invalid VirtualServer", resourceErrors)
+	}
+	if expected := len(vsExes) + 2; manager.configTests != expected {
+		t.Errorf("UpdateConfig() tested the configuration %d times but expected %d", manager.configTests, expected)
+	}
+	if content := manager.configs[getFileNameForVirtualServer(vsExes[1].VirtualServer)]; bytes.Contains(content, []byte("unknown_directive")) {
+		t.Errorf("UpdateConfig() didn't restore the last applied config of the invalid VirtualServer")
+	}
+	for _, i := range []int{0, 2, 3} {
+		if _, exists := manager.configs[getFileNameForVirtualServer(vsExes[i].VirtualServer)]; !exists {
+			t.Errorf("UpdateConfig() removed the config of the valid VirtualServer %v", vsExes[i].VirtualServer.Name)
+		}
+	}
+}
+
+func TestAddOrUpdateIngressWithInvalidConfigKeepsLastAppliedConfig(t *testing.T) {
+	cnf, manager := createSnippetTestingConfigurator(t)
+
+	ingEx := createCafeIngressEx()
+	if err := cnf.AddOrUpdateIngress(&ingEx); err != nil {
+		t.Fatalf("AddOrUpdateIngress() returned an unexpected error: %v", err)
+	}
+
+	name := objectMetaToFileName(&ingEx.Ingress.ObjectMeta)
+	appliedContent := manager.configs[name]
+
+	invalidIngEx := createCafeIngressEx()
+	invalidIngEx.Ingress.Annotations["nginx.org/proxy-buffer-size"] = "unknown_directive"
+	if err := cnf.AddOrUpdateIngress(&invalidIngEx); err == nil {
+		t.Errorf("AddOrUpdateIngress() returned no error for the invalid Ingress")
+	}
+
+	if !bytes.Equal(manager.configs[name], appliedContent) {
+		t.Errorf("AddOrUpdateIngress() didn't restore the last applied config of the Ingress:\n%s", manager.configs[name])
+	}
+	if cnf.ingresses[name] != &ingEx {
+		t.Errorf("AddOrUpdateIngress() didn't keep the last applied Ingress")
+	}
+}
+
+func TestUpdateNamespaceConfigSkipsInvalidResources(t *testing.T) {
+	cnf, manager := createSnippetTestingConfigurator(t)
+
+	validIngEx := createCafeIngressEx()
+	invalidIngEx := createCafeIngressEx()
+	invalidIngEx.Ingress.Name = "invalid-cafe-ingress"
+	invalidIngEx.Ingress.Annotations["nginx.org/location-snippets"] = "unknown_directive on;"
+
+	resourceErrors, err := cnf.UpdateNamespaceConfig([]*IngressEx{&invalidIngEx, &validIngEx}, map[string]*MergeableIngresses{})
+	if err != nil {
+		t.Fatalf("UpdateNamespaceConfig() returned an unexpected error: %v", err)
+	}
+
+	if _, exists := resourceErrors[invalidIngEx.Ingress]; !exists || len(resourceErrors) != 1 {
+		t.Errorf("UpdateNamespaceConfig() returned the resource errors %v but expected only an error for the invalid Ingress", resourceErrors)
+	}
+	if _, exists := manager.configs[objectMetaToFileName(&invalidIngEx.Ingress.ObjectMeta)]; exists {
+		t.Errorf("UpdateNamespaceConfig() kept the config of the invalid Ingress")
+	}
+	if _, exists := manager.configs[objectMetaToFileName(&validIngEx.Ingress.ObjectMeta)]; !exists {
+		t.Errorf("UpdateNamespaceConfig() didn't create the config of the valid Ingress")
+	}
+}
+
+func TestGenerateTLSPassthroughHostsConfig(t *testing.T) {
+	tlsPassthroughPairs := map[string]tlsPassthroughPair{
+		"default/ts-1": {
+			Host:       "app.example.com",
+			UnixSocket: "socket1.sock",
+		},
+		"default/ts-2": {
+			Host:       "app.example.com",
+			UnixSocket: "socket2.sock",
+		},
+		"default/ts-3": {
+			Host:       "some.example.com",
+			UnixSocket: "socket3.sock",
+		},
+	}
+
+	expectedCfg := &version2.TLSPassthroughHostsConfig{
+		"app.example.com":  "socket2.sock",
+		"some.example.com": "socket3.sock",
+	}
+	expectedDuplicatedHosts := []string{"app.example.com"}
+
+	resultCfg, resultDuplicatedHosts := generateTLSPassthroughHostsConfig(tlsPassthroughPairs)
+	if !reflect.DeepEqual(resultCfg, expectedCfg) {
+		t.Errorf("generateTLSPassthroughHostsConfig() returned %v but expected %v", resultCfg, expectedCfg)
+	}
+
+	if !reflect.DeepEqual(resultDuplicatedHosts, expectedDuplicatedHosts) {
+		t.Errorf("generateTLSPassthroughHostsConfig() returned %v but expected %v", resultDuplicatedHosts, expectedDuplicatedHosts)
+	}
+}
+
+func TestGetSecretKeyForReference(t *testing.T) {
+	tests := []struct {
+		secretRef string
+		expected  string
+	}{
+		{
+			secretRef: "tls-secret",
+			expected:  "cafe/tls-secret",
+		},
+		{
+			secretRef: "shared-secrets/tls-secret",
+			expected:  "shared-secrets/tls-secret",
+		},
+	}
+
+	for _, test := range tests {
+		result := GetSecretKeyForReference("cafe", test.secretRef)
+		if result != test.expected {
+			t.Errorf("GetSecretKeyForReference(%q) returned %q but expected %q", test.secretRef, result, test.expected)
+		}
 	}
 }
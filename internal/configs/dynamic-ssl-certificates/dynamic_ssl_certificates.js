@@ -0,0 +1,54 @@
+/*
+ * The lookup of the TLS certificates of the VirtualServers with dynamic certificates.
+ * The function is imported as the dynamic_ssl_certificates module in the main configuration and sets the
+ * $dynamic_ssl_certificate variable, which NGINX evaluates during the TLS handshake, so that a certificate is loaded
+ * only when a client requests its server name.
+ */
+
+var fs = require('fs');
+
+// The certificate of the default server, used when the directory doesn't have a certificate for the server name.
+var defaultCertificate = '/etc/nginx/secrets/default';
+
+// The server name must be a DNS name, so that a client can't make NGINX read a file outside of the directory.
+var serverNameRegexp = /^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$/;
+
+/*
+ * Returns the file with the certificate and the key for the server name of the TLS handshake. The directory has
+ * a file <server name>.pem for every host, for example, cafe.example.com.pem, and a file _.<domain>.pem for
+ * a wildcard certificate, for example, _.example.com.pem for *.example.com.
+ */
+function certificate(r) {
+    var serverName = (r.variables.ssl_server_name || '').toLowerCase();
+    if (!serverNameRegexp.test(serverName)) {
+        return defaultCertificate;
+    }
+
+    var directory = r.variables.dynamic_ssl_certificates_directory;
+
+    var file = directory + '/' + serverName + '.pem';
+    if (isReadable(file)) {
+        return file;
+    }
+
+    var dot = serverName.indexOf('.');
+    if (dot != -1) {
+        var wildcardFile = directory + '/_' + serverName.substring(dot) + '.pem';
+        if (isReadable(wildcardFile)) {
+            return wildcardFile;
+        }
+    }
+
+    return defaultCertificate;
+}
+
+function isReadable(file) {
+    try {
+        fs.accessSync(file, fs.constants.R_OK);
+        return true;
+    } catch (e) {
+        return false;
+    }
+}
+
+export default {certificate};
@@ -1,6 +1,62 @@
 package configs
 
-import conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+import (
+	"fmt"
+
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+)
+
+// ListenerConflict is a listener of a GlobalConfiguration that was ignored because it conflicts with a listener
+// of a preceding GlobalConfiguration.
+type ListenerConflict struct {
+	GlobalConfiguration *conf_v1alpha1.GlobalConfiguration
+	Listener            string
+	Reason              string
+}
+
+// MergeGlobalConfigurations merges the listeners of the GlobalConfigurations in their order. A listener is ignored if
+// a listener of a preceding GlobalConfiguration has the same name or uses the same port and protocol.
+// The GlobalConfigurations must be valid.
+func MergeGlobalConfigurations(globalConfigurations []*conf_v1alpha1.GlobalConfiguration) (*conf_v1alpha1.GlobalConfiguration, []ListenerConflict) {
+	merged := &conf_v1alpha1.GlobalConfiguration{}
+	var conflicts []ListenerConflict
+
+	// owners map the listener names and the port and protocol pairs to the GlobalConfigurations of the listeners
+	nameOwners := make(map[string]string)
+	portOwners := make(map[string]string)
+
+	for _, gc := range globalConfigurations {
+		key := fmt.Sprintf("%s/%s", gc.Namespace, gc.Name)
+
+		for _, l := range gc.Spec.Listeners {
+			portProtocol := fmt.Sprintf("%d/%s", l.Port, l.Protocol)
+
+			if owner, exists := nameOwners[l.Name]; exists {
+				conflicts = append(conflicts, ListenerConflict{
+					GlobalConfiguration: gc,
+					Listener:            l.Name,
+					Reason:              fmt.Sprintf("the name is already used by a listener of GlobalConfiguration %s", owner),
+				})
+				continue
+			}
+
+			if owner, exists := portOwners[portProtocol]; exists {
+				conflicts = append(conflicts, ListenerConflict{
+					GlobalConfiguration: gc,
+					Listener:            l.Name,
+					Reason:              fmt.Sprintf("port %s is already used by a listener of GlobalConfiguration %s", portProtocol, owner),
+				})
+				continue
+			}
+
+			nameOwners[l.Name] = key
+			portOwners[portProtocol] = key
+			merged.Spec.Listeners = append(merged.Spec.Listeners, l)
+		}
+	}
+
+	return merged, conflicts
+}
 
 func ParseGlobalConfiguration(gc *conf_v1alpha1.GlobalConfiguration, tlsPassthrough bool) *GlobalConfigParams {
 	gcfgParams := NewDefaultGlobalConfigParams()
@@ -10,8 +66,15 @@ func ParseGlobalConfiguration(gc *conf_v1alpha1.GlobalConfiguration, tlsPassthro
 
 	for _, l := range gc.Spec.Listeners {
 		gcfgParams.Listeners[l.Name] = Listener{
-			Port:     l.Port,
-			Protocol: l.Protocol,
+			Port:        l.Port,
+			Protocol:    l.Protocol,
+			IPv4:        l.IPv4,
+			IPv6:        l.IPv6,
+			Backlog:     l.Backlog,
+			Reuseport:   l.Reuseport,
+			Fastopen:    l.Fastopen,
+			Deferred:    l.Deferred,
+			SoKeepalive: l.SoKeepalive,
 		}
 	}
 
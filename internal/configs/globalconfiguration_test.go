@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestParseGlobalConfiguration(t *testing.T) {
@@ -17,9 +18,26 @@ func TestParseGlobalConfiguration(t *testing.T) {
 					Protocol: "TCP",
 				},
 				{
-					Name:     "udp-listener",
-					Port:     53,
-					Protocol: "UDP",
+					Name:      "udp-listener",
+					Port:      53,
+					Protocol:  "UDP",
+					Reuseport: true,
+				},
+				{
+					Name:     "dns-tcp-listener",
+					Port:     5353,
+					Protocol: "TCP",
+					IPv4:     "10.0.0.1",
+					IPv6:     "fd00::1",
+					Backlog:  1024,
+				},
+				{
+					Name:        "keepalive-tcp-listener",
+					Port:        5354,
+					Protocol:    "TCP",
+					Fastopen:    256,
+					Deferred:    true,
+					SoKeepalive: "30m::10",
 				},
 			},
 		},
@@ -32,8 +50,23 @@ func TestParseGlobalConfiguration(t *testing.T) {
 				Protocol: "TCP",
 			},
 			"udp-listener": {
-				Port:     53,
-				Protocol: "UDP",
+				Port:      53,
+				Protocol:  "UDP",
+				Reuseport: true,
+			},
+			"dns-tcp-listener": {
+				Port:     5353,
+				Protocol: "TCP",
+				IPv4:     "10.0.0.1",
+				IPv6:     "fd00::1",
+				Backlog:  1024,
+			},
+			"keepalive-tcp-listener": {
+				Port:        5354,
+				Protocol:    "TCP",
+				Fastopen:    256,
+				Deferred:    true,
+				SoKeepalive: "30m::10",
 			},
 		},
 	}
@@ -89,3 +122,100 @@ func TestParseGlobalConfigurationWithTLSPasstrough(t *testing.T) {
 		t.Errorf("ParseGlobalConfiguration() returned \n%+v but expected \n%+v", result, expected)
 	}
 }
+
+func TestMergeGlobalConfigurations(t *testing.T) {
+	teamA := &v1alpha1.GlobalConfiguration{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "global-configuration",
+			Namespace: "team-a",
+		},
+		Spec: v1alpha1.GlobalConfigurationSpec{
+			Listeners: []v1alpha1.Listener{
+				{
+					Name:     "dns-tcp",
+					Port:     53,
+					Protocol: "TCP",
+				},
+				{
+					Name:     "dns-udp",
+					Port:     53,
+					Protocol: "UDP",
+				},
+			},
+		},
+	}
+	teamB := &v1alpha1.GlobalConfiguration{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "global-configuration",
+			Namespace: "team-b",
+		},
+		Spec: v1alpha1.GlobalConfigurationSpec{
+			Listeners: []v1alpha1.Listener{
+				{
+					Name:     "postgres",
+					Port:     5432,
+					Protocol: "TCP",
+				},
+				{
+					Name:     "dns-tcp",
+					Port:     5353,
+					Protocol: "TCP",
+				},
+				{
+					Name:     "other-dns-udp",
+					Port:     53,
+					Protocol: "UDP",
+				},
+			},
+		},
+	}
+
+	expectedListeners := []v1alpha1.Listener{
+		{
+			Name:     "dns-tcp",
+			Port:     53,
+			Protocol: "TCP",
+		},
+		{
+			Name:     "dns-udp",
+			Port:     53,
+			Protocol: "UDP",
+		},
+		{
+			Name:     "postgres",
+			Port:     5432,
+			Protocol: "TCP",
+		},
+	}
+	expectedConflicts := []ListenerConflict{
+		{
+			GlobalConfiguration: teamB,
+			Listener:            "dns-tcp",
+			Reason:              "the name is already used by a listener of GlobalConfiguration team-a/global-configuration",
+		},
+		{
+			GlobalConfiguration: teamB,
+			Listener:            "other-dns-udp",
+			Reason:              "port 53/UDP is already used by a listener of GlobalConfiguration team-a/global-configuration",
+		},
+	}
+
+	merged, conflicts := MergeGlobalConfigurations([]*v1alpha1.GlobalConfiguration{teamA, teamB})
+	if !reflect.DeepEqual(merged.Spec.Listeners, expectedListeners) {
+		t.Errorf("MergeGlobalConfigurations() returned the listeners %+v but expected %+v", merged.Spec.Listeners, expectedListeners)
+	}
+	if !reflect.DeepEqual(conflicts, expectedConflicts) {
+		t.Errorf("MergeGlobalConfigurations() returned the conflicts %+v but expected %+v", conflicts, expectedConflicts)
+	}
+
+	// the order of the GlobalConfigurations decides which listener is ignored
+	_, conflicts = MergeGlobalConfigurations([]*v1alpha1.GlobalConfiguration{teamB, teamA})
+	for _, c := range conflicts {
+		if c.GlobalConfiguration != teamA {
+			t.Errorf("MergeGlobalConfigurations() returned a conflict %+v of the first GlobalConfiguration", c)
+		}
+	}
+	if len(conflicts) != 2 {
+		t.Errorf("MergeGlobalConfigurations() returned %d conflicts but expected 2", len(conflicts))
+	}
+}
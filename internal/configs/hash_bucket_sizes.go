@@ -0,0 +1,262 @@
+package configs
+
+import (
+	"strconv"
+
+	"github.com/golang/glog"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+)
+
+// defaultHashBucketSize is the default size of the buckets of the NGINX hashes, which equals the size of
+// the processor's cache line on most platforms.
+const defaultHashBucketSize = 64
+
+// minHashBucketSize is the smallest bucket size the auto-tuning sets.
+const minHashBucketSize = 32
+
+// defaultProxyHeadersHashMaxSize is the default max size of the proxy headers hashes of NGINX.
+const defaultProxyHeadersHashMaxSize = 512
+
+// baseProxyHeaders is the number of the headers that every location adds to its proxy headers hashes besides the headers
+// of the resources: the request headers set by the proxy module and by the templates, and the response headers
+// hidden by the proxy module.
+const baseProxyHeaders = 16
+
+// pointerSize is the size of a pointer on 64-bit platforms, which NGINX uses to align the elements of a hash.
+const pointerSize = 8
+
+// getHashBucketSizeForKey returns the smallest power of two bucket size of an NGINX hash that fits
+// an element with a key of the length. An element takes a pointer and the key with its length, aligned to
+// the size of a pointer, and every bucket ends with a pointer.
+func getHashBucketSizeForKey(length int) uint64 {
+	elementSize := uint64(pointerSize + (length+2+pointerSize-1)/pointerSize*pointerSize)
+	required := elementSize + pointerSize
+
+	size := uint64(minHashBucketSize)
+	for size < required {
+		size *= 2
+	}
+
+	return size
+}
+
+// getLongestServerNameLength returns the length of the longest server name among the hosts of the Ingress
+// and VirtualServer resources.
+func getLongestServerNameLength(ingresses map[string]*IngressEx, virtualServers map[string]*VirtualServerEx) int {
+	longest := 0
+
+	for _, ingEx := range ingresses {
+		for _, rule := range ingEx.Ingress.Spec.Rules {
+			if len(rule.Host) > longest {
+				longest = len(rule.Host)
+			}
+		}
+		for _, tls := range ingEx.Ingress.Spec.TLS {
+			for _, host := range tls.Hosts {
+				if len(host) > longest {
+					longest = len(host)
+				}
+			}
+		}
+	}
+
+	for _, vsEx := range virtualServers {
+		if len(vsEx.VirtualServer.Spec.Host) > longest {
+			longest = len(vsEx.VirtualServer.Spec.Host)
+		}
+	}
+
+	return longest
+}
+
+// parseServerNamesHashBucketSize returns the size set in the server_names_hash_bucket_size directive.
+// If the directive is not set, NGINX uses the default size.
+func parseServerNamesHashBucketSize(size string) (uint64, error) {
+	if size == "" {
+		return defaultHashBucketSize, nil
+	}
+
+	return strconv.ParseUint(size, 10, 64)
+}
+
+// getMinHashBucketSize returns the smaller of the server names and map hash bucket sizes of the main config.
+func getMinHashBucketSize(mainCfg *version1.MainConfig) uint64 {
+	mapSize := mainCfg.MapHashBucketSize
+	if mapSize == 0 {
+		mapSize = defaultHashBucketSize
+	}
+
+	serverNamesSize, err := parseServerNamesHashBucketSize(mainCfg.ServerNamesHashBucketSize)
+	if err != nil || serverNamesSize > mapSize {
+		return mapSize
+	}
+
+	return serverNamesSize
+}
+
+// tuneHashBucketSizes increases the server names and map hash bucket sizes of the main config when they are too
+// small for the longest server name, so that NGINX doesn't fail to build the hashes of the server names and
+// of the maps keyed by the hosts. A size that is large enough, including a size set in the ConfigMap, is kept.
+func tuneHashBucketSizes(mainCfg *version1.MainConfig, longestServerNameLength int) {
+	required := getHashBucketSizeForKey(longestServerNameLength)
+
+	serverNamesSize, err := parseServerNamesHashBucketSize(mainCfg.ServerNamesHashBucketSize)
+	if err == nil && serverNamesSize < required {
+		glog.Warningf("The server names hash bucket size %v is too small for the longest server name of %v characters, using %v instead", serverNamesSize, longestServerNameLength, required)
+		mainCfg.ServerNamesHashBucketSize = strconv.FormatUint(required, 10)
+	}
+
+	mapSize := mainCfg.MapHashBucketSize
+	if mapSize == 0 {
+		mapSize = defaultHashBucketSize
+	}
+	if mapSize < required {
+		glog.Warningf("The map hash bucket size %v is too small for the longest server name of %v characters, using %v instead", mapSize, longestServerNameLength, required)
+		mainCfg.MapHashBucketSize = required
+	}
+}
+
+// proxyHeaders describes the largest proxy headers hash among the locations: the number of its headers and the length
+// of the longest header name.
+type proxyHeaders struct {
+	count         int
+	longestLength int
+}
+
+// add adds the headers of a location hash.
+func (h *proxyHeaders) add(names []string) {
+	if count := baseProxyHeaders + len(names); count > h.count {
+		h.count = count
+	}
+
+	for _, name := range names {
+		if len(name) > h.longestLength {
+			h.longestLength = len(name)
+		}
+	}
+}
+
+// proxyHeadersHashSizes are the bucket size and the max size of the proxy headers hashes.
+type proxyHeadersHashSizes struct {
+	bucketSize uint64
+	maxSize    uint64
+}
+
+// getProxyHeaders returns the largest proxy headers hash among the locations of the VirtualServer resources and
+// the locations of the Ingress resources, which hide the response headers of the ConfigMap. NGINX builds separate hashes
+// for the request headers a location sets and for the response headers it hides, with the same sizes.
+func getProxyHeaders(virtualServers map[string]*VirtualServerEx, hideHeaders []string) proxyHeaders {
+	var headers proxyHeaders
+	headers.add(hideHeaders)
+
+	addAction := func(action *conf_v1.Action) {
+		if action == nil {
+			return
+		}
+
+		var setNames []string
+		if action.ClientCertForwarding != nil {
+			for _, h := range action.ClientCertForwarding.Headers {
+				setNames = append(setNames, h.Name)
+			}
+		}
+
+		var hideNames []string
+		if action.Proxy != nil {
+			if action.Proxy.RequestHeaders != nil {
+				for _, h := range action.Proxy.RequestHeaders.Set {
+					setNames = append(setNames, h.Name)
+				}
+			}
+			if action.Proxy.ResponseHeaders != nil {
+				hideNames = action.Proxy.ResponseHeaders.Hide
+			}
+		}
+
+		headers.add(setNames)
+		headers.add(hideNames)
+	}
+
+	addRoutes := func(routes []conf_v1.Route) {
+		for _, r := range routes {
+			addAction(r.Action)
+			for _, s := range r.Splits {
+				addAction(s.Action)
+			}
+			for _, m := range r.Matches {
+				addAction(m.Action)
+				for _, s := range m.Splits {
+					addAction(s.Action)
+				}
+			}
+		}
+	}
+
+	for _, vsEx := range virtualServers {
+		addRoutes(vsEx.VirtualServer.Spec.Routes)
+		for _, vsr := range vsEx.VirtualServerRoutes {
+			addRoutes(vsr.Spec.Subroutes)
+		}
+	}
+
+	return headers
+}
+
+// getRequiredProxyHeadersHashSizes returns the smallest proxy headers hash sizes that fit the headers. The max size
+// is the smallest power of two that is at least twice the number of the headers, so that NGINX can find a hash size
+// that spreads the headers evenly across the buckets.
+func getRequiredProxyHeadersHashSizes(headers proxyHeaders) proxyHeadersHashSizes {
+	maxSize := uint64(defaultProxyHeadersHashMaxSize)
+	for maxSize < uint64(2*headers.count) {
+		maxSize *= 2
+	}
+
+	return proxyHeadersHashSizes{
+		bucketSize: getHashBucketSizeForKey(headers.longestLength),
+		maxSize:    maxSize,
+	}
+}
+
+// getProxyHeadersHashSizes returns the proxy headers hash sizes of the main config. If a size is not set,
+// NGINX uses the default size.
+func getProxyHeadersHashSizes(mainCfg *version1.MainConfig) proxyHeadersHashSizes {
+	sizes := proxyHeadersHashSizes{
+		bucketSize: mainCfg.ProxyHeadersHashBucketSize,
+		maxSize:    mainCfg.ProxyHeadersHashMaxSize,
+	}
+
+	if sizes.bucketSize == 0 {
+		sizes.bucketSize = defaultHashBucketSize
+	}
+	if sizes.maxSize == 0 {
+		sizes.maxSize = defaultProxyHeadersHashMaxSize
+	}
+
+	return sizes
+}
+
+// fits checks if the sizes are at least as large as the required sizes.
+func (s proxyHeadersHashSizes) fits(required proxyHeadersHashSizes) bool {
+	return s.bucketSize >= required.bucketSize && s.maxSize >= required.maxSize
+}
+
+// tuneProxyHeadersHashSizes increases the proxy headers hash sizes of the main config when they are too small
+// for the headers, so that NGINX doesn't fail to build the proxy headers hashes. A size that is large enough,
+// including a size set in the ConfigMap, is kept.
+func tuneProxyHeadersHashSizes(mainCfg *version1.MainConfig, headers proxyHeaders) {
+	required := getRequiredProxyHeadersHashSizes(headers)
+	current := getProxyHeadersHashSizes(mainCfg)
+
+	if current.bucketSize < required.bucketSize {
+		glog.Warningf("The proxy headers hash bucket size %v is too small for the longest header name of %v characters, using %v instead",
+			current.bucketSize, headers.longestLength, required.bucketSize)
+		mainCfg.ProxyHeadersHashBucketSize = required.bucketSize
+	}
+
+	if current.maxSize < required.maxSize {
+		glog.Warningf("The proxy headers hash max size %v is too small for the %v headers of a location, using %v instead",
+			current.maxSize, headers.count, required.maxSize)
+		mainCfg.ProxyHeadersHashMaxSize = required.maxSize
+	}
+}
@@ -0,0 +1,401 @@
+package configs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	extensions "k8s.io/api/extensions/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetHashBucketSizeForKey(t *testing.T) {
+	tests := []struct {
+		length   int
+		expected uint64
+	}{
+		{
+			length:   0,
+			expected: 32,
+		},
+		{
+			length:   10,
+			expected: 32,
+		},
+		{
+			length:   38,
+			expected: 64,
+		},
+		{
+			length:   47,
+			expected: 128,
+		},
+		{
+			length:   238,
+			expected: 256,
+		},
+		{
+			length:   239,
+			expected: 512,
+		},
+	}
+
+	for _, test := range tests {
+		result := getHashBucketSizeForKey(test.length)
+		if result != test.expected {
+			t.Errorf("getHashBucketSizeForKey(%v) returned %v but expected %v", test.length, result, test.expected)
+		}
+	}
+}
+
+func TestGetLongestServerNameLength(t *testing.T) {
+	ingresses := map[string]*IngressEx{
+		"default-cafe": {
+			Ingress: &extensions.Ingress{
+				Spec: extensions.IngressSpec{
+					TLS: []extensions.IngressTLS{
+						{
+							Hosts: []string{"very-long-secure-name.example.com"},
+						},
+					},
+					Rules: []extensions.IngressRule{
+						{
+							Host: "cafe.example.com",
+						},
+					},
+				},
+			},
+		},
+	}
+	virtualServers := map[string]*VirtualServerEx{
+		"vs_default_tea": {
+			VirtualServer: &conf_v1.VirtualServer{
+				Spec: conf_v1.VirtualServerSpec{
+					Host: "tea.example.com",
+				},
+			},
+		},
+	}
+
+	expected := len("very-long-secure-name.example.com")
+
+	result := getLongestServerNameLength(ingresses, virtualServers)
+	if result != expected {
+		t.Errorf("getLongestServerNameLength() returned %v but expected %v", result, expected)
+	}
+}
+
+func TestTuneHashBucketSizes(t *testing.T) {
+	tests := []struct {
+		serverNamesHashBucketSize         string
+		mapHashBucketSize                 uint64
+		length                            int
+		expectedServerNamesHashBucketSize string
+		expectedMapHashBucketSize         uint64
+		msg                               string
+	}{
+		{
+			serverNamesHashBucketSize:         "256",
+			mapHashBucketSize:                 0,
+			length:                            16,
+			expectedServerNamesHashBucketSize: "256",
+			expectedMapHashBucketSize:         0,
+			msg:                               "short name",
+		},
+		{
+			serverNamesHashBucketSize:         "256",
+			mapHashBucketSize:                 0,
+			length:                            100,
+			expectedServerNamesHashBucketSize: "256",
+			expectedMapHashBucketSize:         128,
+			msg:                               "long name with the default map hash bucket size",
+		},
+		{
+			serverNamesHashBucketSize:         "256",
+			mapHashBucketSize:                 256,
+			length:                            250,
+			expectedServerNamesHashBucketSize: "512",
+			expectedMapHashBucketSize:         512,
+			msg:                               "very long name",
+		},
+		{
+			serverNamesHashBucketSize:         "1024",
+			mapHashBucketSize:                 1024,
+			length:                            250,
+			expectedServerNamesHashBucketSize: "1024",
+			expectedMapHashBucketSize:         1024,
+			msg:                               "large sizes from the ConfigMap",
+		},
+		{
+			serverNamesHashBucketSize:         "",
+			mapHashBucketSize:                 0,
+			length:                            50,
+			expectedServerNamesHashBucketSize: "128",
+			expectedMapHashBucketSize:         128,
+			msg:                               "default NGINX sizes",
+		},
+	}
+
+	for _, test := range tests {
+		mainCfg := &version1.MainConfig{
+			ServerNamesHashBucketSize: test.serverNamesHashBucketSize,
+			MapHashBucketSize:         test.mapHashBucketSize,
+		}
+
+		tuneHashBucketSizes(mainCfg, test.length)
+
+		if mainCfg.ServerNamesHashBucketSize != test.expectedServerNamesHashBucketSize {
+			t.Errorf("tuneHashBucketSizes() set ServerNamesHashBucketSize %q but expected %q for the case of %s",
+				mainCfg.ServerNamesHashBucketSize, test.expectedServerNamesHashBucketSize, test.msg)
+		}
+		if mainCfg.MapHashBucketSize != test.expectedMapHashBucketSize {
+			t.Errorf("tuneHashBucketSizes() set MapHashBucketSize %v but expected %v for the case of %s",
+				mainCfg.MapHashBucketSize, test.expectedMapHashBucketSize, test.msg)
+		}
+	}
+}
+
+func TestAddOrUpdateVirtualServerWithLongHostTunesHashBucketSizes(t *testing.T) {
+	cnf, err := createTestConfigurator()
+	if err != nil {
+		t.Fatalf("Failed to create a test configurator: %v", err)
+	}
+
+	host := strings.Repeat("a", 100) + ".example.com"
+	vsEx := &VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: host,
+			},
+		},
+	}
+
+	_, err = cnf.AddOrUpdateVirtualServer(vsEx)
+	if err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned unexpected error: %v", err)
+	}
+
+	expected := getHashBucketSizeForKey(len(host))
+	if cnf.hashBucketSize != expected {
+		t.Errorf("AddOrUpdateVirtualServer() set the hash bucket size %v but expected %v", cnf.hashBucketSize, expected)
+	}
+}
+
+func TestGetProxyHeaders(t *testing.T) {
+	virtualServers := map[string]*VirtualServerEx{
+		"vs_default_cafe": {
+			VirtualServer: &conf_v1.VirtualServer{
+				Spec: conf_v1.VirtualServerSpec{
+					Routes: []conf_v1.Route{
+						{
+							Path: "/tea",
+							Action: &conf_v1.Action{
+								Proxy: &conf_v1.ActionProxy{
+									RequestHeaders: &conf_v1.ProxyRequestHeaders{
+										Set: []conf_v1.Header{{Name: "X-Tea"}},
+									},
+								},
+							},
+						},
+						{
+							Path: "/coffee",
+							Matches: []conf_v1.Match{
+								{
+									Action: &conf_v1.Action{
+										Proxy: &conf_v1.ActionProxy{
+											ResponseHeaders: &conf_v1.ProxyResponseHeaders{
+												Hide: []string{"X-Coffee-1", "X-Coffee-2", "X-Coffee-3"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+				{
+					Spec: conf_v1.VirtualServerRouteSpec{
+						Subroutes: []conf_v1.Route{
+							{
+								Path: "/juice",
+								Splits: []conf_v1.Split{
+									{
+										Action: &conf_v1.Action{
+											Proxy: &conf_v1.ActionProxy{
+												RequestHeaders: &conf_v1.ProxyRequestHeaders{
+													Set: []conf_v1.Header{{Name: "X-Very-Long-Juice-Header"}},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		hideHeaders []string
+		expected    proxyHeaders
+		msg         string
+	}{
+		{
+			hideHeaders: nil,
+			expected:    proxyHeaders{count: baseProxyHeaders + 3, longestLength: len("X-Very-Long-Juice-Header")},
+			msg:         "headers of the VirtualServer and the VirtualServerRoute",
+		},
+		{
+			hideHeaders: []string{"X-1", "X-2", "X-3", "X-4"},
+			expected:    proxyHeaders{count: baseProxyHeaders + 4, longestLength: len("X-Very-Long-Juice-Header")},
+			msg:         "hidden headers of the ConfigMap",
+		},
+	}
+
+	for _, test := range tests {
+		result := getProxyHeaders(virtualServers, test.hideHeaders)
+		if result != test.expected {
+			t.Errorf("getProxyHeaders() returned %+v but expected %+v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGetRequiredProxyHeadersHashSizes(t *testing.T) {
+	tests := []struct {
+		headers  proxyHeaders
+		expected proxyHeadersHashSizes
+	}{
+		{
+			headers:  proxyHeaders{count: 20, longestLength: 10},
+			expected: proxyHeadersHashSizes{bucketSize: 32, maxSize: 512},
+		},
+		{
+			headers:  proxyHeaders{count: 256, longestLength: 60},
+			expected: proxyHeadersHashSizes{bucketSize: 128, maxSize: 512},
+		},
+		{
+			headers:  proxyHeaders{count: 300, longestLength: 10},
+			expected: proxyHeadersHashSizes{bucketSize: 32, maxSize: 1024},
+		},
+	}
+
+	for _, test := range tests {
+		result := getRequiredProxyHeadersHashSizes(test.headers)
+		if result != test.expected {
+			t.Errorf("getRequiredProxyHeadersHashSizes(%+v) returned %+v but expected %+v", test.headers, result, test.expected)
+		}
+	}
+}
+
+func TestTuneProxyHeadersHashSizes(t *testing.T) {
+	tests := []struct {
+		bucketSize         uint64
+		maxSize            uint64
+		headers            proxyHeaders
+		expectedBucketSize uint64
+		expectedMaxSize    uint64
+		msg                string
+	}{
+		{
+			bucketSize:         0,
+			maxSize:            0,
+			headers:            proxyHeaders{count: 20, longestLength: 17},
+			expectedBucketSize: 0,
+			expectedMaxSize:    0,
+			msg:                "few short headers with the default NGINX sizes",
+		},
+		{
+			bucketSize:         0,
+			maxSize:            0,
+			headers:            proxyHeaders{count: 300, longestLength: 100},
+			expectedBucketSize: 128,
+			expectedMaxSize:    1024,
+			msg:                "many long headers with the default NGINX sizes",
+		},
+		{
+			bucketSize:         64,
+			maxSize:            2048,
+			headers:            proxyHeaders{count: 300, longestLength: 100},
+			expectedBucketSize: 128,
+			expectedMaxSize:    2048,
+			msg:                "large max size from the ConfigMap",
+		},
+		{
+			bucketSize:         256,
+			maxSize:            4096,
+			headers:            proxyHeaders{count: 300, longestLength: 100},
+			expectedBucketSize: 256,
+			expectedMaxSize:    4096,
+			msg:                "large sizes from the ConfigMap",
+		},
+	}
+
+	for _, test := range tests {
+		mainCfg := &version1.MainConfig{
+			ProxyHeadersHashBucketSize: test.bucketSize,
+			ProxyHeadersHashMaxSize:    test.maxSize,
+		}
+
+		tuneProxyHeadersHashSizes(mainCfg, test.headers)
+
+		if mainCfg.ProxyHeadersHashBucketSize != test.expectedBucketSize {
+			t.Errorf("tuneProxyHeadersHashSizes() set ProxyHeadersHashBucketSize %v but expected %v for the case of %s",
+				mainCfg.ProxyHeadersHashBucketSize, test.expectedBucketSize, test.msg)
+		}
+		if mainCfg.ProxyHeadersHashMaxSize != test.expectedMaxSize {
+			t.Errorf("tuneProxyHeadersHashSizes() set ProxyHeadersHashMaxSize %v but expected %v for the case of %s",
+				mainCfg.ProxyHeadersHashMaxSize, test.expectedMaxSize, test.msg)
+		}
+	}
+}
+
+func TestAddOrUpdateVirtualServerWithLongHeaderTunesProxyHeadersHashSizes(t *testing.T) {
+	cnf, err := createTestConfigurator()
+	if err != nil {
+		t.Fatalf("Failed to create a test configurator: %v", err)
+	}
+
+	name := "X-" + strings.Repeat("a", 100)
+	vsEx := &VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Routes: []conf_v1.Route{
+					{
+						Path: "/",
+						Action: &conf_v1.Action{
+							Proxy: &conf_v1.ActionProxy{
+								RequestHeaders: &conf_v1.ProxyRequestHeaders{
+									Set: []conf_v1.Header{{Name: name, Value: "tea"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	_, err = cnf.AddOrUpdateVirtualServer(vsEx)
+	if err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned unexpected error: %v", err)
+	}
+
+	expected := proxyHeadersHashSizes{bucketSize: getHashBucketSizeForKey(len(name)), maxSize: defaultProxyHeadersHashMaxSize}
+	if cnf.proxyHeadersHashSizes != expected {
+		t.Errorf("AddOrUpdateVirtualServer() set the proxy headers hash sizes %+v but expected %+v", cnf.proxyHeadersHashSizes, expected)
+	}
+}
@@ -14,14 +14,23 @@ import (
 
 const emptyHost = ""
 
+// catchAllServerName is the server name of an Ingress with only a default backend. NGINX selects a server with
+// a regular expression name only if no server has the exact or wildcard name of the host, so the server gets the requests
+// for the hosts of no other resource.
+const catchAllServerName = "~^"
+
+// catchAllStatusZone is the status zone of the server of an Ingress with only a default backend.
+const catchAllStatusZone = "_"
+
 // IngressEx holds an Ingress along with the resources that are referenced in this Ingress.
 type IngressEx struct {
-	Ingress          *extensions.Ingress
-	TLSSecrets       map[string]*api_v1.Secret
-	JWTKey           JWTKey
-	Endpoints        map[string][]string
-	HealthChecks     map[string]*api_v1.Probe
-	ExternalNameSvcs map[string]bool
+	Ingress            *extensions.Ingress
+	TLSSecrets         map[string]*api_v1.Secret
+	JWTKey             JWTKey
+	Endpoints          map[string][]string
+	HealthChecks       map[string]*api_v1.Probe
+	ExternalNameSvcs   map[string]bool
+	NamespaceCfgParams *NamespaceConfigParams
 }
 
 // JWTKey represents a secret that holds JSON Web Key.
@@ -50,6 +59,7 @@ func generateNginxCfg(ingEx *IngressEx, pems map[string]string, isMinion bool, b
 	wsServices := getWebsocketServices(ingEx)
 	spServices := getSessionPersistenceServices(ingEx)
 	rewrites := getRewrites(ingEx)
+	timeouts := getPathTimeouts(ingEx)
 	sslServices := getSSLServices(ingEx)
 	grpcServices := getGrpcServices(ingEx)
 
@@ -77,7 +87,20 @@ func generateNginxCfg(ingEx *IngressEx, pems map[string]string, isMinion bool, b
 
 	var servers []version1.Server
 
-	for _, rule := range ingEx.Ingress.Spec.Rules {
+	rules := ingEx.Ingress.Spec.Rules
+	if ingEx.Ingress.Spec.Backend != nil && !hasHTTPRules(rules) {
+		// the server of an Ingress with only a default backend gets the requests for any host
+		rules = []extensions.IngressRule{
+			{
+				Host: emptyHost,
+				IngressRuleValue: extensions.IngressRuleValue{
+					HTTP: &extensions.HTTPIngressRuleValue{},
+				},
+			},
+		}
+	}
+
+	for _, rule := range rules {
 		if rule.IngressRuleValue.HTTP == nil {
 			continue
 		}
@@ -86,30 +109,39 @@ func generateNginxCfg(ingEx *IngressEx, pems map[string]string, isMinion bool, b
 
 		statusZone := rule.Host
 
+		if rule.Host == emptyHost {
+			serverName = catchAllServerName
+			statusZone = catchAllStatusZone
+		}
+
 		server := version1.Server{
-			Name:                  serverName,
-			ServerTokens:          cfgParams.ServerTokens,
-			HTTP2:                 cfgParams.HTTP2,
-			RedirectToHTTPS:       cfgParams.RedirectToHTTPS,
-			SSLRedirect:           cfgParams.SSLRedirect,
-			ProxyProtocol:         cfgParams.ProxyProtocol,
-			HSTS:                  cfgParams.HSTS,
-			HSTSMaxAge:            cfgParams.HSTSMaxAge,
-			HSTSIncludeSubdomains: cfgParams.HSTSIncludeSubdomains,
-			HSTSBehindProxy:       cfgParams.HSTSBehindProxy,
-			StatusZone:            statusZone,
-			RealIPHeader:          cfgParams.RealIPHeader,
-			SetRealIPFrom:         cfgParams.SetRealIPFrom,
-			RealIPRecursive:       cfgParams.RealIPRecursive,
-			ProxyHideHeaders:      cfgParams.ProxyHideHeaders,
-			ProxyPassHeaders:      cfgParams.ProxyPassHeaders,
-			ServerSnippets:        cfgParams.ServerSnippets,
-			Ports:                 cfgParams.Ports,
-			SSLPorts:              cfgParams.SSLPorts,
-			TLSPassthrough:        staticParams.TLSPassthrough,
+			Name:                   serverName,
+			Source:                 generateSourceComment(staticParams.ConfigSourceComments, "Ingress", ingEx.Ingress.Namespace, ingEx.Ingress.Name, "", ""),
+			ServerTokens:           cfgParams.ServerTokens,
+			HTTP2:                  cfgParams.HTTP2,
+			RedirectToHTTPS:        cfgParams.RedirectToHTTPS,
+			SSLRedirect:            cfgParams.SSLRedirect,
+			ProxyProtocol:          cfgParams.ProxyProtocol,
+			HSTS:                   cfgParams.HSTS,
+			HSTSMaxAge:             cfgParams.HSTSMaxAge,
+			HSTSIncludeSubdomains:  cfgParams.HSTSIncludeSubdomains,
+			HSTSBehindProxy:        cfgParams.HSTSBehindProxy,
+			StatusZone:             statusZone,
+			RealIPHeader:           cfgParams.RealIPHeader,
+			SetRealIPFrom:          cfgParams.SetRealIPFrom,
+			RealIPRecursive:        cfgParams.RealIPRecursive,
+			ForwardedHeadersPolicy: cfgParams.ForwardedHeadersPolicy,
+			ProxyHideHeaders:       cfgParams.ProxyHideHeaders,
+			ProxyPassHeaders:       cfgParams.ProxyPassHeaders,
+			ServerSnippets:         cfgParams.ServerSnippets,
+			Ports:                  cfgParams.Ports,
+			SSLPorts:               cfgParams.SSLPorts,
+			TLSPassthrough:         staticParams.TLSPassthrough,
 		}
 
-		if pemFile, ok := pems[serverName]; ok {
+		server.RequestHeadersTooLarge = cfgParams.MainClientHeaderBufferSize != "" || cfgParams.MainLargeClientHeaderBuffers != ""
+
+		if pemFile, ok := pems[rule.Host]; ok {
 			server.SSL = true
 			server.SSLCertificate = pemFile
 			server.SSLCertificateKey = pemFile
@@ -169,6 +201,8 @@ func generateNginxCfg(ingEx *IngressEx, pems map[string]string, isMinion bool, b
 			proxySSLName := generateProxySSLName(path.Backend.ServiceName, ingEx.Ingress.Namespace)
 			loc := createLocation(pathOrDefault(path.Path), upstreams[upsName], &cfgParams, wsServices[path.Backend.ServiceName], rewrites[path.Backend.ServiceName],
 				ssl, grpcServices[path.Backend.ServiceName], proxySSLName)
+			applyPathTimeouts(&loc, timeouts)
+			loc.Source = generateSourceComment(staticParams.ConfigSourceComments, "Ingress", ingEx.Ingress.Namespace, ingEx.Ingress.Name, "path", pathOrDefault(path.Path))
 			if isMinion && ingEx.JWTKey.Name != "" {
 				loc.JWTAuth = &version1.JWTAuth{
 					Key:   jwtKeyFileName,
@@ -198,6 +232,8 @@ func generateNginxCfg(ingEx *IngressEx, pems map[string]string, isMinion bool, b
 
 			loc := createLocation(pathOrDefault("/"), upstreams[upsName], &cfgParams, wsServices[ingEx.Ingress.Spec.Backend.ServiceName], rewrites[ingEx.Ingress.Spec.Backend.ServiceName],
 				ssl, grpcServices[ingEx.Ingress.Spec.Backend.ServiceName], proxySSLName)
+			applyPathTimeouts(&loc, timeouts)
+			loc.Source = generateSourceComment(staticParams.ConfigSourceComments, "Ingress", ingEx.Ingress.Namespace, ingEx.Ingress.Name, "default backend", loc.Path)
 			locations = append(locations, loc)
 
 			if cfgParams.HealthCheckEnabled {
@@ -236,29 +272,60 @@ func generateNginxCfg(ingEx *IngressEx, pems map[string]string, isMinion bool, b
 	}
 }
 
+// hasHTTPRules checks if any of the rules of an Ingress has HTTP paths.
+func hasHTTPRules(rules []extensions.IngressRule) bool {
+	for _, rule := range rules {
+		if rule.IngressRuleValue.HTTP != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
 func createLocation(path string, upstream version1.Upstream, cfg *ConfigParams, websocket bool, rewrite string, ssl bool, grpc bool, proxySSLName string) version1.Location {
 	loc := version1.Location{
-		Path:                 path,
-		Upstream:             upstream,
-		ProxyConnectTimeout:  cfg.ProxyConnectTimeout,
-		ProxyReadTimeout:     cfg.ProxyReadTimeout,
-		ProxySendTimeout:     cfg.ProxySendTimeout,
-		ClientMaxBodySize:    cfg.ClientMaxBodySize,
-		Websocket:            websocket,
-		Rewrite:              rewrite,
-		SSL:                  ssl,
-		GRPC:                 grpc,
-		ProxyBuffering:       cfg.ProxyBuffering,
-		ProxyBuffers:         cfg.ProxyBuffers,
-		ProxyBufferSize:      cfg.ProxyBufferSize,
-		ProxyMaxTempFileSize: cfg.ProxyMaxTempFileSize,
-		ProxySSLName:         proxySSLName,
-		LocationSnippets:     cfg.LocationSnippets,
+		Path:                     path,
+		Upstream:                 upstream,
+		ProxyConnectTimeout:      cfg.ProxyConnectTimeout,
+		ProxyReadTimeout:         cfg.ProxyReadTimeout,
+		ProxySendTimeout:         cfg.ProxySendTimeout,
+		ClientMaxBodySize:        cfg.ClientMaxBodySize,
+		Websocket:                websocket,
+		Rewrite:                  rewrite,
+		SSL:                      ssl,
+		GRPC:                     grpc,
+		ProxyBuffering:           cfg.ProxyBuffering,
+		ProxyBuffers:             cfg.ProxyBuffers,
+		ProxyBufferSize:          cfg.ProxyBufferSize,
+		ProxyMaxTempFileSize:     cfg.ProxyMaxTempFileSize,
+		ProxyNextUpstreamTimeout: cfg.ProxyNextUpstreamTimeout,
+		ProxyNextUpstreamTries:   cfg.ProxyNextUpstreamTries,
+		ProxySSLName:             proxySSLName,
+		LocationSnippets:         cfg.LocationSnippets,
 	}
 
 	return loc
 }
 
+// applyPathTimeouts overrides the proxy timeouts of the location with the timeouts of its path, if any.
+func applyPathTimeouts(loc *version1.Location, timeouts map[string]pathTimeouts) {
+	t, exists := timeouts[loc.Path]
+	if !exists {
+		return
+	}
+
+	if t.ConnectTimeout != "" {
+		loc.ProxyConnectTimeout = t.ConnectTimeout
+	}
+	if t.ReadTimeout != "" {
+		loc.ProxyReadTimeout = t.ReadTimeout
+	}
+	if t.SendTimeout != "" {
+		loc.ProxySendTimeout = t.SendTimeout
+	}
+}
+
 // upstreamRequiresQueue checks if the upstream requires a queue.
 // Mandatory Health Checks can cause nginx to return errors on reload, since all Upstreams start
 // Unhealthy. By adding a queue to the Upstream we can avoid returning errors, at the cost of a short delay.
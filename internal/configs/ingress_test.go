@@ -1,6 +1,7 @@
 package configs
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -182,15 +183,16 @@ func createExpectedConfigForCafeIngressEx() version1.IngressNginxConfig {
 						ProxySSLName:        "tea-svc.default.svc",
 					},
 				},
-				SSL:               true,
-				SSLCertificate:    "/etc/nginx/secrets/default-cafe-secret",
-				SSLCertificateKey: "/etc/nginx/secrets/default-cafe-secret",
-				StatusZone:        "cafe.example.com",
-				HSTSMaxAge:        2592000,
-				Ports:             []int{80},
-				SSLPorts:          []int{443},
-				SSLRedirect:       true,
-				HealthChecks:      make(map[string]version1.HealthCheck),
+				SSL:                    true,
+				SSLCertificate:         "/etc/nginx/secrets/default-cafe-secret",
+				SSLCertificateKey:      "/etc/nginx/secrets/default-cafe-secret",
+				StatusZone:             "cafe.example.com",
+				HSTSMaxAge:             2592000,
+				Ports:                  []int{80},
+				SSLPorts:               []int{443},
+				SSLRedirect:            true,
+				HealthChecks:           make(map[string]version1.HealthCheck),
+				ForwardedHeadersPolicy: ForwardedHeadersPolicyAppend,
 			},
 		},
 		Ingress: version1.Ingress{
@@ -581,15 +583,16 @@ func createExpectedConfigForMergeableCafeIngress() version1.IngressNginxConfig {
 						ProxySSLName: "tea-svc.default.svc",
 					},
 				},
-				SSL:               true,
-				SSLCertificate:    "/etc/nginx/secrets/default-cafe-secret",
-				SSLCertificateKey: "/etc/nginx/secrets/default-cafe-secret",
-				StatusZone:        "cafe.example.com",
-				HSTSMaxAge:        2592000,
-				Ports:             []int{80},
-				SSLPorts:          []int{443},
-				SSLRedirect:       true,
-				HealthChecks:      make(map[string]version1.HealthCheck),
+				SSL:                    true,
+				SSLCertificate:         "/etc/nginx/secrets/default-cafe-secret",
+				SSLCertificateKey:      "/etc/nginx/secrets/default-cafe-secret",
+				StatusZone:             "cafe.example.com",
+				HSTSMaxAge:             2592000,
+				Ports:                  []int{80},
+				SSLPorts:               []int{443},
+				SSLRedirect:            true,
+				HealthChecks:           make(map[string]version1.HealthCheck),
+				ForwardedHeadersPolicy: ForwardedHeadersPolicyAppend,
 			},
 		},
 		Ingress: version1.Ingress{
@@ -681,15 +684,16 @@ func createExpectedConfigForCrossNamespaceMergeableCafeIngress() version1.Ingres
 						ProxySSLName: "tea-svc.tea.svc",
 					},
 				},
-				SSL:               true,
-				SSLCertificate:    "/etc/nginx/secrets/default-cafe-secret",
-				SSLCertificateKey: "/etc/nginx/secrets/default-cafe-secret",
-				StatusZone:        "cafe.example.com",
-				HSTSMaxAge:        2592000,
-				Ports:             []int{80},
-				SSLPorts:          []int{443},
-				SSLRedirect:       true,
-				HealthChecks:      make(map[string]version1.HealthCheck),
+				SSL:                    true,
+				SSLCertificate:         "/etc/nginx/secrets/default-cafe-secret",
+				SSLCertificateKey:      "/etc/nginx/secrets/default-cafe-secret",
+				StatusZone:             "cafe.example.com",
+				HSTSMaxAge:             2592000,
+				Ports:                  []int{80},
+				SSLPorts:               []int{443},
+				SSLRedirect:            true,
+				HealthChecks:           make(map[string]version1.HealthCheck),
+				ForwardedHeadersPolicy: ForwardedHeadersPolicyAppend,
 			},
 		},
 		Ingress: version1.Ingress{
@@ -728,3 +732,235 @@ func TestGenerateNginxCfgForSpiffe(t *testing.T) {
 		t.Errorf("generateNginxCfg returned \n%v,  but expected \n%v", result, expected)
 	}
 }
+
+func TestGenerateNginxCfgForDefaultBackendOnly(t *testing.T) {
+	cafeIngressEx := createCafeIngressEx()
+	cafeIngressEx.Ingress.Spec.TLS = []v1beta1.IngressTLS{
+		{
+			SecretName: "cafe-secret",
+		},
+	}
+	cafeIngressEx.Ingress.Spec.Rules = nil
+	cafeIngressEx.Ingress.Spec.Backend = &v1beta1.IngressBackend{
+		ServiceName: "coffee-svc",
+		ServicePort: intstr.FromString("80"),
+	}
+	configParams := NewDefaultConfigParams()
+
+	coffeeUpstream := version1.Upstream{
+		Name:             "default-cafe-ingress--coffee-svc-80",
+		LBMethod:         "random two least_conn",
+		UpstreamZoneSize: "256k",
+		UpstreamServers: []version1.UpstreamServer{
+			{
+				Address:     "10.0.0.1",
+				Port:        "80",
+				MaxFails:    1,
+				MaxConns:    0,
+				FailTimeout: "10s",
+			},
+		},
+	}
+	expected := version1.IngressNginxConfig{
+		Upstreams: []version1.Upstream{
+			coffeeUpstream,
+		},
+		Servers: []version1.Server{
+			{
+				Name:         "~^",
+				ServerTokens: "on",
+				Locations: []version1.Location{
+					{
+						Path:                "/",
+						Upstream:            coffeeUpstream,
+						ProxyConnectTimeout: "60s",
+						ProxyReadTimeout:    "60s",
+						ProxySendTimeout:    "60s",
+						ClientMaxBodySize:   "1m",
+						ProxyBuffering:      true,
+						ProxySSLName:        "coffee-svc.default.svc",
+					},
+				},
+				SSL:                    true,
+				SSLCertificate:         "/etc/nginx/secrets/default-cafe-secret",
+				SSLCertificateKey:      "/etc/nginx/secrets/default-cafe-secret",
+				StatusZone:             "_",
+				HSTSMaxAge:             2592000,
+				Ports:                  []int{80},
+				SSLPorts:               []int{443},
+				SSLRedirect:            true,
+				HealthChecks:           make(map[string]version1.HealthCheck),
+				ForwardedHeadersPolicy: ForwardedHeadersPolicyAppend,
+			},
+		},
+		Ingress: version1.Ingress{
+			Name:      "cafe-ingress",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"kubernetes.io/ingress.class": "nginx",
+			},
+		},
+	}
+
+	// the TLS secret of the Ingress without hosts applies to the server of the default backend
+	pems := map[string]string{
+		"": "/etc/nginx/secrets/default-cafe-secret",
+	}
+
+	result := generateNginxCfg(&cafeIngressEx, pems, false, configParams, false, false, "", &StaticConfigParams{})
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateNginxCfg returned \n%v,  but expected \n%v", result, expected)
+	}
+}
+
+func TestGenerateNginxCfgWithSourceComments(t *testing.T) {
+	cafeIngressEx := createCafeIngressEx()
+	configParams := NewDefaultConfigParams()
+
+	expected := createExpectedConfigForCafeIngressEx()
+	for i := range expected.Servers {
+		expected.Servers[i].Source = "source: Ingress default/cafe-ingress"
+		for j := range expected.Servers[i].Locations {
+			expected.Servers[i].Locations[j].Source = fmt.Sprintf(`source: Ingress default/cafe-ingress, path %q`, expected.Servers[i].Locations[j].Path)
+		}
+	}
+
+	pems := map[string]string{
+		"cafe.example.com": "/etc/nginx/secrets/default-cafe-secret",
+	}
+
+	result := generateNginxCfg(&cafeIngressEx, pems, false, configParams, false, false, "", &StaticConfigParams{ConfigSourceComments: true})
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateNginxCfg returned \n%v,  but expected \n%v", result, expected)
+	}
+}
+
+func TestGenerateNginxCfgWithPathTimeouts(t *testing.T) {
+	cafeIngressEx := createCafeIngressEx()
+	cafeIngressEx.Ingress.Annotations["nginx.org/path-timeouts"] = "path=/tea read=300s send=300s;path=/coffee connect=5s;path=/latte read=invalid"
+	configParams := NewDefaultConfigParams()
+
+	expected := createExpectedConfigForCafeIngressEx()
+	expected.Ingress.Annotations = cafeIngressEx.Ingress.Annotations
+	for i := range expected.Servers {
+		for j := range expected.Servers[i].Locations {
+			switch expected.Servers[i].Locations[j].Path {
+			case "/tea":
+				expected.Servers[i].Locations[j].ProxyReadTimeout = "300s"
+				expected.Servers[i].Locations[j].ProxySendTimeout = "300s"
+			case "/coffee":
+				expected.Servers[i].Locations[j].ProxyConnectTimeout = "5s"
+			}
+		}
+	}
+
+	pems := map[string]string{
+		"cafe.example.com": "/etc/nginx/secrets/default-cafe-secret",
+	}
+
+	result := generateNginxCfg(&cafeIngressEx, pems, false, configParams, false, false, "", &StaticConfigParams{})
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateNginxCfg returned \n%v,  but expected \n%v", result, expected)
+	}
+}
+
+func TestGenerateNginxCfgWithLargeClientHeaderBuffers(t *testing.T) {
+	cafeIngressEx := createCafeIngressEx()
+	configParams := NewDefaultConfigParams()
+	configParams.MainLargeClientHeaderBuffers = "4 16k"
+
+	result := generateNginxCfg(&cafeIngressEx, map[string]string{}, false, configParams, false, false, "", &StaticConfigParams{})
+
+	for _, server := range result.Servers {
+		if !server.RequestHeadersTooLarge {
+			t.Errorf("generateNginxCfg returned the server %s without RequestHeadersTooLarge", server.Name)
+		}
+	}
+}
+
+func TestCreateUpstreamForExternalNameService(t *testing.T) {
+	ingEx := &IngressEx{
+		Ingress: &v1beta1.Ingress{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe-ingress",
+				Namespace: "default",
+			},
+		},
+		Endpoints: map[string][]string{
+			"coffee-svc80": {"coffee.example.com:80"},
+		},
+		ExternalNameSvcs: map[string]bool{
+			"coffee-svc": true,
+		},
+	}
+	backend := &v1beta1.IngressBackend{
+		ServiceName: "coffee-svc",
+		ServicePort: intstr.FromInt(80),
+	}
+	cfg := &ConfigParams{
+		MaxFails:    1,
+		FailTimeout: "10s",
+	}
+
+	expected := version1.Upstream{
+		Name: "default-cafe-ingress-coffee-svc-80",
+		UpstreamServers: []version1.UpstreamServer{
+			{
+				Address:     "coffee.example.com",
+				Port:        "80",
+				MaxFails:    1,
+				FailTimeout: "10s",
+				Resolve:     true,
+			},
+		},
+	}
+
+	result := createUpstream(ingEx, "default-cafe-ingress-coffee-svc-80", backend, "", cfg, true, true)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("createUpstream() returned %+v but expected %+v", result, expected)
+	}
+}
+
+func TestCreateUpstreamForExternalNameServiceWithoutResolver(t *testing.T) {
+	ingEx := &IngressEx{
+		Ingress: &v1beta1.Ingress{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe-ingress",
+				Namespace: "default",
+			},
+		},
+		Endpoints: map[string][]string{
+			"coffee-svc80": {"coffee.example.com:80"},
+		},
+		ExternalNameSvcs: map[string]bool{
+			"coffee-svc": true,
+		},
+	}
+	backend := &v1beta1.IngressBackend{
+		ServiceName: "coffee-svc",
+		ServicePort: intstr.FromInt(80),
+	}
+
+	result := createUpstream(ingEx, "default-cafe-ingress-coffee-svc-80", backend, "", &ConfigParams{}, true, false)
+	if len(result.UpstreamServers) != 0 {
+		t.Errorf("createUpstream() returned servers %+v for an ExternalName service without a resolver but expected none", result.UpstreamServers)
+	}
+}
+
+func TestCreateLocationWithProxyNextUpstream(t *testing.T) {
+	cfgParams := NewDefaultConfigParams()
+	cfgParams.ProxyNextUpstreamTries = 3
+	cfgParams.ProxyNextUpstreamTimeout = "5s"
+
+	loc := createLocation("/", version1.Upstream{Name: "test"}, cfgParams, false, "", false, false, "")
+
+	if loc.ProxyNextUpstreamTries != 3 {
+		t.Errorf("createLocation() returned ProxyNextUpstreamTries %d but expected 3", loc.ProxyNextUpstreamTries)
+	}
+	if loc.ProxyNextUpstreamTimeout != "5s" {
+		t.Errorf("createLocation() returned ProxyNextUpstreamTimeout %q but expected %q", loc.ProxyNextUpstreamTimeout, "5s")
+	}
+}
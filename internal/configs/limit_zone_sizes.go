@@ -0,0 +1,118 @@
+package configs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+)
+
+const (
+	kilobyte = 1024
+	megabyte = 1024 * kilobyte
+)
+
+// minLimitZoneSize is the smallest size of a shared memory zone of the limit_req and limit_conn modules. NGINX fails
+// to start with a zone smaller than 8 pages.
+const minLimitZoneSize = 32 * kilobyte
+
+// smallLimitZoneSize is the size below which a zone is likely to overflow under load. When a zone is full,
+// NGINX rejects the requests with new keys.
+const smallLimitZoneSize = megabyte
+
+// maxLimitZoneSizeScale is the largest factor by which the default size of a zone is multiplied for the routes
+// that share the zone.
+const maxLimitZoneSizeScale = 4
+
+// parseLimitZoneSize parses the size of a zone of the limit_req and limit_conn modules, for example, 10m.
+func parseLimitZoneSize(s string) (string, error) {
+	size, err := ParseSize(s)
+	if err != nil {
+		return "", err
+	}
+
+	if getSizeInBytes(size) < minLimitZoneSize {
+		return "", fmt.Errorf("must not be smaller than %dk", minLimitZoneSize/kilobyte)
+	}
+
+	return size, nil
+}
+
+// isSmallLimitZoneSize checks if the size of a zone of the limit_req and limit_conn modules is likely to overflow under load.
+func isSmallLimitZoneSize(size string) bool {
+	return getSizeInBytes(size) < smallLimitZoneSize
+}
+
+// getSizeInBytes returns the number of bytes of a valid NGINX size, for example, 32k.
+func getSizeInBytes(size string) uint64 {
+	multiplier := uint64(1)
+
+	switch strings.ToLower(size[len(size)-1:]) {
+	case "k":
+		multiplier = kilobyte
+		size = size[:len(size)-1]
+	case "m":
+		multiplier = megabyte
+		size = size[:len(size)-1]
+	}
+
+	n, _ := strconv.ParseUint(size, 10, 64)
+
+	return n * multiplier
+}
+
+// formatSize formats the number of bytes as an NGINX size in the largest unit that represents it exactly.
+func formatSize(bytes uint64) string {
+	switch {
+	case bytes%megabyte == 0:
+		return fmt.Sprintf("%dm", bytes/megabyte)
+	case bytes%kilobyte == 0:
+		return fmt.Sprintf("%dk", bytes/kilobyte)
+	}
+
+	return fmt.Sprintf("%d", bytes)
+}
+
+// generateLimitZoneSize generates the size of a zone of a limit policy. The size set in the policy is used as is.
+// Otherwise, the default size is multiplied by the number of the routes that reference the policy and share its zone,
+// up to maxLimitZoneSizeScale times.
+func generateLimitZoneSize(size string, defaultSize string, references int) string {
+	if size != "" {
+		return size
+	}
+
+	if references <= 1 {
+		return defaultSize
+	}
+	if references > maxLimitZoneSizeScale {
+		references = maxLimitZoneSizeScale
+	}
+
+	return formatSize(getSizeInBytes(defaultSize) * uint64(references))
+}
+
+// countPolicyReferences counts the references to every policy in the routes of the VirtualServer and the subroutes
+// of its VirtualServerRoutes. A reference without a namespace refers to a policy in the namespace of the resource of the routes.
+func countPolicyReferences(virtualServerEx *VirtualServerEx) map[string]int {
+	counts := make(map[string]int)
+
+	addReferences := func(routes []conf_v1.Route, namespace string) {
+		for _, r := range routes {
+			for _, p := range r.Policies {
+				polNamespace := p.Namespace
+				if polNamespace == "" {
+					polNamespace = namespace
+				}
+				counts[fmt.Sprintf("%s/%s", polNamespace, p.Name)]++
+			}
+		}
+	}
+
+	addReferences(virtualServerEx.VirtualServer.Spec.Routes, virtualServerEx.VirtualServer.Namespace)
+	for _, vsr := range virtualServerEx.VirtualServerRoutes {
+		addReferences(vsr.Spec.Subroutes, vsr.Namespace)
+	}
+
+	return counts
+}
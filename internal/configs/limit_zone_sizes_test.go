@@ -0,0 +1,133 @@
+package configs
+
+import (
+	"reflect"
+	"testing"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsSmallLimitZoneSize(t *testing.T) {
+	tests := []struct {
+		size     string
+		expected bool
+	}{
+		{size: "32k", expected: true},
+		{size: "1023k", expected: true},
+		{size: "1m", expected: false},
+		{size: "1024K", expected: false},
+		{size: "10M", expected: false},
+		{size: "1048576", expected: false},
+	}
+
+	for _, test := range tests {
+		result := isSmallLimitZoneSize(test.size)
+		if result != test.expected {
+			t.Errorf("isSmallLimitZoneSize(%q) returned %v but expected %v", test.size, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateLimitZoneSize(t *testing.T) {
+	tests := []struct {
+		size        string
+		defaultSize string
+		references  int
+		expected    string
+		msg         string
+	}{
+		{
+			size:        "5m",
+			defaultSize: "10m",
+			references:  3,
+			expected:    "5m",
+			msg:         "size of the policy",
+		},
+		{
+			size:        "",
+			defaultSize: "10m",
+			references:  0,
+			expected:    "10m",
+			msg:         "no references",
+		},
+		{
+			size:        "",
+			defaultSize: "10m",
+			references:  1,
+			expected:    "10m",
+			msg:         "one reference",
+		},
+		{
+			size:        "",
+			defaultSize: "512k",
+			references:  3,
+			expected:    "1536k",
+			msg:         "default size scaled by the references",
+		},
+		{
+			size:        "",
+			defaultSize: "10m",
+			references:  10,
+			expected:    "40m",
+			msg:         "scale limited to the maximum",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateLimitZoneSize(test.size, test.defaultSize, test.references)
+		if result != test.expected {
+			t.Errorf("generateLimitZoneSize() returned %q but expected %q for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestCountPolicyReferences(t *testing.T) {
+	virtualServerEx := &VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Routes: []conf_v1.Route{
+					{
+						Path:     "/tea",
+						Policies: []conf_v1.PolicyReference{{Name: "rate-limit"}},
+					},
+					{
+						Path:     "/coffee",
+						Policies: []conf_v1.PolicyReference{{Name: "rate-limit", Namespace: "default"}, {Name: "allow"}},
+					},
+				},
+			},
+		},
+		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+			{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "juice",
+					Namespace: "juice",
+				},
+				Spec: conf_v1.VirtualServerRouteSpec{
+					Subroutes: []conf_v1.Route{
+						{
+							Path:     "/juice",
+							Policies: []conf_v1.PolicyReference{{Name: "rate-limit"}, {Name: "rate-limit", Namespace: "default"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected := map[string]int{
+		"default/rate-limit": 3,
+		"default/allow":      1,
+		"juice/rate-limit":   1,
+	}
+
+	result := countPolicyReferences(virtualServerEx)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("countPolicyReferences() returned %v but expected %v", result, expected)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+ * The OpenID Connect authorization code flow for the VirtualServers that enable OIDC.
+ * The functions are imported as the oidc module in oidc_common.conf and used in the locations of oidc.conf.
+ */
+
+var crypto = require('crypto');
+
+function auth(r) {
+    if (!r.variables.refresh_token || r.variables.refresh_token == '-') {
+        startAuthFlow(r);
+        return;
+    }
+
+    // The ID token has expired but the refresh token is still valid: ask the identity provider for a new ID token.
+    r.subrequest('/_refresh', 'token=' + r.variables.refresh_token, function(reply) {
+        if (reply.status != 200) {
+            r.error('OIDC refresh failure: ' + reply.status + ' ' + reply.responseBody);
+            r.variables.refresh_token = '-';
+            startAuthFlow(r);
+            return;
+        }
+
+        var tokenset;
+        try {
+            tokenset = JSON.parse(reply.responseBody);
+        } catch (e) {
+            r.error('OIDC refresh response is not JSON: ' + reply.responseBody);
+            r.return(502);
+            return;
+        }
+
+        if (!tokenset.id_token) {
+            r.error('OIDC refresh response did not include an id_token');
+            r.variables.refresh_token = '-';
+            startAuthFlow(r);
+            return;
+        }
+
+        r.subrequest('/_id_token_validation', 'token=' + tokenset.id_token, function(validation) {
+            if (validation.status != 204) {
+                r.variables.refresh_token = '-';
+                startAuthFlow(r);
+                return;
+            }
+
+            r.variables.session_jwt = tokenset.id_token;
+            if (tokenset.refresh_token) {
+                r.variables.refresh_token = tokenset.refresh_token;
+            }
+
+            r.internalRedirect(r.variables.request_uri);
+        });
+    });
+}
+
+function startAuthFlow(r) {
+    var nonce = r.variables.request_id;
+    var state = crypto.createHash('sha256').update(r.variables.request_id + r.variables.msec).digest('base64url');
+
+    r.headersOut['Set-Cookie'] = [
+        'auth_redir=' + encodeURIComponent(r.variables.request_uri) + '; ' + r.variables.oidc_cookie_flags,
+        'auth_nonce=' + nonce + '; ' + r.variables.oidc_cookie_flags,
+        'auth_state=' + state + '; ' + r.variables.oidc_cookie_flags
+    ];
+
+    r.return(302, r.variables.oidc_authz_endpoint +
+        '?response_type=code' +
+        '&scope=' + r.variables.oidc_scopes +
+        '&client_id=' + r.variables.oidc_client +
+        '&redirect_uri=' + r.variables.redirect_base + r.variables.redir_location +
+        '&nonce=' + hashNonce(r, nonce) +
+        '&state=' + state);
+}
+
+function codeExchange(r) {
+    if (!r.variables.arg_code || r.variables.arg_code.length == 0) {
+        if (r.variables.arg_error) {
+            r.error('OIDC error received from the identity provider: ' + r.variables.arg_error + ' ' + r.variables.arg_error_description);
+        } else {
+            r.error('OIDC expected an authorization code but received: ' + r.variables.uri);
+        }
+        r.return(502);
+        return;
+    }
+
+    if (!r.variables.cookie_auth_state || r.variables.arg_state != r.variables.cookie_auth_state) {
+        r.error('OIDC state of the authorization response does not match the state of the session');
+        r.return(403);
+        return;
+    }
+
+    var body = 'code=' + r.variables.arg_code + '&client_secret=' + r.variables.oidc_client_secret;
+    r.subrequest('/_token', body, function(reply) {
+        if (reply.status == 504) {
+            r.error('OIDC timeout connecting to the token endpoint');
+            r.return(502);
+            return;
+        }
+
+        if (reply.status != 200) {
+            r.error('OIDC unexpected response from the token endpoint: ' + reply.status + ' ' + reply.responseBody);
+            r.return(502);
+            return;
+        }
+
+        var tokenset;
+        try {
+            tokenset = JSON.parse(reply.responseBody);
+        } catch (e) {
+            r.error('OIDC token response is not JSON: ' + reply.responseBody);
+            r.return(502);
+            return;
+        }
+
+        if (tokenset.error) {
+            r.error('OIDC ' + tokenset.error + ' ' + tokenset.error_description);
+            r.return(500);
+            return;
+        }
+
+        r.subrequest('/_id_token_validation', 'token=' + tokenset.id_token, function(validation) {
+            if (validation.status != 204) {
+                r.return(500);
+                return;
+            }
+
+            r.variables.new_session = tokenset.id_token;
+            if (tokenset.refresh_token) {
+                r.variables.new_refresh = tokenset.refresh_token;
+            }
+
+            r.headersOut['Set-Cookie'] = [
+                'auth_token=' + r.variables.request_id + '; ' + r.variables.oidc_cookie_flags,
+                'auth_nonce=; ' + r.variables.oidc_cookie_flags,
+                'auth_state=; ' + r.variables.oidc_cookie_flags
+            ];
+
+            var redirect = r.variables.cookie_auth_redir ? decodeURIComponent(r.variables.cookie_auth_redir) : '/';
+            r.return(302, r.variables.redirect_base + redirect);
+        });
+    });
+}
+
+function validateIdToken(r) {
+    var required = ['iat', 'iss', 'sub'];
+    for (var i = 0; i < required.length; i++) {
+        if (!r.variables['jwt_claim_' + required[i]]) {
+            r.error('OIDC ID token is missing the ' + required[i] + ' claim');
+            r.return(403);
+            return;
+        }
+    }
+
+    var audience = r.variables.jwt_claim_aud ? r.variables.jwt_claim_aud.split(',') : [];
+    if (audience.indexOf(r.variables.oidc_client) < 0) {
+        r.error('OIDC ID token has the audience ' + r.variables.jwt_claim_aud + ' that does not include the client ' + r.variables.oidc_client);
+        r.return(403);
+        return;
+    }
+
+    // The nonce is checked only for the initial session: the refreshed ID tokens keep the claim of the first one.
+    if (r.variables.cookie_auth_nonce && r.variables.jwt_claim_nonce != hashNonce(r, r.variables.cookie_auth_nonce)) {
+        r.error('OIDC ID token has an invalid nonce');
+        r.return(403);
+        return;
+    }
+
+    r.return(204);
+}
+
+function logout(r) {
+    r.variables.session_jwt = '-';
+    r.variables.refresh_token = '-';
+    r.return(302, '/_logout');
+}
+
+function hashNonce(r, nonce) {
+    return crypto.createHmac('sha256', r.variables.oidc_client_secret).update(nonce).digest('base64url');
+}
+
+export default {auth, codeExchange, validateIdToken, logout};
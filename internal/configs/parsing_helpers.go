@@ -78,6 +78,20 @@ func GetMapKeyAsUint64(m map[string]string, key string, context apiObject, nonZe
 	return 0, false, nil
 }
 
+// GetMapKeyAsFloat64 tries to find and parse a key in a map as float64.
+func GetMapKeyAsFloat64(m map[string]string, key string, context apiObject) (float64, bool, error) {
+	if str, exists := m[key]; exists {
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return 0, exists, fmt.Errorf("%s %v/%v '%s' contains invalid float: %v, ignoring", context.GetObjectKind().GroupVersionKind().Kind, context.GetNamespace(), context.GetName(), key, err)
+		}
+
+		return f, exists, nil
+	}
+
+	return 0, false, nil
+}
+
 // GetMapKeyAsStringSlice tries to find and parse a key in the map as string slice splitting it on delimiter.
 func GetMapKeyAsStringSlice(m map[string]string, key string, context apiObject, delimiter string) ([]string, bool, error) {
 	if str, exists := m[key]; exists {
@@ -177,6 +191,19 @@ var validTimeSuffixes = []string{
 var durationEscaped = strings.Join(validTimeSuffixes, "|")
 var validNginxTime = regexp.MustCompile(`^([0-9]+([` + durationEscaped + `]?){0,1} *)+$`)
 
+var validSoKeepalive = regexp.MustCompile(`^(on|off|([0-9]+[smh]?)?:([0-9]+[smh]?)?:([0-9]+)?)$`)
+
+// ParseSoKeepalive ensures that the string value is a valid so_keepalive parameter of the listen directive:
+// on, off or [keepidle]:[keepintvl]:[keepcnt].
+func ParseSoKeepalive(s string) (string, error) {
+	s = strings.TrimSpace(s)
+
+	if s != "::" && validSoKeepalive.MatchString(s) {
+		return s, nil
+	}
+	return "", errors.New("Invalid so_keepalive string")
+}
+
 // ParseTime ensures that the string value in the annotation is a valid time.
 func ParseTime(s string) (string, error) {
 	s = strings.TrimSpace(s)
@@ -186,3 +213,40 @@ func ParseTime(s string) (string, error) {
 	}
 	return "", errors.New("Invalid time string")
 }
+
+var validSize = regexp.MustCompile(`^[0-9]+[kKmM]?$`)
+
+// ParseSize ensures that the string value is a valid size, for example, 16, 32k or 64M.
+func ParseSize(s string) (string, error) {
+	s = strings.TrimSpace(s)
+
+	if validSize.MatchString(s) {
+		return s, nil
+	}
+	return "", errors.New("Invalid size string")
+}
+
+var validBuffers = regexp.MustCompile(`^[1-9][0-9]* [0-9]+[kKmM]?$`)
+
+// ParseBuffers ensures that the string value is a valid number and size of buffers, for example, 4 8k.
+func ParseBuffers(s string) (string, error) {
+	s = strings.Join(strings.Fields(s), " ")
+
+	if validBuffers.MatchString(s) {
+		return s, nil
+	}
+	return "", errors.New("Invalid buffers string")
+}
+
+var validSSLSessionCache = regexp.MustCompile(`^(off|none|builtin(:[1-9][0-9]*)?( shared:[A-Za-z0-9_]+:[1-9][0-9]*[kKmM]?)?|shared:[A-Za-z0-9_]+:[1-9][0-9]*[kKmM]?)$`)
+
+// ParseSSLSessionCache ensures that the string value is a valid ssl_session_cache parameter:
+// off, none or [builtin[:size]] [shared:name:size].
+func ParseSSLSessionCache(s string) (string, error) {
+	s = strings.Join(strings.Fields(s), " ")
+
+	if validSSLSessionCache.MatchString(s) {
+		return s, nil
+	}
+	return "", errors.New("Invalid ssl_session_cache string")
+}
@@ -212,6 +212,51 @@ func TestGetMapKeyAsInt64ErrorMessage(t *testing.T) {
 	}
 }
 
+func TestGetMapKeyAsFloat64(t *testing.T) {
+	configMap := configMap
+	configMap.Data = map[string]string{
+		"key": "0.25",
+	}
+
+	f, exists, err := GetMapKeyAsFloat64(configMap.Data, "key", &configMap)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !exists {
+		t.Errorf("The key 'key' must exist in the configMap")
+	}
+	expected := 0.25
+	if f != expected {
+		t.Errorf("Unexpected return value:\nGot: %v\nExpected: %v", f, expected)
+	}
+}
+
+func TestGetMapKeyAsFloat64NotFound(t *testing.T) {
+	configMap := configMap
+	configMap.Data = map[string]string{}
+
+	_, exists, _ := GetMapKeyAsFloat64(configMap.Data, "key", &configMap)
+	if exists {
+		t.Errorf("The key 'key' must not exist in the configMap")
+	}
+}
+
+func TestGetMapKeyAsFloat64ErrorMessage(t *testing.T) {
+	cfgm := configMap
+	cfgm.Data = map[string]string{
+		"key": "string",
+	}
+
+	_, _, err := GetMapKeyAsFloat64(cfgm.Data, "key", &cfgm)
+	if err == nil {
+		t.Error("An error was expected")
+	}
+	expected := `ConfigMap default/test 'key' contains invalid float: strconv.ParseFloat: parsing "string": invalid syntax, ignoring`
+	if err.Error() != expected {
+		t.Errorf("The error message does not match expectations:\nGot: %v\nExpected: %v", err, expected)
+	}
+}
+
 func TestGetMapKeyAsStringSlice(t *testing.T) {
 	configMap := configMap
 	configMap.Data = map[string]string{
@@ -363,6 +408,26 @@ func TestParseLBMethodForPlus(t *testing.T) {
 	}
 }
 
+func TestParseSoKeepalive(t *testing.T) {
+	var testsWithValidInput = []string{"on", "off", "30m::10", "30m:10s:5", ":10:", "::5", "7200:75:9"}
+	var invalidInput = []string{"", "::", "yes", "30m:10s", "30m:10s:5s", "-1::", "1d::"}
+	for _, test := range testsWithValidInput {
+		result, err := ParseSoKeepalive(test)
+		if err != nil {
+			t.Errorf("ParseSoKeepalive(%q) returned an error for valid input", test)
+		}
+		if test != result {
+			t.Errorf("ParseSoKeepalive(%q) returned %q expected %q", test, result, test)
+		}
+	}
+	for _, test := range invalidInput {
+		result, err := ParseSoKeepalive(test)
+		if err == nil {
+			t.Errorf("ParseSoKeepalive(%q) didn't return error. Returned: %q", test, result)
+		}
+	}
+}
+
 func TestParseTime(t *testing.T) {
 	var testsWithValidInput = []string{"1", "1m10s", "11 11", "5m 30s", "1s", "100m", "5w", "15m", "11M", "3h", "100y", "600"}
 	var invalidInput = []string{"ss", "rM", "m0m", "s1s", "-5s", "", "1L"}
@@ -382,3 +447,63 @@ func TestParseTime(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSize(t *testing.T) {
+	var testsWithValidInput = []string{"1", "1k", "16K", "1m", "2M"}
+	var invalidInput = []string{"", "k", "-1", "1g", "1kb", "1 k"}
+	for _, test := range testsWithValidInput {
+		result, err := ParseSize(test)
+		if err != nil {
+			t.Errorf("ParseSize(%q) returned an error for valid input", test)
+		}
+		if test != result {
+			t.Errorf("ParseSize(%q) returned %q expected %q", test, result, test)
+		}
+	}
+	for _, test := range invalidInput {
+		result, err := ParseSize(test)
+		if err == nil {
+			t.Errorf("ParseSize(%q) didn't return error. Returned: %q", test, result)
+		}
+	}
+}
+
+func TestParseBuffers(t *testing.T) {
+	var testsWithValidInput = []string{"4 8k", "8 16K", "2 1m", "1 1024"}
+	var invalidInput = []string{"", "4", "8k", "0 8k", "-4 8k", "4 8kb", "4 8k 8k", "four 8k"}
+	for _, test := range testsWithValidInput {
+		result, err := ParseBuffers(test)
+		if err != nil {
+			t.Errorf("ParseBuffers(%q) returned an error for valid input", test)
+		}
+		if test != result {
+			t.Errorf("ParseBuffers(%q) returned %q expected %q", test, result, test)
+		}
+	}
+	for _, test := range invalidInput {
+		result, err := ParseBuffers(test)
+		if err == nil {
+			t.Errorf("ParseBuffers(%q) didn't return error. Returned: %q", test, result)
+		}
+	}
+}
+
+func TestParseSSLSessionCache(t *testing.T) {
+	var testsWithValidInput = []string{"off", "none", "builtin", "builtin:1000", "shared:SSL:10m", "shared:SSL:1024", "builtin:1000 shared:SSL:10m"}
+	var invalidInput = []string{"", "on", "builtin:", "builtin:0", "shared:SSL", "shared:SSL:", "shared::10m", "shared:SSL:10g", "shared:SSL:0", "shared:SSL:10m builtin", "none shared:SSL:10m", "shared:SSL;:10m"}
+	for _, test := range testsWithValidInput {
+		result, err := ParseSSLSessionCache(test)
+		if err != nil {
+			t.Errorf("ParseSSLSessionCache(%q) returned an error for valid input", test)
+		}
+		if test != result {
+			t.Errorf("ParseSSLSessionCache(%q) returned %q expected %q", test, result, test)
+		}
+	}
+	for _, test := range invalidInput {
+		result, err := ParseSSLSessionCache(test)
+		if err == nil {
+			t.Errorf("ParseSSLSessionCache(%q) didn't return error. Returned: %q", test, result)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package configs
+
+import "k8s.io/apimachinery/pkg/runtime"
+
+// ResourceErrors stores the errors of the resources that were skipped while updating the configuration
+// of multiple resources at once. A skipped resource keeps its last applied NGINX config, or gets none if it is new,
+// so that the resource doesn't prevent NGINX from applying the configuration of the other resources.
+type ResourceErrors map[runtime.Object]error
+
+func newResourceErrors() ResourceErrors {
+	return make(map[runtime.Object]error)
+}
+
+// Add adds the errors of other ResourceErrors.
+func (e ResourceErrors) Add(resourceErrors ResourceErrors) {
+	for k, v := range resourceErrors {
+		e[k] = v
+	}
+}
@@ -0,0 +1,19 @@
+package configs
+
+import "fmt"
+
+// generateSourceComment generates the comment that marks a block of the NGINX config with the resource that
+// produced it, for example, "source: VirtualServer default/cafe, route "/tea"". The path of the route is quoted,
+// so that the comment always stays on a single line. If the comments are not enabled, the comment is empty.
+func generateSourceComment(enabled bool, kind string, namespace string, name string, pathKind string, path string) string {
+	if !enabled {
+		return ""
+	}
+
+	source := fmt.Sprintf("source: %s %s/%s", kind, namespace, name)
+	if path != "" {
+		source = fmt.Sprintf("%s, %s %q", source, pathKind, path)
+	}
+
+	return source
+}
@@ -2,6 +2,7 @@ package configs
 
 import (
 	"fmt"
+	"net"
 
 	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
 	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
@@ -28,27 +29,43 @@ func (tsEx *TransportServerEx) String() string {
 }
 
 // generateTransportServerConfig generates a full configuration for a TransportServer.
-func generateTransportServerConfig(transportServerEx *TransportServerEx, listenerPort int, isPlus bool) version2.TransportServerConfig {
+func generateTransportServerConfig(transportServerEx *TransportServerEx, listener Listener, isPlus bool) version2.TransportServerConfig {
 	upstreamNamer := newUpstreamNamerForTransportServer(transportServerEx.TransportServer)
 
 	upstreams := generateStreamUpstreams(transportServerEx, upstreamNamer, isPlus)
 
 	var proxyRequests, proxyResponses *int
+	var proxyTimeout, proxyConnectTimeout string
+	var proxyProtocol bool
 	if transportServerEx.TransportServer.Spec.UpstreamParameters != nil {
 		proxyRequests = transportServerEx.TransportServer.Spec.UpstreamParameters.UDPRequests
 		proxyResponses = transportServerEx.TransportServer.Spec.UpstreamParameters.UDPResponses
+		proxyTimeout = transportServerEx.TransportServer.Spec.UpstreamParameters.ProxyTimeout
+		proxyConnectTimeout = transportServerEx.TransportServer.Spec.UpstreamParameters.ProxyConnectTimeout
+		proxyProtocol = transportServerEx.TransportServer.Spec.UpstreamParameters.ProxyProtocol != nil &&
+			transportServerEx.TransportServer.Spec.UpstreamParameters.ProxyProtocol.Enable
 	}
 
 	return version2.TransportServerConfig{
 		Server: version2.StreamServer{
-			TLSPassthrough: transportServerEx.TransportServer.Spec.Listener.Name == conf_v1alpha1.TLSPassthroughListenerName,
-			UnixSocket:     generateUnixSocket(transportServerEx),
-			Port:           listenerPort,
-			UDP:            transportServerEx.TransportServer.Spec.Listener.Protocol == "UDP",
-			StatusZone:     transportServerEx.TransportServer.Spec.Listener.Name,
-			ProxyRequests:  proxyRequests,
-			ProxyResponses: proxyResponses,
-			ProxyPass:      upstreamNamer.GetNameForUpstream(transportServerEx.TransportServer.Spec.Action.Pass),
+			TLSPassthrough:      transportServerEx.TransportServer.Spec.Listener.Name == conf_v1alpha1.TLSPassthroughListenerName,
+			UnixSocket:          generateUnixSocket(transportServerEx),
+			Port:                listener.Port,
+			IPv4:                listener.IPv4,
+			IPv6:                listener.IPv6,
+			UDP:                 transportServerEx.TransportServer.Spec.Listener.Protocol == "UDP",
+			Backlog:             listener.Backlog,
+			Reuseport:           listener.Reuseport,
+			Fastopen:            listener.Fastopen,
+			Deferred:            listener.Deferred,
+			SoKeepalive:         listener.SoKeepalive,
+			StatusZone:          transportServerEx.TransportServer.Spec.Listener.Name,
+			ProxyRequests:       proxyRequests,
+			ProxyResponses:      proxyResponses,
+			ProxyTimeout:        proxyTimeout,
+			ProxyConnectTimeout: proxyConnectTimeout,
+			ProxyProtocol:       proxyProtocol,
+			ProxyPass:           upstreamNamer.GetNameForUpstream(transportServerEx.TransportServer.Spec.Action.Pass),
 		},
 		Upstreams: upstreams,
 	}
@@ -72,7 +89,7 @@ func generateStreamUpstreams(transportServerEx *TransportServerEx, upstreamNamer
 		endpointsKey := GenerateEndpointsKey(transportServerEx.TransportServer.Namespace, u.Service, nil, uint16(u.Port))
 		endpoints := transportServerEx.Endpoints[endpointsKey]
 
-		ups := generateStreamUpstream(name, endpoints, isPlus)
+		ups := generateStreamUpstream(name, endpoints, u.Weights, isPlus)
 
 		upstreams = append(upstreams, ups)
 	}
@@ -80,12 +97,13 @@ func generateStreamUpstreams(transportServerEx *TransportServerEx, upstreamNamer
 	return upstreams
 }
 
-func generateStreamUpstream(upstreamName string, endpoints []string, isPlus bool) version2.StreamUpstream {
+func generateStreamUpstream(upstreamName string, endpoints []string, weights []conf_v1alpha1.UpstreamServerWeight, isPlus bool) version2.StreamUpstream {
 	var upsServers []version2.StreamUpstreamServer
 
 	for _, e := range endpoints {
 		s := version2.StreamUpstreamServer{
 			Address: e,
+			Weight:  generateStreamUpstreamServerWeight(e, weights),
 		}
 
 		upsServers = append(upsServers, s)
@@ -102,3 +120,35 @@ func generateStreamUpstream(upstreamName string, endpoints []string, isPlus bool
 		Servers: upsServers,
 	}
 }
+
+// generateStreamUpstreamServerWeight generates the weight of the server of the endpoint. The weight of the IP and the port
+// of the endpoint takes priority over the weight of only its IP. 0 is returned if the server has the default weight.
+func generateStreamUpstreamServerWeight(endpoint string, weights []conf_v1alpha1.UpstreamServerWeight) int {
+	weight := 0
+
+	host, _, err := net.SplitHostPort(endpoint)
+	for _, w := range weights {
+		if w.Address == endpoint {
+			return w.Weight
+		}
+		if err == nil && w.Address == host {
+			weight = w.Weight
+		}
+	}
+
+	return weight
+}
+
+// generateStreamUpstreamServerWeights generates the weights of the servers of the endpoints for NGINX Plus API.
+// The servers with the default weight are not included.
+func generateStreamUpstreamServerWeights(endpoints []string, weights []conf_v1alpha1.UpstreamServerWeight) map[string]int {
+	result := make(map[string]int)
+
+	for _, e := range endpoints {
+		if w := generateStreamUpstreamServerWeight(e, weights); w != 0 {
+			result[e] = w
+		}
+	}
+
+	return result
+}
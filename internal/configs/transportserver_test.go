@@ -92,7 +92,10 @@ func TestGenerateTransportServerConfigForTCP(t *testing.T) {
 		},
 	}
 
-	listenerPort := 2020
+	listener := Listener{
+		Port:     2020,
+		Protocol: "TCP",
+	}
 
 	expected := version2.TransportServerConfig{
 		Upstreams: []version2.StreamUpstream{
@@ -114,7 +117,7 @@ func TestGenerateTransportServerConfigForTCP(t *testing.T) {
 	}
 
 	isPlus := true
-	result := generateTransportServerConfig(&transportServerEx, listenerPort, isPlus)
+	result := generateTransportServerConfig(&transportServerEx, listener, isPlus)
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("generateTransportServerConfig() returned \n%+v but expected \n%+v", result, expected)
 	}
@@ -158,7 +161,10 @@ func TestGenerateTransportServerConfigForUDP(t *testing.T) {
 		},
 	}
 
-	listenerPort := 2020
+	listener := Listener{
+		Port:     2020,
+		Protocol: "UDP",
+	}
 
 	expected := version2.TransportServerConfig{
 		Upstreams: []version2.StreamUpstream{
@@ -182,7 +188,7 @@ func TestGenerateTransportServerConfigForUDP(t *testing.T) {
 	}
 
 	isPlus := true
-	result := generateTransportServerConfig(&transportServerEx, listenerPort, isPlus)
+	result := generateTransportServerConfig(&transportServerEx, listener, isPlus)
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("generateTransportServerConfig() returned \n%+v but expected \n%+v", result, expected)
 	}
@@ -218,3 +224,297 @@ func TestGenerateUnixSocket(t *testing.T) {
 		t.Errorf("generateUnixSocket() returned %q but expected %q", result, expected)
 	}
 }
+
+func TestGenerateTransportServerConfigWithBindAddresses(t *testing.T) {
+	transportServerEx := TransportServerEx{
+		TransportServer: &conf_v1alpha1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.TransportServerSpec{
+				Listener: conf_v1alpha1.TransportServerListener{
+					Name:     "tcp-listener",
+					Protocol: "TCP",
+				},
+				Upstreams: []conf_v1alpha1.Upstream{
+					{
+						Name:    "tcp-app",
+						Service: "tcp-app-svc",
+						Port:    5001,
+					},
+				},
+				Action: &conf_v1alpha1.Action{
+					Pass: "tcp-app",
+				},
+			},
+		},
+	}
+
+	listener := Listener{
+		Port:     2020,
+		Protocol: "TCP",
+		IPv4:     "10.0.0.1",
+		IPv6:     "::1",
+	}
+
+	expected := version2.StreamServer{
+		Port:       2020,
+		IPv4:       "10.0.0.1",
+		IPv6:       "::1",
+		UDP:        false,
+		StatusZone: "tcp-listener",
+		ProxyPass:  "ts_default_tcp-server_tcp-app",
+	}
+
+	isPlus := false
+	result := generateTransportServerConfig(&transportServerEx, listener, isPlus)
+	if !reflect.DeepEqual(result.Server, expected) {
+		t.Errorf("generateTransportServerConfig() returned server \n%+v but expected \n%+v", result.Server, expected)
+	}
+}
+
+func TestGenerateTransportServerConfigWithListenParameters(t *testing.T) {
+	transportServerEx := TransportServerEx{
+		TransportServer: &conf_v1alpha1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.TransportServerSpec{
+				Listener: conf_v1alpha1.TransportServerListener{
+					Name:     "tcp-listener",
+					Protocol: "TCP",
+				},
+				Upstreams: []conf_v1alpha1.Upstream{
+					{
+						Name:    "tcp-app",
+						Service: "tcp-app-svc",
+						Port:    5001,
+					},
+				},
+				Action: &conf_v1alpha1.Action{
+					Pass: "tcp-app",
+				},
+			},
+		},
+	}
+
+	listener := Listener{
+		Port:        2020,
+		Protocol:    "TCP",
+		Backlog:     1024,
+		Reuseport:   true,
+		Fastopen:    256,
+		Deferred:    true,
+		SoKeepalive: "30m::10",
+	}
+
+	expected := version2.StreamServer{
+		Port:        2020,
+		UDP:         false,
+		Backlog:     1024,
+		Reuseport:   true,
+		Fastopen:    256,
+		Deferred:    true,
+		SoKeepalive: "30m::10",
+		StatusZone:  "tcp-listener",
+		ProxyPass:   "ts_default_tcp-server_tcp-app",
+	}
+
+	isPlus := false
+	result := generateTransportServerConfig(&transportServerEx, listener, isPlus)
+	if !reflect.DeepEqual(result.Server, expected) {
+		t.Errorf("generateTransportServerConfig() returned server \n%+v but expected \n%+v", result.Server, expected)
+	}
+}
+
+func TestGenerateTransportServerConfigWithProxyTimeouts(t *testing.T) {
+	transportServerEx := TransportServerEx{
+		TransportServer: &conf_v1alpha1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.TransportServerSpec{
+				Listener: conf_v1alpha1.TransportServerListener{
+					Name:     "tcp-listener",
+					Protocol: "TCP",
+				},
+				Upstreams: []conf_v1alpha1.Upstream{
+					{
+						Name:    "tcp-app",
+						Service: "tcp-app-svc",
+						Port:    5001,
+					},
+				},
+				UpstreamParameters: &conf_v1alpha1.UpstreamParameters{
+					ProxyTimeout:        "1h",
+					ProxyConnectTimeout: "30s",
+				},
+				Action: &conf_v1alpha1.Action{
+					Pass: "tcp-app",
+				},
+			},
+		},
+	}
+
+	listener := Listener{
+		Port:     2020,
+		Protocol: "TCP",
+	}
+
+	expected := version2.StreamServer{
+		Port:                2020,
+		UDP:                 false,
+		StatusZone:          "tcp-listener",
+		ProxyTimeout:        "1h",
+		ProxyConnectTimeout: "30s",
+		ProxyPass:           "ts_default_tcp-server_tcp-app",
+	}
+
+	isPlus := false
+	result := generateTransportServerConfig(&transportServerEx, listener, isPlus)
+	if !reflect.DeepEqual(result.Server, expected) {
+		t.Errorf("generateTransportServerConfig() returned server \n%+v but expected \n%+v", result.Server, expected)
+	}
+}
+
+func TestGenerateTransportServerConfigWithProxyProtocol(t *testing.T) {
+	transportServerEx := TransportServerEx{
+		TransportServer: &conf_v1alpha1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.TransportServerSpec{
+				Listener: conf_v1alpha1.TransportServerListener{
+					Name:     "tcp-listener",
+					Protocol: "TCP",
+				},
+				Upstreams: []conf_v1alpha1.Upstream{
+					{
+						Name:    "tcp-app",
+						Service: "tcp-app-svc",
+						Port:    5001,
+					},
+				},
+				UpstreamParameters: &conf_v1alpha1.UpstreamParameters{
+					ProxyProtocol: &conf_v1alpha1.ProxyProtocol{
+						Enable:  true,
+						Version: 1,
+					},
+				},
+				Action: &conf_v1alpha1.Action{
+					Pass: "tcp-app",
+				},
+			},
+		},
+	}
+
+	listener := Listener{
+		Port:     2020,
+		Protocol: "TCP",
+	}
+
+	expected := version2.StreamServer{
+		Port:          2020,
+		UDP:           false,
+		StatusZone:    "tcp-listener",
+		ProxyProtocol: true,
+		ProxyPass:     "ts_default_tcp-server_tcp-app",
+	}
+
+	isPlus := false
+	result := generateTransportServerConfig(&transportServerEx, listener, isPlus)
+	if !reflect.DeepEqual(result.Server, expected) {
+		t.Errorf("generateTransportServerConfig() returned server \n%+v but expected \n%+v", result.Server, expected)
+	}
+
+	transportServerEx.TransportServer.Spec.UpstreamParameters.ProxyProtocol.Enable = false
+	expected.ProxyProtocol = false
+
+	result = generateTransportServerConfig(&transportServerEx, listener, isPlus)
+	if !reflect.DeepEqual(result.Server, expected) {
+		t.Errorf("generateTransportServerConfig() returned server \n%+v but expected \n%+v for the disabled PROXY protocol", result.Server, expected)
+	}
+}
+
+func TestGenerateTransportServerConfigWithWeights(t *testing.T) {
+	transportServerEx := TransportServerEx{
+		TransportServer: &conf_v1alpha1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.TransportServerSpec{
+				Listener: conf_v1alpha1.TransportServerListener{
+					Name:     "tcp-listener",
+					Protocol: "TCP",
+				},
+				Upstreams: []conf_v1alpha1.Upstream{
+					{
+						Name:    "tcp-app",
+						Service: "tcp-app-svc",
+						Port:    5001,
+						Weights: []conf_v1alpha1.UpstreamServerWeight{
+							{Address: "10.0.0.20", Weight: 2},
+							{Address: "10.0.0.20:5001", Weight: 5},
+							{Address: "10.0.0.21", Weight: 3},
+						},
+					},
+				},
+				Action: &conf_v1alpha1.Action{
+					Pass: "tcp-app",
+				},
+			},
+		},
+		Endpoints: map[string][]string{
+			"default/tcp-app-svc:5001": {
+				"10.0.0.20:5001",
+				"10.0.0.21:5001",
+				"10.0.0.22:5001",
+			},
+		},
+	}
+
+	listener := Listener{
+		Port:     2020,
+		Protocol: "TCP",
+	}
+
+	expected := []version2.StreamUpstream{
+		{
+			Name: "ts_default_tcp-server_tcp-app",
+			Servers: []version2.StreamUpstreamServer{
+				{
+					Address: "10.0.0.20:5001",
+					Weight:  5,
+				},
+				{
+					Address: "10.0.0.21:5001",
+					Weight:  3,
+				},
+				{
+					Address: "10.0.0.22:5001",
+				},
+			},
+		},
+	}
+
+	isPlus := false
+	result := generateTransportServerConfig(&transportServerEx, listener, isPlus)
+	if !reflect.DeepEqual(result.Upstreams, expected) {
+		t.Errorf("generateTransportServerConfig() returned upstreams \n%+v but expected \n%+v", result.Upstreams, expected)
+	}
+
+	expectedWeights := map[string]int{
+		"10.0.0.20:5001": 5,
+		"10.0.0.21:5001": 3,
+	}
+
+	weights := generateStreamUpstreamServerWeights(transportServerEx.Endpoints["default/tcp-app-svc:5001"], transportServerEx.TransportServer.Spec.Upstreams[0].Weights)
+	if !reflect.DeepEqual(weights, expectedWeights) {
+		t.Errorf("generateStreamUpstreamServerWeights() returned %v but expected %v", weights, expectedWeights)
+	}
+}
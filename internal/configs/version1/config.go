@@ -55,6 +55,7 @@ type HealthCheck struct {
 type Server struct {
 	ServerSnippets        []string
 	Name                  string
+	Source                string
 	ServerTokens          string
 	Locations             []Location
 	SSL                   bool
@@ -81,6 +82,10 @@ type Server struct {
 	SetRealIPFrom   []string
 	RealIPRecursive bool
 
+	ForwardedHeadersPolicy string
+
+	RequestHeadersTooLarge bool
+
 	JWTAuth              *JWTAuth
 	JWTRedirectLocations []JWTRedirectLocation
 
@@ -104,23 +109,26 @@ type JWTAuth struct {
 
 // Location describes an NGINX location.
 type Location struct {
-	LocationSnippets     []string
-	Path                 string
-	Upstream             Upstream
-	ProxyConnectTimeout  string
-	ProxyReadTimeout     string
-	ProxySendTimeout     string
-	ClientMaxBodySize    string
-	Websocket            bool
-	Rewrite              string
-	SSL                  bool
-	GRPC                 bool
-	ProxyBuffering       bool
-	ProxyBuffers         string
-	ProxyBufferSize      string
-	ProxyMaxTempFileSize string
-	ProxySSLName         string
-	JWTAuth              *JWTAuth
+	LocationSnippets         []string
+	Path                     string
+	Source                   string
+	Upstream                 Upstream
+	ProxyConnectTimeout      string
+	ProxyReadTimeout         string
+	ProxySendTimeout         string
+	ClientMaxBodySize        string
+	Websocket                bool
+	Rewrite                  string
+	SSL                      bool
+	GRPC                     bool
+	ProxyBuffering           bool
+	ProxyBuffers             string
+	ProxyBufferSize          string
+	ProxyMaxTempFileSize     string
+	ProxyNextUpstreamTimeout string
+	ProxyNextUpstreamTries   int
+	ProxySSLName             string
+	JWTAuth                  *JWTAuth
 
 	MinionIngress *Ingress
 }
@@ -128,6 +136,12 @@ type Location struct {
 // MainConfig describe the main NGINX configuration file.
 type MainConfig struct {
 	AccessLogOff                   bool
+	AccessLogSamplePercentage      string
+	AccessLogNon2xxOnly            bool
+	AccessLogCondition             string
+	BrotliLoadModule               bool
+	OIDC                           bool
+	DynamicSSLCertsDirectory       string
 	DefaultServerAccessLogOff      bool
 	ErrorLogLevel                  string
 	HealthStatus                   bool
@@ -142,15 +156,31 @@ type MainConfig struct {
 	NginxStatus                    bool
 	NginxStatusAllowCIDRs          []string
 	NginxStatusPort                int
+	OpenTelemetryEnabled           bool
+	OpenTelemetryExporterEndpoint  string
+	OpenTelemetryLoadModule        bool
+	OpenTelemetrySamplerPercentage string
+	OpenTelemetryServiceName       string
+	OpenTelemetryTrace             string
 	OpenTracingEnabled             bool
 	OpenTracingLoadModule          bool
 	OpenTracingTracer              string
 	OpenTracingTracerConfig        string
 	ProxyProtocol                  bool
+	ListenBacklog                  int
+	ListenReuseport                bool
+	ListenFastopen                 int
+	ListenDeferred                 bool
+	ListenSoKeepalive              string
 	ResolverAddresses              []string
 	ResolverIPV6                   bool
 	ResolverTimeout                string
 	ResolverValid                  string
+	ConnectionLimitZones           []ConnectionLimitZone
+	ClientHeaderBufferSize         string
+	LargeClientHeaderBuffers       string
+	ClientHeaderTimeout            string
+	ClientBodyTimeout              string
 	ServerNamesHashBucketSize      string
 	ServerNamesHashMaxSize         string
 	ServerTokens                   string
@@ -158,6 +188,9 @@ type MainConfig struct {
 	SSLDHParam                     string
 	SSLPreferServerCiphers         bool
 	SSLProtocols                   string
+	SSLSessionCache                string
+	SSLSessionTimeout              string
+	SSLSessionTickets              string
 	StreamLogFormat                []string
 	StreamLogFormatEscaping        string
 	StreamSnippets                 []string
@@ -165,6 +198,10 @@ type MainConfig struct {
 	TLSPassthrough                 bool
 	VariablesHashBucketSize        uint64
 	VariablesHashMaxSize           uint64
+	MapHashBucketSize              uint64
+	MapHashMaxSize                 uint64
+	ProxyHeadersHashBucketSize     uint64
+	ProxyHeadersHashMaxSize        uint64
 	WorkerConnections              string
 	WorkerCPUAffinity              string
 	WorkerProcesses                string
@@ -172,6 +209,13 @@ type MainConfig struct {
 	WorkerShutdownTimeout          string
 }
 
+// ConnectionLimitZone describes a shared memory zone for limiting the number of connections.
+type ConnectionLimitZone struct {
+	Name string
+	Key  string
+	Size string
+}
+
 // NewUpstreamWithDefaultServer creates an upstream with the default server.
 // proxy_pass to an upstream with the default server returns 502.
 // We use it for services that have no endpoints.
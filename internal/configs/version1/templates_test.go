@@ -2,6 +2,7 @@ package version1
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"text/template"
 )
@@ -174,6 +175,525 @@ func TestMainForNGINX(t *testing.T) {
 	}
 }
 
+func TestMainWithOpenTelemetry(t *testing.T) {
+	cfg := mainCfg
+	cfg.OpenTelemetryLoadModule = true
+	cfg.OpenTelemetryEnabled = true
+	cfg.OpenTelemetryExporterEndpoint = "otel-collector:4317"
+	cfg.OpenTelemetryServiceName = "nginx-ingress"
+	cfg.OpenTelemetrySamplerPercentage = "25%"
+	cfg.OpenTelemetryTrace = "$otel_trace_sampled"
+
+	expectedDirectives := []string{
+		"load_module modules/ngx_otel_module.so;",
+		"endpoint otel-collector:4317;",
+		"otel_service_name nginx-ingress;",
+		"otel_trace_context propagate;",
+		"split_clients $otel_trace_id $otel_trace_sampled {",
+		"25% on;",
+		"otel_trace $otel_trace_sampled;",
+		"otel_trace off;",
+	}
+
+	for _, tmplFile := range []string{nginxPlusMainTmpl, nginxMainTmpl} {
+		tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+		if err != nil {
+			t.Fatalf("Failed to parse template file: %v", err)
+		}
+
+		var buf bytes.Buffer
+
+		err = tmpl.Execute(&buf, cfg)
+		if err != nil {
+			t.Fatalf("Failed to write template %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !strings.Contains(buf.String(), directive) {
+				t.Errorf("Template %v generated a config without %q", tmplFile, directive)
+			}
+		}
+	}
+}
+
+func TestMainWithBrotli(t *testing.T) {
+	cfg := mainCfg
+	cfg.BrotliLoadModule = true
+
+	directive := "load_module modules/ngx_http_brotli_filter_module.so;"
+
+	for _, tmplFile := range []string{nginxPlusMainTmpl, nginxMainTmpl} {
+		tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+		if err != nil {
+			t.Fatalf("Failed to parse template file: %v", err)
+		}
+
+		var buf bytes.Buffer
+
+		err = tmpl.Execute(&buf, cfg)
+		if err != nil {
+			t.Fatalf("Failed to write template %v", err)
+		}
+
+		if !strings.Contains(buf.String(), directive) {
+			t.Errorf("Template %v generated a config without %q", tmplFile, directive)
+		}
+	}
+}
+
+func TestMainForNginxPlusWithOIDC(t *testing.T) {
+	cfg := mainCfg
+	cfg.OIDC = true
+
+	directives := []string{
+		"load_module modules/ngx_http_js_module.so;",
+		"include oidc/oidc_common.conf;",
+	}
+
+	tmpl, err := template.New(nginxPlusMainTmpl).ParseFiles(nginxPlusMainTmpl)
+	if err != nil {
+		t.Fatalf("Failed to parse template file: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	err = tmpl.Execute(&buf, cfg)
+	if err != nil {
+		t.Fatalf("Failed to write template %v", err)
+	}
+
+	for _, directive := range directives {
+		if !strings.Contains(buf.String(), directive) {
+			t.Errorf("Template %v generated a config without %q", nginxPlusMainTmpl, directive)
+		}
+	}
+}
+
+func TestMainForNginxPlusWithDynamicSSLCertificates(t *testing.T) {
+	cfg := mainCfg
+	cfg.DynamicSSLCertsDirectory = "/etc/nginx/dynamic-certificates"
+
+	directives := []string{
+		"load_module modules/ngx_http_js_module.so;",
+		`default "/etc/nginx/dynamic-certificates";`,
+		"js_import dynamic_ssl_certificates from dynamic-ssl-certificates/dynamic_ssl_certificates.js;",
+		"js_set $dynamic_ssl_certificate dynamic_ssl_certificates.certificate;",
+	}
+
+	tmpl, err := template.New(nginxPlusMainTmpl).ParseFiles(nginxPlusMainTmpl)
+	if err != nil {
+		t.Fatalf("Failed to parse template file: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	err = tmpl.Execute(&buf, cfg)
+	if err != nil {
+		t.Fatalf("Failed to write template %v", err)
+	}
+
+	for _, directive := range directives {
+		if !strings.Contains(buf.String(), directive) {
+			t.Errorf("Template %v generated a config without %q", nginxPlusMainTmpl, directive)
+		}
+	}
+}
+
+func TestMainWithSSLSession(t *testing.T) {
+	cfg := mainCfg
+	cfg.SSLSessionCache = "shared:SSL:10m"
+	cfg.SSLSessionTimeout = "1h"
+	cfg.SSLSessionTickets = "off"
+
+	directives := []string{
+		"ssl_session_cache shared:SSL:10m;",
+		"ssl_session_timeout 1h;",
+		"ssl_session_tickets off;",
+	}
+
+	for _, tmplFile := range []string{nginxMainTmpl, nginxPlusMainTmpl} {
+		tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+		if err != nil {
+			t.Fatalf("Failed to parse template file: %v", err)
+		}
+
+		var buf bytes.Buffer
+
+		err = tmpl.Execute(&buf, cfg)
+		if err != nil {
+			t.Fatalf("Failed to write template %v", err)
+		}
+
+		for _, directive := range directives {
+			if !strings.Contains(buf.String(), directive) {
+				t.Errorf("Template %v generated a config without %q", tmplFile, directive)
+			}
+		}
+	}
+}
+
+func TestMainWithClientHeaderBuffers(t *testing.T) {
+	cfg := mainCfg
+	cfg.ClientHeaderBufferSize = "2k"
+	cfg.LargeClientHeaderBuffers = "4 16k"
+
+	directives := []string{
+		"client_header_buffer_size 2k;",
+		"large_client_header_buffers 4 16k;",
+	}
+
+	for _, tmplFile := range []string{nginxMainTmpl, nginxPlusMainTmpl} {
+		tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+		if err != nil {
+			t.Fatalf("Failed to parse template file: %v", err)
+		}
+
+		var buf bytes.Buffer
+
+		err = tmpl.Execute(&buf, cfg)
+		if err != nil {
+			t.Fatalf("Failed to write template %v", err)
+		}
+
+		for _, directive := range directives {
+			if !strings.Contains(buf.String(), directive) {
+				t.Errorf("Template %v generated a config without %q", tmplFile, directive)
+			}
+		}
+	}
+}
+
+func TestMainWithClientTimeouts(t *testing.T) {
+	cfg := mainCfg
+	cfg.ClientHeaderTimeout = "10s"
+	cfg.ClientBodyTimeout = "30s"
+
+	directives := []string{
+		"client_header_timeout 10s;",
+		"client_body_timeout 30s;",
+	}
+
+	for _, tmplFile := range []string{nginxMainTmpl, nginxPlusMainTmpl} {
+		tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+		if err != nil {
+			t.Fatalf("Failed to parse template file: %v", err)
+		}
+
+		var buf bytes.Buffer
+
+		err = tmpl.Execute(&buf, cfg)
+		if err != nil {
+			t.Fatalf("Failed to write template %v", err)
+		}
+
+		for _, directive := range directives {
+			if !strings.Contains(buf.String(), directive) {
+				t.Errorf("Template %v generated a config without %q", tmplFile, directive)
+			}
+		}
+	}
+}
+
+func TestIngressWithRequestHeadersTooLarge(t *testing.T) {
+	server := ingCfg.Servers[0]
+	server.RequestHeadersTooLarge = true
+
+	cfg := ingCfg
+	cfg.Servers = []Server{server}
+
+	directives := []string{
+		"error_page 494 =431 @request_headers_too_large;",
+		"location @request_headers_too_large {",
+		"return 431;",
+	}
+
+	for _, tmplFile := range []string{nginxPlusIngressTmpl, nginxIngressTmpl} {
+		tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+		if err != nil {
+			t.Fatalf("Failed to parse template file: %v", err)
+		}
+
+		var buf bytes.Buffer
+
+		err = tmpl.Execute(&buf, cfg)
+		if err != nil {
+			t.Fatalf("Failed to write template %v", err)
+		}
+
+		for _, directive := range directives {
+			if !strings.Contains(buf.String(), directive) {
+				t.Errorf("Template %v generated a config without %q", tmplFile, directive)
+			}
+		}
+	}
+}
+
+func TestMainWithAccessLogSampling(t *testing.T) {
+	cfg := mainCfg
+	cfg.AccessLogSamplePercentage = "10%"
+	cfg.AccessLogNon2xxOnly = true
+	cfg.AccessLogCondition = "$access_log_sampled_non_2xx"
+
+	directives := []string{
+		"split_clients $request_id $access_log_sampled {",
+		"10% 1;",
+		"map $status $access_log_non_2xx {",
+		"map $access_log_sampled$access_log_non_2xx $access_log_sampled_non_2xx {",
+		"access_log  /var/log/nginx/access.log  main if=$access_log_sampled_non_2xx;",
+	}
+
+	for _, tmplFile := range []string{nginxPlusMainTmpl, nginxMainTmpl} {
+		tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+		if err != nil {
+			t.Fatalf("Failed to parse template file: %v", err)
+		}
+
+		var buf bytes.Buffer
+
+		err = tmpl.Execute(&buf, cfg)
+		if err != nil {
+			t.Fatalf("Failed to write template %v", err)
+		}
+
+		for _, directive := range directives {
+			if !strings.Contains(buf.String(), directive) {
+				t.Errorf("Template %v generated a config without %q", tmplFile, directive)
+			}
+		}
+	}
+}
+
+func TestMainWithConnectionLimitZones(t *testing.T) {
+	cfg := mainCfg
+	cfg.ConnectionLimitZones = []ConnectionLimitZone{
+		{
+			Name: "conn_limit_shared-backend",
+			Key:  "shared-backend",
+			Size: "20m",
+		},
+	}
+
+	directive := `limit_conn_zone "shared-backend" zone=conn_limit_shared-backend:20m;`
+
+	for _, tmplFile := range []string{nginxPlusMainTmpl, nginxMainTmpl} {
+		tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+		if err != nil {
+			t.Fatalf("Failed to parse template file: %v", err)
+		}
+
+		var buf bytes.Buffer
+
+		err = tmpl.Execute(&buf, cfg)
+		if err != nil {
+			t.Fatalf("Failed to write template %v", err)
+		}
+
+		if !strings.Contains(buf.String(), directive) {
+			t.Errorf("Template %v generated a config without %q", tmplFile, directive)
+		}
+	}
+}
+
+func TestMainWithListenParameters(t *testing.T) {
+	tests := []struct {
+		tlsPassthrough bool
+		expected       []string
+	}{
+		{
+			tlsPassthrough: false,
+			expected: []string{
+				"listen 80 default_server reuseport backlog=1024 fastopen=256 deferred so_keepalive=30m::10;",
+				"listen 443 ssl default_server reuseport backlog=1024 fastopen=256 deferred so_keepalive=30m::10;",
+			},
+		},
+		{
+			tlsPassthrough: true,
+			expected: []string{
+				"listen 80 default_server reuseport backlog=1024 fastopen=256 deferred so_keepalive=30m::10;",
+				"listen 443 reuseport backlog=1024 fastopen=256 deferred so_keepalive=30m::10;",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cfg := mainCfg
+		cfg.TLSPassthrough = test.tlsPassthrough
+		cfg.ListenBacklog = 1024
+		cfg.ListenReuseport = true
+		cfg.ListenFastopen = 256
+		cfg.ListenDeferred = true
+		cfg.ListenSoKeepalive = "30m::10"
+
+		for _, tmplFile := range []string{nginxPlusMainTmpl, nginxMainTmpl} {
+			tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+			if err != nil {
+				t.Fatalf("Failed to parse template file: %v", err)
+			}
+
+			var buf bytes.Buffer
+
+			err = tmpl.Execute(&buf, cfg)
+			if err != nil {
+				t.Fatalf("Failed to write template %v", err)
+			}
+
+			for _, directive := range test.expected {
+				if !strings.Contains(buf.String(), directive) {
+					t.Errorf("Template %v generated a config without %q", tmplFile, directive)
+				}
+			}
+
+			// NGINX allows the socket parameters only once per port
+			if count := strings.Count(buf.String(), "reuseport"); count != len(test.expected) {
+				t.Errorf("Template %v generated a config with %d reuseport parameters but expected %d", tmplFile, count, len(test.expected))
+			}
+		}
+	}
+}
+
+func TestIngressWithProxyNextUpstreamParameters(t *testing.T) {
+	tests := []struct {
+		grpc     bool
+		expected []string
+	}{
+		{
+			grpc: false,
+			expected: []string{
+				"proxy_next_upstream_timeout 5s;",
+				"proxy_next_upstream_tries 3;",
+			},
+		},
+		{
+			grpc: true,
+			expected: []string{
+				"grpc_next_upstream_timeout 5s;",
+				"grpc_next_upstream_tries 3;",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		loc := ingCfg.Servers[0].Locations[0]
+		loc.GRPC = test.grpc
+		loc.ProxyNextUpstreamTimeout = "5s"
+		loc.ProxyNextUpstreamTries = 3
+
+		server := ingCfg.Servers[0]
+		server.Locations = []Location{loc}
+
+		cfg := ingCfg
+		cfg.Servers = []Server{server}
+
+		for _, tmplFile := range []string{nginxPlusIngressTmpl, nginxIngressTmpl} {
+			tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+			if err != nil {
+				t.Fatalf("Failed to parse template file: %v", err)
+			}
+
+			var buf bytes.Buffer
+
+			err = tmpl.Execute(&buf, cfg)
+			if err != nil {
+				t.Fatalf("Failed to write template %v", err)
+			}
+
+			for _, directive := range test.expected {
+				if !strings.Contains(buf.String(), directive) {
+					t.Errorf("Template %v generated a config without %q", tmplFile, directive)
+				}
+			}
+		}
+	}
+}
+
+func TestIngressWithForwardedHeadersPolicies(t *testing.T) {
+	tests := []struct {
+		policy     string
+		grpc       bool
+		expected   []string
+		unexpected []string
+	}{
+		{
+			policy: "append",
+			expected: []string{
+				"proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;",
+				"proxy_set_header X-Forwarded-Port $server_port;",
+			},
+		},
+		{
+			policy: "replace",
+			expected: []string{
+				"proxy_set_header X-Forwarded-For $remote_addr;",
+				"proxy_set_header X-Forwarded-Port $server_port;",
+			},
+		},
+		{
+			policy: "replace",
+			grpc:   true,
+			expected: []string{
+				"grpc_set_header X-Forwarded-For $remote_addr;",
+				"grpc_set_header X-Forwarded-Port $server_port;",
+			},
+		},
+		{
+			policy: "pass",
+			unexpected: []string{
+				"X-Forwarded-For",
+				"X-Forwarded-Host",
+				"X-Forwarded-Port",
+				"X-Forwarded-Proto",
+			},
+		},
+		{
+			policy: "pass",
+			grpc:   true,
+			unexpected: []string{
+				"X-Forwarded-For",
+				"X-Forwarded-Host",
+				"X-Forwarded-Port",
+				"X-Forwarded-Proto",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		loc := ingCfg.Servers[0].Locations[0]
+		loc.GRPC = test.grpc
+
+		server := ingCfg.Servers[0]
+		server.Locations = []Location{loc}
+		server.ForwardedHeadersPolicy = test.policy
+
+		cfg := ingCfg
+		cfg.Servers = []Server{server}
+
+		for _, tmplFile := range []string{nginxPlusIngressTmpl, nginxIngressTmpl} {
+			tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+			if err != nil {
+				t.Fatalf("Failed to parse template file: %v", err)
+			}
+
+			var buf bytes.Buffer
+
+			err = tmpl.Execute(&buf, cfg)
+			if err != nil {
+				t.Fatalf("Failed to write template %v", err)
+			}
+
+			for _, directive := range test.expected {
+				if !strings.Contains(buf.String(), directive) {
+					t.Errorf("Template %v generated a config without %q for the policy %q", tmplFile, directive, test.policy)
+				}
+			}
+			for _, header := range test.unexpected {
+				if strings.Contains(buf.String(), header) {
+					t.Errorf("Template %v generated a config with %q for the policy %q", tmplFile, header, test.policy)
+				}
+			}
+		}
+	}
+}
+
 func TestSplitHelperFunction(t *testing.T) {
 	const tpl = `{{range $n := split . ","}}{{$n}} {{end}}`
 
@@ -219,3 +739,40 @@ func TestTrimHelperFunction(t *testing.T) {
 		t.Fatalf("Template generated wrong config, got %v but expected %v.", buf.String(), expected)
 	}
 }
+
+func TestIngressWithSourceComments(t *testing.T) {
+	loc := ingCfg.Servers[0].Locations[0]
+	loc.Source = `source: Ingress default/cafe-ingress, path "/tea"`
+
+	server := ingCfg.Servers[0]
+	server.Source = "source: Ingress default/cafe-ingress"
+	server.Locations = []Location{loc}
+
+	cfg := ingCfg
+	cfg.Servers = []Server{server}
+
+	expectedComments := []string{
+		"# source: Ingress default/cafe-ingress\n",
+		`# source: Ingress default/cafe-ingress, path "/tea"`,
+	}
+
+	for _, tmplFile := range []string{nginxPlusIngressTmpl, nginxIngressTmpl} {
+		tmpl, err := template.New(tmplFile).ParseFiles(tmplFile)
+		if err != nil {
+			t.Fatalf("Failed to parse template file: %v", err)
+		}
+
+		var buf bytes.Buffer
+
+		err = tmpl.Execute(&buf, cfg)
+		if err != nil {
+			t.Fatalf("Failed to write template %v", err)
+		}
+
+		for _, comment := range expectedComments {
+			if !strings.Contains(buf.String(), comment) {
+				t.Errorf("Template %v generated a config without %q", tmplFile, comment)
+			}
+		}
+	}
+}
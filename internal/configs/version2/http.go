@@ -2,12 +2,16 @@ package version2
 
 // VirtualServerConfig holds NGINX configuration for a VirtualServer.
 type VirtualServerConfig struct {
-	Server        Server
-	Upstreams     []Upstream
-	SplitClients  []SplitClient
-	Maps          []Map
-	StatusMatches []StatusMatch
-	SpiffeCerts   bool
+	Server         Server
+	Upstreams      []Upstream
+	SplitClients   []SplitClient
+	Maps           []Map
+	StatusMatches  []StatusMatch
+	LogFormats     []LogFormat
+	LimitReqZones  []LimitReqZone
+	LimitConnZones []LimitConnZone
+	CacheZones     []CacheZone
+	SpiffeCerts    bool
 }
 
 // Upstream defines an upstream.
@@ -24,16 +28,21 @@ type Upstream struct {
 	UpstreamZoneSize string
 	Queue            *Queue
 	SessionCookie    *SessionCookie
+	Resolver         *UpstreamResolver
 }
 
 // UpstreamServer defines an upstream server.
 type UpstreamServer struct {
 	Address string
+	Service string
+	Backup  bool
+	Down    bool
 }
 
 // Server defines a server.
 type Server struct {
 	ServerName                string
+	Source                    string
 	StatusZone                string
 	ProxyProtocol             bool
 	SSL                       *SSL
@@ -41,6 +50,7 @@ type Server struct {
 	RealIPHeader              string
 	SetRealIPFrom             []string
 	RealIPRecursive           bool
+	ForwardedHeadersPolicy    string
 	Snippets                  []string
 	InternalRedirectLocations []InternalRedirectLocation
 	Locations                 []Location
@@ -48,19 +58,66 @@ type Server struct {
 	HealthChecks              []HealthCheck
 	TLSRedirect               *TLSRedirect
 	TLSPassthrough            bool
+	RequestIDHeader           string
+	AccessLogFormat           string
+	AccessLogCondition        string
+	OpenTelemetryTrace        string
+	ClientBodyBufferSize      string
+	ClientBodyTempPath        string
+	ClientBodyTimeout         string
+	ClientHeaderBufferSize    string
+	ClientHeaderTimeout       string
+	LargeClientHeaderBuffers  string
+	RequestHeadersTooLarge    bool
+	Compression               *Compression
+	OIDC                      *OIDC
+	LimitConn                 *LimitConn
+	LimitConnStatus           int
+}
+
+// OIDC defines the OpenID Connect authentication for a server.
+type OIDC struct {
+	AuthEndpoint  string
+	TokenEndpoint string
+	JwksURI       string
+	ClientID      string
+	ClientSecret  string
+	Scope         string
+	RedirectURI   string
+}
+
+// Compression defines the compression of responses for a server.
+// Module is the name of the module, either gzip or brotli, which is also the prefix of its directives.
+type Compression struct {
+	Module    string
+	Types     string
+	MinLength int
+	Level     string
+}
+
+// LogFormat defines a log_format.
+type LogFormat struct {
+	Name     string
+	Escaping string
+	Format   []string
 }
 
 // SSL defines SSL configuration for a server.
 type SSL struct {
-	HTTP2          bool
-	Certificate    string
-	CertificateKey string
-	Ciphers        string
+	HTTP2            bool
+	Certificate      string
+	CertificateKey   string
+	Ciphers          string
+	SessionCache     string
+	SessionTimeout   string
+	SessionTickets   string
+	SessionTicketKey string
 }
 
 // Location defines a location.
 type Location struct {
 	Path                     string
+	Source                   string
 	Internal                 bool
 	Snippets                 []string
 	ProxyConnectTimeout      string
@@ -77,6 +134,7 @@ type Location struct {
 	ProxyNextUpstreamTries   int
 	ProxyInterceptErrors     bool
 	ProxyPassRequestHeaders  bool
+	HostHeader               string
 	ProxySetHeaders          []Header
 	ProxyHideHeaders         []string
 	ProxyPassHeaders         []string
@@ -89,6 +147,103 @@ type Location struct {
 	Return                   *Return
 	ErrorPages               []ErrorPage
 	ProxySSLName             string
+	ProxySSLServerName       bool
+	ProxySSLSessionReuse     string
+	ProxySSLProtocols        string
+	ProxySSLCiphers          string
+	AllowedMethods           *AllowedMethods
+	SubFilter                *SubFilter
+	ProxyRequestBuffering    string
+	ProxyCache               *ProxyCache
+	Allow                    []string
+	Deny                     []string
+	LimitReqs                []LimitReq
+	LimitReqStatus           int
+	LimitConns               []LimitConn
+	LimitConnStatus          int
+	JWTAuth                  *JWTAuth
+	OIDC                     bool
+	OIDCErrorLocation        string
+	PoliciesErrorReturn      *Return
+	Mirror                   string
+	MirrorGate               string
+}
+
+// LimitReqZone defines a shared memory zone for the rate limits of a policy.
+type LimitReqZone struct {
+	Key      string
+	ZoneName string
+	ZoneSize string
+	Rate     string
+}
+
+// LimitReq defines a limit_req directive.
+type LimitReq struct {
+	ZoneName string
+	Burst    int
+	NoDelay  bool
+}
+
+// LimitConnZone defines a shared memory zone for the connection limits of a policy.
+type LimitConnZone struct {
+	Key      string
+	ZoneName string
+	ZoneSize string
+}
+
+// JWTAuth holds JWT authentication configuration.
+type JWTAuth struct {
+	Secret string
+	Realm  string
+	Token  string
+}
+
+// CacheZone defines a cache with a shared memory zone for the cache keys.
+type CacheZone struct {
+	Name string
+	Path string
+	Size string
+}
+
+// ProxyCache defines the caching of the responses in a location.
+type ProxyCache struct {
+	Zone             string
+	Lock             bool
+	LockTimeout      string
+	BackgroundUpdate bool
+	Valid            []ProxyCacheValid
+	Key              string
+}
+
+// ProxyCacheValid defines the caching time of the responses with a status code.
+type ProxyCacheValid struct {
+	Code string
+	Time string
+}
+
+// LimitConn defines a limit_conn directive.
+type LimitConn struct {
+	Zone  string
+	Limit int
+}
+
+// SubFilter defines the substitutions of strings in the responses passed from an upstream.
+type SubFilter struct {
+	Substitutions []Substitution
+	Once          bool
+	Types         string
+}
+
+// Substitution defines a sub_filter directive.
+type Substitution struct {
+	From string
+	To   string
+}
+
+// AllowedMethods defines the request methods allowed in a location. Requests with other methods are rejected with the 405 code.
+type AllowedMethods struct {
+	Pattern string
+	Header  string
 }
 
 // SplitClient defines a split_clients.
@@ -154,6 +309,13 @@ type TLSRedirect struct {
 	BasedOn string
 }
 
+// UpstreamResolver defines a resolver for an upstream with servers that are resolved via DNS.
+type UpstreamResolver struct {
+	Addresses []string
+	Valid     string
+	IPV6      bool
+}
+
 // SessionCookie defines a session cookie for an upstream.
 type SessionCookie struct {
 	Enable   bool
@@ -173,14 +335,18 @@ type Distribution struct {
 
 // InternalRedirectLocation defines a location for internally redirecting requests to named locations.
 type InternalRedirectLocation struct {
-	Path        string
-	Destination string
+	Path           string
+	Source         string
+	Destination    string
+	Internal       bool
+	AllowedMethods *AllowedMethods
 }
 
 // Map defines a map.
 type Map struct {
 	Source     string
 	Variable   string
+	Hostnames  bool
 	Parameters []Parameter
 }
 
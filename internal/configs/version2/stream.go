@@ -15,18 +15,29 @@ type StreamUpstream struct {
 // StreamUpstreamServer defines a stream upstream server.
 type StreamUpstreamServer struct {
 	Address string
+	Weight  int
 }
 
 // StreamServer defines a server in the stream module.
 type StreamServer struct {
-	TLSPassthrough bool
-	UnixSocket     string
-	Port           int
-	UDP            bool
-	StatusZone     string
-	ProxyRequests  *int
-	ProxyResponses *int
-	ProxyPass      string
+	TLSPassthrough      bool
+	UnixSocket          string
+	Port                int
+	IPv4                string
+	IPv6                string
+	UDP                 bool
+	Backlog             int
+	Reuseport           bool
+	Fastopen            int
+	Deferred            bool
+	SoKeepalive         string
+	StatusZone          string
+	ProxyRequests       *int
+	ProxyResponses      *int
+	ProxyTimeout        string
+	ProxyConnectTimeout string
+	ProxyProtocol       bool
+	ProxyPass           string
 }
 
 // TLSPassthroughHostsConfig defines a mapping between TLS Passthrough hosts and the corresponding unix sockets.
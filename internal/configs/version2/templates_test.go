@@ -1,6 +1,7 @@
 package version2
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -98,6 +99,21 @@ var virtualServerCfg = VirtualServerConfig{
 				},
 			},
 		},
+		{
+			Source:    "$host",
+			Variable:  "$vs_default_cafe_map_tenant",
+			Hostnames: true,
+			Parameters: []Parameter{
+				{
+					Value:  `"*.example.com"`,
+					Result: `"example"`,
+				},
+				{
+					Value:  "default",
+					Result: `""`,
+				},
+			},
+		},
 	},
 	Server: Server{
 		ServerName:    "example.com",
@@ -113,11 +129,13 @@ var virtualServerCfg = VirtualServerConfig{
 			BasedOn: "$scheme",
 			Code:    301,
 		},
-		ServerTokens:    "off",
-		SetRealIPFrom:   []string{"0.0.0.0/0"},
-		RealIPHeader:    "X-Real-IP",
-		RealIPRecursive: true,
-		Snippets:        []string{"# server snippet"},
+		ServerTokens:         "off",
+		ClientBodyBufferSize: "16k",
+		ClientBodyTempPath:   "/var/cache/nginx/uploads",
+		SetRealIPFrom:        []string{"0.0.0.0/0"},
+		RealIPHeader:         "X-Real-IP",
+		RealIPRecursive:      true,
+		Snippets:             []string{"# server snippet"},
 		InternalRedirectLocations: []InternalRedirectLocation{
 			{
 				Path:        "/split",
@@ -145,6 +163,7 @@ var virtualServerCfg = VirtualServerConfig{
 				ProxyNextUpstreamTimeout: "5s",
 				Internal:                 true,
 				ProxyPassRequestHeaders:  false,
+				HostHeader:               "tea.example.com",
 				ProxyPassHeaders:         []string{"Host"},
 				ProxyPassRewrite:         "$request_uri",
 				ProxyHideHeaders:         []string{"Header"},
@@ -344,3 +363,1392 @@ func TestTLSPassthroughHosts(t *testing.T) {
 
 	t.Log(string(data))
 }
+
+func TestVirtualServerWithRequestID(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.LogFormats = []LogFormat{
+		{
+			Name:   "vs_default_cafe_request_id",
+			Format: []string{`$remote_addr "$request"`, `"$request_id"`},
+		},
+	}
+	cfg.Server.RequestIDHeader = "X-Request-ID"
+	cfg.Server.AccessLogFormat = "vs_default_cafe_request_id"
+
+	expectedDirectives := []string{
+		`log_format vs_default_cafe_request_id '$remote_addr "$request"' ' "$request_id"';`,
+		"access_log /var/log/nginx/access.log vs_default_cafe_request_id;",
+		"proxy_set_header X-Request-ID $request_id;",
+	}
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithStickySplits(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Maps = []Map{
+		{
+			Source:   "$cookie_vs_split_id",
+			Variable: "$vs_default_cafe_splits_0_sticky_key",
+			Parameters: []Parameter{
+				{
+					Value:  `""`,
+					Result: "$request_id",
+				},
+				{
+					Value:  "default",
+					Result: "$cookie_vs_split_id",
+				},
+			},
+		},
+		{
+			Source:   "$cookie_vs_split_id",
+			Variable: "$vs_default_cafe_splits_0_sticky_cookie",
+			Parameters: []Parameter{
+				{
+					Value:  `""`,
+					Result: `"vs_split_id=$request_id; Path=/"`,
+				},
+				{
+					Value:  "default",
+					Result: `""`,
+				},
+			},
+		},
+	}
+	cfg.SplitClients = []SplitClient{
+		{
+			Source:   "$vs_default_cafe_splits_0_sticky_key",
+			Variable: "$vs_default_cafe_splits_0",
+			Distributions: []Distribution{
+				{
+					Weight: "90%",
+					Value:  "/internal_location_splits_0_split_0",
+				},
+				{
+					Weight: "10%",
+					Value:  "/internal_location_splits_0_split_1",
+				},
+			},
+		},
+	}
+	cfg.Server.Locations = []Location{
+		{
+			Path:      "/internal_location_splits_0_split_0",
+			ProxyPass: "http://coffee-v1",
+			Internal:  true,
+			AddHeaders: []AddHeader{
+				{
+					Header: Header{
+						Name:  "Set-Cookie",
+						Value: "$vs_default_cafe_splits_0_sticky_cookie",
+					},
+					Always: true,
+				},
+			},
+		},
+	}
+
+	expectedDirectives := []string{
+		"map $cookie_vs_split_id $vs_default_cafe_splits_0_sticky_key {",
+		`"" $request_id;`,
+		`"" "vs_split_id=$request_id; Path=/";`,
+		"split_clients $vs_default_cafe_splits_0_sticky_key $vs_default_cafe_splits_0 {",
+		`add_header Set-Cookie "$vs_default_cafe_splits_0_sticky_cookie" always;`,
+	}
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithSubFilter(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.Locations = []Location{
+		{
+			Path:      "/tea",
+			ProxyPass: "http://tea",
+			SubFilter: &SubFilter{
+				Substitutions: []Substitution{
+					{
+						From: "http://backend.local",
+						To:   "https://example.com",
+					},
+					{
+						From: "backend.local",
+						To:   "example.com",
+					},
+				},
+				Once:  false,
+				Types: "text/css application/javascript",
+			},
+		},
+	}
+
+	expectedDirectives := []string{
+		`proxy_set_header Accept-Encoding "";`,
+		`sub_filter "http://backend.local" "https://example.com";`,
+		`sub_filter "backend.local" "example.com";`,
+		"sub_filter_once off;",
+		"sub_filter_types text/css application/javascript;",
+	}
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithRequestBuffering(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.Locations = []Location{
+		{
+			Path:                  "/upload",
+			ProxyPass:             "http://tea",
+			ProxyRequestBuffering: "off",
+		},
+		{
+			Path:      "/tea",
+			ProxyPass: "http://tea",
+		},
+	}
+
+	directive := "proxy_request_buffering off;"
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		if count := bytes.Count(data, []byte("proxy_request_buffering")); count != 1 {
+			t.Errorf("Template %v generated a config with %d proxy_request_buffering directives but expected 1", tmpl, count)
+		}
+		if !bytes.Contains(data, []byte(directive)) {
+			t.Errorf("Template %v generated a config without %q", tmpl, directive)
+		}
+	}
+}
+
+func TestVirtualServerWithHealthCheckPort(t *testing.T) {
+	tests := []struct {
+		port     int
+		expected string
+	}{
+		{
+			port:     8081,
+			expected: "health_check uri=/healthz port=8081 interval=5s jitter=0s",
+		},
+		{
+			port:     0,
+			expected: "health_check uri=/healthz interval=5s jitter=0s",
+		},
+	}
+
+	for _, test := range tests {
+		cfg := virtualServerCfg
+		cfg.Server.HealthChecks = []HealthCheck{
+			{
+				Name:                "coffee",
+				URI:                 "/healthz",
+				Interval:            "5s",
+				Jitter:              "0s",
+				Fails:               1,
+				Passes:              1,
+				Port:                test.port,
+				ProxyPass:           "http://coffee",
+				ProxyConnectTimeout: "5s",
+				ProxyReadTimeout:    "5s",
+				ProxySendTimeout:    "5s",
+			},
+		}
+
+		executor, err := NewTemplateExecutor(nginxPlusVirtualServerTmpl, nginxPlusTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		if !bytes.Contains(data, []byte(test.expected)) {
+			t.Errorf("Template generated a config without %q for the health check port %d", test.expected, test.port)
+		}
+	}
+}
+
+func TestVirtualServerWithLimitConn(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.Locations = []Location{
+		{
+			Path:      "/tea",
+			ProxyPass: "http://tea",
+			LimitConns: []LimitConn{
+				{
+					Zone:  "conn_limit_shared-backend",
+					Limit: 100,
+				},
+			},
+		},
+	}
+
+	directive := "limit_conn conn_limit_shared-backend 100;"
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		if !bytes.Contains(data, []byte(directive)) {
+			t.Errorf("Template %v generated a config without %q", tmpl, directive)
+		}
+	}
+}
+
+func TestVirtualServerWithConnectionLimit(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.LimitConnZones = []LimitConnZone{
+		{
+			Key:      "${binary_remote_addr}",
+			ZoneName: "vs_cl_default_cafe",
+			ZoneSize: "10m",
+		},
+	}
+	cfg.Server.LimitConn = &LimitConn{
+		Zone:  "vs_cl_default_cafe",
+		Limit: 10,
+	}
+	cfg.Server.LimitConnStatus = 429
+
+	expectedDirectives := []string{
+		"limit_conn_zone ${binary_remote_addr} zone=vs_cl_default_cafe:10m;",
+		"limit_conn vs_cl_default_cafe 10;",
+		"limit_conn_status 429;",
+	}
+
+	for _, tmpl := range []string{nginxVirtualServerTmpl, nginxPlusVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxPlusTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithTLSSession(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.SSL = &SSL{
+		Certificate:      "cafe-secret.pem",
+		CertificateKey:   "cafe-secret.pem",
+		SessionCache:     "shared:vs_default_cafe_ssl:10m",
+		SessionTimeout:   "1h",
+		SessionTickets:   "on",
+		SessionTicketKey: "/etc/nginx/secrets/default-ticket-key",
+	}
+
+	expectedDirectives := []string{
+		"ssl_session_cache shared:vs_default_cafe_ssl:10m;",
+		"ssl_session_timeout 1h;",
+		"ssl_session_tickets on;",
+		"ssl_session_ticket_key /etc/nginx/secrets/default-ticket-key;",
+	}
+
+	for _, tmpl := range []string{nginxVirtualServerTmpl, nginxPlusVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxPlusTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithClientTimeouts(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.ClientHeaderTimeout = "10s"
+	cfg.Server.ClientBodyTimeout = "30s"
+
+	expectedDirectives := []string{
+		"client_header_timeout 10s;",
+		"client_body_timeout 30s;",
+	}
+
+	for _, tmpl := range []string{nginxVirtualServerTmpl, nginxPlusVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxPlusTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithClientHeaderBuffers(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.ClientHeaderBufferSize = "2k"
+	cfg.Server.LargeClientHeaderBuffers = "4 16k"
+	cfg.Server.RequestHeadersTooLarge = true
+
+	expectedDirectives := []string{
+		"client_header_buffer_size 2k;",
+		"large_client_header_buffers 4 16k;",
+		"error_page 494 =431 @request_headers_too_large;",
+		"location @request_headers_too_large {",
+		"return 431;",
+	}
+
+	for _, tmpl := range []string{nginxVirtualServerTmpl, nginxPlusVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxPlusTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithProxySSLParameters(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.Locations = []Location{
+		{
+			Path:                 "/tea",
+			ProxyPass:            "https://vs_default_cafe_tea",
+			ProxySSLSessionReuse: "off",
+			ProxySSLProtocols:    "TLSv1.2 TLSv1.3",
+			ProxySSLCiphers:      "HIGH:!aNULL",
+		},
+	}
+
+	expectedDirectives := []string{
+		"proxy_ssl_session_reuse off;",
+		"proxy_ssl_protocols TLSv1.2 TLSv1.3;",
+		"proxy_ssl_ciphers HIGH:!aNULL;",
+	}
+
+	for _, tmpl := range []string{nginxVirtualServerTmpl, nginxPlusVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxPlusTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithProxySSLServerName(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.Locations = []Location{
+		{
+			Path:               "/tea",
+			ProxyPass:          "https://vs_default_cafe_tea",
+			ProxySSLName:       "tea.example.com",
+			ProxySSLServerName: true,
+		},
+	}
+
+	expectedDirectives := []string{
+		"proxy_ssl_server_name on;",
+		"proxy_ssl_name tea.example.com;",
+	}
+
+	for _, tmpl := range []string{nginxVirtualServerTmpl, nginxPlusVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxPlusTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithReturnHeaders(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.Locations = []Location{
+		{
+			Path:        "/maintenance",
+			DefaultType: "text/html",
+			Return: &Return{
+				Code: 503,
+				Text: "Under maintenance",
+			},
+			AddHeaders: []AddHeader{
+				{
+					Header: Header{
+						Name:  "Retry-After",
+						Value: "3600",
+					},
+					Always: true,
+				},
+			},
+		},
+	}
+
+	expectedDirectives := []string{
+		`default_type "text/html";`,
+		`add_header Retry-After "3600" always;`,
+		`return 503 "Under maintenance";`,
+	}
+
+	for _, tmpl := range []string{nginxVirtualServerTmpl, nginxPlusVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxPlusTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerForNginxPlusWithOIDC(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.OIDC = &OIDC{
+		AuthEndpoint:  "https://idp.example.com/auth",
+		TokenEndpoint: "https://idp.example.com/token",
+		JwksURI:       "https://idp.example.com/certs",
+		ClientID:      "nginx-plus",
+		ClientSecret:  "secret",
+		Scope:         "openid+profile",
+		RedirectURI:   "/_codexch",
+	}
+	cfg.Server.Locations = []Location{
+		{
+			Path:              "/",
+			ProxyPass:         "http://test-upstream",
+			OIDC:              true,
+			OIDCErrorLocation: "@oidc_fail_open_0",
+		},
+		{
+			Path:      "@oidc_fail_open_0",
+			ProxyPass: "http://test-upstream",
+		},
+	}
+
+	expectedDirectives := []string{
+		"include oidc/oidc.conf;",
+		`set $oidc_authz_endpoint "https://idp.example.com/auth";`,
+		`set $oidc_token_endpoint "https://idp.example.com/token";`,
+		`set $oidc_jwt_keyfile "https://idp.example.com/certs";`,
+		`set $oidc_scopes "openid+profile";`,
+		`set $oidc_client "nginx-plus";`,
+		`set $oidc_client_secret "secret";`,
+		`set $redir_location "/_codexch";`,
+		"location = /_codexch {",
+		"js_content oidc.codeExchange;",
+		`auth_jwt "" token=$session_jwt;`,
+		"error_page 401 = @do_oidc_flow;",
+		"error_page 500 = @oidc_fail_open_0;",
+		"auth_jwt_key_request /_jwks_uri;",
+		"location @oidc_fail_open_0 {",
+	}
+
+	executor, err := NewTemplateExecutor(nginxPlusVirtualServerTmpl, nginxPlusTransportServerTmpl)
+	if err != nil {
+		t.Fatalf("Failed to create template executor: %v", err)
+	}
+
+	data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	for _, directive := range expectedDirectives {
+		if !bytes.Contains(data, []byte(directive)) {
+			t.Errorf("Template generated a config without %q", directive)
+		}
+	}
+}
+
+func TestVirtualServerWithCustomLogFields(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.LogFormats = []LogFormat{
+		{
+			Name:   "vs_default_cafe_custom",
+			Format: []string{`$remote_addr "$request"`, `user="$http_x_user"`},
+		},
+	}
+	cfg.Server.AccessLogFormat = "vs_default_cafe_custom"
+
+	expectedDirectives := []string{
+		`log_format vs_default_cafe_custom '$remote_addr "$request"' ' user="$http_x_user"';`,
+		"access_log /var/log/nginx/access.log vs_default_cafe_custom;",
+	}
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithAccessLogCondition(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.AccessLogFormat = "vs_default_cafe_request_id"
+	cfg.Server.AccessLogCondition = "$access_log_sampled"
+
+	directive := "access_log /var/log/nginx/access.log vs_default_cafe_request_id if=$access_log_sampled;"
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		if !bytes.Contains(data, []byte(directive)) {
+			t.Errorf("Template %v generated a config without %q", tmpl, directive)
+		}
+	}
+}
+
+func TestVirtualServerWithCache(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.CacheZones = []CacheZone{
+		{
+			Name: "vs_default_cafe_tea",
+			Path: "/var/cache/nginx/vs_default_cafe_tea",
+			Size: "10m",
+		},
+	}
+	cfg.Server.Locations = []Location{
+		{
+			Path:      "/tea",
+			ProxyPass: "http://vs_default_cafe_tea",
+			ProxyCache: &ProxyCache{
+				Zone:             "vs_default_cafe_tea",
+				Lock:             true,
+				LockTimeout:      "10s",
+				BackgroundUpdate: true,
+				Valid: []ProxyCacheValid{
+					{Code: "200", Time: "10m"},
+					{Code: "404", Time: "1m"},
+					{Code: "any", Time: "5s"},
+				},
+				Key: "${scheme}${host}${request_uri}${http_authorization}",
+			},
+		},
+	}
+
+	directives := []string{
+		`proxy_cache_key "${scheme}${host}${request_uri}${http_authorization}";`,
+		"proxy_cache_path /var/cache/nginx/vs_default_cafe_tea keys_zone=vs_default_cafe_tea:10m",
+		"proxy_cache vs_default_cafe_tea;",
+		"proxy_cache_lock on;",
+		"proxy_cache_lock_timeout 10s;",
+		"proxy_cache_background_update on;",
+		"proxy_cache_use_stale updating;",
+		"proxy_cache_valid 200 10m;",
+		"proxy_cache_valid 404 1m;",
+		"proxy_cache_valid any 5s;",
+	}
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range directives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+
+		// the cache purger removes the entries purged by a wildcard key from the disk in NGINX Plus
+		purger := "keys_zone=vs_default_cafe_tea:10m purger=on;"
+		if tmpl == nginxPlusVirtualServerTmpl && !bytes.Contains(data, []byte(purger)) {
+			t.Errorf("Template %v generated a config without %q", tmpl, purger)
+		}
+	}
+}
+
+func TestVirtualServerWithSampledMirror(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.SplitClients = []SplitClient{
+		{
+			Source:   "$request_id",
+			Variable: "$vs_default_cafe_mirror_0",
+			Distributions: []Distribution{
+				{
+					Weight: "10%",
+					Value:  "1",
+				},
+				{
+					Weight: "*",
+					Value:  `""`,
+				},
+			},
+		},
+	}
+	cfg.Server.Locations = []Location{
+		{
+			Path:      "/tea",
+			ProxyPass: "http://vs_default_cafe_tea",
+			Mirror:    "/internal_location_mirror_0",
+		},
+		{
+			Path:       "/internal_location_mirror_0",
+			Internal:   true,
+			ProxyPass:  "http://vs_default_cafe_tea-shadow$request_uri",
+			MirrorGate: "$vs_default_cafe_mirror_0",
+		},
+	}
+
+	directives := []string{
+		"split_clients $request_id $vs_default_cafe_mirror_0 {",
+		"10% 1;",
+		`* "";`,
+		"mirror /internal_location_mirror_0;",
+		`if ($vs_default_cafe_mirror_0 = "") {`,
+		"proxy_pass http://vs_default_cafe_tea-shadow$request_uri;",
+	}
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range directives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithPolicies(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.LimitReqZones = []LimitReqZone{
+		{
+			Key:      "${binary_remote_addr}",
+			ZoneName: "pol_rl_default_rate-limit_default_cafe",
+			ZoneSize: "10m",
+			Rate:     "10r/s",
+		},
+	}
+	cfg.LimitConnZones = []LimitConnZone{
+		{
+			Key:      "${binary_remote_addr}",
+			ZoneName: "pol_cl_default_combined-limit_default_cafe",
+			ZoneSize: "10m",
+		},
+	}
+	cfg.Server.Locations = []Location{
+		{
+			Path:      "/tea",
+			ProxyPass: "http://tea",
+			Allow:     []string{"10.0.0.0/8"},
+			LimitReqs: []LimitReq{
+				{
+					ZoneName: "pol_rl_default_rate-limit_default_cafe",
+					Burst:    5,
+					NoDelay:  true,
+				},
+			},
+			LimitReqStatus: 429,
+			LimitConns: []LimitConn{
+				{
+					Zone:  "pol_cl_default_combined-limit_default_cafe",
+					Limit: 10,
+				},
+			},
+			LimitConnStatus: 429,
+			JWTAuth: &JWTAuth{
+				Secret: "/etc/nginx/secrets/default-jwk-secret",
+				Realm:  "My API",
+				Token:  "$http_token",
+			},
+		},
+		{
+			Path:      "/coffee",
+			ProxyPass: "http://coffee",
+			Deny:      []string{"127.0.0.1"},
+			PoliciesErrorReturn: &Return{
+				Code: 500,
+			},
+		},
+	}
+
+	directives := []string{
+		"limit_req_zone ${binary_remote_addr} zone=pol_rl_default_rate-limit_default_cafe:10m rate=10r/s;",
+		"allow 10.0.0.0/8;",
+		"deny all;",
+		"limit_req zone=pol_rl_default_rate-limit_default_cafe burst=5 nodelay;",
+		"limit_req_status 429;",
+		"limit_conn_zone ${binary_remote_addr} zone=pol_cl_default_combined-limit_default_cafe:10m;",
+		"limit_conn pol_cl_default_combined-limit_default_cafe 10;",
+		"limit_conn_status 429;",
+		"deny 127.0.0.1;",
+		"allow all;",
+		"return 500;",
+	}
+	plusDirectives := []string{
+		`auth_jwt "My API" token=$http_token;`,
+		"auth_jwt_key_file /etc/nginx/secrets/default-jwk-secret;",
+	}
+
+	tests := []struct {
+		tmpl        string
+		expected    []string
+		notExpected []string
+	}{
+		{
+			tmpl:     nginxPlusVirtualServerTmpl,
+			expected: append(directives, plusDirectives...),
+		},
+		{
+			tmpl:        nginxVirtualServerTmpl,
+			expected:    directives,
+			notExpected: plusDirectives,
+		},
+	}
+
+	for _, test := range tests {
+		executor, err := NewTemplateExecutor(test.tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range test.expected {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", test.tmpl, directive)
+			}
+		}
+		for _, directive := range test.notExpected {
+			if bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config with %q", test.tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithAllowedMethods(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.InternalRedirectLocations = []InternalRedirectLocation{
+		{
+			Path:        "/split",
+			Destination: "@split_0",
+			AllowedMethods: &AllowedMethods{
+				Pattern: "POST|PUT",
+				Header:  "POST, PUT",
+			},
+		},
+	}
+	cfg.Server.Locations = []Location{
+		{
+			Path:      "/tea",
+			ProxyPass: "http://tea",
+			AllowedMethods: &AllowedMethods{
+				Pattern: "GET|HEAD",
+				Header:  "GET, HEAD",
+			},
+		},
+	}
+
+	expectedDirectives := []string{
+		`if ($request_method !~ "^(GET|HEAD)$") {`,
+		`add_header Allow "GET, HEAD" always;`,
+		`if ($request_method !~ "^(POST|PUT)$") {`,
+		`add_header Allow "POST, PUT" always;`,
+		"return 405;",
+	}
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithCompression(t *testing.T) {
+	tests := []struct {
+		compression        *Compression
+		expectedDirectives []string
+	}{
+		{
+			compression: &Compression{
+				Module:    "gzip",
+				Types:     "application/json text/css",
+				MinLength: 1000,
+				Level:     "6",
+			},
+			expectedDirectives: []string{
+				"gzip on;",
+				"gzip_types application/json text/css;",
+				"gzip_min_length 1000;",
+				"gzip_comp_level 6;",
+			},
+		},
+		{
+			compression: &Compression{
+				Module: "brotli",
+				Level:  "0",
+			},
+			expectedDirectives: []string{
+				"brotli on;",
+				"brotli_comp_level 0;",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cfg := virtualServerCfg
+		cfg.Server.Compression = test.compression
+
+		for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+			executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+			if err != nil {
+				t.Fatalf("Failed to create template executor: %v", err)
+			}
+
+			data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+			if err != nil {
+				t.Fatalf("Failed to execute template: %v", err)
+			}
+
+			for _, directive := range test.expectedDirectives {
+				if !bytes.Contains(data, []byte(directive)) {
+					t.Errorf("Template %v generated a config without %q", tmpl, directive)
+				}
+			}
+		}
+	}
+}
+
+func TestVirtualServerForNginxPlusWithSRVUpstream(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Upstreams = []Upstream{
+		{
+			Name: "test-upstream",
+			Servers: []UpstreamServer{
+				{
+					Address: "tea-svc.default.svc.cluster.local",
+					Service: "_http._tcp",
+				},
+			},
+			Resolve:          true,
+			MaxFails:         1,
+			FailTimeout:      "10s",
+			UpstreamZoneSize: "256k",
+		},
+	}
+
+	executor, err := NewTemplateExecutor(nginxPlusVirtualServerTmpl, nginxPlusTransportServerTmpl)
+	if err != nil {
+		t.Fatalf("Failed to create template executor: %v", err)
+	}
+
+	data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	expected := "server tea-svc.default.svc.cluster.local service=_http._tcp max_fails=1 fail_timeout=10s max_conns=0 resolve;"
+	if !bytes.Contains(data, []byte(expected)) {
+		t.Errorf("Template generated a config without %q", expected)
+	}
+}
+
+func TestVirtualServerWithBackupServers(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Upstreams = []Upstream{
+		{
+			Name: "test-upstream",
+			Servers: []UpstreamServer{
+				{
+					Address: "10.0.0.20:8001",
+				},
+				{
+					Address: "10.0.0.30:8001",
+					Backup:  true,
+				},
+			},
+			MaxFails:         1,
+			FailTimeout:      "10s",
+			UpstreamZoneSize: "256k",
+		},
+	}
+
+	expected := "server 10.0.0.30:8001 max_fails=1 fail_timeout=10s max_conns=0 backup;"
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxPlusTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		if !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("Template %v generated a config without %q", tmpl, expected)
+		}
+		if !bytes.Contains(data, []byte("server 10.0.0.20:8001 max_fails=1 fail_timeout=10s max_conns=0;")) {
+			t.Errorf("Template %v generated a config with the primary server marked as backup", tmpl)
+		}
+	}
+}
+
+func TestVirtualServerForNginxPlusWithUpstreamResolver(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Upstreams = []Upstream{
+		{
+			Name: "test-upstream",
+			Servers: []UpstreamServer{
+				{
+					Address: "tea.example.com:80",
+				},
+			},
+			Resolve:          true,
+			MaxFails:         1,
+			FailTimeout:      "10s",
+			UpstreamZoneSize: "256k",
+			Resolver: &UpstreamResolver{
+				Addresses: []string{"10.0.0.5", "10.0.0.6"},
+				Valid:     "5s",
+			},
+		},
+	}
+
+	executor, err := NewTemplateExecutor(nginxPlusVirtualServerTmpl, nginxPlusTransportServerTmpl)
+	if err != nil {
+		t.Fatalf("Failed to create template executor: %v", err)
+	}
+
+	data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	expected := "resolver 10.0.0.5 10.0.0.6 valid=5s ipv6=off;"
+	if !bytes.Contains(data, []byte(expected)) {
+		t.Errorf("Template generated a config without %q", expected)
+	}
+}
+
+func TestTransportServerWithBindAddresses(t *testing.T) {
+	cfg := transportServerCfg
+	cfg.Server.IPv4 = "10.0.0.1"
+	cfg.Server.IPv6 = "::1"
+
+	expectedDirectives := []string{
+		"listen 10.0.0.1:1234 udp;",
+		"listen [::1]:1234 udp;",
+	}
+
+	for _, tmpl := range []string{nginxPlusTransportServerTmpl, nginxTransportServerTmpl} {
+		executor, err := NewTemplateExecutor(nginxVirtualServerTmpl, tmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteTransportServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range expectedDirectives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+		if bytes.Contains(data, []byte("listen 1234 udp;")) {
+			t.Errorf("Template %v generated a config with a listen directive for all addresses", tmpl)
+		}
+	}
+}
+
+func TestTransportServerWithListenParameters(t *testing.T) {
+	cfg := transportServerCfg
+	cfg.Server.UDP = false
+	cfg.Server.Backlog = 1024
+	cfg.Server.Reuseport = true
+	cfg.Server.Fastopen = 256
+	cfg.Server.Deferred = true
+	cfg.Server.SoKeepalive = "30m::10"
+
+	directive := "listen 1234 reuseport backlog=1024 fastopen=256 deferred so_keepalive=30m::10;"
+
+	for _, tmpl := range []string{nginxPlusTransportServerTmpl, nginxTransportServerTmpl} {
+		executor, err := NewTemplateExecutor(nginxVirtualServerTmpl, tmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteTransportServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		if !bytes.Contains(data, []byte(directive)) {
+			t.Errorf("Template %v generated a config without %q", tmpl, directive)
+		}
+	}
+}
+
+func TestTransportServerWithProxyTimeouts(t *testing.T) {
+	cfg := transportServerCfg
+	cfg.Server.ProxyTimeout = "1h"
+	cfg.Server.ProxyConnectTimeout = "30s"
+
+	directives := []string{
+		"proxy_timeout 1h;",
+		"proxy_connect_timeout 30s;",
+	}
+
+	for _, tmpl := range []string{nginxPlusTransportServerTmpl, nginxTransportServerTmpl} {
+		executor, err := NewTemplateExecutor(nginxVirtualServerTmpl, tmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteTransportServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range directives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestTransportServerWithWeights(t *testing.T) {
+	cfg := transportServerCfg
+	cfg.Upstreams = []StreamUpstream{
+		{
+			Name: "udp-upstream",
+			Servers: []StreamUpstreamServer{
+				{
+					Address: "10.0.0.20:5001",
+					Weight:  5,
+				},
+				{
+					Address: "10.0.0.21:5001",
+				},
+			},
+		},
+	}
+
+	directives := []string{
+		"server 10.0.0.20:5001 weight=5;",
+		"server 10.0.0.21:5001;",
+	}
+
+	for _, tmpl := range []string{nginxPlusTransportServerTmpl, nginxTransportServerTmpl} {
+		executor, err := NewTemplateExecutor(nginxVirtualServerTmpl, tmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteTransportServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range directives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestTransportServerWithUDPParameters(t *testing.T) {
+	cfg := transportServerCfg
+	cfg.Server.ProxyResponses = createPointerFromInt(0)
+
+	directives := []string{
+		"listen 1234 udp;",
+		"proxy_requests 1;",
+		"proxy_responses 0;",
+	}
+
+	for _, tmpl := range []string{nginxPlusTransportServerTmpl, nginxTransportServerTmpl} {
+		executor, err := NewTemplateExecutor(nginxVirtualServerTmpl, tmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteTransportServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, directive := range directives {
+			if !bytes.Contains(data, []byte(directive)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, directive)
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithForwardedHeadersPolicies(t *testing.T) {
+	tests := []struct {
+		policy     string
+		expected   []string
+		unexpected []string
+	}{
+		{
+			policy: "append",
+			expected: []string{
+				"proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;",
+				"proxy_set_header X-Forwarded-Host $host;",
+			},
+		},
+		{
+			policy: "replace",
+			expected: []string{
+				"proxy_set_header X-Forwarded-For $remote_addr;",
+				"proxy_set_header X-Forwarded-Host $host;",
+			},
+		},
+		{
+			policy: "pass",
+			unexpected: []string{
+				"X-Forwarded-For",
+				"X-Forwarded-Host",
+				"X-Forwarded-Port",
+				"X-Forwarded-Proto",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cfg := virtualServerCfg
+		cfg.Server.ForwardedHeadersPolicy = test.policy
+
+		for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+			executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+			if err != nil {
+				t.Fatalf("Failed to create template executor: %v", err)
+			}
+
+			data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+			if err != nil {
+				t.Fatalf("Failed to execute template: %v", err)
+			}
+
+			for _, directive := range test.expected {
+				if !bytes.Contains(data, []byte(directive)) {
+					t.Errorf("Template %v generated a config without %q for the policy %q", tmpl, directive, test.policy)
+				}
+			}
+			for _, header := range test.unexpected {
+				if bytes.Contains(data, []byte(header)) {
+					t.Errorf("Template %v generated a config with %q for the policy %q", tmpl, header, test.policy)
+				}
+			}
+		}
+	}
+}
+
+func TestVirtualServerWithSourceComments(t *testing.T) {
+	cfg := virtualServerCfg
+	cfg.Server.Source = "source: VirtualServer default/cafe"
+	cfg.Server.InternalRedirectLocations = []InternalRedirectLocation{
+		{
+			Path:        "/coffee",
+			Destination: "$vs_default_cafe_splits_0",
+			Source:      `source: VirtualServer default/cafe, route "/coffee"`,
+		},
+	}
+	cfg.Server.Locations = []Location{
+		{
+			Path:      "/tea",
+			ProxyPass: "http://tea",
+			Source:    `source: VirtualServerRoute default/tea, subroute "/tea"`,
+		},
+	}
+
+	expectedComments := []string{
+		"# source: VirtualServer default/cafe\n",
+		`# source: VirtualServer default/cafe, route "/coffee"`,
+		`# source: VirtualServerRoute default/tea, subroute "/tea"`,
+	}
+
+	for _, tmpl := range []string{nginxPlusVirtualServerTmpl, nginxVirtualServerTmpl} {
+		executor, err := NewTemplateExecutor(tmpl, nginxTransportServerTmpl)
+		if err != nil {
+			t.Fatalf("Failed to create template executor: %v", err)
+		}
+
+		data, err := executor.ExecuteVirtualServerTemplate(&cfg)
+		if err != nil {
+			t.Fatalf("Failed to execute template: %v", err)
+		}
+
+		for _, comment := range expectedComments {
+			if !bytes.Contains(data, []byte(comment)) {
+				t.Errorf("Template %v generated a config without %q", tmpl, comment)
+			}
+		}
+	}
+}
@@ -2,6 +2,8 @@ package configs
 
 import (
 	"fmt"
+	"net"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -18,6 +20,17 @@ import (
 
 const nginx502Server = "unix:/var/lib/nginx/nginx-502-server.sock"
 const internalLocationPrefix = "internal_location_"
+const defaultRequestIDHeader = "X-Request-ID"
+const defaultStickySplitsCookieName = "vs_split_id"
+const defaultOIDCScope = "openid"
+const defaultOIDCRedirectURI = "/_codexch"
+
+// defaultMainLogFormat is the default format of the main access log. It must match the format in the main NGINX template.
+var defaultMainLogFormat = []string{
+	`$remote_addr - $remote_user [$time_local] "$request"`,
+	`$status $body_bytes_sent "$http_referer"`,
+	`"$http_user_agent" "$http_x_forwarded_for"`,
+}
 
 var incompatibleLBMethodsForSlowStart = map[string]bool{
 	"random":                          true,
@@ -30,11 +43,15 @@ var incompatibleLBMethodsForSlowStart = map[string]bool{
 
 // VirtualServerEx holds a VirtualServer along with the resources that are referenced in this VirtualServer.
 type VirtualServerEx struct {
-	VirtualServer       *conf_v1.VirtualServer
-	Endpoints           map[string][]string
-	TLSSecret           *api_v1.Secret
-	VirtualServerRoutes []*conf_v1.VirtualServerRoute
-	ExternalNameSvcs    map[string]bool
+	VirtualServer          *conf_v1.VirtualServer
+	Endpoints              map[string][]string
+	TLSSecret              *api_v1.Secret
+	VirtualServerRoutes    []*conf_v1.VirtualServerRoute
+	ExternalNameSvcs       map[string]bool
+	Policies               map[string]*conf_v1alpha1.Policy
+	JWTKeys                map[string]*api_v1.Secret
+	OIDCSecret             *api_v1.Secret
+	SessionTicketKeySecret *api_v1.Secret
 }
 
 func (vsx *VirtualServerEx) String() string {
@@ -90,6 +107,10 @@ func (namer *upstreamNamer) GetNameForUpstreamFromAction(action *conf_v1.Action)
 		upstream = action.Pass
 	}
 
+	if mapName := strings.TrimPrefix(upstream, "$"); mapName != upstream {
+		return namer.GetNameForUpstreamVariable(mapName)
+	}
+
 	return fmt.Sprintf("%s_%s", namer.prefix, upstream)
 }
 
@@ -97,14 +118,27 @@ func (namer *upstreamNamer) GetNameForUpstream(upstream string) string {
 	return fmt.Sprintf("%s_%s", namer.prefix, upstream)
 }
 
+// GetNameForUpstreamVariable returns the NGINX variable with the name of the upstream selected by a map of the VirtualServer.
+func (namer *upstreamNamer) GetNameForUpstreamVariable(mapName string) string {
+	return fmt.Sprintf("$%s_map_%s_upstream", strings.ReplaceAll(namer.prefix, "-", "_"), mapName)
+}
+
 type variableNamer struct {
 	safeNsName string
+	mapNames   map[string]bool
 }
 
 func newVariableNamer(virtualServer *conf_v1.VirtualServer) *variableNamer {
 	safeNsName := strings.ReplaceAll(fmt.Sprintf("%s_%s", virtualServer.Namespace, virtualServer.Name), "-", "_")
+
+	mapNames := make(map[string]bool)
+	for _, m := range virtualServer.Spec.Maps {
+		mapNames[m.Name] = true
+	}
+
 	return &variableNamer{
 		safeNsName: safeNsName,
+		mapNames:   mapNames,
 	}
 }
 
@@ -112,6 +146,14 @@ func (namer *variableNamer) GetNameForSplitClientVariable(index int) string {
 	return fmt.Sprintf("$vs_%s_splits_%d", namer.safeNsName, index)
 }
 
+func (namer *variableNamer) GetNameForSplitClientStickyKeyVariable(index int) string {
+	return fmt.Sprintf("$vs_%s_splits_%d_sticky_key", namer.safeNsName, index)
+}
+
+func (namer *variableNamer) GetNameForSplitClientStickyCookieVariable(index int) string {
+	return fmt.Sprintf("$vs_%s_splits_%d_sticky_cookie", namer.safeNsName, index)
+}
+
 func (namer *variableNamer) GetNameForVariableForMatchesRouteMap(matchesIndex int, matchIndex int, conditionIndex int) string {
 	return fmt.Sprintf("$vs_%s_matches_%d_match_%d_cond_%d", namer.safeNsName, matchesIndex, matchIndex, conditionIndex)
 }
@@ -120,6 +162,24 @@ func (namer *variableNamer) GetNameForVariableForMatchesRouteMainMap(matchesInde
 	return fmt.Sprintf("$vs_%s_matches_%d", namer.safeNsName, matchesIndex)
 }
 
+func (namer *variableNamer) GetNameForMirrorVariable(index int) string {
+	return fmt.Sprintf("$vs_%s_mirror_%d", namer.safeNsName, index)
+}
+
+func (namer *variableNamer) GetNameForMapVariable(mapName string) string {
+	return fmt.Sprintf("$vs_%s_map_%s", namer.safeNsName, mapName)
+}
+
+// GetNameForVariable returns the NGINX variable for a variable referenced in the VirtualServer.
+// The variables of the maps of the VirtualServer are replaced with the variables of the generated maps.
+func (namer *variableNamer) GetNameForVariable(variable string) string {
+	if mapName := strings.TrimPrefix(variable, "$"); namer.mapNames[mapName] {
+		return namer.GetNameForMapVariable(mapName)
+	}
+
+	return variable
+}
+
 func newHealthCheckWithDefaults(upstream conf_v1.Upstream, upstreamName string, cfgParams *ConfigParams) *version2.HealthCheck {
 	return &version2.HealthCheck{
 		Name:                upstreamName,
@@ -145,6 +205,13 @@ type virtualServerConfigurator struct {
 	isTLSPassthrough     bool
 	warnings             Warnings
 	spiffeCerts          bool
+	openTelemetry        bool
+	brotli               bool
+	oidc                 bool
+	sourceComments       bool
+	dynamicSSLCerts      bool
+	// policyReferences counts the references to the policies in the routes of the VirtualServer being generated.
+	policyReferences map[string]int
 }
 
 func (vsc *virtualServerConfigurator) addWarningf(obj runtime.Object, msgFmt string, args ...interface{}) {
@@ -164,13 +231,56 @@ func newVirtualServerConfigurator(cfgParams *ConfigParams, isPlus bool, isResolv
 		isTLSPassthrough:     staticParams.TLSPassthrough,
 		warnings:             make(map[runtime.Object][]string),
 		spiffeCerts:          staticParams.SpiffeCerts,
+		openTelemetry:        staticParams.EnableOpenTelemetry,
+		brotli:               staticParams.EnableBrotli,
+		oidc:                 staticParams.EnableOIDC,
+		sourceComments:       staticParams.ConfigSourceComments,
+		dynamicSSLCerts:      staticParams.DynamicSSLCertsDirectory != "",
 	}
 }
 
-func (vsc *virtualServerConfigurator) generateEndpointsForUpstream(owner runtime.Object, namespace string, upstream conf_v1.Upstream, virtualServerEx *VirtualServerEx) []string {
+// generateBackupEndpointsForUpstream generates the endpoints of the backup service of the upstream.
+func generateBackupEndpointsForUpstream(namespace string, upstream conf_v1.Upstream, virtualServerEx *VirtualServerEx) []string {
+	if upstream.Backup == "" {
+		return nil
+	}
+
+	return virtualServerEx.Endpoints[GenerateEndpointsKey(namespace, upstream.Backup, nil, upstream.BackupPort)]
+}
+
+// generateServicesEndpointsForUpstream generates the combined endpoints of the service of the upstream and
+// its additional services. An endpoint shared by several services is included once.
+func generateServicesEndpointsForUpstream(namespace string, upstream conf_v1.Upstream, virtualServerEx *VirtualServerEx) []string {
 	endpointsKey := GenerateEndpointsKey(namespace, upstream.Service, upstream.Subselector, upstream.Port)
-	externalNameSvcKey := GenerateExternalNameSvcKey(namespace, upstream.Service)
 	endpoints := virtualServerEx.Endpoints[endpointsKey]
+
+	if len(upstream.Services) == 0 {
+		return endpoints
+	}
+
+	var result []string
+	seen := make(map[string]bool)
+
+	addEndpoints := func(endps []string) {
+		for _, e := range endps {
+			if !seen[e] {
+				seen[e] = true
+				result = append(result, e)
+			}
+		}
+	}
+
+	addEndpoints(endpoints)
+	for _, s := range upstream.Services {
+		addEndpoints(virtualServerEx.Endpoints[GenerateEndpointsKey(namespace, s, nil, upstream.Port)])
+	}
+
+	return result
+}
+
+func (vsc *virtualServerConfigurator) generateEndpointsForUpstream(owner runtime.Object, namespace string, upstream conf_v1.Upstream, virtualServerEx *VirtualServerEx) []string {
+	externalNameSvcKey := GenerateExternalNameSvcKey(namespace, upstream.Service)
+	endpoints := generateServicesEndpointsForUpstream(namespace, upstream, virtualServerEx)
 	if !vsc.isPlus && len(endpoints) == 0 {
 		return []string{nginx502Server}
 	}
@@ -182,13 +292,24 @@ func (vsc *virtualServerConfigurator) generateEndpointsForUpstream(owner runtime
 		endpoints = []string{}
 	}
 
+	if upstream.SRV != nil && !vsc.isResolverConfigured {
+		msgFmt := "DNS SRV service discovery in upstream %v will be ignored. To use DNS SRV service discovery, a resolver must be configured in the ConfigMap"
+		vsc.addWarningf(owner, msgFmt, upstream.Name)
+	}
+
 	return endpoints
 }
 
 // GenerateVirtualServerConfig generates a full configuration for a VirtualServer
-func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(virtualServerEx *VirtualServerEx, tlsPemFileName string) (version2.VirtualServerConfig, Warnings) {
+func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(virtualServerEx *VirtualServerEx, tlsPemFileName string, sessionTicketKeyFileName string,
+	jwtKeyFileNames map[string]string) (version2.VirtualServerConfig, Warnings) {
 	vsc.clearWarnings()
+	vsc.policyReferences = countPolicyReferences(virtualServerEx)
 	ssl := generateSSLConfig(virtualServerEx.VirtualServer.Spec.TLS, tlsPemFileName, vsc.cfgParams)
+	if ssl == nil {
+		ssl = vsc.generateDynamicSSLConfig(virtualServerEx.VirtualServer)
+	}
+	vsc.addTLSSessionToSSLConfig(ssl, virtualServerEx.VirtualServer, sessionTicketKeyFileName)
 	tlsRedirectConfig := generateTLSRedirectConfig(virtualServerEx.VirtualServer.Spec.TLS)
 
 	// crUpstreams maps an UpstreamName to its conf_v1.Upstream as they are generated
@@ -199,6 +320,7 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(virtualServerE
 	var upstreams []version2.Upstream
 	var statusMatches []version2.StatusMatch
 	var healthChecks []version2.HealthCheck
+	var cacheZones []version2.CacheZone
 
 	// generate upstreams for VirtualServer
 	for _, u := range virtualServerEx.VirtualServer.Spec.Upstreams {
@@ -208,12 +330,17 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(virtualServerE
 
 		// isExternalNameSvc is always false for OSS
 		_, isExternalNameSvc := virtualServerEx.ExternalNameSvcs[GenerateExternalNameSvcKey(upstreamNamespace, u.Service)]
-		ups := vsc.generateUpstream(virtualServerEx.VirtualServer, upstreamName, u, isExternalNameSvc, endpoints)
+		backupEndpoints := generateBackupEndpointsForUpstream(upstreamNamespace, u, virtualServerEx)
+		ups := vsc.generateUpstream(virtualServerEx.VirtualServer, upstreamName, u, isExternalNameSvc, endpoints, backupEndpoints)
 		upstreams = append(upstreams, ups)
 
 		u.TLS.Enable = isTLSEnabled(u, vsc.spiffeCerts)
 		crUpstreams[upstreamName] = u
 
+		if u.Cache != nil {
+			cacheZones = append(cacheZones, generateCacheZone(upstreamName, u.Cache))
+		}
+
 		if hc := generateHealthCheck(u, upstreamName, vsc.cfgParams); hc != nil {
 			healthChecks = append(healthChecks, *hc)
 			if u.HealthCheck.StatusMatch != "" {
@@ -231,11 +358,16 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(virtualServerE
 
 			// isExternalNameSvc is always false for OSS
 			_, isExternalNameSvc := virtualServerEx.ExternalNameSvcs[GenerateExternalNameSvcKey(upstreamNamespace, u.Service)]
-			ups := vsc.generateUpstream(vsr, upstreamName, u, isExternalNameSvc, endpoints)
+			backupEndpoints := generateBackupEndpointsForUpstream(upstreamNamespace, u, virtualServerEx)
+			ups := vsc.generateUpstream(vsr, upstreamName, u, isExternalNameSvc, endpoints, backupEndpoints)
 			upstreams = append(upstreams, ups)
 			u.TLS.Enable = isTLSEnabled(u, vsc.spiffeCerts)
 			crUpstreams[upstreamName] = u
 
+			if u.Cache != nil {
+				cacheZones = append(cacheZones, generateCacheZone(upstreamName, u.Cache))
+			}
+
 			if hc := generateHealthCheck(u, upstreamName, vsc.cfgParams); hc != nil {
 				healthChecks = append(healthChecks, *hc)
 				if u.HealthCheck.StatusMatch != "" {
@@ -252,14 +384,53 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(virtualServerE
 	var errorPageLocations []version2.ErrorPageLocation
 	var vsrErrorPagesFromVs = make(map[string][]conf_v1.ErrorPage)
 	var vsrErrorPagesRouteIndex = make(map[string]int)
+	// vsrRouteSplitPrefixes maps a VirtualServerRoute referenced in route splits to the prefix of the paths of its locations
+	var vsrRouteSplitPrefixes = make(map[string]string)
+	var limitReqZones []version2.LimitReqZone
+	var limitConnZones []version2.LimitConnZone
+	var mirrorSplitClients []version2.SplitClient
 	matchesRoutes := 0
+	mirrors := 0
 
 	variableNamer := newVariableNamer(virtualServerEx.VirtualServer)
+	maps = append(maps, generateMaps(virtualServerEx.VirtualServer.Spec.Maps, variableNamer)...)
+
+	upstreamMapNames := GetUpstreamMapNames(virtualServerEx.VirtualServer.Spec.Routes)
+	maps = append(maps, generateUpstreamMaps(virtualServerEx.VirtualServer.Spec.Maps, upstreamMapNames, variableNamer, virtualServerUpstreamNamer)...)
+	for _, m := range virtualServerEx.VirtualServer.Spec.Maps {
+		if upstreamMapNames[m.Name] {
+			// the locations that pass requests to the selected upstream are configured with the default upstream of the map
+			crUpstreams[virtualServerUpstreamNamer.GetNameForUpstreamVariable(m.Name)] = crUpstreams[virtualServerUpstreamNamer.GetNameForUpstream(m.Default)]
+		}
+	}
 
 	// generates config for VirtualServer routes
 	for _, r := range virtualServerEx.VirtualServer.Spec.Routes {
+		r.Splits = applySplitsCutover(r.Splits, r.Cutover)
+		source := generateSourceComment(vsc.sourceComments, "VirtualServer", virtualServerEx.VirtualServer.Namespace, virtualServerEx.VirtualServer.Name, "route", r.Path)
 		errorPageIndex := len(errorPageLocations)
 		errorPageLocations = append(errorPageLocations, generateErrorPageLocations(errorPageIndex, r.ErrorPages)...)
+		if isRouteSplits(r.Splits) {
+			scIndex := len(splitClients)
+			cfg := generateRouteSplitsConfig(r, variableNamer, scIndex)
+
+			addSourceToLocations(cfg.Locations, source)
+			splitClients = append(splitClients, cfg.SplitClients...)
+			locations = append(locations, cfg.Locations...)
+			cfg.InternalRedirectLocation.Source = source
+			internalRedirectLocations = append(internalRedirectLocations, cfg.InternalRedirectLocation)
+
+			for i, split := range r.Splits {
+				name := getVirtualServerRouteKey(split.Route, virtualServerEx.VirtualServer.Namespace)
+				vsrRouteSplitPrefixes[name] = generateRouteSplitPrefix(scIndex, i)
+				if len(r.ErrorPages) > 0 {
+					vsrErrorPagesFromVs[name] = r.ErrorPages
+					vsrErrorPagesRouteIndex[name] = errorPageIndex
+				}
+			}
+			continue
+		}
+
 		// ignore routes that reference VirtualServerRoute
 		if r.Route != "" {
 			// store route error pages and route index for the referenced VirtualServerRoute in case they don't define their own
@@ -274,92 +445,222 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(virtualServerE
 			continue
 		}
 
+		vsNamespace := virtualServerEx.VirtualServer.Namespace
+		policiesCfg := vsc.generatePolicies(virtualServerEx.VirtualServer, vsNamespace, virtualServerEx.VirtualServer, r.Policies, virtualServerEx.Policies, jwtKeyFileNames)
+		limitReqZones = append(limitReqZones, policiesCfg.LimitReqZones...)
+		limitConnZones = append(limitConnZones, policiesCfg.LimitConnZones...)
+		routeLocationsStart := len(locations)
+
 		if len(r.Matches) > 0 {
 			cfg := generateMatchesConfig(r, virtualServerUpstreamNamer, crUpstreams, variableNamer, matchesRoutes, len(splitClients), vsc.cfgParams, r.ErrorPages, errorPageIndex)
+			addSubFilterToLocations(cfg.Locations, r.SubFilter)
+			addRequestBufferingToLocations(cfg.Locations, r.RequestBuffering)
+			addLocationSnippetsToLocations(cfg.Locations, vsc.cfgParams.LocationSnippets, r.LocationSnippets)
+			addPoliciesCfgToLocations(cfg.Locations, policiesCfg)
+			addSourceToLocations(cfg.Locations, source)
 
 			maps = append(maps, cfg.Maps...)
 			locations = append(locations, cfg.Locations...)
+			cfg.InternalRedirectLocation.AllowedMethods = generateAllowedMethods(r.AllowedMethods)
+			cfg.InternalRedirectLocation.Source = source
 			internalRedirectLocations = append(internalRedirectLocations, cfg.InternalRedirectLocation)
 			splitClients = append(splitClients, cfg.SplitClients...)
 
 			matchesRoutes++
 		} else if len(r.Splits) > 0 {
 			cfg := generateDefaultSplitsConfig(r, virtualServerUpstreamNamer, crUpstreams, variableNamer, len(splitClients), vsc.cfgParams, r.ErrorPages, errorPageIndex, r.Path)
+			addSubFilterToLocations(cfg.Locations, r.SubFilter)
+			addRequestBufferingToLocations(cfg.Locations, r.RequestBuffering)
+			addLocationSnippetsToLocations(cfg.Locations, vsc.cfgParams.LocationSnippets, r.LocationSnippets)
+			addPoliciesCfgToLocations(cfg.Locations, policiesCfg)
+			addSourceToLocations(cfg.Locations, source)
 
+			maps = append(maps, cfg.Maps...)
 			splitClients = append(splitClients, cfg.SplitClients...)
 			locations = append(locations, cfg.Locations...)
+			cfg.InternalRedirectLocation.AllowedMethods = generateAllowedMethods(r.AllowedMethods)
+			cfg.InternalRedirectLocation.Source = source
 			internalRedirectLocations = append(internalRedirectLocations, cfg.InternalRedirectLocation)
 		} else {
 			upstreamName := virtualServerUpstreamNamer.GetNameForUpstreamFromAction(r.Action)
 			upstream := crUpstreams[upstreamName]
 			proxySSLName := generateProxySSLName(upstream.Service, virtualServerEx.VirtualServer.Namespace)
 			loc := generateLocation(r.Path, upstreamName, upstream, r.Action, vsc.cfgParams, r.ErrorPages, false, errorPageIndex, proxySSLName, r.Path)
+			loc.AllowedMethods = generateAllowedMethods(r.AllowedMethods)
+			loc.SubFilter = generateSubFilter(r.SubFilter)
+			loc.ProxyRequestBuffering = generateRequestBuffering(r.RequestBuffering)
+			loc.Snippets = generateSnippets(vsc.cfgParams.LocationSnippets, r.LocationSnippets)
+			addPoliciesCfgToLocation(&loc, policiesCfg)
+			loc.Source = source
 			locations = append(locations, loc)
 		}
+
+		if mirrorCfg := generateMirrorConfig(r.Mirror, virtualServerUpstreamNamer, crUpstreams, variableNamer, mirrors, vsc.cfgParams); mirrorCfg != nil {
+			addMirrorToLocations(locations[routeLocationsStart:], mirrorCfg.Location.Path)
+			mirrorCfg.Location.Source = source
+			locations = append(locations, mirrorCfg.Location)
+			if mirrorCfg.SplitClient != nil {
+				mirrorSplitClients = append(mirrorSplitClients, *mirrorCfg.SplitClient)
+			}
+			mirrors++
+		}
 	}
 
 	// generate config for subroutes of each VirtualServerRoute
 	for _, vsr := range virtualServerEx.VirtualServerRoutes {
 		upstreamNamer := newUpstreamNamerForVirtualServerRoute(virtualServerEx.VirtualServer, vsr)
+		vsrNamespaceName := fmt.Sprintf("%v/%v", vsr.Namespace, vsr.Name)
+		routeSplitPrefix, isRouteSplit := vsrRouteSplitPrefixes[vsrNamespaceName]
 		for _, r := range vsr.Spec.Subroutes {
+			r.Splits = applySplitsCutover(r.Splits, r.Cutover)
+			source := generateSourceComment(vsc.sourceComments, "VirtualServerRoute", vsr.Namespace, vsr.Name, "subroute", r.Path)
 			errorPageIndex := len(errorPageLocations)
 			errorPageLocations = append(errorPageLocations, generateErrorPageLocations(errorPageIndex, r.ErrorPages)...)
 			errorPages := r.ErrorPages
 			// use referenced VirtualServer error pages if the route does not define any
 			if r.ErrorPages == nil {
-				vsrNamespaceName := fmt.Sprintf("%v/%v", vsr.Namespace, vsr.Name)
 				if vsErrorPages, ok := vsrErrorPagesFromVs[vsrNamespaceName]; ok {
 					errorPages = vsErrorPages
 					errorPageIndex = vsrErrorPagesRouteIndex[vsrNamespaceName]
 				}
 			}
 
+			// the subroutes of a VirtualServerRoute referenced in route splits are only reachable through the split
+			path := r.Path
+			if isRouteSplit {
+				path = generateRouteSplitPath(routeSplitPrefix, r.Path)
+			}
+
+			policiesCfg := vsc.generatePolicies(vsr, vsr.Namespace, virtualServerEx.VirtualServer, r.Policies, virtualServerEx.Policies, jwtKeyFileNames)
+			limitReqZones = append(limitReqZones, policiesCfg.LimitReqZones...)
+			limitConnZones = append(limitConnZones, policiesCfg.LimitConnZones...)
+			routeLocationsStart := len(locations)
+
 			if len(r.Matches) > 0 {
 				cfg := generateMatchesConfig(r, upstreamNamer, crUpstreams, variableNamer, matchesRoutes, len(splitClients), vsc.cfgParams, errorPages, errorPageIndex)
+				addSubFilterToLocations(cfg.Locations, r.SubFilter)
+				addRequestBufferingToLocations(cfg.Locations, r.RequestBuffering)
+				addLocationSnippetsToLocations(cfg.Locations, vsc.cfgParams.LocationSnippets, r.LocationSnippets)
+				addPoliciesCfgToLocations(cfg.Locations, policiesCfg)
+				addSourceToLocations(cfg.Locations, source)
 
 				maps = append(maps, cfg.Maps...)
 				locations = append(locations, cfg.Locations...)
+				cfg.InternalRedirectLocation.Path = path
+				cfg.InternalRedirectLocation.Internal = isRouteSplit
+				cfg.InternalRedirectLocation.AllowedMethods = generateAllowedMethods(r.AllowedMethods)
+				cfg.InternalRedirectLocation.Source = source
 				internalRedirectLocations = append(internalRedirectLocations, cfg.InternalRedirectLocation)
 				splitClients = append(splitClients, cfg.SplitClients...)
 
 				matchesRoutes++
 			} else if len(r.Splits) > 0 {
 				cfg := generateDefaultSplitsConfig(r, upstreamNamer, crUpstreams, variableNamer, len(splitClients), vsc.cfgParams, errorPages, errorPageIndex, r.Path)
+				addSubFilterToLocations(cfg.Locations, r.SubFilter)
+				addRequestBufferingToLocations(cfg.Locations, r.RequestBuffering)
+				addLocationSnippetsToLocations(cfg.Locations, vsc.cfgParams.LocationSnippets, r.LocationSnippets)
+				addPoliciesCfgToLocations(cfg.Locations, policiesCfg)
+				addSourceToLocations(cfg.Locations, source)
 
+				maps = append(maps, cfg.Maps...)
 				splitClients = append(splitClients, cfg.SplitClients...)
 				locations = append(locations, cfg.Locations...)
+				cfg.InternalRedirectLocation.Path = path
+				cfg.InternalRedirectLocation.Internal = isRouteSplit
+				cfg.InternalRedirectLocation.AllowedMethods = generateAllowedMethods(r.AllowedMethods)
+				cfg.InternalRedirectLocation.Source = source
 				internalRedirectLocations = append(internalRedirectLocations, cfg.InternalRedirectLocation)
 			} else {
 				upstreamName := upstreamNamer.GetNameForUpstreamFromAction(r.Action)
 				upstream := crUpstreams[upstreamName]
 				proxySSLName := generateProxySSLName(upstream.Service, vsr.Namespace)
-				loc := generateLocation(r.Path, upstreamName, upstream, r.Action, vsc.cfgParams, errorPages, false, errorPageIndex, proxySSLName, r.Path)
+				loc := generateLocation(path, upstreamName, upstream, r.Action, vsc.cfgParams, errorPages, isRouteSplit, errorPageIndex, proxySSLName, r.Path)
+				loc.AllowedMethods = generateAllowedMethods(r.AllowedMethods)
+				loc.SubFilter = generateSubFilter(r.SubFilter)
+				loc.ProxyRequestBuffering = generateRequestBuffering(r.RequestBuffering)
+				loc.Snippets = generateSnippets(vsc.cfgParams.LocationSnippets, r.LocationSnippets)
+				addPoliciesCfgToLocation(&loc, policiesCfg)
+				loc.Source = source
 				locations = append(locations, loc)
 			}
+
+			if mirrorCfg := generateMirrorConfig(r.Mirror, upstreamNamer, crUpstreams, variableNamer, mirrors, vsc.cfgParams); mirrorCfg != nil {
+				addMirrorToLocations(locations[routeLocationsStart:], mirrorCfg.Location.Path)
+				mirrorCfg.Location.Source = source
+				locations = append(locations, mirrorCfg.Location)
+				if mirrorCfg.SplitClient != nil {
+					mirrorSplitClients = append(mirrorSplitClients, *mirrorCfg.SplitClient)
+				}
+				mirrors++
+			}
 		}
 	}
 
+	splitClients = append(splitClients, mirrorSplitClients...)
+
+	var logFormats []version2.LogFormat
+	accessLogFormat := ""
+	if logFormat := generateLogFormat(virtualServerEx.VirtualServer, vsc.cfgParams); logFormat != nil {
+		logFormats = append(logFormats, *logFormat)
+		accessLogFormat = logFormat.Name
+	}
+
+	oidcCfg, oidcValid := vsc.generateOIDC(virtualServerEx)
+	locations = vsc.addOIDCToLocations(virtualServerEx.VirtualServer, locations, oidcCfg, oidcValid)
+
+	limitConnZone, limitConn, limitConnStatus := generateConnectionLimit(virtualServerEx, vsc.getLimitConnZoneSize())
+	if limitConnZone != nil {
+		limitConnZones = append(limitConnZones, *limitConnZone)
+		addConnectionLimitToLocations(locations, *limitConn)
+	}
+
+	if errorPage, loc := generateErrorBackend(virtualServerEx.VirtualServer, virtualServerUpstreamNamer, crUpstreams, vsc.cfgParams); loc != nil {
+		addErrorBackendToLocations(locations, *errorPage)
+		locations = append(locations, *loc)
+	}
+
 	vscfg := version2.VirtualServerConfig{
-		Upstreams:     upstreams,
-		SplitClients:  splitClients,
-		Maps:          maps,
-		StatusMatches: statusMatches,
+		Upstreams:      upstreams,
+		SplitClients:   splitClients,
+		Maps:           maps,
+		StatusMatches:  statusMatches,
+		LogFormats:     logFormats,
+		LimitReqZones:  removeDuplicateLimitReqZones(limitReqZones),
+		LimitConnZones: removeDuplicateLimitConnZones(limitConnZones),
+		CacheZones:     cacheZones,
 		Server: version2.Server{
 			ServerName:                virtualServerEx.VirtualServer.Spec.Host,
+			Source:                    generateSourceComment(vsc.sourceComments, "VirtualServer", virtualServerEx.VirtualServer.Namespace, virtualServerEx.VirtualServer.Name, "", ""),
 			StatusZone:                virtualServerEx.VirtualServer.Spec.Host,
 			ProxyProtocol:             vsc.cfgParams.ProxyProtocol,
 			SSL:                       ssl,
-			ServerTokens:              vsc.cfgParams.ServerTokens,
+			ServerTokens:              generateString(virtualServerEx.VirtualServer.Spec.ServerTokens, vsc.cfgParams.ServerTokens),
 			SetRealIPFrom:             vsc.cfgParams.SetRealIPFrom,
 			RealIPHeader:              vsc.cfgParams.RealIPHeader,
 			RealIPRecursive:           vsc.cfgParams.RealIPRecursive,
-			Snippets:                  vsc.cfgParams.ServerSnippets,
+			ForwardedHeadersPolicy:    vsc.cfgParams.ForwardedHeadersPolicy,
+			Snippets:                  generateSnippets(vsc.cfgParams.ServerSnippets, virtualServerEx.VirtualServer.Spec.ServerSnippets),
 			InternalRedirectLocations: internalRedirectLocations,
 			Locations:                 locations,
 			HealthChecks:              healthChecks,
 			TLSRedirect:               tlsRedirectConfig,
 			ErrorPageLocations:        errorPageLocations,
 			TLSPassthrough:            vsc.isTLSPassthrough,
+			RequestIDHeader:           generateRequestIDHeader(virtualServerEx.VirtualServer.Spec.RequestID),
+			AccessLogFormat:           accessLogFormat,
+			AccessLogCondition:        generateAccessLogCondition(vsc.cfgParams.MainAccessLogSampleRate, vsc.cfgParams.MainAccessLogNon2xxOnly),
+			OpenTelemetryTrace:        vsc.generateOpenTelemetryTrace(virtualServerEx.VirtualServer),
+			ClientBodyBufferSize:      generateClientBodyBufferSize(virtualServerEx.VirtualServer.Spec.ClientBody),
+			ClientBodyTempPath:        generateClientBodyTempPath(virtualServerEx.VirtualServer.Spec.ClientBody),
+			ClientBodyTimeout:         generateClientBodyTimeout(virtualServerEx.VirtualServer.Spec.ClientBody),
+			ClientHeaderBufferSize:    generateClientHeaderBufferSize(virtualServerEx.VirtualServer.Spec.ClientHeaders),
+			ClientHeaderTimeout:       generateClientHeaderTimeout(virtualServerEx.VirtualServer.Spec.ClientHeaders),
+			LargeClientHeaderBuffers:  generateLargeClientHeaderBuffers(virtualServerEx.VirtualServer.Spec.ClientHeaders),
+			RequestHeadersTooLarge:    generateRequestHeadersTooLarge(virtualServerEx.VirtualServer.Spec.ClientHeaders, vsc.cfgParams),
+			Compression:               vsc.generateCompression(virtualServerEx.VirtualServer),
+			OIDC:                      oidcCfg,
+			LimitConn:                 limitConn,
+			LimitConnStatus:           limitConnStatus,
 		},
 		SpiffeCerts: vsc.spiffeCerts,
 	}
@@ -367,22 +668,343 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(virtualServerE
 	return vscfg, vsc.warnings
 }
 
-func (vsc *virtualServerConfigurator) generateUpstream(owner runtime.Object, upstreamName string, upstream conf_v1.Upstream, isExternalNameSvc bool, endpoints []string) version2.Upstream {
+// generateOpenTelemetryTrace generates the value of the otel_trace directive for the VirtualServer.
+// An empty string is returned if the VirtualServer doesn't override the tracing configured in the ConfigMap.
+func (vsc *virtualServerConfigurator) generateOpenTelemetryTrace(vs *conf_v1.VirtualServer) string {
+	openTelemetry := vs.Spec.OpenTelemetry
+	if openTelemetry == nil {
+		return ""
+	}
+
+	if !vsc.openTelemetry || vsc.cfgParams.MainOpenTelemetryExporterEndpoint == "" {
+		vsc.addWarningf(vs, "OpenTelemetry will be ignored. To use OpenTelemetry tracing, the OpenTelemetry module must be enabled with the -enable-opentelemetry command-line argument and 'opentelemetry-exporter-endpoint' must be configured in the ConfigMap")
+		return ""
+	}
+
+	if !openTelemetry.Enable {
+		return "off"
+	}
+
+	return generateOpenTelemetryTrace(vsc.cfgParams.MainOpenTelemetrySamplerRatio)
+}
+
+func (vsc *virtualServerConfigurator) generateCompression(vs *conf_v1.VirtualServer) *version2.Compression {
+	compression := vs.Spec.Compression
+	if compression == nil {
+		return nil
+	}
+
+	if compression.Type == "brotli" && !vsc.brotli {
+		vsc.addWarningf(vs, "Compression will be ignored. To use brotli compression, the brotli module must be enabled with the -enable-brotli command-line argument")
+		return nil
+	}
+
+	level := ""
+	if compression.Level != nil {
+		level = strconv.Itoa(*compression.Level)
+	}
+
+	return &version2.Compression{
+		Module:    compression.Type,
+		Types:     strings.Join(compression.Types, " "),
+		MinLength: compression.MinLength,
+		Level:     level,
+	}
+}
+
+// generateOIDC generates the OIDC configuration of the VirtualServer. It returns false if the VirtualServer enables OIDC,
+// but the configuration can't be applied, so that the locations of the VirtualServer are never left unprotected.
+func (vsc *virtualServerConfigurator) generateOIDC(vsEx *VirtualServerEx) (*version2.OIDC, bool) {
+	vs := vsEx.VirtualServer
+	oidc := vs.Spec.OIDC
+	if oidc == nil {
+		return nil, true
+	}
+
+	if !vsc.oidc {
+		vsc.addWarningf(vs, "OIDC can't be applied. To use OIDC, it must be enabled with the -enable-oidc command-line argument")
+		return nil, false
+	}
+
+	if vsEx.OIDCSecret == nil {
+		vsc.addWarningf(vs, "OIDC references an invalid or non-existing secret %s", GetSecretKeyForReference(vs.Namespace, oidc.ClientSecret))
+		return nil, false
+	}
+
+	if !vsc.isResolverConfigured {
+		vsc.addWarningf(vs, "OIDC requires a resolver to resolve the endpoints of the identity provider. Configure the resolver with the resolver-addresses ConfigMap key")
+	}
+
+	return &version2.OIDC{
+		AuthEndpoint:  oidc.AuthEndpoint,
+		TokenEndpoint: oidc.TokenEndpoint,
+		JwksURI:       oidc.JWKSURI,
+		ClientID:      oidc.ClientID,
+		ClientSecret:  string(vsEx.OIDCSecret.Data[OIDCClientSecretKey]),
+		Scope:         generateString(oidc.Scope, defaultOIDCScope),
+		RedirectURI:   generateString(oidc.RedirectURI, defaultOIDCRedirectURI),
+	}, true
+}
+
+// oidcFailureModeOpen is the failure mode of OIDC that passes the requests to the backends without authentication
+// when the identity provider is unavailable. By default, OIDC fails closed and the requests are rejected.
+const oidcFailureModeOpen = "open"
+
+// oidcUnavailableLocation is the location that rejects the requests when the identity provider is unavailable.
+// The location is defined in oidc/oidc.conf.
+const oidcUnavailableLocation = "@oidc_unavailable"
+
+// addOIDCToLocations enables the OIDC authentication in the locations of the VirtualServer and returns the locations
+// with the fallback locations of the open failure mode. If the OIDC configuration can't be applied, the locations return 500.
+// OIDC replaces the JWT policies of the routes, because both rely on auth_jwt.
+//
+// If the keys of the identity provider can't be fetched, auth_jwt fails with 500 and the request is redirected
+// to the unavailable location, which returns 503, or, for the open failure mode, to a copy of the location without OIDC.
+func (vsc *virtualServerConfigurator) addOIDCToLocations(vs *conf_v1.VirtualServer, locations []version2.Location,
+	oidcCfg *version2.OIDC, oidcValid bool) []version2.Location {
+	if !oidcValid {
+		for i := range locations {
+			locations[i].PoliciesErrorReturn = &version2.Return{Code: 500}
+		}
+		return locations
+	}
+
+	if oidcCfg == nil {
+		return locations
+	}
+
+	failOpen := vs.Spec.OIDC.FailureMode == oidcFailureModeOpen
+
+	var failOpenLocations []version2.Location
+	jwtIgnored := false
+	for i := range locations {
+		if locations[i].JWTAuth != nil {
+			locations[i].JWTAuth = nil
+			jwtIgnored = true
+		}
+
+		// a location that rejects the requests because of its policies is never unprotected
+		if locations[i].PoliciesErrorReturn == nil {
+			if failOpen {
+				failOpenLocation := locations[i]
+				failOpenLocation.Path = fmt.Sprintf("@oidc_fail_open_%d", len(failOpenLocations))
+				failOpenLocation.Internal = false
+				failOpenLocations = append(failOpenLocations, failOpenLocation)
+
+				locations[i].OIDCErrorLocation = failOpenLocation.Path
+			} else {
+				locations[i].OIDCErrorLocation = oidcUnavailableLocation
+			}
+		}
+
+		locations[i].OIDC = true
+	}
+
+	if jwtIgnored {
+		vsc.addWarningf(vs, "The jwt policies of the routes are ignored, because OIDC is enabled for the VirtualServer")
+	}
+
+	return append(locations, failOpenLocations...)
+}
+
+func generateClientBodyBufferSize(clientBody *conf_v1.ClientBody) string {
+	if clientBody == nil {
+		return ""
+	}
+
+	return clientBody.BufferSize
+}
+
+func generateClientBodyTempPath(clientBody *conf_v1.ClientBody) string {
+	if clientBody == nil {
+		return ""
+	}
+
+	return clientBody.TempPath
+}
+
+func generateClientBodyTimeout(clientBody *conf_v1.ClientBody) string {
+	if clientBody == nil {
+		return ""
+	}
+
+	return clientBody.Timeout
+}
+
+func generateClientHeaderBufferSize(clientHeaders *conf_v1.ClientHeaders) string {
+	if clientHeaders == nil {
+		return ""
+	}
+
+	return clientHeaders.BufferSize
+}
+
+func generateClientHeaderTimeout(clientHeaders *conf_v1.ClientHeaders) string {
+	if clientHeaders == nil {
+		return ""
+	}
+
+	return clientHeaders.Timeout
+}
+
+func generateLargeClientHeaderBuffers(clientHeaders *conf_v1.ClientHeaders) string {
+	if clientHeaders == nil {
+		return ""
+	}
+
+	return generateBuffers(clientHeaders.LargeBuffers, "")
+}
+
+// generateRequestHeadersTooLarge checks if the requests with headers that don't fit the configured buffers get
+// the 431 response instead of the default 400 response of NGINX.
+func generateRequestHeadersTooLarge(clientHeaders *conf_v1.ClientHeaders, cfgParams *ConfigParams) bool {
+	if cfgParams.MainClientHeaderBufferSize != "" || cfgParams.MainLargeClientHeaderBuffers != "" {
+		return true
+	}
+
+	return clientHeaders != nil && (clientHeaders.BufferSize != "" || clientHeaders.LargeBuffers != nil)
+}
+
+func generateRequestIDHeader(requestID *conf_v1.RequestID) string {
+	if requestID == nil || !requestID.Enable {
+		return ""
+	}
+
+	return generateString(requestID.Header, defaultRequestIDHeader)
+}
+
+// generateRequestIDLogFormat generates a log format for the VirtualServer that extends the main log format with the request ID.
+func generateRequestIDLogFormat(virtualServer *conf_v1.VirtualServer, cfgParams *ConfigParams) *version2.LogFormat {
+	requestID := virtualServer.Spec.RequestID
+	if requestID == nil || !requestID.Enable || cfgParams.MainAccessLogOff {
+		return nil
+	}
+
+	logFormat := generateExtendedMainLogFormat(fmt.Sprintf("%s_request_id", getFileNameForVirtualServer(virtualServer)), cfgParams)
+	logFormat.Format = append(logFormat.Format, `"$request_id"`)
+
+	return logFormat
+}
+
+// generateLogFormat generates a log format for the VirtualServer that extends the main log format with the request ID
+// and the custom fields of the access log. The custom fields follow the request ID.
+func generateLogFormat(virtualServer *conf_v1.VirtualServer, cfgParams *ConfigParams) *version2.LogFormat {
+	logFormat := generateRequestIDLogFormat(virtualServer, cfgParams)
+
+	accessLog := virtualServer.Spec.AccessLog
+	if accessLog == nil || len(accessLog.Fields) == 0 || cfgParams.MainAccessLogOff {
+		return logFormat
+	}
+
+	name := fmt.Sprintf("%s_custom", getFileNameForVirtualServer(virtualServer))
+	if logFormat == nil {
+		logFormat = generateExtendedMainLogFormat(name, cfgParams)
+	} else {
+		logFormat.Name = name
+	}
+
+	for _, f := range accessLog.Fields {
+		logFormat.Format = append(logFormat.Format, fmt.Sprintf(`%s="%s"`, f.Name, f.Variable))
+	}
+
+	return logFormat
+}
+
+// generateExtendedMainLogFormat generates a log format with the fields of the main log format, which the fields of
+// a VirtualServer can extend.
+func generateExtendedMainLogFormat(name string, cfgParams *ConfigParams) *version2.LogFormat {
+	logFormat := &version2.LogFormat{
+		Name: name,
+	}
+
+	if len(cfgParams.MainLogFormat) > 0 {
+		logFormat.Format = append(logFormat.Format, cfgParams.MainLogFormat...)
+		logFormat.Escaping = cfgParams.MainLogFormatEscaping
+	} else {
+		logFormat.Format = append(logFormat.Format, defaultMainLogFormat...)
+	}
+
+	return logFormat
+}
+
+// isEndpointDown checks whether the endpoint matches one of the addresses marked as down: either the IP and the port
+// of the endpoint or only its IP.
+func isEndpointDown(endpoint string, downAddresses []string) bool {
+	host, _, err := net.SplitHostPort(endpoint)
+	for _, d := range downAddresses {
+		if d == endpoint || (err == nil && d == host) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDownAddressInEndpoints(downAddress string, endpoints []string) bool {
+	for _, e := range endpoints {
+		if isEndpointDown(e, []string{downAddress}) {
+			return true
+		}
+	}
+	return false
+}
+
+func (vsc *virtualServerConfigurator) generateUpstream(owner runtime.Object, upstreamName string, upstream conf_v1.Upstream, isExternalNameSvc bool,
+	endpoints []string, backupEndpoints []string) version2.Upstream {
+	// NGINX requires an upstream to have primary servers, so the backup servers become
+	// the primary ones when the primary service has no endpoints.
+	if len(backupEndpoints) > 0 && (len(endpoints) == 0 || (len(endpoints) == 1 && endpoints[0] == nginx502Server)) {
+		endpoints = backupEndpoints
+		backupEndpoints = nil
+	}
+
 	var upsServers []version2.UpstreamServer
 	for _, e := range endpoints {
 		s := version2.UpstreamServer{
 			Address: e,
+			Down:    isEndpointDown(e, upstream.Down),
 		}
 
 		upsServers = append(upsServers, s)
 	}
+	for _, e := range backupEndpoints {
+		upsServers = append(upsServers, version2.UpstreamServer{
+			Address: e,
+			Backup:  true,
+			Down:    isEndpointDown(e, upstream.Down),
+		})
+	}
+
+	for _, d := range upstream.Down {
+		if !isDownAddressInEndpoints(d, endpoints) && !isDownAddressInEndpoints(d, backupEndpoints) {
+			vsc.addWarningf(owner, "The address %s marked as down in the upstream %s doesn't match any endpoint of the upstream", d, upstream.Name)
+		}
+	}
+
+	resolve := isExternalNameSvc
+	if vsc.isSRVDiscoveryEnabled(upstream) {
+		upsServers = []version2.UpstreamServer{
+			{
+				Address: upstream.SRV.Host,
+				Service: upstream.SRV.Service,
+			},
+		}
+		resolve = true
+	}
+
+	if upstream.ConnectionLimitPolicy != "" && generateLimitConn(upstream.ConnectionLimitPolicy, vsc.cfgParams.ConnectionLimitPolicies) == nil {
+		vsc.addWarningf(owner, "The connection limit policy %s referenced in the upstream %s doesn't exist in the ConfigMap and will be ignored",
+			upstream.ConnectionLimitPolicy, upstream.Name)
+	}
 
 	lbMethod := generateLBMethod(upstream.LBMethod, vsc.cfgParams.LBMethod)
+	if len(backupEndpoints) > 0 {
+		lbMethod = generateLBMethodForBackup(lbMethod)
+	}
 
 	ups := version2.Upstream{
 		Name:             upstreamName,
 		Servers:          upsServers,
-		Resolve:          isExternalNameSvc,
+		Resolve:          resolve,
 		LBMethod:         lbMethod,
 		Keepalive:        generateIntFromPointer(upstream.Keepalive, vsc.cfgParams.Keepalive),
 		MaxFails:         generateIntFromPointer(upstream.MaxFails, vsc.cfgParams.MaxFails),
@@ -395,11 +1017,35 @@ func (vsc *virtualServerConfigurator) generateUpstream(owner runtime.Object, ups
 		ups.SlowStart = vsc.generateSlowStartForPlus(owner, upstream, lbMethod)
 		ups.Queue = generateQueueForPlus(upstream.Queue, "60s")
 		ups.SessionCookie = generateSessionCookie(upstream.SessionCookie)
+		if resolve {
+			ups.Resolver = generateUpstreamResolver(upstream.ResolverValid, vsc.cfgParams)
+		}
 	}
 
 	return ups
 }
 
+// generateUpstreamResolver generates a resolver for an upstream with servers resolved via DNS, so that the
+// servers are re-resolved at the interval set in the upstream or the ConfigMap instead of the global resolver one.
+func generateUpstreamResolver(valid string, cfgParams *ConfigParams) *version2.UpstreamResolver {
+	valid = generateString(valid, cfgParams.ResolverUpstreamValid)
+	if valid == "" || len(cfgParams.ResolverAddresses) == 0 {
+		return nil
+	}
+
+	return &version2.UpstreamResolver{
+		Addresses: cfgParams.ResolverAddresses,
+		Valid:     valid,
+		IPV6:      cfgParams.ResolverIPV6,
+	}
+}
+
+// isSRVDiscoveryEnabled checks if NGINX must resolve the servers of the upstream via DNS SRV records
+// instead of using the endpoints of the service.
+func (vsc *virtualServerConfigurator) isSRVDiscoveryEnabled(upstream conf_v1.Upstream) bool {
+	return upstream.SRV != nil && vsc.isPlus && vsc.isResolverConfigured
+}
+
 func (vsc *virtualServerConfigurator) generateSlowStartForPlus(owner runtime.Object, upstream conf_v1.Upstream, lbMethod string) string {
 	if upstream.SlowStart == "" {
 		return ""
@@ -506,6 +1152,30 @@ func GenerateExternalNameSvcKey(namespace string, service string) string {
 	return fmt.Sprintf("%v/%v", namespace, service)
 }
 
+// incompatibleLBMethodsForBackup includes the prefixes of the load balancing methods that NGINX doesn't allow with backup servers.
+var incompatibleLBMethodsForBackup = []string{"hash", "ip_hash", "random"}
+
+// IsLBMethodCompatibleWithBackup returns true if NGINX allows backup servers with the load balancing method.
+func IsLBMethodCompatibleWithBackup(lbMethod string) bool {
+	for _, m := range incompatibleLBMethodsForBackup {
+		if strings.HasPrefix(lbMethod, m) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// generateLBMethodForBackup generates the load balancing method of an upstream with backup servers.
+// An incompatible method, which can only come from the ConfigMap, is replaced with least_conn.
+func generateLBMethodForBackup(lbMethod string) string {
+	if !IsLBMethodCompatibleWithBackup(lbMethod) {
+		return "least_conn"
+	}
+
+	return lbMethod
+}
+
 func generateLBMethod(method string, defaultMethod string) string {
 	if method == "" {
 		return defaultMethod
@@ -637,10 +1307,26 @@ func generateReturnBlock(text string, code int, defaultCode int) *version2.Retur
 	return returnBlock
 }
 
+// generateRedirectURL generates the URL of a redirect, which ends with the path or the query string of the request
+// if the redirect preserves them. The raw $request_uri and $args are used, because the decoded $uri can contain
+// characters that are not allowed in the Location header.
+func generateRedirectURL(redirect *conf_v1.ActionRedirect) string {
+	switch {
+	case redirect.PreservePath && redirect.PreserveQuery:
+		return strings.TrimSuffix(redirect.URL, "/") + "$request_uri"
+	case redirect.PreservePath:
+		return strings.TrimSuffix(redirect.URL, "/") + "$request_uri_path"
+	case redirect.PreserveQuery:
+		return redirect.URL + "$is_args$args"
+	}
+
+	return redirect.URL
+}
+
 func generateLocation(path string, upstreamName string, upstream conf_v1.Upstream, action *conf_v1.Action,
 	cfgParams *ConfigParams, errorPages []conf_v1.ErrorPage, internal bool, errPageIndex int, proxySSLName string, originalPath string) version2.Location {
 	if action.Redirect != nil {
-		returnBlock := generateReturnBlock(action.Redirect.URL, action.Redirect.Code, 301)
+		returnBlock := generateReturnBlock(generateRedirectURL(action.Redirect), action.Redirect.Code, 301)
 		return generateLocationForReturnBlock(path, cfgParams.LocationSnippets, returnBlock, "")
 	}
 
@@ -650,36 +1336,74 @@ func generateLocation(path string, upstreamName string, upstream conf_v1.Upstrea
 			defaultType = "text/plain"
 		}
 		returnBlock := generateReturnBlock(action.Return.Body, action.Return.Code, 200)
-		return generateLocationForReturnBlock(path, cfgParams.LocationSnippets, returnBlock, defaultType)
+		loc := generateLocationForReturnBlock(path, cfgParams.LocationSnippets, returnBlock, defaultType)
+		loc.AddHeaders = generateReturnHeaders(action.Return.Headers)
+		return loc
 	}
 
-	return generateLocationForProxying(path, upstreamName, upstream, cfgParams, errorPages, internal, errPageIndex, proxySSLName, action.Proxy, originalPath)
+	loc := generateLocationForProxying(path, upstreamName, upstream, cfgParams, errorPages, internal, errPageIndex, proxySSLName, action.Proxy, originalPath)
+	loc.ProxySetHeaders = append(loc.ProxySetHeaders, generateClientCertHeaders(action.ClientCertForwarding)...)
+
+	return loc
 }
 
-func generateProxySetHeaders(proxy *conf_v1.ActionProxy) []version2.Header {
-	if proxy == nil || proxy.RequestHeaders == nil {
+func generateClientCertHeaders(ccf *conf_v1.ClientCertForwarding) []version2.Header {
+	if ccf == nil {
 		return nil
 	}
 
 	var headers []version2.Header
-	for _, h := range proxy.RequestHeaders.Set {
+	for _, h := range ccf.Headers {
 		headers = append(headers, version2.Header{
 			Name:  h.Name,
-			Value: h.Value,
+			Value: "$" + h.Variable,
 		})
 	}
 
 	return headers
 }
 
-func generateProxyPassRequestHeaders(proxy *conf_v1.ActionProxy) bool {
+func generateProxySetHeaders(proxy *conf_v1.ActionProxy) []version2.Header {
 	if proxy == nil || proxy.RequestHeaders == nil {
-		return true
+		return nil
 	}
 
-	if proxy.RequestHeaders.Pass != nil {
-		return *proxy.RequestHeaders.Pass
-	}
+	var headers []version2.Header
+	for _, h := range proxy.RequestHeaders.Set {
+		headers = append(headers, version2.Header{
+			Name:  h.Name,
+			Value: h.Value,
+		})
+	}
+
+	return headers
+}
+
+// generateHostHeader returns the value of the Host header passed to the upstream.
+// An empty value means the Host header of the client request is preserved.
+func generateHostHeader(proxy *conf_v1.ActionProxy, upstreamHost string) string {
+	if proxy == nil || proxy.HostHeader == nil {
+		return ""
+	}
+
+	switch proxy.HostHeader.Mode {
+	case "upstream":
+		return upstreamHost
+	case "literal":
+		return proxy.HostHeader.Value
+	}
+
+	return ""
+}
+
+func generateProxyPassRequestHeaders(proxy *conf_v1.ActionProxy) bool {
+	if proxy == nil || proxy.RequestHeaders == nil {
+		return true
+	}
+
+	if proxy.RequestHeaders.Pass != nil {
+		return *proxy.RequestHeaders.Pass
+	}
 
 	return true
 }
@@ -700,12 +1424,34 @@ func generateProxyPassHeaders(proxy *conf_v1.ActionProxy) []string {
 	return proxy.ResponseHeaders.Pass
 }
 
-func generateProxyIgnoreHeaders(proxy *conf_v1.ActionProxy) string {
-	if proxy == nil || proxy.ResponseHeaders == nil {
-		return ""
+// cacheControlHeaders are the headers of a response that control its caching time.
+var cacheControlHeaders = []string{"Cache-Control", "Expires", "X-Accel-Expires"}
+
+// generateProxyIgnoreHeaders generates the headers of the responses ignored by NGINX. They include the headers ignored
+// by the action and by the cache of the upstream. A cache that overrides the cache control ignores the headers that
+// control the caching time, so that the caching times of the cache apply.
+func generateProxyIgnoreHeaders(proxy *conf_v1.ActionProxy, cache *conf_v1.UpstreamCache) string {
+	var headers []string
+	if proxy != nil && proxy.ResponseHeaders != nil {
+		headers = append(headers, proxy.ResponseHeaders.Ignore...)
+	}
+	if cache != nil {
+		headers = append(headers, cache.IgnoreHeaders...)
+		if cache.OverrideCacheControl {
+			headers = append(headers, cacheControlHeaders...)
+		}
+	}
+
+	var result []string
+	seen := make(map[string]bool)
+	for _, h := range headers {
+		if !seen[h] {
+			seen[h] = true
+			result = append(result, h)
+		}
 	}
 
-	return strings.Join(proxy.ResponseHeaders.Ignore, " ")
+	return strings.Join(result, " ")
 }
 
 func generateProxyAddHeaders(proxy *conf_v1.ActionProxy) []version2.AddHeader {
@@ -747,23 +1493,154 @@ func generateLocationForProxying(path string, upstreamName string, upstream conf
 		ProxyNextUpstreamTries:   upstream.ProxyNextUpstreamTries,
 		ProxyInterceptErrors:     generateProxyInterceptErrors(errorPages),
 		ProxyPassRequestHeaders:  generateProxyPassRequestHeaders(proxy),
+		HostHeader:               generateHostHeader(proxy, proxySSLName),
 		ProxySetHeaders:          generateProxySetHeaders(proxy),
 		ProxyHideHeaders:         generateProxyHideHeaders(proxy),
 		ProxyPassHeaders:         generateProxyPassHeaders(proxy),
-		ProxyIgnoreHeaders:       generateProxyIgnoreHeaders(proxy),
+		ProxyIgnoreHeaders:       generateProxyIgnoreHeaders(proxy, upstream.Cache),
 		AddHeaders:               generateProxyAddHeaders(proxy),
 		ProxyPassRewrite:         generateProxyPassRewrite(path, proxy, internal),
 		Rewrites:                 generateRewrites(path, proxy, internal, originalPath),
 		HasKeepalive:             upstreamHasKeepalive(upstream, cfgParams),
 		ErrorPages:               generateErrorPages(errPageIndex, errorPages),
-		ProxySSLName:             proxySSLName,
+		ProxySSLName:             generateActionProxySSLName(proxy, proxySSLName),
+		ProxySSLServerName:       generateProxySSLServerName(upstream.TLS, proxy),
+		ProxySSLSessionReuse:     generateProxySSLSessionReuse(upstream.TLS),
+		ProxySSLProtocols:        generateProxySSLProtocols(upstream.TLS),
+		ProxySSLCiphers:          generateProxySSLCiphers(upstream.TLS),
+		LimitConns:               generateUpstreamLimitConns(upstream.ConnectionLimitPolicy, cfgParams.ConnectionLimitPolicies),
+		ProxyCache:               generateProxyCache(upstreamName, upstream.Cache),
 	}
 }
 
+const cacheDirectory = "/var/cache/nginx"
+
+const defaultCacheZoneSize = "10m"
+
+// generateCacheZone generates the cache for the responses of the upstream.
+// The name of the upstream is unique, so it is used as the name of the zone and the directory of the cache.
+func generateCacheZone(upstreamName string, cache *conf_v1.UpstreamCache) version2.CacheZone {
+	return version2.CacheZone{
+		Name: upstreamName,
+		Path: fmt.Sprintf("%s/%s", cacheDirectory, upstreamName),
+		Size: generateString(cache.ZoneSize, defaultCacheZoneSize),
+	}
+}
+
+// generateProxyCache generates the caching of the responses of the upstream in a location.
+// nil is returned if the caching is not configured for the upstream.
+func generateProxyCache(upstreamName string, cache *conf_v1.UpstreamCache) *version2.ProxyCache {
+	if cache == nil {
+		return nil
+	}
+
+	return &version2.ProxyCache{
+		Zone:             upstreamName,
+		Lock:             cache.CacheLock,
+		LockTimeout:      cache.CacheLockTimeout,
+		BackgroundUpdate: cache.BackgroundUpdate,
+		Valid:            generateProxyCacheValid(cache.Valid),
+		Key:              cache.Key,
+	}
+}
+
+// generateProxyCacheValid generates the caching times of the responses by their status codes.
+// NGINX applies the first caching time that matches the status code of a response, so the codes are sorted
+// and the caching time for any code comes last.
+func generateProxyCacheValid(valid map[string]string) []version2.ProxyCacheValid {
+	var codes []string
+	for code := range valid {
+		if code != "any" {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	if _, exists := valid["any"]; exists {
+		codes = append(codes, "any")
+	}
+
+	var result []version2.ProxyCacheValid
+	for _, code := range codes {
+		result = append(result, version2.ProxyCacheValid{
+			Code: code,
+			Time: valid[code],
+		})
+	}
+
+	return result
+}
+
+// generateLimitConn generates the connection limit of the connection limit policy with the given name.
+// nil is returned if the policy doesn't exist.
+func generateLimitConn(policyName string, policies []ConnectionLimitPolicy) *version2.LimitConn {
+	if policyName == "" {
+		return nil
+	}
+
+	for _, p := range policies {
+		if p.Name == policyName {
+			return &version2.LimitConn{
+				Zone:  getConnectionLimitZoneName(p.Name),
+				Limit: p.Limit,
+			}
+		}
+	}
+
+	return nil
+}
+
+// generateUpstreamLimitConns generates the connection limits of a location for the connection limit policy of its upstream.
+func generateUpstreamLimitConns(policyName string, policies []ConnectionLimitPolicy) []version2.LimitConn {
+	limitConn := generateLimitConn(policyName, policies)
+	if limitConn == nil {
+		return nil
+	}
+
+	return []version2.LimitConn{*limitConn}
+}
+
+// appendLimitConns appends the connection limits to the limits of a location, skipping the limits with the zones
+// the location already limits, so that a location never gets several limit_conn directives for the same zone.
+func appendLimitConns(limitConns []version2.LimitConn, additional ...version2.LimitConn) []version2.LimitConn {
+	for _, lc := range additional {
+		duplicate := false
+		for _, existing := range limitConns {
+			if existing.Zone == lc.Zone {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			limitConns = append(limitConns, lc)
+		}
+	}
+
+	return limitConns
+}
+
 func generateProxyInterceptErrors(errorPages []conf_v1.ErrorPage) bool {
 	return len(errorPages) > 0
 }
 
+// generateReturnHeaders generates the headers of the response of a return. The headers are always added,
+// because a return can have any status code.
+func generateReturnHeaders(headers []conf_v1.Header) []version2.AddHeader {
+	var addHeaders []version2.AddHeader
+
+	for _, h := range headers {
+		addHeaders = append(addHeaders, version2.AddHeader{
+			Header: version2.Header{
+				Name:  h.Name,
+				Value: h.Value,
+			},
+			Always: true,
+		})
+	}
+
+	return addHeaders
+}
+
 func generateLocationForReturnBlock(path string, locationSnippets []string, r *version2.Return, defaultType string) version2.Location {
 	return version2.Location{
 		Path:        path,
@@ -781,10 +1658,15 @@ type routingCfg struct {
 }
 
 func generateSplits(splits []conf_v1.Split, upstreamNamer *upstreamNamer, crUpstreams map[string]conf_v1.Upstream,
-	variableNamer *variableNamer, scIndex int, cfgParams *ConfigParams, errorPages []conf_v1.ErrorPage, errPageIndex int, originalPath string) (version2.SplitClient, []version2.Location) {
+	variableNamer *variableNamer, scIndex int, cfgParams *ConfigParams, errorPages []conf_v1.ErrorPage, errPageIndex int, originalPath string,
+	stickySplits *conf_v1.StickySplits) (version2.SplitClient, []version2.Location) {
 	var distributions []version2.Distribution
 
 	for i, s := range splits {
+		// NGINX doesn't allow zero weights in a split client. The location of such a split is still generated.
+		if s.Weight == 0 {
+			continue
+		}
 		d := version2.Distribution{
 			Weight: fmt.Sprintf("%d%%", s.Weight),
 			Value:  fmt.Sprintf("/%vsplits_%d_split_%d", internalLocationPrefix, scIndex, i),
@@ -792,8 +1674,15 @@ func generateSplits(splits []conf_v1.Split, upstreamNamer *upstreamNamer, crUpst
 		distributions = append(distributions, d)
 	}
 
+	isSticky := stickySplits != nil && stickySplits.Enable
+
+	source := "$request_id"
+	if isSticky {
+		source = variableNamer.GetNameForSplitClientStickyKeyVariable(scIndex)
+	}
+
 	splitClient := version2.SplitClient{
-		Source:        "$request_id",
+		Source:        source,
 		Variable:      variableNamer.GetNameForSplitClientVariable(scIndex),
 		Distributions: distributions,
 	}
@@ -806,15 +1695,68 @@ func generateSplits(splits []conf_v1.Split, upstreamNamer *upstreamNamer, crUpst
 		upstream := crUpstreams[upstreamName]
 		proxySSLName := generateProxySSLName(upstream.Service, upstreamNamer.namespace)
 		loc := generateLocation(path, upstreamName, upstream, s.Action, cfgParams, errorPages, true, errPageIndex, proxySSLName, originalPath)
+		if isSticky {
+			loc.AddHeaders = append(loc.AddHeaders, version2.AddHeader{
+				Header: version2.Header{
+					Name:  "Set-Cookie",
+					Value: variableNamer.GetNameForSplitClientStickyCookieVariable(scIndex),
+				},
+				Always: true,
+			})
+		}
 		locations = append(locations, loc)
 	}
 
 	return splitClient, locations
 }
 
+// generateStickySplitsMaps generates the maps for the sticky splits of a split client.
+// The first map sets the key of the split client to the value of the cookie or, if the client doesn't have the cookie yet,
+// to the request ID. The second map sets the value of the Set-Cookie header that saves the request ID in the cookie
+// so that the next requests of the client land in the same split.
+func generateStickySplitsMaps(stickySplits *conf_v1.StickySplits, variableNamer *variableNamer, scIndex int) []version2.Map {
+	if stickySplits == nil || !stickySplits.Enable {
+		return nil
+	}
+
+	cookieName := generateString(stickySplits.CookieName, defaultStickySplitsCookieName)
+	source := fmt.Sprintf("$cookie_%s", cookieName)
+
+	return []version2.Map{
+		{
+			Source:   source,
+			Variable: variableNamer.GetNameForSplitClientStickyKeyVariable(scIndex),
+			Parameters: []version2.Parameter{
+				{
+					Value:  `""`,
+					Result: "$request_id",
+				},
+				{
+					Value:  "default",
+					Result: source,
+				},
+			},
+		},
+		{
+			Source:   source,
+			Variable: variableNamer.GetNameForSplitClientStickyCookieVariable(scIndex),
+			Parameters: []version2.Parameter{
+				{
+					Value:  `""`,
+					Result: fmt.Sprintf(`"%s=$request_id; Path=/"`, cookieName),
+				},
+				{
+					Value:  "default",
+					Result: `""`,
+				},
+			},
+		},
+	}
+}
+
 func generateDefaultSplitsConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, crUpstreams map[string]conf_v1.Upstream,
 	variableNamer *variableNamer, scIndex int, cfgParams *ConfigParams, errorPages []conf_v1.ErrorPage, errPageIndex int, originalPath string) routingCfg {
-	sc, locs := generateSplits(route.Splits, upstreamNamer, crUpstreams, variableNamer, scIndex, cfgParams, errorPages, errPageIndex, originalPath)
+	sc, locs := generateSplits(route.Splits, upstreamNamer, crUpstreams, variableNamer, scIndex, cfgParams, errorPages, errPageIndex, originalPath, route.StickySplits)
 
 	splitClientVarName := variableNamer.GetNameForSplitClientVariable(scIndex)
 
@@ -824,12 +1766,108 @@ func generateDefaultSplitsConfig(route conf_v1.Route, upstreamNamer *upstreamNam
 	}
 
 	return routingCfg{
+		Maps:                     generateStickySplitsMaps(route.StickySplits, variableNamer, scIndex),
 		SplitClients:             []version2.SplitClient{sc},
 		Locations:                locs,
 		InternalRedirectLocation: irl,
 	}
 }
 
+func isRouteSplits(splits []conf_v1.Split) bool {
+	return len(splits) > 0 && splits[0].Route != ""
+}
+
+func getVirtualServerRouteKey(route string, namespace string) string {
+	// if route is defined without a namespace, use the namespace of VirtualServer.
+	if !strings.Contains(route, "/") {
+		return fmt.Sprintf("%v/%v", namespace, route)
+	}
+	return route
+}
+
+// generateRouteSplitsConfig generates the config for a route that splits the traffic between VirtualServerRoutes.
+// The split client selects the prefix of the internal locations of one of the VirtualServerRoutes, and the location
+// of the route rewrites the URI of a request to that prefix followed by the original URI, so that the request is
+// matched against the subroutes of the selected VirtualServerRoute only. For each prefix, a catch-all location
+// returns 404 for the requests that don't match any subroute.
+func generateRouteSplitsConfig(route conf_v1.Route, variableNamer *variableNamer, scIndex int) routingCfg {
+	var distributions []version2.Distribution
+	var locations []version2.Location
+
+	for i, s := range route.Splits {
+		prefix := generateRouteSplitPrefix(scIndex, i)
+		if s.Weight > 0 {
+			distributions = append(distributions, version2.Distribution{
+				Weight: fmt.Sprintf("%d%%", s.Weight),
+				Value:  prefix,
+			})
+		}
+		locations = append(locations, version2.Location{
+			Path:     generateRouteSplitPath(prefix, ""),
+			Internal: true,
+			Return: &version2.Return{
+				Code: 404,
+			},
+		})
+	}
+
+	splitClientVarName := variableNamer.GetNameForSplitClientVariable(scIndex)
+
+	return routingCfg{
+		SplitClients: []version2.SplitClient{
+			{
+				Source:        "$request_id",
+				Variable:      splitClientVarName,
+				Distributions: distributions,
+			},
+		},
+		Locations: locations,
+		InternalRedirectLocation: version2.InternalRedirectLocation{
+			Path:        route.Path,
+			Destination: fmt.Sprintf("%s$uri", splitClientVarName),
+		},
+	}
+}
+
+// generateRouteSplitPrefix generates the prefix of the internal locations of the VirtualServerRoute referenced in a route split.
+func generateRouteSplitPrefix(scIndex int, splitIndex int) string {
+	return fmt.Sprintf("/%vroute_splits_%d_split_%d", internalLocationPrefix, scIndex, splitIndex)
+}
+
+// applySplitsCutover returns the splits of a route with the weights set by the cutover of the route:
+// promote sends all traffic to the last split and rollback sends all traffic to the first split.
+// The splits of the route are not modified.
+func applySplitsCutover(splits []conf_v1.Split, cutover string) []conf_v1.Split {
+	if len(splits) == 0 || (cutover != conf_v1.CutoverPromote && cutover != conf_v1.CutoverRollback) {
+		return splits
+	}
+
+	target := len(splits) - 1
+	if cutover == conf_v1.CutoverRollback {
+		target = 0
+	}
+
+	result := make([]conf_v1.Split, len(splits))
+	for i, s := range splits {
+		result[i] = s
+		result[i].Weight = 0
+		if i == target {
+			result[i].Weight = 100
+		}
+	}
+
+	return result
+}
+
+// generateRouteSplitPath generates the path of the location of a subroute of a VirtualServerRoute referenced in route splits.
+// The ^~ modifier prevents the regex locations of the VirtualServer from matching the rewritten URI.
+func generateRouteSplitPath(prefix string, path string) string {
+	if strings.HasPrefix(path, "=") {
+		return fmt.Sprintf("= %s%s", prefix, strings.TrimSpace(strings.TrimPrefix(path, "=")))
+	}
+	return fmt.Sprintf("^~ %s%s", prefix, path)
+}
+
 func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, crUpstreams map[string]conf_v1.Upstream,
 	variableNamer *variableNamer, index int, scIndex int, cfgParams *ConfigParams, errorPages []conf_v1.ErrorPage, errPageIndex int) routingCfg {
 	// Generate maps
@@ -838,6 +1876,9 @@ func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, cr
 	for i, m := range route.Matches {
 		for j, c := range m.Conditions {
 			source := getNameForSourceForMatchesRouteMapFromCondition(c)
+			if c.Variable != "" {
+				source = variableNamer.GetNameForVariable(c.Variable)
+			}
 			variable := variableNamer.GetNameForVariableForMatchesRouteMap(index, i, j)
 			successfulResult := "1"
 			if j < len(m.Conditions)-1 {
@@ -904,7 +1945,8 @@ func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, cr
 
 	for i, m := range route.Matches {
 		if len(m.Splits) > 0 {
-			sc, locs := generateSplits(m.Splits, upstreamNamer, crUpstreams, variableNamer, scIndex+scLocalIndex, cfgParams, errorPages, errPageIndex, route.Path)
+			sc, locs := generateSplits(m.Splits, upstreamNamer, crUpstreams, variableNamer, scIndex+scLocalIndex, cfgParams, errorPages, errPageIndex, route.Path, route.StickySplits)
+			maps = append(maps, generateStickySplitsMaps(route.StickySplits, variableNamer, scIndex+scLocalIndex)...)
 			scLocalIndex++
 
 			splitClients = append(splitClients, sc)
@@ -921,7 +1963,8 @@ func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, cr
 
 	// Generate default splits or default action
 	if len(route.Splits) > 0 {
-		sc, locs := generateSplits(route.Splits, upstreamNamer, crUpstreams, variableNamer, scIndex+scLocalIndex, cfgParams, errorPages, errPageIndex, route.Path)
+		sc, locs := generateSplits(route.Splits, upstreamNamer, crUpstreams, variableNamer, scIndex+scLocalIndex, cfgParams, errorPages, errPageIndex, route.Path, route.StickySplits)
+		maps = append(maps, generateStickySplitsMaps(route.StickySplits, variableNamer, scIndex+scLocalIndex)...)
 		splitClients = append(splitClients, sc)
 		locations = append(locations, locs...)
 	} else {
@@ -954,6 +1997,136 @@ var specialMapParameters = map[string]bool{
 	"volatile":  true,
 }
 
+// generateMaps generates the maps declared in the VirtualServer.
+func generateMaps(maps []conf_v1.Map, variableNamer *variableNamer) []version2.Map {
+	var result []version2.Map
+
+	for _, m := range maps {
+		var params []version2.Parameter
+		hostnames := false
+
+		for _, mapping := range m.Mappings {
+			params = append(params, version2.Parameter{
+				Value:  generateValueForMap(mapping.Value),
+				Result: fmt.Sprintf(`"%s"`, mapping.Result),
+			})
+
+			if isHostnameMask(mapping.Value) {
+				hostnames = true
+			}
+		}
+
+		params = append(params, version2.Parameter{
+			Value:  "default",
+			Result: fmt.Sprintf(`"%s"`, m.Default),
+		})
+
+		result = append(result, version2.Map{
+			Source:     m.Source,
+			Variable:   variableNamer.GetNameForMapVariable(m.Name),
+			Parameters: params,
+			Hostnames:  hostnames,
+		})
+	}
+
+	return result
+}
+
+// GetUpstreamMapNames returns the names of the maps referenced as $<map name> instead of an upstream in the actions of the routes.
+func GetUpstreamMapNames(routes []conf_v1.Route) map[string]bool {
+	mapNames := make(map[string]bool)
+
+	addAction := func(action *conf_v1.Action) {
+		if action == nil {
+			return
+		}
+
+		upstream := action.Pass
+		if action.Proxy != nil && action.Proxy.Upstream != "" {
+			upstream = action.Proxy.Upstream
+		}
+
+		if mapName := strings.TrimPrefix(upstream, "$"); mapName != upstream {
+			mapNames[mapName] = true
+		}
+	}
+
+	addSplits := func(splits []conf_v1.Split) {
+		for _, s := range splits {
+			addAction(s.Action)
+		}
+	}
+
+	for _, r := range routes {
+		addAction(r.Action)
+		addSplits(r.Splits)
+
+		for _, m := range r.Matches {
+			addAction(m.Action)
+			addSplits(m.Splits)
+		}
+	}
+
+	return mapNames
+}
+
+// generateUpstreamMaps generates the maps that select the upstreams by the results of the maps of the VirtualServer
+// referenced in actions.
+func generateUpstreamMaps(maps []conf_v1.Map, upstreamMapNames map[string]bool, variableNamer *variableNamer, upstreamNamer *upstreamNamer) []version2.Map {
+	var result []version2.Map
+
+	for _, m := range maps {
+		if !upstreamMapNames[m.Name] {
+			continue
+		}
+
+		var params []version2.Parameter
+		upstreams := make(map[string]bool)
+
+		for _, mapping := range m.Mappings {
+			if upstreams[mapping.Result] {
+				continue
+			}
+			upstreams[mapping.Result] = true
+
+			params = append(params, version2.Parameter{
+				Value:  fmt.Sprintf(`"%s"`, mapping.Result),
+				Result: upstreamNamer.GetNameForUpstream(mapping.Result),
+			})
+		}
+
+		params = append(params, version2.Parameter{
+			Value:  "default",
+			Result: upstreamNamer.GetNameForUpstream(m.Default),
+		})
+
+		result = append(result, version2.Map{
+			Source:     variableNamer.GetNameForMapVariable(m.Name),
+			Variable:   upstreamNamer.GetNameForUpstreamVariable(m.Name),
+			Parameters: params,
+		})
+	}
+
+	return result
+}
+
+func generateValueForMap(value string) string {
+	if len(value) == 0 {
+		return `""`
+	}
+
+	if _, exists := specialMapParameters[value]; exists {
+		return `\` + value
+	}
+
+	return fmt.Sprintf(`"%s"`, value)
+}
+
+// isHostnameMask checks if the value is a hostname with a prefix or suffix mask, like *.example.com or www.example.*
+func isHostnameMask(value string) bool {
+	return strings.HasPrefix(value, "*.") || strings.HasSuffix(value, ".*")
+}
+
 func generateValueForMatchesRouteMap(matchedValue string) (value string, isNegative bool) {
 	if len(matchedValue) == 0 {
 		return `""`, false
@@ -1040,6 +2213,67 @@ func generateSSLConfig(tls *conf_v1.TLS, tlsPemFileName string, cfgParams *Confi
 	return &ssl
 }
 
+// generateDynamicSSLConfig generates the SSL config of a VirtualServer with dynamic certificates. NGINX loads
+// the certificate and the key of such a VirtualServer by SNI from the directory of the dynamic certificates
+// during the TLS handshake, so the certificates are not a part of the configuration and don't slow down the reloads.
+func (vsc *virtualServerConfigurator) generateDynamicSSLConfig(vs *conf_v1.VirtualServer) *version2.SSL {
+	tls := vs.Spec.TLS
+	if tls == nil || !tls.DynamicCertificates {
+		return nil
+	}
+
+	if !vsc.dynamicSSLCerts {
+		vsc.addWarningf(vs, "Dynamic certificates can't be applied. To use dynamic certificates, they must be enabled with the -dynamic-ssl-certificates-directory command-line argument")
+		return nil
+	}
+
+	return &version2.SSL{
+		HTTP2:          vsc.cfgParams.HTTP2,
+		Certificate:    dynamicSSLCertificateVariable,
+		CertificateKey: dynamicSSLCertificateVariable,
+	}
+}
+
+// addTLSSessionToSSLConfig adds the TLS session cache and tickets of the VirtualServer to the SSL config.
+// A session cache with a size is a shared cache of the VirtualServer, so that different VirtualServers
+// never declare the same cache with different sizes.
+func (vsc *virtualServerConfigurator) addTLSSessionToSSLConfig(ssl *version2.SSL, vs *conf_v1.VirtualServer, sessionTicketKeyFileName string) {
+	if ssl == nil {
+		return
+	}
+
+	tls := vs.Spec.TLS
+
+	switch tls.SessionCache {
+	case "":
+	case "off", "none":
+		ssl.SessionCache = tls.SessionCache
+	default:
+		ssl.SessionCache = fmt.Sprintf("shared:vs_%s_%s_ssl:%s", vs.Namespace, vs.Name, tls.SessionCache)
+	}
+
+	ssl.SessionTimeout = tls.SessionTimeout
+
+	if tls.SessionTickets != nil {
+		ssl.SessionTickets = "off"
+		if *tls.SessionTickets {
+			ssl.SessionTickets = "on"
+		}
+	}
+
+	if tls.SessionTicketKey == "" {
+		return
+	}
+
+	if sessionTicketKeyFileName == "" {
+		vsc.addWarningf(vs, "TLS session ticket key references an invalid or non-existing secret %s; NGINX will use a random key, which is not shared across the replicas of the Ingress Controller",
+			GetSecretKeyForReference(vs.Namespace, tls.SessionTicketKey))
+		return
+	}
+
+	ssl.SessionTicketKey = sessionTicketKeyFileName
+}
+
 func generateTLSRedirectConfig(tls *conf_v1.TLS) *version2.TLSRedirect {
 	if tls == nil || tls.Redirect == nil || !tls.Redirect.Enable {
 		return nil
@@ -1064,6 +2298,9 @@ func createEndpointsFromUpstream(upstream version2.Upstream) []string {
 	var endpoints []string
 
 	for _, server := range upstream.Servers {
+		if server.Backup {
+			continue
+		}
 		endpoints = append(endpoints, server.Address)
 	}
 
@@ -1074,8 +2311,9 @@ func createUpstreamsForPlus(virtualServerEx *VirtualServerEx, baseCfgParams *Con
 	var upstreams []version2.Upstream
 
 	isPlus := true
+	isResolverConfigured := len(baseCfgParams.ResolverAddresses) != 0
 	upstreamNamer := newUpstreamNamerForVirtualServer(virtualServerEx.VirtualServer)
-	vsc := newVirtualServerConfigurator(baseCfgParams, isPlus, false, staticParams)
+	vsc := newVirtualServerConfigurator(baseCfgParams, isPlus, isResolverConfigured, staticParams)
 
 	for _, u := range virtualServerEx.VirtualServer.Spec.Upstreams {
 		isExternalNameSvc := virtualServerEx.ExternalNameSvcs[GenerateExternalNameSvcKey(virtualServerEx.VirtualServer.Namespace, u.Service)]
@@ -1084,13 +2322,18 @@ func createUpstreamsForPlus(virtualServerEx *VirtualServerEx, baseCfgParams *Con
 			continue
 		}
 
+		if vsc.isSRVDiscoveryEnabled(u) {
+			glog.V(3).Infof("Upstream %s uses DNS SRV service discovery, skipping NGINX Plus endpoints update via API", u.Name)
+			continue
+		}
+
 		upstreamName := upstreamNamer.GetNameForUpstream(u.Name)
 		upstreamNamespace := virtualServerEx.VirtualServer.Namespace
 
-		endpointsKey := GenerateEndpointsKey(upstreamNamespace, u.Service, u.Subselector, u.Port)
-		endpoints := virtualServerEx.Endpoints[endpointsKey]
+		endpoints := generateServicesEndpointsForUpstream(upstreamNamespace, u, virtualServerEx)
 
-		ups := vsc.generateUpstream(virtualServerEx.VirtualServer, upstreamName, u, isExternalNameSvc, endpoints)
+		backupEndpoints := generateBackupEndpointsForUpstream(upstreamNamespace, u, virtualServerEx)
+		ups := vsc.generateUpstream(virtualServerEx.VirtualServer, upstreamName, u, isExternalNameSvc, endpoints, backupEndpoints)
 		upstreams = append(upstreams, ups)
 	}
 
@@ -1103,13 +2346,18 @@ func createUpstreamsForPlus(virtualServerEx *VirtualServerEx, baseCfgParams *Con
 				continue
 			}
 
+			if vsc.isSRVDiscoveryEnabled(u) {
+				glog.V(3).Infof("Upstream %s uses DNS SRV service discovery, skipping NGINX Plus endpoints update via API", u.Name)
+				continue
+			}
+
 			upstreamName := upstreamNamer.GetNameForUpstream(u.Name)
 			upstreamNamespace := vsr.Namespace
 
-			endpointsKey := GenerateEndpointsKey(upstreamNamespace, u.Service, u.Subselector, u.Port)
-			endpoints := virtualServerEx.Endpoints[endpointsKey]
+			endpoints := generateServicesEndpointsForUpstream(upstreamNamespace, u, virtualServerEx)
 
-			ups := vsc.generateUpstream(vsr, upstreamName, u, isExternalNameSvc, endpoints)
+			backupEndpoints := generateBackupEndpointsForUpstream(upstreamNamespace, u, virtualServerEx)
+			ups := vsc.generateUpstream(vsr, upstreamName, u, isExternalNameSvc, endpoints, backupEndpoints)
 			upstreams = append(upstreams, ups)
 		}
 	}
@@ -1121,11 +2369,24 @@ func createUpstreamServersConfigForPlus(upstream version2.Upstream) nginx.Server
 	if len(upstream.Servers) == 0 {
 		return nginx.ServerConfig{}
 	}
+	var backupServers []string
+	var downServers []string
+	for _, server := range upstream.Servers {
+		if server.Backup {
+			backupServers = append(backupServers, server.Address)
+		}
+		if server.Down {
+			downServers = append(downServers, server.Address)
+		}
+	}
+
 	return nginx.ServerConfig{
-		MaxFails:    upstream.MaxFails,
-		FailTimeout: upstream.FailTimeout,
-		MaxConns:    upstream.MaxConns,
-		SlowStart:   upstream.SlowStart,
+		MaxFails:      upstream.MaxFails,
+		FailTimeout:   upstream.FailTimeout,
+		MaxConns:      upstream.MaxConns,
+		SlowStart:     upstream.SlowStart,
+		BackupServers: backupServers,
+		DownServers:   downServers,
 	}
 }
 
@@ -1164,7 +2425,7 @@ func generateErrorPages(errPageIndex int, errorPages []conf_v1.ErrorPage) []vers
 			if e.Redirect.Code != 0 {
 				code = e.Redirect.Code
 			}
-			name = e.Redirect.URL
+			name = generateRedirectURL(&e.Redirect.ActionRedirect)
 		} else {
 			code = e.Return.Code
 			name = generateErrorPageName(errPageIndex, i)
@@ -1217,6 +2478,513 @@ func generateErrorPageLocations(errPageIndex int, errorPages []conf_v1.ErrorPage
 	return errorPageLocations
 }
 
+// generateSubFilter generates the substitutions of strings in the responses of a route.
+// Like sub_filter_once, the first occurrence of each string is replaced unless all occurrences are requested.
+func generateSubFilter(subFilter *conf_v1.SubFilter) *version2.SubFilter {
+	if subFilter == nil {
+		return nil
+	}
+
+	var substitutions []version2.Substitution
+	for _, s := range subFilter.Substitutions {
+		substitutions = append(substitutions, version2.Substitution{
+			From: s.From,
+			To:   s.To,
+		})
+	}
+
+	return &version2.SubFilter{
+		Substitutions: substitutions,
+		Once:          subFilter.Replace != "all",
+		Types:         strings.Join(subFilter.Types, " "),
+	}
+}
+
+type mirrorConfig struct {
+	Location    version2.Location
+	SplitClient *version2.SplitClient
+}
+
+// generateMirrorConfig generates the internal location that passes the mirrored requests of a route to the shadow
+// upstream. NGINX handles the mirror subrequests in parallel with the requests and ignores their responses.
+// If only a percentage of the requests is mirrored, a split client samples the requests by the request ID,
+// and the location drops the mirror subrequests of the other requests before they reach the shadow upstream.
+func generateMirrorConfig(mirror *conf_v1.Mirror, upstreamNamer *upstreamNamer, crUpstreams map[string]conf_v1.Upstream,
+	variableNamer *variableNamer, index int, cfgParams *ConfigParams) *mirrorConfig {
+	if mirror == nil {
+		return nil
+	}
+
+	path := fmt.Sprintf("/%vmirror_%d", internalLocationPrefix, index)
+	upstreamName := upstreamNamer.GetNameForUpstream(mirror.Upstream)
+	upstream := crUpstreams[upstreamName]
+	proxySSLName := generateProxySSLName(upstream.Service, upstreamNamer.namespace)
+
+	cfg := &mirrorConfig{
+		Location: generateLocationForProxying(path, upstreamName, upstream, cfgParams, nil, true, 0, proxySSLName, nil, ""),
+	}
+
+	if mirror.Percentage == nil || *mirror.Percentage == 100 {
+		return cfg
+	}
+
+	variable := variableNamer.GetNameForMirrorVariable(index)
+	cfg.Location.MirrorGate = variable
+	cfg.SplitClient = &version2.SplitClient{
+		Source:   "$request_id",
+		Variable: variable,
+		Distributions: []version2.Distribution{
+			{
+				Weight: fmt.Sprintf("%d%%", *mirror.Percentage),
+				Value:  "1",
+			},
+			{
+				Weight: "*",
+				Value:  `""`,
+			},
+		},
+	}
+
+	return cfg
+}
+
+// addMirrorToLocations adds the mirroring of a route to the locations that pass its requests to the upstreams.
+func addMirrorToLocations(locations []version2.Location, mirrorPath string) {
+	for i := range locations {
+		if locations[i].ProxyPass != "" {
+			locations[i].Mirror = mirrorPath
+		}
+	}
+}
+
+// addSubFilterToLocations adds the substitutions of a route to the locations generated for its matches and splits.
+func addSubFilterToLocations(locations []version2.Location, subFilter *conf_v1.SubFilter) {
+	sf := generateSubFilter(subFilter)
+	for i := range locations {
+		locations[i].SubFilter = sf
+	}
+}
+
+// generateRequestBuffering generates the value of the proxy_request_buffering directive of a route.
+// If the route doesn't configure the request buffering, the directive is not generated and the NGINX default applies.
+func generateRequestBuffering(requestBuffering *bool) string {
+	if requestBuffering == nil {
+		return ""
+	}
+
+	if *requestBuffering {
+		return "on"
+	}
+
+	return "off"
+}
+
+func addRequestBufferingToLocations(locations []version2.Location, requestBuffering *bool) {
+	rb := generateRequestBuffering(requestBuffering)
+	for i := range locations {
+		locations[i].ProxyRequestBuffering = rb
+	}
+}
+
+// generateSnippets generates the snippets of a server or a location: the snippets from the ConfigMap
+// followed by the snippets of the resource.
+func generateSnippets(globalSnippets []string, snippets string) []string {
+	if snippets == "" {
+		return globalSnippets
+	}
+
+	result := make([]string, 0, len(globalSnippets)+1)
+	result = append(result, globalSnippets...)
+
+	return append(result, snippets)
+}
+
+func addLocationSnippetsToLocations(locations []version2.Location, globalSnippets []string, snippets string) {
+	s := generateSnippets(globalSnippets, snippets)
+	for i := range locations {
+		locations[i].Snippets = s
+	}
+}
+
+func addSourceToLocations(locations []version2.Location, source string) {
+	for i := range locations {
+		locations[i].Source = source
+	}
+}
+
+// policiesCfg holds the configuration generated from the policies referenced by a route.
+type policiesCfg struct {
+	Allow           []string
+	Deny            []string
+	LimitReqZones   []version2.LimitReqZone
+	LimitReqs       []version2.LimitReq
+	LimitReqStatus  int
+	LimitConnZones  []version2.LimitConnZone
+	LimitConns      []version2.LimitConn
+	LimitConnStatus int
+	JWTAuth         *version2.JWTAuth
+	ErrorReturn     *version2.Return
+}
+
+const defaultRateLimitZoneSize = "10m"
+
+// getLimitReqZoneSize returns the default size of the zones of the limit_req module.
+func (vsc *virtualServerConfigurator) getLimitReqZoneSize() string {
+	return generateString(vsc.cfgParams.LimitReqZoneSize, defaultRateLimitZoneSize)
+}
+
+// getLimitConnZoneSize returns the default size of the zones of the limit_conn module.
+func (vsc *virtualServerConfigurator) getLimitConnZoneSize() string {
+	return generateString(vsc.cfgParams.LimitConnZoneSize, defaultRateLimitZoneSize)
+}
+
+// generatePolicies generates the configuration of the policies referenced by a route of the owner (a VirtualServer or a VirtualServerRoute).
+// A reference without a namespace refers to a policy in the namespace of the owner.
+// If a referenced policy is missing or can't be applied, the locations of the route return 500 so that the route is never left unprotected.
+func (vsc *virtualServerConfigurator) generatePolicies(owner runtime.Object, ownerNamespace string, vs *conf_v1.VirtualServer,
+	policyRefs []conf_v1.PolicyReference, policies map[string]*conf_v1alpha1.Policy, jwtKeyFileNames map[string]string) policiesCfg {
+	var cfg policiesCfg
+
+	for _, p := range policyRefs {
+		polNamespace := p.Namespace
+		if polNamespace == "" {
+			polNamespace = ownerNamespace
+		}
+
+		key := fmt.Sprintf("%s/%s", polNamespace, p.Name)
+
+		pol, exists := policies[key]
+		if !exists {
+			vsc.addWarningf(owner, "Policy %s is missing or invalid", key)
+			cfg.ErrorReturn = &version2.Return{Code: 500}
+			continue
+		}
+
+		switch {
+		case pol.Spec.AccessControl != nil:
+			if cfg.Allow != nil || cfg.Deny != nil {
+				vsc.addWarningf(owner, "Multiple access control policies in the same route are not allowed. The policy %s is ignored", key)
+				continue
+			}
+
+			cfg.Allow = pol.Spec.AccessControl.Allow
+			cfg.Deny = pol.Spec.AccessControl.Deny
+		case pol.Spec.RateLimit != nil:
+			rl := pol.Spec.RateLimit
+			zoneName := fmt.Sprintf("pol_rl_%s_%s_%s_%s", polNamespace, p.Name, vs.Namespace, vs.Name)
+
+			cfg.LimitReqZones = append(cfg.LimitReqZones, version2.LimitReqZone{
+				Key:      rl.Key,
+				ZoneName: zoneName,
+				ZoneSize: generateLimitZoneSize(rl.ZoneSize, vsc.getLimitReqZoneSize(), vsc.policyReferences[key]),
+				Rate:     rl.Rate,
+			})
+			cfg.LimitReqs = append(cfg.LimitReqs, version2.LimitReq{
+				ZoneName: zoneName,
+				Burst:    generateIntFromPointer(rl.Burst, 0),
+				NoDelay:  generateBool(rl.NoDelay, false),
+			})
+
+			if cfg.LimitReqStatus == 0 && rl.RejectCode != nil {
+				cfg.LimitReqStatus = *rl.RejectCode
+			}
+		case pol.Spec.CombinedLimit != nil:
+			cl := pol.Spec.CombinedLimit
+			rateZoneName := fmt.Sprintf("pol_rl_%s_%s_%s_%s", polNamespace, p.Name, vs.Namespace, vs.Name)
+			connZoneName := fmt.Sprintf("pol_cl_%s_%s_%s_%s", polNamespace, p.Name, vs.Namespace, vs.Name)
+
+			cfg.LimitReqZones = append(cfg.LimitReqZones, version2.LimitReqZone{
+				Key:      cl.Key,
+				ZoneName: rateZoneName,
+				ZoneSize: generateLimitZoneSize(cl.ZoneSize, vsc.getLimitReqZoneSize(), vsc.policyReferences[key]),
+				Rate:     cl.Rate,
+			})
+			cfg.LimitReqs = append(cfg.LimitReqs, version2.LimitReq{
+				ZoneName: rateZoneName,
+				Burst:    generateIntFromPointer(cl.Burst, 0),
+				NoDelay:  generateBool(cl.NoDelay, false),
+			})
+			cfg.LimitConnZones = append(cfg.LimitConnZones, version2.LimitConnZone{
+				Key:      cl.Key,
+				ZoneName: connZoneName,
+				ZoneSize: generateLimitZoneSize(cl.ZoneSize, vsc.getLimitConnZoneSize(), vsc.policyReferences[key]),
+			})
+			cfg.LimitConns = append(cfg.LimitConns, version2.LimitConn{
+				Zone:  connZoneName,
+				Limit: cl.Connections,
+			})
+
+			if cl.RejectCode != nil {
+				if cfg.LimitReqStatus == 0 {
+					cfg.LimitReqStatus = *cl.RejectCode
+				}
+				if cfg.LimitConnStatus == 0 {
+					cfg.LimitConnStatus = *cl.RejectCode
+				}
+			}
+		case pol.Spec.JWTAuth != nil:
+			if cfg.JWTAuth != nil {
+				vsc.addWarningf(owner, "Multiple jwt policies in the same route are not allowed. The policy %s is ignored", key)
+				continue
+			}
+
+			jwt := pol.Spec.JWTAuth
+			secretKey := GetSecretKeyForReference(polNamespace, jwt.Secret)
+
+			fileName, exists := jwtKeyFileNames[secretKey]
+			if !exists {
+				vsc.addWarningf(owner, "The jwt policy %s references an invalid or non-existing secret %s", key, secretKey)
+				cfg.ErrorReturn = &version2.Return{Code: 500}
+				continue
+			}
+
+			cfg.JWTAuth = &version2.JWTAuth{
+				Secret: fileName,
+				Realm:  jwt.Realm,
+				Token:  jwt.Token,
+			}
+		}
+	}
+
+	return cfg
+}
+
+// addPoliciesCfgToLocation adds the configuration of the policies of a route to a location of the route.
+func addPoliciesCfgToLocation(location *version2.Location, cfg policiesCfg) {
+	location.Allow = cfg.Allow
+	location.Deny = cfg.Deny
+	location.LimitReqs = cfg.LimitReqs
+	location.LimitReqStatus = cfg.LimitReqStatus
+	location.LimitConns = appendLimitConns(location.LimitConns, cfg.LimitConns...)
+	location.LimitConnStatus = cfg.LimitConnStatus
+	location.JWTAuth = cfg.JWTAuth
+	location.PoliciesErrorReturn = cfg.ErrorReturn
+}
+
+// addPoliciesCfgToLocations adds the configuration of the policies of a route to the locations generated for its matches and splits.
+func addPoliciesCfgToLocations(locations []version2.Location, cfg policiesCfg) {
+	for i := range locations {
+		addPoliciesCfgToLocation(&locations[i], cfg)
+	}
+}
+
+// removeDuplicateLimitReqZones removes the zones of the rate limit policies referenced by multiple routes.
+// Those routes share the zone.
+func removeDuplicateLimitReqZones(zones []version2.LimitReqZone) []version2.LimitReqZone {
+	var result []version2.LimitReqZone
+	seen := make(map[string]bool)
+
+	for _, z := range zones {
+		if !seen[z.ZoneName] {
+			seen[z.ZoneName] = true
+			result = append(result, z)
+		}
+	}
+
+	return result
+}
+
+// generateConnectionLimit generates the zone, the limit and the status code of the connection limit of the VirtualServer.
+// nil is returned if the VirtualServer doesn't limit the connections.
+func generateConnectionLimit(virtualServerEx *VirtualServerEx, defaultZoneSize string) (*version2.LimitConnZone, *version2.LimitConn, int) {
+	vs := virtualServerEx.VirtualServer
+	connectionLimit := vs.Spec.ConnectionLimit
+	if connectionLimit == nil {
+		return nil, nil, 0
+	}
+
+	zoneName := fmt.Sprintf("vs_cl_%s_%s", vs.Namespace, vs.Name)
+
+	zone := &version2.LimitConnZone{
+		Key:      connectionLimit.Key,
+		ZoneName: zoneName,
+		ZoneSize: generateString(connectionLimit.ZoneSize, defaultZoneSize),
+	}
+	limit := &version2.LimitConn{
+		Zone:  zoneName,
+		Limit: generateConnectionLimitConnections(virtualServerEx),
+	}
+
+	return zone, limit, generateIntFromPointer(connectionLimit.RejectCode, 0)
+}
+
+// generateConnectionLimitConnections generates the maximum number of connections of the connection limit of the VirtualServer.
+// For a limit derived from the capacity of an upstream, the maximum is the number of connections per endpoint multiplied by
+// the number of the ready endpoints of all services of the upstream, so that the limit scales with the upstream. NGINX requires a positive
+// limit, so an upstream without endpoints has the capacity of one endpoint.
+// 0 is returned if the VirtualServer doesn't limit the connections.
+func generateConnectionLimitConnections(virtualServerEx *VirtualServerEx) int {
+	vs := virtualServerEx.VirtualServer
+	connectionLimit := vs.Spec.ConnectionLimit
+	if connectionLimit == nil {
+		return 0
+	}
+
+	if connectionLimit.ConnectionsPerEndpoint == 0 {
+		return connectionLimit.Connections
+	}
+
+	endpointsCount := 0
+	for _, u := range vs.Spec.Upstreams {
+		if u.Name == connectionLimit.Upstream {
+			endpointsCount = len(generateServicesEndpointsForUpstream(vs.Namespace, u, virtualServerEx))
+			break
+		}
+	}
+
+	if endpointsCount == 0 {
+		endpointsCount = 1
+	}
+
+	return connectionLimit.ConnectionsPerEndpoint * endpointsCount
+}
+
+// addConnectionLimitToLocations adds the connection limit of the VirtualServer to the locations with their own
+// connection limits. NGINX inherits the limit_conn directives of the server only by the locations without any,
+// so the limit of the server must be repeated in such locations to cap the connections to the host.
+func addConnectionLimitToLocations(locations []version2.Location, limitConn version2.LimitConn) {
+	for i := range locations {
+		if len(locations[i].LimitConns) > 0 {
+			locations[i].LimitConns = appendLimitConns(locations[i].LimitConns, limitConn)
+		}
+	}
+}
+
+const errorBackendLocationName = "@error_backend"
+
+// generateErrorBackend generates the error page for the error responses handled by the error backend of the VirtualServer
+// and the named location that proxies those responses to the upstream of the error backend.
+func generateErrorBackend(virtualServer *conf_v1.VirtualServer, upstreamNamer *upstreamNamer, crUpstreams map[string]conf_v1.Upstream,
+	cfgParams *ConfigParams) (*version2.ErrorPage, *version2.Location) {
+	errorBackend := virtualServer.Spec.ErrorBackend
+	if errorBackend == nil {
+		return nil, nil
+	}
+
+	upstreamName := upstreamNamer.GetNameForUpstream(errorBackend.Upstream)
+	upstream := crUpstreams[upstreamName]
+	proxySSLName := generateProxySSLName(upstream.Service, virtualServer.Namespace)
+
+	loc := generateLocationForProxying(errorBackendLocationName, upstreamName, upstream, cfgParams, nil, false, 0, proxySSLName, nil, errorBackendLocationName)
+	// the error backend needs the status code and the URI of the original request to render the response
+	loc.ProxySetHeaders = []version2.Header{
+		{
+			Name:  "X-Code",
+			Value: "$status",
+		},
+		{
+			Name:  "X-Original-URI",
+			Value: "$request_uri",
+		},
+	}
+
+	errorPage := &version2.ErrorPage{
+		Name:  errorBackendLocationName,
+		Codes: generateErrorPageCodes(errorBackend.Codes),
+	}
+
+	return errorPage, &loc
+}
+
+// addErrorBackendToLocations adds the error page of the error backend to the proxying locations. The error page comes
+// after the error pages of the routes, so that NGINX applies those first, and the error responses of the upstreams
+// are intercepted to reach the error backend.
+func addErrorBackendToLocations(locations []version2.Location, errorPage version2.ErrorPage) {
+	for i := range locations {
+		if locations[i].ProxyPass != "" {
+			locations[i].ErrorPages = append(locations[i].ErrorPages, errorPage)
+			locations[i].ProxyInterceptErrors = true
+		}
+	}
+}
+
+// removeDuplicateLimitConnZones removes the connection limit zones of the combined limit policies referenced by
+// multiple routes. Those routes share the zone.
+func removeDuplicateLimitConnZones(zones []version2.LimitConnZone) []version2.LimitConnZone {
+	var result []version2.LimitConnZone
+	seen := make(map[string]bool)
+
+	for _, z := range zones {
+		if !seen[z.ZoneName] {
+			seen[z.ZoneName] = true
+			result = append(result, z)
+		}
+	}
+
+	return result
+}
+
+// generateAllowedMethods generates the allowed request methods of a route. Like limit_except, allowing GET also allows HEAD.
+func generateAllowedMethods(methods []string) *version2.AllowedMethods {
+	if len(methods) == 0 {
+		return nil
+	}
+
+	allowed := append([]string{}, methods...)
+
+	allowsGET := false
+	allowsHEAD := false
+	for _, m := range methods {
+		if m == "GET" {
+			allowsGET = true
+		}
+		if m == "HEAD" {
+			allowsHEAD = true
+		}
+	}
+	if allowsGET && !allowsHEAD {
+		allowed = append(allowed, "HEAD")
+	}
+
+	return &version2.AllowedMethods{
+		Pattern: strings.Join(allowed, "|"),
+		Header:  strings.Join(allowed, ", "),
+	}
+}
+
+func generateProxySSLSessionReuse(tls conf_v1.UpstreamTLS) string {
+	if !tls.Enable || tls.SessionReuse == nil {
+		return ""
+	}
+
+	if *tls.SessionReuse {
+		return "on"
+	}
+
+	return "off"
+}
+
+func generateProxySSLProtocols(tls conf_v1.UpstreamTLS) string {
+	if !tls.Enable {
+		return ""
+	}
+
+	return strings.Join(tls.Protocols, " ")
+}
+
+func generateProxySSLCiphers(tls conf_v1.UpstreamTLS) string {
+	if !tls.Enable {
+		return ""
+	}
+
+	return tls.Ciphers
+}
+
+// generateActionProxySSLName returns the server name of the TLS upstream of the proxy action, which overrides
+// the name generated from the service.
+func generateActionProxySSLName(proxy *conf_v1.ActionProxy, proxySSLName string) string {
+	if proxy == nil || proxy.ProxySSLName == "" {
+		return proxySSLName
+	}
+
+	return proxy.ProxySSLName
+}
+
+// generateProxySSLServerName checks if the server name of the TLS upstream of the proxy action is sent in the SNI.
+func generateProxySSLServerName(tls conf_v1.UpstreamTLS, proxy *conf_v1.ActionProxy) bool {
+	return tls.Enable && proxy != nil && proxy.ProxySSLName != ""
+}
+
 func generateProxySSLName(svcName, ns string) string {
 	return fmt.Sprintf("%s.%s.svc", svcName, ns)
 }
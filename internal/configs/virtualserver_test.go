@@ -8,6 +8,8 @@ import (
 	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
 	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
 	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	api_v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -172,6 +174,52 @@ func TestVariableNamer(t *testing.T) {
 	if result != expected {
 		t.Errorf("GetNameForVariableForMatchesRouteMainMap() returned %q but expected %q", result, expected)
 	}
+
+	// GetNameForMapVariable()
+	expected = "$vs_default_cafe_map_region"
+
+	result = variableNamer.GetNameForMapVariable("region")
+	if result != expected {
+		t.Errorf("GetNameForMapVariable() returned %q but expected %q", result, expected)
+	}
+}
+
+func TestVariableNamerGetNameForVariable(t *testing.T) {
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Maps: []conf_v1.Map{
+				{
+					Name: "region",
+				},
+			},
+		},
+	}
+	variableNamer := newVariableNamer(&virtualServer)
+
+	tests := []struct {
+		variable string
+		expected string
+	}{
+		{
+			variable: "$region",
+			expected: "$vs_default_cafe_map_region",
+		},
+		{
+			variable: "$request_method",
+			expected: "$request_method",
+		},
+	}
+
+	for _, test := range tests {
+		result := variableNamer.GetNameForVariable(test.variable)
+		if result != test.expected {
+			t.Errorf("GetNameForVariable(%q) returned %q but expected %q", test.variable, result, test.expected)
+		}
+	}
 }
 
 func TestGenerateVirtualServerConfig(t *testing.T) {
@@ -557,7 +605,7 @@ func TestGenerateVirtualServerConfig(t *testing.T) {
 	isResolverConfigured := false
 	tlsPemFileName := ""
 	vsc := newVirtualServerConfigurator(&baseCfgParams, isPlus, isResolverConfigured, &StaticConfigParams{TLSPassthrough: true})
-	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, tlsPemFileName)
+	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, tlsPemFileName, "", nil)
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("GenerateVirtualServerConfig returned \n%+v but expected \n%+v", result, expected)
 	}
@@ -653,7 +701,7 @@ func TestGenerateVirtualServerConfigWithSpiffeCerts(t *testing.T) {
 	tlsPemFileName := ""
 	staticConfigParams := &StaticConfigParams{TLSPassthrough: true, SpiffeCerts: true}
 	vsc := newVirtualServerConfigurator(&baseCfgParams, isPlus, isResolverConfigured, staticConfigParams)
-	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, tlsPemFileName)
+	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, tlsPemFileName, "", nil)
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("GenerateVirtualServerConfig returned \n%+v but expected \n%+v", result, expected)
 	}
@@ -895,7 +943,7 @@ func TestGenerateVirtualServerConfigForVirtualServerWithSplits(t *testing.T) {
 	isResolverConfigured := false
 	tlsPemFileName := ""
 	vsc := newVirtualServerConfigurator(&baseCfgParams, isPlus, isResolverConfigured, &StaticConfigParams{})
-	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, tlsPemFileName)
+	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, tlsPemFileName, "", nil)
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("GenerateVirtualServerConfig returned \n%+v but expected \n%+v", result, expected)
 	}
@@ -1170,7 +1218,7 @@ func TestGenerateVirtualServerConfigForVirtualServerWithMatches(t *testing.T) {
 	isResolverConfigured := false
 	tlsPemFileName := ""
 	vsc := newVirtualServerConfigurator(&baseCfgParams, isPlus, isResolverConfigured, &StaticConfigParams{})
-	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, tlsPemFileName)
+	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, tlsPemFileName, "", nil)
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("GenerateVirtualServerConfig returned \n%+v but expected \n%+v", result, expected)
 	}
@@ -1211,7 +1259,7 @@ func TestGenerateUpstream(t *testing.T) {
 	}
 
 	vsc := newVirtualServerConfigurator(&cfgParams, false, false, &StaticConfigParams{})
-	result := vsc.generateUpstream(&conf_v1.VirtualServer{}, name, upstream, false, endpoints)
+	result := vsc.generateUpstream(&conf_v1.VirtualServer{}, name, upstream, false, endpoints, nil)
 	if !reflect.DeepEqual(result, expected) {
 		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
 	}
@@ -1221,472 +1269,316 @@ func TestGenerateUpstream(t *testing.T) {
 	}
 }
 
-func TestGenerateUpstreamWithKeepalive(t *testing.T) {
+func TestGenerateUpstreamWithBackup(t *testing.T) {
 	name := "test-upstream"
-	noKeepalive := 0
-	keepalive := 32
-	endpoints := []string{
-		"192.168.10.10:8080",
+	upstream := conf_v1.Upstream{Service: name, Port: 80, Backup: "backup-svc", BackupPort: 8080}
+	cfgParams := ConfigParams{
+		LBMethod:    "random two least_conn",
+		MaxFails:    1,
+		FailTimeout: "10s",
 	}
 
 	tests := []struct {
-		upstream  conf_v1.Upstream
-		cfgParams *ConfigParams
-		expected  version2.Upstream
-		msg       string
+		endpoints       []string
+		backupEndpoints []string
+		expectedServers []version2.UpstreamServer
+		expectedLB      string
+		msg             string
 	}{
 		{
-			conf_v1.Upstream{Keepalive: &keepalive, Service: name, Port: 80},
-			&ConfigParams{Keepalive: 21},
-			version2.Upstream{
-				Name: "test-upstream",
-				Servers: []version2.UpstreamServer{
-					{
-						Address: "192.168.10.10:8080",
-					},
-				},
-				Keepalive: 32,
+			endpoints:       []string{"10.0.0.1:80"},
+			backupEndpoints: []string{"10.0.0.2:8080"},
+			expectedServers: []version2.UpstreamServer{
+				{Address: "10.0.0.1:80"},
+				{Address: "10.0.0.2:8080", Backup: true},
 			},
-			"upstream keepalive set, configparam set",
+			expectedLB: "least_conn",
+			msg:        "primary and backup endpoints",
 		},
 		{
-			conf_v1.Upstream{Service: name, Port: 80},
-			&ConfigParams{Keepalive: 21},
-			version2.Upstream{
-				Name: "test-upstream",
-				Servers: []version2.UpstreamServer{
-					{
-						Address: "192.168.10.10:8080",
-					},
-				},
-				Keepalive: 21,
+			endpoints:       []string{nginx502Server},
+			backupEndpoints: []string{"10.0.0.2:8080"},
+			expectedServers: []version2.UpstreamServer{
+				{Address: "10.0.0.2:8080"},
 			},
-			"upstream keepalive not set, configparam set",
+			expectedLB: "random two least_conn",
+			msg:        "no primary endpoints",
 		},
 		{
-			conf_v1.Upstream{Keepalive: &noKeepalive, Service: name, Port: 80},
-			&ConfigParams{Keepalive: 21},
-			version2.Upstream{
-				Name: "test-upstream",
-				Servers: []version2.UpstreamServer{
-					{
-						Address: "192.168.10.10:8080",
-					},
-				},
+			endpoints:       []string{"10.0.0.1:80"},
+			backupEndpoints: nil,
+			expectedServers: []version2.UpstreamServer{
+				{Address: "10.0.0.1:80"},
 			},
-			"upstream keepalive set to 0, configparam set",
+			expectedLB: "random two least_conn",
+			msg:        "no backup endpoints",
 		},
 	}
 
 	for _, test := range tests {
-		vsc := newVirtualServerConfigurator(test.cfgParams, false, false, &StaticConfigParams{})
-		result := vsc.generateUpstream(&conf_v1.VirtualServer{}, name, test.upstream, false, endpoints)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateUpstream() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		vsc := newVirtualServerConfigurator(&cfgParams, false, false, &StaticConfigParams{})
+		result := vsc.generateUpstream(&conf_v1.VirtualServer{}, name, upstream, false, test.endpoints, test.backupEndpoints)
+		if !reflect.DeepEqual(result.Servers, test.expectedServers) {
+			t.Errorf("generateUpstream() returned servers %v but expected %v for the case of %s", result.Servers, test.expectedServers, test.msg)
 		}
-
-		if len(vsc.warnings) != 0 {
-			t.Errorf("generateUpstream() returned warnings for %v", test.upstream)
+		if result.LBMethod != test.expectedLB {
+			t.Errorf("generateUpstream() returned lb method %q but expected %q for the case of %s", result.LBMethod, test.expectedLB, test.msg)
 		}
 	}
 }
 
-func TestGenerateUpstreamForExternalNameService(t *testing.T) {
+func TestGenerateUpstreamWithDown(t *testing.T) {
 	name := "test-upstream"
-	endpoints := []string{"example.com"}
-	upstream := conf_v1.Upstream{Service: name}
 	cfgParams := ConfigParams{}
 
-	expected := version2.Upstream{
-		Name: name,
-		Servers: []version2.UpstreamServer{
-			{
-				Address: "example.com",
-			},
-		},
-		Resolve: true,
-	}
-
-	vsc := newVirtualServerConfigurator(&cfgParams, true, true, &StaticConfigParams{})
-	result := vsc.generateUpstream(&conf_v1.VirtualServer{}, name, upstream, true, endpoints)
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
-	}
-
-	if len(vsc.warnings) != 0 {
-		t.Errorf("generateUpstream() returned warnings for %v", upstream)
-	}
-}
-
-func TestGenerateProxyPass(t *testing.T) {
 	tests := []struct {
-		tlsEnabled   bool
-		upstreamName string
-		internal     bool
-		expected     string
+		down             []string
+		expectedServers  []version2.UpstreamServer
+		expectedWarnings int
+		msg              string
 	}{
 		{
-			tlsEnabled:   false,
-			upstreamName: "test-upstream",
-			internal:     false,
-			expected:     "http://test-upstream",
-		},
-		{
-			tlsEnabled:   true,
-			upstreamName: "test-upstream",
-			internal:     false,
-			expected:     "https://test-upstream",
+			down: []string{"10.0.0.1:80"},
+			expectedServers: []version2.UpstreamServer{
+				{Address: "10.0.0.1:80", Down: true},
+				{Address: "10.0.0.1:81"},
+				{Address: "10.0.0.2:80"},
+				{Address: "10.0.0.3:8080", Backup: true},
+			},
+			msg: "ip and port",
 		},
 		{
-			tlsEnabled:   false,
-			upstreamName: "test-upstream",
-			internal:     true,
-			expected:     "http://test-upstream$request_uri",
+			down: []string{"10.0.0.1", "10.0.0.3"},
+			expectedServers: []version2.UpstreamServer{
+				{Address: "10.0.0.1:80", Down: true},
+				{Address: "10.0.0.1:81", Down: true},
+				{Address: "10.0.0.2:80"},
+				{Address: "10.0.0.3:8080", Backup: true, Down: true},
+			},
+			msg: "ip of primary and backup endpoints",
 		},
 		{
-			tlsEnabled:   true,
-			upstreamName: "test-upstream",
-			internal:     true,
-			expected:     "https://test-upstream$request_uri",
+			down: []string{"10.0.0.2:80", "10.0.0.4"},
+			expectedServers: []version2.UpstreamServer{
+				{Address: "10.0.0.1:80"},
+				{Address: "10.0.0.1:81"},
+				{Address: "10.0.0.2:80", Down: true},
+				{Address: "10.0.0.3:8080", Backup: true},
+			},
+			expectedWarnings: 1,
+			msg:              "address without a matching endpoint",
 		},
 	}
 
 	for _, test := range tests {
-		result := generateProxyPass(test.tlsEnabled, test.upstreamName, test.internal, nil)
-		if result != test.expected {
-			t.Errorf("generateProxyPass(%v, %v, %v) returned %v but expected %v", test.tlsEnabled, test.upstreamName, test.internal, result, test.expected)
+		upstream := conf_v1.Upstream{Name: name, Service: "tea-svc", Port: 80, Backup: "backup-svc", BackupPort: 8080, Down: test.down}
+		vs := &conf_v1.VirtualServer{}
+
+		vsc := newVirtualServerConfigurator(&cfgParams, false, false, &StaticConfigParams{})
+		result := vsc.generateUpstream(vs, name, upstream, false, []string{"10.0.0.1:80", "10.0.0.1:81", "10.0.0.2:80"}, []string{"10.0.0.3:8080"})
+		if !reflect.DeepEqual(result.Servers, test.expectedServers) {
+			t.Errorf("generateUpstream() returned servers %v but expected %v for the case of %s", result.Servers, test.expectedServers, test.msg)
+		}
+		if len(vsc.warnings[vs]) != test.expectedWarnings {
+			t.Errorf("generateUpstream() returned warnings %v but expected %d warnings for the case of %s", vsc.warnings[vs], test.expectedWarnings, test.msg)
 		}
 	}
 }
 
-func TestGenerateProxyPassProtocol(t *testing.T) {
-	tests := []struct {
-		upstream conf_v1.Upstream
-		expected string
-	}{
-		{
-			upstream: conf_v1.Upstream{},
-			expected: "http",
-		},
-		{
-			upstream: conf_v1.Upstream{
-				TLS: conf_v1.UpstreamTLS{
-					Enable: true,
-				},
-			},
-			expected: "https",
+func TestGenerateBackupEndpointsForUpstream(t *testing.T) {
+	vsEx := &VirtualServerEx{
+		Endpoints: map[string][]string{
+			"default/tea-svc:80":      {"10.0.0.1:80"},
+			"default/backup-svc:8080": {"10.0.0.2:8080"},
 		},
 	}
 
-	for _, test := range tests {
-		result := generateProxyPassProtocol(test.upstream.TLS.Enable)
-		if result != test.expected {
-			t.Errorf("generateProxyPassProtocol(%v) returned %v but expected %v", test.upstream.TLS.Enable, result, test.expected)
-		}
-	}
-}
+	upstream := conf_v1.Upstream{Service: "tea-svc", Port: 80, Backup: "backup-svc", BackupPort: 8080}
+	expected := []string{"10.0.0.2:8080"}
 
-func TestGenerateString(t *testing.T) {
-	tests := []struct {
-		inputS   string
-		expected string
-	}{
-		{
-			inputS:   "http_404",
-			expected: "http_404",
-		},
-		{
-			inputS:   "",
-			expected: "error timeout",
-		},
+	result := generateBackupEndpointsForUpstream("default", upstream, vsEx)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateBackupEndpointsForUpstream() returned %v but expected %v", result, expected)
 	}
 
-	for _, test := range tests {
-		result := generateString(test.inputS, "error timeout")
-		if result != test.expected {
-			t.Errorf("generateString() return %v but expected %v", result, test.expected)
-		}
+	result = generateBackupEndpointsForUpstream("default", conf_v1.Upstream{Service: "tea-svc", Port: 80}, vsEx)
+	if result != nil {
+		t.Errorf("generateBackupEndpointsForUpstream() returned %v for an upstream without backup", result)
 	}
 }
 
-func TestGenerateBuffer(t *testing.T) {
+func TestGenerateServicesEndpointsForUpstream(t *testing.T) {
+	vsEx := &VirtualServerEx{
+		Endpoints: map[string][]string{
+			"default/tea-svc:80":    {"10.0.0.1:80", "10.0.0.2:80"},
+			"default/tea-v2-svc:80": {"10.0.0.2:80", "10.0.0.3:80"},
+			"default/tea-v3-svc:80": {},
+		},
+	}
+
 	tests := []struct {
-		inputS   *conf_v1.UpstreamBuffers
-		expected string
+		upstream conf_v1.Upstream
+		expected []string
+		msg      string
 	}{
 		{
-			inputS:   nil,
-			expected: "8 4k",
+			upstream: conf_v1.Upstream{Service: "tea-svc", Port: 80},
+			expected: []string{"10.0.0.1:80", "10.0.0.2:80"},
+			msg:      "no services",
 		},
 		{
-			inputS:   &conf_v1.UpstreamBuffers{Number: 8, Size: "16K"},
-			expected: "8 16K",
+			upstream: conf_v1.Upstream{Service: "tea-svc", Services: []string{"tea-v2-svc", "tea-v3-svc"}, Port: 80},
+			expected: []string{"10.0.0.1:80", "10.0.0.2:80", "10.0.0.3:80"},
+			msg:      "services with a shared endpoint",
+		},
+		{
+			upstream: conf_v1.Upstream{Service: "coffee-svc", Services: []string{"tea-v2-svc"}, Port: 80},
+			expected: []string{"10.0.0.2:80", "10.0.0.3:80"},
+			msg:      "service without endpoints",
 		},
 	}
 
 	for _, test := range tests {
-		result := generateBuffers(test.inputS, "8 4k")
-		if result != test.expected {
-			t.Errorf("generateBuffer() return %v but expected %v", result, test.expected)
+		result := generateServicesEndpointsForUpstream("default", test.upstream, vsEx)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateServicesEndpointsForUpstream() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
 		}
 	}
 }
 
-func TestGenerateLocationForProxying(t *testing.T) {
-	cfgParams := ConfigParams{
-		ProxyConnectTimeout:  "30s",
-		ProxyReadTimeout:     "31s",
-		ProxySendTimeout:     "32s",
-		ClientMaxBodySize:    "1m",
-		ProxyMaxTempFileSize: "1024m",
-		ProxyBuffering:       true,
-		ProxyBuffers:         "8 4k",
-		ProxyBufferSize:      "4k",
-		LocationSnippets:     []string{"# location snippet"},
-	}
-	path := "/"
-	upstreamName := "test-upstream"
+func TestGenerateUpstreamWithMissingConnectionLimitPolicy(t *testing.T) {
+	upstream := conf_v1.Upstream{Name: "tea", Service: "tea-svc", Port: 80, ConnectionLimitPolicy: "shared-backend"}
+	vs := &conf_v1.VirtualServer{}
 
-	expected := version2.Location{
-		Path:                     "/",
-		Snippets:                 []string{"# location snippet"},
-		ProxyConnectTimeout:      "30s",
-		ProxyReadTimeout:         "31s",
-		ProxySendTimeout:         "32s",
-		ClientMaxBodySize:        "1m",
-		ProxyMaxTempFileSize:     "1024m",
-		ProxyBuffering:           true,
-		ProxyBuffers:             "8 4k",
-		ProxyBufferSize:          "4k",
-		ProxyPass:                "http://test-upstream",
-		ProxyNextUpstream:        "error timeout",
-		ProxyNextUpstreamTimeout: "0s",
-		ProxyNextUpstreamTries:   0,
-		ProxyPassRequestHeaders:  true,
-	}
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{})
+	vsc.generateUpstream(vs, "vs_default_cafe_tea", upstream, false, nil, nil)
 
-	result := generateLocationForProxying(path, upstreamName, conf_v1.Upstream{}, &cfgParams, nil, false, 0, "", nil, "")
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("generateLocationForProxying() returned \n%v but expected \n%v", result, expected)
+	if len(vsc.warnings[vs]) != 1 {
+		t.Errorf("generateUpstream() returned %d warnings but expected 1", len(vsc.warnings[vs]))
 	}
 }
 
-func TestGenerateReturnBlock(t *testing.T) {
+func TestGenerateCacheZone(t *testing.T) {
 	tests := []struct {
-		text        string
-		code        int
-		defaultCode int
-		expected    *version2.Return
+		cache    *conf_v1.UpstreamCache
+		expected version2.CacheZone
+		msg      string
 	}{
 		{
-			text:        "Hello World!",
-			code:        0, // Not set
-			defaultCode: 200,
-			expected: &version2.Return{
-				Code: 200,
-				Text: "Hello World!",
+			cache: &conf_v1.UpstreamCache{},
+			expected: version2.CacheZone{
+				Name: "vs_default_cafe_tea",
+				Path: "/var/cache/nginx/vs_default_cafe_tea",
+				Size: "10m",
 			},
+			msg: "default zone size",
 		},
 		{
-			text:        "Hello World!",
-			code:        400,
-			defaultCode: 200,
-			expected: &version2.Return{
-				Code: 400,
-				Text: "Hello World!",
+			cache: &conf_v1.UpstreamCache{ZoneSize: "20m"},
+			expected: version2.CacheZone{
+				Name: "vs_default_cafe_tea",
+				Path: "/var/cache/nginx/vs_default_cafe_tea",
+				Size: "20m",
 			},
+			msg: "custom zone size",
 		},
 	}
 
 	for _, test := range tests {
-		result := generateReturnBlock(test.text, test.code, test.defaultCode)
+		result := generateCacheZone("vs_default_cafe_tea", test.cache)
 		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateReturnBlock() returned %v but expected %v", result, test.expected)
+			t.Errorf("generateCacheZone() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
 		}
 	}
-
 }
 
-func TestGenerateLocationForReturnBlock(t *testing.T) {
-	cfgParams := ConfigParams{
-		LocationSnippets: []string{"# location snippet"},
-	}
-	defaultType := "application/json"
-	path := "/"
-	returnBlock := &version2.Return{
-		Code: 301,
-		Text: "http://www.nginx.com",
-	}
-
-	expected := version2.Location{
-		Path:        "/",
-		Snippets:    []string{"# location snippet"},
-		DefaultType: defaultType,
-		Return: &version2.Return{
-			Text: "http://www.nginx.com",
-			Code: 301,
-		},
-	}
-
-	result := generateLocationForReturnBlock(path, cfgParams.LocationSnippets, returnBlock, defaultType)
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("generateLocationForReturnBlock() returned %v but expected %v", result, expected)
-	}
-}
-
-func TestGenerateSSLConfig(t *testing.T) {
+func TestGenerateProxyCache(t *testing.T) {
 	tests := []struct {
-		inputTLS            *conf_v1.TLS
-		inputTLSPemFileName string
-		inputCfgParams      *ConfigParams
-		expected            *version2.SSL
-		msg                 string
+		cache    *conf_v1.UpstreamCache
+		expected *version2.ProxyCache
+		msg      string
 	}{
 		{
-			inputTLS:            nil,
-			inputTLSPemFileName: "",
-			inputCfgParams:      &ConfigParams{},
-			expected:            nil,
-			msg:                 "no TLS field",
-		},
-		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "",
-			},
-			inputTLSPemFileName: "",
-			inputCfgParams:      &ConfigParams{},
-			expected:            nil,
-			msg:                 "TLS field with empty secret",
+			cache:    nil,
+			expected: nil,
+			msg:      "no cache",
 		},
 		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "secret",
-			},
-			inputTLSPemFileName: "",
-			inputCfgParams:      &ConfigParams{},
-			expected: &version2.SSL{
-				HTTP2:          false,
-				Certificate:    pemFileNameForMissingTLSSecret,
-				CertificateKey: pemFileNameForMissingTLSSecret,
-				Ciphers:        "NULL",
+			cache: &conf_v1.UpstreamCache{},
+			expected: &version2.ProxyCache{
+				Zone: "vs_default_cafe_tea",
 			},
-			msg: "secret doesn't exist in the cluster with HTTP2",
+			msg: "cache with default parameters",
 		},
 		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "secret",
+			cache: &conf_v1.UpstreamCache{
+				CacheLock:        true,
+				CacheLockTimeout: "10s",
+				BackgroundUpdate: true,
 			},
-			inputTLSPemFileName: "secret.pem",
-			inputCfgParams:      &ConfigParams{},
-			expected: &version2.SSL{
-				HTTP2:          false,
-				Certificate:    "secret.pem",
-				CertificateKey: "secret.pem",
-				Ciphers:        "",
+			expected: &version2.ProxyCache{
+				Zone:             "vs_default_cafe_tea",
+				Lock:             true,
+				LockTimeout:      "10s",
+				BackgroundUpdate: true,
 			},
-			msg: "normal case with HTTP2",
-		},
-	}
-
-	for _, test := range tests {
-		result := generateSSLConfig(test.inputTLS, test.inputTLSPemFileName, test.inputCfgParams)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateSSLConfig() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
-		}
-	}
-}
-
-func TestGenerateRedirectConfig(t *testing.T) {
-	tests := []struct {
-		inputTLS *conf_v1.TLS
-		expected *version2.TLSRedirect
-		msg      string
-	}{
-		{
-			inputTLS: nil,
-			expected: nil,
-			msg:      "no TLS field",
+			msg: "cache with lock and background update",
 		},
 		{
-			inputTLS: &conf_v1.TLS{
-				Secret:   "secret",
-				Redirect: nil,
+			cache: &conf_v1.UpstreamCache{
+				Valid: map[string]string{
+					"any": "5s",
+					"404": "1m",
+					"200": "10m",
+					"301": "1h",
+				},
 			},
-			expected: nil,
-			msg:      "no redirect field",
-		},
-		{
-			inputTLS: &conf_v1.TLS{
-				Secret:   "secret",
-				Redirect: &conf_v1.TLSRedirect{Enable: false},
+			expected: &version2.ProxyCache{
+				Zone: "vs_default_cafe_tea",
+				Valid: []version2.ProxyCacheValid{
+					{Code: "200", Time: "10m"},
+					{Code: "301", Time: "1h"},
+					{Code: "404", Time: "1m"},
+					{Code: "any", Time: "5s"},
+				},
 			},
-			expected: nil,
-			msg:      "redirect disabled",
+			msg: "cache with validity times for several codes and any code",
 		},
 		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "secret",
-				Redirect: &conf_v1.TLSRedirect{
-					Enable: true,
+			cache: &conf_v1.UpstreamCache{
+				Valid: map[string]string{
+					"200": "10m",
 				},
 			},
-			expected: &version2.TLSRedirect{
-				Code:    301,
-				BasedOn: "$scheme",
+			expected: &version2.ProxyCache{
+				Zone: "vs_default_cafe_tea",
+				Valid: []version2.ProxyCacheValid{
+					{Code: "200", Time: "10m"},
+				},
 			},
-			msg: "normal case with defaults",
+			msg: "cache with a validity time for one code",
 		},
 		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "secret",
-				Redirect: &conf_v1.TLSRedirect{
-					Enable:  true,
-					BasedOn: "x-forwarded-proto",
-				},
+			cache: &conf_v1.UpstreamCache{
+				Key: "${scheme}${host}${request_uri}${http_authorization}",
 			},
-			expected: &version2.TLSRedirect{
-				Code:    301,
-				BasedOn: "$http_x_forwarded_proto",
+			expected: &version2.ProxyCache{
+				Zone: "vs_default_cafe_tea",
+				Key:  "${scheme}${host}${request_uri}${http_authorization}",
 			},
-			msg: "normal case with BasedOn set",
+			msg: "cache with a key",
 		},
 	}
 
 	for _, test := range tests {
-		result := generateTLSRedirectConfig(test.inputTLS)
+		result := generateProxyCache("vs_default_cafe_tea", test.cache)
 		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateTLSRedirectConfig() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
-		}
-	}
-}
-
-func TestGenerateTLSRedirectBasedOn(t *testing.T) {
-	tests := []struct {
-		basedOn  string
-		expected string
-	}{
-		{
-			basedOn:  "scheme",
-			expected: "$scheme",
-		},
-		{
-			basedOn:  "x-forwarded-proto",
-			expected: "$http_x_forwarded_proto",
-		},
-		{
-			basedOn:  "",
-			expected: "$scheme",
-		},
-	}
-	for _, test := range tests {
-		result := generateTLSRedirectBasedOn(test.basedOn)
-		if result != test.expected {
-			t.Errorf("generateTLSRedirectBasedOn(%v) returned %v but expected %v", test.basedOn, result, test.expected)
+			t.Errorf("generateProxyCache() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
 		}
 	}
 }
 
-func TestCreateUpstreamsForPlus(t *testing.T) {
+func TestGenerateVirtualServerConfigWithCache(t *testing.T) {
 	virtualServerEx := VirtualServerEx{
 		VirtualServer: &conf_v1.VirtualServer{
 			ObjectMeta: meta_v1.ObjectMeta{
@@ -1700,21 +1592,14 @@ func TestCreateUpstreamsForPlus(t *testing.T) {
 						Name:    "tea",
 						Service: "tea-svc",
 						Port:    80,
+						Cache: &conf_v1.UpstreamCache{
+							CacheLock:        true,
+							BackgroundUpdate: true,
+						},
 					},
 					{
-						Name:    "test",
-						Service: "test-svc",
-						Port:    80,
-					},
-					{
-						Name:        "subselector-test",
-						Service:     "test-svc",
-						Subselector: map[string]string{"vs": "works"},
-						Port:        80,
-					},
-					{
-						Name:    "external",
-						Service: "external-svc",
+						Name:    "coffee",
+						Service: "coffee-svc",
 						Port:    80,
 					},
 				},
@@ -1726,170 +1611,3892 @@ func TestCreateUpstreamsForPlus(t *testing.T) {
 						},
 					},
 					{
-						Path:  "/coffee",
-						Route: "default/coffee",
-					},
-					{
-						Path: "/external",
+						Path: "/coffee",
 						Action: &conf_v1.Action{
-							Pass: "external",
+							Pass: "coffee",
 						},
 					},
 				},
 			},
 		},
-		Endpoints: map[string][]string{
-			"default/tea-svc:80": {
-				"10.0.0.20:80",
-			},
-			"default/test-svc:80": {},
-			"default/test-svc_vs=works:80": {
-				"10.0.0.30:80",
-			},
-			"default/coffee-svc:80": {
-				"10.0.0.40:80",
-			},
-			"default/test-svc_vsr=works:80": {
-				"10.0.0.50:80",
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{})
+	result, _ := vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+
+	expectedCacheZones := []version2.CacheZone{
+		{
+			Name: "vs_default_cafe_tea",
+			Path: "/var/cache/nginx/vs_default_cafe_tea",
+			Size: "10m",
+		},
+	}
+	if !reflect.DeepEqual(result.CacheZones, expectedCacheZones) {
+		t.Errorf("GenerateVirtualServerConfig() returned cache zones %v but expected %v", result.CacheZones, expectedCacheZones)
+	}
+
+	expectedProxyCaches := map[string]*version2.ProxyCache{
+		"/tea": {
+			Zone:             "vs_default_cafe_tea",
+			Lock:             true,
+			BackgroundUpdate: true,
+		},
+		"/coffee": nil,
+	}
+	for _, loc := range result.Server.Locations {
+		if !reflect.DeepEqual(loc.ProxyCache, expectedProxyCaches[loc.Path]) {
+			t.Errorf("GenerateVirtualServerConfig() returned the cache %v for the location %v but expected %v", loc.ProxyCache, loc.Path, expectedProxyCaches[loc.Path])
+		}
+	}
+}
+
+func TestGenerateLimitConn(t *testing.T) {
+	policies := []ConnectionLimitPolicy{
+		{Name: "shared-backend", Limit: 100},
+		{Name: "legacy", Limit: 10},
+	}
+
+	tests := []struct {
+		policyName string
+		expected   *version2.LimitConn
+		msg        string
+	}{
+		{
+			policyName: "",
+			expected:   nil,
+			msg:        "no policy",
+		},
+		{
+			policyName: "legacy",
+			expected: &version2.LimitConn{
+				Zone:  "conn_limit_legacy",
+				Limit: 10,
 			},
-			"default/external-svc:80": {
-				"example.com:80",
+			msg: "existing policy",
+		},
+		{
+			policyName: "missing",
+			expected:   nil,
+			msg:        "missing policy",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateLimitConn(test.policyName, policies)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateLimitConn() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestAppendLimitConns(t *testing.T) {
+	upstreamLimit := version2.LimitConn{Zone: "conn_limit_legacy", Limit: 10}
+	policyLimit := version2.LimitConn{Zone: "pol_cl_default_limit_default_cafe", Limit: 2}
+
+	// a policy that limits the same zone as the upstream doesn't add another limit_conn directive
+	result := appendLimitConns([]version2.LimitConn{upstreamLimit}, policyLimit, version2.LimitConn{Zone: "conn_limit_legacy", Limit: 5})
+	expected := []version2.LimitConn{upstreamLimit, policyLimit}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("appendLimitConns() returned %+v but expected %+v", result, expected)
+	}
+
+	if result := appendLimitConns(nil); result != nil {
+		t.Errorf("appendLimitConns() returned %+v but expected nil for no limits", result)
+	}
+}
+
+func TestGenerateMaps(t *testing.T) {
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	variableNamer := newVariableNamer(&virtualServer)
+
+	maps := []conf_v1.Map{
+		{
+			Name:   "region",
+			Source: "$http_x_country",
+			Mappings: []conf_v1.MapMapping{
+				{
+					Value:  "US",
+					Result: "us",
+				},
+				{
+					Value:  "default",
+					Result: "special",
+				},
 			},
+			Default: "eu",
 		},
-		ExternalNameSvcs: map[string]bool{
-			"default/external-svc": true,
+		{
+			Name:   "tenant",
+			Source: "$host",
+			Mappings: []conf_v1.MapMapping{
+				{
+					Value:  "*.example.com",
+					Result: "example",
+				},
+				{
+					Value:  "cafe.example.*",
+					Result: "cafe",
+				},
+			},
 		},
-		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
-			{
-				ObjectMeta: meta_v1.ObjectMeta{
-					Name:      "coffee",
-					Namespace: "default",
+	}
+
+	expected := []version2.Map{
+		{
+			Source:   "$http_x_country",
+			Variable: "$vs_default_cafe_map_region",
+			Parameters: []version2.Parameter{
+				{
+					Value:  `"US"`,
+					Result: `"us"`,
+				},
+				{
+					Value:  `\default`,
+					Result: `"special"`,
+				},
+				{
+					Value:  "default",
+					Result: `"eu"`,
+				},
+			},
+		},
+		{
+			Source:    "$host",
+			Variable:  "$vs_default_cafe_map_tenant",
+			Hostnames: true,
+			Parameters: []version2.Parameter{
+				{
+					Value:  `"*.example.com"`,
+					Result: `"example"`,
+				},
+				{
+					Value:  `"cafe.example.*"`,
+					Result: `"cafe"`,
+				},
+				{
+					Value:  "default",
+					Result: `""`,
+				},
+			},
+		},
+	}
+
+	result := generateMaps(maps, variableNamer)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateMaps() returned \n%+v but expected \n%+v", result, expected)
+	}
+}
+
+func TestGenerateVirtualServerConfigWithMaps(t *testing.T) {
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Maps: []conf_v1.Map{
+					{
+						Name:   "region",
+						Source: "$http_x_country",
+						Mappings: []conf_v1.MapMapping{
+							{
+								Value:  "US",
+								Result: "us",
+							},
+						},
+						Default: "eu",
+					},
+				},
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Matches: []conf_v1.Match{
+							{
+								Conditions: []conf_v1.Condition{
+									{
+										Variable: "$region",
+										Value:    "us",
+									},
+								},
+								Action: &conf_v1.Action{
+									Pass: "tea",
+								},
+							},
+						},
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{})
+	result, _ := vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+
+	if len(result.Maps) == 0 || result.Maps[0].Variable != "$vs_default_cafe_map_region" {
+		t.Fatalf("GenerateVirtualServerConfig() returned maps %+v without the map of the VirtualServer first", result.Maps)
+	}
+
+	expectedSource := "$vs_default_cafe_map_region"
+	found := false
+	for _, m := range result.Maps[1:] {
+		if m.Source == expectedSource {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("GenerateVirtualServerConfig() returned maps %+v without a condition map with the source %q", result.Maps, expectedSource)
+	}
+}
+
+func TestGenerateVirtualServerConfigWithUpstreamMaps(t *testing.T) {
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Maps: []conf_v1.Map{
+					{
+						Name:   "backend",
+						Source: "$http_x_backend",
+						Mappings: []conf_v1.MapMapping{
+							{
+								Value:  "v2",
+								Result: "tea-v2",
+							},
+							{
+								Value:  "beta",
+								Result: "tea-v2",
+							},
+						},
+						Default: "tea-v1",
+					},
+				},
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:             "tea-v1",
+						Service:          "tea-v1-svc",
+						Port:             80,
+						ProxyReadTimeout: "30s",
+					},
+					{
+						Name:    "tea-v2",
+						Service: "tea-v2-svc",
+						Port:    80,
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Action: &conf_v1.Action{
+							Pass: "$backend",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expectedMap := version2.Map{
+		Source:   "$vs_default_cafe_map_backend",
+		Variable: "$vs_default_cafe_map_backend_upstream",
+		Parameters: []version2.Parameter{
+			{
+				Value:  `"tea-v2"`,
+				Result: "vs_default_cafe_tea-v2",
+			},
+			{
+				Value:  "default",
+				Result: "vs_default_cafe_tea-v1",
+			},
+		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{})
+	result, _ := vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+
+	found := false
+	for _, m := range result.Maps {
+		if m.Variable == expectedMap.Variable {
+			found = true
+			if !reflect.DeepEqual(m, expectedMap) {
+				t.Errorf("GenerateVirtualServerConfig() returned map %+v but expected %+v", m, expectedMap)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("GenerateVirtualServerConfig() returned maps %+v without the map %v", result.Maps, expectedMap.Variable)
+	}
+
+	if len(result.Server.Locations) != 1 {
+		t.Fatalf("GenerateVirtualServerConfig() returned %d locations but expected 1", len(result.Server.Locations))
+	}
+
+	loc := result.Server.Locations[0]
+	if loc.ProxyPass != "http://$vs_default_cafe_map_backend_upstream" {
+		t.Errorf("GenerateVirtualServerConfig() returned a location with proxy_pass %q but expected %q", loc.ProxyPass, "http://$vs_default_cafe_map_backend_upstream")
+	}
+	// the location is configured with the default upstream of the map
+	if loc.ProxyReadTimeout != "30s" {
+		t.Errorf("GenerateVirtualServerConfig() returned a location with proxy_read_timeout %q but expected %q", loc.ProxyReadTimeout, "30s")
+	}
+}
+
+func TestGenerateVirtualServerConfigWithRouteSplits(t *testing.T) {
+	newVSR := func(name string, service string) *conf_v1.VirtualServerRoute {
+		return &conf_v1.VirtualServerRoute{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerRouteSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "coffee",
+						Service: service,
+						Port:    80,
+					},
+				},
+				Subroutes: []conf_v1.Route{
+					{
+						Path: "/coffee",
+						Action: &conf_v1.Action{
+							Pass: "coffee",
+						},
+					},
+					{
+						Path: "=/coffee/latte",
+						Action: &conf_v1.Action{
+							Pass: "coffee",
+						},
+					},
+				},
+			},
+		}
+	}
+
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Routes: []conf_v1.Route{
+					{
+						Path: "/coffee",
+						Splits: []conf_v1.Split{
+							{
+								Weight: 90,
+								Route:  "coffee-v1",
+							},
+							{
+								Weight: 10,
+								Route:  "default/coffee-v2",
+							},
+						},
+					},
+				},
+			},
+		},
+		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+			newVSR("coffee-v1", "coffee-v1-svc"),
+			newVSR("coffee-v2", "coffee-v2-svc"),
+		},
+	}
+
+	expectedSplitClients := []version2.SplitClient{
+		{
+			Source:   "$request_id",
+			Variable: "$vs_default_cafe_splits_0",
+			Distributions: []version2.Distribution{
+				{
+					Weight: "90%",
+					Value:  "/internal_location_route_splits_0_split_0",
+				},
+				{
+					Weight: "10%",
+					Value:  "/internal_location_route_splits_0_split_1",
+				},
+			},
+		},
+	}
+	expectedInternalRedirectLocations := []version2.InternalRedirectLocation{
+		{
+			Path:        "/coffee",
+			Destination: "$vs_default_cafe_splits_0$uri",
+		},
+	}
+	expectedLocations := map[string]string{
+		"^~ /internal_location_route_splits_0_split_0":             "",
+		"^~ /internal_location_route_splits_0_split_1":             "",
+		"^~ /internal_location_route_splits_0_split_0/coffee":      "http://vs_default_cafe_vsr_default_coffee-v1_coffee$request_uri",
+		"= /internal_location_route_splits_0_split_0/coffee/latte": "http://vs_default_cafe_vsr_default_coffee-v1_coffee$request_uri",
+		"^~ /internal_location_route_splits_0_split_1/coffee":      "http://vs_default_cafe_vsr_default_coffee-v2_coffee$request_uri",
+		"= /internal_location_route_splits_0_split_1/coffee/latte": "http://vs_default_cafe_vsr_default_coffee-v2_coffee$request_uri",
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{})
+	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+
+	if len(warnings) != 0 {
+		t.Errorf("GenerateVirtualServerConfig() returned unexpected warnings: %v", warnings)
+	}
+	if !reflect.DeepEqual(result.SplitClients, expectedSplitClients) {
+		t.Errorf("GenerateVirtualServerConfig() returned split clients %+v but expected %+v", result.SplitClients, expectedSplitClients)
+	}
+	if !reflect.DeepEqual(result.Server.InternalRedirectLocations, expectedInternalRedirectLocations) {
+		t.Errorf("GenerateVirtualServerConfig() returned internal redirect locations %+v but expected %+v",
+			result.Server.InternalRedirectLocations, expectedInternalRedirectLocations)
+	}
+
+	if len(result.Server.Locations) != len(expectedLocations) {
+		t.Fatalf("GenerateVirtualServerConfig() returned %d locations but expected %d", len(result.Server.Locations), len(expectedLocations))
+	}
+	for _, loc := range result.Server.Locations {
+		proxyPass, ok := expectedLocations[loc.Path]
+		if !ok {
+			t.Errorf("GenerateVirtualServerConfig() returned an unexpected location %q", loc.Path)
+			continue
+		}
+		if !loc.Internal {
+			t.Errorf("GenerateVirtualServerConfig() returned the location %q that is not internal", loc.Path)
+		}
+		if loc.ProxyPass != proxyPass {
+			t.Errorf("GenerateVirtualServerConfig() returned the location %q with proxy_pass %q but expected %q", loc.Path, loc.ProxyPass, proxyPass)
+		}
+		if proxyPass == "" && (loc.Return == nil || loc.Return.Code != 404) {
+			t.Errorf("GenerateVirtualServerConfig() returned the catch-all location %q with return %+v but expected 404", loc.Path, loc.Return)
+		}
+	}
+}
+
+func TestGenerateRouteSplitPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{
+			path:     "/coffee",
+			expected: "^~ /internal_location_route_splits_0_split_1/coffee",
+		},
+		{
+			path:     "=/coffee",
+			expected: "= /internal_location_route_splits_0_split_1/coffee",
+		},
+		{
+			path:     "= /coffee",
+			expected: "= /internal_location_route_splits_0_split_1/coffee",
+		},
+		{
+			path:     "",
+			expected: "^~ /internal_location_route_splits_0_split_1",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateRouteSplitPath("/internal_location_route_splits_0_split_1", test.path)
+		if result != test.expected {
+			t.Errorf("generateRouteSplitPath(%q) returned %q but expected %q", test.path, result, test.expected)
+		}
+	}
+}
+
+func TestGetUpstreamMapNames(t *testing.T) {
+	routes := []conf_v1.Route{
+		{
+			Path: "/tea",
+			Action: &conf_v1.Action{
+				Pass: "$backend",
+			},
+		},
+		{
+			Path: "/coffee",
+			Splits: []conf_v1.Split{
+				{
+					Weight: 90,
+					Action: &conf_v1.Action{
+						Pass: "coffee",
+					},
+				},
+				{
+					Weight: 10,
+					Action: &conf_v1.Action{
+						Proxy: &conf_v1.ActionProxy{
+							Upstream: "$canary",
+						},
+					},
+				},
+			},
+		},
+		{
+			Path: "/juice",
+			Matches: []conf_v1.Match{
+				{
+					Action: &conf_v1.Action{
+						Pass: "$tenant",
+					},
+				},
+			},
+			Action: &conf_v1.Action{
+				Redirect: &conf_v1.ActionRedirect{
+					URL: "http://example.com",
+				},
+			},
+		},
+	}
+	expected := map[string]bool{
+		"backend": true,
+		"canary":  true,
+		"tenant":  true,
+	}
+
+	result := GetUpstreamMapNames(routes)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("GetUpstreamMapNames() returned %v but expected %v", result, expected)
+	}
+}
+
+func TestGenerateVirtualServerConfigWithServerTokens(t *testing.T) {
+	tests := []struct {
+		serverTokens         string
+		globalServerTokens   string
+		expectedServerTokens string
+	}{
+		{
+			serverTokens:         "",
+			globalServerTokens:   "on",
+			expectedServerTokens: "on",
+		},
+		{
+			serverTokens:         "off",
+			globalServerTokens:   "on",
+			expectedServerTokens: "off",
+		},
+		{
+			serverTokens:         "build",
+			globalServerTokens:   "off",
+			expectedServerTokens: "build",
+		},
+		{
+			serverTokens:         "My Server",
+			globalServerTokens:   "off",
+			expectedServerTokens: "My Server",
+		},
+	}
+
+	for _, test := range tests {
+		virtualServerEx := VirtualServerEx{
+			VirtualServer: &conf_v1.VirtualServer{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "cafe",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerSpec{
+					Host:         "cafe.example.com",
+					ServerTokens: test.serverTokens,
+				},
+			},
+		}
+
+		vsc := newVirtualServerConfigurator(&ConfigParams{ServerTokens: test.globalServerTokens}, true, false, &StaticConfigParams{})
+		result, _ := vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+
+		if result.Server.ServerTokens != test.expectedServerTokens {
+			t.Errorf("GenerateVirtualServerConfig() returned server tokens %q but expected %q for server tokens %q and global server tokens %q",
+				result.Server.ServerTokens, test.expectedServerTokens, test.serverTokens, test.globalServerTokens)
+		}
+	}
+}
+
+func TestGenerateVirtualServerConfigWithRequestBuffering(t *testing.T) {
+	requestBufferingOn := true
+	requestBufferingOff := false
+
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/upload",
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+						RequestBuffering: &requestBufferingOff,
+					},
+					{
+						Path: "/tea",
+						Matches: []conf_v1.Match{
+							{
+								Conditions: []conf_v1.Condition{
+									{
+										Header: "x-version",
+										Value:  "v2",
+									},
+								},
+								Action: &conf_v1.Action{
+									Pass: "tea",
+								},
+							},
+						},
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+						RequestBuffering: &requestBufferingOn,
+					},
+					{
+						Path: "/coffee",
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{})
+	result, _ := vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+
+	if len(result.Server.Locations) != 4 {
+		t.Fatalf("GenerateVirtualServerConfig() returned %d locations but expected 4", len(result.Server.Locations))
+	}
+
+	for _, loc := range result.Server.Locations {
+		// the locations of the matches of the /tea route are internal
+		expected := "on"
+		switch loc.Path {
+		case "/upload":
+			expected = "off"
+		case "/coffee":
+			expected = ""
+		}
+
+		if loc.ProxyRequestBuffering != expected {
+			t.Errorf("GenerateVirtualServerConfig() returned request buffering %q for the location %v but expected %q", loc.ProxyRequestBuffering, loc.Path, expected)
+		}
+	}
+}
+
+func TestGenerateVirtualServerConfigWithSnippets(t *testing.T) {
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host:           "cafe.example.com",
+				ServerSnippets: "add_header X-Server cafe;",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+						LocationSnippets: "add_header X-Location tea;",
+					},
+					{
+						Path: "/coffee",
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cfgParams := &ConfigParams{
+		ServerSnippets:   []string{"# server snippet from the ConfigMap"},
+		LocationSnippets: []string{"# location snippet from the ConfigMap"},
+	}
+
+	vsc := newVirtualServerConfigurator(cfgParams, false, false, &StaticConfigParams{})
+	result, _ := vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+
+	expectedServerSnippets := []string{"# server snippet from the ConfigMap", "add_header X-Server cafe;"}
+	if !reflect.DeepEqual(result.Server.Snippets, expectedServerSnippets) {
+		t.Errorf("GenerateVirtualServerConfig() returned server snippets %v but expected %v", result.Server.Snippets, expectedServerSnippets)
+	}
+
+	expectedLocationSnippets := map[string][]string{
+		"/tea":    {"# location snippet from the ConfigMap", "add_header X-Location tea;"},
+		"/coffee": {"# location snippet from the ConfigMap"},
+	}
+	for _, loc := range result.Server.Locations {
+		if !reflect.DeepEqual(loc.Snippets, expectedLocationSnippets[loc.Path]) {
+			t.Errorf("GenerateVirtualServerConfig() returned snippets %v for the location %v but expected %v", loc.Snippets, loc.Path, expectedLocationSnippets[loc.Path])
+		}
+	}
+
+	// the snippets of the resource must not change the snippets from the ConfigMap
+	if len(cfgParams.ServerSnippets) != 1 || len(cfgParams.LocationSnippets) != 1 {
+		t.Errorf("GenerateVirtualServerConfig() changed the snippets of the ConfigParams: %v, %v", cfgParams.ServerSnippets, cfgParams.LocationSnippets)
+	}
+}
+
+func TestGenerateVirtualServerConfigWithAllowedMethods(t *testing.T) {
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+						AllowedMethods: []string{"GET"},
+					},
+					{
+						Path: "/tea-matches",
+						Matches: []conf_v1.Match{
+							{
+								Conditions: []conf_v1.Condition{
+									{
+										Header: "x-version",
+										Value:  "v2",
+									},
+								},
+								Action: &conf_v1.Action{
+									Pass: "tea",
+								},
+							},
+						},
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+						AllowedMethods: []string{"POST", "PUT"},
+					},
+					{
+						Path: "/coffee",
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{})
+	result, _ := vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+
+	expectedLocationMethods := map[string]*version2.AllowedMethods{
+		"/tea": {
+			Pattern: "GET|HEAD",
+			Header:  "GET, HEAD",
+		},
+		"/coffee": nil,
+	}
+	for _, loc := range result.Server.Locations {
+		expected, ok := expectedLocationMethods[loc.Path]
+		if !ok {
+			if loc.AllowedMethods != nil {
+				t.Errorf("GenerateVirtualServerConfig() returned allowed methods %+v for the internal location %v", loc.AllowedMethods, loc.Path)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(loc.AllowedMethods, expected) {
+			t.Errorf("GenerateVirtualServerConfig() returned allowed methods %+v for the location %v but expected %+v", loc.AllowedMethods, loc.Path, expected)
+		}
+	}
+
+	expectedRedirectMethods := &version2.AllowedMethods{
+		Pattern: "POST|PUT",
+		Header:  "POST, PUT",
+	}
+	if len(result.Server.InternalRedirectLocations) != 1 {
+		t.Fatalf("GenerateVirtualServerConfig() returned %d internal redirect locations but expected 1", len(result.Server.InternalRedirectLocations))
+	}
+	if !reflect.DeepEqual(result.Server.InternalRedirectLocations[0].AllowedMethods, expectedRedirectMethods) {
+		t.Errorf("GenerateVirtualServerConfig() returned allowed methods %+v for the internal redirect location but expected %+v",
+			result.Server.InternalRedirectLocations[0].AllowedMethods, expectedRedirectMethods)
+	}
+}
+
+func TestGenerateSubFilter(t *testing.T) {
+	tests := []struct {
+		subFilter *conf_v1.SubFilter
+		expected  *version2.SubFilter
+		msg       string
+	}{
+		{
+			subFilter: nil,
+			expected:  nil,
+			msg:       "no sub filter",
+		},
+		{
+			subFilter: &conf_v1.SubFilter{
+				Substitutions: []conf_v1.Substitution{
+					{
+						From: "http://backend.local",
+						To:   "https://example.com",
+					},
+				},
+			},
+			expected: &version2.SubFilter{
+				Substitutions: []version2.Substitution{
+					{
+						From: "http://backend.local",
+						To:   "https://example.com",
+					},
+				},
+				Once: true,
+			},
+			msg: "single substitution",
+		},
+		{
+			subFilter: &conf_v1.SubFilter{
+				Substitutions: []conf_v1.Substitution{
+					{
+						From: "http://backend.local",
+						To:   "https://example.com",
+					},
+					{
+						From: "backend.local",
+						To:   "example.com",
+					},
+				},
+				Replace: "all",
+				Types:   []string{"text/css", "application/javascript"},
+			},
+			expected: &version2.SubFilter{
+				Substitutions: []version2.Substitution{
+					{
+						From: "http://backend.local",
+						To:   "https://example.com",
+					},
+					{
+						From: "backend.local",
+						To:   "example.com",
+					},
+				},
+				Once:  false,
+				Types: "text/css application/javascript",
+			},
+			msg: "multiple substitutions",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateSubFilter(test.subFilter)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateSubFilter() returned %+v but expected %+v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestAddSubFilterToLocations(t *testing.T) {
+	locations := []version2.Location{
+		{
+			Path: "/internal_location_splits_0_split_0",
+		},
+		{
+			Path: "/internal_location_splits_0_split_1",
+		},
+	}
+	subFilter := &conf_v1.SubFilter{
+		Substitutions: []conf_v1.Substitution{
+			{
+				From: "http://backend.local",
+				To:   "https://example.com",
+			},
+		},
+	}
+
+	addSubFilterToLocations(locations, subFilter)
+
+	expected := generateSubFilter(subFilter)
+	for _, loc := range locations {
+		if !reflect.DeepEqual(loc.SubFilter, expected) {
+			t.Errorf("addSubFilterToLocations() set %+v for the location %v but expected %+v", loc.SubFilter, loc.Path, expected)
+		}
+	}
+}
+
+func TestGenerateAllowedMethods(t *testing.T) {
+	tests := []struct {
+		methods  []string
+		expected *version2.AllowedMethods
+		msg      string
+	}{
+		{
+			methods:  nil,
+			expected: nil,
+			msg:      "no methods",
+		},
+		{
+			methods: []string{"GET"},
+			expected: &version2.AllowedMethods{
+				Pattern: "GET|HEAD",
+				Header:  "GET, HEAD",
+			},
+			msg: "GET allows HEAD",
+		},
+		{
+			methods: []string{"HEAD", "GET"},
+			expected: &version2.AllowedMethods{
+				Pattern: "HEAD|GET",
+				Header:  "HEAD, GET",
+			},
+			msg: "GET and HEAD",
+		},
+		{
+			methods: []string{"POST", "DELETE"},
+			expected: &version2.AllowedMethods{
+				Pattern: "POST|DELETE",
+				Header:  "POST, DELETE",
+			},
+			msg: "methods without GET",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateAllowedMethods(test.methods)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateAllowedMethods() returned %+v but expected %+v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateOpenTelemetryTraceForVirtualServer(t *testing.T) {
+	createVirtualServer := func(openTelemetry *conf_v1.OpenTelemetry) *conf_v1.VirtualServer {
+		return &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				OpenTelemetry: openTelemetry,
+			},
+		}
+	}
+
+	cfgParams := &ConfigParams{
+		MainOpenTelemetryExporterEndpoint: "otel-collector:4317",
+		MainOpenTelemetrySamplerRatio:     0.5,
+	}
+
+	tests := []struct {
+		openTelemetry    *conf_v1.OpenTelemetry
+		moduleEnabled    bool
+		expected         string
+		expectedWarnings int
+		msg              string
+	}{
+		{
+			openTelemetry:    nil,
+			moduleEnabled:    true,
+			expected:         "",
+			expectedWarnings: 0,
+			msg:              "not configured",
+		},
+		{
+			openTelemetry:    &conf_v1.OpenTelemetry{Enable: true},
+			moduleEnabled:    true,
+			expected:         "$otel_trace_sampled",
+			expectedWarnings: 0,
+			msg:              "enabled",
+		},
+		{
+			openTelemetry:    &conf_v1.OpenTelemetry{Enable: false},
+			moduleEnabled:    true,
+			expected:         "off",
+			expectedWarnings: 0,
+			msg:              "disabled",
+		},
+		{
+			openTelemetry:    &conf_v1.OpenTelemetry{Enable: true},
+			moduleEnabled:    false,
+			expected:         "",
+			expectedWarnings: 1,
+			msg:              "module not enabled",
+		},
+	}
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(cfgParams, false, false, &StaticConfigParams{EnableOpenTelemetry: test.moduleEnabled})
+		vs := createVirtualServer(test.openTelemetry)
+
+		result := vsc.generateOpenTelemetryTrace(vs)
+		if result != test.expected {
+			t.Errorf("generateOpenTelemetryTrace() returned %q but expected %q for the case of %s", result, test.expected, test.msg)
+		}
+		if len(vsc.warnings[vs]) != test.expectedWarnings {
+			t.Errorf("generateOpenTelemetryTrace() returned %d warnings but expected %d for the case of %s", len(vsc.warnings[vs]), test.expectedWarnings, test.msg)
+		}
+	}
+}
+
+func TestGenerateCompression(t *testing.T) {
+	createVirtualServer := func(compression *conf_v1.Compression) *conf_v1.VirtualServer {
+		return &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Compression: compression,
+			},
+		}
+	}
+
+	gzipLevel := 6
+	brotliLevel := 0
+
+	tests := []struct {
+		compression      *conf_v1.Compression
+		brotliEnabled    bool
+		expected         *version2.Compression
+		expectedWarnings int
+		msg              string
+	}{
+		{
+			compression:      nil,
+			brotliEnabled:    true,
+			expected:         nil,
+			expectedWarnings: 0,
+			msg:              "not configured",
+		},
+		{
+			compression: &conf_v1.Compression{
+				Type:      "gzip",
+				Types:     []string{"application/json", "text/css"},
+				MinLength: 1000,
+				Level:     &gzipLevel,
+			},
+			brotliEnabled: false,
+			expected: &version2.Compression{
+				Module:    "gzip",
+				Types:     "application/json text/css",
+				MinLength: 1000,
+				Level:     "6",
+			},
+			expectedWarnings: 0,
+			msg:              "gzip",
+		},
+		{
+			compression: &conf_v1.Compression{
+				Type: "gzip",
+			},
+			brotliEnabled: false,
+			expected: &version2.Compression{
+				Module: "gzip",
+			},
+			expectedWarnings: 0,
+			msg:              "gzip with defaults",
+		},
+		{
+			compression: &conf_v1.Compression{
+				Type:  "brotli",
+				Types: []string{"application/json"},
+				Level: &brotliLevel,
+			},
+			brotliEnabled: true,
+			expected: &version2.Compression{
+				Module: "brotli",
+				Types:  "application/json",
+				Level:  "0",
+			},
+			expectedWarnings: 0,
+			msg:              "brotli",
+		},
+		{
+			compression: &conf_v1.Compression{
+				Type: "brotli",
+			},
+			brotliEnabled:    false,
+			expected:         nil,
+			expectedWarnings: 1,
+			msg:              "brotli module not enabled",
+		},
+	}
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{EnableBrotli: test.brotliEnabled})
+		vs := createVirtualServer(test.compression)
+
+		result := vsc.generateCompression(vs)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateCompression() returned %+v but expected %+v for the case of %s", result, test.expected, test.msg)
+		}
+		if len(vsc.warnings[vs]) != test.expectedWarnings {
+			t.Errorf("generateCompression() returned %d warnings but expected %d for the case of %s", len(vsc.warnings[vs]), test.expectedWarnings, test.msg)
+		}
+	}
+}
+
+func TestGenerateClientHeaders(t *testing.T) {
+	tests := []struct {
+		clientHeaders        *conf_v1.ClientHeaders
+		cfgParams            *ConfigParams
+		expectedBufferSize   string
+		expectedLargeBuffers string
+		expectedTooLarge     bool
+		expectedTimeout      string
+		msg                  string
+	}{
+		{
+			clientHeaders:        nil,
+			cfgParams:            &ConfigParams{},
+			expectedBufferSize:   "",
+			expectedLargeBuffers: "",
+			expectedTooLarge:     false,
+			msg:                  "no client headers",
+		},
+		{
+			clientHeaders:        nil,
+			cfgParams:            &ConfigParams{MainLargeClientHeaderBuffers: "4 16k"},
+			expectedBufferSize:   "",
+			expectedLargeBuffers: "",
+			expectedTooLarge:     true,
+			msg:                  "large buffers in the ConfigMap",
+		},
+		{
+			clientHeaders: &conf_v1.ClientHeaders{
+				BufferSize: "2k",
+				LargeBuffers: &conf_v1.UpstreamBuffers{
+					Number: 8,
+					Size:   "32k",
+				},
+			},
+			cfgParams:            &ConfigParams{},
+			expectedBufferSize:   "2k",
+			expectedLargeBuffers: "8 32k",
+			expectedTooLarge:     true,
+			msg:                  "buffer size and large buffers",
+		},
+		{
+			clientHeaders:        &conf_v1.ClientHeaders{BufferSize: "2k"},
+			cfgParams:            &ConfigParams{},
+			expectedBufferSize:   "2k",
+			expectedLargeBuffers: "",
+			expectedTooLarge:     true,
+			msg:                  "buffer size only",
+		},
+		{
+			clientHeaders:        &conf_v1.ClientHeaders{Timeout: "10s"},
+			cfgParams:            &ConfigParams{},
+			expectedBufferSize:   "",
+			expectedLargeBuffers: "",
+			expectedTooLarge:     false,
+			expectedTimeout:      "10s",
+			msg:                  "timeout only",
+		},
+	}
+
+	for _, test := range tests {
+		bufferSize := generateClientHeaderBufferSize(test.clientHeaders)
+		if bufferSize != test.expectedBufferSize {
+			t.Errorf("generateClientHeaderBufferSize() returned %q but expected %q for the case of %s", bufferSize, test.expectedBufferSize, test.msg)
+		}
+
+		largeBuffers := generateLargeClientHeaderBuffers(test.clientHeaders)
+		if largeBuffers != test.expectedLargeBuffers {
+			t.Errorf("generateLargeClientHeaderBuffers() returned %q but expected %q for the case of %s", largeBuffers, test.expectedLargeBuffers, test.msg)
+		}
+
+		tooLarge := generateRequestHeadersTooLarge(test.clientHeaders, test.cfgParams)
+		if tooLarge != test.expectedTooLarge {
+			t.Errorf("generateRequestHeadersTooLarge() returned %v but expected %v for the case of %s", tooLarge, test.expectedTooLarge, test.msg)
+		}
+
+		timeout := generateClientHeaderTimeout(test.clientHeaders)
+		if timeout != test.expectedTimeout {
+			t.Errorf("generateClientHeaderTimeout() returned %q but expected %q for the case of %s", timeout, test.expectedTimeout, test.msg)
+		}
+	}
+}
+
+func TestGenerateClientBody(t *testing.T) {
+	tests := []struct {
+		clientBody         *conf_v1.ClientBody
+		expectedBufferSize string
+		expectedTempPath   string
+		expectedTimeout    string
+	}{
+		{
+			clientBody:         nil,
+			expectedBufferSize: "",
+			expectedTempPath:   "",
+			expectedTimeout:    "",
+		},
+		{
+			clientBody:         &conf_v1.ClientBody{},
+			expectedBufferSize: "",
+			expectedTempPath:   "",
+			expectedTimeout:    "",
+		},
+		{
+			clientBody: &conf_v1.ClientBody{
+				BufferSize: "128k",
+				TempPath:   "/var/cache/nginx/uploads",
+				Timeout:    "30s",
+			},
+			expectedBufferSize: "128k",
+			expectedTempPath:   "/var/cache/nginx/uploads",
+			expectedTimeout:    "30s",
+		},
+	}
+
+	for _, test := range tests {
+		bufferSize := generateClientBodyBufferSize(test.clientBody)
+		if bufferSize != test.expectedBufferSize {
+			t.Errorf("generateClientBodyBufferSize(%+v) returned %q but expected %q", test.clientBody, bufferSize, test.expectedBufferSize)
+		}
+
+		tempPath := generateClientBodyTempPath(test.clientBody)
+		if tempPath != test.expectedTempPath {
+			t.Errorf("generateClientBodyTempPath(%+v) returned %q but expected %q", test.clientBody, tempPath, test.expectedTempPath)
+		}
+
+		timeout := generateClientBodyTimeout(test.clientBody)
+		if timeout != test.expectedTimeout {
+			t.Errorf("generateClientBodyTimeout(%+v) returned %q but expected %q", test.clientBody, timeout, test.expectedTimeout)
+		}
+	}
+}
+
+func TestGenerateRequestIDHeader(t *testing.T) {
+	tests := []struct {
+		requestID *conf_v1.RequestID
+		expected  string
+	}{
+		{
+			requestID: nil,
+			expected:  "",
+		},
+		{
+			requestID: &conf_v1.RequestID{Enable: false, Header: "X-Trace-ID"},
+			expected:  "",
+		},
+		{
+			requestID: &conf_v1.RequestID{Enable: true},
+			expected:  "X-Request-ID",
+		},
+		{
+			requestID: &conf_v1.RequestID{Enable: true, Header: "X-Trace-ID"},
+			expected:  "X-Trace-ID",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateRequestIDHeader(test.requestID)
+		if result != test.expected {
+			t.Errorf("generateRequestIDHeader(%+v) returned %q but expected %q", test.requestID, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateRequestIDLogFormat(t *testing.T) {
+	createVirtualServer := func(requestID *conf_v1.RequestID) *conf_v1.VirtualServer {
+		return &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				RequestID: requestID,
+			},
+		}
+	}
+
+	tests := []struct {
+		virtualServer *conf_v1.VirtualServer
+		cfgParams     *ConfigParams
+		expected      *version2.LogFormat
+		msg           string
+	}{
+		{
+			virtualServer: createVirtualServer(nil),
+			cfgParams:     &ConfigParams{},
+			expected:      nil,
+			msg:           "request id not enabled",
+		},
+		{
+			virtualServer: createVirtualServer(&conf_v1.RequestID{Enable: true}),
+			cfgParams:     &ConfigParams{MainAccessLogOff: true},
+			expected:      nil,
+			msg:           "access log off",
+		},
+		{
+			virtualServer: createVirtualServer(&conf_v1.RequestID{Enable: true}),
+			cfgParams:     &ConfigParams{},
+			expected: &version2.LogFormat{
+				Name: "vs_default_cafe_request_id",
+				Format: []string{
+					`$remote_addr - $remote_user [$time_local] "$request"`,
+					`$status $body_bytes_sent "$http_referer"`,
+					`"$http_user_agent" "$http_x_forwarded_for"`,
+					`"$request_id"`,
+				},
+			},
+			msg: "default log format",
+		},
+		{
+			virtualServer: createVirtualServer(&conf_v1.RequestID{Enable: true}),
+			cfgParams: &ConfigParams{
+				MainLogFormat:         []string{`$remote_addr "$request"`},
+				MainLogFormatEscaping: "json",
+			},
+			expected: &version2.LogFormat{
+				Name:     "vs_default_cafe_request_id",
+				Escaping: "json",
+				Format: []string{
+					`$remote_addr "$request"`,
+					`"$request_id"`,
+				},
+			},
+			msg: "custom log format",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateRequestIDLogFormat(test.virtualServer, test.cfgParams)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateRequestIDLogFormat() returned %+v but expected %+v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateOIDC(t *testing.T) {
+	createVirtualServerEx := func(oidc *conf_v1.OIDC, secret *api_v1.Secret) *VirtualServerEx {
+		return &VirtualServerEx{
+			VirtualServer: &conf_v1.VirtualServer{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "cafe",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerSpec{
+					OIDC: oidc,
+				},
+			},
+			OIDCSecret: secret,
+		}
+	}
+
+	oidc := &conf_v1.OIDC{
+		ClientID:      "nginx-plus",
+		ClientSecret:  "oidc-secret",
+		AuthEndpoint:  "https://idp.example.com/auth",
+		TokenEndpoint: "https://idp.example.com/token",
+		JWKSURI:       "https://idp.example.com/certs",
+	}
+	secret := &api_v1.Secret{
+		Data: map[string][]byte{
+			"client-secret": []byte("secret"),
+		},
+	}
+
+	failOpenOIDC := oidc.DeepCopy()
+	failOpenOIDC.FailureMode = "open"
+
+	tests := []struct {
+		vsEx             *VirtualServerEx
+		enabled          bool
+		expected         *version2.OIDC
+		expectedValid    bool
+		expectedWarnings int
+		msg              string
+	}{
+		{
+			vsEx:             createVirtualServerEx(nil, nil),
+			enabled:          true,
+			expected:         nil,
+			expectedValid:    true,
+			expectedWarnings: 0,
+			msg:              "no oidc",
+		},
+		{
+			vsEx:             createVirtualServerEx(oidc, secret),
+			enabled:          false,
+			expected:         nil,
+			expectedValid:    false,
+			expectedWarnings: 1,
+			msg:              "oidc not enabled",
+		},
+		{
+			vsEx:             createVirtualServerEx(oidc, nil),
+			enabled:          true,
+			expected:         nil,
+			expectedValid:    false,
+			expectedWarnings: 1,
+			msg:              "missing secret",
+		},
+		{
+			vsEx:             createVirtualServerEx(failOpenOIDC, nil),
+			enabled:          true,
+			expected:         nil,
+			expectedValid:    false,
+			expectedWarnings: 1,
+			msg:              "missing secret with the open failure mode",
+		},
+		{
+			vsEx:             createVirtualServerEx(failOpenOIDC, secret),
+			enabled:          false,
+			expected:         nil,
+			expectedValid:    false,
+			expectedWarnings: 1,
+			msg:              "oidc not enabled with the open failure mode",
+		},
+		{
+			vsEx:    createVirtualServerEx(oidc, secret),
+			enabled: true,
+			expected: &version2.OIDC{
+				AuthEndpoint:  "https://idp.example.com/auth",
+				TokenEndpoint: "https://idp.example.com/token",
+				JwksURI:       "https://idp.example.com/certs",
+				ClientID:      "nginx-plus",
+				ClientSecret:  "secret",
+				Scope:         "openid",
+				RedirectURI:   "/_codexch",
+			},
+			expectedValid:    true,
+			expectedWarnings: 0,
+			msg:              "valid oidc with default scope and redirect uri",
+		},
+	}
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(&ConfigParams{}, true, true, &StaticConfigParams{EnableOIDC: test.enabled})
+
+		result, valid := vsc.generateOIDC(test.vsEx)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateOIDC() returned %+v but expected %+v for the case of %s", result, test.expected, test.msg)
+		}
+		if valid != test.expectedValid {
+			t.Errorf("generateOIDC() returned %v but expected %v for the case of %s", valid, test.expectedValid, test.msg)
+		}
+		if len(vsc.warnings[test.vsEx.VirtualServer]) != test.expectedWarnings {
+			t.Errorf("generateOIDC() returned %d warnings but expected %d for the case of %s", len(vsc.warnings[test.vsEx.VirtualServer]), test.expectedWarnings, test.msg)
+		}
+	}
+}
+
+func TestGenerateVirtualServerConfigWithOIDCFailureMode(t *testing.T) {
+	for _, failureMode := range []string{"", "closed", "open"} {
+		virtualServerEx := VirtualServerEx{
+			VirtualServer: &conf_v1.VirtualServer{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "cafe",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerSpec{
+					Host: "cafe.example.com",
+					OIDC: &conf_v1.OIDC{
+						ClientID:      "nginx-plus",
+						ClientSecret:  "oidc-secret",
+						AuthEndpoint:  "https://idp.example.com/auth",
+						TokenEndpoint: "https://idp.example.com/token",
+						JWKSURI:       "https://idp.example.com/certs",
+						FailureMode:   failureMode,
+					},
+					Routes: []conf_v1.Route{
+						{
+							Path: "/",
+							Action: &conf_v1.Action{
+								Return: &conf_v1.ActionReturn{Body: "hello"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		// the failure mode never leaves the routes unprotected when the secret is missing
+		vsc := newVirtualServerConfigurator(&ConfigParams{}, true, true, &StaticConfigParams{EnableOIDC: true})
+		result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+
+		expectedErrorReturn := &version2.Return{Code: 500}
+		if result.Server.OIDC != nil {
+			t.Errorf("GenerateVirtualServerConfig() generated the OIDC %+v for a missing secret for the %q failure mode", result.Server.OIDC, failureMode)
+		}
+		if len(result.Server.Locations) == 0 {
+			t.Fatalf("GenerateVirtualServerConfig() generated no locations for the %q failure mode", failureMode)
+		}
+		for _, l := range result.Server.Locations {
+			if l.OIDC || !reflect.DeepEqual(l.PoliciesErrorReturn, expectedErrorReturn) {
+				t.Errorf("GenerateVirtualServerConfig() generated the location %v with the OIDC %v and the error return %+v but expected %+v for the %q failure mode",
+					l.Path, l.OIDC, l.PoliciesErrorReturn, expectedErrorReturn, failureMode)
+			}
+		}
+		if len(warnings[virtualServerEx.VirtualServer]) != 1 {
+			t.Errorf("GenerateVirtualServerConfig() returned the warnings %v but expected 1 warning for the %q failure mode", warnings[virtualServerEx.VirtualServer], failureMode)
+		}
+	}
+}
+
+func TestAddOIDCToLocations(t *testing.T) {
+	jwtAuth := &version2.JWTAuth{Secret: "/etc/nginx/secrets/default-jwk-secret", Realm: "My API"}
+	policiesErrorReturn := &version2.Return{Code: 500}
+
+	tests := []struct {
+		failureMode      string
+		oidcCfg          *version2.OIDC
+		oidcValid        bool
+		expected         []version2.Location
+		expectedWarnings int
+		msg              string
+	}{
+		{
+			oidcCfg:   nil,
+			oidcValid: true,
+			expected: []version2.Location{
+				{Path: "/tea", ProxyPass: "http://tea"},
+				{Path: "/coffee", ProxyPass: "http://coffee", JWTAuth: jwtAuth},
+				{Path: "/juice", PoliciesErrorReturn: policiesErrorReturn},
+			},
+			expectedWarnings: 0,
+			msg:              "no oidc",
+		},
+		{
+			oidcCfg:   &version2.OIDC{},
+			oidcValid: true,
+			expected: []version2.Location{
+				{Path: "/tea", ProxyPass: "http://tea", OIDC: true, OIDCErrorLocation: "@oidc_unavailable"},
+				{Path: "/coffee", ProxyPass: "http://coffee", OIDC: true, OIDCErrorLocation: "@oidc_unavailable"},
+				{Path: "/juice", PoliciesErrorReturn: policiesErrorReturn, OIDC: true},
+			},
+			expectedWarnings: 1,
+			msg:              "oidc replaces jwt",
+		},
+		{
+			failureMode: "closed",
+			oidcCfg:     &version2.OIDC{},
+			oidcValid:   true,
+			expected: []version2.Location{
+				{Path: "/tea", ProxyPass: "http://tea", OIDC: true, OIDCErrorLocation: "@oidc_unavailable"},
+				{Path: "/coffee", ProxyPass: "http://coffee", OIDC: true, OIDCErrorLocation: "@oidc_unavailable"},
+				{Path: "/juice", PoliciesErrorReturn: policiesErrorReturn, OIDC: true},
+			},
+			expectedWarnings: 1,
+			msg:              "oidc with the closed failure mode",
+		},
+		{
+			failureMode: "open",
+			oidcCfg:     &version2.OIDC{},
+			oidcValid:   true,
+			expected: []version2.Location{
+				{Path: "/tea", ProxyPass: "http://tea", OIDC: true, OIDCErrorLocation: "@oidc_fail_open_0"},
+				{Path: "/coffee", ProxyPass: "http://coffee", OIDC: true, OIDCErrorLocation: "@oidc_fail_open_1"},
+				{Path: "/juice", PoliciesErrorReturn: policiesErrorReturn, OIDC: true},
+				{Path: "@oidc_fail_open_0", ProxyPass: "http://tea"},
+				{Path: "@oidc_fail_open_1", ProxyPass: "http://coffee"},
+			},
+			expectedWarnings: 1,
+			msg:              "oidc with the open failure mode",
+		},
+		{
+			oidcCfg:   nil,
+			oidcValid: false,
+			expected: []version2.Location{
+				{Path: "/tea", ProxyPass: "http://tea", PoliciesErrorReturn: policiesErrorReturn},
+				{Path: "/coffee", ProxyPass: "http://coffee", JWTAuth: jwtAuth, PoliciesErrorReturn: policiesErrorReturn},
+				{Path: "/juice", PoliciesErrorReturn: policiesErrorReturn},
+			},
+			expectedWarnings: 0,
+			msg:              "invalid oidc",
+		},
+	}
+
+	for _, test := range tests {
+		vs := &conf_v1.VirtualServer{
+			Spec: conf_v1.VirtualServerSpec{
+				OIDC: &conf_v1.OIDC{FailureMode: test.failureMode},
+			},
+		}
+		locations := []version2.Location{
+			{Path: "/tea", ProxyPass: "http://tea"},
+			{Path: "/coffee", ProxyPass: "http://coffee", JWTAuth: jwtAuth},
+			{Path: "/juice", PoliciesErrorReturn: policiesErrorReturn},
+		}
+		vsc := newVirtualServerConfigurator(&ConfigParams{}, true, false, &StaticConfigParams{EnableOIDC: true})
+
+		locations = vsc.addOIDCToLocations(vs, locations, test.oidcCfg, test.oidcValid)
+		if !reflect.DeepEqual(locations, test.expected) {
+			t.Errorf("addOIDCToLocations() generated %+v but expected %+v for the case of %s", locations, test.expected, test.msg)
+		}
+		if len(vsc.warnings[vs]) != test.expectedWarnings {
+			t.Errorf("addOIDCToLocations() returned %d warnings but expected %d for the case of %s", len(vsc.warnings[vs]), test.expectedWarnings, test.msg)
+		}
+	}
+}
+
+func TestGenerateLogFormat(t *testing.T) {
+	createVirtualServer := func(requestID *conf_v1.RequestID, accessLog *conf_v1.AccessLog) *conf_v1.VirtualServer {
+		return &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				RequestID: requestID,
+				AccessLog: accessLog,
+			},
+		}
+	}
+
+	accessLog := &conf_v1.AccessLog{
+		Fields: []conf_v1.AccessLogField{
+			{Name: "user", Variable: "$http_x_user"},
+			{Name: "jwt_sub", Variable: "$jwt_claim_sub"},
+		},
+	}
+
+	tests := []struct {
+		virtualServer *conf_v1.VirtualServer
+		cfgParams     *ConfigParams
+		expected      *version2.LogFormat
+		msg           string
+	}{
+		{
+			virtualServer: createVirtualServer(nil, nil),
+			cfgParams:     &ConfigParams{},
+			expected:      nil,
+			msg:           "no request id and no custom fields",
+		},
+		{
+			virtualServer: createVirtualServer(nil, &conf_v1.AccessLog{}),
+			cfgParams:     &ConfigParams{},
+			expected:      nil,
+			msg:           "empty custom fields",
+		},
+		{
+			virtualServer: createVirtualServer(nil, accessLog),
+			cfgParams:     &ConfigParams{MainAccessLogOff: true},
+			expected:      nil,
+			msg:           "access log off",
+		},
+		{
+			virtualServer: createVirtualServer(nil, accessLog),
+			cfgParams:     &ConfigParams{},
+			expected: &version2.LogFormat{
+				Name: "vs_default_cafe_custom",
+				Format: []string{
+					`$remote_addr - $remote_user [$time_local] "$request"`,
+					`$status $body_bytes_sent "$http_referer"`,
+					`"$http_user_agent" "$http_x_forwarded_for"`,
+					`user="$http_x_user"`,
+					`jwt_sub="$jwt_claim_sub"`,
+				},
+			},
+			msg: "custom fields with the default log format",
+		},
+		{
+			virtualServer: createVirtualServer(&conf_v1.RequestID{Enable: true}, accessLog),
+			cfgParams: &ConfigParams{
+				MainLogFormat:         []string{`$remote_addr "$request"`},
+				MainLogFormatEscaping: "json",
+			},
+			expected: &version2.LogFormat{
+				Name:     "vs_default_cafe_custom",
+				Escaping: "json",
+				Format: []string{
+					`$remote_addr "$request"`,
+					`"$request_id"`,
+					`user="$http_x_user"`,
+					`jwt_sub="$jwt_claim_sub"`,
+				},
+			},
+			msg: "custom fields with the request id and a custom log format",
+		},
+		{
+			virtualServer: createVirtualServer(&conf_v1.RequestID{Enable: true}, nil),
+			cfgParams:     &ConfigParams{},
+			expected: &version2.LogFormat{
+				Name: "vs_default_cafe_request_id",
+				Format: []string{
+					`$remote_addr - $remote_user [$time_local] "$request"`,
+					`$status $body_bytes_sent "$http_referer"`,
+					`"$http_user_agent" "$http_x_forwarded_for"`,
+					`"$request_id"`,
+				},
+			},
+			msg: "request id without custom fields",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateLogFormat(test.virtualServer, test.cfgParams)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateLogFormat() returned %+v but expected %+v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateUpstreamWithMaxFailsAndFailTimeout(t *testing.T) {
+	name := "test-upstream"
+	maxFails := 5
+	noMaxFails := 0
+	endpoints := []string{
+		"192.168.10.10:8080",
+		"192.168.10.11:8080",
+	}
+	cfgParams := &ConfigParams{MaxFails: 1, FailTimeout: "10s"}
+
+	tests := []struct {
+		upstream conf_v1.Upstream
+		expected version2.Upstream
+		msg      string
+	}{
+		{
+			conf_v1.Upstream{Service: name, Port: 80, MaxFails: &maxFails, FailTimeout: "30s"},
+			version2.Upstream{
+				Name: "test-upstream",
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "192.168.10.10:8080",
+					},
+					{
+						Address: "192.168.10.11:8080",
+					},
+				},
+				MaxFails:    5,
+				FailTimeout: "30s",
+			},
+			"upstream max-fails and fail-timeout set",
+		},
+		{
+			conf_v1.Upstream{Service: name, Port: 80, MaxFails: &noMaxFails},
+			version2.Upstream{
+				Name: "test-upstream",
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "192.168.10.10:8080",
+					},
+					{
+						Address: "192.168.10.11:8080",
+					},
+				},
+				MaxFails:    0,
+				FailTimeout: "10s",
+			},
+			"upstream max-fails set to 0, fail-timeout not set",
+		},
+		{
+			conf_v1.Upstream{Service: name, Port: 80},
+			version2.Upstream{
+				Name: "test-upstream",
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "192.168.10.10:8080",
+					},
+					{
+						Address: "192.168.10.11:8080",
+					},
+				},
+				MaxFails:    1,
+				FailTimeout: "10s",
+			},
+			"upstream max-fails and fail-timeout not set",
+		},
+	}
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(cfgParams, false, false, &StaticConfigParams{})
+		result := vsc.generateUpstream(&conf_v1.VirtualServer{}, name, test.upstream, false, endpoints, nil)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateUpstream() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		}
+
+		if len(vsc.warnings) != 0 {
+			t.Errorf("generateUpstream() returned warnings for %v", test.upstream)
+		}
+	}
+}
+
+func TestGenerateUpstreamWithKeepalive(t *testing.T) {
+	name := "test-upstream"
+	noKeepalive := 0
+	keepalive := 32
+	endpoints := []string{
+		"192.168.10.10:8080",
+	}
+
+	tests := []struct {
+		upstream  conf_v1.Upstream
+		cfgParams *ConfigParams
+		expected  version2.Upstream
+		msg       string
+	}{
+		{
+			conf_v1.Upstream{Keepalive: &keepalive, Service: name, Port: 80},
+			&ConfigParams{Keepalive: 21},
+			version2.Upstream{
+				Name: "test-upstream",
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "192.168.10.10:8080",
+					},
+				},
+				Keepalive: 32,
+			},
+			"upstream keepalive set, configparam set",
+		},
+		{
+			conf_v1.Upstream{Service: name, Port: 80},
+			&ConfigParams{Keepalive: 21},
+			version2.Upstream{
+				Name: "test-upstream",
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "192.168.10.10:8080",
+					},
+				},
+				Keepalive: 21,
+			},
+			"upstream keepalive not set, configparam set",
+		},
+		{
+			conf_v1.Upstream{Keepalive: &noKeepalive, Service: name, Port: 80},
+			&ConfigParams{Keepalive: 21},
+			version2.Upstream{
+				Name: "test-upstream",
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "192.168.10.10:8080",
+					},
+				},
+			},
+			"upstream keepalive set to 0, configparam set",
+		},
+	}
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(test.cfgParams, false, false, &StaticConfigParams{})
+		result := vsc.generateUpstream(&conf_v1.VirtualServer{}, name, test.upstream, false, endpoints, nil)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateUpstream() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
+		}
+
+		if len(vsc.warnings) != 0 {
+			t.Errorf("generateUpstream() returned warnings for %v", test.upstream)
+		}
+	}
+}
+
+func TestGenerateUpstreamForExternalNameService(t *testing.T) {
+	name := "test-upstream"
+	endpoints := []string{"example.com"}
+	upstream := conf_v1.Upstream{Service: name}
+	cfgParams := ConfigParams{}
+
+	expected := version2.Upstream{
+		Name: name,
+		Servers: []version2.UpstreamServer{
+			{
+				Address: "example.com",
+			},
+		},
+		Resolve: true,
+	}
+
+	vsc := newVirtualServerConfigurator(&cfgParams, true, true, &StaticConfigParams{})
+	result := vsc.generateUpstream(&conf_v1.VirtualServer{}, name, upstream, true, endpoints, nil)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
+	}
+
+	if len(vsc.warnings) != 0 {
+		t.Errorf("generateUpstream() returned warnings for %v", upstream)
+	}
+}
+
+func TestGenerateUpstreamWithSRV(t *testing.T) {
+	name := "test-upstream"
+	endpoints := []string{"10.0.0.20:8080"}
+	upstream := conf_v1.Upstream{
+		Service: name,
+		Port:    8080,
+		SRV: &conf_v1.UpstreamSRV{
+			Host:    "test-upstream.default.svc.cluster.local",
+			Service: "_http._tcp",
+		},
+	}
+	cfgParams := ConfigParams{}
+
+	expected := version2.Upstream{
+		Name: name,
+		Servers: []version2.UpstreamServer{
+			{
+				Address: "test-upstream.default.svc.cluster.local",
+				Service: "_http._tcp",
+			},
+		},
+		Resolve: true,
+	}
+
+	vsc := newVirtualServerConfigurator(&cfgParams, true, true, &StaticConfigParams{})
+	result := vsc.generateUpstream(&conf_v1.VirtualServer{}, name, upstream, false, endpoints, nil)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
+	}
+
+	if len(vsc.warnings) != 0 {
+		t.Errorf("generateUpstream() returned warnings for %v", upstream)
+	}
+}
+
+func TestGenerateUpstreamWithSRVWithoutResolver(t *testing.T) {
+	name := "test-upstream"
+	namespace := "default"
+	upstream := conf_v1.Upstream{
+		Name:    name,
+		Service: name,
+		Port:    8080,
+		SRV: &conf_v1.UpstreamSRV{
+			Host:    "test-upstream.default.svc.cluster.local",
+			Service: "_http._tcp",
+		},
+	}
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: namespace,
+		},
+	}
+	vsEx := &VirtualServerEx{
+		VirtualServer: vs,
+		Endpoints: map[string][]string{
+			"default/test-upstream:8080": {"10.0.0.20:8080"},
+		},
+	}
+
+	expected := version2.Upstream{
+		Name: name,
+		Servers: []version2.UpstreamServer{
+			{
+				Address: "10.0.0.20:8080",
+			},
+		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, true, false, &StaticConfigParams{})
+	endpoints := vsc.generateEndpointsForUpstream(vs, namespace, upstream, vsEx)
+	result := vsc.generateUpstream(vs, name, upstream, false, endpoints, nil)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
+	}
+
+	if len(vsc.warnings) != 1 {
+		t.Errorf("generateEndpointsForUpstream() returned %d warnings but expected 1 for %v", len(vsc.warnings), upstream)
+	}
+}
+
+func TestGenerateUpstreamWithSRVAndResolverValid(t *testing.T) {
+	name := "test-upstream"
+	upstream := conf_v1.Upstream{
+		Service: name,
+		Port:    8080,
+		SRV: &conf_v1.UpstreamSRV{
+			Host:    "test-upstream.default.svc.cluster.local",
+			Service: "_http._tcp",
+		},
+		ResolverValid: "5s",
+	}
+	cfgParams := ConfigParams{
+		ResolverAddresses:     []string{"10.0.0.5"},
+		ResolverUpstreamValid: "30s",
+	}
+
+	expected := version2.Upstream{
+		Name: name,
+		Servers: []version2.UpstreamServer{
+			{
+				Address: "test-upstream.default.svc.cluster.local",
+				Service: "_http._tcp",
+			},
+		},
+		Resolve: true,
+		Resolver: &version2.UpstreamResolver{
+			Addresses: []string{"10.0.0.5"},
+			Valid:     "5s",
+		},
+	}
+
+	vsc := newVirtualServerConfigurator(&cfgParams, true, true, &StaticConfigParams{})
+	result := vsc.generateUpstream(&conf_v1.VirtualServer{}, name, upstream, false, nil, nil)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateUpstream() returned %v but expected %v", result, expected)
+	}
+}
+
+func TestGenerateUpstreamResolver(t *testing.T) {
+	tests := []struct {
+		valid     string
+		cfgParams *ConfigParams
+		expected  *version2.UpstreamResolver
+		msg       string
+	}{
+		{
+			valid: "",
+			cfgParams: &ConfigParams{
+				ResolverAddresses: []string{"10.0.0.5"},
+			},
+			expected: nil,
+			msg:      "no valid time",
+		},
+		{
+			valid:     "10s",
+			cfgParams: &ConfigParams{},
+			expected:  nil,
+			msg:       "no resolver addresses",
+		},
+		{
+			valid: "",
+			cfgParams: &ConfigParams{
+				ResolverAddresses:     []string{"10.0.0.5", "10.0.0.6"},
+				ResolverUpstreamValid: "30s",
+				ResolverIPV6:          true,
+			},
+			expected: &version2.UpstreamResolver{
+				Addresses: []string{"10.0.0.5", "10.0.0.6"},
+				Valid:     "30s",
+				IPV6:      true,
+			},
+			msg: "valid time from the ConfigMap",
+		},
+		{
+			valid: "10s",
+			cfgParams: &ConfigParams{
+				ResolverAddresses:     []string{"10.0.0.5"},
+				ResolverUpstreamValid: "30s",
+			},
+			expected: &version2.UpstreamResolver{
+				Addresses: []string{"10.0.0.5"},
+				Valid:     "10s",
+			},
+			msg: "valid time from the upstream overrides the ConfigMap",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateUpstreamResolver(test.valid, test.cfgParams)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateUpstreamResolver() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestCreateUpstreamsForPlusSkipsSRVUpstreams(t *testing.T) {
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
+					{
+						Name:    "coffee",
+						Service: "coffee-svc",
+						Port:    80,
+						SRV: &conf_v1.UpstreamSRV{
+							Host:    "coffee-svc.default.svc.cluster.local",
+							Service: "http",
+						},
+					},
+				},
+			},
+		},
+		Endpoints: map[string][]string{
+			"default/tea-svc:80":    {"10.0.0.20:80"},
+			"default/coffee-svc:80": {"10.0.0.30:80"},
+		},
+	}
+
+	expected := []version2.Upstream{
+		{
+			Name: "vs_default_cafe_tea",
+			Servers: []version2.UpstreamServer{
+				{
+					Address: "10.0.0.20:80",
+				},
+			},
+		},
+	}
+
+	cfgParams := ConfigParams{ResolverAddresses: []string{"kube-dns.kube-system.svc.cluster.local"}}
+	result := createUpstreamsForPlus(&virtualServerEx, &cfgParams, &StaticConfigParams{})
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("createUpstreamsForPlus returned \n%v but expected \n%v", result, expected)
+	}
+}
+
+func TestGenerateProxyPass(t *testing.T) {
+	tests := []struct {
+		tlsEnabled   bool
+		upstreamName string
+		internal     bool
+		expected     string
+	}{
+		{
+			tlsEnabled:   false,
+			upstreamName: "test-upstream",
+			internal:     false,
+			expected:     "http://test-upstream",
+		},
+		{
+			tlsEnabled:   true,
+			upstreamName: "test-upstream",
+			internal:     false,
+			expected:     "https://test-upstream",
+		},
+		{
+			tlsEnabled:   false,
+			upstreamName: "test-upstream",
+			internal:     true,
+			expected:     "http://test-upstream$request_uri",
+		},
+		{
+			tlsEnabled:   true,
+			upstreamName: "test-upstream",
+			internal:     true,
+			expected:     "https://test-upstream$request_uri",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateProxyPass(test.tlsEnabled, test.upstreamName, test.internal, nil)
+		if result != test.expected {
+			t.Errorf("generateProxyPass(%v, %v, %v) returned %v but expected %v", test.tlsEnabled, test.upstreamName, test.internal, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateProxyPassProtocol(t *testing.T) {
+	tests := []struct {
+		upstream conf_v1.Upstream
+		expected string
+	}{
+		{
+			upstream: conf_v1.Upstream{},
+			expected: "http",
+		},
+		{
+			upstream: conf_v1.Upstream{
+				TLS: conf_v1.UpstreamTLS{
+					Enable: true,
+				},
+			},
+			expected: "https",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateProxyPassProtocol(test.upstream.TLS.Enable)
+		if result != test.expected {
+			t.Errorf("generateProxyPassProtocol(%v) returned %v but expected %v", test.upstream.TLS.Enable, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateString(t *testing.T) {
+	tests := []struct {
+		inputS   string
+		expected string
+	}{
+		{
+			inputS:   "http_404",
+			expected: "http_404",
+		},
+		{
+			inputS:   "",
+			expected: "error timeout",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateString(test.inputS, "error timeout")
+		if result != test.expected {
+			t.Errorf("generateString() return %v but expected %v", result, test.expected)
+		}
+	}
+}
+
+func TestGenerateBuffer(t *testing.T) {
+	tests := []struct {
+		inputS   *conf_v1.UpstreamBuffers
+		expected string
+	}{
+		{
+			inputS:   nil,
+			expected: "8 4k",
+		},
+		{
+			inputS:   &conf_v1.UpstreamBuffers{Number: 8, Size: "16K"},
+			expected: "8 16K",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateBuffers(test.inputS, "8 4k")
+		if result != test.expected {
+			t.Errorf("generateBuffer() return %v but expected %v", result, test.expected)
+		}
+	}
+}
+
+func TestGenerateLocationForProxying(t *testing.T) {
+	cfgParams := ConfigParams{
+		ProxyConnectTimeout:  "30s",
+		ProxyReadTimeout:     "31s",
+		ProxySendTimeout:     "32s",
+		ClientMaxBodySize:    "1m",
+		ProxyMaxTempFileSize: "1024m",
+		ProxyBuffering:       true,
+		ProxyBuffers:         "8 4k",
+		ProxyBufferSize:      "4k",
+		LocationSnippets:     []string{"# location snippet"},
+	}
+	path := "/"
+	upstreamName := "test-upstream"
+
+	expected := version2.Location{
+		Path:                     "/",
+		Snippets:                 []string{"# location snippet"},
+		ProxyConnectTimeout:      "30s",
+		ProxyReadTimeout:         "31s",
+		ProxySendTimeout:         "32s",
+		ClientMaxBodySize:        "1m",
+		ProxyMaxTempFileSize:     "1024m",
+		ProxyBuffering:           true,
+		ProxyBuffers:             "8 4k",
+		ProxyBufferSize:          "4k",
+		ProxyPass:                "http://test-upstream",
+		ProxyNextUpstream:        "error timeout",
+		ProxyNextUpstreamTimeout: "0s",
+		ProxyNextUpstreamTries:   0,
+		ProxyPassRequestHeaders:  true,
+	}
+
+	result := generateLocationForProxying(path, upstreamName, conf_v1.Upstream{}, &cfgParams, nil, false, 0, "", nil, "")
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateLocationForProxying() returned \n%v but expected \n%v", result, expected)
+	}
+}
+
+func TestGenerateReturnBlock(t *testing.T) {
+	tests := []struct {
+		text        string
+		code        int
+		defaultCode int
+		expected    *version2.Return
+	}{
+		{
+			text:        "Hello World!",
+			code:        0, // Not set
+			defaultCode: 200,
+			expected: &version2.Return{
+				Code: 200,
+				Text: "Hello World!",
+			},
+		},
+		{
+			text:        "Hello World!",
+			code:        400,
+			defaultCode: 200,
+			expected: &version2.Return{
+				Code: 400,
+				Text: "Hello World!",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		result := generateReturnBlock(test.text, test.code, test.defaultCode)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateReturnBlock() returned %v but expected %v", result, test.expected)
+		}
+	}
+
+}
+
+func TestGenerateLocationForReturnBlock(t *testing.T) {
+	cfgParams := ConfigParams{
+		LocationSnippets: []string{"# location snippet"},
+	}
+	defaultType := "application/json"
+	path := "/"
+	returnBlock := &version2.Return{
+		Code: 301,
+		Text: "http://www.nginx.com",
+	}
+
+	expected := version2.Location{
+		Path:        "/",
+		Snippets:    []string{"# location snippet"},
+		DefaultType: defaultType,
+		Return: &version2.Return{
+			Text: "http://www.nginx.com",
+			Code: 301,
+		},
+	}
+
+	result := generateLocationForReturnBlock(path, cfgParams.LocationSnippets, returnBlock, defaultType)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateLocationForReturnBlock() returned %v but expected %v", result, expected)
+	}
+}
+
+func TestGenerateProxySSLParameters(t *testing.T) {
+	sessionReuseOff := false
+	sessionReuseOn := true
+
+	tests := []struct {
+		tls                  conf_v1.UpstreamTLS
+		expectedSessionReuse string
+		expectedProtocols    string
+		expectedCiphers      string
+		msg                  string
+	}{
+		{
+			tls:                  conf_v1.UpstreamTLS{},
+			expectedSessionReuse: "",
+			expectedProtocols:    "",
+			expectedCiphers:      "",
+			msg:                  "no TLS",
+		},
+		{
+			tls: conf_v1.UpstreamTLS{
+				Enable:       false,
+				SessionReuse: &sessionReuseOff,
+				Protocols:    []string{"TLSv1.3"},
+				Ciphers:      "HIGH:!aNULL",
+			},
+			expectedSessionReuse: "",
+			expectedProtocols:    "",
+			expectedCiphers:      "",
+			msg:                  "TLS parameters for disabled TLS",
+		},
+		{
+			tls: conf_v1.UpstreamTLS{
+				Enable:       true,
+				SessionReuse: &sessionReuseOff,
+				Protocols:    []string{"TLSv1.2", "TLSv1.3"},
+				Ciphers:      "HIGH:!aNULL",
+			},
+			expectedSessionReuse: "off",
+			expectedProtocols:    "TLSv1.2 TLSv1.3",
+			expectedCiphers:      "HIGH:!aNULL",
+			msg:                  "all TLS parameters",
+		},
+		{
+			tls: conf_v1.UpstreamTLS{
+				Enable:       true,
+				SessionReuse: &sessionReuseOn,
+			},
+			expectedSessionReuse: "on",
+			expectedProtocols:    "",
+			expectedCiphers:      "",
+			msg:                  "session reuse only",
+		},
+	}
+
+	for _, test := range tests {
+		loc := generateLocationForProxying("/tea", "vs_default_cafe_tea", conf_v1.Upstream{TLS: test.tls}, &ConfigParams{}, nil, false, 0, "", nil, "/tea")
+
+		if loc.ProxySSLSessionReuse != test.expectedSessionReuse {
+			t.Errorf("generateLocationForProxying() returned ProxySSLSessionReuse %q but expected %q for the case of %s", loc.ProxySSLSessionReuse, test.expectedSessionReuse, test.msg)
+		}
+		if loc.ProxySSLProtocols != test.expectedProtocols {
+			t.Errorf("generateLocationForProxying() returned ProxySSLProtocols %q but expected %q for the case of %s", loc.ProxySSLProtocols, test.expectedProtocols, test.msg)
+		}
+		if loc.ProxySSLCiphers != test.expectedCiphers {
+			t.Errorf("generateLocationForProxying() returned ProxySSLCiphers %q but expected %q for the case of %s", loc.ProxySSLCiphers, test.expectedCiphers, test.msg)
+		}
+	}
+}
+
+func TestGenerateLocationForProxyingWithCacheIgnoreHeaders(t *testing.T) {
+	upstream := conf_v1.Upstream{
+		Cache: &conf_v1.UpstreamCache{
+			Valid:                map[string]string{"200": "10m"},
+			IgnoreHeaders:        []string{"Set-Cookie"},
+			OverrideCacheControl: true,
+		},
+	}
+
+	loc := generateLocationForProxying("/tea", "vs_default_cafe_tea", upstream, &ConfigParams{}, nil, false, 0, "", nil, "/tea")
+
+	expected := "Set-Cookie Cache-Control Expires X-Accel-Expires"
+	if loc.ProxyIgnoreHeaders != expected {
+		t.Errorf("generateLocationForProxying() returned ProxyIgnoreHeaders %q but expected %q", loc.ProxyIgnoreHeaders, expected)
+	}
+	if loc.ProxyCache == nil || !reflect.DeepEqual(loc.ProxyCache.Valid, []version2.ProxyCacheValid{{Code: "200", Time: "10m"}}) {
+		t.Errorf("generateLocationForProxying() returned ProxyCache %+v without the caching times", loc.ProxyCache)
+	}
+}
+
+func TestGenerateRedirectURL(t *testing.T) {
+	tests := []struct {
+		redirect *conf_v1.ActionRedirect
+		expected string
+	}{
+		{
+			redirect: &conf_v1.ActionRedirect{
+				URL: "https://cafe.example.com/menu",
+			},
+			expected: "https://cafe.example.com/menu",
+		},
+		{
+			redirect: &conf_v1.ActionRedirect{
+				URL:           "https://cafe.example.com/",
+				PreservePath:  true,
+				PreserveQuery: true,
+			},
+			expected: "https://cafe.example.com$request_uri",
+		},
+		{
+			redirect: &conf_v1.ActionRedirect{
+				URL:          "${scheme}://tea.example.com/v2",
+				PreservePath: true,
+			},
+			expected: "${scheme}://tea.example.com/v2$request_uri_path",
+		},
+		{
+			redirect: &conf_v1.ActionRedirect{
+				URL:           "https://cafe.example.com/menu",
+				PreserveQuery: true,
+			},
+			expected: "https://cafe.example.com/menu$is_args$args",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateRedirectURL(test.redirect)
+		if result != test.expected {
+			t.Errorf("generateRedirectURL(%+v) returned %q but expected %q", test.redirect, result, test.expected)
+		}
+	}
+}
+
+func TestGenerateLocationForRedirectActionWithPreservedQuery(t *testing.T) {
+	action := &conf_v1.Action{
+		Redirect: &conf_v1.ActionRedirect{
+			URL:           "https://cafe.example.com/menu",
+			Code:          302,
+			PreserveQuery: true,
+		},
+	}
+
+	expected := &version2.Return{
+		Code: 302,
+		Text: "https://cafe.example.com/menu$is_args$args",
+	}
+
+	result := generateLocation("/old-menu", "vs_default_cafe_tea", conf_v1.Upstream{}, action, &ConfigParams{}, nil, false, 0, "", "/old-menu")
+	if !reflect.DeepEqual(result.Return, expected) {
+		t.Errorf("generateLocation() returned the return %+v but expected %+v", result.Return, expected)
+	}
+}
+
+func TestGenerateLocationForReturnAction(t *testing.T) {
+	action := &conf_v1.Action{
+		Return: &conf_v1.ActionReturn{
+			Code: 503,
+			Body: "The service is under maintenance",
+			Headers: []conf_v1.Header{
+				{
+					Name:  "Retry-After",
+					Value: "3600",
+				},
+			},
+		},
+	}
+	cfgParams := &ConfigParams{
+		LocationSnippets: []string{"# location snippet"},
+	}
+
+	expected := version2.Location{
+		Path:        "/maintenance",
+		Snippets:    []string{"# location snippet"},
+		DefaultType: "text/plain",
+		Return: &version2.Return{
+			Code: 503,
+			Text: "The service is under maintenance",
+		},
+		AddHeaders: []version2.AddHeader{
+			{
+				Header: version2.Header{
+					Name:  "Retry-After",
+					Value: "3600",
+				},
+				Always: true,
+			},
+		},
+	}
+
+	result := generateLocation("/maintenance", "vs_default_cafe_tea", conf_v1.Upstream{}, action, cfgParams, nil, false, 0, "", "/maintenance")
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateLocation() returned %+v but expected %+v", result, expected)
+	}
+}
+
+func TestGenerateSSLConfig(t *testing.T) {
+	tests := []struct {
+		inputTLS            *conf_v1.TLS
+		inputTLSPemFileName string
+		inputCfgParams      *ConfigParams
+		expected            *version2.SSL
+		msg                 string
+	}{
+		{
+			inputTLS:            nil,
+			inputTLSPemFileName: "",
+			inputCfgParams:      &ConfigParams{},
+			expected:            nil,
+			msg:                 "no TLS field",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "",
+			},
+			inputTLSPemFileName: "",
+			inputCfgParams:      &ConfigParams{},
+			expected:            nil,
+			msg:                 "TLS field with empty secret",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "secret",
+			},
+			inputTLSPemFileName: "",
+			inputCfgParams:      &ConfigParams{},
+			expected: &version2.SSL{
+				HTTP2:          false,
+				Certificate:    pemFileNameForMissingTLSSecret,
+				CertificateKey: pemFileNameForMissingTLSSecret,
+				Ciphers:        "NULL",
+			},
+			msg: "secret doesn't exist in the cluster with HTTP2",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "secret",
+			},
+			inputTLSPemFileName: "secret.pem",
+			inputCfgParams:      &ConfigParams{},
+			expected: &version2.SSL{
+				HTTP2:          false,
+				Certificate:    "secret.pem",
+				CertificateKey: "secret.pem",
+				Ciphers:        "",
+			},
+			msg: "normal case with HTTP2",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateSSLConfig(test.inputTLS, test.inputTLSPemFileName, test.inputCfgParams)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateSSLConfig() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateDynamicSSLConfig(t *testing.T) {
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Host: "cafe.example.com",
+			TLS: &conf_v1.TLS{
+				DynamicCertificates: true,
+			},
+		},
+	}
+
+	cfgParams := ConfigParams{HTTP2: true}
+	vsc := newVirtualServerConfigurator(&cfgParams, true, false, &StaticConfigParams{DynamicSSLCertsDirectory: "/etc/nginx/dynamic-certificates"})
+
+	expected := &version2.SSL{
+		HTTP2:          true,
+		Certificate:    "$dynamic_ssl_certificate",
+		CertificateKey: "$dynamic_ssl_certificate",
+	}
+
+	result := vsc.generateDynamicSSLConfig(vs)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateDynamicSSLConfig() returned %v but expected %v", result, expected)
+	}
+	if len(vsc.warnings) != 0 {
+		t.Errorf("generateDynamicSSLConfig() returned unexpected warnings: %v", vsc.warnings)
+	}
+
+	vsWithSecret := vs.DeepCopy()
+	vsWithSecret.Spec.TLS = &conf_v1.TLS{Secret: "cafe-secret"}
+	if result := vsc.generateDynamicSSLConfig(vsWithSecret); result != nil {
+		t.Errorf("generateDynamicSSLConfig() returned %v for a VirtualServer without dynamic certificates", result)
+	}
+
+	vsc = newVirtualServerConfigurator(&cfgParams, true, false, &StaticConfigParams{})
+	if result := vsc.generateDynamicSSLConfig(vs); result != nil {
+		t.Errorf("generateDynamicSSLConfig() returned %v when the dynamic certificates are not enabled", result)
+	}
+	if len(vsc.warnings[vs]) != 1 {
+		t.Errorf("generateDynamicSSLConfig() returned %d warnings but expected 1 when the dynamic certificates are not enabled", len(vsc.warnings[vs]))
+	}
+}
+
+func TestGenerateConnectionLimit(t *testing.T) {
+	rejectCode := 429
+	tests := []struct {
+		connectionLimit *conf_v1.ConnectionLimit
+		expectedZone    *version2.LimitConnZone
+		expectedLimit   *version2.LimitConn
+		expectedStatus  int
+		msg             string
+	}{
+		{
+			connectionLimit: nil,
+			expectedZone:    nil,
+			expectedLimit:   nil,
+			expectedStatus:  0,
+			msg:             "no connection limit",
+		},
+		{
+			connectionLimit: &conf_v1.ConnectionLimit{
+				Key:         "${binary_remote_addr}",
+				Connections: 10,
+			},
+			expectedZone: &version2.LimitConnZone{
+				Key:      "${binary_remote_addr}",
+				ZoneName: "vs_cl_default_cafe",
+				ZoneSize: "10m",
+			},
+			expectedLimit: &version2.LimitConn{
+				Zone:  "vs_cl_default_cafe",
+				Limit: 10,
+			},
+			expectedStatus: 0,
+			msg:            "connection limit with the defaults",
+		},
+		{
+			connectionLimit: &conf_v1.ConnectionLimit{
+				Key:         "${uri}",
+				Connections: 100,
+				ZoneSize:    "20m",
+				RejectCode:  &rejectCode,
+			},
+			expectedZone: &version2.LimitConnZone{
+				Key:      "${uri}",
+				ZoneName: "vs_cl_default_cafe",
+				ZoneSize: "20m",
+			},
+			expectedLimit: &version2.LimitConn{
+				Zone:  "vs_cl_default_cafe",
+				Limit: 100,
+			},
+			expectedStatus: 429,
+			msg:            "connection limit with a zone size and a reject code",
+		},
+	}
+
+	for _, test := range tests {
+		vs := &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				ConnectionLimit: test.connectionLimit,
+			},
+		}
+
+		zone, limit, status := generateConnectionLimit(&VirtualServerEx{VirtualServer: vs}, "10m")
+		if !reflect.DeepEqual(zone, test.expectedZone) {
+			t.Errorf("generateConnectionLimit() returned zone %+v but expected %+v for the case of %s", zone, test.expectedZone, test.msg)
+		}
+		if !reflect.DeepEqual(limit, test.expectedLimit) {
+			t.Errorf("generateConnectionLimit() returned limit %+v but expected %+v for the case of %s", limit, test.expectedLimit, test.msg)
+		}
+		if status != test.expectedStatus {
+			t.Errorf("generateConnectionLimit() returned status %d but expected %d for the case of %s", status, test.expectedStatus, test.msg)
+		}
+	}
+}
+
+func TestGenerateConnectionLimitConnections(t *testing.T) {
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				ConnectionLimit: &conf_v1.ConnectionLimit{
+					Key:                    "${binary_remote_addr}",
+					ConnectionsPerEndpoint: 10,
+					Upstream:               "tea",
+				},
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "coffee",
+						Service: "coffee-svc",
+						Port:    80,
+					},
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
+				},
+			},
+		},
+		Endpoints: map[string][]string{
+			"default/coffee-svc:80": {"10.0.0.1:80", "10.0.0.2:80", "10.0.0.3:80", "10.0.0.4:80"},
+		},
+	}
+
+	tests := []struct {
+		endpoints []string
+		expected  int
+		msg       string
+	}{
+		{
+			endpoints: nil,
+			expected:  10,
+			msg:       "no endpoints",
+		},
+		{
+			endpoints: []string{"10.0.1.1:80"},
+			expected:  10,
+			msg:       "one endpoint",
+		},
+		{
+			endpoints: []string{"10.0.1.1:80", "10.0.1.2:80", "10.0.1.3:80"},
+			expected:  30,
+			msg:       "scaled up to three endpoints",
+		},
+	}
+
+	for _, test := range tests {
+		virtualServerEx.Endpoints["default/tea-svc:80"] = test.endpoints
+
+		result := generateConnectionLimitConnections(&virtualServerEx)
+		if result != test.expected {
+			t.Errorf("generateConnectionLimitConnections() returned %d but expected %d for the case of %s", result, test.expected, test.msg)
+		}
+	}
+
+	// the endpoints of all services of the upstream are counted, and an endpoint shared by the services is counted once
+	virtualServerEx.VirtualServer.Spec.Upstreams[1].Services = []string{"green-tea-svc", "black-tea-svc"}
+	virtualServerEx.Endpoints["default/tea-svc:80"] = []string{"10.0.1.1:80"}
+	virtualServerEx.Endpoints["default/green-tea-svc:80"] = []string{"10.0.1.1:80", "10.0.2.1:80"}
+	virtualServerEx.Endpoints["default/black-tea-svc:80"] = []string{"10.0.3.1:80", "10.0.3.2:80"}
+	if result := generateConnectionLimitConnections(&virtualServerEx); result != 40 {
+		t.Errorf("generateConnectionLimitConnections() returned %d but expected 40 for an upstream with multiple services", result)
+	}
+
+	virtualServerEx.VirtualServer.Spec.ConnectionLimit = &conf_v1.ConnectionLimit{
+		Key:         "${binary_remote_addr}",
+		Connections: 5,
+	}
+	if result := generateConnectionLimitConnections(&virtualServerEx); result != 5 {
+		t.Errorf("generateConnectionLimitConnections() returned %d but expected 5 for a fixed limit", result)
+	}
+}
+
+func TestAddConnectionLimitToLocations(t *testing.T) {
+	serverLimit := version2.LimitConn{Zone: "vs_cl_default_cafe", Limit: 10}
+	upstreamLimit := version2.LimitConn{Zone: "conn_limit_policy", Limit: 5}
+	policyLimit := version2.LimitConn{Zone: "pol_cl_default_limit_default_cafe", Limit: 2}
+
+	locations := []version2.Location{
+		{Path: "/"},
+		{Path: "/tea", LimitConns: []version2.LimitConn{upstreamLimit}},
+		{Path: "/coffee", LimitConns: []version2.LimitConn{policyLimit}},
+		{Path: "/juice", LimitConns: []version2.LimitConn{serverLimit}},
+	}
+
+	expected := []version2.Location{
+		{Path: "/"},
+		{Path: "/tea", LimitConns: []version2.LimitConn{upstreamLimit, serverLimit}},
+		{Path: "/coffee", LimitConns: []version2.LimitConn{policyLimit, serverLimit}},
+		{Path: "/juice", LimitConns: []version2.LimitConn{serverLimit}},
+	}
+
+	addConnectionLimitToLocations(locations, serverLimit)
+	if !reflect.DeepEqual(locations, expected) {
+		t.Errorf("addConnectionLimitToLocations() returned %+v but expected %+v", locations, expected)
+	}
+}
+
+func TestGenerateErrorBackend(t *testing.T) {
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			ErrorBackend: &conf_v1.ErrorBackend{
+				Upstream: "errors",
+				Codes:    []int{404, 502, 503},
+			},
+		},
+	}
+	crUpstreams := map[string]conf_v1.Upstream{
+		"vs_default_cafe_errors": {
+			Name:             "errors",
+			Service:          "errors-svc",
+			Port:             80,
+			ProxyReadTimeout: "5s",
+		},
+	}
+	cfgParams := &ConfigParams{
+		ProxyConnectTimeout: "30s",
+		ProxyReadTimeout:    "60s",
+	}
+
+	expectedErrorPage := &version2.ErrorPage{
+		Name:  "@error_backend",
+		Codes: "404 502 503",
+	}
+	expectedProxySetHeaders := []version2.Header{
+		{Name: "X-Code", Value: "$status"},
+		{Name: "X-Original-URI", Value: "$request_uri"},
+	}
+
+	errorPage, loc := generateErrorBackend(vs, newUpstreamNamerForVirtualServer(vs), crUpstreams, cfgParams)
+	if !reflect.DeepEqual(errorPage, expectedErrorPage) {
+		t.Errorf("generateErrorBackend() returned the error page %+v but expected %+v", errorPage, expectedErrorPage)
+	}
+	if loc == nil {
+		t.Fatalf("generateErrorBackend() returned no location")
+	}
+	if loc.Path != "@error_backend" {
+		t.Errorf("generateErrorBackend() returned the location with the path %q but expected %q", loc.Path, "@error_backend")
+	}
+	if loc.ProxyPass != "http://vs_default_cafe_errors" {
+		t.Errorf("generateErrorBackend() returned the location with proxy_pass %q but expected %q", loc.ProxyPass, "http://vs_default_cafe_errors")
+	}
+	if loc.ProxyReadTimeout != "5s" || loc.ProxyConnectTimeout != "30s" {
+		t.Errorf("generateErrorBackend() returned the location with the timeouts %q and %q but expected the timeouts of the upstream and the ConfigMap", loc.ProxyReadTimeout, loc.ProxyConnectTimeout)
+	}
+	if loc.ProxyInterceptErrors || len(loc.ErrorPages) > 0 {
+		t.Errorf("generateErrorBackend() returned the location that intercepts the errors of the error backend")
+	}
+	if !reflect.DeepEqual(loc.ProxySetHeaders, expectedProxySetHeaders) {
+		t.Errorf("generateErrorBackend() returned the location with the headers %+v but expected %+v", loc.ProxySetHeaders, expectedProxySetHeaders)
+	}
+
+	vs.Spec.ErrorBackend = nil
+	errorPage, loc = generateErrorBackend(vs, newUpstreamNamerForVirtualServer(vs), crUpstreams, cfgParams)
+	if errorPage != nil || loc != nil {
+		t.Errorf("generateErrorBackend() returned %+v and %+v for a VirtualServer without an error backend", errorPage, loc)
+	}
+}
+
+func TestAddErrorBackendToLocations(t *testing.T) {
+	errorPage := version2.ErrorPage{Name: "@error_backend", Codes: "404 500"}
+	routeErrorPage := version2.ErrorPage{Name: "@error_page_0_0", Codes: "404", ResponseCode: 200}
+
+	locations := []version2.Location{
+		{Path: "/tea", ProxyPass: "http://vs_default_cafe_tea"},
+		{Path: "/coffee", ProxyPass: "http://vs_default_cafe_coffee", ProxyInterceptErrors: true, ErrorPages: []version2.ErrorPage{routeErrorPage}},
+		{Path: "/return", Return: &version2.Return{Code: 200, Text: "hello"}},
+	}
+
+	expected := []version2.Location{
+		{Path: "/tea", ProxyPass: "http://vs_default_cafe_tea", ProxyInterceptErrors: true, ErrorPages: []version2.ErrorPage{errorPage}},
+		{Path: "/coffee", ProxyPass: "http://vs_default_cafe_coffee", ProxyInterceptErrors: true, ErrorPages: []version2.ErrorPage{routeErrorPage, errorPage}},
+		{Path: "/return", Return: &version2.Return{Code: 200, Text: "hello"}},
+	}
+
+	addErrorBackendToLocations(locations, errorPage)
+	if !reflect.DeepEqual(locations, expected) {
+		t.Errorf("addErrorBackendToLocations() returned %+v but expected %+v", locations, expected)
+	}
+}
+
+func TestAddTLSSessionToSSLConfig(t *testing.T) {
+	ticketsOff := false
+	createVirtualServer := func(tls *conf_v1.TLS) *conf_v1.VirtualServer {
+		return &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				TLS: tls,
+			},
+		}
+	}
+	tests := []struct {
+		tls                      *conf_v1.TLS
+		sessionTicketKeyFileName string
+		expected                 version2.SSL
+		expectedWarnings         int
+		msg                      string
+	}{
+		{
+			tls:                      &conf_v1.TLS{Secret: "secret"},
+			sessionTicketKeyFileName: "",
+			expected:                 version2.SSL{},
+			expectedWarnings:         0,
+			msg:                      "no session options",
+		},
+		{
+			tls: &conf_v1.TLS{
+				Secret:           "secret",
+				SessionCache:     "10m",
+				SessionTimeout:   "1h",
+				SessionTicketKey: "ticket-key",
+			},
+			sessionTicketKeyFileName: "/etc/nginx/secrets/default-ticket-key",
+			expected: version2.SSL{
+				SessionCache:     "shared:vs_default_cafe_ssl:10m",
+				SessionTimeout:   "1h",
+				SessionTicketKey: "/etc/nginx/secrets/default-ticket-key",
+			},
+			expectedWarnings: 0,
+			msg:              "shared cache and ticket key",
+		},
+		{
+			tls: &conf_v1.TLS{
+				Secret:         "secret",
+				SessionCache:   "off",
+				SessionTickets: &ticketsOff,
+			},
+			sessionTicketKeyFileName: "",
+			expected: version2.SSL{
+				SessionCache:   "off",
+				SessionTickets: "off",
+			},
+			expectedWarnings: 0,
+			msg:              "cache and tickets off",
+		},
+		{
+			tls: &conf_v1.TLS{
+				Secret:           "secret",
+				SessionTicketKey: "ticket-key",
+			},
+			sessionTicketKeyFileName: "",
+			expected:                 version2.SSL{},
+			expectedWarnings:         1,
+			msg:                      "missing ticket key secret",
+		},
+	}
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{})
+		vs := createVirtualServer(test.tls)
+
+		ssl := version2.SSL{}
+		vsc.addTLSSessionToSSLConfig(&ssl, vs, test.sessionTicketKeyFileName)
+		if !reflect.DeepEqual(ssl, test.expected) {
+			t.Errorf("addTLSSessionToSSLConfig() returned %+v but expected %+v for the case of %s", ssl, test.expected, test.msg)
+		}
+		if len(vsc.warnings[vs]) != test.expectedWarnings {
+			t.Errorf("addTLSSessionToSSLConfig() returned warnings %v but expected %d for the case of %s", vsc.warnings[vs], test.expectedWarnings, test.msg)
+		}
+	}
+}
+
+func TestGenerateRedirectConfig(t *testing.T) {
+	tests := []struct {
+		inputTLS *conf_v1.TLS
+		expected *version2.TLSRedirect
+		msg      string
+	}{
+		{
+			inputTLS: nil,
+			expected: nil,
+			msg:      "no TLS field",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret:   "secret",
+				Redirect: nil,
+			},
+			expected: nil,
+			msg:      "no redirect field",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret:   "secret",
+				Redirect: &conf_v1.TLSRedirect{Enable: false},
+			},
+			expected: nil,
+			msg:      "redirect disabled",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "secret",
+				Redirect: &conf_v1.TLSRedirect{
+					Enable: true,
+				},
+			},
+			expected: &version2.TLSRedirect{
+				Code:    301,
+				BasedOn: "$scheme",
+			},
+			msg: "normal case with defaults",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "secret",
+				Redirect: &conf_v1.TLSRedirect{
+					Enable:  true,
+					BasedOn: "x-forwarded-proto",
+				},
+			},
+			expected: &version2.TLSRedirect{
+				Code:    301,
+				BasedOn: "$http_x_forwarded_proto",
+			},
+			msg: "normal case with BasedOn set",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateTLSRedirectConfig(test.inputTLS)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateTLSRedirectConfig() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateTLSRedirectBasedOn(t *testing.T) {
+	tests := []struct {
+		basedOn  string
+		expected string
+	}{
+		{
+			basedOn:  "scheme",
+			expected: "$scheme",
+		},
+		{
+			basedOn:  "x-forwarded-proto",
+			expected: "$http_x_forwarded_proto",
+		},
+		{
+			basedOn:  "",
+			expected: "$scheme",
+		},
+	}
+	for _, test := range tests {
+		result := generateTLSRedirectBasedOn(test.basedOn)
+		if result != test.expected {
+			t.Errorf("generateTLSRedirectBasedOn(%v) returned %v but expected %v", test.basedOn, result, test.expected)
+		}
+	}
+}
+
+func TestCreateUpstreamsForPlus(t *testing.T) {
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
+					{
+						Name:    "test",
+						Service: "test-svc",
+						Port:    80,
+					},
+					{
+						Name:        "subselector-test",
+						Service:     "test-svc",
+						Subselector: map[string]string{"vs": "works"},
+						Port:        80,
+					},
+					{
+						Name:    "external",
+						Service: "external-svc",
+						Port:    80,
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+					},
+					{
+						Path:  "/coffee",
+						Route: "default/coffee",
+					},
+					{
+						Path: "/external",
+						Action: &conf_v1.Action{
+							Pass: "external",
+						},
+					},
+				},
+			},
+		},
+		Endpoints: map[string][]string{
+			"default/tea-svc:80": {
+				"10.0.0.20:80",
+			},
+			"default/test-svc:80": {},
+			"default/test-svc_vs=works:80": {
+				"10.0.0.30:80",
+			},
+			"default/coffee-svc:80": {
+				"10.0.0.40:80",
+			},
+			"default/test-svc_vsr=works:80": {
+				"10.0.0.50:80",
+			},
+			"default/external-svc:80": {
+				"example.com:80",
+			},
+		},
+		ExternalNameSvcs: map[string]bool{
+			"default/external-svc": true,
+		},
+		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+			{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "coffee",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerRouteSpec{
+					Host: "cafe.example.com",
+					Upstreams: []conf_v1.Upstream{
+						{
+							Name:    "coffee",
+							Service: "coffee-svc",
+							Port:    80,
+						},
+						{
+							Name:        "subselector-test",
+							Service:     "test-svc",
+							Subselector: map[string]string{"vsr": "works"},
+							Port:        80,
+						},
+					},
+					Subroutes: []conf_v1.Route{
+						{
+							Path: "/coffee",
+							Action: &conf_v1.Action{
+								Pass: "coffee",
+							},
+						},
+						{
+							Path: "/coffee/sub",
+							Action: &conf_v1.Action{
+								Pass: "subselector-test",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected := []version2.Upstream{
+		{
+			Name: "vs_default_cafe_tea",
+			Servers: []version2.UpstreamServer{
+				{
+					Address: "10.0.0.20:80",
+				},
+			},
+		},
+		{
+			Name:    "vs_default_cafe_test",
+			Servers: nil,
+		},
+		{
+			Name: "vs_default_cafe_subselector-test",
+			Servers: []version2.UpstreamServer{
+				{
+					Address: "10.0.0.30:80",
+				},
+			},
+		},
+		{
+			Name: "vs_default_cafe_vsr_default_coffee_coffee",
+			Servers: []version2.UpstreamServer{
+				{
+					Address: "10.0.0.40:80",
+				},
+			},
+		},
+		{
+			Name: "vs_default_cafe_vsr_default_coffee_subselector-test",
+			Servers: []version2.UpstreamServer{
+				{
+					Address: "10.0.0.50:80",
+				},
+			},
+		},
+	}
+
+	result := createUpstreamsForPlus(&virtualServerEx, &ConfigParams{}, &StaticConfigParams{})
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("createUpstreamsForPlus returned \n%v but expected \n%v", result, expected)
+	}
+}
+
+func TestCreateUpstreamServersConfigForPlus(t *testing.T) {
+	upstream := version2.Upstream{
+		Servers: []version2.UpstreamServer{
+			{
+				Address: "10.0.0.20:80",
+			},
+		},
+		MaxFails:    21,
+		MaxConns:    16,
+		FailTimeout: "30s",
+		SlowStart:   "50s",
+	}
+
+	expected := nginx.ServerConfig{
+		MaxFails:    21,
+		MaxConns:    16,
+		FailTimeout: "30s",
+		SlowStart:   "50s",
+	}
+
+	result := createUpstreamServersConfigForPlus(upstream)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("createUpstreamServersConfigForPlus returned %v but expected %v", result, expected)
+	}
+}
+
+func TestCreateUpstreamServersConfigForPlusWithBackup(t *testing.T) {
+	upstream := version2.Upstream{
+		Servers: []version2.UpstreamServer{
+			{
+				Address: "10.0.0.20:80",
+			},
+			{
+				Address: "10.0.0.30:80",
+				Backup:  true,
+			},
+		},
+		MaxFails:    1,
+		FailTimeout: "10s",
+	}
+
+	expected := nginx.ServerConfig{
+		MaxFails:      1,
+		FailTimeout:   "10s",
+		BackupServers: []string{"10.0.0.30:80"},
+	}
+
+	result := createUpstreamServersConfigForPlus(upstream)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("createUpstreamServersConfigForPlus returned %v but expected %v", result, expected)
+	}
+
+	expectedEndpoints := []string{"10.0.0.20:80"}
+	endpoints := createEndpointsFromUpstream(upstream)
+	if !reflect.DeepEqual(endpoints, expectedEndpoints) {
+		t.Errorf("createEndpointsFromUpstream returned %v but expected %v", endpoints, expectedEndpoints)
+	}
+}
+
+func TestCreateUpstreamServersConfigForPlusWithDown(t *testing.T) {
+	upstream := version2.Upstream{
+		Servers: []version2.UpstreamServer{
+			{
+				Address: "10.0.0.20:80",
+				Down:    true,
+			},
+			{
+				Address: "10.0.0.21:80",
+			},
+		},
+		MaxFails:    1,
+		FailTimeout: "10s",
+	}
+
+	expected := nginx.ServerConfig{
+		MaxFails:    1,
+		FailTimeout: "10s",
+		DownServers: []string{"10.0.0.20:80"},
+	}
+
+	result := createUpstreamServersConfigForPlus(upstream)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("createUpstreamServersConfigForPlus returned %v but expected %v", result, expected)
+	}
+}
+
+func TestCreateUpstreamServersConfigForPlusNoUpstreams(t *testing.T) {
+	noUpstream := version2.Upstream{}
+	expected := nginx.ServerConfig{}
+
+	result := createUpstreamServersConfigForPlus(noUpstream)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("createUpstreamServersConfigForPlus returned %v but expected %v", result, expected)
+	}
+}
+
+func TestGenerateSplits(t *testing.T) {
+	originalPath := "/path"
+	splits := []conf_v1.Split{
+		{
+			Weight: 90,
+			Action: &conf_v1.Action{
+				Proxy: &conf_v1.ActionProxy{
+					Upstream:    "coffee-v1",
+					RewritePath: "/rewrite",
+				},
+			},
+		},
+		{
+			Weight: 10,
+			Action: &conf_v1.Action{
+				Pass: "coffee-v2",
+			},
+		},
+	}
+
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
+	variableNamer := newVariableNamer(&virtualServer)
+	scIndex := 1
+	cfgParams := ConfigParams{}
+	crUpstreams := map[string]conf_v1.Upstream{
+		"vs_default_cafe_coffee-v1": {
+			Service: "coffee-v1",
+		},
+		"vs_default_cafe_coffee-v2": {
+			Service: "coffee-v2",
+		},
+	}
+	errorPages := []conf_v1.ErrorPage{
+		{
+			Codes: []int{400, 500},
+			Return: &conf_v1.ErrorPageReturn{
+				ActionReturn: conf_v1.ActionReturn{
+					Code: 200,
+					Type: "application/json",
+					Body: `{\"message\": \"ok\"}`,
+				},
+				Headers: []conf_v1.Header{
+					{
+						Name:  "Set-Cookie",
+						Value: "cookie1=value",
+					},
+				},
+			},
+			Redirect: nil,
+		},
+		{
+			Codes:  []int{500, 502},
+			Return: nil,
+			Redirect: &conf_v1.ErrorPageRedirect{
+				ActionRedirect: conf_v1.ActionRedirect{
+					URL:  "http://nginx.com",
+					Code: 301,
+				},
+			},
+		},
+	}
+
+	expectedSplitClient := version2.SplitClient{
+		Source:   "$request_id",
+		Variable: "$vs_default_cafe_splits_1",
+		Distributions: []version2.Distribution{
+			{
+				Weight: "90%",
+				Value:  "/internal_location_splits_1_split_0",
+			},
+			{
+				Weight: "10%",
+				Value:  "/internal_location_splits_1_split_1",
+			},
+		},
+	}
+	expectedLocations := []version2.Location{
+		{
+			Path:                     "/internal_location_splits_1_split_0",
+			ProxyPass:                "http://vs_default_cafe_coffee-v1",
+			Rewrites:                 []string{"^ $request_uri", fmt.Sprintf(`"^%v(.*)$" "/rewrite$1" break`, originalPath)},
+			ProxyNextUpstream:        "error timeout",
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyNextUpstreamTries:   0,
+			ProxyInterceptErrors:     true,
+			Internal:                 true,
+			ErrorPages: []version2.ErrorPage{
+				{
+					Name:         "@error_page_0_0",
+					Codes:        "400 500",
+					ResponseCode: 200,
+				},
+				{
+					Name:         "http://nginx.com",
+					Codes:        "500 502",
+					ResponseCode: 301,
+				},
+			},
+			ProxySSLName:            "coffee-v1.default.svc",
+			ProxyPassRequestHeaders: true,
+		},
+		{
+			Path:                     "/internal_location_splits_1_split_1",
+			ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
+			ProxyNextUpstream:        "error timeout",
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyNextUpstreamTries:   0,
+			ProxyInterceptErrors:     true,
+			Internal:                 true,
+			ErrorPages: []version2.ErrorPage{
+				{
+					Name:         "@error_page_0_0",
+					Codes:        "400 500",
+					ResponseCode: 200,
+				},
+				{
+					Name:         "http://nginx.com",
+					Codes:        "500 502",
+					ResponseCode: 301,
+				},
+			},
+			ProxySSLName:            "coffee-v2.default.svc",
+			ProxyPassRequestHeaders: true,
+		},
+	}
+
+	resultSplitClient, resultLocations := generateSplits(splits, upstreamNamer, crUpstreams, variableNamer, scIndex, &cfgParams, errorPages, 0, originalPath, nil)
+	if !reflect.DeepEqual(resultSplitClient, expectedSplitClient) {
+		t.Errorf("generateSplits() returned \n%+v but expected \n%+v", resultSplitClient, expectedSplitClient)
+	}
+	if !reflect.DeepEqual(resultLocations, expectedLocations) {
+		t.Errorf("generateSplits() returned \n%+v but expected \n%+v", resultLocations, expectedLocations)
+	}
+
+}
+
+func TestGenerateMirrorConfig(t *testing.T) {
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
+	variableNamer := newVariableNamer(&virtualServer)
+	cfgParams := ConfigParams{}
+	crUpstreams := map[string]conf_v1.Upstream{
+		"vs_default_cafe_tea-shadow": {
+			Service: "tea-shadow",
+		},
+	}
+
+	expectedLocation := version2.Location{
+		Path:                     "/internal_location_mirror_2",
+		ProxyPass:                "http://vs_default_cafe_tea-shadow$request_uri",
+		ProxyNextUpstream:        "error timeout",
+		ProxyNextUpstreamTimeout: "0s",
+		Internal:                 true,
+		ProxySSLName:             "tea-shadow.default.svc",
+		ProxyPassRequestHeaders:  true,
+	}
+
+	sampledLocation := expectedLocation
+	sampledLocation.MirrorGate = "$vs_default_cafe_mirror_2"
+
+	allRequests := 100
+	someRequests := 10
+
+	tests := []struct {
+		mirror   *conf_v1.Mirror
+		expected *mirrorConfig
+		msg      string
+	}{
+		{
+			mirror:   nil,
+			expected: nil,
+			msg:      "no mirror",
+		},
+		{
+			mirror: &conf_v1.Mirror{
+				Upstream: "tea-shadow",
+			},
+			expected: &mirrorConfig{
+				Location: expectedLocation,
+			},
+			msg: "all requests mirrored",
+		},
+		{
+			mirror: &conf_v1.Mirror{
+				Upstream:   "tea-shadow",
+				Percentage: &allRequests,
+			},
+			expected: &mirrorConfig{
+				Location: expectedLocation,
+			},
+			msg: "100 percent of requests mirrored",
+		},
+		{
+			mirror: &conf_v1.Mirror{
+				Upstream:   "tea-shadow",
+				Percentage: &someRequests,
+			},
+			expected: &mirrorConfig{
+				Location: sampledLocation,
+				SplitClient: &version2.SplitClient{
+					Source:   "$request_id",
+					Variable: "$vs_default_cafe_mirror_2",
+					Distributions: []version2.Distribution{
+						{
+							Weight: "10%",
+							Value:  "1",
+						},
+						{
+							Weight: "*",
+							Value:  `""`,
+						},
+					},
+				},
+			},
+			msg: "10 percent of requests mirrored",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateMirrorConfig(test.mirror, upstreamNamer, crUpstreams, variableNamer, 2, &cfgParams)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateMirrorConfig() returned \n%+v but expected \n%+v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateVirtualServerConfigWithSampledMirror(t *testing.T) {
+	percentage := 25
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
+					{
+						Name:    "tea-shadow",
+						Service: "tea-shadow-svc",
+						Port:    80,
+					},
 				},
-				Spec: conf_v1.VirtualServerRouteSpec{
-					Host: "cafe.example.com",
-					Upstreams: []conf_v1.Upstream{
-						{
-							Name:    "coffee",
-							Service: "coffee-svc",
-							Port:    80,
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Action: &conf_v1.Action{
+							Pass: "tea",
 						},
-						{
-							Name:        "subselector-test",
-							Service:     "test-svc",
-							Subselector: map[string]string{"vsr": "works"},
-							Port:        80,
+						Mirror: &conf_v1.Mirror{
+							Upstream:   "tea-shadow",
+							Percentage: &percentage,
 						},
 					},
-					Subroutes: []conf_v1.Route{
-						{
-							Path: "/coffee",
-							Action: &conf_v1.Action{
-								Pass: "coffee",
-							},
-						},
-						{
-							Path: "/coffee/sub",
-							Action: &conf_v1.Action{
-								Pass: "subselector-test",
-							},
+					{
+						Path: "/coffee",
+						Action: &conf_v1.Action{
+							Pass: "tea",
 						},
 					},
 				},
 			},
 		},
+		Endpoints: map[string][]string{
+			"default/tea-svc:80": {
+				"10.0.0.20:80",
+			},
+			"default/tea-shadow-svc:80": {
+				"10.0.0.30:80",
+			},
+		},
 	}
 
-	expected := []version2.Upstream{
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{})
+	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+	if len(warnings) != 0 {
+		t.Errorf("GenerateVirtualServerConfig() returned unexpected warnings: %v", warnings)
+	}
+
+	locations := result.Server.Locations
+	if len(locations) != 3 {
+		t.Fatalf("GenerateVirtualServerConfig() generated %d locations but expected 3", len(locations))
+	}
+
+	if locations[0].Path != "/tea" || locations[0].Mirror != "/internal_location_mirror_0" {
+		t.Errorf("GenerateVirtualServerConfig() generated the location %q with the mirror %q but expected /tea with /internal_location_mirror_0", locations[0].Path, locations[0].Mirror)
+	}
+
+	mirrorLocation := locations[1]
+	if mirrorLocation.Path != "/internal_location_mirror_0" || mirrorLocation.ProxyPass != "http://vs_default_cafe_tea-shadow$request_uri" ||
+		mirrorLocation.MirrorGate != "$vs_default_cafe_mirror_0" || !mirrorLocation.Internal {
+		t.Errorf("GenerateVirtualServerConfig() generated the unexpected mirror location %+v", mirrorLocation)
+	}
+
+	if locations[2].Path != "/coffee" || locations[2].Mirror != "" {
+		t.Errorf("GenerateVirtualServerConfig() generated the location %q with the mirror %q but expected /coffee without a mirror", locations[2].Path, locations[2].Mirror)
+	}
+
+	expectedSplitClients := []version2.SplitClient{
 		{
-			Name: "vs_default_cafe_tea",
-			Servers: []version2.UpstreamServer{
+			Source:   "$request_id",
+			Variable: "$vs_default_cafe_mirror_0",
+			Distributions: []version2.Distribution{
 				{
-					Address: "10.0.0.20:80",
+					Weight: "25%",
+					Value:  "1",
+				},
+				{
+					Weight: "*",
+					Value:  `""`,
 				},
 			},
 		},
+	}
+	if !reflect.DeepEqual(result.SplitClients, expectedSplitClients) {
+		t.Errorf("GenerateVirtualServerConfig() returned the split clients %+v but expected %+v", result.SplitClients, expectedSplitClients)
+	}
+}
+
+func TestApplySplitsCutover(t *testing.T) {
+	splits := []conf_v1.Split{
 		{
-			Name:    "vs_default_cafe_test",
-			Servers: nil,
+			Weight: 90,
+			Action: &conf_v1.Action{Pass: "coffee-v1"},
 		},
 		{
-			Name: "vs_default_cafe_subselector-test",
-			Servers: []version2.UpstreamServer{
-				{
-					Address: "10.0.0.30:80",
-				},
-			},
+			Weight: 10,
+			Action: &conf_v1.Action{Pass: "coffee-v2"},
 		},
+	}
+
+	tests := []struct {
+		cutover         string
+		expectedWeights []int
+		msg             string
+	}{
 		{
-			Name: "vs_default_cafe_vsr_default_coffee_coffee",
-			Servers: []version2.UpstreamServer{
+			cutover:         "",
+			expectedWeights: []int{90, 10},
+			msg:             "no cutover",
+		},
+		{
+			cutover:         conf_v1.CutoverPromote,
+			expectedWeights: []int{0, 100},
+			msg:             "promote",
+		},
+		{
+			cutover:         conf_v1.CutoverRollback,
+			expectedWeights: []int{100, 0},
+			msg:             "rollback",
+		},
+	}
+
+	for _, test := range tests {
+		result := applySplitsCutover(splits, test.cutover)
+
+		var weights []int
+		for _, s := range result {
+			weights = append(weights, s.Weight)
+		}
+		if !reflect.DeepEqual(weights, test.expectedWeights) {
+			t.Errorf("applySplitsCutover() returned weights %v but expected %v for the case of %s", weights, test.expectedWeights, test.msg)
+		}
+		if result[0].Action != splits[0].Action || result[1].Action != splits[1].Action {
+			t.Errorf("applySplitsCutover() didn't keep the actions of the splits for the case of %s", test.msg)
+		}
+	}
+
+	if splits[0].Weight != 90 || splits[1].Weight != 10 {
+		t.Errorf("applySplitsCutover() modified the weights of the original splits")
+	}
+}
+
+func TestGenerateSplitsWithCutover(t *testing.T) {
+	splits := []conf_v1.Split{
+		{
+			Weight: 90,
+			Action: &conf_v1.Action{Pass: "coffee-v1"},
+		},
+		{
+			Weight: 10,
+			Action: &conf_v1.Action{Pass: "coffee-v2"},
+		},
+	}
+
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
+	variableNamer := newVariableNamer(&virtualServer)
+	crUpstreams := map[string]conf_v1.Upstream{
+		"vs_default_cafe_coffee-v1": {
+			Service: "coffee-v1",
+		},
+		"vs_default_cafe_coffee-v2": {
+			Service: "coffee-v2",
+		},
+	}
+
+	tests := []struct {
+		cutover               string
+		expectedDistributions []version2.Distribution
+	}{
+		{
+			cutover: conf_v1.CutoverPromote,
+			expectedDistributions: []version2.Distribution{
 				{
-					Address: "10.0.0.40:80",
+					Weight: "100%",
+					Value:  "/internal_location_splits_1_split_1",
 				},
 			},
 		},
 		{
-			Name: "vs_default_cafe_vsr_default_coffee_subselector-test",
-			Servers: []version2.UpstreamServer{
+			cutover: conf_v1.CutoverRollback,
+			expectedDistributions: []version2.Distribution{
 				{
-					Address: "10.0.0.50:80",
+					Weight: "100%",
+					Value:  "/internal_location_splits_1_split_0",
 				},
 			},
 		},
 	}
 
-	result := createUpstreamsForPlus(&virtualServerEx, &ConfigParams{}, &StaticConfigParams{})
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("createUpstreamsForPlus returned \n%v but expected \n%v", result, expected)
+	for _, test := range tests {
+		resultSplitClient, resultLocations := generateSplits(applySplitsCutover(splits, test.cutover), upstreamNamer, crUpstreams, variableNamer, 1, &ConfigParams{}, nil, 0, "/coffee", nil)
+		if !reflect.DeepEqual(resultSplitClient.Distributions, test.expectedDistributions) {
+			t.Errorf("generateSplits() returned distributions \n%+v but expected \n%+v for the %s cutover", resultSplitClient.Distributions, test.expectedDistributions, test.cutover)
+		}
+		if len(resultLocations) != 2 {
+			t.Errorf("generateSplits() returned %d locations but expected 2 for the %s cutover", len(resultLocations), test.cutover)
+		}
 	}
 }
 
-func TestCreateUpstreamServersConfigForPlus(t *testing.T) {
-	upstream := version2.Upstream{
-		Servers: []version2.UpstreamServer{
-			{
-				Address: "10.0.0.20:80",
-			},
+func TestGenerateRouteSplitsConfigWithCutover(t *testing.T) {
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
 		},
-		MaxFails:    21,
-		MaxConns:    16,
-		FailTimeout: "30s",
-		SlowStart:   "50s",
 	}
+	variableNamer := newVariableNamer(&virtualServer)
 
-	expected := nginx.ServerConfig{
-		MaxFails:    21,
-		MaxConns:    16,
-		FailTimeout: "30s",
-		SlowStart:   "50s",
+	route := conf_v1.Route{
+		Path: "/",
+		Splits: applySplitsCutover([]conf_v1.Split{
+			{
+				Weight: 80,
+				Route:  "coffee-blue",
+			},
+			{
+				Weight: 20,
+				Route:  "coffee-green",
+			},
+		}, conf_v1.CutoverPromote),
 	}
 
-	result := createUpstreamServersConfigForPlus(upstream)
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("createUpstreamServersConfigForPlus returned %v but expected %v", result, expected)
+	expectedDistributions := []version2.Distribution{
+		{
+			Weight: "100%",
+			Value:  "/internal_location_route_splits_0_split_1",
+		},
 	}
-}
-
-func TestCreateUpstreamServersConfigForPlusNoUpstreams(t *testing.T) {
-	noUpstream := version2.Upstream{}
-	expected := nginx.ServerConfig{}
 
-	result := createUpstreamServersConfigForPlus(noUpstream)
-	if !reflect.DeepEqual(result, expected) {
-		t.Errorf("createUpstreamServersConfigForPlus returned %v but expected %v", result, expected)
+	result := generateRouteSplitsConfig(route, variableNamer, 0)
+	if !reflect.DeepEqual(result.SplitClients[0].Distributions, expectedDistributions) {
+		t.Errorf("generateRouteSplitsConfig() returned distributions \n%+v but expected \n%+v", result.SplitClients[0].Distributions, expectedDistributions)
+	}
+	if len(result.Locations) != 2 {
+		t.Errorf("generateRouteSplitsConfig() returned %d locations but expected 2", len(result.Locations))
 	}
 }
 
-func TestGenerateSplits(t *testing.T) {
-	originalPath := "/path"
+func TestGenerateSplitsWithStickySplits(t *testing.T) {
 	splits := []conf_v1.Split{
 		{
 			Weight: 90,
 			Action: &conf_v1.Action{
-				Proxy: &conf_v1.ActionProxy{
-					Upstream:    "coffee-v1",
-					RewritePath: "/rewrite",
-				},
+				Pass: "coffee-v1",
 			},
 		},
 		{
@@ -1908,8 +5515,6 @@ func TestGenerateSplits(t *testing.T) {
 	}
 	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
 	variableNamer := newVariableNamer(&virtualServer)
-	scIndex := 1
-	cfgParams := ConfigParams{}
 	crUpstreams := map[string]conf_v1.Upstream{
 		"vs_default_cafe_coffee-v1": {
 			Service: "coffee-v1",
@@ -1918,108 +5523,139 @@ func TestGenerateSplits(t *testing.T) {
 			Service: "coffee-v2",
 		},
 	}
-	errorPages := []conf_v1.ErrorPage{
-		{
-			Codes: []int{400, 500},
-			Return: &conf_v1.ErrorPageReturn{
-				ActionReturn: conf_v1.ActionReturn{
-					Code: 200,
-					Type: "application/json",
-					Body: `{\"message\": \"ok\"}`,
-				},
-				Headers: []conf_v1.Header{
-					{
-						Name:  "Set-Cookie",
-						Value: "cookie1=value",
-					},
-				},
-			},
-			Redirect: nil,
-		},
-		{
-			Codes:  []int{500, 502},
-			Return: nil,
-			Redirect: &conf_v1.ErrorPageRedirect{
-				ActionRedirect: conf_v1.ActionRedirect{
-					URL:  "http://nginx.com",
-					Code: 301,
-				},
-			},
+	stickySplits := &conf_v1.StickySplits{
+		Enable: true,
+	}
+
+	expectedSource := "$vs_default_cafe_splits_1_sticky_key"
+	expectedAddHeader := version2.AddHeader{
+		Header: version2.Header{
+			Name:  "Set-Cookie",
+			Value: "$vs_default_cafe_splits_1_sticky_cookie",
 		},
+		Always: true,
 	}
 
-	expectedSplitClient := version2.SplitClient{
-		Source:   "$request_id",
-		Variable: "$vs_default_cafe_splits_1",
-		Distributions: []version2.Distribution{
-			{
-				Weight: "90%",
-				Value:  "/internal_location_splits_1_split_0",
-			},
-			{
-				Weight: "10%",
-				Value:  "/internal_location_splits_1_split_1",
-			},
+	resultSplitClient, resultLocations := generateSplits(splits, upstreamNamer, crUpstreams, variableNamer, 1, &ConfigParams{}, nil, 0, "/", stickySplits)
+	if resultSplitClient.Source != expectedSource {
+		t.Errorf("generateSplits() returned a split client with the source %q but expected %q", resultSplitClient.Source, expectedSource)
+	}
+	for _, loc := range resultLocations {
+		if !reflect.DeepEqual(loc.AddHeaders, []version2.AddHeader{expectedAddHeader}) {
+			t.Errorf("generateSplits() returned a location %v with add headers %+v but expected %+v", loc.Path, loc.AddHeaders, expectedAddHeader)
+		}
+	}
+}
+
+func TestGenerateStickySplitsMaps(t *testing.T) {
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
 		},
 	}
-	expectedLocations := []version2.Location{
+	variableNamer := newVariableNamer(&virtualServer)
+
+	tests := []struct {
+		stickySplits *conf_v1.StickySplits
+		expected     []version2.Map
+		msg          string
+	}{
 		{
-			Path:                     "/internal_location_splits_1_split_0",
-			ProxyPass:                "http://vs_default_cafe_coffee-v1",
-			Rewrites:                 []string{"^ $request_uri", fmt.Sprintf(`"^%v(.*)$" "/rewrite$1" break`, originalPath)},
-			ProxyNextUpstream:        "error timeout",
-			ProxyNextUpstreamTimeout: "0s",
-			ProxyNextUpstreamTries:   0,
-			ProxyInterceptErrors:     true,
-			Internal:                 true,
-			ErrorPages: []version2.ErrorPage{
+			stickySplits: nil,
+			expected:     nil,
+			msg:          "no sticky splits",
+		},
+		{
+			stickySplits: &conf_v1.StickySplits{Enable: false, CookieName: "canary"},
+			expected:     nil,
+			msg:          "disabled sticky splits",
+		},
+		{
+			stickySplits: &conf_v1.StickySplits{Enable: true, CookieName: "canary"},
+			expected: []version2.Map{
 				{
-					Name:         "@error_page_0_0",
-					Codes:        "400 500",
-					ResponseCode: 200,
+					Source:   "$cookie_canary",
+					Variable: "$vs_default_cafe_splits_2_sticky_key",
+					Parameters: []version2.Parameter{
+						{
+							Value:  `""`,
+							Result: "$request_id",
+						},
+						{
+							Value:  "default",
+							Result: "$cookie_canary",
+						},
+					},
 				},
 				{
-					Name:         "http://nginx.com",
-					Codes:        "500 502",
-					ResponseCode: 301,
+					Source:   "$cookie_canary",
+					Variable: "$vs_default_cafe_splits_2_sticky_cookie",
+					Parameters: []version2.Parameter{
+						{
+							Value:  `""`,
+							Result: `"canary=$request_id; Path=/"`,
+						},
+						{
+							Value:  "default",
+							Result: `""`,
+						},
+					},
 				},
 			},
-			ProxySSLName:            "coffee-v1.default.svc",
-			ProxyPassRequestHeaders: true,
+			msg: "enabled sticky splits",
 		},
-		{
-			Path:                     "/internal_location_splits_1_split_1",
-			ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
-			ProxyNextUpstream:        "error timeout",
-			ProxyNextUpstreamTimeout: "0s",
-			ProxyNextUpstreamTries:   0,
-			ProxyInterceptErrors:     true,
-			Internal:                 true,
-			ErrorPages: []version2.ErrorPage{
-				{
-					Name:         "@error_page_0_0",
-					Codes:        "400 500",
-					ResponseCode: 200,
+	}
+
+	for _, test := range tests {
+		result := generateStickySplitsMaps(test.stickySplits, variableNamer, 2)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generateStickySplitsMaps() returned \n%+v but expected \n%+v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateDefaultSplitsConfigWithStickySplits(t *testing.T) {
+	route := conf_v1.Route{
+		Path: "/",
+		Splits: []conf_v1.Split{
+			{
+				Weight: 90,
+				Action: &conf_v1.Action{
+					Pass: "coffee-v1",
 				},
-				{
-					Name:         "http://nginx.com",
-					Codes:        "500 502",
-					ResponseCode: 301,
+			},
+			{
+				Weight: 10,
+				Action: &conf_v1.Action{
+					Pass: "coffee-v2",
 				},
 			},
-			ProxySSLName:            "coffee-v2.default.svc",
-			ProxyPassRequestHeaders: true,
+		},
+		StickySplits: &conf_v1.StickySplits{
+			Enable: true,
 		},
 	}
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
+	variableNamer := newVariableNamer(&virtualServer)
 
-	resultSplitClient, resultLocations := generateSplits(splits, upstreamNamer, crUpstreams, variableNamer, scIndex, &cfgParams, errorPages, 0, originalPath)
-	if !reflect.DeepEqual(resultSplitClient, expectedSplitClient) {
-		t.Errorf("generateSplits() returned \n%+v but expected \n%+v", resultSplitClient, expectedSplitClient)
+	result := generateDefaultSplitsConfig(route, upstreamNamer, map[string]conf_v1.Upstream{}, variableNamer, 0, &ConfigParams{}, nil, 0, "/")
+
+	if len(result.Maps) != 2 {
+		t.Fatalf("generateDefaultSplitsConfig() returned %d maps but expected 2", len(result.Maps))
 	}
-	if !reflect.DeepEqual(resultLocations, expectedLocations) {
-		t.Errorf("generateSplits() returned \n%+v but expected \n%+v", resultLocations, expectedLocations)
+	if result.Maps[0].Source != "$cookie_vs_split_id" {
+		t.Errorf("generateDefaultSplitsConfig() returned a map with the source %q but expected %q", result.Maps[0].Source, "$cookie_vs_split_id")
+	}
+	if result.SplitClients[0].Source != result.Maps[0].Variable {
+		t.Errorf("generateDefaultSplitsConfig() returned a split client with the source %q but expected %q", result.SplitClients[0].Source, result.Maps[0].Variable)
 	}
-
 }
 
 func TestGenerateDefaultSplitsConfig(t *testing.T) {
@@ -2479,6 +6115,96 @@ func TestGenerateMatchesConfig(t *testing.T) {
 	}
 }
 
+func TestGenerateMatchesConfigWithMultipleConditions(t *testing.T) {
+	route := conf_v1.Route{
+		Path: "/coffee",
+		Matches: []conf_v1.Match{
+			{
+				Conditions: []conf_v1.Condition{
+					{
+						Header: "x-version",
+						Value:  "v2",
+					},
+					{
+						Cookie: "user",
+						Value:  "beta",
+					},
+				},
+				Action: &conf_v1.Action{
+					Pass: "coffee-v2",
+				},
+			},
+		},
+		Action: &conf_v1.Action{
+			Pass: "coffee-v1",
+		},
+	}
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	upstreamNamer := newUpstreamNamerForVirtualServer(&virtualServer)
+	variableNamer := newVariableNamer(&virtualServer)
+	crUpstreams := map[string]conf_v1.Upstream{
+		"vs_default_cafe_coffee-v1": {Service: "coffee-v1"},
+		"vs_default_cafe_coffee-v2": {Service: "coffee-v2"},
+	}
+
+	// the result of the map of a condition is the variable of the map of the next condition,
+	// so the main map gets 1 only if all the conditions are satisfied.
+	expectedMaps := []version2.Map{
+		{
+			Source:   "$http_x_version",
+			Variable: "$vs_default_cafe_matches_0_match_0_cond_0",
+			Parameters: []version2.Parameter{
+				{
+					Value:  `"v2"`,
+					Result: "$vs_default_cafe_matches_0_match_0_cond_1",
+				},
+				{
+					Value:  "default",
+					Result: "0",
+				},
+			},
+		},
+		{
+			Source:   "$cookie_user",
+			Variable: "$vs_default_cafe_matches_0_match_0_cond_1",
+			Parameters: []version2.Parameter{
+				{
+					Value:  `"beta"`,
+					Result: "1",
+				},
+				{
+					Value:  "default",
+					Result: "0",
+				},
+			},
+		},
+		{
+			Source:   "$vs_default_cafe_matches_0_match_0_cond_0",
+			Variable: "$vs_default_cafe_matches_0",
+			Parameters: []version2.Parameter{
+				{
+					Value:  "~^1",
+					Result: "/internal_location_matches_0_match_0",
+				},
+				{
+					Value:  "default",
+					Result: "/internal_location_matches_0_default",
+				},
+			},
+		},
+	}
+
+	result := generateMatchesConfig(route, upstreamNamer, crUpstreams, variableNamer, 0, 0, &ConfigParams{}, nil, 0)
+	if !reflect.DeepEqual(result.Maps, expectedMaps) {
+		t.Errorf("generateMatchesConfig() returned maps \n%+v but expected \n%+v", result.Maps, expectedMaps)
+	}
+}
+
 func TestGenerateMatchesConfigWithMultipleSplits(t *testing.T) {
 	route := conf_v1.Route{
 		Path: "/",
@@ -3524,7 +7250,7 @@ func TestGenerateUpstreamWithQueue(t *testing.T) {
 
 	for _, test := range tests {
 		vsc := newVirtualServerConfigurator(&ConfigParams{}, test.isPlus, false, &StaticConfigParams{})
-		result := vsc.generateUpstream(&conf_v1.VirtualServer{}, test.name, test.upstream, false, []string{})
+		result := vsc.generateUpstream(&conf_v1.VirtualServer{}, test.name, test.upstream, false, []string{}, nil)
 		if !reflect.DeepEqual(result, test.expected) {
 			t.Errorf("generateUpstream() returned %v but expected %v for the case of %v", result, test.expected, test.msg)
 		}
@@ -3818,6 +7544,59 @@ func TestGenerateProxySSLName(t *testing.T) {
 	}
 }
 
+func TestGenerateActionProxySSLName(t *testing.T) {
+	tlsUpstream := conf_v1.UpstreamTLS{Enable: true}
+
+	tests := []struct {
+		tls                conf_v1.UpstreamTLS
+		proxy              *conf_v1.ActionProxy
+		expectedName       string
+		expectedServerName bool
+		msg                string
+	}{
+		{
+			tls:                tlsUpstream,
+			proxy:              nil,
+			expectedName:       "tea-svc.default.svc",
+			expectedServerName: false,
+			msg:                "no proxy action",
+		},
+		{
+			tls:                tlsUpstream,
+			proxy:              &conf_v1.ActionProxy{Upstream: "tea"},
+			expectedName:       "tea-svc.default.svc",
+			expectedServerName: false,
+			msg:                "proxy action without the server name",
+		},
+		{
+			tls:                tlsUpstream,
+			proxy:              &conf_v1.ActionProxy{Upstream: "tea", ProxySSLName: "tea.example.com"},
+			expectedName:       "tea.example.com",
+			expectedServerName: true,
+			msg:                "proxy action with the server name",
+		},
+		{
+			tls:                conf_v1.UpstreamTLS{},
+			proxy:              &conf_v1.ActionProxy{Upstream: "tea", ProxySSLName: "tea.example.com"},
+			expectedName:       "tea.example.com",
+			expectedServerName: false,
+			msg:                "proxy action with the server name for an upstream without TLS",
+		},
+	}
+
+	for _, test := range tests {
+		name := generateActionProxySSLName(test.proxy, "tea-svc.default.svc")
+		if name != test.expectedName {
+			t.Errorf("generateActionProxySSLName() returned %q but expected %q for the case of %s", name, test.expectedName, test.msg)
+		}
+
+		serverName := generateProxySSLServerName(test.tls, test.proxy)
+		if serverName != test.expectedServerName {
+			t.Errorf("generateProxySSLServerName() returned %v but expected %v for the case of %s", serverName, test.expectedServerName, test.msg)
+		}
+	}
+}
+
 func TestIsTLSEnabled(t *testing.T) {
 	tests := []struct {
 		upstream   conf_v1.Upstream
@@ -3982,6 +7761,55 @@ func TestGenerateProxyPassRewrite(t *testing.T) {
 	}
 }
 
+func TestGenerateHostHeader(t *testing.T) {
+	upstreamHost := "tea-svc.default.svc"
+
+	tests := []struct {
+		proxy    *conf_v1.ActionProxy
+		expected string
+		msg      string
+	}{
+		{
+			proxy:    nil,
+			expected: "",
+			msg:      "no proxy",
+		},
+		{
+			proxy:    &conf_v1.ActionProxy{},
+			expected: "",
+			msg:      "no host header",
+		},
+		{
+			proxy: &conf_v1.ActionProxy{
+				HostHeader: &conf_v1.ProxyHostHeader{Mode: "preserve"},
+			},
+			expected: "",
+			msg:      "preserve mode",
+		},
+		{
+			proxy: &conf_v1.ActionProxy{
+				HostHeader: &conf_v1.ProxyHostHeader{Mode: "upstream"},
+			},
+			expected: "tea-svc.default.svc",
+			msg:      "upstream mode",
+		},
+		{
+			proxy: &conf_v1.ActionProxy{
+				HostHeader: &conf_v1.ProxyHostHeader{Mode: "literal", Value: "tea.example.com"},
+			},
+			expected: "tea.example.com",
+			msg:      "literal mode",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateHostHeader(test.proxy, upstreamHost)
+		if result != test.expected {
+			t.Errorf("generateHostHeader() returned %q but expected %q for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
 func TestGenerateProxySetHeaders(t *testing.T) {
 	tests := []struct {
 		proxy    *conf_v1.ActionProxy
@@ -4031,6 +7859,87 @@ func TestGenerateProxySetHeaders(t *testing.T) {
 	}
 }
 
+func TestGenerateClientCertHeaders(t *testing.T) {
+	ccf := &conf_v1.ClientCertForwarding{
+		Headers: []conf_v1.ClientCertHeader{
+			{
+				Name:     "X-SSL-Client-Cert",
+				Variable: "ssl_client_escaped_cert",
+			},
+			{
+				Name:     "X-SSL-Client-Subject-DN",
+				Variable: "ssl_client_s_dn",
+			},
+			{
+				Name:     "X-SSL-Client-Verify",
+				Variable: "ssl_client_verify",
+			},
+		},
+	}
+	expected := []version2.Header{
+		{
+			Name:  "X-SSL-Client-Cert",
+			Value: "$ssl_client_escaped_cert",
+		},
+		{
+			Name:  "X-SSL-Client-Subject-DN",
+			Value: "$ssl_client_s_dn",
+		},
+		{
+			Name:  "X-SSL-Client-Verify",
+			Value: "$ssl_client_verify",
+		},
+	}
+
+	result := generateClientCertHeaders(ccf)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateClientCertHeaders() returned %v but expected %v", result, expected)
+	}
+
+	if result := generateClientCertHeaders(nil); result != nil {
+		t.Errorf("generateClientCertHeaders(nil) returned %v but expected nil", result)
+	}
+}
+
+func TestGenerateLocationWithClientCertForwarding(t *testing.T) {
+	action := &conf_v1.Action{
+		Proxy: &conf_v1.ActionProxy{
+			Upstream: "tea",
+			RequestHeaders: &conf_v1.ProxyRequestHeaders{
+				Set: []conf_v1.Header{
+					{
+						Name:  "X-Forwarded-Scheme",
+						Value: "https",
+					},
+				},
+			},
+		},
+		ClientCertForwarding: &conf_v1.ClientCertForwarding{
+			Headers: []conf_v1.ClientCertHeader{
+				{
+					Name:     "X-SSL-Client-Verify",
+					Variable: "ssl_client_verify",
+				},
+			},
+		},
+	}
+	expected := []version2.Header{
+		{
+			Name:  "X-Forwarded-Scheme",
+			Value: "https",
+		},
+		{
+			Name:  "X-SSL-Client-Verify",
+			Value: "$ssl_client_verify",
+		},
+	}
+
+	loc := generateLocation("/tea", "vs_default_cafe_tea", conf_v1.Upstream{}, action, &ConfigParams{}, nil, false, 0, "", "/tea")
+	if !reflect.DeepEqual(loc.ProxySetHeaders, expected) {
+		t.Errorf("generateLocation() generated the headers %v but expected %v", loc.ProxySetHeaders, expected)
+	}
+}
+
 func TestGenerateProxyPassRequestHeaders(t *testing.T) {
 	passTrue := true
 	passFalse := false
@@ -4147,6 +8056,7 @@ func TestGenerateProxyPassHeaders(t *testing.T) {
 func TestGenerateProxyIgnoreHeaders(t *testing.T) {
 	tests := []struct {
 		proxy    *conf_v1.ActionProxy
+		cache    *conf_v1.UpstreamCache
 		expected string
 	}{
 		{
@@ -4154,25 +8064,51 @@ func TestGenerateProxyIgnoreHeaders(t *testing.T) {
 			expected: "",
 		},
 		{
-			proxy: &conf_v1.ActionProxy{
-				ResponseHeaders: nil,
+			proxy: &conf_v1.ActionProxy{
+				ResponseHeaders: nil,
+			},
+			expected: "",
+		},
+		{
+			proxy: &conf_v1.ActionProxy{
+				ResponseHeaders: &conf_v1.ProxyResponseHeaders{
+					Ignore: []string{"Header", "Header-2"},
+				},
+			},
+			expected: "Header Header-2",
+		},
+		{
+			proxy: nil,
+			cache: &conf_v1.UpstreamCache{
+				IgnoreHeaders: []string{"Set-Cookie", "Vary"},
+			},
+			expected: "Set-Cookie Vary",
+		},
+		{
+			proxy: nil,
+			cache: &conf_v1.UpstreamCache{
+				OverrideCacheControl: true,
 			},
-			expected: "",
+			expected: "Cache-Control Expires X-Accel-Expires",
 		},
 		{
 			proxy: &conf_v1.ActionProxy{
 				ResponseHeaders: &conf_v1.ProxyResponseHeaders{
-					Ignore: []string{"Header", "Header-2"},
+					Ignore: []string{"Expires", "X-Accel-Redirect"},
 				},
 			},
-			expected: "Header Header-2",
+			cache: &conf_v1.UpstreamCache{
+				IgnoreHeaders:        []string{"Set-Cookie", "X-Accel-Redirect"},
+				OverrideCacheControl: true,
+			},
+			expected: "Expires X-Accel-Redirect Set-Cookie Cache-Control X-Accel-Expires",
 		},
 	}
 
 	for _, test := range tests {
-		result := generateProxyIgnoreHeaders(test.proxy)
+		result := generateProxyIgnoreHeaders(test.proxy, test.cache)
 		if result != test.expected {
-			t.Errorf("generateProxyIgnoreHeaders(%v) returned %v but expected %v", test.proxy, result, test.expected)
+			t.Errorf("generateProxyIgnoreHeaders(%v, %v) returned %v but expected %v", test.proxy, test.cache, result, test.expected)
 		}
 	}
 }
@@ -4237,3 +8173,509 @@ func TestGenerateProxyAddHeaders(t *testing.T) {
 		}
 	}
 }
+
+func TestGeneratePolicies(t *testing.T) {
+	newPolicy := func(name string, spec conf_v1alpha1.PolicySpec) *conf_v1alpha1.Policy {
+		return &conf_v1alpha1.Policy{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: spec,
+		}
+	}
+
+	burst := 5
+	noDelay := true
+	rejectCode := 429
+
+	policies := map[string]*conf_v1alpha1.Policy{
+		"default/allow-policy": newPolicy("allow-policy", conf_v1alpha1.PolicySpec{
+			AccessControl: &conf_v1alpha1.AccessControl{
+				Allow: []string{"10.0.0.0/8"},
+			},
+		}),
+		"default/deny-policy": newPolicy("deny-policy", conf_v1alpha1.PolicySpec{
+			AccessControl: &conf_v1alpha1.AccessControl{
+				Deny: []string{"127.0.0.1"},
+			},
+		}),
+		"default/rate-limit-policy": newPolicy("rate-limit-policy", conf_v1alpha1.PolicySpec{
+			RateLimit: &conf_v1alpha1.RateLimit{
+				Rate:       "10r/s",
+				Key:        "${binary_remote_addr}",
+				Burst:      &burst,
+				NoDelay:    &noDelay,
+				RejectCode: &rejectCode,
+			},
+		}),
+		"default/combined-limit-policy": newPolicy("combined-limit-policy", conf_v1alpha1.PolicySpec{
+			CombinedLimit: &conf_v1alpha1.CombinedLimit{
+				Rate:        "10r/s",
+				Key:         "${binary_remote_addr}",
+				ZoneSize:    "20m",
+				Burst:       &burst,
+				Connections: 10,
+				RejectCode:  &rejectCode,
+			},
+		}),
+		"default/jwt-policy": newPolicy("jwt-policy", conf_v1alpha1.PolicySpec{
+			JWTAuth: &conf_v1alpha1.JWTAuth{
+				Realm:  "My API",
+				Secret: "jwk-secret",
+				Token:  "$http_token",
+			},
+		}),
+		"default/jwt-policy-invalid-secret": newPolicy("jwt-policy-invalid-secret", conf_v1alpha1.PolicySpec{
+			JWTAuth: &conf_v1alpha1.JWTAuth{
+				Realm:  "My API",
+				Secret: "invalid-secret",
+			},
+		}),
+	}
+
+	jwtKeyFileNames := map[string]string{
+		"default/jwk-secret": "/etc/nginx/secrets/default-jwk-secret",
+	}
+
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+
+	tests := []struct {
+		policyRefs       []conf_v1.PolicyReference
+		expected         policiesCfg
+		expectedWarnings int
+		msg              string
+	}{
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "allow-policy",
+				},
+			},
+			expected: policiesCfg{
+				Allow: []string{"10.0.0.0/8"},
+			},
+			expectedWarnings: 0,
+			msg:              "allow access control policy",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "rate-limit-policy",
+					Namespace: "default",
+				},
+			},
+			expected: policiesCfg{
+				LimitReqZones: []version2.LimitReqZone{
+					{
+						Key:      "${binary_remote_addr}",
+						ZoneName: "pol_rl_default_rate-limit-policy_default_cafe",
+						ZoneSize: "10m",
+						Rate:     "10r/s",
+					},
+				},
+				LimitReqs: []version2.LimitReq{
+					{
+						ZoneName: "pol_rl_default_rate-limit-policy_default_cafe",
+						Burst:    5,
+						NoDelay:  true,
+					},
+				},
+				LimitReqStatus: 429,
+			},
+			expectedWarnings: 0,
+			msg:              "rate limit policy with an explicit namespace",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "combined-limit-policy",
+				},
+			},
+			expected: policiesCfg{
+				LimitReqZones: []version2.LimitReqZone{
+					{
+						Key:      "${binary_remote_addr}",
+						ZoneName: "pol_rl_default_combined-limit-policy_default_cafe",
+						ZoneSize: "20m",
+						Rate:     "10r/s",
+					},
+				},
+				LimitReqs: []version2.LimitReq{
+					{
+						ZoneName: "pol_rl_default_combined-limit-policy_default_cafe",
+						Burst:    5,
+					},
+				},
+				LimitReqStatus: 429,
+				LimitConnZones: []version2.LimitConnZone{
+					{
+						Key:      "${binary_remote_addr}",
+						ZoneName: "pol_cl_default_combined-limit-policy_default_cafe",
+						ZoneSize: "20m",
+					},
+				},
+				LimitConns: []version2.LimitConn{
+					{
+						Zone:  "pol_cl_default_combined-limit-policy_default_cafe",
+						Limit: 10,
+					},
+				},
+				LimitConnStatus: 429,
+			},
+			expectedWarnings: 0,
+			msg:              "combined limit policy",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "jwt-policy",
+				},
+			},
+			expected: policiesCfg{
+				JWTAuth: &version2.JWTAuth{
+					Secret: "/etc/nginx/secrets/default-jwk-secret",
+					Realm:  "My API",
+					Token:  "$http_token",
+				},
+			},
+			expectedWarnings: 0,
+			msg:              "jwt policy",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "allow-policy",
+				},
+				{
+					Name: "deny-policy",
+				},
+			},
+			expected: policiesCfg{
+				Allow: []string{"10.0.0.0/8"},
+			},
+			expectedWarnings: 1,
+			msg:              "multiple access control policies",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "missing-policy",
+				},
+			},
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
+			expectedWarnings: 1,
+			msg:              "missing policy",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "jwt-policy-invalid-secret",
+				},
+			},
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
+			expectedWarnings: 1,
+			msg:              "jwt policy with an invalid secret",
+		},
+	}
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(&ConfigParams{}, true, false, &StaticConfigParams{})
+
+		result := vsc.generatePolicies(vs, vs.Namespace, vs, test.policyRefs, policies, jwtKeyFileNames)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("generatePolicies() returned \n%+v but expected \n%+v for the case of %s", result, test.expected, test.msg)
+		}
+		if len(vsc.warnings[vs]) != test.expectedWarnings {
+			t.Errorf("generatePolicies() returned %d warnings but expected %d for the case of %s", len(vsc.warnings[vs]), test.expectedWarnings, test.msg)
+		}
+	}
+}
+
+func TestGeneratePoliciesWithLimitZoneSizes(t *testing.T) {
+	policies := map[string]*conf_v1alpha1.Policy{
+		"default/rate-limit-policy": {
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "rate-limit-policy",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.PolicySpec{
+				RateLimit: &conf_v1alpha1.RateLimit{
+					Rate: "10r/s",
+					Key:  "${binary_remote_addr}",
+				},
+			},
+		},
+		"default/combined-limit-policy": {
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "combined-limit-policy",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.PolicySpec{
+				CombinedLimit: &conf_v1alpha1.CombinedLimit{
+					Rate:        "10r/s",
+					Key:         "${binary_remote_addr}",
+					Connections: 10,
+				},
+			},
+		},
+	}
+
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{LimitReqZoneSize: "20m", LimitConnZoneSize: "5m"}, false, false, &StaticConfigParams{})
+	// the combined limit policy is referenced by two routes, which share its zones
+	vsc.policyReferences = map[string]int{
+		"default/rate-limit-policy":     1,
+		"default/combined-limit-policy": 2,
+	}
+
+	policyRefs := []conf_v1.PolicyReference{
+		{Name: "rate-limit-policy"},
+		{Name: "combined-limit-policy"},
+	}
+
+	expectedLimitReqZones := []version2.LimitReqZone{
+		{
+			Key:      "${binary_remote_addr}",
+			ZoneName: "pol_rl_default_rate-limit-policy_default_cafe",
+			ZoneSize: "20m",
+			Rate:     "10r/s",
+		},
+		{
+			Key:      "${binary_remote_addr}",
+			ZoneName: "pol_rl_default_combined-limit-policy_default_cafe",
+			ZoneSize: "40m",
+			Rate:     "10r/s",
+		},
+	}
+	expectedLimitConnZones := []version2.LimitConnZone{
+		{
+			Key:      "${binary_remote_addr}",
+			ZoneName: "pol_cl_default_combined-limit-policy_default_cafe",
+			ZoneSize: "10m",
+		},
+	}
+
+	result := vsc.generatePolicies(vs, vs.Namespace, vs, policyRefs, policies, nil)
+	if !reflect.DeepEqual(result.LimitReqZones, expectedLimitReqZones) {
+		t.Errorf("generatePolicies() returned the limit_req zones \n%+v but expected \n%+v", result.LimitReqZones, expectedLimitReqZones)
+	}
+	if !reflect.DeepEqual(result.LimitConnZones, expectedLimitConnZones) {
+		t.Errorf("generatePolicies() returned the limit_conn zones \n%+v but expected \n%+v", result.LimitConnZones, expectedLimitConnZones)
+	}
+}
+
+func TestRemoveDuplicateLimitReqZones(t *testing.T) {
+	zones := []version2.LimitReqZone{
+		{ZoneName: "pol_rl_default_one_default_cafe", Rate: "10r/s"},
+		{ZoneName: "pol_rl_default_two_default_cafe", Rate: "20r/s"},
+		{ZoneName: "pol_rl_default_one_default_cafe", Rate: "10r/s"},
+	}
+	expected := []version2.LimitReqZone{
+		{ZoneName: "pol_rl_default_one_default_cafe", Rate: "10r/s"},
+		{ZoneName: "pol_rl_default_two_default_cafe", Rate: "20r/s"},
+	}
+
+	result := removeDuplicateLimitReqZones(zones)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("removeDuplicateLimitReqZones() returned %v but expected %v", result, expected)
+	}
+}
+
+func TestRemoveDuplicateLimitConnZones(t *testing.T) {
+	zones := []version2.LimitConnZone{
+		{ZoneName: "pol_cl_default_one_default_cafe", Key: "${binary_remote_addr}"},
+		{ZoneName: "pol_cl_default_two_default_cafe", Key: "${http_x_user_id}"},
+		{ZoneName: "pol_cl_default_one_default_cafe", Key: "${binary_remote_addr}"},
+	}
+	expected := []version2.LimitConnZone{
+		{ZoneName: "pol_cl_default_one_default_cafe", Key: "${binary_remote_addr}"},
+		{ZoneName: "pol_cl_default_two_default_cafe", Key: "${http_x_user_id}"},
+	}
+
+	result := removeDuplicateLimitConnZones(zones)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("removeDuplicateLimitConnZones() returned %v but expected %v", result, expected)
+	}
+}
+
+func TestGenerateVirtualServerConfigWithSourceComments(t *testing.T) {
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+					},
+					{
+						Path: "/tea-splits",
+						Splits: []conf_v1.Split{
+							{
+								Weight: 50,
+								Action: &conf_v1.Action{Pass: "tea"},
+							},
+							{
+								Weight: 50,
+								Action: &conf_v1.Action{Pass: "tea"},
+							},
+						},
+					},
+					{
+						Path:  "/coffee",
+						Route: "coffee",
+					},
+				},
+			},
+		},
+		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+			{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "coffee",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerRouteSpec{
+					Host: "cafe.example.com",
+					Upstreams: []conf_v1.Upstream{
+						{
+							Name:    "coffee",
+							Service: "coffee-svc",
+							Port:    80,
+						},
+					},
+					Subroutes: []conf_v1.Route{
+						{
+							Path: "/coffee",
+							Action: &conf_v1.Action{
+								Pass: "coffee",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expectedServerSource := "source: VirtualServer default/cafe"
+	expectedLocationSources := map[string]string{
+		"/tea":                                `source: VirtualServer default/cafe, route "/tea"`,
+		"/internal_location_splits_0_split_0": `source: VirtualServer default/cafe, route "/tea-splits"`,
+		"/internal_location_splits_0_split_1": `source: VirtualServer default/cafe, route "/tea-splits"`,
+		"/coffee":                             `source: VirtualServerRoute default/coffee, subroute "/coffee"`,
+	}
+	expectedInternalRedirectLocationSources := map[string]string{
+		"/tea-splits": `source: VirtualServer default/cafe, route "/tea-splits"`,
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{ConfigSourceComments: true})
+	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+	if len(warnings) != 0 {
+		t.Errorf("GenerateVirtualServerConfig() returned unexpected warnings: %v", warnings)
+	}
+
+	if result.Server.Source != expectedServerSource {
+		t.Errorf("GenerateVirtualServerConfig() returned the server source %q but expected %q", result.Server.Source, expectedServerSource)
+	}
+
+	if len(result.Server.Locations) != len(expectedLocationSources) {
+		t.Fatalf("GenerateVirtualServerConfig() returned %d locations but expected %d", len(result.Server.Locations), len(expectedLocationSources))
+	}
+	for _, loc := range result.Server.Locations {
+		if loc.Source != expectedLocationSources[loc.Path] {
+			t.Errorf("GenerateVirtualServerConfig() returned the source %q for the location %v but expected %q", loc.Source, loc.Path, expectedLocationSources[loc.Path])
+		}
+	}
+
+	if len(result.Server.InternalRedirectLocations) != len(expectedInternalRedirectLocationSources) {
+		t.Fatalf("GenerateVirtualServerConfig() returned %d internal redirect locations but expected %d", len(result.Server.InternalRedirectLocations), len(expectedInternalRedirectLocationSources))
+	}
+	for _, loc := range result.Server.InternalRedirectLocations {
+		if loc.Source != expectedInternalRedirectLocationSources[loc.Path] {
+			t.Errorf("GenerateVirtualServerConfig() returned the source %q for the internal redirect location %v but expected %q", loc.Source, loc.Path, expectedInternalRedirectLocationSources[loc.Path])
+		}
+	}
+
+	vsc = newVirtualServerConfigurator(&ConfigParams{}, false, false, &StaticConfigParams{})
+	result, _ = vsc.GenerateVirtualServerConfig(&virtualServerEx, "", "", nil)
+
+	if result.Server.Source != "" {
+		t.Errorf("GenerateVirtualServerConfig() returned the server source %q for disabled source comments", result.Server.Source)
+	}
+	for _, loc := range result.Server.Locations {
+		if loc.Source != "" {
+			t.Errorf("GenerateVirtualServerConfig() returned the source %q for the location %v for disabled source comments", loc.Source, loc.Path)
+		}
+	}
+}
+
+func TestGenerateSourceComment(t *testing.T) {
+	tests := []struct {
+		enabled  bool
+		pathKind string
+		path     string
+		expected string
+	}{
+		{
+			enabled:  false,
+			pathKind: "route",
+			path:     "/tea",
+			expected: "",
+		},
+		{
+			enabled:  true,
+			expected: "source: VirtualServer default/cafe",
+		},
+		{
+			enabled:  true,
+			pathKind: "route",
+			path:     "/tea",
+			expected: `source: VirtualServer default/cafe, route "/tea"`,
+		},
+		{
+			enabled:  true,
+			pathKind: "route",
+			path:     "~ ^/tea\n",
+			expected: `source: VirtualServer default/cafe, route "~ ^/tea\n"`,
+		},
+	}
+
+	for _, test := range tests {
+		result := generateSourceComment(test.enabled, "VirtualServer", "default", "cafe", test.pathKind, test.path)
+		if result != test.expected {
+			t.Errorf("generateSourceComment() returned %q but expected %q", result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/golang/glog"
+)
+
+// cachePurgeEndpoint is the path of the endpoint that purges the cache entries of the VirtualServer upstreams.
+const cachePurgeEndpoint = "/purge-cache"
+
+// cachePurgeHandler handles the requests to the cache purge endpoint.
+// A request must use the POST method, include the token in the Authorization header as a Bearer token
+// and include the cache zone and the key of the entries in the zone and the key query parameters.
+// A key ending with an asterisk purges all entries with the keys that start with the rest of the key.
+type cachePurgeHandler struct {
+	lbc   *LoadBalancerController
+	token string
+}
+
+func (h *cachePurgeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	expected := "Bearer " + h.token
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	zone := r.URL.Query().Get("zone")
+	key := r.URL.Query().Get("key")
+	if zone == "" || key == "" {
+		http.Error(w, "the zone and the key query parameters are required", http.StatusBadRequest)
+		return
+	}
+
+	if !h.lbc.configurator.HasCacheZone(zone) {
+		http.Error(w, fmt.Sprintf("cache zone %v doesn't exist", zone), http.StatusNotFound)
+		return
+	}
+
+	err := h.lbc.configurator.PurgeCache(zone, key)
+	if err != nil {
+		glog.Errorf("Error purging the cache through the cache purge endpoint: %v", err)
+		http.Error(w, "error purging the cache", http.StatusInternalServerError)
+		return
+	}
+	glog.Infof("Purged the entries with key %v from cache zone %v through the cache purge endpoint", key, zone)
+
+	_, err = fmt.Fprintf(w, "purged the entries with key %v from cache zone %v\n", key, zone)
+	if err != nil {
+		glog.Warningf("Error while sending a response for the cache purge endpoint: %v", err)
+	}
+}
+
+// RunCachePurgeListener runs an http server with the endpoint that purges the cache entries of the VirtualServer upstreams.
+func RunCachePurgeListener(port int, token string, lbc *LoadBalancerController) {
+	mux := http.NewServeMux()
+	mux.Handle(cachePurgeEndpoint, &cachePurgeHandler{lbc: lbc, token: token})
+
+	address := fmt.Sprintf(":%v", port)
+	glog.Infof("Starting cache purge listener on: %v%v", address, cachePurgeEndpoint)
+	glog.Fatal("Error in cache purge listener server: ", http.ListenAndServe(address, mux))
+}
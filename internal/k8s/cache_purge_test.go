@@ -0,0 +1,150 @@
+package k8s
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/nginxinc/kubernetes-ingress/internal/configs"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
+	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakePlusCachePurger is a fake manager that records the cache purges in NGINX Plus.
+type fakePlusCachePurger struct {
+	*nginx.FakeManager
+	purges [][2]string
+}
+
+func (m *fakePlusCachePurger) PurgeCacheInPlus(zone string, key string) error {
+	m.purges = append(m.purges, [2]string{zone, key})
+	return nil
+}
+
+func createCachePurgeTestController(t *testing.T) (*LoadBalancerController, *fakePlusCachePurger) {
+	templateExecutor, err := version1.NewTemplateExecutor("../configs/version1/nginx-plus.tmpl", "../configs/version1/nginx-plus.ingress.tmpl")
+	if err != nil {
+		t.Fatalf("templateExecutor could not start: %v", err)
+	}
+
+	templateExecutorV2, err := version2.NewTemplateExecutor("../configs/version2/nginx-plus.virtualserver.tmpl", "../configs/version2/nginx-plus.transportserver.tmpl")
+	if err != nil {
+		t.Fatalf("templateExecutorV2 could not start: %v", err)
+	}
+
+	manager := &fakePlusCachePurger{FakeManager: nginx.NewFakeManager("/etc/nginx")}
+	cnf := configs.NewConfigurator(manager, &configs.StaticConfigParams{}, configs.NewDefaultConfigParams(), configs.NewDefaultGlobalConfigParams(),
+		templateExecutor, templateExecutorV2, true, false)
+
+	vsEx := &configs.VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
+						Cache:   &conf_v1.UpstreamCache{},
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Action: &conf_v1.Action{
+							Pass: "tea",
+						},
+					},
+				},
+			},
+		},
+	}
+	if _, err := cnf.AddOrUpdateVirtualServer(vsEx); err != nil {
+		t.Fatalf("VirtualServer was not added: %v", err)
+	}
+
+	return &LoadBalancerController{configurator: cnf, isNginxPlus: true}, manager
+}
+
+func TestCachePurgeHandler(t *testing.T) {
+	tests := []struct {
+		method         string
+		authorization  string
+		target         string
+		expectedStatus int
+		expectedPurges [][2]string
+		msg            string
+	}{
+		{
+			method:         http.MethodPost,
+			authorization:  "Bearer secret-token",
+			target:         "/purge-cache?zone=vs_default_cafe_tea&key=httpvs_default_cafe_tea%2Ftea%2A",
+			expectedStatus: http.StatusOK,
+			expectedPurges: [][2]string{{"vs_default_cafe_tea", "httpvs_default_cafe_tea/tea*"}},
+			msg:            "valid request",
+		},
+		{
+			method:         http.MethodGet,
+			authorization:  "Bearer secret-token",
+			target:         "/purge-cache?zone=vs_default_cafe_tea&key=%2A",
+			expectedStatus: http.StatusMethodNotAllowed,
+			msg:            "wrong method",
+		},
+		{
+			method:         http.MethodPost,
+			authorization:  "Bearer wrong-token",
+			target:         "/purge-cache?zone=vs_default_cafe_tea&key=%2A",
+			expectedStatus: http.StatusUnauthorized,
+			msg:            "wrong token",
+		},
+		{
+			method:         http.MethodPost,
+			authorization:  "",
+			target:         "/purge-cache?zone=vs_default_cafe_tea&key=%2A",
+			expectedStatus: http.StatusUnauthorized,
+			msg:            "missing token",
+		},
+		{
+			method:         http.MethodPost,
+			authorization:  "Bearer secret-token",
+			target:         "/purge-cache?zone=vs_default_cafe_tea",
+			expectedStatus: http.StatusBadRequest,
+			msg:            "missing key",
+		},
+		{
+			method:         http.MethodPost,
+			authorization:  "Bearer secret-token",
+			target:         "/purge-cache?zone=vs_default_cafe_coffee&key=%2A",
+			expectedStatus: http.StatusNotFound,
+			msg:            "cache zone that doesn't exist",
+		},
+	}
+
+	for _, test := range tests {
+		lbc, manager := createCachePurgeTestController(t)
+		handler := &cachePurgeHandler{lbc: lbc, token: "secret-token"}
+
+		req := httptest.NewRequest(test.method, test.target, nil)
+		if test.authorization != "" {
+			req.Header.Set("Authorization", test.authorization)
+		}
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != test.expectedStatus {
+			t.Errorf("cachePurgeHandler returned status %d but expected %d for the case of %s", rec.Code, test.expectedStatus, test.msg)
+		}
+		if !reflect.DeepEqual(manager.purges, test.expectedPurges) {
+			t.Errorf("cachePurgeHandler purged %v but expected %v for the case of %s", manager.purges, test.expectedPurges, test.msg)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/glog"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// certificateGVR is the resource of the cert-manager Certificates. The Ingress Controller doesn't depend on the
+// cert-manager API packages and handles the Certificates as unstructured objects.
+var certificateGVR = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1alpha2",
+	Resource: "certificates",
+}
+
+// addCertificateHandler adds the handler for cert-manager Certificates to the controller.
+func (lbc *LoadBalancerController) addCertificateHandler(handlers cache.ResourceEventHandlerFuncs) {
+	newListWatch := func(namespace string) cache.ListerWatcher {
+		return &cache.ListWatch{
+			ListFunc: func(options meta_v1.ListOptions) (runtime.Object, error) {
+				return lbc.dynClient.Resource(certificateGVR).Namespace(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options meta_v1.ListOptions) (watch.Interface, error) {
+				return lbc.dynClient.Resource(certificateGVR).Namespace(namespace).Watch(context.TODO(), options)
+			},
+		}
+	}
+
+	lbc.certificateLister, lbc.certificateController = newMultiNamespaceInformer(lbc.namespaces, newListWatch, &unstructured.Unstructured{}, lbc.resync, handlers)
+}
+
+func (lbc *LoadBalancerController) syncCertificate(task task) {
+	key := task.Key
+	_, certExists, err := lbc.certificateLister.GetByKey(key)
+	if err != nil {
+		lbc.syncQueue.Requeue(task, err)
+		return
+	}
+
+	if certExists {
+		glog.V(2).Infof("Adding or Updating Certificate: %v\n", key)
+	} else {
+		glog.V(2).Infof("Deleting Certificate: %v\n", key)
+	}
+
+	namespace, name, err := ParseNamespaceName(key)
+	if err != nil {
+		glog.Warningf("Certificate key %v is invalid: %v", key, err)
+		return
+	}
+
+	// A Certificate becoming ready, renewed into another secret or deleted changes the TLS secret of the VirtualServers
+	// that reference it.
+	virtualServers := findVirtualServersForCertificate(lbc.getVirtualServers(), namespace, name)
+	for _, vs := range virtualServers {
+		lbc.syncQueue.Enqueue(vs)
+	}
+
+	glog.V(2).Infof("Enqueued %v VirtualServers for Certificate %v", len(virtualServers), key)
+}
+
+// getTLSSecretReference returns the reference to the TLS secret of the VirtualServer: either the secret of the TLS
+// or, for a VirtualServer that references a cert-manager Certificate, the secret of the Certificate once it is ready.
+func (lbc *LoadBalancerController) getTLSSecretReference(vs *conf_v1.VirtualServer) (string, error) {
+	if vs.Spec.TLS.Certificate == "" {
+		return vs.Spec.TLS.Secret, nil
+	}
+
+	if lbc.certificateLister == nil {
+		return "", fmt.Errorf("Certificate %v/%v cannot be used: the support for cert-manager is not enabled", vs.Namespace, vs.Spec.TLS.Certificate)
+	}
+
+	certKey := vs.Namespace + "/" + vs.Spec.TLS.Certificate
+	obj, exists, err := lbc.certificateLister.GetByKey(certKey)
+	if err != nil {
+		return "", fmt.Errorf("error retrieving Certificate %v: %v", certKey, err)
+	}
+	if !exists {
+		return "", fmt.Errorf("Certificate %v not found", certKey)
+	}
+
+	return getCertificateSecretName(obj.(*unstructured.Unstructured))
+}
+
+// getCertificateSecretName returns the name of the secret with the certificate and the key issued for a Certificate.
+// The secret is returned only when the Certificate is ready, so that NGINX never uses a secret that is not issued yet.
+func getCertificateSecretName(cert *unstructured.Unstructured) (string, error) {
+	secretName, _, err := unstructured.NestedString(cert.Object, "spec", "secretName")
+	if err != nil || secretName == "" {
+		return "", fmt.Errorf("Certificate %v/%v doesn't have a secretName", cert.GetNamespace(), cert.GetName())
+	}
+
+	if !isCertificateReady(cert) {
+		return "", fmt.Errorf("Certificate %v/%v is not ready", cert.GetNamespace(), cert.GetName())
+	}
+
+	return secretName, nil
+}
+
+// isCertificateReady checks if the Certificate has the Ready condition with the True status.
+func isCertificateReady(cert *unstructured.Unstructured) bool {
+	conditions, _, err := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	if err != nil {
+		return false
+	}
+
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Ready" {
+			return condition["status"] == "True"
+		}
+	}
+
+	return false
+}
+
+// findVirtualServersForCertificate finds the VirtualServers that reference the Certificate.
+func findVirtualServersForCertificate(virtualServers []*conf_v1.VirtualServer, certNamespace string, certName string) []*conf_v1.VirtualServer {
+	var result []*conf_v1.VirtualServer
+
+	for _, vs := range virtualServers {
+		if vs.Spec.TLS == nil || vs.Spec.TLS.Certificate == "" {
+			continue
+		}
+
+		if vs.Namespace == certNamespace && vs.Spec.TLS.Certificate == certName {
+			result = append(result, vs)
+		}
+	}
+
+	return result
+}
+
+// findVirtualServersForCertificateSecret finds the VirtualServers that reference the Certificates whose certificate
+// and key are stored in the secret.
+func findVirtualServersForCertificateSecret(virtualServers []*conf_v1.VirtualServer, certificates []*unstructured.Unstructured,
+	secretNamespace string, secretName string) []*conf_v1.VirtualServer {
+	var result []*conf_v1.VirtualServer
+
+	for _, cert := range certificates {
+		if cert.GetNamespace() != secretNamespace {
+			continue
+		}
+
+		certSecretName, _, err := unstructured.NestedString(cert.Object, "spec", "secretName")
+		if err != nil || certSecretName != secretName {
+			continue
+		}
+
+		result = append(result, findVirtualServersForCertificate(virtualServers, cert.GetNamespace(), cert.GetName())...)
+	}
+
+	return result
+}
+
+func (lbc *LoadBalancerController) getCertificates() []*unstructured.Unstructured {
+	if lbc.certificateLister == nil {
+		return nil
+	}
+
+	var certificates []*unstructured.Unstructured
+	for _, obj := range lbc.certificateLister.List() {
+		certificates = append(certificates, obj.(*unstructured.Unstructured))
+	}
+
+	return certificates
+}
@@ -0,0 +1,214 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+)
+
+func createTestCertificate(namespace string, name string, secretName string, readyStatus string) *unstructured.Unstructured {
+	cert := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1alpha2",
+			"kind":       "Certificate",
+			"metadata": map[string]interface{}{
+				"namespace": namespace,
+				"name":      name,
+			},
+			"spec": map[string]interface{}{
+				"secretName": secretName,
+			},
+		},
+	}
+
+	if readyStatus != "" {
+		cert.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":   "Ready",
+					"status": readyStatus,
+				},
+			},
+		}
+	}
+
+	return cert
+}
+
+func TestGetCertificateSecretName(t *testing.T) {
+	cert := createTestCertificate("default", "cafe-certificate", "cafe-secret", "True")
+
+	result, err := getCertificateSecretName(cert)
+	if err != nil {
+		t.Errorf("getCertificateSecretName() returned an unexpected error: %v", err)
+	}
+	if result != "cafe-secret" {
+		t.Errorf("getCertificateSecretName() returned %q but expected %q", result, "cafe-secret")
+	}
+}
+
+func TestGetCertificateSecretNameFails(t *testing.T) {
+	tests := []struct {
+		cert *unstructured.Unstructured
+		msg  string
+	}{
+		{
+			cert: createTestCertificate("default", "cafe-certificate", "cafe-secret", "False"),
+			msg:  "not ready",
+		},
+		{
+			cert: createTestCertificate("default", "cafe-certificate", "cafe-secret", ""),
+			msg:  "no status",
+		},
+		{
+			cert: createTestCertificate("default", "cafe-certificate", "", "True"),
+			msg:  "no secretName",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := getCertificateSecretName(test.cert)
+		if err == nil {
+			t.Errorf("getCertificateSecretName() returned no error for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestFindVirtualServersForCertificateSecret(t *testing.T) {
+	vsWithCert := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			TLS: &conf_v1.TLS{
+				Certificate: "cafe-certificate",
+			},
+		},
+	}
+	vsWithSecret := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "tea",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			TLS: &conf_v1.TLS{
+				Secret: "cafe-secret",
+			},
+		},
+	}
+	vsInOtherNamespace := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "other",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			TLS: &conf_v1.TLS{
+				Certificate: "cafe-certificate",
+			},
+		},
+	}
+	virtualServers := []*conf_v1.VirtualServer{vsWithCert, vsWithSecret, vsInOtherNamespace}
+
+	certificates := []*unstructured.Unstructured{
+		createTestCertificate("default", "cafe-certificate", "cafe-secret", "True"),
+		createTestCertificate("other", "cafe-certificate", "other-secret", "True"),
+	}
+
+	expected := []*conf_v1.VirtualServer{vsWithCert}
+
+	result := findVirtualServersForCertificateSecret(virtualServers, certificates, "default", "cafe-secret")
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("findVirtualServersForCertificateSecret() returned %v but expected %v", result, expected)
+	}
+
+	result = findVirtualServersForCertificate(virtualServers, "other", "cafe-certificate")
+	expected = []*conf_v1.VirtualServer{vsInOtherNamespace}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("findVirtualServersForCertificate() returned %v but expected %v", result, expected)
+	}
+}
+
+func TestCreateVirtualServerWithCertificate(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe-secret",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			v1.TLSCertKey:       []byte("cert"),
+			v1.TLSPrivateKeyKey: []byte("key"),
+		},
+	}
+
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Host: "cafe.example.com",
+			TLS: &conf_v1.TLS{
+				Certificate: "cafe-certificate",
+			},
+		},
+	}
+
+	tests := []struct {
+		cert           *unstructured.Unstructured
+		expectedSecret *v1.Secret
+		msg            string
+	}{
+		{
+			cert:           createTestCertificate("default", "cafe-certificate", "cafe-secret", "True"),
+			expectedSecret: secret,
+			msg:            "ready certificate",
+		},
+		{
+			cert:           createTestCertificate("default", "cafe-certificate", "cafe-secret", "False"),
+			expectedSecret: nil,
+			msg:            "certificate not ready",
+		},
+		{
+			cert:           nil,
+			expectedSecret: nil,
+			msg:            "missing certificate",
+		},
+	}
+
+	for _, test := range tests {
+		secretLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+		err := secretLister.Add(secret)
+		if err != nil {
+			t.Fatalf("Failed to add the secret to the store: %v", err)
+		}
+
+		certificateLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+		if test.cert != nil {
+			err = certificateLister.Add(test.cert)
+			if err != nil {
+				t.Fatalf("Failed to add the certificate to the store: %v", err)
+			}
+		}
+
+		lbc := &LoadBalancerController{
+			secretLister:      storeToSecretLister{secretLister},
+			certificateLister: certificateLister,
+		}
+
+		vsEx, _ := lbc.createVirtualServer(vs)
+		if vsEx.TLSSecret != test.expectedSecret {
+			t.Errorf("createVirtualServer() returned the TLS secret %v but expected %v for the case of %s", vsEx.TLSSecret, test.expectedSecret, test.msg)
+		}
+	}
+
+	lbc := &LoadBalancerController{}
+	if _, err := lbc.getTLSSecretReference(vs); err == nil {
+		t.Errorf("getTLSSecretReference() returned no error for a controller without the support for cert-manager")
+	}
+}
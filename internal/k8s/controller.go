@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -30,7 +31,10 @@ import (
 	"k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	core_v1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -55,110 +59,173 @@ import (
 
 const (
 	ingressClassKey = "kubernetes.io/ingress.class"
+	pausedKey       = "nginx.org/paused"
 )
 
 // LoadBalancerController watches Kubernetes API and
 // reconfigures NGINX via NginxController when needed
 type LoadBalancerController struct {
-	client                        kubernetes.Interface
-	confClient                    k8s_nginx.Interface
-	ingressController             cache.Controller
-	svcController                 cache.Controller
-	endpointController            cache.Controller
-	configMapController           cache.Controller
-	secretController              cache.Controller
-	virtualServerController       cache.Controller
-	virtualServerRouteController  cache.Controller
-	globalConfigurationController cache.Controller
-	transportServerController     cache.Controller
-	podController                 cache.Controller
-	ingressLister                 storeToIngressLister
-	svcLister                     cache.Store
-	endpointLister                storeToEndpointLister
-	configMapLister               storeToConfigMapLister
-	podLister                     indexerToPodLister
-	secretLister                  storeToSecretLister
-	virtualServerLister           cache.Store
-	virtualServerRouteLister      cache.Store
-	globalConfiguratonLister      cache.Store
-	transportServerLister         cache.Store
-	syncQueue                     *taskQueue
-	ctx                           context.Context
-	cancel                        context.CancelFunc
-	configurator                  *configs.Configurator
-	watchNginxConfigMaps          bool
-	watchGlobalConfiguration      bool
-	isNginxPlus                   bool
-	recorder                      record.EventRecorder
-	defaultServerSecret           string
-	ingressClass                  string
-	useIngressClassOnly           bool
-	statusUpdater                 *statusUpdater
-	leaderElector                 *leaderelection.LeaderElector
-	reportIngressStatus           bool
-	isLeaderElectionEnabled       bool
-	leaderElectionLockName        string
-	resync                        time.Duration
-	namespace                     string
-	controllerNamespace           string
-	wildcardTLSSecret             string
-	areCustomResourcesEnabled     bool
-	metricsCollector              collectors.ControllerCollector
-	globalConfigurationValidator  *validation.GlobalConfigurationValidator
-	transportServerValidator      *validation.TransportServerValidator
-	spiffeController              *spiffeController
-	syncLock                      sync.Mutex
+	client                          kubernetes.Interface
+	confClient                      k8s_nginx.Interface
+	dynClient                       dynamic.Interface
+	ingressController               cache.Controller
+	svcController                   cache.Controller
+	endpointController              cache.Controller
+	configMapController             cache.Controller
+	namespaceConfigMapController    cache.Controller
+	secretController                cache.Controller
+	virtualServerController         cache.Controller
+	virtualServerRouteController    cache.Controller
+	globalConfigurationControllers  []cache.Controller
+	transportServerController       cache.Controller
+	policyController                cache.Controller
+	podController                   cache.Controller
+	certificateController           cache.Controller
+	ingressLister                   storeToIngressLister
+	svcLister                       cache.Store
+	endpointLister                  storeToEndpointLister
+	configMapLister                 storeToConfigMapLister
+	namespaceConfigMapLister        storeToConfigMapLister
+	podLister                       storeToPodLister
+	secretLister                    storeToSecretLister
+	virtualServerLister             cache.Store
+	virtualServerRouteLister        cache.Store
+	globalConfigurationListers      []cache.Store
+	globalConfigurations            *globalConfigurationSet
+	transportServerLister           cache.Store
+	policyLister                    cache.Store
+	certificateLister               cache.Store
+	policyReferences                *policyReferenceIndex
+	ingressPaths                    *ingressPathIndex
+	syncQueue                       *taskQueue
+	ctx                             context.Context
+	cancel                          context.CancelFunc
+	configurator                    *configs.Configurator
+	watchNginxConfigMaps            bool
+	nginxConfigMapsKey              string
+	namespaceConfigMapName          string
+	watchGlobalConfiguration        bool
+	isNginxPlus                     bool
+	recorder                        record.EventRecorder
+	eventLog                        *eventLog
+	defaultServerSecret             string
+	ingressClass                    string
+	useIngressClassOnly             bool
+	statusUpdater                   *statusUpdater
+	leaderElector                   *leaderelection.LeaderElector
+	reportIngressStatus             bool
+	isLeaderElectionEnabled         bool
+	leaderElectionLockName          string
+	resync                          time.Duration
+	namespaces                      []string
+	controllerNamespace             string
+	wildcardTLSSecret               string
+	secretsNamespace                string
+	areCustomResourcesEnabled       bool
+	metricsCollector                collectors.ControllerCollector
+	globalConfigurationValidator    *validation.GlobalConfigurationValidator
+	transportServerValidator        *validation.TransportServerValidator
+	spiffeController                *spiffeController
+	missingTLSSecretPolicy          string
+	isDefaultServerSecretSelfSigned bool
+	externalServiceAddressChecker   *externalServiceAddressChecker
+	syncWorkers                     int
+	ingressDeleteGracePeriod        time.Duration
+	allowSnippets                   bool
+	maxVirtualServersPerNamespace   int
+	syncLock                        sync.RWMutex
+	resourceLocks                   resourceLocks
+	readyMu                         sync.Mutex
+	isReady                         bool
 }
 
 var keyFunc = cache.DeletionHandlingMetaNamespaceKeyFunc
 
+const (
+	// externalServiceAddressCheckPeriod is the period of checking that the external service has an address.
+	externalServiceAddressCheckPeriod = 30 * time.Second
+	// externalServiceAddressTimeout is the time after which the Ingress Controller reports that the external service
+	// doesn't have an address.
+	externalServiceAddressTimeout = 5 * time.Minute
+)
+
 // NewLoadBalancerControllerInput holds the input needed to call NewLoadBalancerController.
 type NewLoadBalancerControllerInput struct {
-	KubeClient                   kubernetes.Interface
-	ConfClient                   k8s_nginx.Interface
-	ResyncPeriod                 time.Duration
-	Namespace                    string
-	NginxConfigurator            *configs.Configurator
-	DefaultServerSecret          string
-	IsNginxPlus                  bool
-	IngressClass                 string
-	UseIngressClassOnly          bool
-	ExternalServiceName          string
-	ControllerNamespace          string
-	ReportIngressStatus          bool
-	IsLeaderElectionEnabled      bool
-	LeaderElectionLockName       string
-	WildcardTLSSecret            string
-	ConfigMaps                   string
-	GlobalConfiguration          string
-	AreCustomResourcesEnabled    bool
-	MetricsCollector             collectors.ControllerCollector
-	GlobalConfigurationValidator *validation.GlobalConfigurationValidator
-	TransportServerValidator     *validation.TransportServerValidator
-	SpireAgentAddress            string
+	KubeClient                      kubernetes.Interface
+	ConfClient                      k8s_nginx.Interface
+	DynamicClient                   dynamic.Interface
+	ResyncPeriod                    time.Duration
+	Namespaces                      []string
+	NginxConfigurator               *configs.Configurator
+	DefaultServerSecret             string
+	IsNginxPlus                     bool
+	IngressClass                    string
+	UseIngressClassOnly             bool
+	ExternalServiceName             string
+	ControllerNamespace             string
+	ReportIngressStatus             bool
+	IsLeaderElectionEnabled         bool
+	LeaderElectionLockName          string
+	WildcardTLSSecret               string
+	SecretsNamespace                string
+	ConfigMaps                      string
+	NamespaceConfigMapName          string
+	GlobalConfiguration             string
+	AreCustomResourcesEnabled       bool
+	MetricsCollector                collectors.ControllerCollector
+	GlobalConfigurationValidator    *validation.GlobalConfigurationValidator
+	TransportServerValidator        *validation.TransportServerValidator
+	SpireAgentAddress               string
+	MissingTLSSecretPolicy          string
+	IsDefaultServerSecretSelfSigned bool
+	SyncWorkers                     int
+	IngressDeleteGracePeriod        time.Duration
+	IsCertManagerEnabled            bool
+	AllowSnippets                   bool
+	MaxVirtualServersPerNamespace   int
+	IsStructuredEventLogEnabled     bool
 }
 
 // NewLoadBalancerController creates a controller
 func NewLoadBalancerController(input NewLoadBalancerControllerInput) *LoadBalancerController {
 	lbc := &LoadBalancerController{
-		client:                       input.KubeClient,
-		confClient:                   input.ConfClient,
-		configurator:                 input.NginxConfigurator,
-		defaultServerSecret:          input.DefaultServerSecret,
-		isNginxPlus:                  input.IsNginxPlus,
-		ingressClass:                 input.IngressClass,
-		useIngressClassOnly:          input.UseIngressClassOnly,
-		reportIngressStatus:          input.ReportIngressStatus,
-		isLeaderElectionEnabled:      input.IsLeaderElectionEnabled,
-		leaderElectionLockName:       input.LeaderElectionLockName,
-		resync:                       input.ResyncPeriod,
-		namespace:                    input.Namespace,
-		controllerNamespace:          input.ControllerNamespace,
-		wildcardTLSSecret:            input.WildcardTLSSecret,
-		areCustomResourcesEnabled:    input.AreCustomResourcesEnabled,
-		metricsCollector:             input.MetricsCollector,
-		globalConfigurationValidator: input.GlobalConfigurationValidator,
-		transportServerValidator:     input.TransportServerValidator,
+		client:                          input.KubeClient,
+		confClient:                      input.ConfClient,
+		dynClient:                       input.DynamicClient,
+		configurator:                    input.NginxConfigurator,
+		defaultServerSecret:             input.DefaultServerSecret,
+		isNginxPlus:                     input.IsNginxPlus,
+		ingressClass:                    input.IngressClass,
+		useIngressClassOnly:             input.UseIngressClassOnly,
+		reportIngressStatus:             input.ReportIngressStatus,
+		isLeaderElectionEnabled:         input.IsLeaderElectionEnabled,
+		leaderElectionLockName:          input.LeaderElectionLockName,
+		resync:                          input.ResyncPeriod,
+		namespaces:                      input.Namespaces,
+		controllerNamespace:             input.ControllerNamespace,
+		wildcardTLSSecret:               input.WildcardTLSSecret,
+		secretsNamespace:                input.SecretsNamespace,
+		areCustomResourcesEnabled:       input.AreCustomResourcesEnabled,
+		metricsCollector:                input.MetricsCollector,
+		globalConfigurationValidator:    input.GlobalConfigurationValidator,
+		transportServerValidator:        input.TransportServerValidator,
+		missingTLSSecretPolicy:          input.MissingTLSSecretPolicy,
+		isDefaultServerSecretSelfSigned: input.IsDefaultServerSecretSelfSigned,
+		namespaceConfigMapName:          input.NamespaceConfigMapName,
+		syncWorkers:                     input.SyncWorkers,
+		ingressDeleteGracePeriod:        input.IngressDeleteGracePeriod,
+		allowSnippets:                   input.AllowSnippets,
+		maxVirtualServersPerNamespace:   input.MaxVirtualServersPerNamespace,
+		policyReferences:                newPolicyReferenceIndex(),
+		ingressPaths:                    newIngressPathIndex(),
+	}
+
+	if lbc.syncWorkers < 1 {
+		lbc.syncWorkers = 1
+	}
+
+	if len(lbc.namespaces) == 0 {
+		lbc.namespaces = []string{api_v1.NamespaceAll}
 	}
 
 	eventBroadcaster := record.NewBroadcaster()
@@ -168,8 +235,19 @@ func NewLoadBalancerController(input NewLoadBalancerControllerInput) *LoadBalanc
 	})
 	lbc.recorder = eventBroadcaster.NewRecorder(scheme.Scheme,
 		api_v1.EventSource{Component: "nginx-ingress-controller"})
+	if input.IsStructuredEventLogEnabled {
+		lbc.eventLog = newEventLog(os.Stdout)
+		lbc.recorder = &eventLogRecorder{
+			EventRecorder: lbc.recorder,
+			eventLog:      lbc.eventLog,
+		}
+	}
 
-	lbc.syncQueue = newTaskQueue(lbc.sync)
+	lbc.syncQueue = newTaskQueue(lbc.sync, lbc.syncWorkers)
+	lbc.syncQueue.onDrain = lbc.metricsCollector.UpdateLastSyncQueueDrainTime
+	if input.ReportIngressStatus && input.ExternalServiceName != "" {
+		lbc.externalServiceAddressChecker = &externalServiceAddressChecker{timeout: externalServiceAddressTimeout}
+	}
 	if input.SpireAgentAddress != "" {
 		var err error
 		lbc.spiffeController, err = NewSpiffeController(lbc.syncSVIDRotation, input.SpireAgentAddress)
@@ -199,13 +277,22 @@ func NewLoadBalancerController(input NewLoadBalancerControllerInput) *LoadBalanc
 		lbc.addVirtualServerHandler(createVirtualServerHandlers(lbc))
 		lbc.addVirtualServerRouteHandler(createVirtualServerRouteHandlers(lbc))
 		lbc.addTransportServerHandler(createTransportServerHandlers(lbc))
+		lbc.addPolicyHandler(createPolicyHandlers(lbc))
+
+		if input.IsCertManagerEnabled {
+			lbc.addCertificateHandler(createCertificateHandlers(lbc))
+		}
 
 		if input.GlobalConfiguration != "" {
 			lbc.watchGlobalConfiguration = true
 
-			ns, name, _ := ParseNamespaceName(input.GlobalConfiguration)
+			keys, _ := ParseGlobalConfigurations(input.GlobalConfiguration)
+			lbc.globalConfigurations = newGlobalConfigurationSet(keys)
 
-			lbc.addGlobalConfigurationHandler(createGlobalConfigurationHandlers(lbc), ns, name)
+			for _, key := range keys {
+				ns, name, _ := ParseNamespaceName(key)
+				lbc.addGlobalConfigurationHandler(createGlobalConfigurationHandlers(lbc), ns, name)
+			}
 		}
 	}
 
@@ -215,10 +302,15 @@ func NewLoadBalancerController(input NewLoadBalancerControllerInput) *LoadBalanc
 			glog.Warning(err)
 		} else {
 			lbc.watchNginxConfigMaps = true
+			lbc.nginxConfigMapsKey = input.ConfigMaps
 			lbc.addConfigMapHandler(createConfigMapHandlers(lbc, nginxConfigMapsName), nginxConfigMapsNS)
 		}
 	}
 
+	if lbc.namespaceConfigMapName != "" {
+		lbc.addNamespaceConfigMapHandler(createConfigMapHandlers(lbc, lbc.namespaceConfigMapName), lbc.namespaceConfigMapName)
+	}
+
 	if input.IsLeaderElectionEnabled {
 		lbc.addLeaderHandler(createLeaderHandler(lbc))
 	}
@@ -247,58 +339,105 @@ func (lbc *LoadBalancerController) AddSyncQueue(item interface{}) {
 	lbc.syncQueue.Enqueue(item)
 }
 
+// addSyncQueueForIngressDelete enqueues the sync of an Ingress resource affected by a deletion of an Ingress.
+// If the delete grace period is configured, the sync is delayed so that NGINX keeps serving the configuration of
+// the deleted Ingress until the period expires.
+func (lbc *LoadBalancerController) addSyncQueueForIngressDelete(item interface{}) {
+	if lbc.ingressDeleteGracePeriod > 0 {
+		lbc.syncQueue.EnqueueAfter(item, lbc.ingressDeleteGracePeriod)
+		return
+	}
+	lbc.syncQueue.Enqueue(item)
+}
+
+// newInformer creates an informer for the resources in the watched namespaces.
+func (lbc *LoadBalancerController) newInformer(client cache.Getter, resource string, fieldSelector fields.Selector, objType runtime.Object,
+	handlers cache.ResourceEventHandlerFuncs) (cache.Store, cache.Controller) {
+	return lbc.newInformerForNamespaces(lbc.namespaces, client, resource, fieldSelector, objType, handlers)
+}
+
+// newInformerForNamespaces creates an informer for the resources in the given namespaces.
+func (lbc *LoadBalancerController) newInformerForNamespaces(namespaces []string, client cache.Getter, resource string, fieldSelector fields.Selector,
+	objType runtime.Object, handlers cache.ResourceEventHandlerFuncs) (cache.Store, cache.Controller) {
+	newListWatch := func(namespace string) cache.ListerWatcher {
+		return cache.NewListWatchFromClient(client, resource, namespace, fieldSelector)
+	}
+	return newMultiNamespaceInformer(namespaces, newListWatch, objType, lbc.resync, handlers)
+}
+
+// getSecretNamespaces returns the namespaces to watch for secrets: the watched namespaces, the namespaces of the special
+// secrets and the secrets namespace, so that those secrets are updated even if their namespaces are not watched.
+func (lbc *LoadBalancerController) getSecretNamespaces() []string {
+	namespaces := append([]string{}, lbc.namespaces...)
+
+	watched := make(map[string]bool)
+	for _, ns := range namespaces {
+		if ns == api_v1.NamespaceAll {
+			return namespaces
+		}
+		watched[ns] = true
+	}
+
+	for _, secret := range []string{lbc.defaultServerSecret, lbc.wildcardTLSSecret} {
+		if secret == "" {
+			continue
+		}
+		ns, _, err := ParseNamespaceName(secret)
+		if err != nil || watched[ns] {
+			continue
+		}
+		watched[ns] = true
+		namespaces = append(namespaces, ns)
+	}
+
+	if lbc.secretsNamespace != "" && !watched[lbc.secretsNamespace] {
+		namespaces = append(namespaces, lbc.secretsNamespace)
+	}
+
+	return namespaces
+}
+
 // addSecretHandler adds the handler for secrets to the controller
 func (lbc *LoadBalancerController) addSecretHandler(handlers cache.ResourceEventHandlerFuncs) {
-	lbc.secretLister.Store, lbc.secretController = cache.NewInformer(
-		cache.NewListWatchFromClient(
-			lbc.client.CoreV1().RESTClient(),
-			"secrets",
-			lbc.namespace,
-			fields.Everything()),
+	lbc.secretLister.Store, lbc.secretController = lbc.newInformerForNamespaces(
+		lbc.getSecretNamespaces(),
+		lbc.client.CoreV1().RESTClient(),
+		"secrets",
+		fields.Everything(),
 		&api_v1.Secret{},
-		lbc.resync,
 		handlers,
 	)
 }
 
 // addServiceHandler adds the handler for services to the controller
 func (lbc *LoadBalancerController) addServiceHandler(handlers cache.ResourceEventHandlerFuncs) {
-	lbc.svcLister, lbc.svcController = cache.NewInformer(
-		cache.NewListWatchFromClient(
-			lbc.client.CoreV1().RESTClient(),
-			"services",
-			lbc.namespace,
-			fields.Everything()),
+	lbc.svcLister, lbc.svcController = lbc.newInformer(
+		lbc.client.CoreV1().RESTClient(),
+		"services",
+		fields.Everything(),
 		&api_v1.Service{},
-		lbc.resync,
 		handlers,
 	)
 }
 
 // addIngressHandler adds the handler for ingresses to the controller
 func (lbc *LoadBalancerController) addIngressHandler(handlers cache.ResourceEventHandlerFuncs) {
-	lbc.ingressLister.Store, lbc.ingressController = cache.NewInformer(
-		cache.NewListWatchFromClient(
-			lbc.client.ExtensionsV1beta1().RESTClient(),
-			"ingresses",
-			lbc.namespace,
-			fields.Everything()),
+	lbc.ingressLister.Store, lbc.ingressController = lbc.newInformer(
+		lbc.client.ExtensionsV1beta1().RESTClient(),
+		"ingresses",
+		fields.Everything(),
 		&extensions.Ingress{},
-		lbc.resync,
 		handlers,
 	)
 }
 
 // addEndpointHandler adds the handler for endpoints to the controller
 func (lbc *LoadBalancerController) addEndpointHandler(handlers cache.ResourceEventHandlerFuncs) {
-	lbc.endpointLister.Store, lbc.endpointController = cache.NewInformer(
-		cache.NewListWatchFromClient(
-			lbc.client.CoreV1().RESTClient(),
-			"endpoints",
-			lbc.namespace,
-			fields.Everything()),
+	lbc.endpointLister.Store, lbc.endpointController = lbc.newInformer(
+		lbc.client.CoreV1().RESTClient(),
+		"endpoints",
+		fields.Everything(),
 		&api_v1.Endpoints{},
-		lbc.resync,
 		handlers,
 	)
 }
@@ -317,48 +456,50 @@ func (lbc *LoadBalancerController) addConfigMapHandler(handlers cache.ResourceEv
 	)
 }
 
+// addNamespaceConfigMapHandler adds the handler for the per-namespace config maps to the controller.
+// Only the config maps with the given name are watched.
+func (lbc *LoadBalancerController) addNamespaceConfigMapHandler(handlers cache.ResourceEventHandlerFuncs, name string) {
+	lbc.namespaceConfigMapLister.Store, lbc.namespaceConfigMapController = lbc.newInformer(
+		lbc.client.CoreV1().RESTClient(),
+		"configmaps",
+		fields.OneTermEqualSelector("metadata.name", name),
+		&api_v1.ConfigMap{},
+		handlers,
+	)
+}
+
 func (lbc *LoadBalancerController) addPodHandler() {
-	lbc.podLister.Indexer, lbc.podController = cache.NewIndexerInformer(
-		cache.NewListWatchFromClient(
-			lbc.client.CoreV1().RESTClient(),
-			"pods",
-			lbc.namespace,
-			fields.Everything()),
+	lbc.podLister.Store, lbc.podController = lbc.newInformer(
+		lbc.client.CoreV1().RESTClient(),
+		"pods",
+		fields.Everything(),
 		&api_v1.Pod{},
-		lbc.resync,
 		cache.ResourceEventHandlerFuncs{},
-		cache.Indexers{},
 	)
 }
 
 func (lbc *LoadBalancerController) addVirtualServerHandler(handlers cache.ResourceEventHandlerFuncs) {
-	lbc.virtualServerLister, lbc.virtualServerController = cache.NewInformer(
-		cache.NewListWatchFromClient(
-			lbc.confClient.K8sV1().RESTClient(),
-			"virtualservers",
-			lbc.namespace,
-			fields.Everything()),
+	lbc.virtualServerLister, lbc.virtualServerController = lbc.newInformer(
+		lbc.confClient.K8sV1().RESTClient(),
+		"virtualservers",
+		fields.Everything(),
 		&conf_v1.VirtualServer{},
-		lbc.resync,
 		handlers,
 	)
 }
 
 func (lbc *LoadBalancerController) addVirtualServerRouteHandler(handlers cache.ResourceEventHandlerFuncs) {
-	lbc.virtualServerRouteLister, lbc.virtualServerRouteController = cache.NewInformer(
-		cache.NewListWatchFromClient(
-			lbc.confClient.K8sV1().RESTClient(),
-			"virtualserverroutes",
-			lbc.namespace,
-			fields.Everything()),
+	lbc.virtualServerRouteLister, lbc.virtualServerRouteController = lbc.newInformer(
+		lbc.confClient.K8sV1().RESTClient(),
+		"virtualserverroutes",
+		fields.Everything(),
 		&conf_v1.VirtualServerRoute{},
-		lbc.resync,
 		handlers,
 	)
 }
 
 func (lbc *LoadBalancerController) addGlobalConfigurationHandler(handlers cache.ResourceEventHandlerFuncs, namespace string, name string) {
-	lbc.globalConfiguratonLister, lbc.globalConfigurationController = cache.NewInformer(
+	lister, controller := cache.NewInformer(
 		cache.NewListWatchFromClient(
 			lbc.confClient.K8sV1alpha1().RESTClient(),
 			"globalconfigurations",
@@ -368,17 +509,62 @@ func (lbc *LoadBalancerController) addGlobalConfigurationHandler(handlers cache.
 		lbc.resync,
 		handlers,
 	)
+
+	lbc.globalConfigurationListers = append(lbc.globalConfigurationListers, lister)
+	lbc.globalConfigurationControllers = append(lbc.globalConfigurationControllers, controller)
+}
+
+// getGlobalConfiguration gets the GlobalConfiguration with the key from the listers of the GlobalConfigurations.
+func (lbc *LoadBalancerController) getGlobalConfiguration(key string) (*conf_v1alpha1.GlobalConfiguration, bool, error) {
+	for _, lister := range lbc.globalConfigurationListers {
+		obj, exists, err := lister.GetByKey(key)
+		if err != nil {
+			return nil, false, err
+		}
+		if exists {
+			return obj.(*conf_v1alpha1.GlobalConfiguration), true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// getValidGlobalConfigurations returns the last valid versions of the GlobalConfigurations. A GlobalConfiguration
+// that hasn't been synced yet is validated, so that its listeners are not missing from the merged listeners.
+func (lbc *LoadBalancerController) getValidGlobalConfigurations() []*conf_v1alpha1.GlobalConfiguration {
+	for _, key := range lbc.globalConfigurations.getKeys() {
+		if lbc.globalConfigurations.has(key) {
+			continue
+		}
+
+		gc, exists, err := lbc.getGlobalConfiguration(key)
+		if err != nil || !exists {
+			continue
+		}
+		if lbc.globalConfigurationValidator.ValidateGlobalConfiguration(gc) == nil {
+			lbc.globalConfigurations.update(gc)
+		}
+	}
+
+	return lbc.globalConfigurations.getAll()
 }
 
 func (lbc *LoadBalancerController) addTransportServerHandler(handlers cache.ResourceEventHandlerFuncs) {
-	lbc.transportServerLister, lbc.transportServerController = cache.NewInformer(
-		cache.NewListWatchFromClient(
-			lbc.confClient.K8sV1alpha1().RESTClient(),
-			"transportservers",
-			lbc.namespace,
-			fields.Everything()),
+	lbc.transportServerLister, lbc.transportServerController = lbc.newInformer(
+		lbc.confClient.K8sV1alpha1().RESTClient(),
+		"transportservers",
+		fields.Everything(),
 		&conf_v1alpha1.TransportServer{},
-		lbc.resync,
+		handlers,
+	)
+}
+
+func (lbc *LoadBalancerController) addPolicyHandler(handlers cache.ResourceEventHandlerFuncs) {
+	lbc.policyLister, lbc.policyController = lbc.newInformer(
+		lbc.confClient.K8sV1alpha1().RESTClient(),
+		"policies",
+		fields.Everything(),
+		&conf_v1alpha1.Policy{},
 		handlers,
 	)
 }
@@ -404,17 +590,39 @@ func (lbc *LoadBalancerController) Run() {
 	if lbc.watchNginxConfigMaps {
 		go lbc.configMapController.Run(lbc.ctx.Done())
 	}
+	if lbc.namespaceConfigMapName != "" {
+		go lbc.namespaceConfigMapController.Run(lbc.ctx.Done())
+	}
 	go lbc.ingressController.Run(lbc.ctx.Done())
 	if lbc.areCustomResourcesEnabled {
 		go lbc.virtualServerController.Run(lbc.ctx.Done())
 		go lbc.virtualServerRouteController.Run(lbc.ctx.Done())
 		go lbc.transportServerController.Run(lbc.ctx.Done())
+		go lbc.policyController.Run(lbc.ctx.Done())
+		if lbc.certificateController != nil {
+			go lbc.certificateController.Run(lbc.ctx.Done())
+		}
 	}
 	if lbc.watchGlobalConfiguration {
-		go lbc.globalConfigurationController.Run(lbc.ctx.Done())
+		for _, controller := range lbc.globalConfigurationControllers {
+			go controller.Run(lbc.ctx.Done())
+		}
+	}
+
+	if lbc.isDefaultServerSecretSelfSigned {
+		go lbc.runSelfSignedDefaultServerSecretRotation(configs.SelfSignedCertificateRotationPeriod, lbc.ctx.Done())
+	}
+
+	if lbc.missingTLSSecretPolicy == configs.MissingTLSSecretPolicySelfSigned {
+		go lbc.runSelfSignedCertificateRenewal(configs.SelfSignedCertificateRenewalCheckPeriod, lbc.ctx.Done())
+	}
+
+	if lbc.externalServiceAddressChecker != nil {
+		go lbc.runExternalServiceAddressCheck(externalServiceAddressCheckPeriod, lbc.ctx.Done())
 	}
 
 	go lbc.syncQueue.Run(time.Second, lbc.ctx.Done())
+	lbc.setReady(true)
 	<-lbc.ctx.Done()
 }
 
@@ -438,6 +646,21 @@ func (lbc *LoadBalancerController) syncEndpoint(task task) {
 	if endpExists {
 		ings := lbc.getIngressForEndpoints(obj)
 
+		var virtualServers []*conf_v1.VirtualServer
+		var transportServers []*conf_v1alpha1.TransportServer
+		if lbc.areCustomResourcesEnabled {
+			virtualServers = lbc.getVirtualServersForEndpoints(obj.(*api_v1.Endpoints))
+			transportServers = lbc.getTransportServersForEndpoints(obj.(*api_v1.Endpoints))
+		}
+
+		// the configs are generated from the current state of the resources once the resources are locked
+		unlock := lbc.lockResources(ings, virtualServers, transportServers)
+		defer unlock()
+
+		ings = lbc.getLatestIngresses(ings)
+		virtualServers = lbc.getLatestVirtualServers(virtualServers)
+		transportServers = lbc.getLatestTransportServers(transportServers)
+
 		var ingExes []*configs.IngressEx
 		var mergableIngressesSlice []*configs.MergeableIngresses
 
@@ -491,7 +714,6 @@ func (lbc *LoadBalancerController) syncEndpoint(task task) {
 		}
 
 		if lbc.areCustomResourcesEnabled {
-			virtualServers := lbc.getVirtualServersForEndpoints(obj.(*api_v1.Endpoints))
 			virtualServersExes := lbc.virtualServersToVirtualServerExes(virtualServers)
 
 			if len(virtualServersExes) > 0 {
@@ -502,7 +724,6 @@ func (lbc *LoadBalancerController) syncEndpoint(task task) {
 				}
 			}
 
-			transportServers := lbc.getTransportServersForEndpoints(obj.(*api_v1.Endpoints))
 			transportServerExes := lbc.transportServersToTransportServerExes(transportServers)
 
 			if len(transportServerExes) > 0 {
@@ -556,7 +777,7 @@ func (lbc *LoadBalancerController) syncConfig(task task) {
 		}
 	}
 
-	warnings, updateErr := lbc.configurator.UpdateConfig(cfgParams, ingExes, mergeableIngresses, virtualServerExes)
+	warnings, resourceErrors, updateErr := lbc.configurator.UpdateConfig(cfgParams, ingExes, mergeableIngresses, virtualServerExes)
 
 	eventTitle := "Updated"
 	eventType := api_v1.EventTypeNormal
@@ -578,15 +799,18 @@ func (lbc *LoadBalancerController) syncConfig(task task) {
 		lbc.recorder.Eventf(cfgm, eventType, eventTitle, "Configuration from %v was updated %s", key, cmWarningMessage)
 	}
 	for _, ingEx := range ingExes {
-		lbc.recorder.Eventf(ingEx.Ingress, eventType, eventTitle, "Configuration for %v/%v was updated %s",
-			ingEx.Ingress.Namespace, ingEx.Ingress.Name, eventWarningMessage)
+		ingEventType, ingEventTitle, ingEventWarningMessage := getEventParamsForSkippedResource(resourceErrors, ingEx.Ingress, eventType, eventTitle, eventWarningMessage)
+		lbc.recorder.Eventf(ingEx.Ingress, ingEventType, ingEventTitle, "Configuration for %v/%v was updated %s",
+			ingEx.Ingress.Namespace, ingEx.Ingress.Name, ingEventWarningMessage)
 	}
 	for _, mergeableIng := range mergeableIngresses {
 		master := mergeableIng.Master
-		lbc.recorder.Eventf(master.Ingress, eventType, eventTitle, "Configuration for %v/%v(Master) was updated %s", master.Ingress.Namespace, master.Ingress.Name, eventWarningMessage)
+		// an error of a mergeable Ingress is reported for the master
+		ingEventType, ingEventTitle, ingEventWarningMessage := getEventParamsForSkippedResource(resourceErrors, master.Ingress, eventType, eventTitle, eventWarningMessage)
+		lbc.recorder.Eventf(master.Ingress, ingEventType, ingEventTitle, "Configuration for %v/%v(Master) was updated %s", master.Ingress.Namespace, master.Ingress.Name, ingEventWarningMessage)
 		for _, minion := range mergeableIng.Minions {
-			lbc.recorder.Eventf(minion.Ingress, eventType, eventTitle, "Configuration for %v/%v(Minion) was updated %s",
-				minion.Ingress.Namespace, minion.Ingress.Name, eventWarningMessage)
+			lbc.recorder.Eventf(minion.Ingress, ingEventType, ingEventTitle, "Configuration for %v/%v(Minion) was updated %s",
+				minion.Ingress.Namespace, minion.Ingress.Name, ingEventWarningMessage)
 		}
 	}
 	for _, vsEx := range virtualServerExes {
@@ -595,6 +819,8 @@ func (lbc *LoadBalancerController) syncConfig(task task) {
 		vsEventWarningMessage := eventWarningMessage
 		vsState := conf_v1.StateValid
 
+		_, skipped := resourceErrors[vsEx.VirtualServer]
+
 		if messages, ok := warnings[vsEx.VirtualServer]; ok && updateErr == nil {
 			vsEventType = api_v1.EventTypeWarning
 			vsEventTitle = "UpdatedWithWarning"
@@ -602,6 +828,12 @@ func (lbc *LoadBalancerController) syncConfig(task task) {
 			vsState = conf_v1.StateWarning
 		}
 
+		// an error of a VirtualServer is also reported for its VirtualServerRoutes
+		if skipped {
+			vsEventType, vsEventTitle, vsEventWarningMessage = getEventParamsForSkippedResource(resourceErrors, vsEx.VirtualServer, eventType, eventTitle, eventWarningMessage)
+			vsState = conf_v1.StateInvalid
+		}
+
 		msg := fmt.Sprintf("Configuration for %v/%v was updated %s", vsEx.VirtualServer.Namespace, vsEx.VirtualServer.Name, vsEventWarningMessage)
 		lbc.recorder.Eventf(vsEx.VirtualServer, vsEventType, vsEventTitle, msg)
 
@@ -626,6 +858,11 @@ func (lbc *LoadBalancerController) syncConfig(task task) {
 				vsrState = conf_v1.StateWarning
 			}
 
+			if skipped {
+				vsrEventType, vsrEventTitle, vsrEventWarningMessage = getEventParamsForSkippedResource(resourceErrors, vsEx.VirtualServer, eventType, eventTitle, eventWarningMessage)
+				vsrState = conf_v1.StateInvalid
+			}
+
 			msg := fmt.Sprintf("Configuration for %v/%v was updated %s", vsr.Namespace, vsr.Name, vsrEventWarningMessage)
 			lbc.recorder.Eventf(vsr, vsrEventType, vsrEventTitle, msg)
 
@@ -640,6 +877,138 @@ func (lbc *LoadBalancerController) syncConfig(task task) {
 	}
 }
 
+// getEventParamsForSkippedResource returns the type, the title and the warning message of the event for a resource
+// updated together with other resources. If the resource failed to update, its update was skipped, so that the configuration
+// of the other resources was applied. Otherwise, the parameters of the event for all resources are returned.
+func getEventParamsForSkippedResource(resourceErrors configs.ResourceErrors, obj runtime.Object, eventType string, eventTitle string,
+	eventWarningMessage string) (string, string, string) {
+	if err, ok := resourceErrors[obj]; ok {
+		return api_v1.EventTypeWarning, "UpdatedWithError", fmt.Sprintf("but was skipped and not applied: %v", err)
+	}
+
+	return eventType, eventTitle, eventWarningMessage
+}
+
+// isNamespaceConfigMapKey checks if the key belongs to a per-namespace ConfigMap.
+// The ConfigMap of the Ingress Controller is never considered a per-namespace ConfigMap.
+func (lbc *LoadBalancerController) isNamespaceConfigMapKey(key string) bool {
+	if lbc.namespaceConfigMapName == "" || key == lbc.nginxConfigMapsKey {
+		return false
+	}
+
+	_, name, err := ParseNamespaceName(key)
+	if err != nil {
+		return false
+	}
+
+	return name == lbc.namespaceConfigMapName
+}
+
+// getNamespaceConfigParams returns the parameters of the per-namespace ConfigMap of the namespace
+// or nil if the ConfigMap doesn't exist.
+func (lbc *LoadBalancerController) getNamespaceConfigParams(namespace string) *configs.NamespaceConfigParams {
+	if lbc.namespaceConfigMapName == "" {
+		return nil
+	}
+
+	key := namespace + "/" + lbc.namespaceConfigMapName
+	if key == lbc.nginxConfigMapsKey {
+		return nil
+	}
+
+	obj, exists, err := lbc.namespaceConfigMapLister.GetByKey(key)
+	if err != nil {
+		glog.Warningf("Error getting the per-namespace ConfigMap %v: %v", key, err)
+		return nil
+	}
+	if !exists {
+		return nil
+	}
+
+	return configs.ParseNamespaceConfigMap(obj.(*api_v1.ConfigMap))
+}
+
+func (lbc *LoadBalancerController) syncNamespaceConfigMap(task task) {
+	key := task.Key
+	glog.V(3).Infof("Syncing per-namespace configmap %v", key)
+
+	obj, configExists, err := lbc.namespaceConfigMapLister.GetByKey(key)
+	if err != nil {
+		lbc.syncQueue.Requeue(task, err)
+		return
+	}
+
+	namespace, _, _ := ParseNamespaceName(key)
+
+	allIngresses, allMergeableIngresses := lbc.GetManagedIngresses()
+
+	var ingresses []extensions.Ingress
+	for _, ing := range allIngresses {
+		if ing.Namespace == namespace {
+			ingresses = append(ingresses, ing)
+		}
+	}
+	ingExes := lbc.ingressesToIngressExes(ingresses)
+
+	mergeableIngresses := make(map[string]*configs.MergeableIngresses)
+	for name, mergeableIng := range allMergeableIngresses {
+		if isMergeableIngressInNamespace(mergeableIng, namespace) {
+			mergeableIngresses[name] = mergeableIng
+		}
+	}
+
+	if len(ingExes) == 0 && len(mergeableIngresses) == 0 {
+		return
+	}
+
+	resourceErrors, updateErr := lbc.configurator.UpdateNamespaceConfig(ingExes, mergeableIngresses)
+
+	eventTitle := "Updated"
+	eventType := api_v1.EventTypeNormal
+	eventWarningMessage := ""
+
+	if updateErr != nil {
+		eventTitle = "UpdatedWithError"
+		eventType = api_v1.EventTypeWarning
+		eventWarningMessage = fmt.Sprintf("but was not applied: %v", updateErr)
+	}
+
+	if configExists {
+		cfgm := obj.(*api_v1.ConfigMap)
+		lbc.recorder.Eventf(cfgm, eventType, eventTitle, "Configuration from %v was updated %s", key, eventWarningMessage)
+	}
+	for _, ingEx := range ingExes {
+		ingEventType, ingEventTitle, ingEventWarningMessage := getEventParamsForSkippedResource(resourceErrors, ingEx.Ingress, eventType, eventTitle, eventWarningMessage)
+		lbc.recorder.Eventf(ingEx.Ingress, ingEventType, ingEventTitle, "Configuration for %v/%v was updated %s",
+			ingEx.Ingress.Namespace, ingEx.Ingress.Name, ingEventWarningMessage)
+	}
+	for _, mergeableIng := range mergeableIngresses {
+		master := mergeableIng.Master
+		// an error of a mergeable Ingress is reported for the master
+		ingEventType, ingEventTitle, ingEventWarningMessage := getEventParamsForSkippedResource(resourceErrors, master.Ingress, eventType, eventTitle, eventWarningMessage)
+		lbc.recorder.Eventf(master.Ingress, ingEventType, ingEventTitle, "Configuration for %v/%v(Master) was updated %s", master.Ingress.Namespace, master.Ingress.Name, ingEventWarningMessage)
+		for _, minion := range mergeableIng.Minions {
+			lbc.recorder.Eventf(minion.Ingress, ingEventType, ingEventTitle, "Configuration for %v/%v(Minion) was updated %s",
+				minion.Ingress.Namespace, minion.Ingress.Name, ingEventWarningMessage)
+		}
+	}
+}
+
+// isMergeableIngressInNamespace checks if the master or any of the minions of the mergeable Ingress belong to the namespace.
+func isMergeableIngressInNamespace(mergeableIng *configs.MergeableIngresses, namespace string) bool {
+	if mergeableIng.Master.Ingress.Namespace == namespace {
+		return true
+	}
+
+	for _, minion := range mergeableIng.Minions {
+		if minion.Ingress.Namespace == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GetManagedIngresses gets Ingress resources that the IC is currently responsible for
 func (lbc *LoadBalancerController) GetManagedIngresses() ([]extensions.Ingress, map[string]*configs.MergeableIngresses) {
 	mergeableIngresses := make(map[string]*configs.MergeableIngresses)
@@ -694,6 +1063,7 @@ func (lbc *LoadBalancerController) virtualServersToVirtualServerExes(virtualServ
 
 	for _, vs := range virtualServers {
 		vsEx, _ := lbc.createVirtualServer(vs) // ignoring VirtualServerRouteErrors
+		lbc.policyReferences.update(vs, vsEx.VirtualServerRoutes)
 		virtualServersExes = append(virtualServersExes, vsEx)
 	}
 
@@ -713,9 +1083,16 @@ func (lbc *LoadBalancerController) transportServersToTransportServerExes(transpo
 
 func (lbc *LoadBalancerController) sync(task task) {
 	glog.V(3).Infof("Syncing %v", task.Key)
-	if lbc.spiffeController != nil {
-		lbc.syncLock.Lock()
-		defer lbc.syncLock.Unlock()
+	if lbc.spiffeController != nil || lbc.isDefaultServerSecretSelfSigned || lbc.externalServiceAddressChecker != nil || lbc.syncWorkers > 1 {
+		// the tasks that change the state shared by all resources must not run concurrently with other tasks.
+		// The other tasks lock the resources whose configs they generate, see resourceLocks.
+		if isSharedStateTask(task) {
+			lbc.syncLock.Lock()
+			defer lbc.syncLock.Unlock()
+		} else {
+			lbc.syncLock.RLock()
+			defer lbc.syncLock.RUnlock()
+		}
 	}
 	switch task.Kind {
 	case ingress:
@@ -725,7 +1102,11 @@ func (lbc *LoadBalancerController) sync(task task) {
 		lbc.syncIngMinion(task)
 		lbc.updateIngressMetrics()
 	case configMap:
-		lbc.syncConfig(task)
+		if lbc.isNamespaceConfigMapKey(task.Key) {
+			lbc.syncNamespaceConfigMap(task)
+		} else {
+			lbc.syncConfig(task)
+		}
 	case endpoints:
 		lbc.syncEndpoint(task)
 	case secret:
@@ -740,13 +1121,72 @@ func (lbc *LoadBalancerController) sync(task task) {
 		lbc.updateVirtualServerMetrics()
 	case globalConfiguration:
 		lbc.syncGlobalConfiguration(task)
+		lbc.updateGlobalConfigurationMetrics()
+		lbc.updateTransportServerMetrics()
 	case transportserver:
 		lbc.syncTransportServer(task)
+		lbc.updateTransportServerMetrics()
+	case policy:
+		lbc.syncPolicy(task)
+	case certificate:
+		lbc.syncCertificate(task)
+	}
+}
+
+// isSharedStateTask checks if the task changes the state shared by all resources, like the ConfigMap,
+// the GlobalConfiguration or the external service used for reporting the status.
+func isSharedStateTask(task task) bool {
+	switch task.Kind {
+	case configMap, service, globalConfiguration:
+		return true
+	}
+	return false
+}
+
+func (lbc *LoadBalancerController) syncPolicy(task task) {
+	key := task.Key
+	obj, polExists, err := lbc.policyLister.GetByKey(key)
+	if err != nil {
+		lbc.syncQueue.Requeue(task, err)
+		return
+	}
+
+	glog.V(2).Infof("Adding, Updating or Deleting Policy: %v\n", key)
+
+	if polExists {
+		pol := obj.(*conf_v1alpha1.Policy)
+		err := validation.ValidatePolicy(pol, lbc.isNginxPlus)
+		if err != nil {
+			lbc.recorder.Eventf(pol, api_v1.EventTypeWarning, "Rejected", "Policy %v is invalid and was rejected: %v", key, err)
+			lbc.recordValidationFailure(pol, err)
+		} else if pol.Spec.JWTAuth != nil {
+			if _, err := lbc.getSecretKeyForReference(pol.Namespace, pol.Spec.JWTAuth.Secret); err != nil {
+				lbc.recorder.Eventf(pol, api_v1.EventTypeWarning, "SecretNotAllowed", "Policy %v was added or updated with warning(s): %v", key, err)
+			} else {
+				lbc.recorder.Eventf(pol, api_v1.EventTypeNormal, "AddedOrUpdated", "Policy %v was added or updated", key)
+			}
+		} else {
+			lbc.recorder.Eventf(pol, api_v1.EventTypeNormal, "AddedOrUpdated", "Policy %v was added or updated", key)
+		}
+	}
+
+	// A Policy change or deletion affects the configuration of the VirtualServers that reference it,
+	// either in their own routes or in the subroutes of their VirtualServerRoutes.
+	namespace, name, err := ParseNamespaceName(key)
+	if err != nil {
+		glog.Warningf("Policy key %v is invalid: %v", key, err)
+		return
 	}
+
+	resyncs := lbc.enqueueVirtualServersForPolicy(namespace, name)
+	glog.V(2).Infof("Enqueued %v VirtualServers for Policy %v", resyncs, key)
 }
 
 func (lbc *LoadBalancerController) syncTransportServer(task task) {
 	key := task.Key
+	unlock := lbc.resourceLocks.lock([]string{getTransportServerLockKey(key)})
+	defer unlock()
+
 	obj, tsExists, err := lbc.transportServerLister.GetByKey(key)
 	if err != nil {
 		lbc.syncQueue.Requeue(task, err)
@@ -774,6 +1214,7 @@ func (lbc *LoadBalancerController) syncTransportServer(task task) {
 			glog.Errorf("Error when deleting configuration for %v: %v", key, err)
 		}
 		lbc.recorder.Eventf(ts, api_v1.EventTypeWarning, "Rejected", "TransportServer %v is invalid and was rejected: %v", key, validationErr)
+		lbc.recordValidationFailure(ts, validationErr)
 		return
 	}
 
@@ -805,7 +1246,7 @@ func (lbc *LoadBalancerController) syncTransportServer(task task) {
 
 func (lbc *LoadBalancerController) syncGlobalConfiguration(task task) {
 	key := task.Key
-	obj, gcExists, err := lbc.globalConfiguratonLister.GetByKey(key)
+	gc, gcExists, err := lbc.getGlobalConfiguration(key)
 	if err != nil {
 		lbc.syncQueue.Requeue(task, err)
 		return
@@ -818,20 +1259,28 @@ func (lbc *LoadBalancerController) syncGlobalConfiguration(task task) {
 
 	glog.V(2).Infof("GlobalConfiguration was updated: %v\n", key)
 
-	gc := obj.(*conf_v1alpha1.GlobalConfiguration)
-
 	validationErr := lbc.globalConfigurationValidator.ValidateGlobalConfiguration(gc)
 	if validationErr != nil {
 		lbc.recorder.Eventf(gc, api_v1.EventTypeWarning, "Rejected", "GlobalConfiguration %v is invalid and was rejected: %v", key, validationErr)
+		lbc.recordValidationFailure(gc, validationErr)
 		return
 	}
 
+	lbc.globalConfigurations.update(gc)
+
+	// the listeners of all GlobalConfigurations are merged, so a conflict can be caused by a change of another GlobalConfiguration
+	mergedGC, conflicts := configs.MergeGlobalConfigurations(lbc.getValidGlobalConfigurations())
+	for _, c := range conflicts {
+		lbc.recorder.Eventf(c.GlobalConfiguration, api_v1.EventTypeWarning, "ListenerConflict", "Listener %v of GlobalConfiguration %v/%v was ignored: %v",
+			c.Listener, c.GlobalConfiguration.Namespace, c.GlobalConfiguration.Name, c.Reason)
+	}
+
 	// GlobalConfiguration configures listeners
 	// As a result, a change in a GC might affect all TransportServers
 
 	transportServerExes := lbc.transportServersToTransportServerExes(lbc.getTransportServers())
 
-	updatedTransportServerExes, deletedTransportServerExes, updateErr := lbc.configurator.UpdateGlobalConfiguration(gc, transportServerExes)
+	updatedTransportServerExes, deletedTransportServerExes, updateErr := lbc.configurator.UpdateGlobalConfiguration(mergedGC, transportServerExes)
 
 	for _, tsEx := range deletedTransportServerExes {
 		eventTitle := "Rejected"
@@ -865,15 +1314,21 @@ func (lbc *LoadBalancerController) syncGlobalConfiguration(task task) {
 
 func (lbc *LoadBalancerController) syncVirtualServer(task task) {
 	key := task.Key
+	unlock := lbc.resourceLocks.lock([]string{getVirtualServerLockKey(key)})
+	defer unlock()
+
 	obj, vsExists, err := lbc.virtualServerLister.GetByKey(key)
 	if err != nil {
 		lbc.syncQueue.Requeue(task, err)
 		return
 	}
 	previousVSRs := lbc.configurator.GetVirtualServerRoutesForVirtualServer(key)
+	previousVS := lbc.configurator.GetVirtualServer(key)
 	if !vsExists {
 		glog.V(2).Infof("Deleting VirtualServer: %v\n", key)
 
+		lbc.policyReferences.remove(key)
+
 		err := lbc.configurator.DeleteVirtualServer(key)
 		if err != nil {
 			glog.Errorf("Error when deleting configuration for %v: %v", key, err)
@@ -892,46 +1347,70 @@ func (lbc *LoadBalancerController) syncVirtualServer(task task) {
 			}
 
 		}
+
+		if namespace, _, err := ParseNamespaceName(key); err == nil {
+			lbc.enqueueVirtualServersWithinNamespaceLimit(namespace)
+		}
 		return
 	}
 
 	glog.V(2).Infof("Adding or Updating VirtualServer: %v\n", key)
 	vs := obj.(*conf_v1.VirtualServer)
 
-	validationErr := validation.ValidateVirtualServer(vs, lbc.isNginxPlus)
-	if validationErr != nil {
-		err := lbc.configurator.DeleteVirtualServer(key)
-		if err != nil {
-			glog.Errorf("Error when deleting configuration for %v: %v", key, err)
-		}
-
-		reason := "Rejected"
-		msg := fmt.Sprintf("VirtualServer %v is invalid and was rejected: %v", key, validationErr)
+	validationErrs := lbc.validateVirtualServer(vs)
+	if len(validationErrs) > 0 {
+		msg := fmt.Sprintf("VirtualServer %v is invalid and was rejected: %v", key, validationErrs.ToAggregate())
+		lbc.rejectVirtualServer(vs, key, msg, previousVSRs)
+		lbc.recordValidationFailure(vs, validationErrs.ToAggregate())
+		lbc.enqueueVirtualServersWithinNamespaceLimit(vs.Namespace)
+		return
+	}
 
-		lbc.recorder.Eventf(vs, api_v1.EventTypeWarning, reason, msg)
-		if lbc.reportVsVsrStatusEnabled() {
-			err = lbc.statusUpdater.UpdateVirtualServerStatus(vs, conf_v1.StateInvalid, reason, msg)
-		}
+	if lbc.isVirtualServerOverNamespaceLimit(vs) {
+		msg := fmt.Sprintf("VirtualServer %v was rejected: the namespace %v already has the maximum of %v VirtualServers", key, vs.Namespace, lbc.maxVirtualServersPerNamespace)
+		lbc.rejectVirtualServer(vs, key, msg, previousVSRs)
+		return
+	}
 
-		reason = "NoVirtualServerFound"
-		for _, vsr := range previousVSRs {
-			msg := fmt.Sprintf("No VirtualServer references VirtualServerRoute %v/%v", vsr.Namespace, vsr.Name)
-			lbc.recorder.Eventf(vsr, api_v1.EventTypeWarning, reason, msg)
+	if err := lbc.validateUpstreamServicePorts(vs.Namespace, vs.Spec.Upstreams); err != nil {
+		msg := fmt.Sprintf("VirtualServer %v is invalid and was rejected: %v", key, err)
+		lbc.rejectVirtualServer(vs, key, msg, previousVSRs)
+		return
+	}
 
-			if lbc.reportVsVsrStatusEnabled() {
-				virtualServersForVSR := []*conf_v1.VirtualServer{}
-				err = lbc.statusUpdater.UpdateVirtualServerRouteStatusWithReferencedBy(vsr, conf_v1.StateInvalid, reason, msg, virtualServersForVSR)
-				if err != nil {
-					glog.Errorf("Error when updating the status for VirtualServerRoute %v/%v: %v", vsr.Namespace, vsr.Name, err)
-				}
-			}
-		}
+	if err := lbc.validateUpstreamServices(vs.Namespace, vs.Spec.Upstreams); err != nil {
+		msg := fmt.Sprintf("VirtualServer %v is invalid and was rejected: %v", key, err)
+		lbc.rejectVirtualServer(vs, key, msg, previousVSRs)
 		return
 	}
 
 	var handledVSRs []*conf_v1.VirtualServerRoute
 
 	vsEx, vsrErrors := lbc.createVirtualServer(vs)
+	lbc.policyReferences.update(vs, vsEx.VirtualServerRoutes)
+
+	if vs.Spec.TLS != nil && vs.Spec.TLS.Secret != "" {
+		if _, err := lbc.getSecretKeyForReference(vs.Namespace, vs.Spec.TLS.Secret); err != nil {
+			lbc.recorder.Eventf(vs, api_v1.EventTypeWarning, "SecretNotAllowed", "VirtualServer %v references a TLS secret that is not allowed: %v", key, err)
+		}
+	}
+
+	if vs.Spec.TLS != nil && vs.Spec.TLS.Certificate != "" {
+		if _, err := lbc.getTLSSecretReference(vs); err != nil {
+			lbc.recorder.Eventf(vs, api_v1.EventTypeWarning, "CertificateNotReady", "VirtualServer %v references a Certificate that cannot be used yet: %v", key, err)
+		}
+	}
+
+	if err := lbc.validateErrorBackendService(vs); err != nil {
+		lbc.recorder.Eventf(vs, api_v1.EventTypeWarning, "ErrorBackendServiceNotFound", "VirtualServer %v references an error backend that cannot handle the error responses: %v", key, err)
+	}
+
+	if lbc.missingTLSSecretPolicy == configs.MissingTLSSecretPolicyError && configs.HasMissingTLSSecret(vsEx) {
+		msg := fmt.Sprintf("VirtualServer %v references %v that is invalid, doesn't exist or is not allowed; the %s policy was applied: the VirtualServer was rejected",
+			key, configs.GetMissingTLSSecretDescription(vs), configs.MissingTLSSecretPolicyError)
+		lbc.rejectVirtualServer(vs, key, msg, previousVSRs)
+		return
+	}
 
 	for _, vsrError := range vsrErrors {
 		lbc.recorder.Eventf(vs, api_v1.EventTypeWarning, "IgnoredVirtualServerRoute", "Ignored VirtualServerRoute %v: %v", vsrError.VirtualServerRouteNsName, vsrError.Error)
@@ -969,6 +1448,14 @@ func (lbc *LoadBalancerController) syncVirtualServer(task task) {
 	msg := fmt.Sprintf("Configuration for %v was added or updated %s", key, vsEventWarningMessage)
 	lbc.recorder.Eventf(vs, vsEventType, vsEventTitle, msg)
 
+	if addErr == nil {
+		var previousRoutes []conf_v1.Route
+		if previousVS != nil {
+			previousRoutes = previousVS.Spec.Routes
+		}
+		lbc.recordSplitsCutoverEvents(vs, findChangedCutovers(previousRoutes, vs.Spec.Routes))
+	}
+
 	if lbc.reportVsVsrStatusEnabled() {
 		err = lbc.statusUpdater.UpdateVirtualServerStatus(vs, state, vsEventTitle, msg)
 
@@ -992,6 +1479,14 @@ func (lbc *LoadBalancerController) syncVirtualServer(task task) {
 		msg := fmt.Sprintf("Configuration for %v/%v was added or updated %s", vsr.Namespace, vsr.Name, vsrEventWarningMessage)
 		lbc.recorder.Eventf(vsr, vsrEventType, vsrEventTitle, msg)
 
+		if addErr == nil {
+			var previousSubroutes []conf_v1.Route
+			if previousVSR := findVirtualServerRoute(previousVSRs, vsr.Namespace, vsr.Name); previousVSR != nil {
+				previousSubroutes = previousVSR.Spec.Subroutes
+			}
+			lbc.recordSplitsCutoverEvents(vsr, findChangedCutovers(previousSubroutes, vsr.Spec.Subroutes))
+		}
+
 		if lbc.reportVsVsrStatusEnabled() {
 			vss := []*conf_v1.VirtualServer{vs}
 			err = lbc.statusUpdater.UpdateVirtualServerRouteStatusWithReferencedBy(vsr, state, vsrEventTitle, msg, vss)
@@ -1019,6 +1514,45 @@ func (lbc *LoadBalancerController) syncVirtualServer(task task) {
 	}
 }
 
+// findChangedCutovers returns the routes that have a cutover that the route with the same path in the previous routes
+// didn't have, so that a promotion or a rollback is reported only once.
+func findChangedCutovers(previousRoutes []conf_v1.Route, routes []conf_v1.Route) []conf_v1.Route {
+	previousCutovers := make(map[string]string)
+	for _, r := range previousRoutes {
+		previousCutovers[r.Path] = r.Cutover
+	}
+
+	var changed []conf_v1.Route
+	for _, r := range routes {
+		if r.Cutover != "" && previousCutovers[r.Path] != r.Cutover {
+			changed = append(changed, r)
+		}
+	}
+
+	return changed
+}
+
+// recordSplitsCutoverEvents records an event for the promotion or the rollback of the splits of each route.
+func (lbc *LoadBalancerController) recordSplitsCutoverEvents(obj runtime.Object, routes []conf_v1.Route) {
+	for _, r := range routes {
+		switch r.Cutover {
+		case conf_v1.CutoverPromote:
+			lbc.recorder.Eventf(obj, api_v1.EventTypeNormal, "SplitsPromoted", "All traffic of the route %v goes to the last split", r.Path)
+		case conf_v1.CutoverRollback:
+			lbc.recorder.Eventf(obj, api_v1.EventTypeNormal, "SplitsRolledBack", "All traffic of the route %v goes to the first split", r.Path)
+		}
+	}
+}
+
+func findVirtualServerRoute(vsrs []*conf_v1.VirtualServerRoute, namespace string, name string) *conf_v1.VirtualServerRoute {
+	for _, vsr := range vsrs {
+		if vsr.Namespace == namespace && vsr.Name == name {
+			return vsr
+		}
+	}
+	return nil
+}
+
 func findOrphanedVirtualServerRoutes(previousVSRs []*conf_v1.VirtualServerRoute, handledVSRs []*conf_v1.VirtualServerRoute) []*conf_v1.VirtualServerRoute {
 	var orphanedVSRs []*conf_v1.VirtualServerRoute
 	for _, prev := range previousVSRs {
@@ -1038,6 +1572,41 @@ func findOrphanedVirtualServerRoutes(previousVSRs []*conf_v1.VirtualServerRoute,
 	return orphanedVSRs
 }
 
+// rejectVirtualServer removes the configuration of the VirtualServer and reports it as rejected
+// along with the VirtualServerRoutes that it previously referenced.
+func (lbc *LoadBalancerController) rejectVirtualServer(vs *conf_v1.VirtualServer, key string, msg string, previousVSRs []*conf_v1.VirtualServerRoute) {
+	lbc.policyReferences.remove(key)
+
+	err := lbc.configurator.DeleteVirtualServer(key)
+	if err != nil {
+		glog.Errorf("Error when deleting configuration for %v: %v", key, err)
+	}
+
+	reason := "Rejected"
+
+	lbc.recorder.Eventf(vs, api_v1.EventTypeWarning, reason, msg)
+	if lbc.reportVsVsrStatusEnabled() {
+		err = lbc.statusUpdater.UpdateVirtualServerStatus(vs, conf_v1.StateInvalid, reason, msg)
+		if err != nil {
+			glog.Errorf("Error when updating the status for VirtualServer %v/%v: %v", vs.Namespace, vs.Name, err)
+		}
+	}
+
+	reason = "NoVirtualServerFound"
+	for _, vsr := range previousVSRs {
+		msg := fmt.Sprintf("No VirtualServer references VirtualServerRoute %v/%v", vsr.Namespace, vsr.Name)
+		lbc.recorder.Eventf(vsr, api_v1.EventTypeWarning, reason, msg)
+
+		if lbc.reportVsVsrStatusEnabled() {
+			virtualServersForVSR := []*conf_v1.VirtualServer{}
+			err = lbc.statusUpdater.UpdateVirtualServerRouteStatusWithReferencedBy(vsr, conf_v1.StateInvalid, reason, msg, virtualServersForVSR)
+			if err != nil {
+				glog.Errorf("Error when updating the status for VirtualServerRoute %v/%v: %v", vsr.Namespace, vsr.Name, err)
+			}
+		}
+	}
+}
+
 func (lbc *LoadBalancerController) syncVirtualServerRoute(task task) {
 	key := task.Key
 
@@ -1059,10 +1628,14 @@ func (lbc *LoadBalancerController) syncVirtualServerRoute(task task) {
 	vsr := obj.(*conf_v1.VirtualServerRoute)
 
 	validationErr := validation.ValidateVirtualServerRoute(vsr, lbc.isNginxPlus)
+	if validationErr == nil && !lbc.allowSnippets {
+		validationErr = validation.RejectSnippetsInVirtualServerRoute(vsr)
+	}
 	if validationErr != nil {
 		reason := "Rejected"
 		msg := fmt.Sprintf("VirtualServerRoute %s is invalid and was rejected: %v", key, validationErr)
 		lbc.recorder.Eventf(vsr, api_v1.EventTypeWarning, reason, msg)
+		lbc.recordValidationFailure(vsr, validationErr)
 		if lbc.reportVsVsrStatusEnabled() {
 			err = lbc.statusUpdater.UpdateVirtualServerRouteStatus(vsr, conf_v1.StateInvalid, reason, msg)
 			if err != nil {
@@ -1100,6 +1673,9 @@ func (lbc *LoadBalancerController) syncIngMinion(task task) {
 	}
 	glog.V(2).Infof("Adding or Updating Minion: %v\n", key)
 
+	// the paths of a minion don't conflict with the paths of regular Ingresses. The Ingress could be a regular Ingress before.
+	lbc.enqueueIngresses(lbc.ingressPaths.remove(key))
+
 	minion := obj.(*extensions.Ingress)
 
 	master, err := lbc.FindMasterForMinion(minion)
@@ -1110,6 +1686,8 @@ func (lbc *LoadBalancerController) syncIngMinion(task task) {
 
 	_, err = lbc.createIngress(minion)
 	if err != nil {
+		lbc.recorder.Eventf(minion, api_v1.EventTypeWarning, "Rejected", "%v was rejected: %v", key, err)
+		lbc.recordValidationFailure(minion, err)
 		lbc.syncQueue.RequeueAfter(task, err, 5*time.Second)
 		if !lbc.configurator.HasMinion(master, minion) {
 			return
@@ -1121,6 +1699,9 @@ func (lbc *LoadBalancerController) syncIngMinion(task task) {
 
 func (lbc *LoadBalancerController) syncIng(task task) {
 	key := task.Key
+	unlock := lbc.resourceLocks.lock([]string{getIngressLockKey(key)})
+	defer unlock()
+
 	ing, ingExists, err := lbc.ingressLister.GetByKeySafe(key)
 	if err != nil {
 		lbc.syncQueue.Requeue(task, err)
@@ -1134,10 +1715,20 @@ func (lbc *LoadBalancerController) syncIng(task task) {
 		if err != nil {
 			glog.Errorf("Error when deleting configuration for %v: %v", key, err)
 		}
+
+		lbc.enqueueIngresses(lbc.ingressPaths.remove(key))
 	} else {
+		if isIngressPaused(ing) && lbc.configurator.HasIngress(ing) {
+			glog.V(2).Infof("Ingress %v is paused, keeping its configuration\n", key)
+			return
+		}
+
 		glog.V(2).Infof("Adding or Updating Ingress: %v\n", key)
 
 		if isMaster(ing) {
+			// the paths of a master and its minions don't conflict with the paths of regular Ingresses
+			lbc.enqueueIngresses(lbc.ingressPaths.remove(key))
+
 			mergeableIngExs, err := lbc.createMergableIngresses(ing)
 			if err != nil {
 				// we need to requeue because an error can occur even if the master is valid
@@ -1145,6 +1736,7 @@ func (lbc *LoadBalancerController) syncIng(task task) {
 				// in the master or minions.
 				lbc.syncQueue.RequeueAfter(task, err, 5*time.Second)
 				lbc.recorder.Eventf(ing, api_v1.EventTypeWarning, "Rejected", "%v was rejected: %v", key, err)
+				lbc.recordValidationFailure(ing, err)
 				if lbc.reportStatusEnabled() {
 					err = lbc.statusUpdater.ClearIngressStatus(*ing)
 					if err != nil {
@@ -1178,9 +1770,16 @@ func (lbc *LoadBalancerController) syncIng(task task) {
 			}
 			return
 		}
+
+		lbc.enqueueIngresses(lbc.ingressPaths.update(ing))
+		for _, c := range lbc.ingressPaths.getConflicts(key) {
+			lbc.recorder.Eventf(ing, api_v1.EventTypeWarning, "PathConflict", "The path %v of the host %v of %v is ignored: the older Ingress %v defines the same host and path", c.path, c.host, key, c.owner)
+		}
+
 		ingEx, err := lbc.createIngress(ing)
 		if err != nil {
 			lbc.recorder.Eventf(ing, api_v1.EventTypeWarning, "Rejected", "%v was rejected: %v", key, err)
+			lbc.recordValidationFailure(ing, err)
 			if lbc.reportStatusEnabled() {
 				err = lbc.statusUpdater.ClearIngressStatus(*ing)
 				if err != nil {
@@ -1190,6 +1789,10 @@ func (lbc *LoadBalancerController) syncIng(task task) {
 			return
 		}
 
+		if err := lbc.validateIngressDefaultBackend(ing); err != nil {
+			lbc.recorder.Eventf(ing, api_v1.EventTypeWarning, "InvalidDefaultBackend", "%v references an invalid default backend: %v", key, err)
+		}
+
 		err = lbc.configurator.AddOrUpdateIngress(ingEx)
 		if err != nil {
 			lbc.recorder.Eventf(ing, api_v1.EventTypeWarning, "AddedOrUpdatedWithError", "Configuration for %v was added or updated, but not applied: %v", key, err)
@@ -1218,6 +1821,28 @@ func (lbc *LoadBalancerController) updateVirtualServerMetrics() {
 	lbc.metricsCollector.SetVirtualServerRoutes(vsrCount)
 }
 
+// updateTransportServerMetrics counts the valid TransportServers that reference an existing listener.
+func (lbc *LoadBalancerController) updateTransportServerMetrics() {
+	transportServers := lbc.filterOutTransportServersWithNonExistingListener(lbc.getTransportServers())
+	lbc.metricsCollector.SetTransportServers(len(transportServers))
+}
+
+// updateGlobalConfigurationMetrics counts the valid GlobalConfigurations.
+func (lbc *LoadBalancerController) updateGlobalConfigurationMetrics() {
+	count := 0
+
+	for _, lister := range lbc.globalConfigurationListers {
+		for _, obj := range lister.List() {
+			gc := obj.(*conf_v1alpha1.GlobalConfiguration)
+			if lbc.globalConfigurationValidator.ValidateGlobalConfiguration(gc) == nil {
+				count++
+			}
+		}
+	}
+
+	lbc.metricsCollector.SetGlobalConfigurations(count)
+}
+
 // syncExternalService does not sync all services.
 // We only watch the Service specified by the external-service flag.
 func (lbc *LoadBalancerController) syncExternalService(task task) {
@@ -1250,6 +1875,58 @@ func (lbc *LoadBalancerController) syncExternalService(task task) {
 	}
 }
 
+// runExternalServiceAddressCheck periodically checks that the external service has an address.
+func (lbc *LoadBalancerController) runExternalServiceAddressCheck(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			lbc.syncLock.Lock()
+			lbc.checkExternalServiceAddress(time.Now())
+			lbc.syncLock.Unlock()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// checkExternalServiceAddress logs and records an event when the external service hasn't got an address
+// for longer than the timeout, which explains why the status of the resources is empty.
+// The check is skipped if the external-status-address ConfigMap key is set, because it takes precedence.
+func (lbc *LoadBalancerController) checkExternalServiceAddress(now time.Time) {
+	if lbc.statusUpdater.externalStatusAddress != "" {
+		return
+	}
+
+	key := lbc.statusUpdater.namespace + "/" + lbc.statusUpdater.externalServiceName
+	obj, exists, err := lbc.svcLister.GetByKey(key)
+	if err != nil {
+		glog.Errorf("Error getting the external service %v: %v", key, err)
+		return
+	}
+
+	var svc *api_v1.Service
+	if exists {
+		svc = obj.(*api_v1.Service)
+	}
+
+	hasAddress := len(getExternalServiceAddress(svc)) > 0
+	if !lbc.externalServiceAddressChecker.check(hasAddress, now) {
+		return
+	}
+
+	if svc == nil {
+		glog.Warningf("The external service %v doesn't exist after %v. The status of the resources will be empty", key, lbc.externalServiceAddressChecker.timeout)
+		return
+	}
+
+	glog.Warningf("The external service %v doesn't have an external address after %v. The status of the resources will be empty", key, lbc.externalServiceAddressChecker.timeout)
+	lbc.recorder.Eventf(svc, api_v1.EventTypeWarning, "NoExternalAddress",
+		"The service doesn't have an external address after %v. The status of the resources will be empty", lbc.externalServiceAddressChecker.timeout)
+}
+
 // IsExternalServiceForStatus matches the service specified by the external-service arg
 func (lbc *LoadBalancerController) IsExternalServiceForStatus(svc *api_v1.Service) bool {
 	return lbc.statusUpdater.namespace == svc.Namespace && lbc.statusUpdater.externalServiceName == svc.Name
@@ -1303,6 +1980,32 @@ func (lbc *LoadBalancerController) syncSecret(task task) {
 
 	glog.V(2).Infof("Found %v Ingresses with Secret %v", len(ings), key)
 
+	if lbc.areCustomResourcesEnabled {
+		// the VirtualServers that reference JWT policies with the secret need the new content of the secret
+		// or need to stop using it.
+		for _, pol := range findPoliciesForSecret(lbc.getPolicies(), namespace, name) {
+			lbc.enqueueVirtualServersForPolicy(pol.Namespace, pol.Name)
+		}
+
+		// the VirtualServers with OIDC that reference the secret need the new client secret or need to stop using it.
+		for _, vs := range findVirtualServersForOIDCSecret(lbc.getVirtualServers(), namespace, name) {
+			lbc.syncQueue.Enqueue(vs)
+		}
+
+		// the VirtualServers that reference the secret as the TLS session ticket key need the new key
+		// or need to stop using it.
+		for _, vs := range findVirtualServersForSessionTicketKeySecret(lbc.getVirtualServers(), namespace, name) {
+			lbc.syncQueue.Enqueue(vs)
+		}
+	}
+
+	// the configs are generated from the current state of the resources once the resources are locked
+	unlock := lbc.lockResources(ings, virtualServers, nil)
+	defer unlock()
+
+	ings = lbc.getLatestIngresses(ings)
+	virtualServers = lbc.getLatestVirtualServers(virtualServers)
+
 	if !secrExists {
 		glog.V(2).Infof("Deleting Secret: %v\n", key)
 
@@ -1375,6 +2078,7 @@ func (lbc *LoadBalancerController) handleSecretUpdate(secret *api_v1.Secret, ing
 		lbc.handleRegularSecretDeletion(secretNsName, ings, virtualServers)
 
 		lbc.recorder.Eventf(secret, api_v1.EventTypeWarning, "Rejected", "%v was rejected: %v", secretNsName, err)
+		lbc.recordValidationFailure(secret, err)
 		return
 	}
 
@@ -1386,9 +2090,14 @@ func (lbc *LoadBalancerController) handleSecretUpdate(secret *api_v1.Secret, ing
 	// we can safely ignore the error because the secret is valid in this function
 	kind, _ := GetSecretKind(secret)
 
-	if kind == JWK {
+	switch kind {
+	case JWK:
 		lbc.configurator.AddOrUpdateJWKSecret(secret)
-	} else {
+	case OIDC:
+		// the client secret is a part of the configuration of the VirtualServers with OIDC, which are resynced with the secret.
+	case SessionTicketKey:
+		// the file of the key is updated when the VirtualServers that reference the secret are resynced with the secret.
+	default:
 		regular, mergeable := lbc.createIngresses(ings)
 
 		virtualServerExes := lbc.virtualServersToVirtualServerExes(virtualServers)
@@ -1413,7 +2122,12 @@ func (lbc *LoadBalancerController) handleSecretUpdate(secret *api_v1.Secret, ing
 func (lbc *LoadBalancerController) handleSpecialSecretUpdate(secret *api_v1.Secret) {
 	var specialSecretsToUpdate []string
 	secretNsName := secret.Namespace + "/" + secret.Name
-	err := ValidateTLSSecret(secret)
+	var err error
+	if secretNsName == lbc.defaultServerSecret {
+		err = ValidateDefaultServerTLSSecret(secret)
+	} else {
+		err = ValidateTLSSecret(secret)
+	}
 	if err != nil {
 		glog.Errorf("Couldn't validate the special Secret %v: %v", secretNsName, err)
 		lbc.recorder.Eventf(secret, api_v1.EventTypeWarning, "Rejected", "the special Secret %v was rejected, using the previous version: %v", secretNsName, err)
@@ -1755,6 +2469,145 @@ func (lbc *LoadBalancerController) getVirtualServersForService(service *api_v1.S
 	return result
 }
 
+// addBackupEndpointsForUpstream adds the endpoints of the backup service of the upstream to the endpoints.
+func (lbc *LoadBalancerController) addBackupEndpointsForUpstream(namespace string, u conf_v1.Upstream, endpoints map[string][]string) {
+	if u.Backup == "" {
+		return
+	}
+
+	endps, _, err := lbc.getEndpointsForUpstream(namespace, u.Backup, u.BackupPort)
+	if err != nil {
+		glog.Warningf("Error getting Endpoints for the backup service of Upstream %v: %v", u.Name, err)
+	}
+
+	endpoints[configs.GenerateEndpointsKey(namespace, u.Backup, nil, u.BackupPort)] = endps
+}
+
+// addServicesEndpointsForUpstream adds the endpoints of the additional services of the upstream to the endpoints.
+func (lbc *LoadBalancerController) addServicesEndpointsForUpstream(namespace string, u conf_v1.Upstream, endpoints map[string][]string) {
+	for _, s := range u.Services {
+		endps, _, err := lbc.getEndpointsForUpstream(namespace, s, u.Port)
+		if err != nil {
+			glog.Warningf("Error getting Endpoints for the service %v of Upstream %v: %v", s, u.Name, err)
+		}
+
+		endpoints[configs.GenerateEndpointsKey(namespace, s, nil, u.Port)] = endps
+	}
+}
+
+// validateUpstreamServices checks that the additional services of the upstreams exist, expose the ports of the upstreams
+// and are not of the type ExternalName, as NGINX can't combine the endpoints of such services with other endpoints.
+func (lbc *LoadBalancerController) validateUpstreamServices(namespace string, upstreams []conf_v1.Upstream) error {
+	for _, u := range upstreams {
+		for _, s := range u.Services {
+			svc, err := lbc.getServiceForUpstream(namespace, s, u.Port)
+			if err != nil {
+				return fmt.Errorf("upstream %v: %v", u.Name, err)
+			}
+
+			if svc.Spec.Type == api_v1.ServiceTypeExternalName {
+				return fmt.Errorf("upstream %v: service %v/%v is of the type ExternalName", u.Name, svc.Namespace, svc.Name)
+			}
+
+			if err := validateServicePortReference(svc, intstr.FromInt(int(u.Port))); err != nil {
+				return fmt.Errorf("upstream %v: %v", u.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateUpstreamServicePorts checks that the services of the upstreams, including the backup services, expose
+// the ports the upstreams reference. The services that don't exist are not checked: the upstreams of such services
+// have no endpoints until the services are created.
+func (lbc *LoadBalancerController) validateUpstreamServicePorts(namespace string, upstreams []conf_v1.Upstream) error {
+	for _, u := range upstreams {
+		if svc, err := lbc.getServiceForUpstream(namespace, u.Service, u.Port); err == nil {
+			if err := validateServicePortReference(svc, intstr.FromInt(int(u.Port))); err != nil {
+				return fmt.Errorf("upstream %v: %v", u.Name, err)
+			}
+		}
+
+		if u.Backup == "" {
+			continue
+		}
+
+		if svc, err := lbc.getServiceForUpstream(namespace, u.Backup, u.BackupPort); err == nil {
+			if err := validateServicePortReference(svc, intstr.FromInt(int(u.BackupPort))); err != nil {
+				return fmt.Errorf("backup of upstream %v: %v", u.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateErrorBackendService checks that the service of the upstream of the error backend of the VirtualServer exists.
+// The upstream references the service, so the VirtualServer is resynced on the changes of the service.
+func (lbc *LoadBalancerController) validateErrorBackendService(vs *conf_v1.VirtualServer) error {
+	if vs.Spec.ErrorBackend == nil {
+		return nil
+	}
+
+	for _, u := range vs.Spec.Upstreams {
+		if u.Name == vs.Spec.ErrorBackend.Upstream {
+			_, err := lbc.getServiceForUpstream(vs.Namespace, u.Service, u.Port)
+			return err
+		}
+	}
+
+	return fmt.Errorf("upstream %v doesn't exist", vs.Spec.ErrorBackend.Upstream)
+}
+
+// validateIngressDefaultBackend checks that the service of the default backend of the Ingress exists and exposes the port.
+// The Ingress references the service, so the Ingress is resynced on the changes of the service.
+func (lbc *LoadBalancerController) validateIngressDefaultBackend(ing *extensions.Ingress) error {
+	if ing.Spec.Backend == nil {
+		return nil
+	}
+
+	svc, err := lbc.getServiceForIngressBackend(ing.Spec.Backend, ing.Namespace)
+	if err != nil {
+		return err
+	}
+
+	return validateServicePortReference(svc, ing.Spec.Backend.ServicePort)
+}
+
+// validateServicePortReference checks that the service exposes the port, referenced by its name or number.
+// ExternalName services don't need to define their ports.
+func validateServicePortReference(svc *api_v1.Service, port intstr.IntOrString) error {
+	if svc.Spec.Type == api_v1.ServiceTypeExternalName {
+		return nil
+	}
+
+	for _, p := range svc.Spec.Ports {
+		if (port.Type == intstr.Int && p.Port == port.IntVal) || (port.Type == intstr.String && p.Name == port.StrVal) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("service %v/%v doesn't expose the port %v", svc.Namespace, svc.Name, port.String())
+}
+
+// isServiceReferencedByUpstreams checks if any of the upstreams references the service as its service,
+// one of its additional services or its backup service.
+func isServiceReferencedByUpstreams(upstreams []conf_v1.Upstream, serviceName string) bool {
+	for _, u := range upstreams {
+		if u.Service == serviceName || u.Backup == serviceName {
+			return true
+		}
+		for _, s := range u.Services {
+			if s == serviceName {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func findVirtualServersForService(virtualServers []*conf_v1.VirtualServer, service *api_v1.Service) []*conf_v1.VirtualServer {
 	var result []*conf_v1.VirtualServer
 
@@ -1762,15 +2615,8 @@ func findVirtualServersForService(virtualServers []*conf_v1.VirtualServer, servi
 		if vs.Namespace != service.Namespace {
 			continue
 		}
-
-		isReferenced := false
-		for _, u := range vs.Spec.Upstreams {
-			if u.Service == service.Name {
-				isReferenced = true
-				break
-			}
-		}
-		if !isReferenced {
+
+		if !isServiceReferencedByUpstreams(vs.Spec.Upstreams, service.Name) {
 			continue
 		}
 
@@ -1842,14 +2688,7 @@ func findVirtualServerRoutesForService(virtualServerRoutes []*conf_v1.VirtualSer
 			continue
 		}
 
-		isReferenced := false
-		for _, u := range vsr.Spec.Upstreams {
-			if u.Service == service.Name {
-				isReferenced = true
-				break
-			}
-		}
-		if !isReferenced {
+		if !isServiceReferencedByUpstreams(vsr.Spec.Upstreams, service.Name) {
 			continue
 		}
 
@@ -1861,11 +2700,13 @@ func findVirtualServerRoutesForService(virtualServerRoutes []*conf_v1.VirtualSer
 
 func (lbc *LoadBalancerController) getVirtualServersForSecret(secretNamespace string, secretName string) []*conf_v1.VirtualServer {
 	virtualServers := lbc.getVirtualServers()
-	return findVirtualServersForSecret(virtualServers, secretNamespace, secretName)
+	result := findVirtualServersForSecret(virtualServers, secretNamespace, secretName)
+	return append(result, findVirtualServersForCertificateSecret(virtualServers, lbc.getCertificates(), secretNamespace, secretName)...)
 }
 
 func findVirtualServersForSecret(virtualServers []*conf_v1.VirtualServer, secretNamespace string, secretName string) []*conf_v1.VirtualServer {
 	var result []*conf_v1.VirtualServer
+	secretKey := secretNamespace + "/" + secretName
 
 	for _, vs := range virtualServers {
 		if vs.Spec.TLS == nil {
@@ -1875,7 +2716,43 @@ func findVirtualServersForSecret(virtualServers []*conf_v1.VirtualServer, secret
 			continue
 		}
 
-		if vs.Namespace == secretNamespace && vs.Spec.TLS.Secret == secretName {
+		if configs.GetSecretKeyForReference(vs.Namespace, vs.Spec.TLS.Secret) == secretKey {
+			result = append(result, vs)
+		}
+	}
+
+	return result
+}
+
+// findVirtualServersForOIDCSecret finds the VirtualServers with OIDC that reference the secret as the client secret.
+func findVirtualServersForOIDCSecret(virtualServers []*conf_v1.VirtualServer, secretNamespace string, secretName string) []*conf_v1.VirtualServer {
+	var result []*conf_v1.VirtualServer
+	secretKey := secretNamespace + "/" + secretName
+
+	for _, vs := range virtualServers {
+		if vs.Spec.OIDC == nil || vs.Spec.OIDC.ClientSecret == "" {
+			continue
+		}
+
+		if configs.GetSecretKeyForReference(vs.Namespace, vs.Spec.OIDC.ClientSecret) == secretKey {
+			result = append(result, vs)
+		}
+	}
+
+	return result
+}
+
+// findVirtualServersForSessionTicketKeySecret finds the VirtualServers that reference the secret as the TLS session ticket key.
+func findVirtualServersForSessionTicketKeySecret(virtualServers []*conf_v1.VirtualServer, secretNamespace string, secretName string) []*conf_v1.VirtualServer {
+	var result []*conf_v1.VirtualServer
+	secretKey := secretNamespace + "/" + secretName
+
+	for _, vs := range virtualServers {
+		if vs.Spec.TLS == nil || vs.Spec.TLS.SessionTicketKey == "" {
+			continue
+		}
+
+		if configs.GetSecretKeyForReference(vs.Namespace, vs.Spec.TLS.SessionTicketKey) == secretKey {
 			result = append(result, vs)
 		}
 	}
@@ -1883,6 +2760,80 @@ func findVirtualServersForSecret(virtualServers []*conf_v1.VirtualServer, secret
 	return result
 }
 
+func (lbc *LoadBalancerController) getPolicies() []*conf_v1alpha1.Policy {
+	var policies []*conf_v1alpha1.Policy
+
+	for _, obj := range lbc.policyLister.List() {
+		policies = append(policies, obj.(*conf_v1alpha1.Policy))
+	}
+
+	return policies
+}
+
+// findPoliciesForSecret finds the JWT policies that reference the secret.
+func findPoliciesForSecret(policies []*conf_v1alpha1.Policy, secretNamespace string, secretName string) []*conf_v1alpha1.Policy {
+	var result []*conf_v1alpha1.Policy
+	secretKey := secretNamespace + "/" + secretName
+
+	for _, pol := range policies {
+		if pol.Spec.JWTAuth != nil && configs.GetSecretKeyForReference(pol.Namespace, pol.Spec.JWTAuth.Secret) == secretKey {
+			result = append(result, pol)
+		}
+	}
+
+	return result
+}
+
+// enqueueIngresses enqueues the Ingresses with the keys, for example, the Ingresses that define the same host and path
+// as a changed Ingress according to the Ingress path index.
+func (lbc *LoadBalancerController) enqueueIngresses(keys []string) {
+	for _, key := range keys {
+		ing, exists, err := lbc.ingressLister.GetByKeySafe(key)
+		if err != nil {
+			glog.Warningf("Error when getting Ingress %v: %v", key, err)
+			continue
+		}
+		if !exists {
+			// the deletion of the Ingress is not processed yet. It will remove the Ingress from the index.
+			continue
+		}
+
+		lbc.syncQueue.Enqueue(ing)
+	}
+}
+
+// enqueueVirtualServersForPolicy enqueues the VirtualServers that reference the policy according to the policy reference index.
+// It returns the number of the enqueued VirtualServers.
+func (lbc *LoadBalancerController) enqueueVirtualServersForPolicy(policyNamespace string, policyName string) int {
+	resyncs := 0
+
+	for _, vsKey := range lbc.policyReferences.getVirtualServerKeys(policyNamespace + "/" + policyName) {
+		obj, exists, err := lbc.virtualServerLister.GetByKey(vsKey)
+		if err != nil {
+			glog.Warningf("Error when getting VirtualServer %v for Policy %v/%v: %v", vsKey, policyNamespace, policyName, err)
+			continue
+		}
+		if !exists {
+			// the deletion of the VirtualServer is not processed yet. It will remove the VirtualServer from the index.
+			continue
+		}
+
+		lbc.syncQueue.Enqueue(obj.(*conf_v1.VirtualServer))
+		resyncs++
+	}
+
+	return resyncs
+}
+
+// validateVirtualServer validates the VirtualServer. If the snippets are disabled, a VirtualServer with snippets is invalid.
+func (lbc *LoadBalancerController) validateVirtualServer(vs *conf_v1.VirtualServer) field.ErrorList {
+	allErrs := validation.ValidateVirtualServer(vs, lbc.isNginxPlus)
+	if !lbc.allowSnippets {
+		allErrs = append(allErrs, validation.RejectSnippetsInVirtualServer(vs)...)
+	}
+	return allErrs
+}
+
 func (lbc *LoadBalancerController) getVirtualServers() []*conf_v1.VirtualServer {
 	var virtualServers []*conf_v1.VirtualServer
 
@@ -1894,16 +2845,16 @@ func (lbc *LoadBalancerController) getVirtualServers() []*conf_v1.VirtualServer
 			continue
 		}
 
-		err := validation.ValidateVirtualServer(vs, lbc.isNginxPlus)
-		if err != nil {
-			glog.V(3).Infof("Skipping invalid VirtualServer %s/%s: %v", vs.Namespace, vs.Name, err)
+		errs := lbc.validateVirtualServer(vs)
+		if len(errs) > 0 {
+			glog.V(3).Infof("Skipping invalid VirtualServer %s/%s: %v", vs.Namespace, vs.Name, errs.ToAggregate())
 			continue
 		}
 
 		virtualServers = append(virtualServers, vs)
 	}
 
-	return virtualServers
+	return filterVirtualServersWithinNamespaceLimit(virtualServers, lbc.maxVirtualServersPerNamespace)
 }
 
 func (lbc *LoadBalancerController) getVirtualServerRoutes() []*conf_v1.VirtualServerRoute {
@@ -1918,6 +2869,9 @@ func (lbc *LoadBalancerController) getVirtualServerRoutes() []*conf_v1.VirtualSe
 		}
 
 		err := validation.ValidateVirtualServerRoute(vsr, lbc.isNginxPlus)
+		if err == nil && !lbc.allowSnippets {
+			err = validation.RejectSnippetsInVirtualServerRoute(vsr)
+		}
 		if err != nil {
 			glog.V(3).Infof("Skipping invalid VirtualServerRoute %s/%s: %v", vsr.Namespace, vsr.Name, err)
 			continue
@@ -1966,14 +2920,8 @@ func findVirtualServersForVirtualServerRouteKey(virtualServers []*conf_v1.Virtua
 	var result []*conf_v1.VirtualServer
 
 	for _, vs := range virtualServers {
-		for _, r := range vs.Spec.Routes {
-			// if route is defined without a namespace, use the namespace of VirtualServer.
-			vsrKey := r.Route
-			if !strings.Contains(r.Route, "/") {
-				vsrKey = fmt.Sprintf("%s/%s", vs.Namespace, r.Route)
-			}
-
-			if vsrKey == key {
+		for _, ref := range getVirtualServerRouteReferences(vs) {
+			if ref.key == key {
 				result = append(result, vs)
 				break
 			}
@@ -1983,6 +2931,62 @@ func findVirtualServersForVirtualServerRouteKey(virtualServers []*conf_v1.Virtua
 	return result
 }
 
+// virtualServerRouteReference is a reference to a VirtualServerRoute in a route of a VirtualServer.
+type virtualServerRouteReference struct {
+	key     string
+	path    string
+	isSplit bool
+}
+
+// getVirtualServerRouteReferences returns the references to VirtualServerRoutes in the routes and the route splits of the VirtualServer.
+func getVirtualServerRouteReferences(vs *conf_v1.VirtualServer) []virtualServerRouteReference {
+	var refs []virtualServerRouteReference
+
+	getKey := func(route string) string {
+		// if route is defined without a namespace, use the namespace of VirtualServer.
+		if !strings.Contains(route, "/") {
+			return fmt.Sprintf("%s/%s", vs.Namespace, route)
+		}
+		return route
+	}
+
+	for _, r := range vs.Spec.Routes {
+		if r.Route != "" {
+			refs = append(refs, virtualServerRouteReference{key: getKey(r.Route), path: r.Path})
+		}
+
+		for _, s := range r.Splits {
+			if s.Route != "" {
+				refs = append(refs, virtualServerRouteReference{key: getKey(s.Route), path: r.Path, isSplit: true})
+			}
+		}
+	}
+
+	return refs
+}
+
+// getSecretKeyForReference returns the key of the secret referenced by a resource in the namespace.
+// A resource can only reference the secrets of its own namespace and the secrets of the secrets namespace.
+// For other secrets, the key is returned along with an error.
+func (lbc *LoadBalancerController) getSecretKeyForReference(namespace string, secretRef string) (string, error) {
+	secretKey := configs.GetSecretKeyForReference(namespace, secretRef)
+
+	secretNamespace, _, err := ParseNamespaceName(secretKey)
+	if err != nil {
+		return secretKey, err
+	}
+
+	if secretNamespace == namespace || secretNamespace == lbc.secretsNamespace {
+		return secretKey, nil
+	}
+
+	if lbc.secretsNamespace == "" {
+		return secretKey, fmt.Errorf("secret %v is in another namespace and the secrets namespace is not configured", secretKey)
+	}
+	return secretKey, fmt.Errorf("secret %v is in another namespace; only the secrets of the namespace %v or the secrets namespace %v can be referenced",
+		secretKey, namespace, lbc.secretsNamespace)
+}
+
 func (lbc *LoadBalancerController) getAndValidateSecret(secretKey string) (*api_v1.Secret, error) {
 	secretObject, secretExists, err := lbc.secretLister.GetByKey(secretKey)
 	if err != nil {
@@ -2000,9 +3004,97 @@ func (lbc *LoadBalancerController) getAndValidateSecret(secretKey string) (*api_
 	return secret, nil
 }
 
+// getAndValidateJWKSecret gets the secret with the key and checks that it is a valid JWK secret.
+func (lbc *LoadBalancerController) getAndValidateJWKSecret(secretKey string) (*api_v1.Secret, error) {
+	secretObject, secretExists, err := lbc.secretLister.GetByKey(secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving secret %v", secretKey)
+	}
+	if !secretExists {
+		return nil, fmt.Errorf("secret %v not found", secretKey)
+	}
+	secret := secretObject.(*api_v1.Secret)
+
+	err = ValidateJWKSecret(secret)
+	if err != nil {
+		return nil, fmt.Errorf("error validating secret %v: %v", secretKey, err)
+	}
+	return secret, nil
+}
+
+// getAndValidateOIDCSecret gets the secret with the key and checks that it is a valid OIDC secret.
+func (lbc *LoadBalancerController) getAndValidateOIDCSecret(secretKey string) (*api_v1.Secret, error) {
+	secretObject, secretExists, err := lbc.secretLister.GetByKey(secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving secret %v", secretKey)
+	}
+	if !secretExists {
+		return nil, fmt.Errorf("secret %v not found", secretKey)
+	}
+	secret := secretObject.(*api_v1.Secret)
+
+	err = ValidateOIDCSecret(secret)
+	if err != nil {
+		return nil, fmt.Errorf("error validating secret %v: %v", secretKey, err)
+	}
+	return secret, nil
+}
+
+// getAndValidateSessionTicketKeySecret gets the secret with the key and checks that it is a valid TLS session ticket key secret.
+func (lbc *LoadBalancerController) getAndValidateSessionTicketKeySecret(secretKey string) (*api_v1.Secret, error) {
+	secretObject, secretExists, err := lbc.secretLister.GetByKey(secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving secret %v", secretKey)
+	}
+	if !secretExists {
+		return nil, fmt.Errorf("secret %v not found", secretKey)
+	}
+	secret := secretObject.(*api_v1.Secret)
+
+	err = ValidateSessionTicketKeySecret(secret)
+	if err != nil {
+		return nil, fmt.Errorf("error validating secret %v: %v", secretKey, err)
+	}
+	return secret, nil
+}
+
+// snippetsAnnotations includes the annotations of Ingress resources that insert NGINX configuration as is.
+var snippetsAnnotations = []string{"nginx.org/server-snippets", "nginx.org/location-snippets"}
+
+// rejectIngressSnippets returns an error if the Ingress includes a snippets annotation.
+func rejectIngressSnippets(ing *extensions.Ingress) error {
+	for _, a := range snippetsAnnotations {
+		if _, exists := ing.Annotations[a]; exists {
+			return fmt.Errorf("the annotation %v is not allowed: snippets are disabled", a)
+		}
+	}
+	return nil
+}
+
 func (lbc *LoadBalancerController) createIngress(ing *extensions.Ingress) (*configs.IngressEx, error) {
+	if isIngressPaused(ing) {
+		// a paused Ingress keeps the spec of its last applied configuration, so that the updates of its endpoints,
+		// secrets and ConfigMaps don't apply the changes of the spec made while the Ingress is paused
+		if applied := lbc.configurator.GetIngress(ing.Namespace + "/" + ing.Name); applied != nil {
+			ing = applied
+		}
+	}
+
+	if !lbc.allowSnippets {
+		if err := rejectIngressSnippets(ing); err != nil {
+			return nil, err
+		}
+	}
+
+	if !isMaster(ing) && !isMinion(ing) {
+		if conflicts := lbc.ingressPaths.getConflicts(ing.Namespace + "/" + ing.Name); len(conflicts) > 0 {
+			ing = removeConflictingPaths(ing, conflicts)
+		}
+	}
+
 	ingEx := &configs.IngressEx{
-		Ingress: ing,
+		Ingress:            ing,
+		NamespaceCfgParams: lbc.getNamespaceConfigParams(ing.Namespace),
 	}
 
 	ingEx.TLSSecrets = make(map[string]*api_v1.Secret)
@@ -2112,7 +3204,7 @@ func (lbc *LoadBalancerController) createIngress(ing *extensions.Ingress) (*conf
 		validRules++
 	}
 
-	if validRules == 0 {
+	if validRules == 0 && ing.Spec.Backend == nil {
 		return nil, fmt.Errorf("Ingress contains no valid rules")
 	}
 
@@ -2145,11 +3237,18 @@ func (lbc *LoadBalancerController) createVirtualServer(virtualServer *conf_v1.Vi
 		VirtualServer: virtualServer,
 	}
 
-	if virtualServer.Spec.TLS != nil && virtualServer.Spec.TLS.Secret != "" {
-		secretKey := virtualServer.Namespace + "/" + virtualServer.Spec.TLS.Secret
-		secret, err := lbc.getAndValidateSecret(secretKey)
+	if virtualServer.Spec.TLS != nil && (virtualServer.Spec.TLS.Secret != "" || virtualServer.Spec.TLS.Certificate != "") {
+		var secretKey string
+		var secret *api_v1.Secret
+		secretRef, err := lbc.getTLSSecretReference(virtualServer)
+		if err == nil {
+			secretKey, err = lbc.getSecretKeyForReference(virtualServer.Namespace, secretRef)
+		}
+		if err == nil {
+			secret, err = lbc.getAndValidateSecret(secretKey)
+		}
 		if err != nil {
-			glog.Warningf("Error trying to get the secret %v for VirtualServer %v: %v", secretKey, virtualServer.Name, err)
+			glog.Warningf("Error trying to get the TLS secret for VirtualServer %v: %v", virtualServer.Name, err)
 		} else {
 			virtualServerEx.TLSSecret = secret
 		}
@@ -2180,23 +3279,17 @@ func (lbc *LoadBalancerController) createVirtualServer(virtualServer *conf_v1.Vi
 		}
 
 		endpoints[endpointsKey] = endps
+
+		lbc.addServicesEndpointsForUpstream(virtualServer.Namespace, u, endpoints)
+		lbc.addBackupEndpointsForUpstream(virtualServer.Namespace, u, endpoints)
 	}
 
 	var virtualServerRoutes []*conf_v1.VirtualServerRoute
 	var virtualServerRouteErrors []virtualServerRouteError
 
 	// gather all referenced VirtualServerRoutes
-	for _, r := range virtualServer.Spec.Routes {
-		if r.Route == "" {
-			continue
-		}
-
-		vsrKey := r.Route
-
-		// if route is defined without a namespace, use the namespace of VirtualServer.
-		if !strings.Contains(r.Route, "/") {
-			vsrKey = fmt.Sprintf("%s/%s", virtualServer.Namespace, r.Route)
-		}
+	for _, ref := range getVirtualServerRouteReferences(virtualServer) {
+		vsrKey := ref.key
 
 		obj, exists, err := lbc.virtualServerRouteLister.GetByKey(vsrKey)
 		if err != nil {
@@ -2219,7 +3312,20 @@ func (lbc *LoadBalancerController) createVirtualServer(virtualServer *conf_v1.Vi
 			continue
 		}
 
-		err = validation.ValidateVirtualServerRouteForVirtualServer(vsr, virtualServer.Spec.Host, r.Path, lbc.isNginxPlus)
+		if ref.isSplit {
+			err = validation.ValidateVirtualServerRouteForRouteSplit(vsr, virtualServer.Spec.Host, ref.path, lbc.isNginxPlus)
+		} else {
+			err = validation.ValidateVirtualServerRouteForVirtualServer(vsr, virtualServer.Spec.Host, ref.path, lbc.isNginxPlus)
+		}
+		if err == nil && !lbc.allowSnippets {
+			err = validation.RejectSnippetsInVirtualServerRoute(vsr)
+		}
+		if err == nil {
+			err = lbc.validateUpstreamServicePorts(vsr.Namespace, vsr.Spec.Upstreams)
+		}
+		if err == nil {
+			err = lbc.validateUpstreamServices(vsr.Namespace, vsr.Spec.Upstreams)
+		}
 		if err != nil {
 			glog.Warningf("VirtualServer %s/%s references invalid VirtualServerRoute %s: %v", virtualServer.Name, virtualServer.Namespace, vsrKey, err)
 			virtualServerRouteErrors = append(virtualServerRouteErrors, newVirtualServerRouteErrorFromVSR(vsr, err))
@@ -2247,16 +3353,108 @@ func (lbc *LoadBalancerController) createVirtualServer(virtualServer *conf_v1.Vi
 				glog.Warningf("Error getting Endpoints for Upstream %v: %v", u.Name, err)
 			}
 			endpoints[endpointsKey] = endps
+
+			lbc.addServicesEndpointsForUpstream(vsr.Namespace, u, endpoints)
+			lbc.addBackupEndpointsForUpstream(vsr.Namespace, u, endpoints)
 		}
 	}
 
+	policies := make(map[string]*conf_v1alpha1.Policy)
+	jwtKeys := make(map[string]*api_v1.Secret)
+
+	lbc.getPoliciesForRoutes(virtualServer.Spec.Routes, virtualServer.Namespace, policies, jwtKeys)
+	for _, vsr := range virtualServerRoutes {
+		lbc.getPoliciesForRoutes(vsr.Spec.Subroutes, vsr.Namespace, policies, jwtKeys)
+	}
+
 	virtualServerEx.Endpoints = endpoints
 	virtualServerEx.VirtualServerRoutes = virtualServerRoutes
 	virtualServerEx.ExternalNameSvcs = externalNameSvcs
+	virtualServerEx.Policies = policies
+	virtualServerEx.JWTKeys = jwtKeys
+
+	if oidc := virtualServer.Spec.OIDC; oidc != nil && lbc.isNginxPlus {
+		secretKey, err := lbc.getSecretKeyForReference(virtualServer.Namespace, oidc.ClientSecret)
+		var secret *api_v1.Secret
+		if err == nil {
+			secret, err = lbc.getAndValidateOIDCSecret(secretKey)
+		}
+		if err != nil {
+			glog.Warningf("Error trying to get the OIDC secret %v for VirtualServer %v/%v: %v", secretKey, virtualServer.Namespace, virtualServer.Name, err)
+		} else {
+			virtualServerEx.OIDCSecret = secret
+		}
+	}
+
+	if tls := virtualServer.Spec.TLS; tls != nil && tls.SessionTicketKey != "" {
+		secretKey, err := lbc.getSecretKeyForReference(virtualServer.Namespace, tls.SessionTicketKey)
+		var secret *api_v1.Secret
+		if err == nil {
+			secret, err = lbc.getAndValidateSessionTicketKeySecret(secretKey)
+		}
+		if err != nil {
+			glog.Warningf("Error trying to get the TLS session ticket key secret %v for VirtualServer %v/%v: %v", secretKey, virtualServer.Namespace, virtualServer.Name, err)
+		} else {
+			virtualServerEx.SessionTicketKeySecret = secret
+		}
+	}
 
 	return &virtualServerEx, virtualServerRouteErrors
 }
 
+// getPoliciesForRoutes gets the valid policies referenced by the routes and the secrets of the JWT policies among them.
+// Missing or invalid policies are skipped, so that the configuration generation can report them.
+func (lbc *LoadBalancerController) getPoliciesForRoutes(routes []conf_v1.Route, ownerNamespace string,
+	policies map[string]*conf_v1alpha1.Policy, jwtKeys map[string]*api_v1.Secret) {
+	for _, r := range routes {
+		for _, p := range r.Policies {
+			namespace := p.Namespace
+			if namespace == "" {
+				namespace = ownerNamespace
+			}
+			policyKey := namespace + "/" + p.Name
+
+			if _, exists := policies[policyKey]; exists {
+				continue
+			}
+
+			obj, exists, err := lbc.policyLister.GetByKey(policyKey)
+			if err != nil {
+				glog.Warningf("Failed to get Policy %s: %v", policyKey, err)
+				continue
+			}
+			if !exists {
+				glog.Warningf("Policy %s doesn't exist", policyKey)
+				continue
+			}
+
+			pol := obj.(*conf_v1alpha1.Policy)
+
+			err = validation.ValidatePolicy(pol, lbc.isNginxPlus)
+			if err != nil {
+				glog.Warningf("Policy %s is invalid: %v", policyKey, err)
+				continue
+			}
+
+			policies[policyKey] = pol
+
+			if pol.Spec.JWTAuth != nil {
+				secretKey, err := lbc.getSecretKeyForReference(pol.Namespace, pol.Spec.JWTAuth.Secret)
+				var secret *api_v1.Secret
+				if err == nil {
+					secret, err = lbc.getAndValidateJWKSecret(secretKey)
+				}
+				if err != nil {
+					glog.Warningf("Error trying to get the secret %v for Policy %v: %v", secretKey, policyKey, err)
+					continue
+				}
+
+				jwtKeys[secretKey] = secret
+			}
+		}
+	}
+}
+
 func (lbc *LoadBalancerController) createTransportServer(transportServer *conf_v1alpha1.TransportServer) *configs.TransportServerEx {
 	endpoints := make(map[string][]string)
 
@@ -2570,21 +3768,37 @@ func (lbc *LoadBalancerController) isHealthCheckEnabled(ing *extensions.Ingress)
 	return false
 }
 
-// ValidateSecret validates that the secret follows the TLS Secret format.
-// For NGINX Plus, it also checks if the secret follows the JWK Secret format.
+// isIngressPaused checks if the changes of the Ingress must be ignored until it is unpaused.
+func isIngressPaused(ing *extensions.Ingress) bool {
+	if paused, exists, err := configs.GetMapKeyAsBool(ing.Annotations, pausedKey, ing); exists {
+		if err != nil {
+			glog.Error(err)
+		}
+		return paused
+	}
+	return false
+}
+
+// ValidateSecret validates that the secret follows the TLS or the TLS session ticket key Secret format.
+// For NGINX Plus, it also checks if the secret follows the JWK or the OIDC Secret format.
 func (lbc *LoadBalancerController) ValidateSecret(secret *api_v1.Secret) error {
 	err1 := ValidateTLSSecret(secret)
+	err2 := ValidateSessionTicketKeySecret(secret)
 	if !lbc.isNginxPlus {
-		return err1
+		if err1 == nil || err2 == nil {
+			return nil
+		}
+		return fmt.Errorf("Secret is not a TLS or TLS session ticket key secret")
 	}
 
-	err2 := ValidateJWKSecret(secret)
+	err3 := ValidateJWKSecret(secret)
+	err4 := ValidateOIDCSecret(secret)
 
-	if err1 == nil || err2 == nil {
+	if err1 == nil || err2 == nil || err3 == nil || err4 == nil {
 		return nil
 	}
 
-	return fmt.Errorf("Secret is not a TLS or JWK secret")
+	return fmt.Errorf("Secret is not a TLS, TLS session ticket key, JWK or OIDC secret")
 }
 
 // getMinionsForHost returns a list of all minion ingress resources for a given master
@@ -2649,6 +3863,28 @@ func (lbc *LoadBalancerController) getMinionsForMaster(master *configs.IngressEx
 	return minions, nil
 }
 
+// getAppliedMinionsForMaster returns the minions of the last applied configuration of a paused master,
+// sorted by creation time. The minions were validated when the configuration was applied.
+func (lbc *LoadBalancerController) getAppliedMinionsForMaster(master *extensions.Ingress) []*configs.IngressEx {
+	applied := lbc.configurator.GetMinions(master.Namespace + "/" + master.Name)
+
+	sort.Slice(applied, func(i, j int) bool {
+		return applied[i].CreationTimestamp.Time.UnixNano() < applied[j].CreationTimestamp.Time.UnixNano()
+	})
+
+	var minions []*configs.IngressEx
+	for _, ing := range applied {
+		ingEx, err := lbc.createIngress(ing)
+		if err != nil {
+			glog.Errorf("Error creating ingress resource %v/%v: %v", ing.Namespace, ing.Name, err)
+			continue
+		}
+		minions = append(minions, ingEx)
+	}
+
+	return minions
+}
+
 // FindMasterForMinion returns a master for a given minion
 func (lbc *LoadBalancerController) FindMasterForMinion(minion *extensions.Ingress) (*extensions.Ingress, error) {
 	ings, err := lbc.ingressLister.List()
@@ -2706,7 +3942,12 @@ func (lbc *LoadBalancerController) createMergableIngresses(master *extensions.In
 	}
 	mergeableIngresses.Master = masterIngEx
 
-	minions, err := lbc.getMinionsForMaster(masterIngEx)
+	var minions []*configs.IngressEx
+	if isIngressPaused(master) && lbc.configurator.HasIngress(master) {
+		minions = lbc.getAppliedMinionsForMaster(master)
+	} else {
+		minions, err = lbc.getMinionsForMaster(masterIngEx)
+	}
 	if err != nil {
 		err = fmt.Errorf("Error Obtaining Ingress Resources: %v", err)
 		return &mergeableIngresses, err
@@ -2729,3 +3970,47 @@ func (lbc *LoadBalancerController) syncSVIDRotation(svidResponse *workload.X509S
 		glog.Errorf("failed to rotate SPIFFE certificates: %v", err)
 	}
 }
+
+// runSelfSignedDefaultServerSecretRotation periodically regenerates the self-signed certificate of the default server.
+func (lbc *LoadBalancerController) runSelfSignedDefaultServerSecretRotation(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			lbc.syncSelfSignedDefaultServerSecretRotation()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// runSelfSignedCertificateRenewal periodically renews the self-signed certificates of the VirtualServers with missing TLS Secrets
+// before they expire.
+func (lbc *LoadBalancerController) runSelfSignedCertificateRenewal(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			glog.V(3).Info("Renewing the self-signed certificates of the VirtualServers with missing TLS secrets")
+			if err := lbc.configurator.RenewSelfSignedCertificates(); err != nil {
+				glog.Errorf("failed to renew the self-signed certificates of the VirtualServers: %v", err)
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func (lbc *LoadBalancerController) syncSelfSignedDefaultServerSecretRotation() {
+	lbc.syncLock.Lock()
+	defer lbc.syncLock.Unlock()
+	glog.V(3).Info("Rotating the self-signed certificate of the default server")
+	err := lbc.configurator.RotateSelfSignedDefaultServerSecret()
+	if err != nil {
+		glog.Errorf("failed to rotate the self-signed certificate of the default server: %v", err)
+	}
+}
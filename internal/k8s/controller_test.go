@@ -3,6 +3,7 @@ package k8s
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 	"unsafe"
@@ -14,6 +15,7 @@ import (
 	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
 	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
 	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/validation"
 	v1 "k8s.io/api/core/v1"
 	extensions "k8s.io/api/extensions/v1beta1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 )
 
 func TestHasCorrectIngressClass(t *testing.T) {
@@ -361,6 +364,108 @@ func TestCreateMergableIngressesInvalidMaster(t *testing.T) {
 	}
 }
 
+func TestRejectIngressSnippets(t *testing.T) {
+	tests := []struct {
+		annotations map[string]string
+		expectedErr bool
+		msg         string
+	}{
+		{
+			annotations: map[string]string{"nginx.org/proxy-connect-timeout": "10s"},
+			expectedErr: false,
+			msg:         "no snippets",
+		},
+		{
+			annotations: map[string]string{"nginx.org/server-snippets": "deny all;"},
+			expectedErr: true,
+			msg:         "server snippets",
+		},
+		{
+			annotations: map[string]string{"nginx.org/location-snippets": "deny all;"},
+			expectedErr: true,
+			msg:         "location snippets",
+		},
+	}
+
+	for _, test := range tests {
+		ing := &extensions.Ingress{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:        "cafe-ingress",
+				Namespace:   "default",
+				Annotations: test.annotations,
+			},
+		}
+
+		err := rejectIngressSnippets(ing)
+		if (err != nil) != test.expectedErr {
+			t.Errorf("rejectIngressSnippets() returned error %v for the case of %s", err, test.msg)
+		}
+	}
+}
+
+func TestCreateIngressWithDisabledSnippets(t *testing.T) {
+	ing := &extensions.Ingress{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe-ingress",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"nginx.org/location-snippets": "deny all;",
+			},
+		},
+	}
+
+	lbc := LoadBalancerController{
+		allowSnippets: false,
+	}
+
+	expected := fmt.Errorf("the annotation nginx.org/location-snippets is not allowed: snippets are disabled")
+	_, err := lbc.createIngress(ing)
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("createIngress() returned error %v but expected %v", err, expected)
+	}
+}
+
+func TestGetVirtualServersWithDisabledSnippets(t *testing.T) {
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Host: "cafe.example.com",
+		},
+	}
+	vsWithSnippets := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "tea",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Host:           "tea.example.com",
+			ServerSnippets: "deny all;",
+		},
+	}
+
+	virtualServerLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for _, v := range []*conf_v1.VirtualServer{vs, vsWithSnippets} {
+		err := virtualServerLister.Add(v)
+		if err != nil {
+			t.Fatalf("Failed to add the VirtualServer to the store: %v", err)
+		}
+	}
+
+	lbc := LoadBalancerController{
+		virtualServerLister: virtualServerLister,
+		allowSnippets:       false,
+	}
+
+	expected := []*conf_v1.VirtualServer{vs}
+	result := lbc.getVirtualServers()
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("getVirtualServers() returned %v but expected %v", result, expected)
+	}
+}
+
 func TestFindMasterForMinion(t *testing.T) {
 	cafeMaster, coffeeMinion, teaMinion, lbc := getMergableDefaults()
 
@@ -1414,7 +1519,21 @@ func TestFindVirtualServersForService(t *testing.T) {
 			},
 		},
 	}
-	virtualServers := []*conf_v1.VirtualServer{&vs1, &vs2, &vs3}
+	vs4 := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "vs-4",
+			Namespace: "ns-1",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Upstreams: []conf_v1.Upstream{
+				{
+					Service:  "some-service",
+					Services: []string{"test-service"},
+				},
+			},
+		},
+	}
+	virtualServers := []*conf_v1.VirtualServer{&vs1, &vs2, &vs3, &vs4}
 
 	service := v1.Service{
 		ObjectMeta: meta_v1.ObjectMeta{
@@ -1423,7 +1542,7 @@ func TestFindVirtualServersForService(t *testing.T) {
 		},
 	}
 
-	expected := []*conf_v1.VirtualServer{&vs1}
+	expected := []*conf_v1.VirtualServer{&vs1, &vs4}
 
 	result := findVirtualServersForService(virtualServers, &service)
 	if !reflect.DeepEqual(result, expected) {
@@ -1471,7 +1590,21 @@ func TestFindVirtualServerRoutesForService(t *testing.T) {
 			},
 		},
 	}
-	virtualServerRoutes := []*conf_v1.VirtualServerRoute{&vsr1, &vsr2, &vsr3}
+	vsr4 := conf_v1.VirtualServerRoute{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "vsr-4",
+			Namespace: "ns-1",
+		},
+		Spec: conf_v1.VirtualServerRouteSpec{
+			Upstreams: []conf_v1.Upstream{
+				{
+					Service:  "some-service",
+					Services: []string{"test-service"},
+				},
+			},
+		},
+	}
+	virtualServerRoutes := []*conf_v1.VirtualServerRoute{&vsr1, &vsr2, &vsr3, &vsr4}
 
 	service := v1.Service{
 		ObjectMeta: meta_v1.ObjectMeta{
@@ -1480,7 +1613,7 @@ func TestFindVirtualServerRoutesForService(t *testing.T) {
 		},
 	}
 
-	expected := []*conf_v1.VirtualServerRoute{&vsr1}
+	expected := []*conf_v1.VirtualServerRoute{&vsr1, &vsr4}
 
 	result := findVirtualServerRoutesForService(virtualServerRoutes, &service)
 	if !reflect.DeepEqual(result, expected) {
@@ -1594,6 +1727,139 @@ func TestFindTransportServersForService(t *testing.T) {
 	}
 }
 
+func TestFindVirtualServersForOIDCSecret(t *testing.T) {
+	createVirtualServer := func(name string, namespace string, oidc *conf_v1.OIDC) *conf_v1.VirtualServer {
+		return &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				OIDC: oidc,
+			},
+		}
+	}
+
+	vs1 := createVirtualServer("vs-1", "ns-1", nil)
+	vs2 := createVirtualServer("vs-2", "ns-1", &conf_v1.OIDC{ClientSecret: "oidc-secret"})
+	vs3 := createVirtualServer("vs-3", "ns-1", &conf_v1.OIDC{ClientSecret: "other-secret"})
+	vs4 := createVirtualServer("vs-4", "ns-2", &conf_v1.OIDC{ClientSecret: "oidc-secret"})
+	vs5 := createVirtualServer("vs-5", "ns-2", &conf_v1.OIDC{ClientSecret: "ns-1/oidc-secret"})
+
+	virtualServers := []*conf_v1.VirtualServer{vs1, vs2, vs3, vs4, vs5}
+	expected := []*conf_v1.VirtualServer{vs2, vs5}
+
+	result := findVirtualServersForOIDCSecret(virtualServers, "ns-1", "oidc-secret")
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("findVirtualServersForOIDCSecret returned %v but expected %v", result, expected)
+	}
+}
+
+func TestFindVirtualServersForSessionTicketKeySecret(t *testing.T) {
+	createVirtualServer := func(name string, namespace string, tls *conf_v1.TLS) *conf_v1.VirtualServer {
+		return &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				TLS: tls,
+			},
+		}
+	}
+
+	vs1 := createVirtualServer("vs-1", "ns-1", nil)
+	vs2 := createVirtualServer("vs-2", "ns-1", &conf_v1.TLS{Secret: "tls-secret", SessionTicketKey: "ticket-key"})
+	vs3 := createVirtualServer("vs-3", "ns-1", &conf_v1.TLS{Secret: "ticket-key"})
+	vs4 := createVirtualServer("vs-4", "ns-2", &conf_v1.TLS{Secret: "tls-secret", SessionTicketKey: "ticket-key"})
+	vs5 := createVirtualServer("vs-5", "ns-2", &conf_v1.TLS{Secret: "tls-secret", SessionTicketKey: "ns-1/ticket-key"})
+
+	virtualServers := []*conf_v1.VirtualServer{vs1, vs2, vs3, vs4, vs5}
+	expected := []*conf_v1.VirtualServer{vs2, vs5}
+
+	result := findVirtualServersForSessionTicketKeySecret(virtualServers, "ns-1", "ticket-key")
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("findVirtualServersForSessionTicketKeySecret returned %v but expected %v", result, expected)
+	}
+}
+
+func TestValidateSessionTicketKeySecret(t *testing.T) {
+	tests := []struct {
+		data     map[string][]byte
+		expected bool
+		msg      string
+	}{
+		{
+			data:     map[string][]byte{"ticket-key": make([]byte, 48)},
+			expected: true,
+			msg:      "valid 48-byte key",
+		},
+		{
+			data:     map[string][]byte{"ticket-key": make([]byte, 80)},
+			expected: true,
+			msg:      "valid 80-byte key",
+		},
+		{
+			data:     map[string][]byte{"jwk": make([]byte, 48)},
+			expected: false,
+			msg:      "missing key",
+		},
+		{
+			data:     map[string][]byte{"ticket-key": make([]byte, 32)},
+			expected: false,
+			msg:      "key of an invalid size",
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateSessionTicketKeySecret(&v1.Secret{Data: test.data})
+		if (err == nil) != test.expected {
+			t.Errorf("ValidateSessionTicketKeySecret() returned %v for the case of %s", err, test.msg)
+		}
+	}
+}
+
+func TestValidateOIDCSecret(t *testing.T) {
+	tests := []struct {
+		data     map[string][]byte
+		expected bool
+		msg      string
+	}{
+		{
+			data:     map[string][]byte{"client-secret": []byte("a-valid_secret.123")},
+			expected: true,
+			msg:      "valid secret",
+		},
+		{
+			data:     map[string][]byte{"jwk": []byte("key")},
+			expected: false,
+			msg:      "missing client secret",
+		},
+		{
+			data:     map[string][]byte{"client-secret": []byte("")},
+			expected: false,
+			msg:      "empty client secret",
+		},
+		{
+			data:     map[string][]byte{"client-secret": []byte("secret$1")},
+			expected: false,
+			msg:      "client secret with $",
+		},
+		{
+			data:     map[string][]byte{"client-secret": []byte("secret\n")},
+			expected: false,
+			msg:      "client secret with a new line",
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateOIDCSecret(&v1.Secret{Data: test.data})
+		if (err == nil) != test.expected {
+			t.Errorf("ValidateOIDCSecret() returned %v for the case of %s", err, test.msg)
+		}
+	}
+}
+
 func TestFindVirtualServersForSecret(t *testing.T) {
 	vs1 := conf_v1.VirtualServer{
 		ObjectMeta: meta_v1.ObjectMeta{
@@ -1649,9 +1915,21 @@ func TestFindVirtualServersForSecret(t *testing.T) {
 		},
 	}
 
-	virtualServers := []*conf_v1.VirtualServer{&vs1, &vs2, &vs3, &vs4, &vs5}
+	vs6 := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "vs-6",
+			Namespace: "ns-2",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			TLS: &conf_v1.TLS{
+				Secret: "ns-1/test-secret",
+			},
+		},
+	}
+
+	virtualServers := []*conf_v1.VirtualServer{&vs1, &vs2, &vs3, &vs4, &vs5, &vs6}
 
-	expected := []*conf_v1.VirtualServer{&vs4}
+	expected := []*conf_v1.VirtualServer{&vs4, &vs6}
 
 	result := findVirtualServersForSecret(virtualServers, "ns-1", "test-secret")
 	if !reflect.DeepEqual(result, expected) {
@@ -1720,6 +1998,122 @@ func TestFindVirtualServersForVirtualServerRoute(t *testing.T) {
 	}
 }
 
+func TestFindVirtualServersForVirtualServerRouteWithRouteSplits(t *testing.T) {
+	vs := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Routes: []conf_v1.Route{
+				{
+					Path: "/coffee",
+					Splits: []conf_v1.Split{
+						{
+							Weight: 90,
+							Route:  "coffee-v1",
+						},
+						{
+							Weight: 10,
+							Route:  "some-ns/coffee-v2",
+						},
+					},
+				},
+			},
+		},
+	}
+	virtualServers := []*conf_v1.VirtualServer{&vs}
+
+	// a change of either VirtualServerRoute of the splits must resync the VirtualServer
+	for _, vsr := range []conf_v1.VirtualServerRoute{
+		{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "coffee-v1",
+				Namespace: "default",
+			},
+		},
+		{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "coffee-v2",
+				Namespace: "some-ns",
+			},
+		},
+	} {
+		result := findVirtualServersForVirtualServerRoute(virtualServers, &vsr)
+		if !reflect.DeepEqual(result, virtualServers) {
+			t.Errorf("findVirtualServersForVirtualServerRoute returned %v for %s/%s but expected %v", result, vsr.Namespace, vsr.Name, virtualServers)
+		}
+	}
+
+	other := conf_v1.VirtualServerRoute{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "coffee-v2",
+			Namespace: "default",
+		},
+	}
+	if result := findVirtualServersForVirtualServerRoute(virtualServers, &other); len(result) != 0 {
+		t.Errorf("findVirtualServersForVirtualServerRoute returned %v for an unreferenced VirtualServerRoute but expected none", result)
+	}
+}
+
+func TestGetVirtualServerRouteReferences(t *testing.T) {
+	vs := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Routes: []conf_v1.Route{
+				{
+					Path:  "/tea",
+					Route: "tea",
+				},
+				{
+					Path: "/coffee",
+					Splits: []conf_v1.Split{
+						{
+							Weight: 90,
+							Route:  "coffee-v1",
+						},
+						{
+							Weight: 10,
+							Route:  "some-ns/coffee-v2",
+						},
+					},
+				},
+				{
+					Path: "/juice",
+					Action: &conf_v1.Action{
+						Pass: "juice",
+					},
+				},
+			},
+		},
+	}
+
+	expected := []virtualServerRouteReference{
+		{
+			key:  "default/tea",
+			path: "/tea",
+		},
+		{
+			key:     "default/coffee-v1",
+			path:    "/coffee",
+			isSplit: true,
+		},
+		{
+			key:     "some-ns/coffee-v2",
+			path:    "/coffee",
+			isSplit: true,
+		},
+	}
+
+	result := getVirtualServerRouteReferences(&vs)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("getVirtualServerRouteReferences() returned %+v but expected %+v", result, expected)
+	}
+}
+
 func TestFormatWarningsMessages(t *testing.T) {
 	warnings := []string{"Test warning", "Test warning 2"}
 
@@ -1844,3 +2238,1334 @@ func TestGetStatusFromEventTitle(t *testing.T) {
 		}
 	}
 }
+
+func TestIsNamespaceConfigMapKey(t *testing.T) {
+	tests := []struct {
+		namespaceConfigMapName string
+		key                    string
+		expected               bool
+		msg                    string
+	}{
+		{
+			namespaceConfigMapName: "",
+			key:                    "default/nginx-config-overrides",
+			expected:               false,
+			msg:                    "per-namespace ConfigMaps are disabled",
+		},
+		{
+			namespaceConfigMapName: "nginx-config-overrides",
+			key:                    "default/nginx-config-overrides",
+			expected:               true,
+			msg:                    "per-namespace ConfigMap",
+		},
+		{
+			namespaceConfigMapName: "nginx-config-overrides",
+			key:                    "default/other-config",
+			expected:               false,
+			msg:                    "other ConfigMap",
+		},
+		{
+			namespaceConfigMapName: "nginx-config",
+			key:                    "nginx-ingress/nginx-config",
+			expected:               false,
+			msg:                    "ConfigMap of the Ingress Controller",
+		},
+	}
+
+	for _, test := range tests {
+		lbc := LoadBalancerController{
+			namespaceConfigMapName: test.namespaceConfigMapName,
+			nginxConfigMapsKey:     "nginx-ingress/nginx-config",
+		}
+
+		result := lbc.isNamespaceConfigMapKey(test.key)
+		if result != test.expected {
+			t.Errorf("isNamespaceConfigMapKey(%q) returned %v but expected %v for the case of %s", test.key, result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGetNamespaceConfigParams(t *testing.T) {
+	lbc := LoadBalancerController{
+		namespaceConfigMapName: "nginx-config-overrides",
+		namespaceConfigMapLister: storeToConfigMapLister{
+			Store: cache.NewStore(cache.MetaNamespaceKeyFunc),
+		},
+	}
+
+	err := lbc.namespaceConfigMapLister.Add(&v1.ConfigMap{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "nginx-config-overrides",
+			Namespace: "cafe",
+		},
+		Data: map[string]string{
+			"proxy-read-timeout": "120s",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add the ConfigMap to the store: %v", err)
+	}
+
+	expected := &configs.NamespaceConfigParams{ProxyReadTimeout: "120s"}
+	result := lbc.getNamespaceConfigParams("cafe")
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("getNamespaceConfigParams(\"cafe\") returned %+v but expected %+v", result, expected)
+	}
+
+	result = lbc.getNamespaceConfigParams("tea")
+	if result != nil {
+		t.Errorf("getNamespaceConfigParams(\"tea\") returned %+v but expected nil", result)
+	}
+}
+
+func TestIsMergeableIngressInNamespace(t *testing.T) {
+	mergeableIng := &configs.MergeableIngresses{
+		Master: &configs.IngressEx{
+			Ingress: &extensions.Ingress{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "cafe-ingress-master",
+					Namespace: "default",
+				},
+			},
+		},
+		Minions: []*configs.IngressEx{
+			{
+				Ingress: &extensions.Ingress{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "cafe-ingress-tea-minion",
+						Namespace: "tea",
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		namespace string
+		expected  bool
+	}{
+		{
+			namespace: "default",
+			expected:  true,
+		},
+		{
+			namespace: "tea",
+			expected:  true,
+		},
+		{
+			namespace: "coffee",
+			expected:  false,
+		},
+	}
+
+	for _, test := range tests {
+		result := isMergeableIngressInNamespace(mergeableIng, test.namespace)
+		if result != test.expected {
+			t.Errorf("isMergeableIngressInNamespace() returned %v but expected %v for the namespace %q", result, test.expected, test.namespace)
+		}
+	}
+}
+
+func TestCheckExternalServiceAddressWithoutAddress(t *testing.T) {
+	svcLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	err := svcLister.Add(&v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "nginx-ingress",
+			Namespace: "nginx-ingress",
+		},
+		Spec: v1.ServiceSpec{
+			Type: v1.ServiceTypeLoadBalancer,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add the Service to the store: %v", err)
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	lbc := LoadBalancerController{
+		svcLister: svcLister,
+		recorder:  recorder,
+		statusUpdater: &statusUpdater{
+			namespace:           "nginx-ingress",
+			externalServiceName: "nginx-ingress",
+		},
+		externalServiceAddressChecker: &externalServiceAddressChecker{timeout: externalServiceAddressTimeout},
+	}
+
+	start := time.Now()
+	for elapsed := time.Duration(0); elapsed <= 2*externalServiceAddressTimeout; elapsed += externalServiceAddressCheckPeriod {
+		lbc.checkExternalServiceAddress(start.Add(elapsed))
+	}
+
+	if len(recorder.Events) != 1 {
+		t.Fatalf("checkExternalServiceAddress() recorded %d events but expected 1", len(recorder.Events))
+	}
+
+	event := <-recorder.Events
+	if !strings.HasPrefix(event, "Warning NoExternalAddress") {
+		t.Errorf("checkExternalServiceAddress() recorded the event %q but expected a NoExternalAddress warning", event)
+	}
+}
+
+func TestHandleSpecialSecretUpdateRotatesDefaultServerSecret(t *testing.T) {
+	newSecret := func(secretType v1.SecretType, cert string) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "default-server-secret",
+				Namespace: "nginx-ingress",
+			},
+			Type: secretType,
+			Data: map[string][]byte{
+				v1.TLSCertKey:       []byte(cert),
+				v1.TLSPrivateKeyKey: []byte("key"),
+			},
+		}
+	}
+
+	tests := []struct {
+		secret        *v1.Secret
+		expectedEvent string
+		msg           string
+	}{
+		{
+			secret:        newSecret(v1.SecretTypeTLS, "rotated-cert"),
+			expectedEvent: "Normal Updated",
+			msg:           "rotated TLS secret",
+		},
+		{
+			secret:        newSecret(v1.SecretTypeOpaque, "rotated-cert"),
+			expectedEvent: "Warning Rejected",
+			msg:           "rotated secret of a wrong type",
+		},
+	}
+
+	for _, test := range tests {
+		recorder := record.NewFakeRecorder(1)
+		lbc := &LoadBalancerController{
+			configurator: configs.NewConfigurator(nginx.NewFakeManager("/etc/nginx"), &configs.StaticConfigParams{}, &configs.ConfigParams{},
+				&configs.GlobalConfigParams{}, &version1.TemplateExecutor{}, &version2.TemplateExecutor{}, false, false),
+			defaultServerSecret: "nginx-ingress/default-server-secret",
+			recorder:            recorder,
+		}
+
+		lbc.handleSpecialSecretUpdate(test.secret)
+
+		event := <-recorder.Events
+		if !strings.HasPrefix(event, test.expectedEvent) {
+			t.Errorf("handleSpecialSecretUpdate() recorded the event %q but expected %q for the case of %s", event, test.expectedEvent, test.msg)
+		}
+	}
+}
+
+func TestGetSecretNamespaces(t *testing.T) {
+	tests := []struct {
+		namespaces          []string
+		defaultServerSecret string
+		wildcardTLSSecret   string
+		secretsNamespace    string
+		expected            []string
+	}{
+		{
+			namespaces:          []string{""},
+			defaultServerSecret: "nginx-ingress/default-server-secret",
+			expected:            []string{""},
+		},
+		{
+			namespaces: []string{"cafe"},
+			expected:   []string{"cafe"},
+		},
+		{
+			namespaces:          []string{"cafe", "tea"},
+			defaultServerSecret: "nginx-ingress/default-server-secret",
+			wildcardTLSSecret:   "nginx-ingress/wildcard-secret",
+			expected:            []string{"cafe", "tea", "nginx-ingress"},
+		},
+		{
+			namespaces:          []string{"cafe"},
+			defaultServerSecret: "cafe/default-server-secret",
+			wildcardTLSSecret:   "tea/wildcard-secret",
+			expected:            []string{"cafe", "tea"},
+		},
+		{
+			namespaces:       []string{"cafe"},
+			secretsNamespace: "shared-secrets",
+			expected:         []string{"cafe", "shared-secrets"},
+		},
+		{
+			namespaces:       []string{"cafe"},
+			secretsNamespace: "cafe",
+			expected:         []string{"cafe"},
+		},
+	}
+
+	for _, test := range tests {
+		lbc := &LoadBalancerController{
+			namespaces:          test.namespaces,
+			defaultServerSecret: test.defaultServerSecret,
+			wildcardTLSSecret:   test.wildcardTLSSecret,
+			secretsNamespace:    test.secretsNamespace,
+		}
+
+		result := lbc.getSecretNamespaces()
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("getSecretNamespaces() returned %v but expected %v for namespaces %v", result, test.expected, test.namespaces)
+		}
+	}
+}
+
+func TestFindPoliciesForSecret(t *testing.T) {
+	jwtPol1 := &conf_v1alpha1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "jwt-policy-1",
+			Namespace: "ns-1",
+		},
+		Spec: conf_v1alpha1.PolicySpec{
+			JWTAuth: &conf_v1alpha1.JWTAuth{
+				Secret: "jwk-secret",
+			},
+		},
+	}
+	jwtPol2 := &conf_v1alpha1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "jwt-policy-2",
+			Namespace: "ns-2",
+		},
+		Spec: conf_v1alpha1.PolicySpec{
+			JWTAuth: &conf_v1alpha1.JWTAuth{
+				Secret: "jwk-secret",
+			},
+		},
+	}
+	aclPol := &conf_v1alpha1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "acl-policy",
+			Namespace: "ns-1",
+		},
+		Spec: conf_v1alpha1.PolicySpec{
+			AccessControl: &conf_v1alpha1.AccessControl{
+				Allow: []string{"10.0.0.0/8"},
+			},
+		},
+	}
+
+	policies := []*conf_v1alpha1.Policy{jwtPol1, jwtPol2, aclPol}
+
+	expected := []*conf_v1alpha1.Policy{jwtPol1}
+
+	result := findPoliciesForSecret(policies, "ns-1", "jwk-secret")
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("findPoliciesForSecret() returned %v but expected %v", result, expected)
+	}
+}
+
+func TestSyncPolicyResyncsReferencingVirtualServers(t *testing.T) {
+	pol := &conf_v1alpha1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "rate-limit",
+			Namespace: "default",
+		},
+		Spec: conf_v1alpha1.PolicySpec{
+			RateLimit: &conf_v1alpha1.RateLimit{
+				Rate: "10r/s",
+				Key:  "${binary_remote_addr}",
+			},
+		},
+	}
+
+	newVirtualServer := func(name string, route string, policies []conf_v1.PolicyReference) *conf_v1.VirtualServer {
+		return &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: name + ".example.com",
+				Routes: []conf_v1.Route{
+					{
+						Path:     "/",
+						Route:    route,
+						Policies: policies,
+					},
+				},
+			},
+		}
+	}
+
+	cafe := newVirtualServer("cafe", "", []conf_v1.PolicyReference{{Name: "rate-limit"}})
+	tea := newVirtualServer("tea", "default/tea", nil)
+	bakery := newVirtualServer("bakery", "", nil)
+
+	teaVSR := &conf_v1.VirtualServerRoute{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "tea",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerRouteSpec{
+			Host: "tea.example.com",
+			Subroutes: []conf_v1.Route{
+				{
+					Path:     "/",
+					Policies: []conf_v1.PolicyReference{{Name: "rate-limit", Namespace: "default"}},
+				},
+			},
+		},
+	}
+
+	policyLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	virtualServerLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+
+	for _, vs := range []*conf_v1.VirtualServer{cafe, tea, bakery} {
+		err := virtualServerLister.Add(vs)
+		if err != nil {
+			t.Fatalf("Failed to add a VirtualServer to the store: %v", err)
+		}
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	lbc := &LoadBalancerController{
+		recorder:            recorder,
+		syncQueue:           newTaskQueue(func(task) {}, 1),
+		policyLister:        policyLister,
+		virtualServerLister: virtualServerLister,
+		policyReferences:    newPolicyReferenceIndex(),
+	}
+
+	// the index is updated when the VirtualServers are synced
+	lbc.policyReferences.update(cafe, nil)
+	lbc.policyReferences.update(tea, []*conf_v1.VirtualServerRoute{teaVSR})
+	lbc.policyReferences.update(bakery, nil)
+
+	err := policyLister.Add(pol)
+	if err != nil {
+		t.Fatalf("Failed to add the Policy: %v", err)
+	}
+
+	updatedPol := pol.DeepCopy()
+	updatedPol.Spec.RateLimit.Rate = "20r/s"
+
+	err = policyLister.Update(updatedPol)
+	if err != nil {
+		t.Fatalf("Failed to update the Policy: %v", err)
+	}
+
+	lbc.syncPolicy(task{Kind: policy, Key: "default/rate-limit"})
+
+	expected := []task{
+		{Kind: virtualserver, Key: "default/cafe"},
+		{Kind: virtualserver, Key: "default/tea"},
+	}
+
+	if l := lbc.syncQueue.queue.Len(); l != len(expected) {
+		t.Fatalf("syncPolicy() added %d tasks to the queue but expected %d", l, len(expected))
+	}
+
+	var result []task
+	for range expected {
+		item, _ := lbc.syncQueue.queue.Get()
+		lbc.syncQueue.queue.Done(item)
+		result = append(result, item.(task))
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("syncPolicy() added the tasks %+v but expected %+v", result, expected)
+	}
+
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Normal AddedOrUpdated") {
+		t.Errorf("syncPolicy() recorded the event %q but expected an AddedOrUpdated event", event)
+	}
+
+	// after the deletion of a VirtualServer, the Policy no longer resyncs it
+	err = virtualServerLister.Delete(cafe)
+	if err != nil {
+		t.Fatalf("Failed to delete a VirtualServer from the store: %v", err)
+	}
+	lbc.policyReferences.remove("default/cafe")
+
+	lbc.syncPolicy(task{Kind: policy, Key: "default/rate-limit"})
+
+	if l := lbc.syncQueue.queue.Len(); l != 1 {
+		t.Errorf("syncPolicy() after the deletion of a VirtualServer added %d tasks to the queue but expected 1", l)
+	}
+}
+
+func TestGetSecretKeyForReference(t *testing.T) {
+	tests := []struct {
+		secretsNamespace string
+		secretRef        string
+		expectedKey      string
+		expectedErr      bool
+		msg              string
+	}{
+		{
+			secretRef:   "cafe-secret",
+			expectedKey: "cafe/cafe-secret",
+			expectedErr: false,
+			msg:         "same namespace",
+		},
+		{
+			secretRef:   "cafe/cafe-secret",
+			expectedKey: "cafe/cafe-secret",
+			expectedErr: false,
+			msg:         "same namespace with an explicit namespace",
+		},
+		{
+			secretsNamespace: "shared-secrets",
+			secretRef:        "shared-secrets/wildcard-secret",
+			expectedKey:      "shared-secrets/wildcard-secret",
+			expectedErr:      false,
+			msg:              "cross-namespace reference to the secrets namespace",
+		},
+		{
+			secretsNamespace: "shared-secrets",
+			secretRef:        "tea/tea-secret",
+			expectedKey:      "tea/tea-secret",
+			expectedErr:      true,
+			msg:              "cross-namespace reference to another namespace",
+		},
+		{
+			secretRef:   "shared-secrets/wildcard-secret",
+			expectedKey: "shared-secrets/wildcard-secret",
+			expectedErr: true,
+			msg:         "cross-namespace reference without the secrets namespace",
+		},
+	}
+
+	for _, test := range tests {
+		lbc := &LoadBalancerController{
+			secretsNamespace: test.secretsNamespace,
+		}
+
+		key, err := lbc.getSecretKeyForReference("cafe", test.secretRef)
+		if key != test.expectedKey {
+			t.Errorf("getSecretKeyForReference() returned the key %q but expected %q for the case of %s", key, test.expectedKey, test.msg)
+		}
+		if (err != nil) != test.expectedErr {
+			t.Errorf("getSecretKeyForReference() returned the error %v for the case of %s", err, test.msg)
+		}
+	}
+}
+
+func TestCreateVirtualServerWithCrossNamespaceTLSSecret(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "wildcard-secret",
+			Namespace: "shared-secrets",
+		},
+		Type: v1.SecretTypeTLS,
+		Data: map[string][]byte{
+			v1.TLSCertKey:       []byte("cert"),
+			v1.TLSPrivateKeyKey: []byte("key"),
+		},
+	}
+
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "cafe",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Host: "cafe.example.com",
+			TLS: &conf_v1.TLS{
+				Secret: "shared-secrets/wildcard-secret",
+			},
+		},
+	}
+
+	tests := []struct {
+		secretsNamespace string
+		expectedSecret   *v1.Secret
+		msg              string
+	}{
+		{
+			secretsNamespace: "shared-secrets",
+			expectedSecret:   secret,
+			msg:              "allowed cross-namespace reference",
+		},
+		{
+			secretsNamespace: "",
+			expectedSecret:   nil,
+			msg:              "disallowed cross-namespace reference",
+		},
+	}
+
+	for _, test := range tests {
+		secretLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+		err := secretLister.Add(secret)
+		if err != nil {
+			t.Fatalf("Failed to add the secret to the store: %v", err)
+		}
+
+		lbc := &LoadBalancerController{
+			secretLister:     storeToSecretLister{secretLister},
+			secretsNamespace: test.secretsNamespace,
+		}
+
+		vsEx, _ := lbc.createVirtualServer(vs)
+		if vsEx.TLSSecret != test.expectedSecret {
+			t.Errorf("createVirtualServer() returned the TLS secret %v but expected %v for the case of %s", vsEx.TLSSecret, test.expectedSecret, test.msg)
+		}
+	}
+}
+
+func TestSyncGlobalConfigurationWithConflictingPort(t *testing.T) {
+	gc := &conf_v1alpha1.GlobalConfiguration{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "global-configuration",
+			Namespace: "nginx-ingress",
+		},
+		Spec: conf_v1alpha1.GlobalConfigurationSpec{
+			Listeners: []conf_v1alpha1.Listener{
+				{
+					Name:     "tcp-listener",
+					Port:     443,
+					Protocol: "TCP",
+				},
+			},
+		},
+	}
+
+	globalConfigurationLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	err := globalConfigurationLister.Add(gc)
+	if err != nil {
+		t.Fatalf("Failed to add the GlobalConfiguration to the store: %v", err)
+	}
+
+	recorder := record.NewFakeRecorder(1)
+	collector := &recordingControllerCollector{}
+	// the controller has no configurator, so the test panics if the rejected GlobalConfiguration reaches it
+	lbc := &LoadBalancerController{
+		recorder:                   recorder,
+		metricsCollector:           collector,
+		globalConfigurationListers: []cache.Store{globalConfigurationLister},
+		globalConfigurationValidator: validation.NewGlobalConfigurationValidator(map[int]string{
+			80:  "the HTTP listener",
+			443: "the HTTPS listener",
+		}),
+	}
+
+	lbc.syncGlobalConfiguration(task{Kind: globalConfiguration, Key: "nginx-ingress/global-configuration"})
+
+	expected := "Warning Rejected GlobalConfiguration nginx-ingress/global-configuration is invalid and was rejected: " +
+		"spec.listeners[0].port: Forbidden: port 443 is already used by the HTTPS listener"
+	if event := <-recorder.Events; event != expected {
+		t.Errorf("syncGlobalConfiguration() recorded the event %q but expected %q", event, expected)
+	}
+
+	expectedFailures := map[validationFailure]int{
+		{kind: "GlobalConfiguration", reason: "FieldValueForbidden"}: 1,
+	}
+	if !reflect.DeepEqual(collector.validationFailures, expectedFailures) {
+		t.Errorf("syncGlobalConfiguration() counted the validation failures %v but expected %v", collector.validationFailures, expectedFailures)
+	}
+}
+
+type validationFailure struct {
+	kind   string
+	reason string
+}
+
+type recordingControllerCollector struct {
+	collectors.ControllerFakeCollector
+	transportServers     int
+	globalConfigurations int
+	validationFailures   map[validationFailure]int
+}
+
+func (cc *recordingControllerCollector) IncResourceValidationFailures(kind string, reason string) {
+	if cc.validationFailures == nil {
+		cc.validationFailures = make(map[validationFailure]int)
+	}
+	cc.validationFailures[validationFailure{kind: kind, reason: reason}]++
+}
+
+func (cc *recordingControllerCollector) SetTransportServers(count int) {
+	cc.transportServers = count
+}
+
+func (cc *recordingControllerCollector) SetGlobalConfigurations(count int) {
+	cc.globalConfigurations = count
+}
+
+func TestUpdateTransportServerAndGlobalConfigurationMetrics(t *testing.T) {
+	gc := &conf_v1alpha1.GlobalConfiguration{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "global-configuration",
+			Namespace: "nginx-ingress",
+		},
+		Spec: conf_v1alpha1.GlobalConfigurationSpec{
+			Listeners: []conf_v1alpha1.Listener{
+				{
+					Name:     "tcp-listener",
+					Port:     5353,
+					Protocol: "TCP",
+				},
+			},
+		},
+	}
+	ts := &conf_v1alpha1.TransportServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "tcp-app",
+			Namespace: "default",
+		},
+		Spec: conf_v1alpha1.TransportServerSpec{
+			Listener: conf_v1alpha1.TransportServerListener{
+				Name:     "tcp-listener",
+				Protocol: "TCP",
+			},
+			Upstreams: []conf_v1alpha1.Upstream{
+				{
+					Name:    "tcp-app",
+					Service: "tcp-app-svc",
+					Port:    5001,
+				},
+			},
+			Action: &conf_v1alpha1.Action{
+				Pass: "tcp-app",
+			},
+		},
+	}
+
+	globalCfgParams := &configs.GlobalConfigParams{
+		Listeners: map[string]configs.Listener{
+			"tcp-listener": {
+				Port:     5353,
+				Protocol: "TCP",
+			},
+		},
+	}
+
+	collector := &recordingControllerCollector{}
+	lbc := LoadBalancerController{
+		configurator: configs.NewConfigurator(nginx.NewFakeManager("/etc/nginx"), &configs.StaticConfigParams{}, &configs.ConfigParams{},
+			globalCfgParams, &version1.TemplateExecutor{}, &version2.TemplateExecutor{}, false, false),
+		globalConfigurationListers:   []cache.Store{cache.NewStore(cache.MetaNamespaceKeyFunc)},
+		transportServerLister:        cache.NewStore(cache.MetaNamespaceKeyFunc),
+		globalConfigurationValidator: validation.NewGlobalConfigurationValidator(map[int]string{}),
+		transportServerValidator:     validation.NewTransportServerValidator(false),
+		metricsCollector:             collector,
+	}
+
+	lbc.globalConfigurationListers[0].Add(gc)
+	lbc.transportServerLister.Add(ts)
+
+	lbc.updateGlobalConfigurationMetrics()
+	lbc.updateTransportServerMetrics()
+
+	if collector.globalConfigurations != 1 {
+		t.Errorf("updateGlobalConfigurationMetrics() set %d GlobalConfigurations after adding, expected 1", collector.globalConfigurations)
+	}
+	if collector.transportServers != 1 {
+		t.Errorf("updateTransportServerMetrics() set %d TransportServers after adding, expected 1", collector.transportServers)
+	}
+
+	lbc.globalConfigurationListers[0].Delete(gc)
+	lbc.transportServerLister.Delete(ts)
+
+	lbc.updateGlobalConfigurationMetrics()
+	lbc.updateTransportServerMetrics()
+
+	if collector.globalConfigurations != 0 {
+		t.Errorf("updateGlobalConfigurationMetrics() set %d GlobalConfigurations after removing, expected 0", collector.globalConfigurations)
+	}
+	if collector.transportServers != 0 {
+		t.Errorf("updateTransportServerMetrics() set %d TransportServers after removing, expected 0", collector.transportServers)
+	}
+}
+
+func TestGetEventParamsForSkippedResource(t *testing.T) {
+	skippedIng := &extensions.Ingress{ObjectMeta: meta_v1.ObjectMeta{Name: "skipped", Namespace: "default"}}
+	validIng := &extensions.Ingress{ObjectMeta: meta_v1.ObjectMeta{Name: "valid", Namespace: "default"}}
+
+	resourceErrors := configs.ResourceErrors{
+		skippedIng: fmt.Errorf("invalid snippets"),
+	}
+
+	eventType, eventTitle, eventWarningMessage := getEventParamsForSkippedResource(resourceErrors, skippedIng, v1.EventTypeNormal, "Updated", "")
+	if eventType != v1.EventTypeWarning || eventTitle != "UpdatedWithError" || eventWarningMessage != "but was skipped and not applied: invalid snippets" {
+		t.Errorf("getEventParamsForSkippedResource() returned %q, %q, %q for a skipped resource", eventType, eventTitle, eventWarningMessage)
+	}
+
+	eventType, eventTitle, eventWarningMessage = getEventParamsForSkippedResource(resourceErrors, validIng, v1.EventTypeNormal, "Updated", "")
+	if eventType != v1.EventTypeNormal || eventTitle != "Updated" || eventWarningMessage != "" {
+		t.Errorf("getEventParamsForSkippedResource() returned %q, %q, %q for a valid resource", eventType, eventTitle, eventWarningMessage)
+	}
+}
+
+func TestFindChangedCutovers(t *testing.T) {
+	tests := []struct {
+		previousRoutes []conf_v1.Route
+		routes         []conf_v1.Route
+		expected       []conf_v1.Route
+		msg            string
+	}{
+		{
+			previousRoutes: nil,
+			routes: []conf_v1.Route{
+				{Path: "/tea"},
+				{Path: "/coffee", Cutover: "promote"},
+			},
+			expected: []conf_v1.Route{
+				{Path: "/coffee", Cutover: "promote"},
+			},
+			msg: "new resource with a cutover",
+		},
+		{
+			previousRoutes: []conf_v1.Route{
+				{Path: "/coffee"},
+			},
+			routes: []conf_v1.Route{
+				{Path: "/coffee", Cutover: "rollback"},
+			},
+			expected: []conf_v1.Route{
+				{Path: "/coffee", Cutover: "rollback"},
+			},
+			msg: "added cutover",
+		},
+		{
+			previousRoutes: []conf_v1.Route{
+				{Path: "/coffee", Cutover: "promote"},
+			},
+			routes: []conf_v1.Route{
+				{Path: "/coffee", Cutover: "rollback"},
+			},
+			expected: []conf_v1.Route{
+				{Path: "/coffee", Cutover: "rollback"},
+			},
+			msg: "changed cutover",
+		},
+		{
+			previousRoutes: []conf_v1.Route{
+				{Path: "/coffee", Cutover: "promote"},
+			},
+			routes: []conf_v1.Route{
+				{Path: "/coffee", Cutover: "promote"},
+			},
+			expected: nil,
+			msg:      "unchanged cutover",
+		},
+		{
+			previousRoutes: []conf_v1.Route{
+				{Path: "/coffee", Cutover: "promote"},
+			},
+			routes: []conf_v1.Route{
+				{Path: "/coffee"},
+			},
+			expected: nil,
+			msg:      "removed cutover",
+		},
+	}
+
+	for _, test := range tests {
+		result := findChangedCutovers(test.previousRoutes, test.routes)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("findChangedCutovers() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestValidateServicePortReference(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "coffee-svc",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name: "http",
+					Port: 80,
+				},
+			},
+		},
+	}
+
+	validPorts := []intstr.IntOrString{
+		intstr.FromInt(80),
+		intstr.FromString("http"),
+	}
+
+	for _, port := range validPorts {
+		if err := validateServicePortReference(svc, port); err != nil {
+			t.Errorf("validateServicePortReference() returned error %v for the valid port %v", err, port.String())
+		}
+	}
+
+	invalidPorts := []intstr.IntOrString{
+		intstr.FromInt(8080),
+		intstr.FromString("https"),
+	}
+
+	for _, port := range invalidPorts {
+		if err := validateServicePortReference(svc, port); err == nil {
+			t.Errorf("validateServicePortReference() returned no error for the missing port %v", port.String())
+		}
+	}
+
+	externalSvc := &v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "external-svc",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Type:         v1.ServiceTypeExternalName,
+			ExternalName: "example.com",
+		},
+	}
+
+	if err := validateServicePortReference(externalSvc, intstr.FromInt(80)); err != nil {
+		t.Errorf("validateServicePortReference() returned error %v for an ExternalName service", err)
+	}
+}
+
+func TestValidateIngressDefaultBackend(t *testing.T) {
+	svcLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	err := svcLister.Add(&v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "coffee-svc",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name: "http",
+					Port: 80,
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add the Service to the store: %v", err)
+	}
+
+	lbc := LoadBalancerController{
+		svcLister: svcLister,
+	}
+
+	createIngress := func(backend *extensions.IngressBackend) *extensions.Ingress {
+		return &extensions.Ingress{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe-ingress",
+				Namespace: "default",
+			},
+			Spec: extensions.IngressSpec{
+				Backend: backend,
+			},
+		}
+	}
+
+	validIngresses := []*extensions.Ingress{
+		createIngress(nil),
+		createIngress(&extensions.IngressBackend{ServiceName: "coffee-svc", ServicePort: intstr.FromInt(80)}),
+		createIngress(&extensions.IngressBackend{ServiceName: "coffee-svc", ServicePort: intstr.FromString("http")}),
+	}
+
+	for _, ing := range validIngresses {
+		if err := lbc.validateIngressDefaultBackend(ing); err != nil {
+			t.Errorf("validateIngressDefaultBackend() returned error %v for the valid default backend %+v", err, ing.Spec.Backend)
+		}
+	}
+
+	invalidIngresses := []*extensions.Ingress{
+		createIngress(&extensions.IngressBackend{ServiceName: "tea-svc", ServicePort: intstr.FromInt(80)}),
+		createIngress(&extensions.IngressBackend{ServiceName: "coffee-svc", ServicePort: intstr.FromInt(8080)}),
+		createIngress(&extensions.IngressBackend{ServiceName: "coffee-svc", ServicePort: intstr.FromString("https")}),
+	}
+
+	for _, ing := range invalidIngresses {
+		if err := lbc.validateIngressDefaultBackend(ing); err == nil {
+			t.Errorf("validateIngressDefaultBackend() returned no error for the invalid default backend %+v", ing.Spec.Backend)
+		}
+	}
+}
+
+func TestValidateErrorBackendService(t *testing.T) {
+	svcLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	err := svcLister.Add(&v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "errors-svc",
+			Namespace: "default",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add the Service to the store: %v", err)
+	}
+
+	lbc := LoadBalancerController{
+		svcLister: svcLister,
+	}
+
+	createVirtualServer := func(errorBackend *conf_v1.ErrorBackend) *conf_v1.VirtualServer {
+		return &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				ErrorBackend: errorBackend,
+				Upstreams: []conf_v1.Upstream{
+					{Name: "errors", Service: "errors-svc", Port: 80},
+					{Name: "tea", Service: "tea-svc", Port: 80},
+				},
+			},
+		}
+	}
+
+	validVirtualServers := []*conf_v1.VirtualServer{
+		createVirtualServer(nil),
+		createVirtualServer(&conf_v1.ErrorBackend{Upstream: "errors", Codes: []int{404}}),
+	}
+
+	for _, vs := range validVirtualServers {
+		if err := lbc.validateErrorBackendService(vs); err != nil {
+			t.Errorf("validateErrorBackendService() returned error %v for the valid error backend %+v", err, vs.Spec.ErrorBackend)
+		}
+	}
+
+	invalidVirtualServers := []*conf_v1.VirtualServer{
+		createVirtualServer(&conf_v1.ErrorBackend{Upstream: "tea", Codes: []int{404}}),
+		createVirtualServer(&conf_v1.ErrorBackend{Upstream: "coffee", Codes: []int{404}}),
+	}
+
+	for _, vs := range invalidVirtualServers {
+		if err := lbc.validateErrorBackendService(vs); err == nil {
+			t.Errorf("validateErrorBackendService() returned no error for the invalid error backend %+v", vs.Spec.ErrorBackend)
+		}
+	}
+}
+
+func TestValidateUpstreamServicePorts(t *testing.T) {
+	svcLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	err := svcLister.Add(&v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "coffee-svc",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name: "http",
+					Port: 80,
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add the Service to the store: %v", err)
+	}
+
+	lbc := LoadBalancerController{
+		svcLister: svcLister,
+	}
+
+	validUpstreams := [][]conf_v1.Upstream{
+		{
+			{Name: "coffee", Service: "coffee-svc", Port: 80},
+		},
+		{
+			{Name: "tea", Service: "tea-svc", Port: 8080},
+		},
+		{
+			{Name: "coffee", Service: "coffee-svc", Port: 80, Backup: "coffee-svc", BackupPort: 80},
+		},
+	}
+
+	for _, upstreams := range validUpstreams {
+		if err := lbc.validateUpstreamServicePorts("default", upstreams); err != nil {
+			t.Errorf("validateUpstreamServicePorts() returned error %v for the valid upstreams %+v", err, upstreams)
+		}
+	}
+
+	invalidUpstreams := [][]conf_v1.Upstream{
+		{
+			{Name: "coffee", Service: "coffee-svc", Port: 8080},
+		},
+		{
+			{Name: "coffee", Service: "coffee-svc", Port: 80, Backup: "coffee-svc", BackupPort: 8080},
+		},
+	}
+
+	for _, upstreams := range invalidUpstreams {
+		if err := lbc.validateUpstreamServicePorts("default", upstreams); err == nil {
+			t.Errorf("validateUpstreamServicePorts() returned no error for the invalid upstreams %+v", upstreams)
+		}
+	}
+}
+
+func TestValidateUpstreamServices(t *testing.T) {
+	svcLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	services := []*v1.Service{
+		{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "coffee-v2-svc",
+				Namespace: "default",
+			},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{
+						Name: "http",
+						Port: 80,
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "external-svc",
+				Namespace: "default",
+			},
+			Spec: v1.ServiceSpec{
+				Type:         v1.ServiceTypeExternalName,
+				ExternalName: "coffee.example.com",
+			},
+		},
+	}
+	for _, svc := range services {
+		if err := svcLister.Add(svc); err != nil {
+			t.Fatalf("Failed to add the Service to the store: %v", err)
+		}
+	}
+
+	lbc := LoadBalancerController{
+		svcLister: svcLister,
+	}
+
+	validUpstreams := [][]conf_v1.Upstream{
+		{
+			{Name: "coffee", Service: "coffee-svc", Port: 80},
+		},
+		{
+			{Name: "coffee", Service: "coffee-svc", Services: []string{"coffee-v2-svc"}, Port: 80},
+		},
+	}
+
+	for _, upstreams := range validUpstreams {
+		if err := lbc.validateUpstreamServices("default", upstreams); err != nil {
+			t.Errorf("validateUpstreamServices() returned error %v for the valid upstreams %+v", err, upstreams)
+		}
+	}
+
+	invalidUpstreams := [][]conf_v1.Upstream{
+		{
+			{Name: "coffee", Service: "coffee-svc", Services: []string{"coffee-v3-svc"}, Port: 80},
+		},
+		{
+			{Name: "coffee", Service: "coffee-svc", Services: []string{"coffee-v2-svc"}, Port: 8080},
+		},
+		{
+			{Name: "coffee", Service: "coffee-svc", Services: []string{"external-svc"}, Port: 80},
+		},
+	}
+
+	for _, upstreams := range invalidUpstreams {
+		if err := lbc.validateUpstreamServices("default", upstreams); err == nil {
+			t.Errorf("validateUpstreamServices() returned no error for the invalid upstreams %+v", upstreams)
+		}
+	}
+}
+
+func TestSyncPausedIngressKeepsAppliedSpec(t *testing.T) {
+	templateExecutor, err := version1.NewTemplateExecutor("../configs/version1/nginx.tmpl", "../configs/version1/nginx.ingress.tmpl")
+	if err != nil {
+		t.Fatalf("templateExecutor could not start: %v", err)
+	}
+	templateExecutorV2, err := version2.NewTemplateExecutor("../configs/version2/nginx.virtualserver.tmpl", "../configs/version2/nginx.transportserver.tmpl")
+	if err != nil {
+		t.Fatalf("templateExecutorV2 could not start: %v", err)
+	}
+
+	cnf := configs.NewConfigurator(nginx.NewFakeManager("/etc/nginx"), &configs.StaticConfigParams{}, configs.NewDefaultConfigParams(),
+		configs.NewDefaultGlobalConfigParams(), templateExecutor, templateExecutorV2, false, false)
+
+	lbc := LoadBalancerController{
+		ingressClass:    "nginx",
+		configurator:    cnf,
+		ingressLister:   storeToIngressLister{Store: cache.NewStore(cache.MetaNamespaceKeyFunc)},
+		svcLister:       cache.NewStore(cache.MetaNamespaceKeyFunc),
+		endpointLister:  storeToEndpointLister{Store: cache.NewStore(cache.MetaNamespaceKeyFunc)},
+		configMapLister: storeToConfigMapLister{Store: cache.NewStore(cache.MetaNamespaceKeyFunc)},
+		statusUpdater:   &statusUpdater{},
+		ingressPaths:    newIngressPathIndex(),
+		recorder:        record.NewFakeRecorder(10),
+	}
+
+	newIngress := func(path string, paused bool) *extensions.Ingress {
+		ing := createTestIngressWithPaths("cafe", time.Now(), "cafe.example.com", path)
+		ing.Spec.Rules[0].HTTP.Paths[0].Backend.ServiceName = "tea-svc"
+		if paused {
+			ing.Annotations = map[string]string{pausedKey: "true"}
+		}
+		return ing
+	}
+
+	err = cnf.AddOrUpdateIngress(&configs.IngressEx{
+		Ingress:   newIngress("/tea", false),
+		Endpoints: map[string][]string{"tea-svc80": {"10.0.0.1:8080"}},
+	})
+	if err != nil {
+		t.Fatalf("AddOrUpdateIngress() returned an unexpected error: %v", err)
+	}
+
+	// the path was changed while the Ingress is paused
+	lbc.ingressLister.Add(newIngress("/green-tea", true))
+	lbc.svcLister.Add(&v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "tea-svc", Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}},
+		},
+	})
+	lbc.endpointLister.Add(&v1.Endpoints{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "tea-svc", Namespace: "default"},
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses: []v1.EndpointAddress{{IP: "10.0.0.2"}},
+				Ports:     []v1.EndpointPort{{Port: 8080}},
+			},
+		},
+	})
+	lbc.configMapLister.Add(&v1.ConfigMap{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "nginx-config", Namespace: "default"},
+		Data:       map[string]string{"proxy-read-timeout": "90s"},
+	})
+
+	checkAppliedPath := func(sync string) {
+		ing := cnf.GetIngress("default/cafe")
+		if ing == nil {
+			t.Fatalf("The configuration of the paused Ingress was removed after the sync of the %s", sync)
+		}
+		if path := ing.Spec.Rules[0].HTTP.Paths[0].Path; path != "/tea" {
+			t.Errorf("The sync of the %s applied the path %q of the paused Ingress but expected the last applied path /tea", sync, path)
+		}
+	}
+
+	lbc.syncEndpoint(task{Kind: endpoints, Key: "default/tea-svc"})
+	checkAppliedPath("endpoints")
+
+	lbc.syncConfig(task{Kind: configMap, Key: "default/nginx-config"})
+	checkAppliedPath("ConfigMap")
+
+	// after the Ingress is unpaused, its spec is applied
+	lbc.ingressLister.Update(newIngress("/green-tea", false))
+	lbc.syncEndpoint(task{Kind: endpoints, Key: "default/tea-svc"})
+	if path := cnf.GetIngress("default/cafe").Spec.Rules[0].HTTP.Paths[0].Path; path != "/green-tea" {
+		t.Errorf("The sync of the endpoints applied the path %q of the unpaused Ingress but expected /green-tea", path)
+	}
+}
+
+func TestCreateMergeableIngressesForPausedMaster(t *testing.T) {
+	templateExecutor, err := version1.NewTemplateExecutor("../configs/version1/nginx.tmpl", "../configs/version1/nginx.ingress.tmpl")
+	if err != nil {
+		t.Fatalf("templateExecutor could not start: %v", err)
+	}
+
+	cnf := configs.NewConfigurator(nginx.NewFakeManager("/etc/nginx"), &configs.StaticConfigParams{}, configs.NewDefaultConfigParams(),
+		configs.NewDefaultGlobalConfigParams(), templateExecutor, &version2.TemplateExecutor{}, false, false)
+
+	cafeMaster, coffeeMinion, _, _ := getMergableDefaults()
+	cafeMaster.Spec.Rules[0].HTTP = &extensions.HTTPIngressRuleValue{
+		Paths: []extensions.HTTPIngressPath{},
+	}
+
+	err = cnf.AddOrUpdateMergeableIngress(&configs.MergeableIngresses{
+		Master:  &configs.IngressEx{Ingress: cafeMaster.DeepCopy()},
+		Minions: []*configs.IngressEx{{Ingress: coffeeMinion.DeepCopy(), Endpoints: map[string][]string{}}},
+	})
+	if err != nil {
+		t.Fatalf("AddOrUpdateMergeableIngress() returned an unexpected error: %v", err)
+	}
+
+	lbc := LoadBalancerController{
+		ingressClass:  "nginx",
+		configurator:  cnf,
+		ingressLister: storeToIngressLister{Store: cache.NewStore(cache.MetaNamespaceKeyFunc)},
+		svcLister:     cache.NewStore(cache.MetaNamespaceKeyFunc),
+		ingressPaths:  newIngressPathIndex(),
+	}
+
+	// the path of the minion was changed while the master is paused
+	pausedMaster := cafeMaster.DeepCopy()
+	pausedMaster.Annotations[pausedKey] = "true"
+	changedMinion := coffeeMinion.DeepCopy()
+	changedMinion.Spec.Rules[0].HTTP.Paths[0].Path = "/espresso"
+	lbc.ingressLister.Add(pausedMaster)
+	lbc.ingressLister.Add(changedMinion)
+
+	mergeableIngs, err := lbc.createMergableIngresses(pausedMaster)
+	if err != nil {
+		t.Fatalf("createMergableIngresses() returned an unexpected error: %v", err)
+	}
+	if len(mergeableIngs.Minions) != 1 {
+		t.Fatalf("createMergableIngresses() returned %d minions but expected 1", len(mergeableIngs.Minions))
+	}
+	if path := mergeableIngs.Minions[0].Ingress.Spec.Rules[0].HTTP.Paths[0].Path; path != "/coffee" {
+		t.Errorf("createMergableIngresses() returned the minion path %q for a paused master but expected the last applied path /coffee", path)
+	}
+}
+
+func TestSyncVirtualServerWithMissingTLSSecretPolicyError(t *testing.T) {
+	templateExecutor, err := version1.NewTemplateExecutor("../configs/version1/nginx.tmpl", "../configs/version1/nginx.ingress.tmpl")
+	if err != nil {
+		t.Fatalf("templateExecutor could not start: %v", err)
+	}
+	templateExecutorV2, err := version2.NewTemplateExecutor("../configs/version2/nginx.virtualserver.tmpl", "../configs/version2/nginx.transportserver.tmpl")
+	if err != nil {
+		t.Fatalf("templateExecutorV2 could not start: %v", err)
+	}
+
+	staticCfgParams := &configs.StaticConfigParams{MissingTLSSecretPolicy: configs.MissingTLSSecretPolicyError}
+	cnf := configs.NewConfigurator(nginx.NewFakeManager("/etc/nginx"), staticCfgParams, configs.NewDefaultConfigParams(),
+		configs.NewDefaultGlobalConfigParams(), templateExecutor, templateExecutorV2, false, false)
+
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Host: "cafe.example.com",
+			TLS: &conf_v1.TLS{
+				Secret: "cafe-secret",
+			},
+		},
+	}
+
+	// the VirtualServer was applied before its TLS secret was removed
+	_, err = cnf.AddOrUpdateVirtualServer(&configs.VirtualServerEx{VirtualServer: vs, TLSSecret: &v1.Secret{}})
+	if err != nil {
+		t.Fatalf("AddOrUpdateVirtualServer() returned an unexpected error: %v", err)
+	}
+
+	virtualServerLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	err = virtualServerLister.Add(vs)
+	if err != nil {
+		t.Fatalf("Failed to add the VirtualServer to the store: %v", err)
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	lbc := &LoadBalancerController{
+		configurator:            cnf,
+		virtualServerLister:     virtualServerLister,
+		secretLister:            storeToSecretLister{cache.NewStore(cache.MetaNamespaceKeyFunc)},
+		svcLister:               cache.NewStore(cache.MetaNamespaceKeyFunc),
+		policyReferences:        newPolicyReferenceIndex(),
+		recorder:                recorder,
+		missingTLSSecretPolicy:  configs.MissingTLSSecretPolicyError,
+		isLeaderElectionEnabled: true,
+	}
+
+	lbc.syncVirtualServer(task{Kind: virtualserver, Key: "default/cafe"})
+
+	expectedEvent := "Warning Rejected VirtualServer default/cafe references TLS secret default/cafe-secret that is invalid, doesn't exist or is not allowed; " +
+		"the error policy was applied: the VirtualServer was rejected"
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	if !reflect.DeepEqual(events, []string{expectedEvent}) {
+		t.Errorf("syncVirtualServer() recorded the events %q but expected %q", events, expectedEvent)
+	}
+
+	if cnf.GetVirtualServer("default/cafe") != nil {
+		t.Errorf("syncVirtualServer() kept the configuration of the VirtualServer with a missing TLS secret")
+	}
+}
@@ -0,0 +1,120 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	api_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// ignoredByClassReason is the reason of the event log entries of the resources that the Ingress Controller ignores
+// because of their class. No Kubernetes Event is recorded for such resources, as they belong to other controllers.
+const ignoredByClassReason = "IgnoredByClass"
+
+// eventLogEntry is a line of the structured event log. The fields and their order are the stable format of the log.
+type eventLogEntry struct {
+	Time      string `json:"time"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+}
+
+// eventLog writes the decisions of the Ingress Controller about the resources as JSON lines.
+type eventLog struct {
+	writer io.Writer
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+func newEventLog(writer io.Writer) *eventLog {
+	return &eventLog{
+		writer: writer,
+		now:    time.Now,
+	}
+}
+
+func (l *eventLog) log(object runtime.Object, eventType string, reason string, message string) {
+	entry := eventLogEntry{
+		Time:    l.now().UTC().Format(time.RFC3339),
+		Kind:    getObjectKind(object),
+		Type:    eventType,
+		Reason:  reason,
+		Message: message,
+	}
+
+	if accessor, err := meta.Accessor(object); err == nil {
+		entry.Namespace = accessor.GetNamespace()
+		entry.Name = accessor.GetName()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		glog.Errorf("Failed to marshal the event log entry %+v: %v", entry, err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.writer.Write(append(line, '\n')); err != nil {
+		glog.Errorf("Failed to write the event log entry: %v", err)
+	}
+}
+
+// getObjectKind returns the kind of the object. The objects from the listers don't have the kind set, so the kind
+// of such objects is the name of their type.
+func getObjectKind(object runtime.Object) string {
+	if kind := object.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+
+	t := reflect.TypeOf(object)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+// eventLogRecorder records the Kubernetes Events and writes them to the event log.
+type eventLogRecorder struct {
+	record.EventRecorder
+	eventLog *eventLog
+}
+
+func (r *eventLogRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	r.EventRecorder.Event(object, eventType, reason, message)
+	r.eventLog.log(object, eventType, reason, message)
+}
+
+func (r *eventLogRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.Eventf(object, eventType, reason, messageFmt, args...)
+	r.eventLog.log(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *eventLogRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventType, reason, messageFmt, args...)
+	r.eventLog.log(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// logIgnoredByClass writes the entry about the resource ignored because of its class to the event log, if the event log
+// is enabled.
+func (lbc *LoadBalancerController) logIgnoredByClass(object runtime.Object) {
+	if lbc.eventLog == nil {
+		return
+	}
+
+	lbc.eventLog.log(object, api_v1.EventTypeNormal, ignoredByClassReason,
+		fmt.Sprintf("The resource was ignored because its class doesn't match the class %v of the Ingress Controller", lbc.ingressClass))
+}
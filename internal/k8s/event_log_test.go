@@ -0,0 +1,76 @@
+package k8s
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	api_v1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestEventLogRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	eventLog := newEventLog(&buf)
+	eventLog.now = func() time.Time {
+		return time.Date(2020, 10, 14, 10, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	}
+
+	fakeRecorder := record.NewFakeRecorder(10)
+	lbc := LoadBalancerController{
+		ingressClass: "nginx",
+		eventLog:     eventLog,
+		recorder: &eventLogRecorder{
+			EventRecorder: fakeRecorder,
+			eventLog:      eventLog,
+		},
+	}
+
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	ing := &v1beta1.Ingress{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "tea",
+			Namespace: "default",
+		},
+	}
+	vsr := &conf_v1.VirtualServerRoute{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "coffee",
+			Namespace: "other",
+		},
+	}
+
+	lbc.recorder.Eventf(vs, api_v1.EventTypeWarning, "Rejected", "VirtualServer %v is invalid and was rejected: %v", "default/cafe", `spec.host: Required value`)
+	lbc.recorder.Event(ing, api_v1.EventTypeNormal, "AddedOrUpdated", "Configuration for default/tea was added or updated")
+	lbc.logIgnoredByClass(vsr)
+
+	expected := []string{
+		`{"time":"2020-10-14T08:00:00Z","kind":"VirtualServer","namespace":"default","name":"cafe","type":"Warning","reason":"Rejected","message":"VirtualServer default/cafe is invalid and was rejected: spec.host: Required value"}`,
+		`{"time":"2020-10-14T08:00:00Z","kind":"Ingress","namespace":"default","name":"tea","type":"Normal","reason":"AddedOrUpdated","message":"Configuration for default/tea was added or updated"}`,
+		`{"time":"2020-10-14T08:00:00Z","kind":"VirtualServerRoute","namespace":"other","name":"coffee","type":"Normal","reason":"IgnoredByClass","message":"The resource was ignored because its class doesn't match the class nginx of the Ingress Controller"}`,
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("The event log has %d lines but expected %d: %q", len(lines), len(expected), buf.String())
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("The event log line %d is %s but expected %s", i, lines[i], expected[i])
+		}
+	}
+
+	// the events are still recorded as Kubernetes Events, but the ignored resources are not
+	if len(fakeRecorder.Events) != 2 {
+		t.Errorf("The recorder recorded %d Kubernetes Events but expected 2", len(fakeRecorder.Events))
+	}
+}
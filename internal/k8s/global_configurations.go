@@ -0,0 +1,89 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+)
+
+// ParseGlobalConfigurations parses a comma-separated list of GlobalConfiguration resources in the format
+// <namespace>/<name> and returns the keys of the resources in their order.
+func ParseGlobalConfigurations(value string) ([]string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+
+	for _, key := range strings.Split(value, ",") {
+		ns, name, err := ParseNamespaceName(key)
+		if err != nil {
+			return nil, err
+		}
+		if ns == "" || name == "" {
+			return nil, fmt.Errorf("%q must follow the format <namespace>/<name>", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("GlobalConfiguration %v is specified more than once", key)
+		}
+
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// globalConfigurationSet keeps the last valid versions of the GlobalConfigurations of the Ingress Controller,
+// so that the listeners of a GlobalConfiguration that becomes invalid or is removed are retained.
+// The set is safe for concurrent use by multiple sync workers.
+type globalConfigurationSet struct {
+	mu sync.Mutex
+	// keys are the keys of the GlobalConfigurations in the order of the -global-configuration flag,
+	// which is the order their listeners are merged in.
+	keys  []string
+	valid map[string]*conf_v1alpha1.GlobalConfiguration
+}
+
+func newGlobalConfigurationSet(keys []string) *globalConfigurationSet {
+	return &globalConfigurationSet{
+		keys:  keys,
+		valid: make(map[string]*conf_v1alpha1.GlobalConfiguration),
+	}
+}
+
+// update stores the valid GlobalConfiguration.
+func (s *globalConfigurationSet) update(gc *conf_v1alpha1.GlobalConfiguration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.valid[gc.Namespace+"/"+gc.Name] = gc
+}
+
+// has checks if the GlobalConfiguration with the key has a valid version.
+func (s *globalConfigurationSet) has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, exists := s.valid[key]
+	return exists
+}
+
+// getKeys returns the keys of all GlobalConfigurations, including the ones without a valid version.
+func (s *globalConfigurationSet) getKeys() []string {
+	return s.keys
+}
+
+// getAll returns the last valid versions of the GlobalConfigurations in the order of their keys.
+func (s *globalConfigurationSet) getAll() []*conf_v1alpha1.GlobalConfiguration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []*conf_v1alpha1.GlobalConfiguration
+	for _, key := range s.keys {
+		if gc, exists := s.valid[key]; exists {
+			result = append(result, gc)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,124 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nginxinc/kubernetes-ingress/internal/configs"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
+	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/validation"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestParseGlobalConfigurations(t *testing.T) {
+	keys, err := ParseGlobalConfigurations("team-a/global-configuration,team-b/global-configuration")
+	if err != nil {
+		t.Fatalf("ParseGlobalConfigurations() returned an unexpected error: %v", err)
+	}
+
+	expected := []string{"team-a/global-configuration", "team-b/global-configuration"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("ParseGlobalConfigurations() returned %v but expected %v", keys, expected)
+	}
+
+	invalidValues := []string{
+		"global-configuration",
+		"team-a/global-configuration,",
+		"team-a/global-configuration,/global-configuration",
+		"team-a/global-configuration,team-a/global-configuration",
+	}
+
+	for _, value := range invalidValues {
+		if _, err := ParseGlobalConfigurations(value); err == nil {
+			t.Errorf("ParseGlobalConfigurations(%q) returned no error", value)
+		}
+	}
+}
+
+func createTestGlobalConfiguration(namespace string, listeners ...conf_v1alpha1.Listener) *conf_v1alpha1.GlobalConfiguration {
+	return &conf_v1alpha1.GlobalConfiguration{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "global-configuration",
+			Namespace: namespace,
+		},
+		Spec: conf_v1alpha1.GlobalConfigurationSpec{
+			Listeners: listeners,
+		},
+	}
+}
+
+func TestSyncGlobalConfigurationMergesListeners(t *testing.T) {
+	teamA := createTestGlobalConfiguration("team-a",
+		conf_v1alpha1.Listener{Name: "dns-tcp", Port: 5353, Protocol: "TCP"})
+	teamB := createTestGlobalConfiguration("team-b",
+		conf_v1alpha1.Listener{Name: "postgres", Port: 5432, Protocol: "TCP"},
+		conf_v1alpha1.Listener{Name: "other-dns-tcp", Port: 5353, Protocol: "TCP"})
+
+	teamALister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	teamBLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := teamALister.Add(teamA); err != nil {
+		t.Fatalf("Failed to add the GlobalConfiguration to the store: %v", err)
+	}
+	if err := teamBLister.Add(teamB); err != nil {
+		t.Fatalf("Failed to add the GlobalConfiguration to the store: %v", err)
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	lbc := &LoadBalancerController{
+		configurator: configs.NewConfigurator(nginx.NewFakeManager("/etc/nginx"), &configs.StaticConfigParams{}, &configs.ConfigParams{},
+			configs.NewDefaultGlobalConfigParams(), &version1.TemplateExecutor{}, &version2.TemplateExecutor{}, false, false),
+		recorder:                     recorder,
+		transportServerLister:        cache.NewStore(cache.MetaNamespaceKeyFunc),
+		globalConfigurationListers:   []cache.Store{teamALister, teamBLister},
+		globalConfigurations:         newGlobalConfigurationSet([]string{"team-a/global-configuration", "team-b/global-configuration"}),
+		globalConfigurationValidator: validation.NewGlobalConfigurationValidator(map[int]string{}),
+	}
+
+	// the GlobalConfiguration of team-a wasn't synced yet, but its listeners take precedence
+	lbc.syncGlobalConfiguration(task{Kind: globalConfiguration, Key: "team-b/global-configuration"})
+
+	for _, l := range []string{"dns-tcp", "postgres"} {
+		if !lbc.configurator.CheckIfListenerExists(&conf_v1alpha1.TransportServerListener{Name: l, Protocol: "TCP"}) {
+			t.Errorf("syncGlobalConfiguration() didn't configure the listener %v", l)
+		}
+	}
+	if lbc.configurator.CheckIfListenerExists(&conf_v1alpha1.TransportServerListener{Name: "other-dns-tcp", Protocol: "TCP"}) {
+		t.Errorf("syncGlobalConfiguration() configured the conflicting listener other-dns-tcp")
+	}
+
+	expected := "Warning ListenerConflict Listener other-dns-tcp of GlobalConfiguration team-b/global-configuration was ignored: " +
+		"port 5353/TCP is already used by a listener of GlobalConfiguration team-a/global-configuration"
+	if event := <-recorder.Events; event != expected {
+		t.Errorf("syncGlobalConfiguration() recorded the event %q but expected %q", event, expected)
+	}
+
+	// an invalid GlobalConfiguration is rejected and its last valid listeners are retained
+	invalidTeamA := createTestGlobalConfiguration("team-a",
+		conf_v1alpha1.Listener{Name: "dns-tcp", Port: 5353, Protocol: "SCTP"})
+	if err := teamALister.Update(invalidTeamA); err != nil {
+		t.Fatalf("Failed to update the GlobalConfiguration in the store: %v", err)
+	}
+
+	lbc.syncGlobalConfiguration(task{Kind: globalConfiguration, Key: "team-b/global-configuration"})
+
+	if !lbc.configurator.CheckIfListenerExists(&conf_v1alpha1.TransportServerListener{Name: "dns-tcp", Protocol: "TCP"}) {
+		t.Errorf("syncGlobalConfiguration() removed the listener dns-tcp of the GlobalConfiguration that became invalid")
+	}
+
+	// a removed GlobalConfiguration is retained too
+	if err := teamALister.Delete(invalidTeamA); err != nil {
+		t.Fatalf("Failed to delete the GlobalConfiguration from the store: %v", err)
+	}
+
+	lbc.syncGlobalConfiguration(task{Kind: globalConfiguration, Key: "team-a/global-configuration"})
+	lbc.syncGlobalConfiguration(task{Kind: globalConfiguration, Key: "team-b/global-configuration"})
+
+	if !lbc.configurator.CheckIfListenerExists(&conf_v1alpha1.TransportServerListener{Name: "dns-tcp", Protocol: "TCP"}) {
+		t.Errorf("syncGlobalConfiguration() removed the listener dns-tcp of the removed GlobalConfiguration")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/golang/glog"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/tools/cache"
 
 	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
@@ -95,6 +96,7 @@ func createIngressHandlers(lbc *LoadBalancerController) cache.ResourceEventHandl
 			ingress := obj.(*v1beta1.Ingress)
 			if !lbc.HasCorrectIngressClass(ingress) {
 				glog.Infof("Ignoring Ingress %v based on Annotation %v", ingress.Name, ingressClassKey)
+				lbc.logIgnoredByClass(ingress)
 				return
 			}
 			glog.V(3).Infof("Adding Ingress: %v", ingress.Name)
@@ -124,18 +126,37 @@ func createIngressHandlers(lbc *LoadBalancerController) cache.ResourceEventHandl
 					return
 				}
 				glog.V(3).Infof("Removing Ingress: %v(Minion) for %v(Master)", ingress.Name, master.Name)
-				lbc.AddSyncQueue(master)
+				lbc.addSyncQueueForIngressDelete(master)
 			} else {
 				glog.V(3).Infof("Removing Ingress: %v", ingress.Name)
-				lbc.AddSyncQueue(obj)
+				lbc.addSyncQueueForIngressDelete(obj)
 			}
 		},
 		UpdateFunc: func(old, current interface{}) {
 			c := current.(*v1beta1.Ingress)
 			o := old.(*v1beta1.Ingress)
 			if !lbc.HasCorrectIngressClass(c) {
+				if lbc.HasCorrectIngressClass(o) {
+					// the Ingress is no longer handled by the Ingress Controller, so its paths go to the other Ingresses
+					glog.V(3).Infof("Ingress %v changed its class, releasing its paths", c.Name)
+					lbc.enqueueIngresses(lbc.ingressPaths.remove(c.Namespace + "/" + c.Name))
+				}
+				return
+			}
+			wasPaused := isIngressPaused(o)
+			isPaused := isIngressPaused(c)
+			if isPaused {
+				if !wasPaused {
+					glog.V(3).Infof("Ingress %v paused", c.Name)
+					lbc.recorder.Eventf(c, v1.EventTypeNormal, "Paused", "Changes of %v/%v will be ignored until the %v annotation is removed", c.Namespace, c.Name, pausedKey)
+				}
+				glog.V(3).Infof("Ingress %v is paused, ignoring changes", c.Name)
 				return
 			}
+			if wasPaused {
+				glog.V(3).Infof("Ingress %v unpaused", c.Name)
+				lbc.recorder.Eventf(c, v1.EventTypeNormal, "Unpaused", "Changes of %v/%v will be applied again", c.Namespace, c.Name)
+			}
 			if hasChanges(o, c) {
 				glog.V(3).Infof("Ingress %v changed, syncing", c.Name)
 				lbc.AddSyncQueue(c)
@@ -318,6 +339,7 @@ func createVirtualServerHandlers(lbc *LoadBalancerController) cache.ResourceEven
 			vs := obj.(*conf_v1.VirtualServer)
 			if !lbc.HasCorrectIngressClass(vs) {
 				glog.Infof("Ignoring VirtualServer %v based on class %v", vs.Name, vs.Spec.IngressClass)
+				lbc.logIgnoredByClass(vs)
 				return
 			}
 			glog.V(3).Infof("Adding VirtualServer: %v", vs.Name)
@@ -365,6 +387,7 @@ func createVirtualServerRouteHandlers(lbc *LoadBalancerController) cache.Resourc
 			vsr := obj.(*conf_v1.VirtualServerRoute)
 			if !lbc.HasCorrectIngressClass(vsr) {
 				glog.Infof("Ignoring VirtualServerRoute %v based on class %v", vsr.Name, vsr.Spec.IngressClass)
+				lbc.logIgnoredByClass(vsr)
 				return
 			}
 			glog.V(3).Infof("Adding VirtualServerRoute: %v", vsr.Name)
@@ -473,3 +496,72 @@ func createTransportServerHandlers(lbc *LoadBalancerController) cache.ResourceEv
 		},
 	}
 }
+
+func createPolicyHandlers(lbc *LoadBalancerController) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			pol := obj.(*conf_v1alpha1.Policy)
+			glog.V(3).Infof("Adding Policy: %v", pol.Name)
+			lbc.AddSyncQueue(pol)
+		},
+		DeleteFunc: func(obj interface{}) {
+			pol, isPol := obj.(*conf_v1alpha1.Policy)
+			if !isPol {
+				deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					glog.V(3).Infof("Error received unexpected object: %v", obj)
+					return
+				}
+				pol, ok = deletedState.Obj.(*conf_v1alpha1.Policy)
+				if !ok {
+					glog.V(3).Infof("Error DeletedFinalStateUnknown contained non-Policy object: %v", deletedState.Obj)
+					return
+				}
+			}
+			glog.V(3).Infof("Removing Policy: %v", pol.Name)
+			lbc.AddSyncQueue(pol)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curPol := cur.(*conf_v1alpha1.Policy)
+			if !reflect.DeepEqual(old, cur) {
+				glog.V(3).Infof("Policy %v changed, syncing", curPol.Name)
+				lbc.AddSyncQueue(curPol)
+			}
+		},
+	}
+}
+
+// createCertificateHandlers builds the handler funcs for cert-manager Certificates.
+func createCertificateHandlers(lbc *LoadBalancerController) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			cert := obj.(*unstructured.Unstructured)
+			glog.V(3).Infof("Adding Certificate: %v", cert.GetName())
+			lbc.AddSyncQueue(cert)
+		},
+		DeleteFunc: func(obj interface{}) {
+			cert, isCert := obj.(*unstructured.Unstructured)
+			if !isCert {
+				deletedState, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					glog.V(3).Infof("Error received unexpected object: %v", obj)
+					return
+				}
+				cert, ok = deletedState.Obj.(*unstructured.Unstructured)
+				if !ok {
+					glog.V(3).Infof("Error DeletedFinalStateUnknown contained non-Certificate object: %v", deletedState.Obj)
+					return
+				}
+			}
+			glog.V(3).Infof("Removing Certificate: %v", cert.GetName())
+			lbc.AddSyncQueue(cert)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			curCert := cur.(*unstructured.Unstructured)
+			if !reflect.DeepEqual(old, cur) {
+				glog.V(3).Infof("Certificate %v changed, syncing", curCert.GetName())
+				lbc.AddSyncQueue(curCert)
+			}
+		},
+	}
+}
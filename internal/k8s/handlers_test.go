@@ -1,10 +1,19 @@
 package k8s
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 )
 
 func TestHasServicePortChanges(t *testing.T) {
@@ -154,3 +163,145 @@ func TestHasServicePortChanges(t *testing.T) {
 		}
 	}
 }
+
+func TestIngressHandlersIgnoreChangesWhilePaused(t *testing.T) {
+	newIngress := func(backend string, paused bool) *v1beta1.Ingress {
+		ing := &v1beta1.Ingress{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:        "cafe-ingress",
+				Namespace:   "default",
+				Annotations: map[string]string{},
+			},
+			Spec: v1beta1.IngressSpec{
+				Backend: &v1beta1.IngressBackend{
+					ServiceName: backend,
+					ServicePort: intstr.FromInt(80),
+				},
+			},
+		}
+		if paused {
+			ing.Annotations[pausedKey] = "true"
+		}
+		return ing
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	lbc := &LoadBalancerController{
+		recorder:  recorder,
+		syncQueue: newTaskQueue(func(task) {}, 1),
+	}
+	handlers := createIngressHandlers(lbc)
+
+	handlers.UpdateFunc(newIngress("tea-svc", false), newIngress("tea-svc", true))
+	handlers.UpdateFunc(newIngress("tea-svc", true), newIngress("coffee-svc", true))
+
+	if l := lbc.syncQueue.queue.Len(); l != 0 {
+		t.Errorf("UpdateFunc() added %d tasks to the queue for a paused Ingress but expected 0", l)
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Normal Paused") {
+		t.Errorf("UpdateFunc() recorded the event %q but expected a Paused event", event)
+	}
+
+	handlers.UpdateFunc(newIngress("coffee-svc", true), newIngress("coffee-svc", false))
+
+	if l := lbc.syncQueue.queue.Len(); l != 1 {
+		t.Errorf("UpdateFunc() added %d tasks to the queue for an unpaused Ingress but expected 1", l)
+	}
+	if event := <-recorder.Events; !strings.HasPrefix(event, "Normal Unpaused") {
+		t.Errorf("UpdateFunc() recorded the event %q but expected an Unpaused event", event)
+	}
+
+	item, _ := lbc.syncQueue.queue.Get()
+	lbc.syncQueue.queue.Done(item)
+
+	handlers.DeleteFunc(newIngress("coffee-svc", true))
+
+	if l := lbc.syncQueue.queue.Len(); l != 1 {
+		t.Errorf("DeleteFunc() added %d tasks to the queue for a paused Ingress but expected 1", l)
+	}
+}
+
+func TestIngressHandlersDelayRemovalDuringGracePeriod(t *testing.T) {
+	ing := &v1beta1.Ingress{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe-ingress",
+			Namespace: "default",
+		},
+	}
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	lbc := &LoadBalancerController{
+		syncQueue:                newTaskQueueWithClock(func(task) {}, 1, fakeClock),
+		ingressDeleteGracePeriod: 30 * time.Second,
+	}
+	defer lbc.syncQueue.queue.ShutDown()
+	handlers := createIngressHandlers(lbc)
+
+	handlers.DeleteFunc(ing)
+
+	fakeClock.Step(29 * time.Second)
+	if l := lbc.syncQueue.queue.Len(); l != 0 {
+		t.Errorf("DeleteFunc() added %d tasks to the queue before the grace period expired but expected 0", l)
+	}
+
+	// the delayed task is added to the queue by the goroutine of the queue once the clock passes the grace period
+	fakeClock.Step(time.Second)
+	err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		return lbc.syncQueue.queue.Len() == 1, nil
+	})
+	if err != nil {
+		t.Errorf("DeleteFunc() added %d tasks to the queue after the grace period expired but expected 1", lbc.syncQueue.queue.Len())
+	}
+}
+
+func TestPolicyHandlers(t *testing.T) {
+	newPolicy := func(allow string) *conf_v1alpha1.Policy {
+		return &conf_v1alpha1.Policy{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "allow-internal",
+				Namespace: "default",
+			},
+			Spec: conf_v1alpha1.PolicySpec{
+				AccessControl: &conf_v1alpha1.AccessControl{
+					Allow: []string{allow},
+				},
+			},
+		}
+	}
+
+	lbc := &LoadBalancerController{
+		syncQueue: newTaskQueue(func(task) {}, 1),
+	}
+	handlers := createPolicyHandlers(lbc)
+
+	expectTask := func(action string) {
+		if l := lbc.syncQueue.queue.Len(); l != 1 {
+			t.Fatalf("%s added %d tasks to the queue but expected 1", action, l)
+		}
+
+		item, _ := lbc.syncQueue.queue.Get()
+		lbc.syncQueue.queue.Done(item)
+
+		expected := task{Kind: policy, Key: "default/allow-internal"}
+		if item.(task) != expected {
+			t.Errorf("%s added the task %+v but expected %+v", action, item, expected)
+		}
+	}
+
+	handlers.AddFunc(newPolicy("10.0.0.0/8"))
+	expectTask("AddFunc()")
+
+	handlers.UpdateFunc(newPolicy("10.0.0.0/8"), newPolicy("10.0.0.0/8"))
+	if l := lbc.syncQueue.queue.Len(); l != 0 {
+		t.Errorf("UpdateFunc() added %d tasks to the queue for an unchanged Policy but expected 0", l)
+	}
+
+	handlers.UpdateFunc(newPolicy("10.0.0.0/8"), newPolicy("192.168.0.0/16"))
+	expectTask("UpdateFunc()")
+
+	handlers.DeleteFunc(newPolicy("192.168.0.0/16"))
+	expectTask("DeleteFunc()")
+
+	handlers.DeleteFunc(cache.DeletedFinalStateUnknown{Key: "default/allow-internal", Obj: newPolicy("192.168.0.0/16")})
+	expectTask("DeleteFunc() with a DeletedFinalStateUnknown")
+}
@@ -0,0 +1,229 @@
+package k8s
+
+import (
+	"sort"
+	"sync"
+
+	extensions "k8s.io/api/extensions/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// hostPath is a path of a host defined in a rule of an Ingress.
+type hostPath struct {
+	host string
+	path string
+}
+
+// ingressClaim is an Ingress that defines a host and path pair.
+type ingressClaim struct {
+	key     string
+	created meta_v1.Time
+}
+
+// olderThan checks if the Ingress of the claim was created before the Ingress of the other claim. The Ingresses
+// created at the same time are ordered by their keys, so that the result is deterministic.
+func (c ingressClaim) olderThan(other ingressClaim) bool {
+	if !c.created.Equal(&other.created) {
+		return c.created.Before(&other.created)
+	}
+	return c.key < other.key
+}
+
+// ingressPathConflict is a host and path pair of an Ingress that an older Ingress defines too.
+type ingressPathConflict struct {
+	host  string
+	path  string
+	owner string
+}
+
+// ingressPathIndex keeps track of the host and path pairs defined by the regular Ingresses, so that when several
+// Ingresses define the same host and path, the oldest Ingress gets the path and the others ignore it.
+// The index is updated every time an Ingress is processed. The index is safe for concurrent use by multiple sync workers.
+type ingressPathIndex struct {
+	mu sync.Mutex
+	// claims maps a host and path pair to the Ingresses that define it, keyed by the Ingress keys.
+	claims map[hostPath]map[string]ingressClaim
+	// paths maps an Ingress key to the host and path pairs it defines.
+	paths map[string]map[hostPath]bool
+	// created maps an Ingress key to its creation time.
+	created map[string]meta_v1.Time
+}
+
+func newIngressPathIndex() *ingressPathIndex {
+	return &ingressPathIndex{
+		claims:  make(map[hostPath]map[string]ingressClaim),
+		paths:   make(map[string]map[hostPath]bool),
+		created: make(map[string]meta_v1.Time),
+	}
+}
+
+// update replaces the host and path pairs of the Ingress with the pairs defined by its rules. If the pairs or the creation
+// time of the Ingress changed, update returns the sorted keys of the other Ingresses that define any of the previous or new
+// pairs, as the change can change the Ingress that gets the pairs.
+func (idx *ingressPathIndex) update(ing *extensions.Ingress) []string {
+	key := ing.Namespace + "/" + ing.Name
+	paths := getIngressHostPaths(ing)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	previous, exists := idx.paths[key]
+	created := idx.created[key]
+	if exists && created.Equal(&ing.CreationTimestamp) && equalHostPaths(previous, paths) {
+		return nil
+	}
+
+	affected := idx.removeLocked(key)
+
+	claim := ingressClaim{key: key, created: ing.CreationTimestamp}
+	for hp := range paths {
+		for otherKey := range idx.claims[hp] {
+			affected[otherKey] = true
+		}
+		if idx.claims[hp] == nil {
+			idx.claims[hp] = make(map[string]ingressClaim)
+		}
+		idx.claims[hp][key] = claim
+	}
+
+	idx.paths[key] = paths
+	idx.created[key] = ing.CreationTimestamp
+
+	return sortedKeys(affected)
+}
+
+// remove removes the Ingress with the key from the index. It returns the sorted keys of the other Ingresses that define
+// any of the pairs of the removed Ingress.
+func (idx *ingressPathIndex) remove(key string) []string {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	return sortedKeys(idx.removeLocked(key))
+}
+
+func (idx *ingressPathIndex) removeLocked(key string) map[string]bool {
+	affected := make(map[string]bool)
+
+	for hp := range idx.paths[key] {
+		delete(idx.claims[hp], key)
+		for otherKey := range idx.claims[hp] {
+			affected[otherKey] = true
+		}
+		if len(idx.claims[hp]) == 0 {
+			delete(idx.claims, hp)
+		}
+	}
+
+	delete(idx.paths, key)
+	delete(idx.created, key)
+
+	return affected
+}
+
+// getConflicts returns the host and path pairs of the Ingress with the key that an older Ingress defines too,
+// sorted by the host and the path.
+func (idx *ingressPathIndex) getConflicts(key string) []ingressPathConflict {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	var conflicts []ingressPathConflict
+
+	for hp := range idx.paths[key] {
+		claims := idx.claims[hp]
+
+		owner := claims[key]
+		for _, c := range claims {
+			if c.olderThan(owner) {
+				owner = c
+			}
+		}
+
+		if owner.key != key {
+			conflicts = append(conflicts, ingressPathConflict{host: hp.host, path: hp.path, owner: owner.key})
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].host != conflicts[j].host {
+			return conflicts[i].host < conflicts[j].host
+		}
+		return conflicts[i].path < conflicts[j].path
+	})
+
+	return conflicts
+}
+
+// getIngressHostPaths returns the host and path pairs defined by the rules of the Ingress.
+func getIngressHostPaths(ing *extensions.Ingress) map[hostPath]bool {
+	paths := make(map[hostPath]bool)
+
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			paths[hostPath{host: rule.Host, path: path.Path}] = true
+		}
+	}
+
+	return paths
+}
+
+// removeConflictingPaths returns a copy of the Ingress without the conflicting paths. A rule left without paths is removed.
+func removeConflictingPaths(ing *extensions.Ingress, conflicts []ingressPathConflict) *extensions.Ingress {
+	conflicting := make(map[hostPath]bool)
+	for _, c := range conflicts {
+		conflicting[hostPath{host: c.host, path: c.path}] = true
+	}
+
+	result := ing.DeepCopy()
+
+	var rules []extensions.IngressRule
+	for _, rule := range result.Spec.Rules {
+		if rule.HTTP == nil {
+			rules = append(rules, rule)
+			continue
+		}
+
+		var paths []extensions.HTTPIngressPath
+		for _, path := range rule.HTTP.Paths {
+			if !conflicting[hostPath{host: rule.Host, path: path.Path}] {
+				paths = append(paths, path)
+			}
+		}
+
+		if len(paths) == 0 {
+			continue
+		}
+
+		rule.HTTP.Paths = paths
+		rules = append(rules, rule)
+	}
+
+	result.Spec.Rules = rules
+
+	return result
+}
+
+func equalHostPaths(a map[hostPath]bool, b map[hostPath]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for hp := range a {
+		if !b[hp] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(keys map[string]bool) []string {
+	var result []string
+	for key := range keys {
+		result = append(result, key)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,247 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nginxinc/kubernetes-ingress/internal/configs"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
+	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
+	extensions "k8s.io/api/extensions/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/cache"
+)
+
+func createTestIngressWithPaths(name string, created time.Time, host string, paths ...string) *extensions.Ingress {
+	var httpPaths []extensions.HTTPIngressPath
+	for _, p := range paths {
+		httpPaths = append(httpPaths, extensions.HTTPIngressPath{
+			Path: p,
+			Backend: extensions.IngressBackend{
+				ServiceName: name + "-svc",
+				ServicePort: intstr.FromInt(80),
+			},
+		})
+	}
+
+	return &extensions.Ingress{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: meta_v1.NewTime(created),
+		},
+		Spec: extensions.IngressSpec{
+			Rules: []extensions.IngressRule{
+				{
+					Host: host,
+					IngressRuleValue: extensions.IngressRuleValue{
+						HTTP: &extensions.HTTPIngressRuleValue{
+							Paths: httpPaths,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestIngressPathIndex(t *testing.T) {
+	now := time.Now()
+
+	cafe := createTestIngressWithPaths("cafe", now.Add(-time.Hour), "cafe.example.com", "/tea", "/coffee")
+	tea := createTestIngressWithPaths("tea", now, "cafe.example.com", "/tea")
+	// the Ingresses created at the same time are ordered by the name
+	bakery := createTestIngressWithPaths("bakery", now, "cafe.example.com", "/tea")
+
+	idx := newIngressPathIndex()
+
+	// the newest Ingress is processed first and gets the path until the older Ingresses are processed
+	if affected := idx.update(tea); affected != nil {
+		t.Errorf("update() returned %v for the first Ingress but expected no affected Ingresses", affected)
+	}
+	if conflicts := idx.getConflicts("default/tea"); conflicts != nil {
+		t.Errorf("getConflicts() returned %v for the only Ingress of the path but expected no conflicts", conflicts)
+	}
+
+	expectedAffected := []string{"default/tea"}
+	if affected := idx.update(cafe); !reflect.DeepEqual(affected, expectedAffected) {
+		t.Errorf("update() returned %v but expected %v", affected, expectedAffected)
+	}
+
+	expectedAffected = []string{"default/cafe", "default/tea"}
+	if affected := idx.update(bakery); !reflect.DeepEqual(affected, expectedAffected) {
+		t.Errorf("update() returned %v but expected %v", affected, expectedAffected)
+	}
+
+	// an update without a change of the paths doesn't affect other Ingresses
+	if affected := idx.update(tea); affected != nil {
+		t.Errorf("update() returned %v for an unchanged Ingress but expected no affected Ingresses", affected)
+	}
+
+	expectedConflicts := []ingressPathConflict{{host: "cafe.example.com", path: "/tea", owner: "default/cafe"}}
+	for _, key := range []string{"default/tea", "default/bakery"} {
+		if conflicts := idx.getConflicts(key); !reflect.DeepEqual(conflicts, expectedConflicts) {
+			t.Errorf("getConflicts() returned %v for %v but expected %v", conflicts, key, expectedConflicts)
+		}
+	}
+	if conflicts := idx.getConflicts("default/cafe"); conflicts != nil {
+		t.Errorf("getConflicts() returned %v for the oldest Ingress but expected no conflicts", conflicts)
+	}
+
+	// after the removal of the oldest Ingress, the path goes to the next oldest Ingress
+	expectedAffected = []string{"default/bakery", "default/tea"}
+	if affected := idx.remove("default/cafe"); !reflect.DeepEqual(affected, expectedAffected) {
+		t.Errorf("remove() returned %v but expected %v", affected, expectedAffected)
+	}
+
+	expectedConflicts = []ingressPathConflict{{host: "cafe.example.com", path: "/tea", owner: "default/bakery"}}
+	if conflicts := idx.getConflicts("default/tea"); !reflect.DeepEqual(conflicts, expectedConflicts) {
+		t.Errorf("getConflicts() returned %v but expected %v", conflicts, expectedConflicts)
+	}
+	if conflicts := idx.getConflicts("default/bakery"); conflicts != nil {
+		t.Errorf("getConflicts() returned %v for the oldest Ingress but expected no conflicts", conflicts)
+	}
+}
+
+func TestCreateIngressWithConflictingPaths(t *testing.T) {
+	now := time.Now()
+
+	cafe := createTestIngressWithPaths("cafe", now.Add(-time.Hour), "cafe.example.com", "/tea", "/coffee")
+	tea := createTestIngressWithPaths("tea", now, "cafe.example.com", "/tea", "/green-tea")
+	coffee := createTestIngressWithPaths("coffee", now, "cafe.example.com", "/coffee")
+
+	ingressLister := storeToIngressLister{Store: cache.NewStore(cache.MetaNamespaceKeyFunc)}
+	for _, ing := range []*extensions.Ingress{cafe, tea, coffee} {
+		err := ingressLister.Add(ing)
+		if err != nil {
+			t.Fatalf("Failed to add the Ingress to the store: %v", err)
+		}
+	}
+
+	lbc := &LoadBalancerController{
+		ingressLister: ingressLister,
+		svcLister:     cache.NewStore(cache.MetaNamespaceKeyFunc),
+		syncQueue:     newTaskQueue(func(task) {}, 1),
+		ingressPaths:  newIngressPathIndex(),
+	}
+
+	lbc.enqueueIngresses(lbc.ingressPaths.update(tea))
+	lbc.enqueueIngresses(lbc.ingressPaths.update(coffee))
+	lbc.enqueueIngresses(lbc.ingressPaths.update(cafe))
+
+	// the older Ingress resyncs the newer Ingresses with the same paths
+	if l := lbc.syncQueue.queue.Len(); l != 2 {
+		t.Errorf("update() of the older Ingress enqueued %d Ingresses but expected 2", l)
+	}
+
+	ingEx, err := lbc.createIngress(tea)
+	if err != nil {
+		t.Fatalf("createIngress() returned an unexpected error: %v", err)
+	}
+
+	var paths []string
+	for _, p := range ingEx.Ingress.Spec.Rules[0].HTTP.Paths {
+		paths = append(paths, p.Path)
+	}
+	expected := []string{"/green-tea"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("createIngress() returned the paths %v but expected %v", paths, expected)
+	}
+	if len(tea.Spec.Rules[0].HTTP.Paths) != 2 {
+		t.Errorf("createIngress() changed the paths of the Ingress from the lister")
+	}
+
+	// the Ingress without other paths has no valid rules
+	_, err = lbc.createIngress(coffee)
+	if err == nil {
+		t.Errorf("createIngress() returned no error for an Ingress with only conflicting paths")
+	}
+
+	ingEx, err = lbc.createIngress(cafe)
+	if err != nil {
+		t.Fatalf("createIngress() returned an unexpected error: %v", err)
+	}
+	if l := len(ingEx.Ingress.Spec.Rules[0].HTTP.Paths); l != 2 {
+		t.Errorf("createIngress() returned %d paths for the oldest Ingress but expected 2", l)
+	}
+}
+
+func TestIngressPathsOfIngressConvertedToMinion(t *testing.T) {
+	now := time.Now()
+
+	cafe := createTestIngressWithPaths("cafe", now.Add(-time.Hour), "cafe.example.com", "/tea")
+	tea := createTestIngressWithPaths("tea", now, "cafe.example.com", "/tea")
+
+	ingressLister := storeToIngressLister{Store: cache.NewStore(cache.MetaNamespaceKeyFunc)}
+	lbc := &LoadBalancerController{
+		ingressLister: ingressLister,
+		configurator: configs.NewConfigurator(nginx.NewFakeManager("/etc/nginx"), &configs.StaticConfigParams{}, &configs.ConfigParams{},
+			&configs.GlobalConfigParams{}, &version1.TemplateExecutor{}, &version2.TemplateExecutor{}, false, false),
+		syncQueue:    newTaskQueue(func(task) {}, 1),
+		ingressPaths: newIngressPathIndex(),
+	}
+
+	lbc.ingressPaths.update(cafe)
+	lbc.ingressPaths.update(tea)
+	if conflicts := lbc.ingressPaths.getConflicts("default/tea"); len(conflicts) != 1 {
+		t.Fatalf("getConflicts() returned %v but expected a conflict with the older Ingress", conflicts)
+	}
+
+	cafeMinion := cafe.DeepCopy()
+	cafeMinion.Annotations = map[string]string{"nginx.org/mergeable-ingress-type": "minion"}
+	for _, ing := range []*extensions.Ingress{cafeMinion, tea} {
+		err := ingressLister.Add(ing)
+		if err != nil {
+			t.Fatalf("Failed to add the Ingress to the store: %v", err)
+		}
+	}
+
+	// the minion has no master, but its paths are released anyway
+	lbc.syncIngMinion(task{Kind: ingressMinion, Key: "default/cafe"})
+
+	if conflicts := lbc.ingressPaths.getConflicts("default/tea"); conflicts != nil {
+		t.Errorf("getConflicts() returned %v after the older Ingress became a minion but expected no conflicts", conflicts)
+	}
+	if l := lbc.syncQueue.queue.Len(); l != 1 {
+		t.Errorf("syncIngMinion() enqueued %d Ingresses but expected the Ingress that gets the paths", l)
+	}
+}
+
+func TestIngressPathsOfIngressWithChangedClass(t *testing.T) {
+	now := time.Now()
+
+	cafe := createTestIngressWithPaths("cafe", now.Add(-time.Hour), "cafe.example.com", "/tea")
+	tea := createTestIngressWithPaths("tea", now, "cafe.example.com", "/tea")
+
+	ingressLister := storeToIngressLister{Store: cache.NewStore(cache.MetaNamespaceKeyFunc)}
+	lbc := &LoadBalancerController{
+		ingressClass:  "nginx",
+		ingressLister: ingressLister,
+		syncQueue:     newTaskQueue(func(task) {}, 1),
+		ingressPaths:  newIngressPathIndex(),
+	}
+
+	lbc.ingressPaths.update(cafe)
+	lbc.ingressPaths.update(tea)
+
+	otherClass := cafe.DeepCopy()
+	otherClass.Annotations = map[string]string{ingressClassKey: "gce"}
+	for _, ing := range []*extensions.Ingress{otherClass, tea} {
+		err := ingressLister.Add(ing)
+		if err != nil {
+			t.Fatalf("Failed to add the Ingress to the store: %v", err)
+		}
+	}
+
+	createIngressHandlers(lbc).UpdateFunc(cafe, otherClass)
+
+	if conflicts := lbc.ingressPaths.getConflicts("default/tea"); conflicts != nil {
+		t.Errorf("getConflicts() returned %v after the older Ingress changed its class but expected no conflicts", conflicts)
+	}
+	if l := lbc.syncQueue.queue.Len(); l != 1 {
+		t.Errorf("UpdateFunc() enqueued %d Ingresses but expected the Ingress that gets the paths", l)
+	}
+}
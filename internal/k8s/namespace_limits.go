@@ -0,0 +1,76 @@
+package k8s
+
+import (
+	"sort"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+)
+
+// filterVirtualServersWithinNamespaceLimit returns the VirtualServers that fit the limit of VirtualServers per namespace.
+// In every namespace, the oldest VirtualServers are kept, so that a new VirtualServer never takes the place of
+// a VirtualServer that already works. A limit of 0 means no limit.
+func filterVirtualServersWithinNamespaceLimit(virtualServers []*conf_v1.VirtualServer, limit int) []*conf_v1.VirtualServer {
+	if limit == 0 {
+		return virtualServers
+	}
+
+	sorted := append([]*conf_v1.VirtualServer{}, virtualServers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, tj := sorted[i].CreationTimestamp, sorted[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	counts := make(map[string]int)
+	allowed := make(map[*conf_v1.VirtualServer]bool)
+	for _, vs := range sorted {
+		if counts[vs.Namespace] < limit {
+			counts[vs.Namespace]++
+			allowed[vs] = true
+		}
+	}
+
+	var result []*conf_v1.VirtualServer
+	for _, vs := range virtualServers {
+		if allowed[vs] {
+			result = append(result, vs)
+		}
+	}
+
+	return result
+}
+
+// isVirtualServerOverNamespaceLimit checks if the VirtualServer doesn't fit the limit of VirtualServers of its namespace.
+func (lbc *LoadBalancerController) isVirtualServerOverNamespaceLimit(vs *conf_v1.VirtualServer) bool {
+	if lbc.maxVirtualServersPerNamespace == 0 {
+		return false
+	}
+
+	for _, v := range lbc.getVirtualServers() {
+		if v.Namespace == vs.Namespace && v.Name == vs.Name {
+			return false
+		}
+	}
+
+	return true
+}
+
+// enqueueVirtualServersWithinNamespaceLimit enqueues the VirtualServers of the namespace that fit the limit but don't
+// have a configuration, so that the VirtualServers rejected because of the limit take the place freed by the deletion
+// or the rejection of another VirtualServer.
+func (lbc *LoadBalancerController) enqueueVirtualServersWithinNamespaceLimit(namespace string) {
+	if lbc.maxVirtualServersPerNamespace == 0 {
+		return
+	}
+
+	for _, vs := range lbc.getVirtualServers() {
+		if vs.Namespace != namespace {
+			continue
+		}
+		if lbc.configurator.GetVirtualServer(vs.Namespace+"/"+vs.Name) == nil {
+			lbc.syncQueue.Enqueue(vs)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func createTestVirtualServerWithCreationTime(namespace string, name string, created time.Time) *conf_v1.VirtualServer {
+	return &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			CreationTimestamp: meta_v1.NewTime(created),
+		},
+		Spec: conf_v1.VirtualServerSpec{
+			Host: name + ".example.com",
+		},
+	}
+}
+
+func TestFilterVirtualServersWithinNamespaceLimit(t *testing.T) {
+	now := time.Now()
+
+	newest := createTestVirtualServerWithCreationTime("tenant-a", "newest", now)
+	oldest := createTestVirtualServerWithCreationTime("tenant-a", "oldest", now.Add(-2*time.Hour))
+	middle := createTestVirtualServerWithCreationTime("tenant-a", "middle", now.Add(-time.Hour))
+	otherNamespace := createTestVirtualServerWithCreationTime("tenant-b", "newest", now)
+
+	virtualServers := []*conf_v1.VirtualServer{newest, oldest, middle, otherNamespace}
+
+	tests := []struct {
+		limit    int
+		expected []*conf_v1.VirtualServer
+		msg      string
+	}{
+		{
+			limit:    0,
+			expected: virtualServers,
+			msg:      "no limit",
+		},
+		{
+			limit:    2,
+			expected: []*conf_v1.VirtualServer{oldest, middle, otherNamespace},
+			msg:      "limit exceeded in one namespace",
+		},
+		{
+			limit:    1,
+			expected: []*conf_v1.VirtualServer{oldest, otherNamespace},
+			msg:      "limit of one VirtualServer",
+		},
+		{
+			limit:    3,
+			expected: virtualServers,
+			msg:      "limit not exceeded",
+		},
+	}
+
+	for _, test := range tests {
+		result := filterVirtualServersWithinNamespaceLimit(virtualServers, test.limit)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("filterVirtualServersWithinNamespaceLimit() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestIsVirtualServerOverNamespaceLimit(t *testing.T) {
+	created := time.Now()
+
+	// the VirtualServers with the same creation time are ordered by the name
+	cafe := createTestVirtualServerWithCreationTime("default", "cafe", created)
+	tea := createTestVirtualServerWithCreationTime("default", "tea", created)
+	invalid := createTestVirtualServerWithCreationTime("default", "a-invalid", created)
+	invalid.Spec.Host = ""
+
+	virtualServerLister := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for _, vs := range []*conf_v1.VirtualServer{cafe, tea, invalid} {
+		err := virtualServerLister.Add(vs)
+		if err != nil {
+			t.Fatalf("Failed to add the VirtualServer to the store: %v", err)
+		}
+	}
+
+	lbc := LoadBalancerController{
+		virtualServerLister:           virtualServerLister,
+		allowSnippets:                 true,
+		maxVirtualServersPerNamespace: 1,
+	}
+
+	if lbc.isVirtualServerOverNamespaceLimit(cafe) {
+		t.Errorf("isVirtualServerOverNamespaceLimit() returned true for the VirtualServer within the limit")
+	}
+	if !lbc.isVirtualServerOverNamespaceLimit(tea) {
+		t.Errorf("isVirtualServerOverNamespaceLimit() returned false for the VirtualServer over the limit")
+	}
+
+	lbc.maxVirtualServersPerNamespace = 0
+	if lbc.isVirtualServerOverNamespaceLimit(tea) {
+		t.Errorf("isVirtualServerOverNamespaceLimit() returned true for a controller without the limit")
+	}
+}
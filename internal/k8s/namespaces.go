@@ -0,0 +1,200 @@
+package k8s
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// newMultiNamespaceInformer creates an informer for every namespace and combines them into a single store and controller.
+// The same handlers are attached to the informers of all namespaces.
+// For a single namespace, including all namespaces (""), it is the same as cache.NewInformer.
+func newMultiNamespaceInformer(namespaces []string, newListWatch func(namespace string) cache.ListerWatcher, objType runtime.Object,
+	resync time.Duration, handlers cache.ResourceEventHandlerFuncs) (cache.Store, cache.Controller) {
+	if len(namespaces) == 1 {
+		return cache.NewInformer(newListWatch(namespaces[0]), objType, resync, handlers)
+	}
+
+	stores := make(map[string]cache.Store)
+	var controllers multiNamespaceController
+
+	for _, ns := range namespaces {
+		store, controller := cache.NewInformer(newListWatch(ns), objType, resync, handlers)
+		stores[ns] = store
+		controllers = append(controllers, controller)
+	}
+
+	return &multiNamespaceStore{stores: stores}, controllers
+}
+
+// multiNamespaceStore is a cache.Store that combines the stores of the informers of several namespaces.
+// Keys of objects include the namespace, so the keys from different stores never collide.
+type multiNamespaceStore struct {
+	stores map[string]cache.Store
+}
+
+func (s *multiNamespaceStore) storeForObject(obj interface{}) (cache.Store, error) {
+	if d, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return s.storeForKey(d.Key)
+	}
+
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	store, exists := s.stores[m.GetNamespace()]
+	if !exists {
+		return nil, fmt.Errorf("namespace %q is not watched", m.GetNamespace())
+	}
+
+	return store, nil
+}
+
+func (s *multiNamespaceStore) storeForKey(key string) (cache.Store, error) {
+	namespace, _, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	store, exists := s.stores[namespace]
+	if !exists {
+		return nil, fmt.Errorf("namespace %q is not watched", namespace)
+	}
+
+	return store, nil
+}
+
+// Add adds the object to the store of its namespace.
+func (s *multiNamespaceStore) Add(obj interface{}) error {
+	store, err := s.storeForObject(obj)
+	if err != nil {
+		return err
+	}
+	return store.Add(obj)
+}
+
+// Update updates the object in the store of its namespace.
+func (s *multiNamespaceStore) Update(obj interface{}) error {
+	store, err := s.storeForObject(obj)
+	if err != nil {
+		return err
+	}
+	return store.Update(obj)
+}
+
+// Delete deletes the object from the store of its namespace.
+func (s *multiNamespaceStore) Delete(obj interface{}) error {
+	store, err := s.storeForObject(obj)
+	if err != nil {
+		return err
+	}
+	return store.Delete(obj)
+}
+
+// List lists the objects of all namespaces.
+func (s *multiNamespaceStore) List() []interface{} {
+	var objs []interface{}
+	for _, store := range s.stores {
+		objs = append(objs, store.List()...)
+	}
+	return objs
+}
+
+// ListKeys lists the keys of the objects of all namespaces.
+func (s *multiNamespaceStore) ListKeys() []string {
+	var keys []string
+	for _, store := range s.stores {
+		keys = append(keys, store.ListKeys()...)
+	}
+	return keys
+}
+
+// Get returns the object from the store of its namespace.
+// Objects from namespaces that are not watched are never found.
+func (s *multiNamespaceStore) Get(obj interface{}) (item interface{}, exists bool, err error) {
+	store, err := s.storeForObject(obj)
+	if err != nil {
+		return nil, false, nil
+	}
+	return store.Get(obj)
+}
+
+// GetByKey returns the object with the key from the store of its namespace.
+// Objects from namespaces that are not watched are never found.
+func (s *multiNamespaceStore) GetByKey(key string) (item interface{}, exists bool, err error) {
+	store, err := s.storeForKey(key)
+	if err != nil {
+		return nil, false, nil
+	}
+	return store.GetByKey(key)
+}
+
+// Replace replaces the contents of the store of every namespace with the objects of that namespace from the list.
+func (s *multiNamespaceStore) Replace(list []interface{}, resourceVersion string) error {
+	lists := make(map[string][]interface{})
+	for ns := range s.stores {
+		lists[ns] = nil
+	}
+
+	for _, obj := range list {
+		m, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		if _, exists := lists[m.GetNamespace()]; !exists {
+			return fmt.Errorf("namespace %q is not watched", m.GetNamespace())
+		}
+		lists[m.GetNamespace()] = append(lists[m.GetNamespace()], obj)
+	}
+
+	for ns, store := range s.stores {
+		err := store.Replace(lists[ns], resourceVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Resync resyncs the stores of all namespaces.
+func (s *multiNamespaceStore) Resync() error {
+	for _, store := range s.stores {
+		err := store.Resync()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// multiNamespaceController is a cache.Controller that runs the controllers of the informers of several namespaces.
+type multiNamespaceController []cache.Controller
+
+// Run runs the controllers of all namespaces until stopCh is closed.
+func (c multiNamespaceController) Run(stopCh <-chan struct{}) {
+	for _, controller := range c {
+		go controller.Run(stopCh)
+	}
+	<-stopCh
+}
+
+// HasSynced returns true if the controllers of all namespaces have synced.
+func (c multiNamespaceController) HasSynced() bool {
+	for _, controller := range c {
+		if !controller.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// LastSyncResourceVersion returns an empty string, because the resource versions of different namespaces
+// can't be combined into one.
+func (c multiNamespaceController) LastSyncResourceVersion() string {
+	return ""
+}
@@ -0,0 +1,101 @@
+package k8s
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestMultiNamespaceInformer(t *testing.T) {
+	newService := func(namespace string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "coffee-svc",
+				Namespace: namespace,
+			},
+		}
+	}
+
+	client := fake.NewSimpleClientset(newService("cafe"), newService("tea"), newService("ignored"))
+
+	newListWatch := func(namespace string) cache.ListerWatcher {
+		return &cache.ListWatch{
+			ListFunc: func(options meta_v1.ListOptions) (runtime.Object, error) {
+				return client.CoreV1().Services(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options meta_v1.ListOptions) (watch.Interface, error) {
+				return client.CoreV1().Services(namespace).Watch(context.TODO(), options)
+			},
+		}
+	}
+
+	queue := newTaskQueue(func(task) {}, 1)
+	handlers := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			queue.Enqueue(obj)
+		},
+	}
+
+	store, controller := newMultiNamespaceInformer([]string{"cafe", "tea"}, newListWatch, &v1.Service{}, 0, handlers)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go controller.Run(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh, controller.HasSynced) {
+		t.Fatal("newMultiNamespaceInformer() returned a controller that failed to sync")
+	}
+
+	keys := store.ListKeys()
+	sort.Strings(keys)
+	expectedKeys := []string{"cafe/coffee-svc", "tea/coffee-svc"}
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("store.ListKeys() returned %v but expected %v", keys, expectedKeys)
+	}
+
+	if l := len(store.List()); l != 2 {
+		t.Errorf("store.List() returned %d objects but expected 2", l)
+	}
+
+	for _, key := range expectedKeys {
+		if _, exists, err := store.GetByKey(key); !exists || err != nil {
+			t.Errorf("store.GetByKey(%q) returned exists %v and error %v but expected the object", key, exists, err)
+		}
+	}
+
+	if _, exists, err := store.GetByKey("ignored/coffee-svc"); exists || err != nil {
+		t.Errorf("store.GetByKey() returned exists %v and error %v for an object of an ignored namespace", exists, err)
+	}
+
+	if _, exists, _ := store.Get(newService("ignored")); exists {
+		t.Errorf("store.Get() found an object of an ignored namespace")
+	}
+
+	// the objects with the same name in different namespaces must not be deduplicated by the queue
+	if l := queue.queue.Len(); l != 2 {
+		t.Errorf("the handlers added %d tasks to the queue but expected 2", l)
+	}
+}
+
+func TestNewMultiNamespaceInformerForSingleNamespace(t *testing.T) {
+	newListWatch := func(namespace string) cache.ListerWatcher {
+		return &cache.ListWatch{}
+	}
+
+	store, controller := newMultiNamespaceInformer([]string{""}, newListWatch, &v1.Service{}, 0, cache.ResourceEventHandlerFuncs{})
+
+	if _, ok := store.(*multiNamespaceStore); ok {
+		t.Errorf("newMultiNamespaceInformer() returned a multi-namespace store for a single namespace")
+	}
+	if _, ok := controller.(multiNamespaceController); ok {
+		t.Errorf("newMultiNamespaceInformer() returned a multi-namespace controller for a single namespace")
+	}
+}
@@ -0,0 +1,108 @@
+package k8s
+
+import (
+	"sort"
+	"sync"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+)
+
+// policyReferenceIndex keeps track of the VirtualServers that reference policies, either in their routes
+// or through the subroutes of their VirtualServerRoutes. The index is updated every time a VirtualServer
+// is processed, so that a Policy change only resyncs the VirtualServers that reference it.
+// The index is safe for concurrent use by multiple sync workers.
+type policyReferenceIndex struct {
+	mu sync.Mutex
+	// virtualServers maps a policy key to the set of the keys of the VirtualServers that reference it.
+	virtualServers map[string]map[string]bool
+	// policies maps a VirtualServer key to the keys of the policies it references.
+	policies map[string][]string
+}
+
+func newPolicyReferenceIndex() *policyReferenceIndex {
+	return &policyReferenceIndex{
+		virtualServers: make(map[string]map[string]bool),
+		policies:       make(map[string][]string),
+	}
+}
+
+// update replaces the policies referenced by the VirtualServer with the policies referenced by its routes
+// and the subroutes of the VirtualServerRoutes.
+func (idx *policyReferenceIndex) update(vs *conf_v1.VirtualServer, vsrs []*conf_v1.VirtualServerRoute) {
+	vsKey := vs.Namespace + "/" + vs.Name
+
+	policyKeys := make(map[string]bool)
+	addPolicyKeys(policyKeys, vs.Spec.Routes, vs.Namespace)
+	for _, vsr := range vsrs {
+		addPolicyKeys(policyKeys, vsr.Spec.Subroutes, vsr.Namespace)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(vsKey)
+
+	if len(policyKeys) == 0 {
+		return
+	}
+
+	var keys []string
+	for polKey := range policyKeys {
+		if idx.virtualServers[polKey] == nil {
+			idx.virtualServers[polKey] = make(map[string]bool)
+		}
+		idx.virtualServers[polKey][vsKey] = true
+		keys = append(keys, polKey)
+	}
+
+	idx.policies[vsKey] = keys
+}
+
+// remove removes the VirtualServer with the key from the index.
+func (idx *policyReferenceIndex) remove(vsKey string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(vsKey)
+}
+
+func (idx *policyReferenceIndex) removeLocked(vsKey string) {
+	for _, polKey := range idx.policies[vsKey] {
+		delete(idx.virtualServers[polKey], vsKey)
+		if len(idx.virtualServers[polKey]) == 0 {
+			delete(idx.virtualServers, polKey)
+		}
+	}
+
+	delete(idx.policies, vsKey)
+}
+
+// getVirtualServerKeys returns the sorted keys of the VirtualServers that reference the policy with the key.
+func (idx *policyReferenceIndex) getVirtualServerKeys(policyKey string) []string {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	var keys []string
+	for vsKey := range idx.virtualServers[policyKey] {
+		keys = append(keys, vsKey)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// addPolicyKeys adds the keys of the policies referenced by the routes to policyKeys.
+// A reference without a namespace refers to a policy in the namespace of the resource of the routes.
+func addPolicyKeys(policyKeys map[string]bool, routes []conf_v1.Route, ownerNamespace string) {
+	for _, r := range routes {
+		for _, p := range r.Policies {
+			namespace := p.Namespace
+			if namespace == "" {
+				namespace = ownerNamespace
+			}
+
+			policyKeys[namespace+"/"+p.Name] = true
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPolicyReferenceIndex(t *testing.T) {
+	newVirtualServer := func(namespace string, name string, policies []conf_v1.PolicyReference) *conf_v1.VirtualServer {
+		return &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Routes: []conf_v1.Route{
+					{
+						Path:     "/",
+						Policies: policies,
+					},
+				},
+			},
+		}
+	}
+
+	vs1 := newVirtualServer("ns-1", "vs-1", []conf_v1.PolicyReference{{Name: "test-policy"}})
+	vs2 := newVirtualServer("ns-2", "vs-2", []conf_v1.PolicyReference{{Name: "test-policy", Namespace: "ns-1"}})
+	vs3 := newVirtualServer("ns-2", "vs-3", []conf_v1.PolicyReference{{Name: "test-policy"}})
+	vs4 := newVirtualServer("ns-1", "vs-4", nil)
+
+	vsr := &conf_v1.VirtualServerRoute{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "vsr-1",
+			Namespace: "ns-1",
+		},
+		Spec: conf_v1.VirtualServerRouteSpec{
+			Subroutes: []conf_v1.Route{
+				{
+					Path:     "/",
+					Policies: []conf_v1.PolicyReference{{Name: "test-policy"}},
+				},
+			},
+		},
+	}
+
+	idx := newPolicyReferenceIndex()
+	idx.update(vs1, nil)
+	idx.update(vs2, nil)
+	idx.update(vs3, nil)
+	idx.update(vs4, []*conf_v1.VirtualServerRoute{vsr})
+
+	expected := []string{"ns-1/vs-1", "ns-1/vs-4", "ns-2/vs-2"}
+	result := idx.getVirtualServerKeys("ns-1/test-policy")
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("getVirtualServerKeys() returned %v but expected %v", result, expected)
+	}
+
+	// vs-4 no longer references the VirtualServerRoute
+	idx.update(vs4, nil)
+	idx.remove("ns-2/vs-2")
+
+	expected = []string{"ns-1/vs-1"}
+	result = idx.getVirtualServerKeys("ns-1/test-policy")
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("getVirtualServerKeys() after the update and removal returned %v but expected %v", result, expected)
+	}
+
+	idx.remove("ns-1/vs-1")
+	idx.remove("ns-2/vs-3")
+
+	if len(idx.virtualServers) != 0 || len(idx.policies) != 0 {
+		t.Errorf("the index is not empty after the removal of all VirtualServers: %v, %v", idx.virtualServers, idx.policies)
+	}
+}
@@ -0,0 +1,69 @@
+package k8s
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// readyStatusEndpoint is the path of the endpoint that reports whether the Ingress Controller is ready.
+const readyStatusEndpoint = "/nginx-ready"
+
+// IsReady returns true if the Ingress Controller is ready to receive traffic.
+func (lbc *LoadBalancerController) IsReady() bool {
+	lbc.readyMu.Lock()
+	defer lbc.readyMu.Unlock()
+
+	return lbc.isReady
+}
+
+func (lbc *LoadBalancerController) setReady(ready bool) {
+	lbc.readyMu.Lock()
+	defer lbc.readyMu.Unlock()
+
+	lbc.isReady = ready
+}
+
+// ShutdownWithDelay shuts down the controller in the order that avoids dropped requests:
+// it reports the Ingress Controller as not ready, waits for the delay, so that the load balancers stop
+// sending traffic to it, and then stops the controller once the sync queue is drained.
+func (lbc *LoadBalancerController) ShutdownWithDelay(delay time.Duration) {
+	lbc.setReady(false)
+
+	if delay > 0 {
+		glog.Infof("Reporting the Ingress Controller as not ready and waiting %v before shutting down", delay)
+		time.Sleep(delay)
+	}
+
+	glog.Infof("Draining the sync queue")
+	lbc.Stop()
+}
+
+// readyStatusHandler handles the requests to the ready status endpoint.
+type readyStatusHandler struct {
+	lbc *LoadBalancerController
+}
+
+func (h *readyStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.lbc.IsReady() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	_, err := fmt.Fprintln(w, "ready")
+	if err != nil {
+		glog.Warningf("Error while sending a response for the ready status endpoint: %v", err)
+	}
+}
+
+// RunReadyStatusListener runs an http server with the endpoint that reports whether the Ingress Controller is ready.
+func RunReadyStatusListener(port int, lbc *LoadBalancerController) {
+	mux := http.NewServeMux()
+	mux.Handle(readyStatusEndpoint, &readyStatusHandler{lbc: lbc})
+
+	address := fmt.Sprintf(":%v", port)
+	glog.Infof("Starting ready status listener on: %v%v", address, readyStatusEndpoint)
+	glog.Fatal("Error in ready status listener server: ", http.ListenAndServe(address, mux))
+}
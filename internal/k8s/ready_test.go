@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReadyStatusHandler(t *testing.T) {
+	tests := []struct {
+		ready          bool
+		expectedStatus int
+	}{
+		{
+			ready:          true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			ready:          false,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		lbc := &LoadBalancerController{}
+		lbc.setReady(test.ready)
+		handler := &readyStatusHandler{lbc: lbc}
+
+		req := httptest.NewRequest(http.MethodGet, readyStatusEndpoint, nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != test.expectedStatus {
+			t.Errorf("readyStatusHandler returned status %d but expected %d when ready is %v", rec.Code, test.expectedStatus, test.ready)
+		}
+	}
+}
+
+func TestShutdownWithDelay(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+
+	addEvent := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	lbc := &LoadBalancerController{}
+	lbc.ctx, lbc.cancel = context.WithCancel(context.Background())
+	lbc.syncQueue = newTaskQueue(func(t task) {
+		if lbc.IsReady() {
+			addEvent("synced " + t.Key + " while ready")
+			return
+		}
+		addEvent("synced " + t.Key + " while not ready")
+	}, 1)
+	lbc.setReady(true)
+
+	go lbc.syncQueue.Run(time.Millisecond, lbc.ctx.Done())
+
+	done := make(chan struct{})
+	go func() {
+		lbc.ShutdownWithDelay(200 * time.Millisecond)
+		addEvent("stopped")
+		close(done)
+	}()
+
+	// resources changed during the delay must still be processed
+	time.Sleep(50 * time.Millisecond)
+	if lbc.IsReady() {
+		t.Errorf("ShutdownWithDelay() didn't report the Ingress Controller as not ready during the delay")
+	}
+	lbc.syncQueue.Enqueue(&conf_v1.VirtualServer{ObjectMeta: meta_v1.ObjectMeta{Name: "cafe", Namespace: "default"}})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("ShutdownWithDelay() didn't return")
+	}
+
+	expected := []string{"synced default/cafe while not ready", "stopped"}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(events) != len(expected) {
+		t.Fatalf("ShutdownWithDelay() resulted in the events %v but expected %v", events, expected)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("ShutdownWithDelay() resulted in the events %v but expected %v", events, expected)
+			break
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package k8s
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/golang/glog"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	extensions "k8s.io/api/extensions/v1beta1"
+)
+
+// resourceLocks serializes the tasks that generate the config of the same resource when the tasks are synced
+// concurrently by several workers, like the task of an Ingress and the task of the Endpoints of its service.
+// A task locks the resources before it reads them from the listers, so that the config generated from an older
+// state of a resource never overwrites the config generated from a newer one.
+// The zero value is ready to use.
+type resourceLocks struct {
+	mu    sync.Mutex
+	locks map[string]*resourceLock
+}
+
+// resourceLock is the lock of a resource with the number of the tasks that hold it or wait for it.
+type resourceLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks the resources with the keys and returns the function that unlocks them. The resources are locked
+// in the order of their keys, so that the tasks that lock several resources never deadlock.
+func (rl *resourceLocks) lock(keys []string) (unlock func()) {
+	sortedKeys := make([]string, 0, len(keys))
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			sortedKeys = append(sortedKeys, key)
+		}
+	}
+	sort.Strings(sortedKeys)
+
+	var locks []*resourceLock
+
+	rl.mu.Lock()
+	if rl.locks == nil {
+		rl.locks = make(map[string]*resourceLock)
+	}
+	for _, key := range sortedKeys {
+		l, exists := rl.locks[key]
+		if !exists {
+			l = &resourceLock{}
+			rl.locks[key] = l
+		}
+		l.refs++
+		locks = append(locks, l)
+	}
+	rl.mu.Unlock()
+
+	for _, l := range locks {
+		l.mu.Lock()
+	}
+
+	return func() {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+
+		for i := len(locks) - 1; i >= 0; i-- {
+			locks[i].mu.Unlock()
+			locks[i].refs--
+			if locks[i].refs == 0 {
+				delete(rl.locks, sortedKeys[i])
+			}
+		}
+	}
+}
+
+func getIngressLockKey(key string) string {
+	return "Ingress/" + key
+}
+
+func getVirtualServerLockKey(key string) string {
+	return "VirtualServer/" + key
+}
+
+func getTransportServerLockKey(key string) string {
+	return "TransportServer/" + key
+}
+
+// lockResources locks the resources whose configs include the Ingresses, the VirtualServers and the TransportServers
+// and returns the function that unlocks them. The config of a minion is a part of the config of its master.
+func (lbc *LoadBalancerController) lockResources(ings []extensions.Ingress, virtualServers []*conf_v1.VirtualServer,
+	transportServers []*conf_v1alpha1.TransportServer) (unlock func()) {
+	var keys []string
+
+	for i := range ings {
+		ing := &ings[i]
+		if isMinion(ing) {
+			master, err := lbc.FindMasterForMinion(ing)
+			if err != nil {
+				glog.V(3).Infof("Not locking Ingress %v/%v(Minion): %v", ing.Namespace, ing.Name, err)
+				continue
+			}
+			ing = master
+		}
+		keys = append(keys, getIngressLockKey(ing.Namespace+"/"+ing.Name))
+	}
+
+	for _, vs := range virtualServers {
+		keys = append(keys, getVirtualServerLockKey(vs.Namespace+"/"+vs.Name))
+	}
+
+	for _, ts := range transportServers {
+		keys = append(keys, getTransportServerLockKey(ts.Namespace+"/"+ts.Name))
+	}
+
+	return lbc.resourceLocks.lock(keys)
+}
+
+// getLatestIngresses returns the current versions of the Ingresses from the lister, so that the config of a locked
+// Ingress is generated from its current state. The deleted Ingresses are skipped.
+func (lbc *LoadBalancerController) getLatestIngresses(ings []extensions.Ingress) []extensions.Ingress {
+	var latest []extensions.Ingress
+
+	for _, ing := range ings {
+		current, exists, err := lbc.ingressLister.GetByKeySafe(ing.Namespace + "/" + ing.Name)
+		if err != nil || !exists {
+			continue
+		}
+		latest = append(latest, *current)
+	}
+
+	return latest
+}
+
+// getLatestVirtualServers returns the current versions of the VirtualServers from the lister.
+// The deleted VirtualServers are skipped.
+func (lbc *LoadBalancerController) getLatestVirtualServers(virtualServers []*conf_v1.VirtualServer) []*conf_v1.VirtualServer {
+	var latest []*conf_v1.VirtualServer
+
+	for _, vs := range virtualServers {
+		obj, exists, err := lbc.virtualServerLister.GetByKey(vs.Namespace + "/" + vs.Name)
+		if err != nil || !exists {
+			continue
+		}
+		latest = append(latest, obj.(*conf_v1.VirtualServer))
+	}
+
+	return latest
+}
+
+// getLatestTransportServers returns the current versions of the TransportServers from the lister.
+// The deleted TransportServers are skipped.
+func (lbc *LoadBalancerController) getLatestTransportServers(transportServers []*conf_v1alpha1.TransportServer) []*conf_v1alpha1.TransportServer {
+	var latest []*conf_v1alpha1.TransportServer
+
+	for _, ts := range transportServers {
+		obj, exists, err := lbc.transportServerLister.GetByKey(ts.Namespace + "/" + ts.Name)
+		if err != nil || !exists {
+			continue
+		}
+		latest = append(latest, obj.(*conf_v1alpha1.TransportServer))
+	}
+
+	return latest
+}
@@ -0,0 +1,160 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nginxinc/kubernetes-ingress/internal/configs"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version1"
+	"github.com/nginxinc/kubernetes-ingress/internal/configs/version2"
+	"github.com/nginxinc/kubernetes-ingress/internal/nginx"
+	v1 "k8s.io/api/core/v1"
+	extensions "k8s.io/api/extensions/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+// waitForResourceLockRefs waits until the number of the tasks that hold or wait for the lock of the resource
+// reaches refs.
+func waitForResourceLockRefs(t *testing.T, rl *resourceLocks, key string, refs int) {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(time.Millisecond) {
+		rl.mu.Lock()
+		l, exists := rl.locks[key]
+		current := 0
+		if exists {
+			current = l.refs
+		}
+		rl.mu.Unlock()
+
+		if current == refs {
+			return
+		}
+	}
+	t.Fatalf("The lock of %v didn't reach %d holders and waiters", key, refs)
+}
+
+func TestResourceLocks(t *testing.T) {
+	var rl resourceLocks
+
+	unlock := rl.lock([]string{"Ingress/default/cafe", "VirtualServer/default/cafe"})
+
+	// a task that locks other resources doesn't wait
+	rl.lock([]string{"Ingress/default/tea", "Ingress/default/tea"})()
+
+	locked := make(chan struct{})
+	go func() {
+		rl.lock([]string{"VirtualServer/default/cafe", "TransportServer/default/cafe"})()
+		close(locked)
+	}()
+
+	waitForResourceLockRefs(t, &rl, "VirtualServer/default/cafe", 2)
+	select {
+	case <-locked:
+		t.Fatalf("lock() locked a resource that is locked by another task")
+	default:
+	}
+
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("lock() didn't lock a resource after it was unlocked by another task")
+	}
+
+	if len(rl.locks) != 0 {
+		t.Errorf("resourceLocks kept the locks %v of the unlocked resources", rl.locks)
+	}
+}
+
+func TestSyncEndpointWaitsForIngressTask(t *testing.T) {
+	templateExecutor, err := version1.NewTemplateExecutor("../configs/version1/nginx.tmpl", "../configs/version1/nginx.ingress.tmpl")
+	if err != nil {
+		t.Fatalf("templateExecutor could not start: %v", err)
+	}
+	templateExecutorV2, err := version2.NewTemplateExecutor("../configs/version2/nginx.virtualserver.tmpl", "../configs/version2/nginx.transportserver.tmpl")
+	if err != nil {
+		t.Fatalf("templateExecutorV2 could not start: %v", err)
+	}
+
+	cnf := configs.NewConfigurator(nginx.NewFakeManager("/etc/nginx"), &configs.StaticConfigParams{}, configs.NewDefaultConfigParams(),
+		configs.NewDefaultGlobalConfigParams(), templateExecutor, templateExecutorV2, false, false)
+
+	lbc := &LoadBalancerController{
+		ingressClass:   "nginx",
+		configurator:   cnf,
+		syncWorkers:    2,
+		ingressLister:  storeToIngressLister{Store: cache.NewStore(cache.MetaNamespaceKeyFunc)},
+		svcLister:      cache.NewStore(cache.MetaNamespaceKeyFunc),
+		endpointLister: storeToEndpointLister{Store: cache.NewStore(cache.MetaNamespaceKeyFunc)},
+		statusUpdater:  &statusUpdater{},
+		ingressPaths:   newIngressPathIndex(),
+		recorder:       record.NewFakeRecorder(10),
+	}
+
+	newIngress := func(path string) *extensions.Ingress {
+		ing := createTestIngressWithPaths("cafe", time.Now(), "cafe.example.com", path)
+		ing.Spec.Rules[0].HTTP.Paths[0].Backend.ServiceName = "tea-svc"
+		return ing
+	}
+
+	err = cnf.AddOrUpdateIngress(&configs.IngressEx{
+		Ingress:   newIngress("/tea"),
+		Endpoints: map[string][]string{"tea-svc80": {"10.0.0.1:8080"}},
+	})
+	if err != nil {
+		t.Fatalf("AddOrUpdateIngress() returned an unexpected error: %v", err)
+	}
+
+	lbc.ingressLister.Add(newIngress("/tea"))
+	lbc.svcLister.Add(&v1.Service{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "tea-svc", Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}},
+		},
+	})
+	lbc.endpointLister.Add(&v1.Endpoints{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "tea-svc", Namespace: "default"},
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses: []v1.EndpointAddress{{IP: "10.0.0.2"}},
+				Ports:     []v1.EndpointPort{{Port: 8080}},
+			},
+		},
+	})
+
+	// the task of the Ingress is in the middle of applying a new path when the task of the Endpoints starts
+	unlock := lbc.resourceLocks.lock([]string{getIngressLockKey("default/cafe")})
+
+	synced := make(chan struct{})
+	go func() {
+		lbc.sync(task{Kind: endpoints, Key: "default/tea-svc"})
+		close(synced)
+	}()
+
+	// the task of the Endpoints has found the Ingress with the old path and waits for the task of the Ingress
+	waitForResourceLockRefs(t, &lbc.resourceLocks, getIngressLockKey("default/cafe"), 2)
+
+	lbc.ingressLister.Update(newIngress("/green-tea"))
+	err = cnf.AddOrUpdateIngress(&configs.IngressEx{
+		Ingress:   newIngress("/green-tea"),
+		Endpoints: map[string][]string{"tea-svc80": {"10.0.0.1:8080"}},
+	})
+	if err != nil {
+		t.Fatalf("AddOrUpdateIngress() returned an unexpected error: %v", err)
+	}
+	unlock()
+
+	select {
+	case <-synced:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The sync of the Endpoints didn't finish after the Ingress was unlocked")
+	}
+
+	if path := cnf.GetIngress("default/cafe").Spec.Rules[0].HTTP.Paths[0].Path; path != "/green-tea" {
+		t.Errorf("The sync of the Endpoints applied the path %q of an older version of the Ingress but expected /green-tea", path)
+	}
+}
@@ -0,0 +1,113 @@
+package k8s
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/golang/glog"
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	extensions "k8s.io/api/extensions/v1beta1"
+)
+
+// resyncEndpoint is the path of the endpoint that triggers a full resync.
+const resyncEndpoint = "/resync"
+
+// EnqueueAllResources enqueues all the resources handled by the Ingress Controller, so that the configuration
+// for every resource is generated again. It returns the number of enqueued resources.
+func (lbc *LoadBalancerController) EnqueueAllResources() int {
+	count := 0
+
+	for _, obj := range lbc.ingressLister.Store.List() {
+		ing := obj.(*extensions.Ingress)
+		if !lbc.HasCorrectIngressClass(ing) {
+			continue
+		}
+		lbc.syncQueue.Enqueue(ing)
+		count++
+	}
+
+	if !lbc.areCustomResourcesEnabled {
+		return count
+	}
+
+	for _, obj := range lbc.virtualServerLister.List() {
+		vs := obj.(*conf_v1.VirtualServer)
+		if !lbc.HasCorrectIngressClass(vs) {
+			continue
+		}
+		lbc.syncQueue.Enqueue(vs)
+		count++
+	}
+
+	for _, obj := range lbc.virtualServerRouteLister.List() {
+		vsr := obj.(*conf_v1.VirtualServerRoute)
+		if !lbc.HasCorrectIngressClass(vsr) {
+			continue
+		}
+		lbc.syncQueue.Enqueue(vsr)
+		count++
+	}
+
+	for _, obj := range lbc.transportServerLister.List() {
+		lbc.syncQueue.Enqueue(obj.(*conf_v1alpha1.TransportServer))
+		count++
+	}
+
+	for _, obj := range lbc.policyLister.List() {
+		lbc.syncQueue.Enqueue(obj.(*conf_v1alpha1.Policy))
+		count++
+	}
+
+	if lbc.watchGlobalConfiguration {
+		for _, lister := range lbc.globalConfigurationListers {
+			for _, obj := range lister.List() {
+				lbc.syncQueue.Enqueue(obj.(*conf_v1alpha1.GlobalConfiguration))
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// resyncHandler handles the requests to the resync endpoint.
+// A request must use the POST method and include the token in the Authorization header as a Bearer token.
+type resyncHandler struct {
+	lbc   *LoadBalancerController
+	token string
+}
+
+func (h *resyncHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	expected := "Bearer " + h.token
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	count := h.lbc.EnqueueAllResources()
+	glog.Infof("Enqueued %d resources for a full resync requested through the resync endpoint", count)
+
+	w.WriteHeader(http.StatusAccepted)
+	_, err := fmt.Fprintf(w, "enqueued %d resources\n", count)
+	if err != nil {
+		glog.Warningf("Error while sending a response for the resync endpoint: %v", err)
+	}
+}
+
+// RunResyncListener runs an http server with the endpoint that triggers a full resync of all resources.
+func RunResyncListener(port int, token string, lbc *LoadBalancerController) {
+	mux := http.NewServeMux()
+	mux.Handle(resyncEndpoint, &resyncHandler{lbc: lbc, token: token})
+
+	address := fmt.Sprintf(":%v", port)
+	glog.Infof("Starting resync listener on: %v%v", address, resyncEndpoint)
+	glog.Fatal("Error in resync listener server: ", http.ListenAndServe(address, mux))
+}
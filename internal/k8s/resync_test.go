@@ -0,0 +1,167 @@
+package k8s
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
+	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	extensions "k8s.io/api/extensions/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func createResyncTestController() *LoadBalancerController {
+	lbc := &LoadBalancerController{
+		ingressClass:              "nginx",
+		areCustomResourcesEnabled: true,
+		watchGlobalConfiguration:  true,
+		syncQueue:                 newTaskQueue(func(task) {}, 1),
+		ingressLister:             storeToIngressLister{Store: cache.NewStore(cache.MetaNamespaceKeyFunc)},
+		virtualServerLister:       cache.NewStore(cache.MetaNamespaceKeyFunc),
+		virtualServerRouteLister:  cache.NewStore(cache.MetaNamespaceKeyFunc),
+		transportServerLister:     cache.NewStore(cache.MetaNamespaceKeyFunc),
+		policyLister:              cache.NewStore(cache.MetaNamespaceKeyFunc),
+		globalConfigurationListers: []cache.Store{
+			cache.NewStore(cache.MetaNamespaceKeyFunc),
+		},
+	}
+
+	objMeta := meta_v1.ObjectMeta{
+		Name:      "cafe",
+		Namespace: "default",
+	}
+
+	lbc.ingressLister.Add(&extensions.Ingress{ObjectMeta: objMeta})
+	lbc.ingressLister.Add(&extensions.Ingress{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        "other-class",
+			Namespace:   "default",
+			Annotations: map[string]string{ingressClassKey: "other"},
+		},
+	})
+	lbc.virtualServerLister.Add(&conf_v1.VirtualServer{ObjectMeta: objMeta})
+	lbc.virtualServerRouteLister.Add(&conf_v1.VirtualServerRoute{ObjectMeta: objMeta})
+	lbc.transportServerLister.Add(&conf_v1alpha1.TransportServer{ObjectMeta: objMeta})
+	lbc.policyLister.Add(&conf_v1alpha1.Policy{ObjectMeta: objMeta})
+	lbc.globalConfigurationListers[0].Add(&conf_v1alpha1.GlobalConfiguration{ObjectMeta: objMeta})
+
+	return lbc
+}
+
+func getQueuedTasks(lbc *LoadBalancerController) map[task]bool {
+	tasks := make(map[task]bool)
+
+	for lbc.syncQueue.queue.Len() > 0 {
+		item, _ := lbc.syncQueue.queue.Get()
+		lbc.syncQueue.queue.Done(item)
+		tasks[item.(task)] = true
+	}
+
+	return tasks
+}
+
+func TestEnqueueAllResources(t *testing.T) {
+	lbc := createResyncTestController()
+
+	count := lbc.EnqueueAllResources()
+
+	expected := map[task]bool{
+		{Kind: ingress, Key: "default/cafe"}:             true,
+		{Kind: virtualserver, Key: "default/cafe"}:       true,
+		{Kind: virtualServerRoute, Key: "default/cafe"}:  true,
+		{Kind: transportserver, Key: "default/cafe"}:     true,
+		{Kind: policy, Key: "default/cafe"}:              true,
+		{Kind: globalConfiguration, Key: "default/cafe"}: true,
+	}
+
+	if count != len(expected) {
+		t.Errorf("EnqueueAllResources() returned %d but expected %d", count, len(expected))
+	}
+
+	result := getQueuedTasks(lbc)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("EnqueueAllResources() added the tasks %v but expected %v", result, expected)
+	}
+}
+
+func TestEnqueueAllResourcesWithoutCustomResources(t *testing.T) {
+	lbc := createResyncTestController()
+	lbc.areCustomResourcesEnabled = false
+
+	count := lbc.EnqueueAllResources()
+
+	expected := map[task]bool{
+		{Kind: ingress, Key: "default/cafe"}: true,
+	}
+
+	if count != len(expected) {
+		t.Errorf("EnqueueAllResources() returned %d but expected %d", count, len(expected))
+	}
+
+	result := getQueuedTasks(lbc)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("EnqueueAllResources() added the tasks %v but expected %v", result, expected)
+	}
+}
+
+func TestResyncHandler(t *testing.T) {
+	tests := []struct {
+		method         string
+		authorization  string
+		expectedStatus int
+		expectedTasks  int
+		msg            string
+	}{
+		{
+			method:         http.MethodPost,
+			authorization:  "Bearer secret-token",
+			expectedStatus: http.StatusAccepted,
+			expectedTasks:  6,
+			msg:            "valid request",
+		},
+		{
+			method:         http.MethodGet,
+			authorization:  "Bearer secret-token",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedTasks:  0,
+			msg:            "wrong method",
+		},
+		{
+			method:         http.MethodPost,
+			authorization:  "Bearer wrong-token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedTasks:  0,
+			msg:            "wrong token",
+		},
+		{
+			method:         http.MethodPost,
+			authorization:  "",
+			expectedStatus: http.StatusUnauthorized,
+			expectedTasks:  0,
+			msg:            "missing token",
+		},
+	}
+
+	for _, test := range tests {
+		lbc := createResyncTestController()
+		handler := &resyncHandler{lbc: lbc, token: "secret-token"}
+
+		req := httptest.NewRequest(test.method, resyncEndpoint, nil)
+		if test.authorization != "" {
+			req.Header.Set("Authorization", test.authorization)
+		}
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != test.expectedStatus {
+			t.Errorf("resyncHandler returned status %d but expected %d for the case of %s", rec.Code, test.expectedStatus, test.msg)
+		}
+		if l := lbc.syncQueue.queue.Len(); l != test.expectedTasks {
+			t.Errorf("resyncHandler added %d tasks to the queue but expected %d for the case of %s", l, test.expectedTasks, test.msg)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"fmt"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 )
@@ -9,11 +10,28 @@ import (
 // JWTKeyKey is the key of the data field of a Secret where the JWK must be stored.
 const JWTKeyKey = "jwk"
 
+// OIDCClientSecretKey is the key of the data field of a Secret where the OIDC client secret must be stored.
+const OIDCClientSecretKey = "client-secret"
+
+// SessionTicketKeyKey is the key of the data field of a Secret where the TLS session ticket key must be stored.
+const SessionTicketKeyKey = "ticket-key"
+
+// validSessionTicketKeySizes includes the sizes of the TLS session ticket keys that NGINX supports:
+// 48 bytes for the AES256 encryption and 80 bytes for the AES256 and AES128 encryption.
+var validSessionTicketKeySizes = map[int]bool{
+	48: true,
+	80: true,
+}
+
 const (
 	// TLS Secret
 	TLS = iota
 	// JWK Secret
 	JWK
+	// OIDC Secret
+	OIDC
+	// SessionTicketKey Secret
+	SessionTicketKey
 )
 
 // ValidateTLSSecret validates the secret. If it is valid, the function returns nil.
@@ -29,6 +47,16 @@ func ValidateTLSSecret(secret *v1.Secret) error {
 	return nil
 }
 
+// ValidateDefaultServerTLSSecret validates the secret of the default server. Unlike the TLS secrets of the resources,
+// the secret must be of the kubernetes.io/tls type. If it is valid, the function returns nil.
+func ValidateDefaultServerTLSSecret(secret *v1.Secret) error {
+	if secret.Type != v1.SecretTypeTLS {
+		return fmt.Errorf("Secret must be of the type %v", v1.SecretTypeTLS)
+	}
+
+	return ValidateTLSSecret(secret)
+}
+
 // ValidateJWKSecret validates the secret. If it is valid, the function returns nil.
 func ValidateJWKSecret(secret *v1.Secret) error {
 	if _, exists := secret.Data[JWTKeyKey]; !exists {
@@ -38,6 +66,40 @@ func ValidateJWKSecret(secret *v1.Secret) error {
 	return nil
 }
 
+// ValidateOIDCSecret validates the secret. If it is valid, the function returns nil.
+// The client secret is a part of the NGINX configuration, so it must not include the characters that
+// NGINX interprets in the value of a variable.
+func ValidateOIDCSecret(secret *v1.Secret) error {
+	clientSecret, exists := secret.Data[OIDCClientSecretKey]
+	if !exists {
+		return fmt.Errorf("Secret doesn't have %v", OIDCClientSecretKey)
+	}
+
+	if len(clientSecret) == 0 {
+		return fmt.Errorf("Secret has an empty %v", OIDCClientSecretKey)
+	}
+
+	if strings.ContainsAny(string(clientSecret), " \t\r\n\"'$\\;{}") {
+		return fmt.Errorf("Secret has an invalid %v: it must not include any whitespace character, `\"`, `'`, `$`, `\\`, `;`, `{` or `}`", OIDCClientSecretKey)
+	}
+
+	return nil
+}
+
+// ValidateSessionTicketKeySecret validates the secret. If it is valid, the function returns nil.
+func ValidateSessionTicketKeySecret(secret *v1.Secret) error {
+	key, exists := secret.Data[SessionTicketKeyKey]
+	if !exists {
+		return fmt.Errorf("Secret doesn't have %v", SessionTicketKeyKey)
+	}
+
+	if !validSessionTicketKeySizes[len(key)] {
+		return fmt.Errorf("Secret has an invalid %v: it must be 48 or 80 bytes long, got %v bytes", SessionTicketKeyKey, len(key))
+	}
+
+	return nil
+}
+
 // GetSecretKind returns the kind of the Secret.
 func GetSecretKind(secret *v1.Secret) (int, error) {
 	if err := ValidateTLSSecret(secret); err == nil {
@@ -46,6 +108,12 @@ func GetSecretKind(secret *v1.Secret) (int, error) {
 	if err := ValidateJWKSecret(secret); err == nil {
 		return JWK, nil
 	}
+	if err := ValidateOIDCSecret(secret); err == nil {
+		return OIDC, nil
+	}
+	if err := ValidateSessionTicketKeySecret(secret); err == nil {
+		return SessionTicketKey, nil
+	}
 
 	return 0, fmt.Errorf("Unknown Secret")
 }
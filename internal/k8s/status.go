@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/nginxinc/kubernetes-ingress/internal/configs"
@@ -225,6 +226,35 @@ func getExternalServiceAddress(svc *api_v1.Service) []string {
 	return addresses
 }
 
+// externalServiceAddressChecker detects when the external service stays without an address for too long,
+// which leaves the status of the resources empty.
+type externalServiceAddressChecker struct {
+	timeout             time.Duration
+	withoutAddressSince time.Time
+	reported            bool
+}
+
+// check records if the external service has an address at the moment now. It returns true
+// when the service has been without an address for at least the timeout and that hasn't been reported yet.
+func (c *externalServiceAddressChecker) check(hasAddress bool, now time.Time) bool {
+	if hasAddress {
+		c.withoutAddressSince = time.Time{}
+		c.reported = false
+		return false
+	}
+
+	if c.withoutAddressSince.IsZero() {
+		c.withoutAddressSince = now
+	}
+
+	if c.reported || now.Sub(c.withoutAddressSince) < c.timeout {
+		return false
+	}
+
+	c.reported = true
+	return true
+}
+
 // SaveStatusFromExternalStatus saves the status from a string.
 // For use with the external-status-address ConfigMap setting.
 // This method does not update ingress status - statusUpdater.UpdateIngressStatus must be called separately.
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	conf_v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
 	v1 "k8s.io/api/core/v1"
@@ -369,3 +370,65 @@ func TestIsRequiredPort(t *testing.T) {
 		}
 	}
 }
+
+func TestExternalServiceAddressChecker(t *testing.T) {
+	start := time.Now()
+	checker := externalServiceAddressChecker{timeout: 5 * time.Minute}
+
+	tests := []struct {
+		hasAddress bool
+		elapsed    time.Duration
+		expected   bool
+		msg        string
+	}{
+		{
+			hasAddress: false,
+			elapsed:    0,
+			expected:   false,
+			msg:        "no address at the beginning",
+		},
+		{
+			hasAddress: false,
+			elapsed:    4 * time.Minute,
+			expected:   false,
+			msg:        "no address before the timeout",
+		},
+		{
+			hasAddress: false,
+			elapsed:    5 * time.Minute,
+			expected:   true,
+			msg:        "no address after the timeout",
+		},
+		{
+			hasAddress: false,
+			elapsed:    6 * time.Minute,
+			expected:   false,
+			msg:        "no address after the timeout is reported only once",
+		},
+		{
+			hasAddress: true,
+			elapsed:    7 * time.Minute,
+			expected:   false,
+			msg:        "address",
+		},
+		{
+			hasAddress: false,
+			elapsed:    8 * time.Minute,
+			expected:   false,
+			msg:        "address lost",
+		},
+		{
+			hasAddress: false,
+			elapsed:    13 * time.Minute,
+			expected:   true,
+			msg:        "address lost for the timeout",
+		},
+	}
+
+	for _, test := range tests {
+		result := checker.check(test.hasAddress, start.Add(test.elapsed))
+		if result != test.expected {
+			t.Errorf("check() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -9,6 +10,8 @@ import (
 	conf_v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/workqueue"
 )
@@ -16,27 +19,55 @@ import (
 // taskQueue manages a work queue through an independent worker that
 // invokes the given sync function for every work item inserted.
 type taskQueue struct {
-	// queue is the work queue the worker polls
-	queue *workqueue.Type
+	// queue is the work queue the worker polls. The delayed tasks wait in the queue, so they are dropped on shutdown.
+	queue workqueue.DelayingInterface
 	// sync is called for each item in the queue
 	sync func(task)
-	// workerDone is closed when the worker exits
+	// workers is the number of workers that process the queue concurrently
+	workers int
+	// workerDone is closed when all the workers exit
 	workerDone chan struct{}
+	// onDrain, if set, is called with the drain time every time the queue is drained
+	onDrain func(time.Duration)
+
+	// drainMu protects the fields that track the drain of the queue
+	drainMu sync.Mutex
+	// drainStart is the time when a worker started processing tasks after the queue was drained
+	drainStart time.Time
+	// active is the number of tasks being processed
+	active int
 }
 
-// newTaskQueue creates a new task queue with the given sync function.
+// newTaskQueue creates a new task queue with the given sync function and number of workers.
 // The sync function is called for every element inserted into the queue.
-func newTaskQueue(syncFn func(task)) *taskQueue {
+// The queue never hands the same task to more than one worker at a time: if a task is added
+// while it is being processed, it is processed again only after the worker is done with it.
+func newTaskQueue(syncFn func(task), workers int) *taskQueue {
+	return newTaskQueueWithClock(syncFn, workers, clock.RealClock{})
+}
+
+// newTaskQueueWithClock creates a new task queue that uses the clock to delay the tasks.
+func newTaskQueueWithClock(syncFn func(task), workers int, c clock.Clock) *taskQueue {
 	return &taskQueue{
-		queue:      workqueue.New(),
+		queue:      workqueue.NewDelayingQueueWithCustomClock(c, ""),
 		sync:       syncFn,
+		workers:    workers,
 		workerDone: make(chan struct{}),
 	}
 }
 
-// Run begins running the worker for the given duration
+// Run begins running the workers for the given duration
 func (tq *taskQueue) Run(period time.Duration, stopCh <-chan struct{}) {
-	wait.Until(tq.worker, period, stopCh)
+	var wg sync.WaitGroup
+	for i := 0; i < tq.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(tq.worker, period, stopCh)
+		}()
+	}
+	wg.Wait()
+	close(tq.workerDone)
 }
 
 // Enqueue enqueues ns/name of the given api object in the task queue.
@@ -58,6 +89,25 @@ func (tq *taskQueue) Enqueue(obj interface{}) {
 	tq.queue.Add(task)
 }
 
+// EnqueueAfter enqueues ns/name of the given api object in the task queue after the given duration.
+func (tq *taskQueue) EnqueueAfter(obj interface{}, after time.Duration) {
+	key, err := keyFunc(obj)
+	if err != nil {
+		glog.V(3).Infof("Couldn't get key for object %v: %v", obj, err)
+		return
+	}
+
+	t, err := newTask(key, obj)
+	if err != nil {
+		glog.V(3).Infof("Couldn't create a task for object %v: %v", obj, err)
+		return
+	}
+
+	glog.V(3).Infof("Adding an element with a key: %v after %s", t.Key, after.String())
+
+	tq.queue.AddAfter(t, after)
+}
+
 // Requeue adds the task to the queue again and logs the given error
 func (tq *taskQueue) Requeue(task task, err error) {
 	glog.Errorf("Requeuing %v, err %v", task.Key, err)
@@ -67,10 +117,7 @@ func (tq *taskQueue) Requeue(task task, err error) {
 // RequeueAfter adds the task to the queue after the given duration
 func (tq *taskQueue) RequeueAfter(t task, err error, after time.Duration) {
 	glog.Errorf("Requeuing %v after %s, err %v", t.Key, after.String(), err)
-	go func(t task, after time.Duration) {
-		time.Sleep(after)
-		tq.queue.Add(t)
-	}(t, after)
+	tq.queue.AddAfter(t, after)
 }
 
 // Worker processes work in the queue through sync.
@@ -78,16 +125,49 @@ func (tq *taskQueue) worker() {
 	for {
 		t, quit := tq.queue.Get()
 		if quit {
-			close(tq.workerDone)
 			return
 		}
+		tq.startTask()
 		glog.V(3).Infof("Syncing %v", t.(task).Key)
 		tq.sync(t.(task))
 		tq.queue.Done(t)
+		tq.finishTask()
+	}
+}
+
+func (tq *taskQueue) startTask() {
+	tq.drainMu.Lock()
+	defer tq.drainMu.Unlock()
+
+	if tq.active == 0 && tq.drainStart.IsZero() {
+		tq.drainStart = time.Now()
+	}
+	tq.active++
+}
+
+// finishTask reports the drain time when the last task being processed is finished and the queue is empty.
+// The drain time is the time between the start of the first task after the previous drain and the finish
+// of the last task, so that for a mass change it shows how long it took to process all the changes.
+func (tq *taskQueue) finishTask() {
+	tq.drainMu.Lock()
+	defer tq.drainMu.Unlock()
+
+	tq.active--
+	if tq.active > 0 || tq.queue.Len() > 0 {
+		return
+	}
+
+	drainTime := time.Since(tq.drainStart)
+	tq.drainStart = time.Time{}
+
+	glog.V(3).Infof("The sync queue was drained in %v", drainTime)
+
+	if tq.onDrain != nil {
+		tq.onDrain(drainTime)
 	}
 }
 
-// Shutdown shuts down the work queue and waits for the worker to ACK
+// Shutdown shuts down the work queue and waits for the workers to ACK
 func (tq *taskQueue) Shutdown() {
 	tq.queue.ShutDown()
 	<-tq.workerDone
@@ -117,6 +197,10 @@ const (
 	globalConfiguration
 	// transportserver resource
 	transportserver
+	// policy resource
+	policy
+	// certificate resource, which is a cert-manager Certificate
+	certificate
 )
 
 // task is an element of a taskQueue
@@ -152,6 +236,11 @@ func newTask(key string, obj interface{}) (task, error) {
 		k = globalConfiguration
 	case *conf_v1alpha1.TransportServer:
 		k = transportserver
+	case *conf_v1alpha1.Policy:
+		k = policy
+	case *unstructured.Unstructured:
+		// the only unstructured resources the Ingress Controller watches are the cert-manager Certificates
+		k = certificate
 	default:
 		return task{}, fmt.Errorf("Unknow type: %v", t)
 	}
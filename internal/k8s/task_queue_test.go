@@ -0,0 +1,137 @@
+package k8s
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTaskQueueProcessesDistinctTasksInParallel(t *testing.T) {
+	tasks := []task{
+		{Kind: ingress, Key: "default/cafe-ingress"},
+		{Kind: ingress, Key: "default/tea-ingress"},
+	}
+
+	started := make(chan struct{}, len(tasks))
+	release := make(chan struct{})
+
+	tq := newTaskQueue(func(t task) {
+		started <- struct{}{}
+		<-release
+	}, len(tasks))
+
+	stopCh := make(chan struct{})
+	go tq.Run(time.Second, stopCh)
+
+	for _, task := range tasks {
+		tq.queue.Add(task)
+	}
+
+	for range tasks {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the tasks were not processed in parallel")
+		}
+	}
+
+	close(release)
+	close(stopCh)
+	tq.Shutdown()
+}
+
+func TestTaskQueueSerializesIdenticalTasks(t *testing.T) {
+	tsk := task{Kind: ingress, Key: "default/cafe-ingress"}
+
+	var mu sync.Mutex
+	running := 0
+	maxRunning := 0
+	processed := 0
+	done := make(chan struct{})
+
+	tq := newTaskQueue(func(t task) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		processed++
+		if processed == 2 {
+			close(done)
+		}
+		mu.Unlock()
+	}, 4)
+
+	stopCh := make(chan struct{})
+	go tq.Run(time.Second, stopCh)
+
+	tq.queue.Add(tsk)
+	// add the same task while it is being processed
+	time.Sleep(10 * time.Millisecond)
+	tq.queue.Add(tsk)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the task was not processed twice")
+	}
+
+	close(stopCh)
+	tq.Shutdown()
+
+	if maxRunning != 1 {
+		t.Errorf("the same task was processed by %d workers at the same time but expected 1", maxRunning)
+	}
+}
+
+func TestTaskQueueReportsDrainTime(t *testing.T) {
+	tasks := []task{
+		{Kind: ingress, Key: "default/cafe-ingress"},
+		{Kind: ingress, Key: "default/tea-ingress"},
+		{Kind: virtualserver, Key: "default/cafe"},
+		{Kind: virtualserver, Key: "default/tea"},
+	}
+
+	release := make(chan struct{})
+
+	tq := newTaskQueue(func(t task) {
+		<-release
+		time.Sleep(10 * time.Millisecond)
+	}, 2)
+
+	drained := make(chan time.Duration, len(tasks))
+	tq.onDrain = func(d time.Duration) {
+		drained <- d
+	}
+
+	stopCh := make(chan struct{})
+	go tq.Run(time.Second, stopCh)
+
+	for _, task := range tasks {
+		tq.queue.Add(task)
+	}
+	close(release)
+
+	select {
+	case d := <-drained:
+		// 4 tasks of 10ms each processed by 2 workers
+		if d < 20*time.Millisecond {
+			t.Errorf("the queue reported the drain time %v but expected at least 20ms", d)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the queue didn't report the drain time")
+	}
+
+	close(stopCh)
+	tq.Shutdown()
+
+	if l := len(drained); l != 0 {
+		t.Errorf("the queue reported %d more drains but expected 0", l)
+	}
+}
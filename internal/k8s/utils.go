@@ -96,15 +96,18 @@ func (s *storeToConfigMapLister) List() (cfgm v1.ConfigMapList, err error) {
 	return cfgm, nil
 }
 
-// indexerToPodLister makes a Indexer that lists Pods.
-type indexerToPodLister struct {
-	cache.Indexer
+// storeToPodLister makes a Store that lists Pods.
+type storeToPodLister struct {
+	cache.Store
 }
 
-// ListByNamespace lists all Pods in the indexer for a given namespace that match the provided selector.
-func (ipl indexerToPodLister) ListByNamespace(ns string, selector labels.Selector) (pods []*v1.Pod, err error) {
-	err = cache.ListAllByNamespace(ipl.Indexer, ns, selector, func(m interface{}) {
-		pods = append(pods, m.(*v1.Pod))
+// ListByNamespace lists all Pods in the store for a given namespace that match the provided selector.
+func (spl storeToPodLister) ListByNamespace(ns string, selector labels.Selector) (pods []*v1.Pod, err error) {
+	err = cache.ListAll(spl.Store, selector, func(m interface{}) {
+		pod := m.(*v1.Pod)
+		if pod.Namespace == ns {
+			pods = append(pods, pod)
+		}
 	})
 	return pods, err
 }
@@ -0,0 +1,29 @@
+package k8s
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// invalidReason is the reason of the validation failures whose errors don't have a type.
+const invalidReason = "Invalid"
+
+// getValidationFailureReason returns the reason of a validation failure, which is the type of the first field error,
+// for example, FieldValueRequired. The reason of the other errors is Invalid.
+func getValidationFailureReason(err error) string {
+	if agg, ok := err.(utilerrors.Aggregate); ok && len(agg.Errors()) > 0 {
+		err = agg.Errors()[0]
+	}
+
+	if fieldErr, ok := err.(*field.Error); ok {
+		return string(fieldErr.Type)
+	}
+
+	return invalidReason
+}
+
+// recordValidationFailure counts the resource rejected because of the validation error in the metrics.
+func (lbc *LoadBalancerController) recordValidationFailure(object runtime.Object, err error) {
+	lbc.metricsCollector.IncResourceValidationFailures(getObjectKind(object), getValidationFailureReason(err))
+}
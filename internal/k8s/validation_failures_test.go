@@ -0,0 +1,42 @@
+package k8s
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestGetValidationFailureReason(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+		msg      string
+	}{
+		{
+			err: field.ErrorList{
+				field.Required(field.NewPath("spec", "host"), ""),
+				field.Invalid(field.NewPath("spec", "upstreams"), "", ""),
+			}.ToAggregate(),
+			expected: "FieldValueRequired",
+			msg:      "aggregate of field errors",
+		},
+		{
+			err:      field.Forbidden(field.NewPath("spec", "listeners"), ""),
+			expected: "FieldValueForbidden",
+			msg:      "field error",
+		},
+		{
+			err:      errors.New("Ingress contains no valid rules"),
+			expected: "Invalid",
+			msg:      "error without a type",
+		},
+	}
+
+	for _, test := range tests {
+		result := getValidationFailureReason(test.err)
+		if result != test.expected {
+			t.Errorf("getValidationFailureReason() returned %q but expected %q for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
@@ -1,23 +1,37 @@
 package collectors
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 var labelNamesController = []string{"type"}
 
+var labelNamesValidationFailures = []string{"kind", "reason"}
+
 // ControllerCollector is an interface for the metrics of the Controller
 type ControllerCollector interface {
 	SetIngresses(ingressType string, count int)
 	SetVirtualServers(count int)
 	SetVirtualServerRoutes(count int)
+	SetTransportServers(count int)
+	SetGlobalConfigurations(count int)
+	UpdateLastSyncQueueDrainTime(duration time.Duration)
+	IncResourceValidationFailures(kind string, reason string)
 	Register(registry *prometheus.Registry) error
 }
 
 // ControllerMetricsCollector implements the ControllerCollector interface and prometheus.Collector interface
 type ControllerMetricsCollector struct {
-	crdsEnabled              bool
-	ingressesTotal           *prometheus.GaugeVec
-	virtualServersTotal      prometheus.Gauge
-	virtualServerRoutesTotal prometheus.Gauge
+	crdsEnabled               bool
+	ingressesTotal            *prometheus.GaugeVec
+	lastSyncQueueDrainTime    prometheus.Gauge
+	validationFailuresTotal   *prometheus.CounterVec
+	virtualServersTotal       prometheus.Gauge
+	virtualServerRoutesTotal  prometheus.Gauge
+	transportServersTotal     prometheus.Gauge
+	globalConfigurationsTotal prometheus.Gauge
 }
 
 // NewControllerMetricsCollector creates a new ControllerMetricsCollector
@@ -32,8 +46,31 @@ func NewControllerMetricsCollector(crdsEnabled bool, constLabels map[string]stri
 		labelNamesController,
 	)
 
+	lastSyncQueueDrainTime := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name:        "sync_queue_last_drain_milliseconds",
+			Namespace:   metricsNamespace,
+			Help:        "Duration in milliseconds of the last drain of the sync queue",
+			ConstLabels: constLabels,
+		},
+	)
+
+	validationFailuresTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "resource_validation_failures_total",
+			Namespace:   metricsNamespace,
+			Help:        "Number of resources rejected because of validation errors",
+			ConstLabels: constLabels,
+		},
+		labelNamesValidationFailures,
+	)
+
 	if !crdsEnabled {
-		return &ControllerMetricsCollector{ingressesTotal: ingResTotal}
+		return &ControllerMetricsCollector{
+			ingressesTotal:          ingResTotal,
+			lastSyncQueueDrainTime:  lastSyncQueueDrainTime,
+			validationFailuresTotal: validationFailuresTotal,
+		}
 	}
 
 	vsResTotal := prometheus.NewGauge(
@@ -54,11 +91,33 @@ func NewControllerMetricsCollector(crdsEnabled bool, constLabels map[string]stri
 		},
 	)
 
+	tsResTotal := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name:        "transportserver_resources_total",
+			Namespace:   metricsNamespace,
+			Help:        "Number of handled TransportServer resources",
+			ConstLabels: constLabels,
+		},
+	)
+
+	gcResTotal := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name:        "globalconfiguration_resources_total",
+			Namespace:   metricsNamespace,
+			Help:        "Number of handled GlobalConfiguration resources",
+			ConstLabels: constLabels,
+		},
+	)
+
 	return &ControllerMetricsCollector{
-		crdsEnabled:              true,
-		ingressesTotal:           ingResTotal,
-		virtualServersTotal:      vsResTotal,
-		virtualServerRoutesTotal: vsrResTotal,
+		crdsEnabled:               true,
+		ingressesTotal:            ingResTotal,
+		lastSyncQueueDrainTime:    lastSyncQueueDrainTime,
+		validationFailuresTotal:   validationFailuresTotal,
+		virtualServersTotal:       vsResTotal,
+		virtualServerRoutesTotal:  vsrResTotal,
+		transportServersTotal:     tsResTotal,
+		globalConfigurationsTotal: gcResTotal,
 	}
 }
 
@@ -77,21 +136,50 @@ func (cc *ControllerMetricsCollector) SetVirtualServerRoutes(count int) {
 	cc.virtualServerRoutesTotal.Set(float64(count))
 }
 
+// SetTransportServers sets the value of the TransportServer resources gauge
+func (cc *ControllerMetricsCollector) SetTransportServers(count int) {
+	cc.transportServersTotal.Set(float64(count))
+}
+
+// SetGlobalConfigurations sets the value of the GlobalConfiguration resources gauge
+func (cc *ControllerMetricsCollector) SetGlobalConfigurations(count int) {
+	cc.globalConfigurationsTotal.Set(float64(count))
+}
+
+// UpdateLastSyncQueueDrainTime updates the duration of the last drain of the sync queue
+func (cc *ControllerMetricsCollector) UpdateLastSyncQueueDrainTime(duration time.Duration) {
+	cc.lastSyncQueueDrainTime.Set(float64(duration / time.Millisecond))
+}
+
+// IncResourceValidationFailures increments the counter of the resources of a given kind rejected because of validation errors
+// of a given reason
+func (cc *ControllerMetricsCollector) IncResourceValidationFailures(kind string, reason string) {
+	cc.validationFailuresTotal.WithLabelValues(kind, reason).Inc()
+}
+
 // Describe implements prometheus.Collector interface Describe method
 func (cc *ControllerMetricsCollector) Describe(ch chan<- *prometheus.Desc) {
 	cc.ingressesTotal.Describe(ch)
+	cc.lastSyncQueueDrainTime.Describe(ch)
+	cc.validationFailuresTotal.Describe(ch)
 	if cc.crdsEnabled {
 		cc.virtualServersTotal.Describe(ch)
 		cc.virtualServerRoutesTotal.Describe(ch)
+		cc.transportServersTotal.Describe(ch)
+		cc.globalConfigurationsTotal.Describe(ch)
 	}
 }
 
 // Collect implements the prometheus.Collector interface Collect method
 func (cc *ControllerMetricsCollector) Collect(ch chan<- prometheus.Metric) {
 	cc.ingressesTotal.Collect(ch)
+	cc.lastSyncQueueDrainTime.Collect(ch)
+	cc.validationFailuresTotal.Collect(ch)
 	if cc.crdsEnabled {
 		cc.virtualServersTotal.Collect(ch)
 		cc.virtualServerRoutesTotal.Collect(ch)
+		cc.transportServersTotal.Collect(ch)
+		cc.globalConfigurationsTotal.Collect(ch)
 	}
 }
 
@@ -119,3 +207,15 @@ func (cc *ControllerFakeCollector) SetVirtualServers(count int) {}
 
 // SetVirtualServerRoutes implements a fake SetVirtualServerRoutes
 func (cc *ControllerFakeCollector) SetVirtualServerRoutes(count int) {}
+
+// SetTransportServers implements a fake SetTransportServers
+func (cc *ControllerFakeCollector) SetTransportServers(count int) {}
+
+// SetGlobalConfigurations implements a fake SetGlobalConfigurations
+func (cc *ControllerFakeCollector) SetGlobalConfigurations(count int) {}
+
+// UpdateLastSyncQueueDrainTime implements a fake UpdateLastSyncQueueDrainTime
+func (cc *ControllerFakeCollector) UpdateLastSyncQueueDrainTime(duration time.Duration) {}
+
+// IncResourceValidationFailures implements a fake IncResourceValidationFailures
+func (cc *ControllerFakeCollector) IncResourceValidationFailures(kind string, reason string) {}
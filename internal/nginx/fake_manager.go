@@ -31,6 +31,18 @@ func (*FakeManager) CreateMainConfig(content []byte) {
 	glog.V(3).Info(string(content))
 }
 
+// TestMainConfig provides a fake implementation of TestMainConfig.
+func (*FakeManager) TestMainConfig(content []byte) error {
+	glog.V(3).Info("Testing main config")
+	return nil
+}
+
+// TestConfig provides a fake implementation of TestConfig.
+func (*FakeManager) TestConfig() error {
+	glog.V(3).Info("Testing config")
+	return nil
+}
+
 // CreateConfig provides a fake implementation of CreateConfig.
 func (*FakeManager) CreateConfig(name string, content []byte) {
 	glog.V(3).Infof("Writing config %v", name)
@@ -64,6 +76,12 @@ func (fm *FakeManager) CreateSecret(name string, content []byte, mode os.FileMod
 	return fm.GetFilenameForSecret(name)
 }
 
+// CreateVersionedSecret provides a fake implementation of CreateVersionedSecret.
+func (fm *FakeManager) CreateVersionedSecret(name string, content []byte, mode os.FileMode) string {
+	glog.V(3).Infof("Writing versioned secret %v", name)
+	return fm.GetFilenameForSecret(name)
+}
+
 // DeleteSecret provides a fake implementation of DeleteSecret.
 func (*FakeManager) DeleteSecret(name string) {
 	glog.V(3).Infof("Deleting secret %v", name)
@@ -112,11 +130,23 @@ func (*FakeManager) UpdateServersInPlus(upstream string, servers []string, confi
 }
 
 // UpdateStreamServersInPlus provides a fake implementation of UpdateStreamServersInPlus.
-func (*FakeManager) UpdateStreamServersInPlus(upstream string, servers []string) error {
+func (*FakeManager) UpdateStreamServersInPlus(upstream string, servers []string, config StreamServerConfig) error {
 	glog.V(3).Infof("Updating stream servers of %v: %v", upstream, servers)
 	return nil
 }
 
+// DrainServersInPlus provides a fake implementation of DrainServersInPlus.
+func (*FakeManager) DrainServersInPlus() error {
+	glog.V(3).Info("Draining servers of all upstreams")
+	return nil
+}
+
+// PurgeCacheInPlus provides a fake implementation of PurgeCacheInPlus.
+func (*FakeManager) PurgeCacheInPlus(zone string, key string) error {
+	glog.V(3).Infof("Purging the entries with key %v from cache zone %v", key, zone)
+	return nil
+}
+
 // CreateOpenTracingTracerConfig creates a fake implementation of CreateOpenTracingTracerConfig.
 func (*FakeManager) CreateOpenTracingTracerConfig(content string) error {
 	glog.V(3).Infof("Writing OpenTracing tracer config file")
@@ -1,11 +1,14 @@
 package nginx
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"os/exec"
 	"path"
+	"sync"
 	"time"
 
 	"github.com/nginxinc/kubernetes-ingress/internal/metrics/collectors"
@@ -21,6 +24,9 @@ const TLSSecretFileMode = 0600
 const JWKSecretFileMode = 0644
 
 const configFileMode = 0644
+
+// secretVersionLength is the number of hex digits of the content hash in the filenames of versioned secrets.
+const secretVersionLength = 16
 const jsonFileForOpenTracingTracer = "/var/lib/nginx/tracer-config.json"
 
 // ServerConfig holds the config data for an upstream server in NGINX Plus.
@@ -29,18 +35,31 @@ type ServerConfig struct {
 	MaxConns    int
 	FailTimeout string
 	SlowStart   string
+	// BackupServers are the servers that receive requests only when the other servers are unavailable.
+	BackupServers []string
+	// DownServers are the servers marked as permanently unavailable.
+	DownServers []string
+}
+
+// StreamServerConfig holds the config data for the servers of a stream upstream in NGINX Plus.
+type StreamServerConfig struct {
+	// Weights are the weights of the servers by their addresses. The servers not included have the default weight.
+	Weights map[string]int
 }
 
 // The Manager interface updates NGINX configuration, starts, reloads and quits NGINX,
 // updates NGINX Plus upstream servers.
 type Manager interface {
 	CreateMainConfig(content []byte)
+	TestMainConfig(content []byte) error
+	TestConfig() error
 	CreateConfig(name string, content []byte)
 	DeleteConfig(name string)
 	CreateStreamConfig(name string, content []byte)
 	DeleteStreamConfig(name string)
 	CreateTLSPassthroughHostsConfig(content []byte)
 	CreateSecret(name string, content []byte, mode os.FileMode) string
+	CreateVersionedSecret(name string, content []byte, mode os.FileMode) string
 	DeleteSecret(name string)
 	GetFilenameForSecret(name string) string
 	CreateDHParam(content string) (string, error)
@@ -51,7 +70,9 @@ type Manager interface {
 	UpdateConfigVersionFile(openTracing bool)
 	SetPlusClients(plusClient *client.NginxClient, plusConfigVersionCheckClient *http.Client)
 	UpdateServersInPlus(upstream string, servers []string, config ServerConfig) error
-	UpdateStreamServersInPlus(upstream string, servers []string) error
+	UpdateStreamServersInPlus(upstream string, servers []string, config StreamServerConfig) error
+	DrainServersInPlus() error
+	PurgeCacheInPlus(zone string, key string) error
 	SetOpenTracing(openTracing bool)
 }
 
@@ -75,10 +96,17 @@ type LocalManager struct {
 	plusConfigVersionCheckClient *http.Client
 	metricsCollector             collectors.ManagerCollector
 	OpenTracing                  bool
+	secretVersions               map[string]string
+	staleSecretFilenames         map[string]bool
+	// verifyConfigBeforeReload enables testing the configuration with nginx -t before every reload
+	verifyConfigBeforeReload bool
+	// reloadMu ensures that reloads never overlap
+	reloadMu sync.Mutex
 }
 
-// NewLocalManager creates a LocalManager.
-func NewLocalManager(confPath string, binaryFilename string, mc collectors.ManagerCollector) *LocalManager {
+// NewLocalManager creates a LocalManager. If verifyConfigBeforeReload is true, the LocalManager tests the configuration
+// before every reload and doesn't reload NGINX if the test fails, so that NGINX keeps running with the last valid configuration.
+func NewLocalManager(confPath string, binaryFilename string, mc collectors.ManagerCollector, verifyConfigBeforeReload bool) *LocalManager {
 	verifyConfigGenerator, err := newVerifyConfigGenerator()
 	if err != nil {
 		glog.Fatalf("error instantiating a verifyConfigGenerator: %v", err)
@@ -99,6 +127,9 @@ func NewLocalManager(confPath string, binaryFilename string, mc collectors.Manag
 		reloadCmd:                   fmt.Sprintf("%v -s %v", binaryFilename, "reload"),
 		quitCmd:                     fmt.Sprintf("%v -s %v", binaryFilename, "quit"),
 		metricsCollector:            mc,
+		secretVersions:              make(map[string]string),
+		staleSecretFilenames:        make(map[string]bool),
+		verifyConfigBeforeReload:    verifyConfigBeforeReload,
 	}
 
 	return &manager
@@ -115,6 +146,44 @@ func (lm *LocalManager) CreateMainConfig(content []byte) {
 	}
 }
 
+// TestMainConfig tests the main NGINX configuration with the content without applying it.
+// The content is written to a temporary file next to the main configuration file, so that includes with
+// relative paths are resolved the same way, and the file is tested with nginx -t.
+func (lm *LocalManager) TestMainConfig(content []byte) error {
+	f, err := ioutil.TempFile(path.Dir(lm.mainConfFilename), "nginx-test-*.conf")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file for the main config: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the main config to %v: %v", f.Name(), err)
+	}
+
+	glog.V(3).Infof("Testing main config %v", f.Name())
+
+	if err := shellOut(fmt.Sprintf("%v -t -c %v", lm.binaryFilename, f.Name())); err != nil {
+		return fmt.Errorf("nginx config test failed: %v", err)
+	}
+
+	return nil
+}
+
+// TestConfig tests the current NGINX configuration on the disk with nginx -t without applying it.
+func (lm *LocalManager) TestConfig() error {
+	glog.V(3).Info("Testing config")
+
+	if err := shellOut(fmt.Sprintf("%v -t", lm.binaryFilename)); err != nil {
+		return fmt.Errorf("nginx config test failed: %v", err)
+	}
+
+	return nil
+}
+
 // CreateConfig creates a configuration file. If the file already exists, it will be overridden.
 func (lm *LocalManager) CreateConfig(name string, content []byte) {
 	createConfig(lm.getFilenameForConfig(name), content)
@@ -182,9 +251,53 @@ func (lm *LocalManager) CreateSecret(name string, content []byte, mode os.FileMo
 	return filename
 }
 
+// CreateVersionedSecret creates a secret file with the specified name, content and mode. The filename includes
+// the version of the content, so that a secret update creates a new file instead of overriding the file
+// referenced by the running NGINX configuration. The file of the previous version is removed after the next
+// successful reload.
+func (lm *LocalManager) CreateVersionedSecret(name string, content []byte, mode os.FileMode) string {
+	filename := lm.getFilenameForVersionedSecret(name, content)
+
+	if prevFilename, exists := lm.secretVersions[name]; exists && prevFilename != filename {
+		lm.staleSecretFilenames[prevFilename] = true
+	}
+	// the content can change back to a previous version before a reload
+	delete(lm.staleSecretFilenames, filename)
+	lm.secretVersions[name] = filename
+
+	glog.V(3).Infof("Writing secret to %v", filename)
+
+	createFileAndWriteAtomically(filename, lm.secretsPath, mode, content)
+
+	return filename
+}
+
+func (lm *LocalManager) getFilenameForVersionedSecret(name string, content []byte) string {
+	version := fmt.Sprintf("%x", sha256.Sum256(content))[:secretVersionLength]
+	return fmt.Sprintf("%s.%s", lm.GetFilenameForSecret(name), version)
+}
+
+// removeStaleSecrets removes the files of the previous versions of the secrets, which are no longer referenced
+// by the NGINX configuration.
+func (lm *LocalManager) removeStaleSecrets() {
+	for filename := range lm.staleSecretFilenames {
+		glog.V(3).Infof("Deleting stale secret from %v", filename)
+
+		if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+			glog.Warningf("Failed to delete stale secret from %v: %v", filename, err)
+		}
+
+		delete(lm.staleSecretFilenames, filename)
+	}
+}
+
 // DeleteSecret the file with the secret.
 func (lm *LocalManager) DeleteSecret(name string) {
 	filename := lm.GetFilenameForSecret(name)
+	if versionedFilename, exists := lm.secretVersions[name]; exists {
+		filename = versionedFilename
+		delete(lm.secretVersions, name)
+	}
 
 	glog.V(3).Infof("Deleting secret from %v", filename)
 
@@ -232,7 +345,18 @@ func (lm *LocalManager) Start(done chan error) {
 }
 
 // Reload reloads NGINX.
+// Reloads are serialized: a reload waits until the previous reload is finished.
 func (lm *LocalManager) Reload() error {
+	lm.reloadMu.Lock()
+	defer lm.reloadMu.Unlock()
+
+	if lm.verifyConfigBeforeReload {
+		if err := lm.TestConfig(); err != nil {
+			lm.metricsCollector.IncNginxReloadErrors()
+			return fmt.Errorf("nginx reload skipped: %v", err)
+		}
+	}
+
 	// write a new config version
 	lm.configVersion++
 	lm.UpdateConfigVersionFile(lm.OpenTracing)
@@ -253,6 +377,8 @@ func (lm *LocalManager) Reload() error {
 
 	lm.metricsCollector.IncNginxReloadCount()
 
+	lm.removeStaleSecrets()
+
 	t2 := time.Now()
 	lm.metricsCollector.UpdateLastReloadTime(t2.Sub(t1))
 	return nil
@@ -296,14 +422,35 @@ func (lm *LocalManager) UpdateServersInPlus(upstream string, servers []string, c
 
 	glog.V(3).Infof("API has the correct config version: %v.", lm.configVersion)
 
+	downServers := make(map[string]bool)
+	for _, s := range config.DownServers {
+		downServers[s] = true
+	}
+
 	var upsServers []client.UpstreamServer
 	for _, s := range servers {
+		down := downServers[s]
 		upsServers = append(upsServers, client.UpstreamServer{
 			Server:      s,
 			MaxFails:    &config.MaxFails,
 			MaxConns:    &config.MaxConns,
 			FailTimeout: config.FailTimeout,
 			SlowStart:   config.SlowStart,
+			Down:        &down,
+		})
+	}
+
+	backup := true
+	for _, s := range config.BackupServers {
+		down := downServers[s]
+		upsServers = append(upsServers, client.UpstreamServer{
+			Server:      s,
+			MaxFails:    &config.MaxFails,
+			MaxConns:    &config.MaxConns,
+			FailTimeout: config.FailTimeout,
+			SlowStart:   config.SlowStart,
+			Backup:      &backup,
+			Down:        &down,
 		})
 	}
 
@@ -319,7 +466,7 @@ func (lm *LocalManager) UpdateServersInPlus(upstream string, servers []string, c
 }
 
 // UpdateStreamServersInPlus updates NGINX Plus stream servers of the given upstream.
-func (lm *LocalManager) UpdateStreamServersInPlus(upstream string, servers []string) error {
+func (lm *LocalManager) UpdateStreamServersInPlus(upstream string, servers []string, config StreamServerConfig) error {
 	err := verifyConfigVersion(lm.plusConfigVersionCheckClient, lm.configVersion)
 	if err != nil {
 		return fmt.Errorf("error verifying config version: %v", err)
@@ -329,8 +476,14 @@ func (lm *LocalManager) UpdateStreamServersInPlus(upstream string, servers []str
 
 	var upsServers []client.StreamUpstreamServer
 	for _, s := range servers {
+		// the default weight of NGINX is set explicitly, so that the servers get it back when their weight is removed
+		weight := 1
+		if w, exists := config.Weights[s]; exists {
+			weight = w
+		}
 		upsServers = append(upsServers, client.StreamUpstreamServer{
 			Server: s,
+			Weight: &weight,
 		})
 	}
 
@@ -345,6 +498,79 @@ func (lm *LocalManager) UpdateStreamServersInPlus(upstream string, servers []str
 	return nil
 }
 
+// plusDrainClient is the part of the NGINX Plus API client used to drain upstream servers.
+type plusDrainClient interface {
+	GetStats() (*client.Stats, error)
+	GetHTTPServers(upstream string) ([]client.UpstreamServer, error)
+	UpdateHTTPServer(upstream string, server client.UpstreamServer) error
+}
+
+// DrainServersInPlus puts the servers of all NGINX Plus HTTP upstreams into the draining mode, so that
+// the established connections are finished while no new requests are sent to the servers.
+func (lm *LocalManager) DrainServersInPlus() error {
+	return drainHTTPServers(lm.plusClient)
+}
+
+func drainHTTPServers(plusClient plusDrainClient) error {
+	stats, err := plusClient.GetStats()
+	if err != nil {
+		return fmt.Errorf("error getting upstreams: %v", err)
+	}
+
+	for upstream := range stats.Upstreams {
+		servers, err := plusClient.GetHTTPServers(upstream)
+		if err != nil {
+			return fmt.Errorf("error getting servers of %v upstream: %v", upstream, err)
+		}
+
+		for _, s := range servers {
+			if s.Drain {
+				continue
+			}
+
+			err := plusClient.UpdateHTTPServer(upstream, client.UpstreamServer{ID: s.ID, Server: s.Server, Drain: true})
+			if err != nil {
+				return fmt.Errorf("error draining server %v of %v upstream: %v", s.Server, upstream, err)
+			}
+		}
+
+		glog.V(3).Infof("Drained servers of %v upstream", upstream)
+	}
+
+	return nil
+}
+
+// PurgeCacheInPlus removes the entries with the given key from the cache zone. A key ending with an asterisk is
+// a wildcard that removes all entries with the keys that start with the rest of the key.
+func (lm *LocalManager) PurgeCacheInPlus(zone string, key string) error {
+	return purgeCache(lm.plusConfigVersionCheckClient, zone, key)
+}
+
+// purgeCache sends a purge request to the cache purge location of the NGINX Plus API server.
+// The zone and the key are sent in headers, so that the key is passed to NGINX Plus as is.
+func purgeCache(httpClient *http.Client, zone string, key string) error {
+	req, err := http.NewRequest("PURGE", "http://nginx-plus-api/purge-cache", nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("x-cache-zone", zone)
+	req.Header.Set("x-cache-key", key)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error doing request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// NGINX Plus returns 412 when there are no entries with the key in the cache
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusPreconditionFailed {
+		return fmt.Errorf("API returned non-success status: %v", resp.StatusCode)
+	}
+
+	return nil
+}
+
 // CreateOpenTracingTracerConfig creates a json configuration file for the OpenTracing tracer with the content of the string.
 func (lm *LocalManager) CreateOpenTracingTracerConfig(content string) error {
 	glog.V(3).Infof("Writing OpenTracing tracer config file to %v", jsonFileForOpenTracingTracer)
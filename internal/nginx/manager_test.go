@@ -0,0 +1,398 @@
+package nginx
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/nginxinc/kubernetes-ingress/internal/metrics/collectors"
+	"github.com/nginxinc/nginx-plus-go-client/client"
+)
+
+func newTestLocalManager(secretsPath string) *LocalManager {
+	return &LocalManager{
+		secretsPath:          secretsPath,
+		secretVersions:       make(map[string]string),
+		staleSecretFilenames: make(map[string]bool),
+	}
+}
+
+func fileExists(filename string) bool {
+	_, err := os.Stat(filename)
+	return err == nil
+}
+
+func TestCreateVersionedSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatalf("Failed to create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	lm := newTestLocalManager(dir)
+
+	oldFilename := lm.CreateVersionedSecret("default-cafe-secret", []byte("old"), TLSSecretFileMode)
+	if sameFilename := lm.CreateVersionedSecret("default-cafe-secret", []byte("old"), TLSSecretFileMode); sameFilename != oldFilename {
+		t.Errorf("CreateVersionedSecret() returned %v for the same content but expected %v", sameFilename, oldFilename)
+	}
+	if len(lm.staleSecretFilenames) != 0 {
+		t.Errorf("CreateVersionedSecret() marked files %v as stale for the same content", lm.staleSecretFilenames)
+	}
+
+	newFilename := lm.CreateVersionedSecret("default-cafe-secret", []byte("new"), TLSSecretFileMode)
+	if newFilename == oldFilename {
+		t.Fatalf("CreateVersionedSecret() returned the same filename %v for the updated content", newFilename)
+	}
+
+	// the file referenced by the running NGINX configuration must exist until a reload
+	if !fileExists(oldFilename) {
+		t.Errorf("CreateVersionedSecret() removed the file of the previous version %v before a reload", oldFilename)
+	}
+
+	content, err := ioutil.ReadFile(newFilename)
+	if err != nil {
+		t.Fatalf("Failed to read the file of the new version %v: %v", newFilename, err)
+	}
+	if string(content) != "new" {
+		t.Errorf("CreateVersionedSecret() wrote %q but expected %q", content, "new")
+	}
+
+	lm.removeStaleSecrets()
+
+	if fileExists(oldFilename) {
+		t.Errorf("removeStaleSecrets() didn't remove the file of the previous version %v", oldFilename)
+	}
+	if !fileExists(newFilename) {
+		t.Errorf("removeStaleSecrets() removed the file of the current version %v", newFilename)
+	}
+}
+
+func TestCreateVersionedSecretWithRevertedContent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatalf("Failed to create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	lm := newTestLocalManager(dir)
+
+	filename := lm.CreateVersionedSecret("default-cafe-secret", []byte("old"), TLSSecretFileMode)
+	lm.CreateVersionedSecret("default-cafe-secret", []byte("new"), TLSSecretFileMode)
+	// the content changes back before a reload
+	lm.CreateVersionedSecret("default-cafe-secret", []byte("old"), TLSSecretFileMode)
+
+	lm.removeStaleSecrets()
+
+	if !fileExists(filename) {
+		t.Errorf("removeStaleSecrets() removed the file of the current version %v", filename)
+	}
+}
+
+func TestDeleteVersionedSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatalf("Failed to create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	lm := newTestLocalManager(dir)
+
+	filename := lm.CreateVersionedSecret("default-cafe-secret", []byte("content"), TLSSecretFileMode)
+	lm.DeleteSecret("default-cafe-secret")
+
+	if fileExists(filename) {
+		t.Errorf("DeleteSecret() didn't remove the file %v", filename)
+	}
+}
+
+// configVersionTransport returns the current config version of the LocalManager, as if NGINX was reloaded successfully.
+type configVersionTransport struct {
+	lm *LocalManager
+}
+
+func (c configVersionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(strconv.Itoa(c.lm.configVersion))),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func TestReloadsDoNotOverlap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatalf("Failed to create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	verifyConfigGenerator, err := newVerifyConfigGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create a verifyConfigGenerator: %v", err)
+	}
+
+	// the reload command fails if another reload is in progress
+	lockDir := path.Join(dir, "reload.lock")
+
+	lm := newTestLocalManager(dir)
+	lm.configVersionFilename = path.Join(dir, "config-version.conf")
+	lm.verifyConfigGenerator = verifyConfigGenerator
+	lm.reloadCmd = fmt.Sprintf("mkdir %s && sleep 0.05 && rmdir %s", lockDir, lockDir)
+	lm.metricsCollector = collectors.NewManagerFakeCollector()
+	lm.verifyClient = &verifyClient{
+		client:     &http.Client{Transport: configVersionTransport{lm: lm}},
+		maxRetries: 1,
+	}
+
+	reloads := 5
+
+	var wg sync.WaitGroup
+	errs := make(chan error, reloads)
+
+	for i := 0; i < reloads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- lm.Reload()
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Reload() returned unexpected error: %v", err)
+		}
+	}
+
+	if lm.configVersion != reloads {
+		t.Errorf("Reload() updated the config version to %d but expected %d", lm.configVersion, reloads)
+	}
+}
+
+func TestReloadWithConfigVerification(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nginx")
+	if err != nil {
+		t.Fatalf("Failed to create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	confdPath := path.Join(dir, "conf.d")
+	if err := os.Mkdir(confdPath, 0755); err != nil {
+		t.Fatalf("Failed to create the conf.d dir: %v", err)
+	}
+
+	verifyConfigGenerator, err := newVerifyConfigGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create a verifyConfigGenerator: %v", err)
+	}
+
+	// the fake binary fails the test if a config includes an unknown directive, like nginx -t
+	binary := path.Join(dir, "nginx")
+	script := fmt.Sprintf("#!/bin/sh\ngrep -q unknown_directive %s/*.conf && exit 1\nexit 0\n", confdPath)
+	if err := ioutil.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write the fake binary: %v", err)
+	}
+
+	reloadsFilename := path.Join(dir, "reloads")
+
+	lm := newTestLocalManager(dir)
+	lm.confdPath = confdPath
+	lm.configVersionFilename = path.Join(dir, "config-version.conf")
+	lm.binaryFilename = binary
+	lm.verifyConfigGenerator = verifyConfigGenerator
+	lm.verifyConfigBeforeReload = true
+	lm.reloadCmd = fmt.Sprintf("echo reload >> %s", reloadsFilename)
+	lm.metricsCollector = collectors.NewManagerFakeCollector()
+	lm.verifyClient = &verifyClient{
+		client:     &http.Client{Transport: configVersionTransport{lm: lm}},
+		maxRetries: 1,
+	}
+
+	lm.CreateConfig("default-cafe", []byte("server {\n    listen 80;\n}\n"))
+	if err := lm.Reload(); err != nil {
+		t.Fatalf("Reload() returned an unexpected error for a valid config: %v", err)
+	}
+
+	lm.CreateConfig("default-tea", []byte("server {\n    unknown_directive on;\n}\n"))
+	if err := lm.Reload(); err == nil {
+		t.Errorf("Reload() returned no error for an invalid config")
+	}
+
+	reloads, err := ioutil.ReadFile(reloadsFilename)
+	if err != nil {
+		t.Fatalf("Failed to read the reloads file: %v", err)
+	}
+	if string(reloads) != "reload\n" {
+		t.Errorf("Reload() reloaded NGINX %q but expected only the reload of the valid config", reloads)
+	}
+	if lm.configVersion != 1 {
+		t.Errorf("Reload() updated the config version to %d but expected 1", lm.configVersion)
+	}
+
+	// without the verification, the invalid config is reloaded
+	lm.verifyConfigBeforeReload = false
+	if err := lm.Reload(); err != nil {
+		t.Errorf("Reload() returned an unexpected error without the verification: %v", err)
+	}
+	if lm.configVersion != 2 {
+		t.Errorf("Reload() updated the config version to %d but expected 2", lm.configVersion)
+	}
+}
+
+func TestTestMainConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nginx")
+	if err != nil {
+		t.Fatalf("Failed to create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the fake binary fails the test of a config with an unknown directive, like nginx -t -c <file>
+	binary := path.Join(dir, "nginx")
+	script := "#!/bin/sh\ngrep -q unknown_directive \"$3\" && exit 1\nexit 0\n"
+	if err := ioutil.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write the fake binary: %v", err)
+	}
+
+	lm := newTestLocalManager(dir)
+	lm.mainConfFilename = path.Join(dir, "nginx.conf")
+	lm.binaryFilename = binary
+
+	if err := lm.TestMainConfig([]byte("http {\n    map_hash_bucket_size 128;\n}\n")); err != nil {
+		t.Errorf("TestMainConfig() returned an unexpected error for a valid config: %v", err)
+	}
+
+	if err := lm.TestMainConfig([]byte("http {\n    unknown_directive on;\n}\n")); err == nil {
+		t.Errorf("TestMainConfig() returned no error for an invalid config")
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read the temp dir: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("TestMainConfig() left %d files in the config dir but expected only the binary", len(files))
+	}
+	if fileExists(lm.mainConfFilename) {
+		t.Errorf("TestMainConfig() wrote the main config file")
+	}
+}
+
+type fakePlusDrainClient struct {
+	servers map[string][]client.UpstreamServer
+	drained map[string][]string
+}
+
+func (c *fakePlusDrainClient) GetStats() (*client.Stats, error) {
+	upstreams := make(client.Upstreams)
+	for name := range c.servers {
+		upstreams[name] = client.Upstream{}
+	}
+
+	return &client.Stats{Upstreams: upstreams}, nil
+}
+
+func (c *fakePlusDrainClient) GetHTTPServers(upstream string) ([]client.UpstreamServer, error) {
+	return c.servers[upstream], nil
+}
+
+func (c *fakePlusDrainClient) UpdateHTTPServer(upstream string, server client.UpstreamServer) error {
+	if !server.Drain {
+		return fmt.Errorf("server %v of %v upstream was updated without drain", server.Server, upstream)
+	}
+
+	c.drained[upstream] = append(c.drained[upstream], server.Server)
+	return nil
+}
+
+func TestDrainHTTPServers(t *testing.T) {
+	plusClient := &fakePlusDrainClient{
+		servers: map[string][]client.UpstreamServer{
+			"vs_default_cafe_tea": {
+				{ID: 0, Server: "10.0.0.1:80"},
+				{ID: 1, Server: "10.0.0.2:80"},
+			},
+			"vs_default_cafe_coffee": {
+				{ID: 0, Server: "10.0.0.3:80"},
+				{ID: 1, Server: "10.0.0.4:80", Drain: true},
+			},
+		},
+		drained: make(map[string][]string),
+	}
+
+	err := drainHTTPServers(plusClient)
+	if err != nil {
+		t.Fatalf("drainHTTPServers() returned an unexpected error: %v", err)
+	}
+
+	expected := map[string][]string{
+		"vs_default_cafe_tea":    {"10.0.0.1:80", "10.0.0.2:80"},
+		"vs_default_cafe_coffee": {"10.0.0.3:80"},
+	}
+	if !reflect.DeepEqual(plusClient.drained, expected) {
+		t.Errorf("drainHTTPServers() drained the servers %v but expected %v", plusClient.drained, expected)
+	}
+}
+
+// roundTripFunc is an HTTP transport that handles the requests with a function instead of sending them.
+type roundTripFunc func(req *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func TestPurgeCache(t *testing.T) {
+	tests := []struct {
+		status      int
+		expectedErr bool
+		msg         string
+	}{
+		{
+			status:      http.StatusNoContent,
+			expectedErr: false,
+			msg:         "purged entries",
+		},
+		{
+			status:      http.StatusPreconditionFailed,
+			expectedErr: false,
+			msg:         "no entries",
+		},
+		{
+			status:      http.StatusInternalServerError,
+			expectedErr: true,
+			msg:         "error",
+		},
+	}
+
+	for _, test := range tests {
+		var purgeReq *http.Request
+		httpClient := &http.Client{
+			Transport: roundTripFunc(func(req *http.Request) *http.Response {
+				purgeReq = req
+				return &http.Response{StatusCode: test.status, Body: ioutil.NopCloser(bytes.NewReader(nil))}
+			}),
+		}
+
+		err := purgeCache(httpClient, "vs_default_cafe_tea", "httpvs_default_cafe_tea/tea*")
+		if test.expectedErr != (err != nil) {
+			t.Errorf("purgeCache() returned %v for the case of %s", err, test.msg)
+		}
+
+		if purgeReq.Method != "PURGE" || purgeReq.URL.Path != "/purge-cache" {
+			t.Errorf("purgeCache() sent %v %v but expected PURGE /purge-cache for the case of %s", purgeReq.Method, purgeReq.URL.Path, test.msg)
+		}
+		if zone := purgeReq.Header.Get("x-cache-zone"); zone != "vs_default_cafe_tea" {
+			t.Errorf("purgeCache() sent the cache zone %q but expected %q for the case of %s", zone, "vs_default_cafe_tea", test.msg)
+		}
+		if key := purgeReq.Header.Get("x-cache-key"); key != "httpvs_default_cafe_tea/tea*" {
+			t.Errorf("purgeCache() sent the cache key %q but expected %q for the case of %s", key, "httpvs_default_cafe_tea/tea*", test.msg)
+		}
+	}
+}
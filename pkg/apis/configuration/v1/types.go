@@ -13,6 +13,13 @@ const (
 	StateInvalid = "Invalid"
 )
 
+const (
+	// CutoverPromote sends all traffic of a route to the last of its splits.
+	CutoverPromote = "promote"
+	// CutoverRollback sends all traffic of a route to the first of its splits.
+	CutoverRollback = "rollback"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:validation:Optional
@@ -28,17 +35,116 @@ type VirtualServer struct {
 
 // VirtualServerSpec is the spec of the VirtualServer resource.
 type VirtualServerSpec struct {
-	IngressClass string     `json:"ingressClassName"`
-	Host         string     `json:"host"`
-	TLS          *TLS       `json:"tls"`
-	Upstreams    []Upstream `json:"upstreams"`
-	Routes       []Route    `json:"routes"`
+	IngressClass    string           `json:"ingressClassName"`
+	Host            string           `json:"host"`
+	TLS             *TLS             `json:"tls"`
+	OIDC            *OIDC            `json:"oidc"`
+	RequestID       *RequestID       `json:"requestID"`
+	AccessLog       *AccessLog       `json:"accessLog"`
+	OpenTelemetry   *OpenTelemetry   `json:"opentelemetry"`
+	Maps            []Map            `json:"maps"`
+	ClientBody      *ClientBody      `json:"clientBody"`
+	ClientHeaders   *ClientHeaders   `json:"clientHeaders"`
+	Compression     *Compression     `json:"compression"`
+	ConnectionLimit *ConnectionLimit `json:"connectionLimit"`
+	ErrorBackend    *ErrorBackend    `json:"errorBackend"`
+	ServerTokens    string           `json:"serverTokens"`
+	ServerSnippets  string           `json:"serverSnippets"`
+	Upstreams       []Upstream       `json:"upstreams"`
+	Routes          []Route          `json:"routes"`
+}
+
+// RequestID defines the generation and propagation of request IDs for a VirtualServer.
+type RequestID struct {
+	Enable bool   `json:"enable"`
+	Header string `json:"header"`
+}
+
+// OIDC defines the OpenID Connect authentication of a VirtualServer.
+type OIDC struct {
+	ClientID      string `json:"clientID"`
+	ClientSecret  string `json:"clientSecret"`
+	AuthEndpoint  string `json:"authEndpoint"`
+	TokenEndpoint string `json:"tokenEndpoint"`
+	JWKSURI       string `json:"jwksURI"`
+	Scope         string `json:"scope"`
+	RedirectURI   string `json:"redirectURI"`
+	FailureMode   string `json:"failureMode"`
+}
+
+// AccessLog defines the custom fields of the access log of a VirtualServer.
+type AccessLog struct {
+	Fields []AccessLogField `json:"fields"`
+}
+
+// AccessLogField defines a custom field of the access log with the value of an NGINX variable.
+type AccessLogField struct {
+	Name     string `json:"name"`
+	Variable string `json:"variable"`
+}
+
+// OpenTelemetry defines the OpenTelemetry tracing configuration for a VirtualServer.
+type OpenTelemetry struct {
+	Enable bool `json:"enable"`
+}
+
+// ClientBody defines the buffering of client request bodies for a VirtualServer.
+type ClientBody struct {
+	BufferSize string `json:"bufferSize"`
+	TempPath   string `json:"tempPath"`
+	Timeout    string `json:"timeout"`
+}
+
+// ClientHeaders defines the buffers for reading client request headers for a VirtualServer.
+type ClientHeaders struct {
+	BufferSize   string           `json:"bufferSize"`
+	LargeBuffers *UpstreamBuffers `json:"largeBuffers"`
+	Timeout      string           `json:"timeout"`
+}
+
+// Compression defines the compression of responses for a VirtualServer.
+type Compression struct {
+	Type      string   `json:"type"`
+	Types     []string `json:"types"`
+	MinLength int      `json:"minLength"`
+	Level     *int     `json:"level"`
+}
+
+// ConnectionLimit defines a limit of the number of concurrent connections to the host of a VirtualServer.
+type ConnectionLimit struct {
+	Key                    string `json:"key"`
+	Connections            int    `json:"connections"`
+	ConnectionsPerEndpoint int    `json:"connectionsPerEndpoint"`
+	Upstream               string `json:"upstream"`
+	ZoneSize               string `json:"zoneSize"`
+	RejectCode             *int   `json:"rejectCode"`
+}
+
+// ErrorBackend defines an upstream that handles the error responses of a VirtualServer.
+type ErrorBackend struct {
+	Upstream string `json:"upstream"`
+	Codes    []int  `json:"codes"`
+}
+
+// Map defines a variable whose value depends on the value of the source variable.
+type Map struct {
+	Name     string       `json:"name"`
+	Source   string       `json:"source"`
+	Mappings []MapMapping `json:"mappings"`
+	Default  string       `json:"default"`
+}
+
+// MapMapping defines a mapping of a value of the source variable to a result in a Map.
+type MapMapping struct {
+	Value  string `json:"value"`
+	Result string `json:"result"`
 }
 
 // Upstream defines an upstream.
 type Upstream struct {
 	Name                     string            `json:"name"`
 	Service                  string            `json:"service"`
+	Services                 []string          `json:"services"`
 	Subselector              map[string]string `json:"subselector"`
 	Port                     uint16            `json:"port"`
 	LBMethod                 string            `json:"lb-method"`
@@ -61,6 +167,25 @@ type Upstream struct {
 	SlowStart                string            `json:"slow-start"`
 	Queue                    *UpstreamQueue    `json:"queue"`
 	SessionCookie            *SessionCookie    `json:"sessionCookie"`
+	SRV                      *UpstreamSRV      `json:"srv"`
+	ConnectionLimitPolicy    string            `json:"connection-limit-policy"`
+	Cache                    *UpstreamCache    `json:"cache"`
+	ResolverValid            string            `json:"resolver-valid"`
+	Backup                   string            `json:"backup"`
+	BackupPort               uint16            `json:"backup-port"`
+	Down                     []string          `json:"down"`
+}
+
+// UpstreamCache defines the caching of the responses of an Upstream.
+type UpstreamCache struct {
+	ZoneSize             string            `json:"zoneSize"`
+	CacheLock            bool              `json:"cacheLock"`
+	CacheLockTimeout     string            `json:"cacheLockTimeout"`
+	BackgroundUpdate     bool              `json:"backgroundUpdate"`
+	Valid                map[string]string `json:"valid"`
+	Key                  string            `json:"key"`
+	IgnoreHeaders        []string          `json:"ignoreHeaders"`
+	OverrideCacheControl bool              `json:"overrideCacheControl"`
 }
 
 // UpstreamBuffers defines Buffer Configuration for an Upstream.
@@ -69,9 +194,18 @@ type UpstreamBuffers struct {
 	Size   string `json:"size"`
 }
 
+// UpstreamSRV defines the discovery of the servers of an Upstream via DNS SRV records.
+type UpstreamSRV struct {
+	Host    string `json:"host"`
+	Service string `json:"service"`
+}
+
 // UpstreamTLS defines a TLS configuration for an Upstream.
 type UpstreamTLS struct {
-	Enable bool `json:"enable"`
+	Enable       bool     `json:"enable"`
+	SessionReuse *bool    `json:"sessionReuse"`
+	Protocols    []string `json:"protocols"`
+	Ciphers      string   `json:"ciphers"`
 }
 
 // HealthCheck defines the parameters for active Upstream HealthChecks.
@@ -110,43 +244,100 @@ type SessionCookie struct {
 
 // Route defines a route.
 type Route struct {
-	Path       string      `json:"path"`
-	Route      string      `json:"route"`
-	Action     *Action     `json:"action"`
-	Splits     []Split     `json:"splits"`
-	Matches    []Match     `json:"matches"`
-	ErrorPages []ErrorPage `json:"errorPages"`
+	Path             string            `json:"path"`
+	Route            string            `json:"route"`
+	Action           *Action           `json:"action"`
+	Splits           []Split           `json:"splits"`
+	StickySplits     *StickySplits     `json:"stickySplits"`
+	Matches          []Match           `json:"matches"`
+	ErrorPages       []ErrorPage       `json:"errorPages"`
+	AllowedMethods   []string          `json:"allowedMethods"`
+	SubFilter        *SubFilter        `json:"subFilter"`
+	RequestBuffering *bool             `json:"requestBuffering"`
+	LocationSnippets string            `json:"locationSnippets"`
+	Policies         []PolicyReference `json:"policies"`
+	Cutover          string            `json:"cutover"`
+	Mirror           *Mirror           `json:"mirror"`
+}
+
+// Mirror defines the mirroring of a percentage of the requests of a route to a shadow upstream. The responses of
+// the shadow upstream are ignored.
+type Mirror struct {
+	Upstream   string `json:"upstream"`
+	Percentage *int   `json:"percentage"`
+}
+
+// PolicyReference references a policy by name and an optional namespace.
+type PolicyReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// SubFilter defines the substitutions of strings in the responses of a route.
+type SubFilter struct {
+	Substitutions []Substitution `json:"substitutions"`
+	Replace       string         `json:"replace"`
+	Types         []string       `json:"types"`
+}
+
+// Substitution defines a substitution of a string in a response.
+type Substitution struct {
+	From string `json:"from"`
+	To   string `json:"to"`
 }
 
 // Action defines an action.
 type Action struct {
-	Pass     string          `json:"pass"`
-	Redirect *ActionRedirect `json:"redirect"`
-	Return   *ActionReturn   `json:"return"`
-	Proxy    *ActionProxy    `json:"proxy"`
+	Pass                 string                `json:"pass"`
+	Redirect             *ActionRedirect       `json:"redirect"`
+	Return               *ActionReturn         `json:"return"`
+	Proxy                *ActionProxy          `json:"proxy"`
+	ClientCertForwarding *ClientCertForwarding `json:"clientCertForwarding"`
+}
+
+// ClientCertForwarding defines the details of the client certificate passed to the upstream in an Action.
+type ClientCertForwarding struct {
+	Headers []ClientCertHeader `json:"headers"`
+}
+
+// ClientCertHeader defines a header that passes a detail of the client certificate to the upstream.
+type ClientCertHeader struct {
+	Name     string `json:"name"`
+	Variable string `json:"variable"`
 }
 
 // ActionRedirect defines a redirect in an Action.
 type ActionRedirect struct {
-	URL  string `json:"url"`
-	Code int    `json:"code"`
+	URL           string `json:"url"`
+	Code          int    `json:"code"`
+	PreservePath  bool   `json:"preservePath"`
+	PreserveQuery bool   `json:"preserveQuery"`
 }
 
 // ActionReturn defines a return in an Action.
 type ActionReturn struct {
-	Code int    `json:"code"`
-	Type string `json:"type"`
-	Body string `json:"body"`
+	Code    int      `json:"code"`
+	Type    string   `json:"type"`
+	Body    string   `json:"body"`
+	Headers []Header `json:"headers"`
 }
 
 // ActionProxy defines a proxy in an Action.
 type ActionProxy struct {
 	Upstream        string                `json:"upstream"`
 	RewritePath     string                `json:"rewritePath"`
+	HostHeader      *ProxyHostHeader      `json:"hostHeader"`
+	ProxySSLName    string                `json:"proxySslName"`
 	RequestHeaders  *ProxyRequestHeaders  `json:"requestHeaders"`
 	ResponseHeaders *ProxyResponseHeaders `json:"responseHeaders"`
 }
 
+// ProxyHostHeader defines the Host header passed to the upstream in an ActionProxy.
+type ProxyHostHeader struct {
+	Mode  string `json:"mode"`
+	Value string `json:"value"`
+}
+
 // ProxyRequestHeaders defines the request headers manipulation in an ActionProxy.
 type ProxyRequestHeaders struct {
 	Pass *bool    `json:"pass"`
@@ -171,6 +362,13 @@ type AddHeader struct {
 type Split struct {
 	Weight int     `json:"weight"`
 	Action *Action `json:"action"`
+	Route  string  `json:"route"`
+}
+
+// StickySplits defines the sticky assignment of clients to the splits of a Route.
+type StickySplits struct {
+	Enable     bool   `json:"enable"`
+	CookieName string `json:"cookieName"`
 }
 
 // Condition defines a condition in a MatchRule.
@@ -209,8 +407,14 @@ type ErrorPageRedirect struct {
 
 // TLS defines TLS configuration for a VirtualServer.
 type TLS struct {
-	Secret   string       `json:"secret"`
-	Redirect *TLSRedirect `json:"redirect"`
+	Secret              string       `json:"secret"`
+	Certificate         string       `json:"certificate"`
+	DynamicCertificates bool         `json:"dynamicCertificates"`
+	Redirect            *TLSRedirect `json:"redirect"`
+	SessionCache        string       `json:"sessionCache"`
+	SessionTimeout      string       `json:"sessionTimeout"`
+	SessionTickets      *bool        `json:"sessionTickets"`
+	SessionTicketKey    string       `json:"sessionTicketKey"`
 }
 
 // TLSRedirect defines a redirect for a TLS.
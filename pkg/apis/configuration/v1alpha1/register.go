@@ -30,6 +30,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&GlobalConfiguration{},
 		&GlobalConfigurationList{},
+		&Policy{},
+		&PolicyList{},
 		&TransportServer{},
 		&TransportServerList{},
 	)
@@ -30,9 +30,16 @@ type GlobalConfigurationSpec struct {
 
 // Listener defines a listener.
 type Listener struct {
-	Name     string `json:"name"`
-	Port     int    `json:"port"`
-	Protocol string `json:"protocol"`
+	Name        string `json:"name"`
+	Port        int    `json:"port"`
+	Protocol    string `json:"protocol"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Backlog     int    `json:"backlog"`
+	Reuseport   bool   `json:"reuseport"`
+	Fastopen    int    `json:"fastopen"`
+	Deferred    bool   `json:"deferred"`
+	SoKeepalive string `json:"soKeepalive"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -74,15 +81,31 @@ type TransportServerListener struct {
 
 // Upstream defines an upstream.
 type Upstream struct {
-	Name    string `json:"name"`
-	Service string `json:"service"`
-	Port    int    `json:"port"`
+	Name    string                 `json:"name"`
+	Service string                 `json:"service"`
+	Port    int                    `json:"port"`
+	Weights []UpstreamServerWeight `json:"weights"`
+}
+
+// UpstreamServerWeight defines the weight of the servers of an upstream with an address.
+type UpstreamServerWeight struct {
+	Address string `json:"address"`
+	Weight  int    `json:"weight"`
 }
 
 // UpstreamParameters defines parameters for an upstream.
 type UpstreamParameters struct {
-	UDPRequests  *int `json:"udpRequests"`
-	UDPResponses *int `json:"udpResponses"`
+	UDPRequests         *int           `json:"udpRequests"`
+	UDPResponses        *int           `json:"udpResponses"`
+	ProxyTimeout        string         `json:"proxyTimeout"`
+	ProxyConnectTimeout string         `json:"proxyConnectTimeout"`
+	ProxyProtocol       *ProxyProtocol `json:"proxyProtocol"`
+}
+
+// ProxyProtocol defines the PROXY protocol for the connections to an upstream.
+type ProxyProtocol struct {
+	Enable  bool `json:"enable"`
+	Version int  `json:"version"`
 }
 
 // Action defines an action.
@@ -99,3 +122,70 @@ type TransportServerList struct {
 
 	Items []TransportServer `json:"items"`
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:validation:Optional
+
+// Policy defines the Policy resource.
+type Policy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PolicySpec `json:"spec"`
+}
+
+// PolicySpec is the spec of the Policy resource.
+// The spec includes multiple fields, where each field represents a different policy.
+// Only one policy (field) is allowed.
+type PolicySpec struct {
+	AccessControl *AccessControl `json:"accessControl"`
+	RateLimit     *RateLimit     `json:"rateLimit"`
+	JWTAuth       *JWTAuth       `json:"jwt"`
+	CombinedLimit *CombinedLimit `json:"combinedLimit"`
+}
+
+// AccessControl defines an access policy based on the source IP of a request.
+type AccessControl struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// RateLimit defines a rate limit policy.
+type RateLimit struct {
+	Rate       string `json:"rate"`
+	Key        string `json:"key"`
+	ZoneSize   string `json:"zoneSize"`
+	Burst      *int   `json:"burst"`
+	NoDelay    *bool  `json:"noDelay"`
+	RejectCode *int   `json:"rejectCode"`
+}
+
+// CombinedLimit defines a policy that limits both the rate of requests and the number of connections
+// per the same key.
+type CombinedLimit struct {
+	Key         string `json:"key"`
+	ZoneSize    string `json:"zoneSize"`
+	Rate        string `json:"rate"`
+	Burst       *int   `json:"burst"`
+	NoDelay     *bool  `json:"noDelay"`
+	Connections int    `json:"connections"`
+	RejectCode  *int   `json:"rejectCode"`
+}
+
+// JWTAuth holds JWT authentication configuration.
+type JWTAuth struct {
+	Realm  string `json:"realm"`
+	Secret string `json:"secret"`
+	Token  string `json:"token"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PolicyList is a list of the Policy resources.
+type PolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Policy `json:"items"`
+}
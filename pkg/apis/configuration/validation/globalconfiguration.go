@@ -2,7 +2,9 @@ package validation
 
 import (
 	"fmt"
+	"net"
 
+	"github.com/nginxinc/kubernetes-ingress/internal/configs"
 	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -11,11 +13,13 @@ import (
 
 // GlobalConfigurationValidator validates a GlobalConfiguration resource.
 type GlobalConfigurationValidator struct {
-	forbiddenListenerPorts map[int]bool
+	forbiddenListenerPorts map[int]string
 }
 
-// NewGlobalConfigurationValidator creates a new GlobalConfigurationValidator.
-func NewGlobalConfigurationValidator(forbiddenListenerPorts map[int]bool) *GlobalConfigurationValidator {
+// NewGlobalConfigurationValidator creates a new GlobalConfigurationValidator. forbiddenListenerPorts maps the ports
+// that the listeners can't use to the names of the listeners of NGINX or the Ingress Controller that already use them,
+// for example, 80 to "the HTTP listener".
+func NewGlobalConfigurationValidator(forbiddenListenerPorts map[int]string) *GlobalConfigurationValidator {
 	return &GlobalConfigurationValidator{
 		forbiddenListenerPorts: forbiddenListenerPorts,
 	}
@@ -35,7 +39,7 @@ func (gcv *GlobalConfigurationValidator) validateListeners(listeners []v1alpha1.
 	allErrs := field.ErrorList{}
 
 	listenerNames := sets.String{}
-	portProtocolCombinations := sets.String{}
+	portProtocolCombinations := make(map[string]string)
 
 	for i, l := range listeners {
 		idxPath := fieldPath.Index(i)
@@ -46,12 +50,12 @@ func (gcv *GlobalConfigurationValidator) validateListeners(listeners []v1alpha1.
 			allErrs = append(allErrs, listenerErrs...)
 		} else if listenerNames.Has(l.Name) {
 			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), l.Name))
-		} else if portProtocolCombinations.Has(portProtocolKey) {
-			msg := fmt.Sprintf("Duplicated port/protocol combination %s", portProtocolKey)
-			allErrs = append(allErrs, field.Duplicate(fieldPath, msg))
+		} else if name, exists := portProtocolCombinations[portProtocolKey]; exists {
+			msg := fmt.Sprintf("port %s is already used by the listener %s", portProtocolKey, name)
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("port"), msg))
 		} else {
 			listenerNames.Insert(l.Name)
-			portProtocolCombinations.Insert(portProtocolKey)
+			portProtocolCombinations[portProtocolKey] = l.Name
 		}
 	}
 
@@ -68,6 +72,99 @@ func (gcv *GlobalConfigurationValidator) validateListener(listener v1alpha1.List
 	allErrs = append(allErrs, validateGlobalConfigurationListenerName(listener.Name, fieldPath.Child("name"))...)
 	allErrs = append(allErrs, gcv.validateListenerPort(listener.Port, fieldPath.Child("port"))...)
 	allErrs = append(allErrs, validateListenerProtocol(listener.Protocol, fieldPath.Child("protocol"))...)
+	allErrs = append(allErrs, validateListenerIPv4(listener.IPv4, fieldPath.Child("ipv4"))...)
+	allErrs = append(allErrs, validateListenerIPv6(listener.IPv6, fieldPath.Child("ipv6"))...)
+	allErrs = append(allErrs, validateListenerBacklog(listener.Backlog, listener.Protocol, fieldPath.Child("backlog"))...)
+	allErrs = append(allErrs, validateListenerFastopen(listener.Fastopen, listener.Protocol, fieldPath.Child("fastopen"))...)
+	allErrs = append(allErrs, validateListenerSoKeepalive(listener.SoKeepalive, listener.Protocol, fieldPath.Child("soKeepalive"))...)
+
+	if listener.Deferred && listener.Protocol == "UDP" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("deferred"), "is not supported for UDP listeners"))
+	}
+
+	return allErrs
+}
+
+func validateListenerIPv4(address string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if address == "" {
+		return allErrs
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil || ip.To4() == nil {
+		return append(allErrs, field.Invalid(fieldPath, address, "must be a valid IPv4 address"))
+	}
+
+	return allErrs
+}
+
+func validateListenerIPv6(address string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if address == "" {
+		return allErrs
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil || ip.To4() != nil {
+		return append(allErrs, field.Invalid(fieldPath, address, "must be a valid IPv6 address"))
+	}
+
+	return allErrs
+}
+
+func validateListenerBacklog(backlog int, protocol string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if backlog == 0 {
+		return allErrs
+	}
+
+	if backlog < 0 {
+		return append(allErrs, field.Invalid(fieldPath, backlog, "must be a positive integer"))
+	}
+
+	if protocol == "UDP" {
+		return append(allErrs, field.Forbidden(fieldPath, "is not supported for UDP listeners"))
+	}
+
+	return allErrs
+}
+
+func validateListenerFastopen(fastopen int, protocol string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if fastopen == 0 {
+		return allErrs
+	}
+
+	if fastopen < 0 {
+		return append(allErrs, field.Invalid(fieldPath, fastopen, "must be a positive integer"))
+	}
+
+	if protocol == "UDP" {
+		return append(allErrs, field.Forbidden(fieldPath, "is not supported for UDP listeners"))
+	}
+
+	return allErrs
+}
+
+func validateListenerSoKeepalive(soKeepalive string, protocol string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if soKeepalive == "" {
+		return allErrs
+	}
+
+	if _, err := configs.ParseSoKeepalive(soKeepalive); err != nil {
+		return append(allErrs, field.Invalid(fieldPath, soKeepalive, "must be on, off or [keepidle]:[keepintvl]:[keepcnt], for example, 30m::10"))
+	}
+
+	if protocol == "UDP" {
+		return append(allErrs, field.Forbidden(fieldPath, "is not supported for UDP listeners"))
+	}
 
 	return allErrs
 }
@@ -85,8 +182,8 @@ func validateGlobalConfigurationListenerName(name string, fieldPath *field.Path)
 func (gcv *GlobalConfigurationValidator) validateListenerPort(port int, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
-	if gcv.forbiddenListenerPorts[port] {
-		msg := fmt.Sprintf("port %v is forbidden", port)
+	if user, forbidden := gcv.forbiddenListenerPorts[port]; forbidden {
+		msg := fmt.Sprintf("port %v is already used by %s", port, user)
 		return append(allErrs, field.Forbidden(fieldPath, msg))
 	}
 
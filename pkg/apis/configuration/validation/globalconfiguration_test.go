@@ -37,9 +37,78 @@ func TestValidateGlobalConfiguration(t *testing.T) {
 	}
 }
 
+func TestValidateGlobalConfigurationFails(t *testing.T) {
+	tests := []struct {
+		listeners []v1alpha1.Listener
+		expected  string
+		msg       string
+	}{
+		{
+			listeners: []v1alpha1.Listener{
+				{
+					Name:     "tcp-listener",
+					Port:     80,
+					Protocol: "TCP",
+				},
+			},
+			expected: "spec.listeners[0].port: Forbidden: port 80 is already used by the HTTP listener",
+			msg:      "TCP listener with the port of the HTTP listener",
+		},
+		{
+			listeners: []v1alpha1.Listener{
+				{
+					Name:     "udp-listener",
+					Port:     443,
+					Protocol: "UDP",
+				},
+			},
+			expected: "spec.listeners[0].port: Forbidden: port 443 is already used by the HTTPS listener",
+			msg:      "UDP listener with the port of the HTTPS listener",
+		},
+		{
+			listeners: []v1alpha1.Listener{
+				{
+					Name:     "dns-tcp",
+					Port:     5353,
+					Protocol: "TCP",
+				},
+				{
+					Name:     "other-tcp",
+					Port:     5353,
+					Protocol: "TCP",
+				},
+			},
+			expected: "spec.listeners[1].port: Forbidden: port 5353/TCP is already used by the listener dns-tcp",
+			msg:      "two stream listeners with the same port",
+		},
+	}
+
+	gcv := NewGlobalConfigurationValidator(map[int]string{
+		80:  "the HTTP listener",
+		443: "the HTTPS listener",
+	})
+
+	for _, test := range tests {
+		globalConfiguration := v1alpha1.GlobalConfiguration{
+			Spec: v1alpha1.GlobalConfigurationSpec{
+				Listeners: test.listeners,
+			},
+		}
+
+		err := gcv.ValidateGlobalConfiguration(&globalConfiguration)
+		if err == nil {
+			t.Errorf("ValidateGlobalConfiguration() returned no error for the case of %s", test.msg)
+			continue
+		}
+		if err.Error() != test.expected {
+			t.Errorf("ValidateGlobalConfiguration() returned error %q but expected %q for the case of %s", err.Error(), test.expected, test.msg)
+		}
+	}
+}
+
 func TestValidateListenerPort(t *testing.T) {
-	forbiddenListenerPorts := map[int]bool{
-		1234: true,
+	forbiddenListenerPorts := map[int]string{
+		1234: "the HTTP listener",
 	}
 
 	gcv := &GlobalConfigurationValidator{
@@ -127,17 +196,49 @@ func TestValidateListenersFails(t *testing.T) {
 }
 
 func TestValidateListener(t *testing.T) {
-	listener := v1alpha1.Listener{
-		Name:     "tcp-listener",
-		Port:     53,
-		Protocol: "TCP",
+	listeners := []v1alpha1.Listener{
+		{
+			Name:     "tcp-listener",
+			Port:     53,
+			Protocol: "TCP",
+		},
+		{
+			Name:     "tcp-listener",
+			Port:     53,
+			Protocol: "TCP",
+			IPv4:     "10.0.0.1",
+			IPv6:     "fd00::1",
+		},
+		{
+			Name:      "tcp-listener",
+			Port:      53,
+			Protocol:  "TCP",
+			Backlog:   1024,
+			Reuseport: true,
+		},
+		{
+			Name:        "tcp-listener",
+			Port:        53,
+			Protocol:    "TCP",
+			Fastopen:    256,
+			Deferred:    true,
+			SoKeepalive: "30m::10",
+		},
+		{
+			Name:      "udp-listener",
+			Port:      53,
+			Protocol:  "UDP",
+			Reuseport: true,
+		},
 	}
 
 	gcv := createGlobalConfigurationValidator()
 
-	allErrs := gcv.validateListener(listener, field.NewPath("listener"))
-	if len(allErrs) > 0 {
-		t.Errorf("validateListener() returned errors %v for valid intput", allErrs)
+	for _, listener := range listeners {
+		allErrs := gcv.validateListener(listener, field.NewPath("listener"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateListener() returned errors %v for valid intput %+v", allErrs, listener)
+		}
 	}
 }
 
@@ -178,6 +279,105 @@ func TestValidateListenerFails(t *testing.T) {
 			},
 			msg: "name of a built-in listener",
 		},
+		{
+			Listener: v1alpha1.Listener{
+				Name:     "tcp-listener",
+				Port:     2201,
+				Protocol: "TCP",
+				IPv4:     "10.0.0",
+			},
+			msg: "invalid ipv4",
+		},
+		{
+			Listener: v1alpha1.Listener{
+				Name:     "tcp-listener",
+				Port:     2201,
+				Protocol: "TCP",
+				IPv4:     "fd00::1",
+			},
+			msg: "ipv6 address in ipv4",
+		},
+		{
+			Listener: v1alpha1.Listener{
+				Name:     "tcp-listener",
+				Port:     2201,
+				Protocol: "TCP",
+				IPv6:     "10.0.0.1",
+			},
+			msg: "ipv4 address in ipv6",
+		},
+		{
+			Listener: v1alpha1.Listener{
+				Name:     "tcp-listener",
+				Port:     2201,
+				Protocol: "TCP",
+				Backlog:  -1,
+			},
+			msg: "negative backlog",
+		},
+		{
+			Listener: v1alpha1.Listener{
+				Name:     "udp-listener",
+				Port:     2201,
+				Protocol: "UDP",
+				Backlog:  1024,
+			},
+			msg: "backlog for a UDP listener",
+		},
+		{
+			Listener: v1alpha1.Listener{
+				Name:     "tcp-listener",
+				Port:     2201,
+				Protocol: "TCP",
+				Fastopen: -1,
+			},
+			msg: "negative fastopen",
+		},
+		{
+			Listener: v1alpha1.Listener{
+				Name:     "udp-listener",
+				Port:     2201,
+				Protocol: "UDP",
+				Fastopen: 256,
+			},
+			msg: "fastopen for a UDP listener",
+		},
+		{
+			Listener: v1alpha1.Listener{
+				Name:     "udp-listener",
+				Port:     2201,
+				Protocol: "UDP",
+				Deferred: true,
+			},
+			msg: "deferred for a UDP listener",
+		},
+		{
+			Listener: v1alpha1.Listener{
+				Name:        "tcp-listener",
+				Port:        2201,
+				Protocol:    "TCP",
+				SoKeepalive: "yes",
+			},
+			msg: "invalid soKeepalive",
+		},
+		{
+			Listener: v1alpha1.Listener{
+				Name:        "udp-listener",
+				Port:        2201,
+				Protocol:    "UDP",
+				SoKeepalive: "on",
+			},
+			msg: "soKeepalive for a UDP listener",
+		},
+		{
+			Listener: v1alpha1.Listener{
+				Name:     "tcp-listener",
+				Port:     2201,
+				Protocol: "TCP",
+				IPv6:     "fd00::zz",
+			},
+			msg: "invalid ipv6",
+		},
 	}
 
 	gcv := createGlobalConfigurationValidator()
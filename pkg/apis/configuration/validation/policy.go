@@ -0,0 +1,242 @@
+package validation
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	v1alpha1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidatePolicy validates a Policy.
+func ValidatePolicy(policy *v1alpha1.Policy, isPlus bool) error {
+	allErrs := validatePolicySpec(&policy.Spec, field.NewPath("spec"), isPlus)
+	return allErrs.ToAggregate()
+}
+
+func validatePolicySpec(spec *v1alpha1.PolicySpec, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	fieldCount := 0
+
+	if spec.AccessControl != nil {
+		allErrs = append(allErrs, validateAccessControl(spec.AccessControl, fieldPath.Child("accessControl"))...)
+		fieldCount++
+	}
+
+	if spec.RateLimit != nil {
+		allErrs = append(allErrs, validateRateLimit(spec.RateLimit, fieldPath.Child("rateLimit"))...)
+		fieldCount++
+	}
+
+	if spec.JWTAuth != nil {
+		if !isPlus {
+			return append(allErrs, field.Forbidden(fieldPath.Child("jwt"), "jwt secrets are only supported in NGINX Plus"))
+		}
+
+		allErrs = append(allErrs, validateJWT(spec.JWTAuth, fieldPath.Child("jwt"))...)
+		fieldCount++
+	}
+
+	if spec.CombinedLimit != nil {
+		allErrs = append(allErrs, validateCombinedLimit(spec.CombinedLimit, fieldPath.Child("combinedLimit"))...)
+		fieldCount++
+	}
+
+	if fieldCount != 1 {
+		msg := "must specify exactly one of: `accessControl`, `rateLimit`, `jwt`, `combinedLimit`"
+		if fieldCount > 1 {
+			msg = fmt.Sprintf("%s; only one policy is allowed per Policy resource", msg)
+		}
+		allErrs = append(allErrs, field.Invalid(fieldPath, "", msg))
+	}
+
+	return allErrs
+}
+
+func validateAccessControl(accessControl *v1alpha1.AccessControl, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	fieldCount := 0
+
+	if accessControl.Allow != nil {
+		for i, ipOrCIDR := range accessControl.Allow {
+			allErrs = append(allErrs, validateIPorCIDR(ipOrCIDR, fieldPath.Child("allow").Index(i))...)
+		}
+		fieldCount++
+	}
+
+	if accessControl.Deny != nil {
+		for i, ipOrCIDR := range accessControl.Deny {
+			allErrs = append(allErrs, validateIPorCIDR(ipOrCIDR, fieldPath.Child("deny").Index(i))...)
+		}
+		fieldCount++
+	}
+
+	if fieldCount != 1 {
+		allErrs = append(allErrs, field.Invalid(fieldPath, "", "must specify exactly one of: `allow` or `deny`"))
+	}
+
+	return allErrs
+}
+
+func validateIPorCIDR(ipOrCIDR string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	_, _, err := net.ParseCIDR(ipOrCIDR)
+	if err == nil {
+		// valid CIDR
+		return allErrs
+	}
+
+	ip := net.ParseIP(ipOrCIDR)
+	if ip != nil {
+		// valid IP
+		return allErrs
+	}
+
+	return append(allErrs, field.Invalid(fieldPath, ipOrCIDR, "must be a CIDR or IP"))
+}
+
+const rateFmt = `[1-9]\d*r/[sm]`
+const rateErrMsg = "must consist of numeric characters followed by a valid rate suffix. 'r/s|r/m'"
+
+var rateRegexp = regexp.MustCompile("^" + rateFmt + "$")
+
+// rateLimitKeySpecialVariables includes the prefixes of the NGINX variables allowed in the key of a rate limit.
+var rateLimitKeySpecialVariables = []string{"arg_", "http_", "cookie_"}
+
+// rateLimitKeyVariables includes the NGINX variables allowed in the key of a rate limit.
+var rateLimitKeyVariables = map[string]bool{
+	"binary_remote_addr": true,
+	"request_uri":        true,
+	"remote_addr":        true,
+	"uri":                true,
+	"args":               true,
+}
+
+func validateRateLimit(rateLimit *v1alpha1.RateLimit, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateRate(rateLimit.Rate, fieldPath.Child("rate"))...)
+	allErrs = append(allErrs, validateRateLimitKey(rateLimit.Key, fieldPath.Child("key"))...)
+	allErrs = append(allErrs, validateSize(rateLimit.ZoneSize, fieldPath.Child("zoneSize"))...)
+	allErrs = append(allErrs, validatePositiveIntOrZeroFromPointer(rateLimit.Burst, fieldPath.Child("burst"))...)
+
+	if rateLimit.RejectCode != nil {
+		if *rateLimit.RejectCode < 400 || *rateLimit.RejectCode > 599 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("rejectCode"), *rateLimit.RejectCode, "must be within the range [400-599]"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateCombinedLimit(combinedLimit *v1alpha1.CombinedLimit, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateRate(combinedLimit.Rate, fieldPath.Child("rate"))...)
+	allErrs = append(allErrs, validateRateLimitKey(combinedLimit.Key, fieldPath.Child("key"))...)
+	allErrs = append(allErrs, validateSize(combinedLimit.ZoneSize, fieldPath.Child("zoneSize"))...)
+	allErrs = append(allErrs, validatePositiveIntOrZeroFromPointer(combinedLimit.Burst, fieldPath.Child("burst"))...)
+
+	if combinedLimit.Connections <= 0 {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("connections"), "must be positive"))
+	}
+
+	if combinedLimit.RejectCode != nil {
+		if *combinedLimit.RejectCode < 400 || *combinedLimit.RejectCode > 599 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("rejectCode"), *combinedLimit.RejectCode, "must be within the range [400-599]"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateRate(rate string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if rate == "" {
+		return append(allErrs, field.Required(fieldPath, ""))
+	}
+
+	if !rateRegexp.MatchString(rate) {
+		msg := validation.RegexError(rateErrMsg, rateFmt, "16r/s", "32r/m")
+		return append(allErrs, field.Invalid(fieldPath, rate, msg))
+	}
+
+	return allErrs
+}
+
+func validateRateLimitKey(key string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if key == "" {
+		return append(allErrs, field.Required(fieldPath, ""))
+	}
+
+	if !escapedStringsFmtRegexp.MatchString(key) {
+		msg := validation.RegexError(escapedStringsErrMsg, escapedStringsFmt, "${binary_remote_addr}", "${binary_remote_addr}${request_uri}")
+		allErrs = append(allErrs, field.Invalid(fieldPath, key, msg))
+	}
+
+	allErrs = append(allErrs, validateStringWithVariables(key, fieldPath, rateLimitKeySpecialVariables, rateLimitKeyVariables)...)
+
+	return allErrs
+}
+
+// jwtTokenSpecialVariables includes the prefixes of the NGINX variables allowed in the token of a JWT policy.
+var jwtTokenSpecialVariables = []string{"arg_", "http_", "cookie_"}
+
+func validateJWT(jwt *v1alpha1.JWTAuth, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if jwt.Realm == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("realm"), ""))
+	} else if !escapedStringsFmtRegexp.MatchString(jwt.Realm) {
+		msg := validation.RegexError(escapedStringsErrMsg, escapedStringsFmt, "MyAPI", `My \"API\"`)
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("realm"), jwt.Realm, msg))
+	}
+
+	if jwt.Secret == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("secret"), ""))
+	} else {
+		allErrs = append(allErrs, validateSecretReference(jwt.Secret, fieldPath.Child("secret"))...)
+	}
+
+	allErrs = append(allErrs, validateJWTToken(jwt.Token, fieldPath.Child("token"))...)
+
+	return allErrs
+}
+
+func validateJWTToken(token string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if token == "" {
+		return allErrs
+	}
+
+	nginxVars := strings.Split(token, "$")
+	if len(nginxVars) != 2 || nginxVars[0] != "" {
+		return append(allErrs, field.Invalid(fieldPath, token, "must be a single NGINX variable, for example $http_token"))
+	}
+
+	nVar := token[1:]
+
+	special := false
+	for _, specialVar := range jwtTokenSpecialVariables {
+		if strings.HasPrefix(nVar, specialVar) {
+			special = true
+			break
+		}
+	}
+
+	if !special {
+		return append(allErrs, field.Invalid(fieldPath, token, fmt.Sprintf("must be a variable with one of the prefixes: %s", strings.Join(jwtTokenSpecialVariables, ", "))))
+	}
+
+	return append(allErrs, validateSpecialVariable(nVar, fieldPath)...)
+}
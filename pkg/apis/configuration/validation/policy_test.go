@@ -0,0 +1,483 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidatePolicy(t *testing.T) {
+	tests := []struct {
+		policy *v1alpha1.Policy
+		isPlus bool
+		msg    string
+	}{
+		{
+			policy: &v1alpha1.Policy{
+				Spec: v1alpha1.PolicySpec{
+					AccessControl: &v1alpha1.AccessControl{
+						Allow: []string{"127.0.0.1", "10.0.0.0/8"},
+					},
+				},
+			},
+			isPlus: false,
+			msg:    "allow access control policy",
+		},
+		{
+			policy: &v1alpha1.Policy{
+				Spec: v1alpha1.PolicySpec{
+					RateLimit: &v1alpha1.RateLimit{
+						Rate:     "10r/s",
+						Key:      "${binary_remote_addr}",
+						ZoneSize: "10M",
+					},
+				},
+			},
+			isPlus: false,
+			msg:    "rate limit policy",
+		},
+		{
+			policy: &v1alpha1.Policy{
+				Spec: v1alpha1.PolicySpec{
+					JWTAuth: &v1alpha1.JWTAuth{
+						Realm:  "My API",
+						Secret: "jwk-secret",
+						Token:  "$cookie_auth_token",
+					},
+				},
+			},
+			isPlus: true,
+			msg:    "jwt policy",
+		},
+		{
+			policy: &v1alpha1.Policy{
+				Spec: v1alpha1.PolicySpec{
+					CombinedLimit: &v1alpha1.CombinedLimit{
+						Rate:        "10r/s",
+						Key:         "${binary_remote_addr}",
+						Connections: 10,
+					},
+				},
+			},
+			isPlus: false,
+			msg:    "combined limit policy",
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidatePolicy(test.policy, test.isPlus)
+		if err != nil {
+			t.Errorf("ValidatePolicy() returned error %v for valid input for the case of %s", err, test.msg)
+		}
+	}
+}
+
+func TestValidatePolicyFails(t *testing.T) {
+	tests := []struct {
+		policy *v1alpha1.Policy
+		isPlus bool
+		msg    string
+	}{
+		{
+			policy: &v1alpha1.Policy{
+				Spec: v1alpha1.PolicySpec{},
+			},
+			isPlus: false,
+			msg:    "empty policy spec",
+		},
+		{
+			policy: &v1alpha1.Policy{
+				Spec: v1alpha1.PolicySpec{
+					AccessControl: &v1alpha1.AccessControl{
+						Allow: []string{"127.0.0.1"},
+					},
+					RateLimit: &v1alpha1.RateLimit{
+						Rate: "10r/s",
+						Key:  "${binary_remote_addr}",
+					},
+				},
+			},
+			isPlus: false,
+			msg:    "multiple policies in the spec",
+		},
+		{
+			policy: &v1alpha1.Policy{
+				Spec: v1alpha1.PolicySpec{
+					JWTAuth: &v1alpha1.JWTAuth{
+						Realm:  "My API",
+						Secret: "jwk-secret",
+					},
+				},
+			},
+			isPlus: false,
+			msg:    "jwt policy in NGINX",
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidatePolicy(test.policy, test.isPlus)
+		if err == nil {
+			t.Errorf("ValidatePolicy() returned no error for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateAccessControl(t *testing.T) {
+	validInput := []*v1alpha1.AccessControl{
+		{
+			Allow: []string{},
+		},
+		{
+			Allow: []string{"127.0.0.1"},
+		},
+		{
+			Deny: []string{},
+		},
+		{
+			Deny: []string{"127.0.0.1"},
+		},
+	}
+
+	for _, input := range validInput {
+		allErrs := validateAccessControl(input, field.NewPath("accessControl"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateAccessControl(%+v) returned errors %v for valid input", input, allErrs)
+		}
+	}
+}
+
+func TestValidateAccessControlFails(t *testing.T) {
+	tests := []struct {
+		accessControl *v1alpha1.AccessControl
+		msg           string
+	}{
+		{
+			accessControl: &v1alpha1.AccessControl{
+				Allow: nil,
+				Deny:  nil,
+			},
+			msg: "neither allow nor deny is defined",
+		},
+		{
+			accessControl: &v1alpha1.AccessControl{
+				Allow: []string{},
+				Deny:  []string{},
+			},
+			msg: "both allow and deny are defined",
+		},
+		{
+			accessControl: &v1alpha1.AccessControl{
+				Allow: []string{"invalid"},
+			},
+			msg: "invalid allow",
+		},
+		{
+			accessControl: &v1alpha1.AccessControl{
+				Deny: []string{"10.0.0."},
+			},
+			msg: "invalid deny",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateAccessControl(test.accessControl, field.NewPath("accessControl"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateAccessControl() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateRateLimit(t *testing.T) {
+	burst := 5
+	rejectCode := 429
+
+	validInput := []*v1alpha1.RateLimit{
+		{
+			Rate: "10r/s",
+			Key:  "${binary_remote_addr}",
+		},
+		{
+			Rate:       "30r/m",
+			Key:        "${http_x_user_id}${request_uri}",
+			ZoneSize:   "64k",
+			Burst:      &burst,
+			RejectCode: &rejectCode,
+		},
+	}
+
+	for _, input := range validInput {
+		allErrs := validateRateLimit(input, field.NewPath("rateLimit"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateRateLimit(%+v) returned errors %v for valid input", input, allErrs)
+		}
+	}
+}
+
+func TestValidateRateLimitFails(t *testing.T) {
+	negativeBurst := -1
+	invalidRejectCode := 600
+
+	tests := []struct {
+		rateLimit *v1alpha1.RateLimit
+		msg       string
+	}{
+		{
+			rateLimit: &v1alpha1.RateLimit{
+				Key: "${binary_remote_addr}",
+			},
+			msg: "missing rate",
+		},
+		{
+			rateLimit: &v1alpha1.RateLimit{
+				Rate: "10r/h",
+				Key:  "${binary_remote_addr}",
+			},
+			msg: "invalid rate",
+		},
+		{
+			rateLimit: &v1alpha1.RateLimit{
+				Rate: "10r/s",
+			},
+			msg: "missing key",
+		},
+		{
+			rateLimit: &v1alpha1.RateLimit{
+				Rate: "10r/s",
+				Key:  "${host}",
+			},
+			msg: "key with an unsupported variable",
+		},
+		{
+			rateLimit: &v1alpha1.RateLimit{
+				Rate: "10r/s",
+				Key:  `"${binary_remote_addr}`,
+			},
+			msg: "key with an unescaped quote",
+		},
+		{
+			rateLimit: &v1alpha1.RateLimit{
+				Rate:     "10r/s",
+				Key:      "${binary_remote_addr}",
+				ZoneSize: "10G",
+			},
+			msg: "invalid zone size",
+		},
+		{
+			rateLimit: &v1alpha1.RateLimit{
+				Rate:  "10r/s",
+				Key:   "${binary_remote_addr}",
+				Burst: &negativeBurst,
+			},
+			msg: "negative burst",
+		},
+		{
+			rateLimit: &v1alpha1.RateLimit{
+				Rate:       "10r/s",
+				Key:        "${binary_remote_addr}",
+				RejectCode: &invalidRejectCode,
+			},
+			msg: "reject code out of range",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateRateLimit(test.rateLimit, field.NewPath("rateLimit"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateRateLimit() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateCombinedLimit(t *testing.T) {
+	burst := 5
+	rejectCode := 429
+
+	validInput := []*v1alpha1.CombinedLimit{
+		{
+			Rate:        "10r/s",
+			Key:         "${binary_remote_addr}",
+			Connections: 10,
+		},
+		{
+			Rate:        "30r/m",
+			Key:         "${http_x_user_id}",
+			ZoneSize:    "64k",
+			Burst:       &burst,
+			Connections: 1,
+			RejectCode:  &rejectCode,
+		},
+	}
+
+	for _, input := range validInput {
+		allErrs := validateCombinedLimit(input, field.NewPath("combinedLimit"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateCombinedLimit(%+v) returned errors %v for valid input", input, allErrs)
+		}
+	}
+}
+
+func TestValidateCombinedLimitFails(t *testing.T) {
+	negativeBurst := -1
+	invalidRejectCode := 399
+
+	tests := []struct {
+		combinedLimit *v1alpha1.CombinedLimit
+		msg           string
+	}{
+		{
+			combinedLimit: &v1alpha1.CombinedLimit{
+				Key:         "${binary_remote_addr}",
+				Connections: 10,
+			},
+			msg: "missing rate",
+		},
+		{
+			combinedLimit: &v1alpha1.CombinedLimit{
+				Rate:        "10r/s",
+				Connections: 10,
+			},
+			msg: "missing key",
+		},
+		{
+			combinedLimit: &v1alpha1.CombinedLimit{
+				Rate: "10r/s",
+				Key:  "${binary_remote_addr}",
+			},
+			msg: "missing connections",
+		},
+		{
+			combinedLimit: &v1alpha1.CombinedLimit{
+				Rate:        "10r/s",
+				Key:         "${binary_remote_addr}",
+				Connections: -1,
+			},
+			msg: "negative connections",
+		},
+		{
+			combinedLimit: &v1alpha1.CombinedLimit{
+				Rate:        "10r/s",
+				Key:         "${binary_remote_addr}",
+				ZoneSize:    "10G",
+				Connections: 10,
+			},
+			msg: "invalid zone size",
+		},
+		{
+			combinedLimit: &v1alpha1.CombinedLimit{
+				Rate:        "10r/s",
+				Key:         "${binary_remote_addr}",
+				Burst:       &negativeBurst,
+				Connections: 10,
+			},
+			msg: "negative burst",
+		},
+		{
+			combinedLimit: &v1alpha1.CombinedLimit{
+				Rate:        "10r/s",
+				Key:         "${binary_remote_addr}",
+				Connections: 10,
+				RejectCode:  &invalidRejectCode,
+			},
+			msg: "reject code out of range",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCombinedLimit(test.combinedLimit, field.NewPath("combinedLimit"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateCombinedLimit() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateJWT(t *testing.T) {
+	validInput := []*v1alpha1.JWTAuth{
+		{
+			Realm:  "My API",
+			Secret: "jwk-secret",
+		},
+		{
+			Realm:  `My \"API\"`,
+			Secret: "jwk-secret",
+			Token:  "$http_token",
+		},
+		{
+			Realm:  "My API",
+			Secret: "jwk-secret",
+			Token:  "$arg_token",
+		},
+	}
+
+	for _, input := range validInput {
+		allErrs := validateJWT(input, field.NewPath("jwt"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateJWT(%+v) returned errors %v for valid input", input, allErrs)
+		}
+	}
+}
+
+func TestValidateJWTFails(t *testing.T) {
+	tests := []struct {
+		jwt *v1alpha1.JWTAuth
+		msg string
+	}{
+		{
+			jwt: &v1alpha1.JWTAuth{
+				Secret: "jwk-secret",
+			},
+			msg: "missing realm",
+		},
+		{
+			jwt: &v1alpha1.JWTAuth{
+				Realm:  `My "API"`,
+				Secret: "jwk-secret",
+			},
+			msg: "realm with an unescaped quote",
+		},
+		{
+			jwt: &v1alpha1.JWTAuth{
+				Realm: "My API",
+			},
+			msg: "missing secret",
+		},
+		{
+			jwt: &v1alpha1.JWTAuth{
+				Realm:  "My API",
+				Secret: "jwk_secret",
+			},
+			msg: "invalid secret name",
+		},
+		{
+			jwt: &v1alpha1.JWTAuth{
+				Realm:  "My API",
+				Secret: "jwk-secret",
+				Token:  "http_token",
+			},
+			msg: "token without a variable",
+		},
+		{
+			jwt: &v1alpha1.JWTAuth{
+				Realm:  "My API",
+				Secret: "jwk-secret",
+				Token:  "$host",
+			},
+			msg: "token with an unsupported variable",
+		},
+		{
+			jwt: &v1alpha1.JWTAuth{
+				Realm:  "My API",
+				Secret: "jwk-secret",
+				Token:  "$http_token$arg_token",
+			},
+			msg: "token with multiple variables",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateJWT(test.jwt, field.NewPath("jwt"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateJWT() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
@@ -150,11 +150,36 @@ func validateTransportServerUpstreams(upstreams []v1alpha1.Upstream, fieldPath *
 		for _, msg := range validation.IsValidPortNum(u.Port) {
 			allErrs = append(allErrs, field.Invalid(idxPath.Child("port"), u.Port, msg))
 		}
+
+		allErrs = append(allErrs, validateUpstreamServerWeights(u.Weights, idxPath.Child("weights"))...)
 	}
 
 	return allErrs, upstreamNames
 }
 
+func validateUpstreamServerWeights(weights []v1alpha1.UpstreamServerWeight, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	addresses := sets.String{}
+	for i, w := range weights {
+		idxPath := fieldPath.Index(i)
+
+		allErrs = append(allErrs, validateEndpointAddress(w.Address, idxPath.Child("address"))...)
+
+		if addresses.Has(w.Address) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("address"), w.Address))
+		} else {
+			addresses.Insert(w.Address)
+		}
+
+		if w.Weight <= 0 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("weight"), w.Weight, "must be positive"))
+		}
+	}
+
+	return allErrs
+}
+
 func validateTransportServerUpstreamParameters(upstreamParameters *v1alpha1.UpstreamParameters, fieldPath *field.Path, protocol string) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -164,6 +189,9 @@ func validateTransportServerUpstreamParameters(upstreamParameters *v1alpha1.Upst
 
 	allErrs = append(allErrs, validateUDPUpstreamParameter(upstreamParameters.UDPRequests, fieldPath.Child("udpRequests"), protocol)...)
 	allErrs = append(allErrs, validateUDPUpstreamParameter(upstreamParameters.UDPResponses, fieldPath.Child("udpResponses"), protocol)...)
+	allErrs = append(allErrs, validateTime(upstreamParameters.ProxyTimeout, fieldPath.Child("proxyTimeout"))...)
+	allErrs = append(allErrs, validateTime(upstreamParameters.ProxyConnectTimeout, fieldPath.Child("proxyConnectTimeout"))...)
+	allErrs = append(allErrs, validateProxyProtocol(upstreamParameters.ProxyProtocol, fieldPath.Child("proxyProtocol"), protocol)...)
 
 	return allErrs
 }
@@ -178,6 +206,26 @@ func validateUDPUpstreamParameter(parameter *int, fieldPath *field.Path, protoco
 	return validatePositiveIntOrZeroFromPointer(parameter, fieldPath)
 }
 
+// validateProxyProtocol validates the PROXY protocol for the connections to an upstream. NGINX sends only
+// version 1 of the PROXY protocol, which is defined for TCP connections.
+func validateProxyProtocol(proxyProtocol *v1alpha1.ProxyProtocol, fieldPath *field.Path, protocol string) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if proxyProtocol == nil || !proxyProtocol.Enable {
+		return allErrs
+	}
+
+	if protocol == "UDP" {
+		return append(allErrs, field.Forbidden(fieldPath, "is not allowed for UDP TransportServers"))
+	}
+
+	if proxyProtocol.Version != 0 && proxyProtocol.Version != 1 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("version"), proxyProtocol.Version, "must be 1: NGINX supports sending only version 1 of the PROXY protocol"))
+	}
+
+	return allErrs
+}
+
 func validateTransportServerAction(action *v1alpha1.Action, fieldPath *field.Path, upstreamNames sets.String) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -96,6 +96,23 @@ func TestValidateTransportServerUpstreams(t *testing.T) {
 			},
 			msg: "2 valid upstreams",
 		},
+		{
+			upstreams: []v1alpha1.Upstream{
+				{
+					Name:    "upstream1",
+					Service: "test-1",
+					Port:    80,
+					Weights: []v1alpha1.UpstreamServerWeight{
+						{Address: "10.0.0.1:80", Weight: 5},
+						{Address: "10.0.0.2", Weight: 1},
+					},
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "valid upstream with weights",
+		},
 	}
 
 	for _, test := range tests {
@@ -170,6 +187,55 @@ func TestValidateTransportServerUpstreamsFails(t *testing.T) {
 			},
 			msg: "duplicated upstreams",
 		},
+		{
+			upstreams: []v1alpha1.Upstream{
+				{
+					Name:    "upstream1",
+					Service: "test-1",
+					Port:    80,
+					Weights: []v1alpha1.UpstreamServerWeight{
+						{Address: "10.0.0.1:80", Weight: 0},
+					},
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "zero weight",
+		},
+		{
+			upstreams: []v1alpha1.Upstream{
+				{
+					Name:    "upstream1",
+					Service: "test-1",
+					Port:    80,
+					Weights: []v1alpha1.UpstreamServerWeight{
+						{Address: "tcp-app", Weight: 2},
+					},
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "weight of an invalid address",
+		},
+		{
+			upstreams: []v1alpha1.Upstream{
+				{
+					Name:    "upstream1",
+					Service: "test-1",
+					Port:    80,
+					Weights: []v1alpha1.UpstreamServerWeight{
+						{Address: "10.0.0.1", Weight: 2},
+						{Address: "10.0.0.1", Weight: 3},
+					},
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "duplicated weight addresses",
+		},
 	}
 
 	for _, test := range tests {
@@ -400,6 +466,13 @@ func TestValidateUpstreamParameters(t *testing.T) {
 			parameters: &v1alpha1.UpstreamParameters{},
 			msg:        "Non-nil parameters",
 		},
+		{
+			parameters: &v1alpha1.UpstreamParameters{
+				ProxyTimeout:        "1h",
+				ProxyConnectTimeout: "30s",
+			},
+			msg: "proxy timeouts",
+		},
 	}
 
 	for _, test := range tests {
@@ -410,6 +483,33 @@ func TestValidateUpstreamParameters(t *testing.T) {
 	}
 }
 
+func TestValidateUpstreamParametersFails(t *testing.T) {
+	tests := []struct {
+		parameters *v1alpha1.UpstreamParameters
+		msg        string
+	}{
+		{
+			parameters: &v1alpha1.UpstreamParameters{
+				ProxyTimeout: "1x",
+			},
+			msg: "invalid proxyTimeout",
+		},
+		{
+			parameters: &v1alpha1.UpstreamParameters{
+				ProxyConnectTimeout: "-30s",
+			},
+			msg: "invalid proxyConnectTimeout",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateTransportServerUpstreamParameters(test.parameters, field.NewPath("upstreamParameters"), "TCP")
+		if len(allErrs) == 0 {
+			t.Errorf("validateTransportServerUpstreamParameters() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
 func TestValidateUDPUpstreamParameter(t *testing.T) {
 	validInput := []struct {
 		parameter *int
@@ -464,6 +564,64 @@ func TestValidateUDPUpstreamParameterFails(t *testing.T) {
 	}
 }
 
+func TestValidateProxyProtocol(t *testing.T) {
+	validInput := []struct {
+		proxyProtocol *v1alpha1.ProxyProtocol
+		protocol      string
+	}{
+		{
+			proxyProtocol: nil,
+			protocol:      "TCP",
+		},
+		{
+			proxyProtocol: &v1alpha1.ProxyProtocol{Enable: true},
+			protocol:      "TCP",
+		},
+		{
+			proxyProtocol: &v1alpha1.ProxyProtocol{Enable: true, Version: 1},
+			protocol:      "TLS_PASSTHROUGH",
+		},
+		{
+			proxyProtocol: &v1alpha1.ProxyProtocol{Enable: false},
+			protocol:      "UDP",
+		},
+	}
+
+	for _, input := range validInput {
+		allErrs := validateProxyProtocol(input.proxyProtocol, field.NewPath("proxyProtocol"), input.protocol)
+		if len(allErrs) > 0 {
+			t.Errorf("validateProxyProtocol(%+v, %q) returned errors %v for valid input", input.proxyProtocol, input.protocol, allErrs)
+		}
+	}
+}
+
+func TestValidateProxyProtocolFails(t *testing.T) {
+	invalidInput := []struct {
+		proxyProtocol *v1alpha1.ProxyProtocol
+		protocol      string
+	}{
+		{
+			proxyProtocol: &v1alpha1.ProxyProtocol{Enable: true},
+			protocol:      "UDP",
+		},
+		{
+			proxyProtocol: &v1alpha1.ProxyProtocol{Enable: true, Version: 2},
+			protocol:      "TCP",
+		},
+		{
+			proxyProtocol: &v1alpha1.ProxyProtocol{Enable: true, Version: -1},
+			protocol:      "TCP",
+		},
+	}
+
+	for _, input := range invalidInput {
+		allErrs := validateProxyProtocol(input.proxyProtocol, field.NewPath("proxyProtocol"), input.protocol)
+		if len(allErrs) == 0 {
+			t.Errorf("validateProxyProtocol(%+v, %q) returned no errors for invalid input", input.proxyProtocol, input.protocol)
+		}
+	}
+}
+
 func TestValidateTransportServerAction(t *testing.T) {
 	upstreamNames := map[string]sets.Empty{
 		"test": {},
@@ -2,7 +2,12 @@ package validation
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -20,10 +25,9 @@ const (
 
 var escapedStringsFmtRegexp = regexp.MustCompile("^" + escapedStringsFmt + "$")
 
-// ValidateVirtualServer validates a VirtualServer.
-func ValidateVirtualServer(virtualServer *v1.VirtualServer, isPlus bool) error {
-	allErrs := validateVirtualServerSpec(&virtualServer.Spec, field.NewPath("spec"), isPlus)
-	return allErrs.ToAggregate()
+// ValidateVirtualServer validates a VirtualServer and returns every problem found, each scoped to its field path.
+func ValidateVirtualServer(virtualServer *v1.VirtualServer, isPlus bool) field.ErrorList {
+	return validateVirtualServerSpec(&virtualServer.Spec, field.NewPath("spec"), isPlus)
 }
 
 // validateVirtualServerSpec validates a VirtualServerSpec.
@@ -32,11 +36,27 @@ func validateVirtualServerSpec(spec *v1.VirtualServerSpec, fieldPath *field.Path
 
 	allErrs = append(allErrs, validateHost(spec.Host, fieldPath.Child("host"))...)
 	allErrs = append(allErrs, validateTLS(spec.TLS, fieldPath.Child("tls"))...)
+	allErrs = append(allErrs, validateOIDC(spec.OIDC, fieldPath.Child("oidc"), isPlus)...)
+	allErrs = append(allErrs, validateRequestID(spec.RequestID, fieldPath.Child("requestID"))...)
+	allErrs = append(allErrs, validateAccessLog(spec.AccessLog, fieldPath.Child("accessLog"), isPlus)...)
+
+	allErrs = append(allErrs, validateClientBody(spec.ClientBody, fieldPath.Child("clientBody"))...)
+	allErrs = append(allErrs, validateClientHeaders(spec.ClientHeaders, fieldPath.Child("clientHeaders"))...)
+	allErrs = append(allErrs, validateCompression(spec.Compression, fieldPath.Child("compression"))...)
+	allErrs = append(allErrs, validateServerTokens(spec.ServerTokens, fieldPath.Child("serverTokens"), isPlus)...)
+
+	mapErrs, mapNames := validateMaps(spec.Maps, fieldPath.Child("maps"))
+	allErrs = append(allErrs, mapErrs...)
 
 	upstreamErrs, upstreamNames := validateUpstreams(spec.Upstreams, fieldPath.Child("upstreams"), isPlus)
 	allErrs = append(allErrs, upstreamErrs...)
+	allErrs = append(allErrs, validateConnectionLimit(spec.ConnectionLimit, fieldPath.Child("connectionLimit"), upstreamNames)...)
+	allErrs = append(allErrs, validateErrorBackend(spec.ErrorBackend, fieldPath.Child("errorBackend"), upstreamNames)...)
 
-	allErrs = append(allErrs, validateVirtualServerRoutes(spec.Routes, fieldPath.Child("routes"), upstreamNames)...)
+	upstreamMapErrs, upstreamVariables := validateUpstreamMaps(spec, fieldPath.Child("maps"), upstreamNames)
+	allErrs = append(allErrs, upstreamMapErrs...)
+
+	allErrs = append(allErrs, validateVirtualServerRoutes(spec.Routes, fieldPath.Child("routes"), upstreamNames.Union(upstreamVariables), mapNames)...)
 
 	return allErrs
 }
@@ -63,10 +83,657 @@ func validateTLS(tls *v1.TLS, fieldPath *field.Path) field.ErrorList {
 		return allErrs
 	}
 
-	allErrs = append(allErrs, validateSecretName(tls.Secret, fieldPath.Child("secret"))...)
+	allErrs = append(allErrs, validateSecretReference(tls.Secret, fieldPath.Child("secret"))...)
+
+	if tls.Certificate != "" {
+		if tls.Secret != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("certificate"), "must not be set together with secret"))
+		} else {
+			for _, msg := range validation.IsDNS1123Subdomain(tls.Certificate) {
+				allErrs = append(allErrs, field.Invalid(fieldPath.Child("certificate"), tls.Certificate, msg))
+			}
+		}
+	}
+
+	if tls.DynamicCertificates && (tls.Secret != "" || tls.Certificate != "") {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("dynamicCertificates"), "must not be set together with secret or certificate"))
+	}
 
 	allErrs = append(allErrs, validateTLSRedirect(tls.Redirect, fieldPath.Child("redirect"))...)
 
+	allErrs = append(allErrs, validateTLSSessionCache(tls.SessionCache, fieldPath.Child("sessionCache"))...)
+	allErrs = append(allErrs, validateTime(tls.SessionTimeout, fieldPath.Child("sessionTimeout"))...)
+
+	if tls.SessionTicketKey != "" {
+		if tls.SessionTickets != nil && !*tls.SessionTickets {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("sessionTicketKey"), "must not be set when sessionTickets is false"))
+		} else {
+			allErrs = append(allErrs, validateSecretReference(tls.SessionTicketKey, fieldPath.Child("sessionTicketKey"))...)
+		}
+	}
+
+	return allErrs
+}
+
+func validateTLSSessionCache(cache string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if cache == "" || cache == "off" || cache == "none" {
+		return allErrs
+	}
+
+	if !sizeRegexp.MatchString(cache) {
+		msg := validation.RegexError("must be 'off', 'none' or a size: "+sizeErrMsg, sizeFmt, "off", "none", "10m")
+		return append(allErrs, field.Invalid(fieldPath, cache, msg))
+	}
+
+	return allErrs
+}
+
+const oidcClientIDFmt = `[^\s"'\\$;{}]+`
+const oidcClientIDErrMsg = "must not include any whitespace character, `\"`, `'`, `\\`, `$`, `;`, `{` or `}`"
+
+var oidcClientIDRegexp = regexp.MustCompile("^" + oidcClientIDFmt + "$")
+
+const oidcScopeFmt = `[A-Za-z0-9_.:/-]+`
+const oidcScopeErrMsg = "a valid scope must consist of alphanumeric characters, '_', '.', ':', '/' or '-'"
+
+var oidcScopeRegexp = regexp.MustCompile("^" + oidcScopeFmt + "$")
+
+// oidcLocations includes the locations of the OIDC configuration that the redirect URI must not conflict with.
+var oidcLocations = map[string]bool{
+	"/_jwks_uri":            true,
+	"/_token":               true,
+	"/_refresh":             true,
+	"/_id_token_validation": true,
+	"/logout":               true,
+	"/_logout":              true,
+}
+
+func validateOIDC(oidc *v1.OIDC, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if oidc == nil {
+		return allErrs
+	}
+
+	if !isPlus {
+		return append(allErrs, field.Forbidden(fieldPath, "oidc is only supported in NGINX Plus"))
+	}
+
+	if oidc.ClientID == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("clientID"), ""))
+	} else if !oidcClientIDRegexp.MatchString(oidc.ClientID) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("clientID"), oidc.ClientID, oidcClientIDErrMsg))
+	}
+
+	if oidc.ClientSecret == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("clientSecret"), ""))
+	} else {
+		allErrs = append(allErrs, validateSecretReference(oidc.ClientSecret, fieldPath.Child("clientSecret"))...)
+	}
+
+	allErrs = append(allErrs, validateOIDCEndpoint(oidc.AuthEndpoint, fieldPath.Child("authEndpoint"))...)
+	allErrs = append(allErrs, validateOIDCEndpoint(oidc.TokenEndpoint, fieldPath.Child("tokenEndpoint"))...)
+	allErrs = append(allErrs, validateOIDCEndpoint(oidc.JWKSURI, fieldPath.Child("jwksURI"))...)
+	allErrs = append(allErrs, validateOIDCScope(oidc.Scope, fieldPath.Child("scope"))...)
+	allErrs = append(allErrs, validateOIDCFailureMode(oidc.FailureMode, fieldPath.Child("failureMode"))...)
+
+	if oidc.RedirectURI != "" {
+		if !pathRegexp.MatchString(oidc.RedirectURI) || strings.ContainsAny(oidc.RedirectURI, `"'$\`) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("redirectURI"), oidc.RedirectURI, "must start with / and must not include any whitespace character, `{`, `}`, `;`, `\"`, `'`, `$` or `\\`"))
+		} else if oidcLocations[oidc.RedirectURI] {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("redirectURI"), oidc.RedirectURI, "must not be a location of the OIDC configuration"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateOIDCEndpoint(endpoint string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if endpoint == "" {
+		return append(allErrs, field.Required(fieldPath, ""))
+	}
+
+	if strings.ContainsAny(endpoint, " \t\n\"'$;{}\\") {
+		return append(allErrs, field.Invalid(fieldPath, endpoint, "must not include any whitespace character, `\"`, `'`, `$`, `;`, `{`, `}` or `\\`"))
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return append(allErrs, field.Invalid(fieldPath, endpoint, fmt.Sprintf("must be a valid URL: %v", err)))
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return append(allErrs, field.Invalid(fieldPath, endpoint, "must be a URL with the http or https scheme"))
+	}
+
+	if u.Host == "" {
+		return append(allErrs, field.Invalid(fieldPath, endpoint, "must be a URL with a host"))
+	}
+
+	return allErrs
+}
+
+// validateOIDCScope validates the scopes of the OIDC authentication, separated with `+`. The scopes must include openid.
+func validateOIDCScope(scope string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if scope == "" {
+		return allErrs
+	}
+
+	hasOpenID := false
+	for _, s := range strings.Split(scope, "+") {
+		if !oidcScopeRegexp.MatchString(s) {
+			msg := validation.RegexError(oidcScopeErrMsg, oidcScopeFmt, "openid", "profile")
+			return append(allErrs, field.Invalid(fieldPath, scope, msg))
+		}
+		if s == "openid" {
+			hasOpenID = true
+		}
+	}
+
+	if !hasOpenID {
+		allErrs = append(allErrs, field.Invalid(fieldPath, scope, "must include the openid scope"))
+	}
+
+	return allErrs
+}
+
+// oidcFailureModes includes the behaviors of the OIDC authentication when the identity provider is unavailable.
+var oidcFailureModes = map[string]bool{
+	"open":   true,
+	"closed": true,
+}
+
+func validateOIDCFailureMode(mode string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if mode == "" {
+		return allErrs
+	}
+
+	if !oidcFailureModes[mode] {
+		return append(allErrs, field.NotSupported(fieldPath, mode, []string{"open", "closed"}))
+	}
+
+	return allErrs
+}
+
+func validateRequestID(requestID *v1.RequestID, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if requestID == nil || requestID.Header == "" {
+		return allErrs
+	}
+
+	for _, msg := range validation.IsHTTPHeaderName(requestID.Header) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("header"), requestID.Header, msg))
+	}
+
+	return allErrs
+}
+
+const accessLogFieldNameFmt = `[A-Za-z_][A-Za-z0-9_]*`
+const accessLogFieldNameErrMsg = "a valid access log field name must start with a letter or '_' and consist of alphanumeric characters or '_'"
+
+var accessLogFieldNameRegexp = regexp.MustCompile("^" + accessLogFieldNameFmt + "$")
+
+// accessLogVariables includes NGINX variables allowed to be used in the custom fields of the access log.
+var accessLogVariables = map[string]bool{
+	"request_id":             true,
+	"request_time":           true,
+	"request_length":         true,
+	"request_method":         true,
+	"request_uri":            true,
+	"uri":                    true,
+	"args":                   true,
+	"host":                   true,
+	"scheme":                 true,
+	"server_name":            true,
+	"server_protocol":        true,
+	"remote_addr":            true,
+	"remote_port":            true,
+	"bytes_sent":             true,
+	"connection":             true,
+	"ssl_protocol":           true,
+	"ssl_cipher":             true,
+	"upstream_addr":          true,
+	"upstream_status":        true,
+	"upstream_connect_time":  true,
+	"upstream_header_time":   true,
+	"upstream_response_time": true,
+}
+
+// accessLogSpecialVariables includes the prefixes of NGINX variables allowed to be used in the custom fields of
+// the access log.
+var accessLogSpecialVariables = []string{"arg_", "http_", "cookie_"}
+
+// accessLogResponseHeaderVariables includes the prefixes of the NGINX variables of the response headers allowed
+// to be used in the custom fields of the access log.
+var accessLogResponseHeaderVariables = []string{"sent_http_", "upstream_http_"}
+
+// jwtClaimVariablePrefix is the prefix of the variables of the claims of a JWT, which are supported in NGINX Plus only.
+const jwtClaimVariablePrefix = "jwt_claim_"
+
+func validateAccessLog(accessLog *v1.AccessLog, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if accessLog == nil {
+		return allErrs
+	}
+
+	names := sets.String{}
+	for i, f := range accessLog.Fields {
+		idxPath := fieldPath.Child("fields").Index(i)
+
+		if f.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), ""))
+		} else if !accessLogFieldNameRegexp.MatchString(f.Name) {
+			msg := validation.RegexError(accessLogFieldNameErrMsg, accessLogFieldNameFmt, "user", "jwt_sub")
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), f.Name, msg))
+		} else if names.Has(f.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), f.Name))
+		} else {
+			names.Insert(f.Name)
+		}
+
+		allErrs = append(allErrs, validateAccessLogVariable(f.Variable, idxPath.Child("variable"), isPlus)...)
+	}
+
+	return allErrs
+}
+
+func validateAccessLogVariable(variable string, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if variable == "" {
+		return append(allErrs, field.Required(fieldPath, ""))
+	}
+
+	if !strings.HasPrefix(variable, "$") {
+		return append(allErrs, field.Invalid(fieldPath, variable, "must start with `$`"))
+	}
+
+	name := variable[1:]
+
+	if strings.HasPrefix(name, jwtClaimVariablePrefix) {
+		if !isPlus {
+			return append(allErrs, field.Forbidden(fieldPath, "jwt claims are only supported in NGINX Plus"))
+		}
+		if !mapNameRegexp.MatchString(strings.TrimPrefix(name, jwtClaimVariablePrefix)) {
+			return append(allErrs, field.Invalid(fieldPath, variable, "a valid jwt claim must consist of alphanumeric characters or '_'"))
+		}
+		return allErrs
+	}
+
+	for _, prefix := range accessLogResponseHeaderVariables {
+		if strings.HasPrefix(name, prefix) {
+			for _, msg := range isValidSpecialVariableHeader(strings.TrimPrefix(name, prefix)) {
+				allErrs = append(allErrs, field.Invalid(fieldPath, variable, msg))
+			}
+			return allErrs
+		}
+	}
+
+	for _, prefix := range accessLogSpecialVariables {
+		if strings.HasPrefix(name, prefix) {
+			return append(allErrs, validateSpecialVariable(name, fieldPath)...)
+		}
+	}
+
+	return append(allErrs, validateVariable(name, accessLogVariables, fieldPath)...)
+}
+
+// serverTokensValues includes the values of the server_tokens directive that are supported by both NGINX and NGINX Plus.
+var serverTokensValues = map[string]bool{
+	"on":    true,
+	"off":   true,
+	"build": true,
+}
+
+func validateServerTokens(serverTokens string, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if serverTokens == "" || serverTokensValues[serverTokens] {
+		return allErrs
+	}
+
+	if !isPlus {
+		return append(allErrs, field.NotSupported(fieldPath, serverTokens, []string{"on", "off", "build"}))
+	}
+
+	if !escapedStringsFmtRegexp.MatchString(serverTokens) {
+		msg := validation.RegexError(escapedStringsErrMsg, escapedStringsFmt, "on", `My \"Server\"`)
+		allErrs = append(allErrs, field.Invalid(fieldPath, serverTokens, msg))
+	}
+
+	return allErrs
+}
+
+func validateClientBody(clientBody *v1.ClientBody, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if clientBody == nil {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateSize(clientBody.BufferSize, fieldPath.Child("bufferSize"))...)
+	allErrs = append(allErrs, validateClientBodyTempPath(clientBody.TempPath, fieldPath.Child("tempPath"))...)
+	allErrs = append(allErrs, validateTime(clientBody.Timeout, fieldPath.Child("timeout"))...)
+
+	return allErrs
+}
+
+// compressionLevels maps the compression types to their ranges of compression levels.
+var compressionLevels = map[string][2]int{
+	"gzip":   {1, 9},
+	"brotli": {0, 11},
+}
+
+const mimeTypeFmt = `[a-zA-Z0-9][a-zA-Z0-9!#$&^_.+-]*/([a-zA-Z0-9][a-zA-Z0-9!#$&^_.+-]*|\*)`
+const mimeTypeErrMsg = "must be a MIME type in the format 'type/subtype' or '*'"
+
+var mimeTypeRegexp = regexp.MustCompile("^" + mimeTypeFmt + "$")
+
+func validateConnectionLimit(connectionLimit *v1.ConnectionLimit, fieldPath *field.Path, upstreamNames sets.String) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if connectionLimit == nil {
+		return allErrs
+	}
+
+	// the key is a parameter of the limit_conn_zone directive, so it must not break the directive.
+	if strings.ContainsAny(connectionLimit.Key, " \t\r\n;\\") {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("key"), connectionLimit.Key, "must not include any whitespace character, `;` or `\\`"))
+	} else {
+		allErrs = append(allErrs, validateRateLimitKey(connectionLimit.Key, fieldPath.Child("key"))...)
+	}
+	allErrs = append(allErrs, validateSize(connectionLimit.ZoneSize, fieldPath.Child("zoneSize"))...)
+
+	// the limit is either fixed or derived from the number of the endpoints of an upstream
+	if connectionLimit.ConnectionsPerEndpoint != 0 || connectionLimit.Upstream != "" {
+		if connectionLimit.Connections != 0 {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("connections"), "must not be specified with connectionsPerEndpoint"))
+		}
+		if connectionLimit.ConnectionsPerEndpoint <= 0 {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("connectionsPerEndpoint"), "must be positive"))
+		}
+		if connectionLimit.Upstream == "" {
+			allErrs = append(allErrs, field.Required(fieldPath.Child("upstream"), "must specify the upstream for connectionsPerEndpoint"))
+		} else if !upstreamNames.Has(connectionLimit.Upstream) {
+			allErrs = append(allErrs, field.NotFound(fieldPath.Child("upstream"), connectionLimit.Upstream))
+		}
+	} else if connectionLimit.Connections <= 0 {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("connections"), "must be positive"))
+	}
+
+	if connectionLimit.RejectCode != nil {
+		if *connectionLimit.RejectCode < 400 || *connectionLimit.RejectCode > 599 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("rejectCode"), *connectionLimit.RejectCode, "must be within the range [400-599]"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateCompression(compression *v1.Compression, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if compression == nil {
+		return allErrs
+	}
+
+	if compression.Type == "" {
+		return append(allErrs, field.Required(fieldPath.Child("type"), ""))
+	}
+
+	levels, ok := compressionLevels[compression.Type]
+	if !ok {
+		return append(allErrs, field.NotSupported(fieldPath.Child("type"), compression.Type, []string{"gzip", "brotli"}))
+	}
+
+	allTypes := sets.String{}
+	for i, t := range compression.Types {
+		idxPath := fieldPath.Child("types").Index(i)
+
+		if t != "*" && !mimeTypeRegexp.MatchString(t) {
+			msg := validation.RegexError(mimeTypeErrMsg, mimeTypeFmt, "application/json", "image/svg+xml")
+			allErrs = append(allErrs, field.Invalid(idxPath, t, msg))
+		} else if allTypes.Has(t) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, t))
+		} else {
+			allTypes.Insert(t)
+		}
+	}
+
+	allErrs = append(allErrs, validatePositiveIntOrZero(compression.MinLength, fieldPath.Child("minLength"))...)
+
+	if compression.Level != nil {
+		for _, msg := range validation.IsInRange(*compression.Level, levels[0], levels[1]) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("level"), *compression.Level, msg))
+		}
+	}
+
+	return allErrs
+}
+
+func validateClientHeaders(clientHeaders *v1.ClientHeaders, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if clientHeaders == nil {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateSize(clientHeaders.BufferSize, fieldPath.Child("bufferSize"))...)
+	allErrs = append(allErrs, validateBuffer(clientHeaders.LargeBuffers, fieldPath.Child("largeBuffers"))...)
+	allErrs = append(allErrs, validateTime(clientHeaders.Timeout, fieldPath.Child("timeout"))...)
+
+	return allErrs
+}
+
+// clientBodyTempPathParentDirs includes the directories writable by the NGINX user in the Ingress Controller images.
+var clientBodyTempPathParentDirs = []string{"/var/cache/nginx/", "/var/lib/nginx/"}
+
+const clientBodyTempPathFmt = `/[a-zA-Z0-9_./-]+`
+const clientBodyTempPathErrMsg = "must be an absolute path that consists of alphanumeric characters, '_', '.', '/' or '-'"
+
+var clientBodyTempPathRegexp = regexp.MustCompile("^" + clientBodyTempPathFmt + "$")
+
+func validateClientBodyTempPath(tempPath string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if tempPath == "" {
+		return allErrs
+	}
+
+	if !clientBodyTempPathRegexp.MatchString(tempPath) {
+		msg := validation.RegexError(clientBodyTempPathErrMsg, clientBodyTempPathFmt, "/var/cache/nginx/client_temp_uploads")
+		return append(allErrs, field.Invalid(fieldPath, tempPath, msg))
+	}
+
+	if path.Clean(tempPath) != tempPath {
+		return append(allErrs, field.Invalid(fieldPath, tempPath, "must be a clean path without '..', '.' or repeated and trailing '/' elements"))
+	}
+
+	for _, dir := range clientBodyTempPathParentDirs {
+		if strings.HasPrefix(tempPath, dir) {
+			return allErrs
+		}
+	}
+
+	msg := fmt.Sprintf("must be a subdirectory of a directory writable by the NGINX user: %s", strings.Join(clientBodyTempPathParentDirs, ", "))
+	return append(allErrs, field.Invalid(fieldPath, tempPath, msg))
+}
+
+const mapNameFmt string = "[_A-Za-z0-9]+"
+const mapNameErrMsg string = "a valid map name must consist of alphanumeric characters or '_'"
+
+var mapNameRegexp = regexp.MustCompile("^" + mapNameFmt + "$")
+
+func validateMaps(maps []v1.Map, fieldPath *field.Path) (allErrs field.ErrorList, mapNames sets.String) {
+	allErrs = field.ErrorList{}
+	mapNames = sets.String{}
+
+	for i, m := range maps {
+		idxPath := fieldPath.Index(i)
+
+		nameErrs := validateMapName(m.Name, idxPath.Child("name"))
+		if len(nameErrs) > 0 {
+			allErrs = append(allErrs, nameErrs...)
+		} else if mapNames.Has(m.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), m.Name))
+		} else {
+			mapNames.Insert(m.Name)
+		}
+
+		allErrs = append(allErrs, validateMapSource(m.Source, idxPath.Child("source"))...)
+		allErrs = append(allErrs, validateMapMappings(m.Mappings, idxPath.Child("mappings"))...)
+		allErrs = append(allErrs, validateMapResult(m.Default, idxPath.Child("default"))...)
+	}
+
+	return allErrs, mapNames
+}
+
+// validateUpstreamMaps validates the maps referenced as $<map name> instead of an upstream in the actions of the routes.
+// The results of such maps must be the names of upstreams with the same TLS configuration.
+// It returns the variables of the referenced maps.
+func validateUpstreamMaps(spec *v1.VirtualServerSpec, fieldPath *field.Path, upstreamNames sets.String) (allErrs field.ErrorList, upstreamVariables sets.String) {
+	allErrs = field.ErrorList{}
+	upstreamVariables = sets.String{}
+
+	upstreamMapNames := configs.GetUpstreamMapNames(spec.Routes)
+	if len(upstreamMapNames) == 0 {
+		return allErrs, upstreamVariables
+	}
+
+	tlsConfigs := make(map[string]v1.UpstreamTLS)
+	for _, u := range spec.Upstreams {
+		tlsConfigs[u.Name] = u.TLS
+	}
+
+	for i, m := range spec.Maps {
+		if !upstreamMapNames[m.Name] {
+			continue
+		}
+
+		upstreamVariables.Insert("$" + m.Name)
+		idxPath := fieldPath.Index(i)
+
+		validateResult := func(result string, resultPath *field.Path) {
+			if !upstreamNames.Has(result) {
+				allErrs = append(allErrs, field.Invalid(resultPath, result, "must be the name of an upstream because the map is referenced as an upstream in an action"))
+			} else if upstreamNames.Has(m.Default) && !reflect.DeepEqual(tlsConfigs[result], tlsConfigs[m.Default]) {
+				allErrs = append(allErrs, field.Invalid(resultPath, result, "must be the name of an upstream with the same TLS configuration as the upstream of the default result"))
+			}
+		}
+
+		for j, mapping := range m.Mappings {
+			validateResult(mapping.Result, idxPath.Child("mappings").Index(j).Child("result"))
+		}
+		validateResult(m.Default, idxPath.Child("default"))
+	}
+
+	return allErrs, upstreamVariables
+}
+
+func validateMapName(name string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if name == "" {
+		return append(allErrs, field.Required(fieldPath, ""))
+	}
+
+	if !mapNameRegexp.MatchString(name) {
+		return append(allErrs, field.Invalid(fieldPath, name, validation.RegexError(mapNameErrMsg, mapNameFmt, "my_map_123")))
+	}
+
+	// the variable of a map can be used in conditions, so it must not shadow the variables allowed there
+	if _, exists := validVariableNames["$"+name]; exists {
+		allErrs = append(allErrs, field.Invalid(fieldPath, name, "must not be the name of an NGINX variable"))
+	}
+
+	return allErrs
+}
+
+// validMapSourceVariableNames includes NGINX variables allowed to be used as a source of a map
+// in addition to the variables allowed in conditions.
+var validMapSourceVariableNames = map[string]bool{
+	"$host": true,
+	"$uri":  true,
+}
+
+// mapSourceSpecialVariables includes the prefixes of NGINX variables allowed to be used as a source of a map.
+var mapSourceSpecialVariables = []string{"arg_", "http_", "cookie_"}
+
+func validateMapSource(source string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if source == "" {
+		return append(allErrs, field.Required(fieldPath, ""))
+	}
+
+	if !strings.HasPrefix(source, "$") {
+		return append(allErrs, field.Invalid(fieldPath, source, "must start with `$`"))
+	}
+
+	if validVariableNames[source] || validMapSourceVariableNames[source] {
+		return allErrs
+	}
+
+	name := source[1:]
+	for _, prefix := range mapSourceSpecialVariables {
+		if strings.HasPrefix(name, prefix) {
+			return append(allErrs, validateSpecialVariable(name, fieldPath)...)
+		}
+	}
+
+	return append(allErrs, field.Invalid(fieldPath, source, "is not allowed or is not an NGINX variable"))
+}
+
+func validateMapMappings(mappings []v1.MapMapping, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(mappings) == 0 {
+		return append(allErrs, field.Required(fieldPath, "must specify at least one mapping"))
+	}
+
+	values := sets.String{}
+
+	for i, m := range mappings {
+		idxPath := fieldPath.Index(i)
+
+		valueErrs := field.ErrorList{}
+		for _, msg := range isValidMatchValue(m.Value) {
+			valueErrs = append(valueErrs, field.Invalid(idxPath.Child("value"), m.Value, msg))
+		}
+
+		if len(valueErrs) > 0 {
+			allErrs = append(allErrs, valueErrs...)
+		} else if values.Has(m.Value) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("value"), m.Value))
+		} else {
+			values.Insert(m.Value)
+		}
+
+		allErrs = append(allErrs, validateMapResult(m.Result, idxPath.Child("result"))...)
+	}
+
+	return allErrs
+}
+
+func validateMapResult(result string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, msg := range isValidMatchValue(result) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, result, msg))
+	}
+
+	allErrs = append(allErrs, validateStringNoVariables(result, fieldPath)...)
+
 	return allErrs
 }
 
@@ -243,17 +910,91 @@ func validateUpstreamHealthCheck(hc *v1.HealthCheck, fieldPath *field.Path) fiel
 	allErrs = append(allErrs, validateTime(hc.SendTimeout, fieldPath.Child("send-timeout"))...)
 	allErrs = append(allErrs, validateStatusMatch(hc.StatusMatch, fieldPath.Child("statusMatch"))...)
 
-	for i, header := range hc.Headers {
-		idxPath := fieldPath.Child("headers").Index(i)
-		allErrs = append(allErrs, validateHeader(header, idxPath)...)
+	for i, header := range hc.Headers {
+		idxPath := fieldPath.Child("headers").Index(i)
+		allErrs = append(allErrs, validateHeader(header, idxPath)...)
+	}
+
+	if hc.TLS != nil && (hc.TLS.SessionReuse != nil || len(hc.TLS.Protocols) > 0 || hc.TLS.Ciphers != "") {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("tls"), "only enable is supported for the TLS of health checks"))
+	}
+
+	if hc.Port != 0 {
+		for _, msg := range validation.IsValidPortNum(hc.Port) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("port"), hc.Port, msg))
+		}
+	}
+
+	return allErrs
+}
+
+// validUpstreamTLSProtocols includes the protocols supported by the proxy_ssl_protocols directive.
+var validUpstreamTLSProtocols = map[string]bool{
+	"SSLv2":   true,
+	"SSLv3":   true,
+	"TLSv1":   true,
+	"TLSv1.1": true,
+	"TLSv1.2": true,
+	"TLSv1.3": true,
+}
+
+const upstreamTLSCiphersFmt = `[A-Za-z0-9_!+@=.:-]+`
+const upstreamTLSCiphersErrMsg = "must be a valid OpenSSL cipher list"
+
+var upstreamTLSCiphersRegexp = regexp.MustCompile("^" + upstreamTLSCiphersFmt + "$")
+
+func validateUpstreamTLS(tls v1.UpstreamTLS, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	protocols := sets.NewString()
+	for i, p := range tls.Protocols {
+		idxPath := fieldPath.Child("protocols").Index(i)
+
+		if !validUpstreamTLSProtocols[p] {
+			allErrs = append(allErrs, field.NotSupported(idxPath, p, sets.StringKeySet(validUpstreamTLSProtocols).List()))
+		} else if protocols.Has(p) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, p))
+		}
+
+		protocols.Insert(p)
+	}
+
+	if tls.Ciphers != "" && !upstreamTLSCiphersRegexp.MatchString(tls.Ciphers) {
+		msg := validation.RegexError(upstreamTLSCiphersErrMsg, upstreamTLSCiphersFmt, "HIGH:!aNULL:!MD5", "ECDHE-RSA-AES256-GCM-SHA384")
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("ciphers"), tls.Ciphers, msg))
+	}
+
+	return allErrs
+}
+
+// srvServiceFmt matches a service name like 'http' or a prefix of the SRV records like '_http._tcp'.
+const srvServiceFmt = `_?[a-z0-9]([-a-z0-9]*[a-z0-9])?(\._?[a-z0-9]([-a-z0-9]*[a-z0-9])?)*`
+const srvServiceErrMsg = "must be a valid DNS name, where the labels can start with '_'"
+
+var srvServiceRegexp = regexp.MustCompile("^" + srvServiceFmt + "$")
+
+func validateUpstreamSRV(srv *v1.UpstreamSRV, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if srv == nil {
+		return allErrs
 	}
 
-	if hc.Port > 0 {
-		for _, msg := range validation.IsValidPortNum(hc.Port) {
-			allErrs = append(allErrs, field.Invalid(fieldPath.Child("port"), hc.Port, msg))
+	if srv.Host == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("host"), ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(srv.Host) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("host"), srv.Host, msg))
 		}
 	}
 
+	if srv.Service == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("service"), ""))
+	} else if len(srv.Service) > validation.DNS1123SubdomainMaxLength || !srvServiceRegexp.MatchString(srv.Service) {
+		msg := validation.RegexError(srvServiceErrMsg, srvServiceFmt, "http", "_http._tcp")
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("service"), srv.Service, msg))
+	}
+
 	return allErrs
 }
 
@@ -420,6 +1161,31 @@ func validateSecretName(name string, fieldPath *field.Path) field.ErrorList {
 	return allErrs
 }
 
+// validateSecretReference validates a reference to a secret: either the name of a secret in the namespace of the resource,
+// or a secret in another namespace in the <namespace>/<name> format.
+func validateSecretReference(ref string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !strings.Contains(ref, "/") {
+		return validateSecretName(ref, fieldPath)
+	}
+
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 {
+		return append(allErrs, field.Invalid(fieldPath, ref, "must be the name of a secret or follow the format <namespace>/<name>"))
+	}
+
+	for _, msg := range validation.IsDNS1123Label(parts[0]) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, ref, fmt.Sprintf("invalid namespace: %v", msg)))
+	}
+
+	if parts[1] == "" {
+		return append(allErrs, field.Invalid(fieldPath, ref, "the name of the secret must not be empty"))
+	}
+
+	return append(allErrs, validateSecretName(parts[1], fieldPath)...)
+}
+
 func validateUpstreams(upstreams []v1.Upstream, fieldPath *field.Path, isPlus bool) (allErrs field.ErrorList, upstreamNames sets.String) {
 	allErrs = field.ErrorList{}
 	upstreamNames = sets.String{}
@@ -454,8 +1220,16 @@ func validateUpstreams(upstreams []v1.Upstream, fieldPath *field.Path, isPlus bo
 		allErrs = append(allErrs, validateTime(u.SlowStart, idxPath.Child("slow-start"))...)
 		allErrs = append(allErrs, validateBuffer(u.ProxyBuffers, idxPath.Child("buffers"))...)
 		allErrs = append(allErrs, validateSize(u.ProxyBufferSize, idxPath.Child("buffer-size"))...)
+		allErrs = append(allErrs, validateUpstreamTLS(u.TLS, idxPath.Child("tls"))...)
 		allErrs = append(allErrs, validateQueue(u.Queue, idxPath.Child("queue"))...)
 		allErrs = append(allErrs, validateSessionCookie(u.SessionCookie, idxPath.Child("sessionCookie"))...)
+		allErrs = append(allErrs, validateUpstreamSRV(u.SRV, idxPath.Child("srv"))...)
+		allErrs = append(allErrs, validateConnectionLimitPolicy(u.ConnectionLimitPolicy, idxPath.Child("connection-limit-policy"))...)
+		allErrs = append(allErrs, validateUpstreamCache(u.Cache, idxPath.Child("cache"))...)
+		allErrs = append(allErrs, validateTime(u.ResolverValid, idxPath.Child("resolver-valid"))...)
+		allErrs = append(allErrs, validateUpstreamServices(u, idxPath.Child("services"))...)
+		allErrs = append(allErrs, validateUpstreamBackup(u, idxPath)...)
+		allErrs = append(allErrs, validateUpstreamDown(u, idxPath.Child("down"))...)
 
 		for _, msg := range validation.IsValidPortNum(int(u.Port)) {
 			allErrs = append(allErrs, field.Invalid(idxPath.Child("port"), u.Port, msg))
@@ -512,6 +1286,55 @@ func validateUpstreamName(name string, fieldPath *field.Path) field.ErrorList {
 	return validateDNS1035Label(name, fieldPath)
 }
 
+// validateErrorBackend validates the error backend of a VirtualServer: the upstream must be defined in the VirtualServer
+// and the codes must be unique error status codes.
+func validateErrorBackend(errorBackend *v1.ErrorBackend, fieldPath *field.Path, upstreamNames sets.String) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if errorBackend == nil {
+		return allErrs
+	}
+
+	if errorBackend.Upstream == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("upstream"), ""))
+	} else if !upstreamNames.Has(errorBackend.Upstream) {
+		allErrs = append(allErrs, field.NotFound(fieldPath.Child("upstream"), errorBackend.Upstream))
+	}
+
+	if len(errorBackend.Codes) == 0 {
+		return append(allErrs, field.Required(fieldPath.Child("codes"), "must include at least 1 status code in `codes`"))
+	}
+
+	codes := make(map[int]bool)
+	for i, c := range errorBackend.Codes {
+		idxPath := fieldPath.Child("codes").Index(i)
+		for _, msg := range validation.IsInRange(c, 400, 599) {
+			allErrs = append(allErrs, field.Invalid(idxPath, c, msg))
+		}
+		if codes[c] {
+			allErrs = append(allErrs, field.Duplicate(idxPath, c))
+		}
+		codes[c] = true
+	}
+
+	return allErrs
+}
+
+// validateConnectionLimitPolicy validates the name of a connection limit policy defined in the ConfigMap.
+func validateConnectionLimitPolicy(name string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if name == "" {
+		return allErrs
+	}
+
+	for _, msg := range validation.IsDNS1123Label(name) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, name, msg))
+	}
+
+	return allErrs
+}
+
 // validateServiceName checks if a service name is valid.
 // It performs the same validation as ValidateServiceName from k8s.io/kubernetes/pkg/apis/core/validation/validation.go.
 func validateServiceName(name string, fieldPath *field.Path) field.ErrorList {
@@ -532,7 +1355,7 @@ func validateDNS1035Label(name string, fieldPath *field.Path) field.ErrorList {
 	return allErrs
 }
 
-func validateVirtualServerRoutes(routes []v1.Route, fieldPath *field.Path, upstreamNames sets.String) field.ErrorList {
+func validateVirtualServerRoutes(routes []v1.Route, fieldPath *field.Path, upstreamNames sets.String, mapNames sets.String) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	allPaths := sets.String{}
@@ -541,7 +1364,7 @@ func validateVirtualServerRoutes(routes []v1.Route, fieldPath *field.Path, upstr
 		idxPath := fieldPath.Index(i)
 
 		isRouteFieldForbidden := false
-		routeErrs := validateRoute(r, idxPath, upstreamNames, isRouteFieldForbidden)
+		routeErrs := validateRoute(r, idxPath, upstreamNames, mapNames, isRouteFieldForbidden)
 		if len(routeErrs) > 0 {
 			allErrs = append(allErrs, routeErrs...)
 		} else if allPaths.Has(r.Path) {
@@ -551,10 +1374,46 @@ func validateVirtualServerRoutes(routes []v1.Route, fieldPath *field.Path, upstr
 		}
 	}
 
+	allErrs = append(allErrs, validateRouteSplitsReferences(routes, fieldPath)...)
+
+	return allErrs
+}
+
+// validateRouteSplitsReferences checks that a VirtualServerRoute referenced in the splits of a route
+// is not referenced by any other route or split, because its subroutes can only be configured once.
+func validateRouteSplitsReferences(routes []v1.Route, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	references := make(map[string]int)
+	for _, r := range routes {
+		if r.Route != "" {
+			references[r.Route]++
+		}
+		if hasRouteSplits(r.Splits) {
+			for _, s := range r.Splits {
+				if s.Route != "" {
+					references[s.Route]++
+				}
+			}
+		}
+	}
+
+	for i, r := range routes {
+		if !hasRouteSplits(r.Splits) {
+			continue
+		}
+
+		for j, s := range r.Splits {
+			if s.Route != "" && references[s.Route] > 1 {
+				allErrs = append(allErrs, field.Duplicate(fieldPath.Index(i).Child("splits").Index(j).Child("route"), s.Route))
+			}
+		}
+	}
+
 	return allErrs
 }
 
-func validateRoute(route v1.Route, fieldPath *field.Path, upstreamNames sets.String, isRouteFieldForbidden bool) field.ErrorList {
+func validateRoute(route v1.Route, fieldPath *field.Path, upstreamNames sets.String, mapNames sets.String, isRouteFieldForbidden bool) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	allErrs = append(allErrs, validateRoutePath(route.Path, fieldPath.Child("path"))...)
@@ -567,22 +1426,61 @@ func validateRoute(route v1.Route, fieldPath *field.Path, upstreamNames sets.Str
 	}
 
 	if len(route.Splits) > 0 {
-		allErrs = append(allErrs, validateSplits(route.Splits, fieldPath.Child("splits"), upstreamNames, route.Path)...)
+		if !isRouteFieldForbidden && hasRouteSplits(route.Splits) {
+			allErrs = append(allErrs, validateRouteSplits(route, fieldPath)...)
+		} else {
+			allErrs = append(allErrs, validateSplits(route.Splits, fieldPath.Child("splits"), upstreamNames, route.Path)...)
+		}
 		fieldCount++
 	}
 
 	// Matches are optional. that's why we don't do fieldCount++
 	if len(route.Matches) > 0 {
 		for i, m := range route.Matches {
-			allErrs = append(allErrs, validateMatch(m, fieldPath.Child("matches").Index(i), upstreamNames, route.Path)...)
+			allErrs = append(allErrs, validateMatch(m, fieldPath.Child("matches").Index(i), upstreamNames, mapNames, route.Path)...)
 		}
 	}
 
+	allErrs = append(allErrs, validateStickySplits(route, fieldPath.Child("stickySplits"))...)
+	allErrs = append(allErrs, validateCutover(route, fieldPath.Child("cutover"))...)
+
 	for i, e := range route.ErrorPages {
 		allErrs = append(allErrs, validateErrorPage(e, fieldPath.Child("errorPages").Index(i))...)
 	}
 
+	allErrs = append(allErrs, validateAllowedMethods(route.AllowedMethods, fieldPath.Child("allowedMethods"))...)
+	allErrs = append(allErrs, validateSubFilter(route.SubFilter, fieldPath.Child("subFilter"))...)
+	allErrs = append(allErrs, validatePolicyReferences(route.Policies, fieldPath.Child("policies"))...)
+	allErrs = append(allErrs, validateMirror(route.Mirror, fieldPath.Child("mirror"), upstreamNames)...)
+
+	if route.Mirror != nil && !isRouteFieldForbidden && hasRouteSplits(route.Splits) {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("mirror"), "is not allowed when the splits reference VirtualServerRoutes"))
+	}
+
+	if route.RequestBuffering != nil && !*route.RequestBuffering && isRequestBodyUsedInRoute(route) {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("requestBuffering"), "cannot be disabled when the route uses the request body in conditions or return actions"))
+	}
+
 	if route.Route != "" {
+		if len(route.AllowedMethods) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("allowedMethods"), "is not allowed when `route` is specified"))
+		}
+		if route.SubFilter != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("subFilter"), "is not allowed when `route` is specified"))
+		}
+		if route.RequestBuffering != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("requestBuffering"), "is not allowed when `route` is specified"))
+		}
+		if route.LocationSnippets != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("locationSnippets"), "is not allowed when `route` is specified"))
+		}
+		if len(route.Policies) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("policies"), "is not allowed when `route` is specified"))
+		}
+		if route.Mirror != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("mirror"), "is not allowed when `route` is specified"))
+		}
+
 		if isRouteFieldForbidden {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("route"), "is not allowed"))
 		} else {
@@ -603,6 +1501,159 @@ func validateRoute(route v1.Route, fieldPath *field.Path, upstreamNames sets.Str
 	return allErrs
 }
 
+// validateMirror validates the mirroring of a route to a shadow upstream.
+func validateMirror(mirror *v1.Mirror, fieldPath *field.Path, upstreamNames sets.String) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if mirror == nil {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateReferencedUpstream(mirror.Upstream, fieldPath.Child("upstream"), upstreamNames)...)
+
+	if mirror.Percentage != nil {
+		for _, msg := range validation.IsInRange(*mirror.Percentage, 1, 100) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("percentage"), *mirror.Percentage, msg))
+		}
+	}
+
+	return allErrs
+}
+
+// validatePolicyReferences validates the references to the policies of a route.
+func validatePolicyReferences(policies []v1.PolicyReference, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	policyKeys := sets.String{}
+
+	for i, p := range policies {
+		idxPath := fieldPath.Index(i)
+
+		if p.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), ""))
+			continue
+		}
+
+		nameErrs := field.ErrorList{}
+		for _, msg := range validation.IsDNS1123Subdomain(p.Name) {
+			nameErrs = append(nameErrs, field.Invalid(idxPath.Child("name"), p.Name, msg))
+		}
+
+		if p.Namespace != "" {
+			for _, msg := range validation.IsDNS1123Label(p.Namespace) {
+				nameErrs = append(nameErrs, field.Invalid(idxPath.Child("namespace"), p.Namespace, msg))
+			}
+		}
+
+		if len(nameErrs) > 0 {
+			allErrs = append(allErrs, nameErrs...)
+			continue
+		}
+
+		key := p.Namespace + "/" + p.Name
+		if policyKeys.Has(key) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, p.Name))
+		} else {
+			policyKeys.Insert(key)
+		}
+	}
+
+	return allErrs
+}
+
+var validSubFilterReplaceValues = map[string]bool{
+	"once": true,
+	"all":  true,
+}
+
+const subFilterStringErrMsg string = `must have all '"' escaped and must not contain any '$' or end with an unescaped '\'`
+
+func validateSubFilter(subFilter *v1.SubFilter, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if subFilter == nil {
+		return allErrs
+	}
+
+	if len(subFilter.Substitutions) == 0 {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("substitutions"), "must specify at least one substitution"))
+	}
+
+	allFroms := sets.String{}
+	for i, s := range subFilter.Substitutions {
+		idxPath := fieldPath.Child("substitutions").Index(i)
+
+		if s.From == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("from"), ""))
+		} else if !headerValueFmtRegexp.MatchString(s.From) {
+			msg := validation.RegexError(subFilterStringErrMsg, headerValueFmt, "http://backend.local", `\"title\"`)
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("from"), s.From, msg))
+		} else if allFroms.Has(strings.ToLower(s.From)) {
+			// sub_filter matches strings case-insensitively
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("from"), s.From))
+		} else {
+			allFroms.Insert(strings.ToLower(s.From))
+		}
+
+		if !headerValueFmtRegexp.MatchString(s.To) {
+			msg := validation.RegexError(subFilterStringErrMsg, headerValueFmt, "https://example.com", `\"new title\"`)
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("to"), s.To, msg))
+		}
+	}
+
+	if subFilter.Replace != "" && !validSubFilterReplaceValues[subFilter.Replace] {
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("replace"), subFilter.Replace, []string{"once", "all"}))
+	}
+
+	allTypes := sets.String{}
+	for i, t := range subFilter.Types {
+		idxPath := fieldPath.Child("types").Index(i)
+
+		if t != "*" && !mimeTypeRegexp.MatchString(t) {
+			msg := validation.RegexError(mimeTypeErrMsg, mimeTypeFmt, "application/json", "text/css")
+			allErrs = append(allErrs, field.Invalid(idxPath, t, msg))
+		} else if t == "text/html" {
+			allErrs = append(allErrs, field.Invalid(idxPath, t, "responses of the `text/html` type are always processed"))
+		} else if allTypes.Has(t) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, t))
+		} else {
+			allTypes.Insert(t)
+		}
+	}
+
+	return allErrs
+}
+
+var validRequestMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"POST":    true,
+	"PUT":     true,
+	"DELETE":  true,
+	"CONNECT": true,
+	"OPTIONS": true,
+	"TRACE":   true,
+	"PATCH":   true,
+}
+
+func validateAllowedMethods(methods []string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	allMethods := sets.String{}
+
+	for i, m := range methods {
+		idxPath := fieldPath.Index(i)
+
+		if !validRequestMethods[m] {
+			allErrs = append(allErrs, field.Invalid(idxPath, m, "must be one of GET, HEAD, POST, PUT, DELETE, CONNECT, OPTIONS, TRACE or PATCH"))
+		} else if allMethods.Has(m) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, m))
+		} else {
+			allMethods.Insert(m)
+		}
+	}
+
+	return allErrs
+}
+
 func errorPageHasRequiredFields(errorPage v1.ErrorPage) bool {
 	var count int
 
@@ -662,6 +1713,10 @@ func validateErrorPageReturn(r *v1.ErrorPageReturn, fieldPath *field.Path) field
 var errorPageHeaderValueVariables = map[string]bool{"upstream_status": true}
 
 func validateErrorPageHeader(h v1.Header, fieldPath *field.Path) field.ErrorList {
+	return validateReturnHeader(h, fieldPath, errorPageHeaderValueVariables)
+}
+
+func validateReturnHeader(h v1.Header, fieldPath *field.Path, validVars map[string]bool) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	if h.Name == "" {
@@ -677,7 +1732,7 @@ func validateErrorPageHeader(h v1.Header, fieldPath *field.Path) field.ErrorList
 		allErrs = append(allErrs, field.Invalid(fieldPath.Child("value"), h.Value, msg))
 	}
 
-	allErrs = append(allErrs, validateStringWithVariables(h.Value, fieldPath.Child("value"), nil, errorPageHeaderValueVariables)...)
+	allErrs = append(allErrs, validateStringWithVariables(h.Value, fieldPath.Child("value"), nil, validVars)...)
 
 	return allErrs
 }
@@ -713,6 +1768,36 @@ func countActions(action *v1.Action) int {
 	return count
 }
 
+// isRequestBodyUsedInRoute returns true if the conditions or the return actions of the route use the request body,
+// which is only available when NGINX reads the whole body of the request before passing it to an upstream.
+func isRequestBodyUsedInRoute(route v1.Route) bool {
+	actions := []*v1.Action{route.Action}
+	for _, s := range route.Splits {
+		actions = append(actions, s.Action)
+	}
+
+	for _, m := range route.Matches {
+		for _, c := range m.Conditions {
+			if c.Variable == "$request_body" {
+				return true
+			}
+		}
+
+		actions = append(actions, m.Action)
+		for _, s := range m.Splits {
+			actions = append(actions, s.Action)
+		}
+	}
+
+	for _, a := range actions {
+		if a != nil && a.Return != nil && strings.Contains(a.Return.Body, "${request_body}") {
+			return true
+		}
+	}
+
+	return false
+}
+
 // returnBodyVariables includes NGINX variables allowed to be used in a return body.
 var returnBodyVariables = map[string]bool{
 	"request_uri":         true,
@@ -758,7 +1843,7 @@ func validateAction(action *v1.Action, fieldPath *field.Path, upstreamNames sets
 	}
 
 	if action.Pass != "" {
-		allErrs = append(allErrs, validateReferencedUpstream(action.Pass, fieldPath.Child("pass"), upstreamNames)...)
+		allErrs = append(allErrs, validateActionUpstream(action.Pass, fieldPath.Child("pass"), upstreamNames)...)
 	}
 
 	if action.Redirect != nil {
@@ -773,6 +1858,61 @@ func validateAction(action *v1.Action, fieldPath *field.Path, upstreamNames sets
 		allErrs = append(allErrs, validateActionProxy(action.Proxy, fieldPath.Child("proxy"), upstreamNames, path, internal)...)
 	}
 
+	if action.ClientCertForwarding != nil {
+		if action.Pass == "" && action.Proxy == nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("clientCertForwarding"), "can only be used with `pass` or `proxy`"))
+		}
+		allErrs = append(allErrs, validateClientCertForwarding(action.ClientCertForwarding, fieldPath.Child("clientCertForwarding"))...)
+	}
+
+	return allErrs
+}
+
+// validClientCertVariables includes the variables with the details of the client certificate
+// that can be passed in a header. $ssl_client_cert is not included because its value spans multiple lines.
+var validClientCertVariables = map[string]bool{
+	"ssl_client_escaped_cert": true,
+	"ssl_client_fingerprint":  true,
+	"ssl_client_i_dn":         true,
+	"ssl_client_s_dn":         true,
+	"ssl_client_serial":       true,
+	"ssl_client_v_end":        true,
+	"ssl_client_v_remain":     true,
+	"ssl_client_v_start":      true,
+	"ssl_client_verify":       true,
+}
+
+func validateClientCertForwarding(ccf *v1.ClientCertForwarding, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(ccf.Headers) == 0 {
+		return append(allErrs, field.Required(fieldPath.Child("headers"), "must include at least one header"))
+	}
+
+	headerNames := sets.String{}
+
+	for i, h := range ccf.Headers {
+		idxPath := fieldPath.Child("headers").Index(i)
+
+		if h.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), ""))
+		} else if msgs := validation.IsHTTPHeaderName(h.Name); len(msgs) > 0 {
+			for _, msg := range msgs {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), h.Name, msg))
+			}
+		} else if headerNames.Has(strings.ToLower(h.Name)) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), h.Name))
+		} else {
+			headerNames.Insert(strings.ToLower(h.Name))
+		}
+
+		if h.Variable == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("variable"), ""))
+		} else {
+			allErrs = append(allErrs, validateVariable(h.Variable, validClientCertVariables, idxPath.Child("variable"))...)
+		}
+	}
+
 	return allErrs
 }
 
@@ -785,6 +1925,11 @@ func validateActionRedirect(redirect *v1.ActionRedirect, fieldPath *field.Path,
 		allErrs = append(allErrs, validateRedirectStatusCode(redirect.Code, fieldPath.Child("code"))...)
 	}
 
+	if (redirect.PreservePath || redirect.PreserveQuery) && strings.Contains(redirect.URL, "?") {
+		msg := "must not contain a query string when the path or the query string of the request is preserved"
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("url"), redirect.URL, msg))
+	}
+
 	return allErrs
 }
 
@@ -933,12 +2078,23 @@ func validateActionReturn(r *v1.ActionReturn, fieldPath *field.Path, specialVali
 		allErrs = append(allErrs, validateActionReturnCode(r.Code, fieldPath.Child("code"))...)
 	}
 
+	for i, header := range r.Headers {
+		allErrs = append(allErrs, validateReturnHeader(header, fieldPath.Child("headers").Index(i), nil)...)
+	}
+
 	return allErrs
 }
 
+// maxActionReturnBodyLength limits the size of the bodies of returns, which NGINX keeps in memory as part of the config.
+const maxActionReturnBodyLength = 8192
+
 func validateActionReturnBody(body string, fieldPath *field.Path, specialValidVars []string, validVars map[string]bool) field.ErrorList {
 	allErrs := field.ErrorList{}
 
+	if len(body) > maxActionReturnBodyLength {
+		return append(allErrs, field.TooLong(fieldPath, body, maxActionReturnBodyLength))
+	}
+
 	if !escapedStringsFmtRegexp.MatchString(body) {
 		msg := validation.RegexError(escapedStringsErrMsg, escapedStringsFmt, `Hello World! \n`, `\"${request_uri}\" is unavailable. \n`)
 		allErrs = append(allErrs, field.Invalid(fieldPath, body, msg))
@@ -998,10 +2154,28 @@ func validateReferencedUpstream(name string, fieldPath *field.Path, upstreamName
 	return allErrs
 }
 
+// validateActionUpstream validates the upstream of an action, which is either the name of an upstream
+// or the variable of a map of the VirtualServer that selects an upstream.
+func validateActionUpstream(name string, fieldPath *field.Path, upstreamNames sets.String) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !strings.HasPrefix(name, "$") {
+		return validateReferencedUpstream(name, fieldPath, upstreamNames)
+	}
+
+	if !upstreamNames.Has(name) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, name, "must be the variable of a map of the VirtualServer in the format $<map name>"))
+	}
+
+	return allErrs
+}
+
 func validateActionProxy(p *v1.ActionProxy, fieldPath *field.Path, upstreamNames sets.String, path string, internal bool) field.ErrorList {
 	allErrs := field.ErrorList{}
 
-	allErrs = append(allErrs, validateReferencedUpstream(p.Upstream, fieldPath.Child("upstream"), upstreamNames)...)
+	allErrs = append(allErrs, validateActionUpstream(p.Upstream, fieldPath.Child("upstream"), upstreamNames)...)
+	allErrs = append(allErrs, validateActionProxyHostHeader(p.HostHeader, fieldPath.Child("hostHeader"))...)
+	allErrs = append(allErrs, validateActionProxySSLName(p.ProxySSLName, fieldPath.Child("proxySslName"))...)
 	allErrs = append(allErrs, validateActionProxyRequestHeaders(p.RequestHeaders, fieldPath.Child("requestHeaders"))...)
 	allErrs = append(allErrs, validateActionProxyResponseHeaders(p.ResponseHeaders, fieldPath.Child("responseHeaders"))...)
 
@@ -1038,21 +2212,84 @@ func validateActionProxyRewritePath(rewritePath string, fieldPath *field.Path) f
 
 	allErrs = append(allErrs, validateStringNoVariables(rewritePath, fieldPath)...)
 
-	return append(allErrs, validatePath(rewritePath, fieldPath)...)
+	return append(allErrs, validatePath(rewritePath, fieldPath)...)
+}
+
+func validateActionProxyRewritePathForRegexp(rewritePath string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if rewritePath == "" {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateStringNoVariables(rewritePath, fieldPath)...)
+
+	if !escapedStringsFmtRegexp.MatchString(rewritePath) {
+		msg := validation.RegexError(escapedStringsErrMsg, escapedStringsFmt, "/rewrite$1", "/images")
+		allErrs = append(allErrs, field.Invalid(fieldPath, rewritePath, msg))
+	}
+
+	return allErrs
+}
+
+func validateActionProxyHostHeader(hostHeader *v1.ProxyHostHeader, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if hostHeader == nil {
+		return allErrs
+	}
+
+	switch hostHeader.Mode {
+	case "", "preserve", "upstream":
+		if hostHeader.Value != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("value"), "is only allowed with the 'literal' mode"))
+		}
+	case "literal":
+		allErrs = append(allErrs, validateHostHeaderValue(hostHeader.Value, fieldPath.Child("value"))...)
+	default:
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("mode"), hostHeader.Mode, "accepted values are 'preserve', 'upstream', 'literal'"))
+	}
+
+	return allErrs
+}
+
+// validateActionProxySSLName validates the server name sent to a TLS upstream in the SNI and used to verify its certificate.
+func validateActionProxySSLName(name string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if name == "" {
+		return allErrs
+	}
+
+	for _, msg := range validation.IsDNS1123Subdomain(name) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, name, msg))
+	}
+
+	return allErrs
 }
 
-func validateActionProxyRewritePathForRegexp(rewritePath string, fieldPath *field.Path) field.ErrorList {
+// validateHostHeaderValue validates a value of the Host header: a host name or an IP address with an optional port.
+func validateHostHeaderValue(value string, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
-	if rewritePath == "" {
-		return allErrs
+	if value == "" {
+		return append(allErrs, field.Required(fieldPath, ""))
 	}
 
-	allErrs = append(allErrs, validateStringNoVariables(rewritePath, fieldPath)...)
+	host := value
+	if h, port, err := net.SplitHostPort(value); err == nil {
+		host = h
+		if p, err := strconv.Atoi(port); err != nil || len(validation.IsValidPortNum(p)) > 0 {
+			allErrs = append(allErrs, field.Invalid(fieldPath, value, "must contain a valid port number"))
+		}
+	}
 
-	if !escapedStringsFmtRegexp.MatchString(rewritePath) {
-		msg := validation.RegexError(escapedStringsErrMsg, escapedStringsFmt, "/rewrite$1", "/images")
-		allErrs = append(allErrs, field.Invalid(fieldPath, rewritePath, msg))
+	if net.ParseIP(host) != nil {
+		return allErrs
+	}
+
+	for _, msg := range validation.IsDNS1123Subdomain(host) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, value, msg))
 	}
 
 	return allErrs
@@ -1150,11 +2387,151 @@ func validateSplits(splits []v1.Split, fieldPath *field.Path, upstreamNames sets
 			allErrs = append(allErrs, validateAction(s.Action, idxPath.Child("action"), upstreamNames, path, true)...)
 		}
 
+		if s.Route != "" {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("route"), "is only allowed in the splits of a VirtualServer route"))
+		}
+
+		totalWeight += s.Weight
+	}
+
+	if totalWeight != 100 {
+		allErrs = append(allErrs, field.Invalid(fieldPath, totalWeight, "the sum of the weights of all splits must be equal to 100"))
+	}
+
+	return allErrs
+}
+
+// validateCutover validates the cutover of a route. The cutover flips the traffic between the two splits of a blue/green
+// deployment, so it requires exactly two splits.
+func validateCutover(route v1.Route, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if route.Cutover == "" {
+		return allErrs
+	}
+
+	if route.Cutover != v1.CutoverPromote && route.Cutover != v1.CutoverRollback {
+		return append(allErrs, field.NotSupported(fieldPath, route.Cutover, []string{v1.CutoverPromote, v1.CutoverRollback}))
+	}
+
+	if len(route.Splits) != 2 {
+		allErrs = append(allErrs, field.Forbidden(fieldPath, "requires exactly 2 splits"))
+	}
+
+	return allErrs
+}
+
+func hasRouteSplits(splits []v1.Split) bool {
+	for _, s := range splits {
+		if s.Route != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRouteSplits validates the splits of a VirtualServer route that split the traffic between VirtualServerRoutes.
+func validateRouteSplits(route v1.Route, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	splitsPath := fieldPath.Child("splits")
+
+	if isRegexOrExactMatch(route.Path) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("path"), route.Path, "must be a prefix path when the splits reference VirtualServerRoutes"))
+	}
+
+	if len(route.Matches) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("matches"), "is not allowed when the splits reference VirtualServerRoutes"))
+	}
+
+	if route.StickySplits != nil && route.StickySplits.Enable {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("stickySplits"), "is not allowed when the splits reference VirtualServerRoutes"))
+	}
+
+	if len(route.AllowedMethods) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("allowedMethods"), "is not allowed when the splits reference VirtualServerRoutes"))
+	}
+
+	if route.SubFilter != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("subFilter"), "is not allowed when the splits reference VirtualServerRoutes"))
+	}
+
+	if route.RequestBuffering != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("requestBuffering"), "is not allowed when the splits reference VirtualServerRoutes"))
+	}
+
+	if route.LocationSnippets != "" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("locationSnippets"), "is not allowed when the splits reference VirtualServerRoutes"))
+	}
+
+	if len(route.Policies) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("policies"), "is not allowed when the splits reference VirtualServerRoutes"))
+	}
+
+	if len(route.Splits) < 2 {
+		return append(allErrs, field.Invalid(splitsPath, "", "must include at least 2 splits"))
+	}
+
+	totalWeight := 0
+	routes := sets.String{}
+
+	for i, s := range route.Splits {
+		idxPath := splitsPath.Index(i)
+
+		for _, msg := range validation.IsInRange(s.Weight, 1, 99) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("weight"), s.Weight, msg))
+		}
+
+		if s.Action != nil {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("action"), "is not allowed when the splits reference VirtualServerRoutes"))
+		}
+
+		if s.Route == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("route"), "must be specified in all splits when one of the splits references a VirtualServerRoute"))
+		} else if routeErrs := validateRouteField(s.Route, idxPath.Child("route")); len(routeErrs) > 0 {
+			allErrs = append(allErrs, routeErrs...)
+		} else if routes.Has(s.Route) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("route"), s.Route))
+		} else {
+			routes.Insert(s.Route)
+		}
+
 		totalWeight += s.Weight
 	}
 
 	if totalWeight != 100 {
-		allErrs = append(allErrs, field.Invalid(fieldPath, "", "the sum of the weights of all splits must be equal to 100"))
+		allErrs = append(allErrs, field.Invalid(splitsPath, totalWeight, "the sum of the weights of all splits must be equal to 100"))
+	}
+
+	return allErrs
+}
+
+func validateStickySplits(route v1.Route, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	stickySplits := route.StickySplits
+	if stickySplits == nil {
+		return allErrs
+	}
+
+	if stickySplits.CookieName != "" {
+		for _, msg := range isCookieName(stickySplits.CookieName) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("cookieName"), stickySplits.CookieName, msg))
+		}
+	}
+
+	if !stickySplits.Enable {
+		return allErrs
+	}
+
+	hasSplits := len(route.Splits) > 0
+	for _, m := range route.Matches {
+		if len(m.Splits) > 0 {
+			hasSplits = true
+		}
+	}
+
+	if !hasSplits {
+		allErrs = append(allErrs, field.Invalid(fieldPath, "", "requires the route or its matches to define splits"))
 	}
 
 	return allErrs
@@ -1217,14 +2594,27 @@ func validatePath(path string, fieldPath *field.Path) field.ErrorList {
 	return allErrs
 }
 
-func validateMatch(match v1.Match, fieldPath *field.Path, upstreamNames sets.String, path string) field.ErrorList {
+func validateMatch(match v1.Match, fieldPath *field.Path, upstreamNames sets.String, mapNames sets.String, path string) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	if len(match.Conditions) == 0 {
 		allErrs = append(allErrs, field.Required(fieldPath.Child("conditions"), "must specify at least one condition"))
 	} else {
+		sources := sets.NewString()
+
 		for i, c := range match.Conditions {
-			allErrs = append(allErrs, validateCondition(c, fieldPath.Child("conditions").Index(i))...)
+			idxPath := fieldPath.Child("conditions").Index(i)
+			allErrs = append(allErrs, validateCondition(c, idxPath, mapNames)...)
+
+			source := getConditionSource(c)
+			if source == "" {
+				continue
+			}
+			if sources.Has(source) {
+				allErrs = append(allErrs, field.Duplicate(idxPath, source))
+			} else {
+				sources.Insert(source)
+			}
 		}
 	}
 
@@ -1247,7 +2637,7 @@ func validateMatch(match v1.Match, fieldPath *field.Path, upstreamNames sets.Str
 	return allErrs
 }
 
-func validateCondition(condition v1.Condition, fieldPath *field.Path) field.ErrorList {
+func validateCondition(condition v1.Condition, fieldPath *field.Path, mapNames sets.String) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	fieldCount := 0
@@ -1274,7 +2664,7 @@ func validateCondition(condition v1.Condition, fieldPath *field.Path) field.Erro
 	}
 
 	if condition.Variable != "" {
-		allErrs = append(allErrs, validateVariableName(condition.Variable, fieldPath.Child("variable"))...)
+		allErrs = append(allErrs, validateVariableName(condition.Variable, fieldPath.Child("variable"), mapNames)...)
 		fieldCount++
 	}
 
@@ -1289,6 +2679,26 @@ func validateCondition(condition v1.Condition, fieldPath *field.Path) field.Erro
 	return allErrs
 }
 
+// getConditionSource returns the NGINX variable that the condition is evaluated against.
+// The conditions of a match are combined with AND, so NGINX can't satisfy two conditions with the same source
+// unless they are redundant. Header and argument names are case-insensitive in NGINX, and a '-' in a header name
+// is the same as '_'.
+func getConditionSource(condition v1.Condition) string {
+	if condition.Header != "" {
+		return "$http_" + strings.ToLower(strings.ReplaceAll(condition.Header, "-", "_"))
+	}
+
+	if condition.Cookie != "" {
+		return "$cookie_" + condition.Cookie
+	}
+
+	if condition.Argument != "" {
+		return "$arg_" + strings.ToLower(condition.Argument)
+	}
+
+	return condition.Variable
+}
+
 const cookieNameFmt string = "[_A-Za-z0-9]+"
 const cookieNameErrMsg string = "a valid cookie name must consist of alphanumeric characters or '_'"
 
@@ -1329,13 +2739,17 @@ var validVariableNames = map[string]bool{
 	"$scheme":         true,
 }
 
-func validateVariableName(name string, fieldPath *field.Path) field.ErrorList {
+func validateVariableName(name string, fieldPath *field.Path, mapNames sets.String) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	if !strings.HasPrefix(name, "$") {
 		return append(allErrs, field.Invalid(fieldPath, name, "must start with `$`"))
 	}
 
+	if mapNames.Has(name[1:]) {
+		return allErrs
+	}
+
 	if _, exists := validVariableNames[name]; !exists {
 		return append(allErrs, field.Invalid(fieldPath, name, "is not allowed or is not an NGINX variable"))
 	}
@@ -1356,6 +2770,38 @@ func ValidateVirtualServerRoute(virtualServerRoute *v1.VirtualServerRoute, isPlu
 	return allErrs.ToAggregate()
 }
 
+// RejectSnippetsInVirtualServer returns the errors for the snippets of the VirtualServer. It is used when the
+// Ingress Controller doesn't allow snippets, which insert NGINX configuration as is.
+func RejectSnippetsInVirtualServer(virtualServer *v1.VirtualServer) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fieldPath := field.NewPath("spec")
+
+	if virtualServer.Spec.ServerSnippets != "" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("serverSnippets"), "snippets are disabled"))
+	}
+
+	return append(allErrs, rejectLocationSnippets(virtualServer.Spec.Routes, fieldPath.Child("routes"))...)
+}
+
+// RejectSnippetsInVirtualServerRoute returns an error for the snippets of the VirtualServerRoute. It is used when the
+// Ingress Controller doesn't allow snippets.
+func RejectSnippetsInVirtualServerRoute(virtualServerRoute *v1.VirtualServerRoute) error {
+	allErrs := rejectLocationSnippets(virtualServerRoute.Spec.Subroutes, field.NewPath("spec").Child("subroutes"))
+	return allErrs.ToAggregate()
+}
+
+func rejectLocationSnippets(routes []v1.Route, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, r := range routes {
+		if r.LocationSnippets != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Index(i).Child("locationSnippets"), "snippets are disabled"))
+		}
+	}
+
+	return allErrs
+}
+
 // ValidateVirtualServerRouteForVirtualServer validates a VirtualServerRoute for a VirtualServer represented by its host and path prefix.
 func ValidateVirtualServerRouteForVirtualServer(virtualServerRoute *v1.VirtualServerRoute, virtualServerHost string, vsPath string, isPlus bool) error {
 	allErrs := validateVirtualServerRouteSpec(&virtualServerRoute.Spec, field.NewPath("spec"), virtualServerHost, vsPath, isPlus)
@@ -1375,6 +2821,22 @@ func validateVirtualServerRouteSpec(spec *v1.VirtualServerRouteSpec, fieldPath *
 	return allErrs
 }
 
+// ValidateVirtualServerRouteForRouteSplit validates a VirtualServerRoute referenced in the splits of a VirtualServer route.
+// On top of the checks of ValidateVirtualServerRouteForVirtualServer, it rejects the subroutes with regex paths,
+// because their locations cannot be made internal to the split.
+func ValidateVirtualServerRouteForRouteSplit(virtualServerRoute *v1.VirtualServerRoute, virtualServerHost string, vsPath string, isPlus bool) error {
+	fieldPath := field.NewPath("spec")
+	allErrs := validateVirtualServerRouteSpec(&virtualServerRoute.Spec, fieldPath, virtualServerHost, vsPath, isPlus)
+
+	for i, r := range virtualServerRoute.Spec.Subroutes {
+		if strings.HasPrefix(r.Path, "~") {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("subroutes").Index(i).Child("path"), r.Path, "must not be a regex path when the VirtualServerRoute is referenced in splits"))
+		}
+	}
+
+	return allErrs.ToAggregate()
+}
+
 func validateVirtualServerRouteHost(host string, virtualServerHost string, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -1407,14 +2869,14 @@ func validateVirtualServerRouteSubroutes(routes []v1.Route, fieldPath *field.Pat
 			return append(allErrs, field.Invalid(idxPath.Child("path"), routes[0].Path, "must have the same path as the referenced VirtualServer route path"))
 		}
 
-		return validateRoute(routes[0], idxPath, upstreamNames, true)
+		return validateRoute(routes[0], idxPath, upstreamNames, nil, true)
 	}
 
 	for i, r := range routes {
 		idxPath := fieldPath.Index(i)
 
 		isRouteFieldForbidden := true
-		routeErrs := validateRoute(r, idxPath, upstreamNames, isRouteFieldForbidden)
+		routeErrs := validateRoute(r, idxPath, upstreamNames, nil, isRouteFieldForbidden)
 
 		if vsPath != "" && !strings.HasPrefix(r.Path, vsPath) && !isRegexOrExactMatch(r.Path) {
 			msg := fmt.Sprintf("must start with '%s'", vsPath)
@@ -1456,6 +2918,141 @@ func rejectPlusResourcesInOSS(upstream v1.Upstream, idxPath *field.Path, isPlus
 		allErrs = append(allErrs, field.Forbidden(idxPath.Child("queue"), "queue is only supported in NGINX Plus"))
 	}
 
+	if upstream.SRV != nil {
+		allErrs = append(allErrs, field.Forbidden(idxPath.Child("srv"), "DNS SRV service discovery is only supported in NGINX Plus"))
+	}
+
+	if upstream.ResolverValid != "" {
+		allErrs = append(allErrs, field.Forbidden(idxPath.Child("resolver-valid"), "resolver-valid is only supported in NGINX Plus"))
+	}
+
+	return allErrs
+}
+
+// validateUpstreamServices validates the additional services of an upstream, whose endpoints are combined with
+// the endpoints of the service of the upstream. The additional services use the port of the upstream.
+func validateUpstreamServices(upstream v1.Upstream, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(upstream.Services) == 0 {
+		return allErrs
+	}
+
+	if len(upstream.Subselector) > 0 {
+		return append(allErrs, field.Forbidden(fieldPath, "is not allowed with subselector"))
+	}
+
+	if upstream.SRV != nil {
+		return append(allErrs, field.Forbidden(fieldPath, "is not allowed with srv"))
+	}
+
+	services := sets.NewString(upstream.Service)
+	for i, s := range upstream.Services {
+		idxPath := fieldPath.Index(i)
+
+		allErrs = append(allErrs, validateServiceName(s, idxPath)...)
+
+		if s == upstream.Backup && upstream.BackupPort == upstream.Port {
+			allErrs = append(allErrs, field.Invalid(idxPath, s, "must be different from the backup service and port"))
+		}
+
+		if services.Has(s) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, s))
+		} else {
+			services.Insert(s)
+		}
+	}
+
+	return allErrs
+}
+
+// validateUpstreamDown validates the addresses of the upstream servers marked as down. An address is the IP of an
+// endpoint, which marks the endpoint down on all ports, or the IP and the port of an endpoint.
+func validateUpstreamDown(upstream v1.Upstream, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(upstream.Down) == 0 {
+		return allErrs
+	}
+
+	if upstream.SRV != nil {
+		return append(allErrs, field.Forbidden(fieldPath, "is not allowed with srv"))
+	}
+
+	addresses := sets.String{}
+	for i, address := range upstream.Down {
+		idxPath := fieldPath.Index(i)
+
+		allErrs = append(allErrs, validateEndpointAddress(address, idxPath)...)
+
+		if addresses.Has(address) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, address))
+		} else {
+			addresses.Insert(address)
+		}
+	}
+
+	return allErrs
+}
+
+func validateEndpointAddress(address string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if net.ParseIP(address) != nil {
+		return allErrs
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) == nil {
+		return append(allErrs, field.Invalid(fieldPath, address, "must be an IP address or an IP address with a port, for example 10.0.0.1 or 10.0.0.1:8080"))
+	}
+
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		return append(allErrs, field.Invalid(fieldPath, address, "must include a numeric port"))
+	}
+
+	for _, msg := range validation.IsValidPortNum(portNum) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, address, msg))
+	}
+
+	return allErrs
+}
+
+// validateUpstreamBackup validates the backup service of an upstream. The backup service must differ from
+// the primary service, so that the upstream always has primary servers.
+func validateUpstreamBackup(upstream v1.Upstream, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if upstream.Backup == "" {
+		if upstream.BackupPort != 0 {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("backup-port"), "requires backup"))
+		}
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateServiceName(upstream.Backup, fieldPath.Child("backup"))...)
+
+	if upstream.BackupPort == 0 {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("backup-port"), ""))
+	} else {
+		for _, msg := range validation.IsValidPortNum(int(upstream.BackupPort)) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("backup-port"), upstream.BackupPort, msg))
+		}
+	}
+
+	if upstream.Backup == upstream.Service && upstream.BackupPort == upstream.Port && len(upstream.Subselector) == 0 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("backup"), upstream.Backup, "must be different from the primary service and port"))
+	}
+
+	if upstream.SRV != nil {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("backup"), "backup is not allowed with srv"))
+	}
+
+	if !configs.IsLBMethodCompatibleWithBackup(upstream.LBMethod) {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("backup"), fmt.Sprintf("backup is not allowed with the lb method %q", upstream.LBMethod)))
+	}
+
 	return allErrs
 }
 
@@ -1474,6 +3071,87 @@ func validateQueue(queue *v1.UpstreamQueue, fieldPath *field.Path) field.ErrorLi
 	return allErrs
 }
 
+var cacheValidCodeRegexp = regexp.MustCompile(`^[1-5][0-9][0-9]$`)
+
+func validateUpstreamCache(cache *v1.UpstreamCache, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if cache == nil {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateSize(cache.ZoneSize, fieldPath.Child("zoneSize"))...)
+
+	if cache.CacheLockTimeout != "" {
+		if !cache.CacheLock {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("cacheLockTimeout"), "cacheLockTimeout requires cacheLock to be enabled"))
+		}
+		allErrs = append(allErrs, validateTime(cache.CacheLockTimeout, fieldPath.Child("cacheLockTimeout"))...)
+	}
+
+	var codes []string
+	for code := range cache.Valid {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		time := cache.Valid[code]
+		validPath := fieldPath.Child("valid").Key(code)
+
+		if code != "any" && !cacheValidCodeRegexp.MatchString(code) {
+			allErrs = append(allErrs, field.Invalid(validPath, code, "must be a status code within the range [100-599] or 'any'"))
+		}
+
+		if time == "" {
+			allErrs = append(allErrs, field.Required(validPath, "must specify the validity time"))
+		} else {
+			allErrs = append(allErrs, validateTime(time, validPath)...)
+		}
+	}
+
+	if cache.Key != "" {
+		allErrs = append(allErrs, validateCacheKey(cache.Key, fieldPath.Child("key"))...)
+	}
+
+	allErrs = append(allErrs, validateIgnoreHeaders(cache.IgnoreHeaders, fieldPath.Child("ignoreHeaders"))...)
+
+	if cache.OverrideCacheControl && len(cache.Valid) == 0 {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("overrideCacheControl"), "overrideCacheControl requires the caching times of the valid field"))
+	}
+
+	return allErrs
+}
+
+// cacheKeySpecialVariables includes the prefixes of the NGINX variables allowed in the key of a cache.
+var cacheKeySpecialVariables = []string{"arg_", "http_", "cookie_"}
+
+// cacheKeyVariables includes the NGINX variables allowed in the key of a cache.
+var cacheKeyVariables = map[string]bool{
+	"scheme":         true,
+	"host":           true,
+	"proxy_host":     true,
+	"request_method": true,
+	"request_uri":    true,
+	"uri":            true,
+	"args":           true,
+	"remote_addr":    true,
+	"server_port":    true,
+}
+
+func validateCacheKey(key string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !escapedStringsFmtRegexp.MatchString(key) {
+		msg := validation.RegexError(escapedStringsErrMsg, escapedStringsFmt, "${scheme}${host}${request_uri}", "${host}${request_uri}${http_authorization}")
+		allErrs = append(allErrs, field.Invalid(fieldPath, key, msg))
+	}
+
+	allErrs = append(allErrs, validateStringWithVariables(key, fieldPath, cacheKeySpecialVariables, cacheKeyVariables)...)
+
+	return allErrs
+}
+
 // isValidLabelName checks if a label name is valid.
 // It performs the same validation as ValidateLabelName from k8s.io/apimachinery/pkg/apis/meta/v1/validation/validation.go.
 func isValidLabelName(labelName string, fieldPath *field.Path) field.ErrorList {
@@ -2,6 +2,7 @@ package validation
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	v1 "github.com/nginxinc/kubernetes-ingress/pkg/apis/configuration/v1"
@@ -54,9 +55,54 @@ func TestValidateVirtualServer(t *testing.T) {
 		},
 	}
 
-	err := ValidateVirtualServer(&virtualServer, false)
-	if err != nil {
-		t.Errorf("ValidateVirtualServer() returned error %v for valid input %v", err, virtualServer)
+	errs := ValidateVirtualServer(&virtualServer, false)
+	if len(errs) > 0 {
+		t.Errorf("ValidateVirtualServer() returned errors %v for valid input %v", errs, virtualServer)
+	}
+}
+
+func TestValidateVirtualServerReportsAllErrors(t *testing.T) {
+	virtualServer := v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+		Spec: v1.VirtualServerSpec{
+			Host: "",
+			Upstreams: []v1.Upstream{
+				{
+					Name:    "first",
+					Service: "service-1",
+					Port:    0,
+				},
+			},
+			Routes: []v1.Route{
+				{
+					Path: "/first",
+					Action: &v1.Action{
+						Pass: "nonexistent",
+					},
+				},
+			},
+		},
+	}
+
+	errs := ValidateVirtualServer(&virtualServer, false)
+
+	expectedFields := []string{
+		"spec.host",
+		"spec.upstreams[0].port",
+		"spec.routes[0].action.pass",
+	}
+
+	if len(errs) != len(expectedFields) {
+		t.Fatalf("ValidateVirtualServer() returned %d errors %v but expected %d", len(errs), errs, len(expectedFields))
+	}
+
+	for i, f := range expectedFields {
+		if errs[i].Field != f {
+			t.Errorf("ValidateVirtualServer() returned error #%d for field %q but expected %q", i, errs[i].Field, f)
+		}
 	}
 }
 
@@ -124,6 +170,32 @@ func TestValidateTLS(t *testing.T) {
 				Code:   createPointerFromInt(307),
 			},
 		},
+		{
+			Secret: "shared-secrets/my-secret",
+		},
+		{
+			Secret:           "my-secret",
+			SessionCache:     "10m",
+			SessionTimeout:   "1h",
+			SessionTickets:   createPointerFromBool(true),
+			SessionTicketKey: "ticket-key-secret",
+		},
+		{
+			Secret:           "my-secret",
+			SessionCache:     "off",
+			SessionTicketKey: "shared-secrets/ticket-key-secret",
+		},
+		{
+			Secret:         "my-secret",
+			SessionCache:   "none",
+			SessionTickets: createPointerFromBool(false),
+		},
+		{
+			Certificate: "my-certificate",
+		},
+		{
+			DynamicCertificates: true,
+		},
 	}
 
 	for _, tls := range validTLSes {
@@ -138,7 +210,7 @@ func TestValidateTLS(t *testing.T) {
 			Secret: "-",
 		},
 		{
-			Secret: "a/b",
+			Secret: "a/b/c",
 		},
 		{
 			Secret: "my-secret",
@@ -156,6 +228,42 @@ func TestValidateTLS(t *testing.T) {
 				BasedOn: "invalidScheme",
 			},
 		},
+		{
+			Secret:       "my-secret",
+			SessionCache: "shared:SSL:10m",
+		},
+		{
+			Secret:       "my-secret",
+			SessionCache: "10g",
+		},
+		{
+			Secret:         "my-secret",
+			SessionTimeout: "1 hour",
+		},
+		{
+			Secret:           "my-secret",
+			SessionTicketKey: "a/b/c",
+		},
+		{
+			Secret:           "my-secret",
+			SessionTickets:   createPointerFromBool(false),
+			SessionTicketKey: "ticket-key-secret",
+		},
+		{
+			Certificate: "shared/my-certificate",
+		},
+		{
+			Secret:      "my-secret",
+			Certificate: "my-certificate",
+		},
+		{
+			Secret:              "my-secret",
+			DynamicCertificates: true,
+		},
+		{
+			Certificate:         "my-certificate",
+			DynamicCertificates: true,
+		},
 	}
 
 	for _, tls := range invalidTLSes {
@@ -166,570 +274,2840 @@ func TestValidateTLS(t *testing.T) {
 	}
 }
 
-func TestValidateUpstreams(t *testing.T) {
+func TestValidateRequestID(t *testing.T) {
+	tests := []*v1.RequestID{
+		nil,
+		{Enable: true},
+		{Enable: true, Header: "X-Trace-ID"},
+	}
+
+	for _, test := range tests {
+		allErrs := validateRequestID(test, field.NewPath("requestID"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateRequestID(%+v) returned errors for valid input: %v", test, allErrs)
+		}
+	}
+}
+
+func TestValidateRequestIDFails(t *testing.T) {
+	tests := []*v1.RequestID{
+		{Enable: true, Header: "X Trace ID"},
+		{Enable: true, Header: "X-Trace-ID:"},
+	}
+
+	for _, test := range tests {
+		allErrs := validateRequestID(test, field.NewPath("requestID"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateRequestID(%+v) returned no errors for invalid input", test)
+		}
+	}
+}
+
+func TestValidateServerTokens(t *testing.T) {
 	tests := []struct {
-		upstreams             []v1.Upstream
-		expectedUpstreamNames sets.String
-		msg                   string
+		serverTokens string
+		isPlus       bool
 	}{
-		{
-			upstreams:             []v1.Upstream{},
-			expectedUpstreamNames: sets.String{},
-			msg:                   "no upstreams",
-		},
-		{
-			upstreams: []v1.Upstream{
-				{
-					Name:                     "upstream1",
-					Service:                  "test-1",
-					Port:                     80,
-					ProxyNextUpstream:        "error timeout",
-					ProxyNextUpstreamTimeout: "10s",
-					ProxyNextUpstreamTries:   5,
-					MaxConns:                 createPointerFromInt(16),
-				},
-				{
-					Name:                     "upstream2",
-					Subselector:              map[string]string{"version": "test"},
-					Service:                  "test-2",
-					Port:                     80,
-					ProxyNextUpstream:        "error timeout",
-					ProxyNextUpstreamTimeout: "10s",
-					ProxyNextUpstreamTries:   5,
-				},
-			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
-				"upstream2": {},
-			},
-			msg: "2 valid upstreams",
-		},
+		{serverTokens: "", isPlus: false},
+		{serverTokens: "on", isPlus: false},
+		{serverTokens: "off", isPlus: false},
+		{serverTokens: "build", isPlus: false},
+		{serverTokens: "build", isPlus: true},
+		{serverTokens: "My Server", isPlus: true},
+		{serverTokens: `My \"Server\"`, isPlus: true},
 	}
-	isPlus := false
+
 	for _, test := range tests {
-		allErrs, resultUpstreamNames := validateUpstreams(test.upstreams, field.NewPath("upstreams"), isPlus)
-		if len(allErrs) > 0 {
-			t.Errorf("validateUpstreams() returned errors %v for valid input for the case of %s", allErrs, test.msg)
-		}
-		if !resultUpstreamNames.Equal(test.expectedUpstreamNames) {
-			t.Errorf("validateUpstreams() returned %v expected %v for the case of %s", resultUpstreamNames, test.expectedUpstreamNames, test.msg)
+		allErrs := validateServerTokens(test.serverTokens, field.NewPath("serverTokens"), test.isPlus)
+		if len(allErrs) != 0 {
+			t.Errorf("validateServerTokens(%q, %v) returned errors for valid input: %v", test.serverTokens, test.isPlus, allErrs)
 		}
 	}
 }
 
-func TestValidateUpstreamsFails(t *testing.T) {
+func TestValidateServerTokensFails(t *testing.T) {
 	tests := []struct {
-		upstreams             []v1.Upstream
-		expectedUpstreamNames sets.String
-		msg                   string
+		serverTokens string
+		isPlus       bool
 	}{
+		{serverTokens: "My Server", isPlus: false},
+		{serverTokens: "true", isPlus: false},
+		{serverTokens: `My "Server"`, isPlus: true},
+		{serverTokens: `My Server\`, isPlus: true},
+	}
+
+	for _, test := range tests {
+		allErrs := validateServerTokens(test.serverTokens, field.NewPath("serverTokens"), test.isPlus)
+		if len(allErrs) == 0 {
+			t.Errorf("validateServerTokens(%q, %v) returned no errors for invalid input", test.serverTokens, test.isPlus)
+		}
+	}
+}
+
+func TestValidateClientBody(t *testing.T) {
+	tests := []*v1.ClientBody{
+		nil,
+		{},
+		{BufferSize: "16k"},
+		{TempPath: "/var/cache/nginx/uploads"},
+		{BufferSize: "1m", TempPath: "/var/lib/nginx/client_body_temp"},
+		{Timeout: "30s"},
+	}
+
+	for _, test := range tests {
+		allErrs := validateClientBody(test, field.NewPath("clientBody"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateClientBody(%+v) returned errors for valid input: %v", test, allErrs)
+		}
+	}
+}
+
+func TestValidateClientHeaders(t *testing.T) {
+	tests := []*v1.ClientHeaders{
+		nil,
+		{},
+		{BufferSize: "2k"},
+		{LargeBuffers: &v1.UpstreamBuffers{Number: 4, Size: "16k"}},
+		{BufferSize: "1k", LargeBuffers: &v1.UpstreamBuffers{Number: 8, Size: "1m"}},
+		{Timeout: "10s"},
+	}
+
+	for _, test := range tests {
+		allErrs := validateClientHeaders(test, field.NewPath("clientHeaders"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateClientHeaders(%+v) returned errors for valid input: %v", test, allErrs)
+		}
+	}
+}
+
+func TestValidateClientHeadersFails(t *testing.T) {
+	tests := []*v1.ClientHeaders{
+		{BufferSize: "2kb"},
+		{LargeBuffers: &v1.UpstreamBuffers{Number: 0, Size: "16k"}},
+		{LargeBuffers: &v1.UpstreamBuffers{Number: 4}},
+		{LargeBuffers: &v1.UpstreamBuffers{Number: 4, Size: "16g"}},
+		{Timeout: "10x"},
+		{Timeout: "10 seconds"},
+	}
+
+	for _, test := range tests {
+		allErrs := validateClientHeaders(test, field.NewPath("clientHeaders"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateClientHeaders(%+v) returned no errors for invalid input", test)
+		}
+	}
+}
+
+func TestValidateClientBodyFails(t *testing.T) {
+	tests := []*v1.ClientBody{
+		{BufferSize: "16kb"},
+		{BufferSize: "-1"},
+		{TempPath: "uploads"},
+		{TempPath: "/tmp/uploads"},
+		{TempPath: "/var/cache/nginx"},
+		{TempPath: "/var/cache/nginx/"},
+		{TempPath: "/var/cache/nginx/../../../etc/nginx"},
+		{TempPath: "/var/cache/nginx/uploads;"},
+		{TempPath: "/var/cache/nginx/up loads"},
+		{Timeout: "ten"},
+		{Timeout: "-30s"},
+	}
+
+	for _, test := range tests {
+		allErrs := validateClientBody(test, field.NewPath("clientBody"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateClientBody(%+v) returned no errors for invalid input", test)
+		}
+	}
+}
+
+func TestValidateCompression(t *testing.T) {
+	tests := []*v1.Compression{
+		nil,
+		{Type: "gzip"},
+		{Type: "gzip", Types: []string{"application/json", "image/svg+xml"}, MinLength: 1000, Level: createPointerFromInt(9)},
+		{Type: "gzip", Types: []string{"*"}},
+		{Type: "brotli", Level: createPointerFromInt(0)},
+		{Type: "brotli", Types: []string{"text/css", "application/javascript"}, Level: createPointerFromInt(11)},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCompression(test, field.NewPath("compression"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateCompression(%+v) returned errors for valid input: %v", test, allErrs)
+		}
+	}
+}
+
+func TestValidateCompressionFails(t *testing.T) {
+	tests := []*v1.Compression{
+		{},
+		{Type: "deflate"},
+		{Type: "gzip", Level: createPointerFromInt(0)},
+		{Type: "gzip", Level: createPointerFromInt(10)},
+		{Type: "brotli", Level: createPointerFromInt(12)},
+		{Type: "gzip", MinLength: -1},
+		{Type: "gzip", Types: []string{"json"}},
+		{Type: "gzip", Types: []string{"application/json;"}},
+		{Type: "gzip", Types: []string{"text/css", "text/css"}},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCompression(test, field.NewPath("compression"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateCompression(%+v) returned no errors for invalid input", test)
+		}
+	}
+}
+
+func TestValidateConnectionLimit(t *testing.T) {
+	tests := []*v1.ConnectionLimit{
+		nil,
+		{Key: "${binary_remote_addr}", Connections: 10},
+		{Key: "${remote_addr}${uri}", Connections: 1000, ZoneSize: "20m", RejectCode: createPointerFromInt(429)},
+		{Key: "${http_x_user}${arg_id}", Connections: 1},
+		{Key: "${binary_remote_addr}", ConnectionsPerEndpoint: 10, Upstream: "tea"},
+	}
+
+	for _, test := range tests {
+		allErrs := validateConnectionLimit(test, field.NewPath("connectionLimit"), sets.NewString("tea"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateConnectionLimit(%+v) returned errors for valid input: %v", test, allErrs)
+		}
+	}
+}
+
+func TestValidateConnectionLimitFails(t *testing.T) {
+	tests := []*v1.ConnectionLimit{
+		{},
+		{Key: "${binary_remote_addr}"},
+		{Key: "${binary_remote_addr}", Connections: -1},
+		{Connections: 10},
+		{Key: "${unknown}", Connections: 10},
+		{Key: "${binary_remote_addr};", Connections: 10},
+		{Key: "${binary_remote_addr} ${uri}", Connections: 10},
+		{Key: "${binary_remote_addr}", Connections: 10, ZoneSize: "10g"},
+		{Key: "${binary_remote_addr}", Connections: 10, RejectCode: createPointerFromInt(200)},
+		{Key: "${binary_remote_addr}", ConnectionsPerEndpoint: 10},
+		{Key: "${binary_remote_addr}", ConnectionsPerEndpoint: 10, Upstream: "coffee"},
+		{Key: "${binary_remote_addr}", ConnectionsPerEndpoint: -1, Upstream: "tea"},
+		{Key: "${binary_remote_addr}", Upstream: "tea"},
+		{Key: "${binary_remote_addr}", Connections: 10, ConnectionsPerEndpoint: 10, Upstream: "tea"},
+	}
+
+	for _, test := range tests {
+		allErrs := validateConnectionLimit(test, field.NewPath("connectionLimit"), sets.NewString("tea"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateConnectionLimit(%+v) returned no errors for invalid input", test)
+		}
+	}
+}
+
+func TestValidateErrorBackend(t *testing.T) {
+	tests := []*v1.ErrorBackend{
+		nil,
+		{Upstream: "errors", Codes: []int{404}},
+		{Upstream: "errors", Codes: []int{400, 404, 500, 502, 503, 504, 599}},
+	}
+
+	for _, test := range tests {
+		allErrs := validateErrorBackend(test, field.NewPath("errorBackend"), sets.NewString("errors"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateErrorBackend(%+v) returned errors for valid input: %v", test, allErrs)
+		}
+	}
+}
+
+func TestValidateErrorBackendFails(t *testing.T) {
+	tests := []*v1.ErrorBackend{
+		{},
+		{Codes: []int{404}},
+		{Upstream: "unknown", Codes: []int{404}},
+		{Upstream: "errors"},
+		{Upstream: "errors", Codes: []int{302}},
+		{Upstream: "errors", Codes: []int{600}},
+		{Upstream: "errors", Codes: []int{404, 404}},
+	}
+
+	for _, test := range tests {
+		allErrs := validateErrorBackend(test, field.NewPath("errorBackend"), sets.NewString("errors"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateErrorBackend(%+v) returned no errors for invalid input", test)
+		}
+	}
+}
+
+func TestValidateStickySplits(t *testing.T) {
+	splits := []v1.Split{
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:                     "@upstream1",
-					Service:                  "test-1",
-					Port:                     80,
-					ProxyNextUpstream:        "http_502",
-					ProxyNextUpstreamTimeout: "10s",
-					ProxyNextUpstreamTries:   5,
-				},
+			Weight: 90,
+			Action: &v1.Action{
+				Pass: "test-1",
 			},
-			expectedUpstreamNames: sets.String{},
-			msg:                   "invalid upstream name",
 		},
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:                     "upstream1",
-					Service:                  "@test-1",
-					Port:                     80,
-					ProxyNextUpstream:        "error timeout",
-					ProxyNextUpstreamTimeout: "10s",
-					ProxyNextUpstreamTries:   5,
-				},
-			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
+			Weight: 10,
+			Action: &v1.Action{
+				Pass: "test-2",
 			},
-			msg: "invalid service",
 		},
+	}
+
+	tests := []struct {
+		route v1.Route
+		msg   string
+	}{
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:                     "upstream1",
-					Service:                  "test-1",
-					Port:                     0,
-					ProxyNextUpstream:        "error timeout",
-					ProxyNextUpstreamTimeout: "10s",
-					ProxyNextUpstreamTries:   5,
-				},
-			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
+			route: v1.Route{
+				Path:   "/",
+				Splits: splits,
 			},
-			msg: "invalid port",
+			msg: "no sticky splits",
 		},
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:                     "upstream1",
-					Service:                  "test-1",
-					Port:                     80,
-					ProxyNextUpstream:        "error timeout",
-					ProxyNextUpstreamTimeout: "10s",
-					ProxyNextUpstreamTries:   5,
-				},
-				{
-					Name:                     "upstream1",
-					Service:                  "test-2",
-					Port:                     80,
-					ProxyNextUpstream:        "error timeout",
-					ProxyNextUpstreamTimeout: "10s",
-					ProxyNextUpstreamTries:   5,
-				},
-			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
+			route: v1.Route{
+				Path:         "/",
+				Splits:       splits,
+				StickySplits: &v1.StickySplits{Enable: true},
 			},
-			msg: "duplicated upstreams",
+			msg: "sticky splits with the default cookie",
 		},
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:                     "upstream1",
-					Service:                  "test-1",
-					Port:                     80,
-					ProxyNextUpstream:        "https_504",
-					ProxyNextUpstreamTimeout: "10s",
-					ProxyNextUpstreamTries:   5,
-				},
-			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
+			route: v1.Route{
+				Path:         "/",
+				Splits:       splits,
+				StickySplits: &v1.StickySplits{Enable: true, CookieName: "canary_id"},
 			},
-			msg: "invalid next upstream syntax",
+			msg: "sticky splits with a custom cookie",
 		},
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:                     "upstream1",
-					Service:                  "test-1",
-					Port:                     80,
-					ProxyNextUpstream:        "http_504",
-					ProxyNextUpstreamTimeout: "-2s",
-					ProxyNextUpstreamTries:   5,
+			route: v1.Route{
+				Path: "/",
+				Matches: []v1.Match{
+					{
+						Splits: splits,
+					},
 				},
+				StickySplits: &v1.StickySplits{Enable: true},
 			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
-			},
-			msg: "invalid upstream timeout value",
+			msg: "sticky splits of matches",
 		},
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:                     "upstream1",
-					Service:                  "test-1",
-					Port:                     80,
-					ProxyNextUpstream:        "https_504",
-					ProxyNextUpstreamTimeout: "10s",
-					ProxyNextUpstreamTries:   -1,
-				},
-			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
+			route: v1.Route{
+				Path:         "/",
+				Action:       &v1.Action{Pass: "test-1"},
+				StickySplits: &v1.StickySplits{Enable: false},
 			},
-			msg: "invalid upstream tries value",
+			msg: "disabled sticky splits without splits",
 		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateStickySplits(test.route, field.NewPath("stickySplits"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateStickySplits() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateStickySplitsFails(t *testing.T) {
+	tests := []struct {
+		route v1.Route
+		msg   string
+	}{
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:     "upstream1",
-					Service:  "test-1",
-					Port:     80,
-					MaxConns: createPointerFromInt(-1),
-				},
-			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
+			route: v1.Route{
+				Path:         "/",
+				Action:       &v1.Action{Pass: "test-1"},
+				StickySplits: &v1.StickySplits{Enable: true},
 			},
-			msg: "negative value for MaxConns",
+			msg: "sticky splits without splits",
 		},
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:              "upstream1",
-					Service:           "test-1",
-					Port:              80,
-					ClientMaxBodySize: "7mins",
+			route: v1.Route{
+				Path: "/",
+				Splits: []v1.Split{
+					{
+						Weight: 100,
+						Action: &v1.Action{Pass: "test-1"},
+					},
 				},
+				StickySplits: &v1.StickySplits{Enable: true, CookieName: "canary-id"},
 			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
-			},
-			msg: "invalid value for ClientMaxBodySize",
+			msg: "invalid cookie name",
 		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateStickySplits(test.route, field.NewPath("stickySplits"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateStickySplits() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateCutover(t *testing.T) {
+	splits := []v1.Split{
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:    "upstream1",
-					Service: "test-1",
-					Port:    80,
-					ProxyBuffers: &v1.UpstreamBuffers{
-						Number: -1,
-						Size:   "1G",
-					},
-				},
-			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
+			Weight: 90,
+			Action: &v1.Action{
+				Pass: "test-1",
 			},
-			msg: "invalid value for ProxyBuffers",
 		},
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:            "upstream1",
-					Service:         "test-1",
-					Port:            80,
-					ProxyBufferSize: "1G",
-				},
-			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
+			Weight: 10,
+			Action: &v1.Action{
+				Pass: "test-2",
 			},
-			msg: "invalid value for ProxyBufferSize",
 		},
+	}
+
+	tests := []struct {
+		route v1.Route
+		msg   string
+	}{
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:        "upstream1",
-					Service:     "test-1",
-					Subselector: map[string]string{"\\$invalidkey": "test"},
-					Port:        80,
-				},
-			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
+			route: v1.Route{
+				Path:   "/",
+				Splits: splits,
 			},
-			msg: "invalid key for subselector",
+			msg: "no cutover",
 		},
 		{
-			upstreams: []v1.Upstream{
-				{
-					Name:        "upstream1",
-					Service:     "test-1",
-					Subselector: map[string]string{"version": "test=fail"},
-					Port:        80,
-				},
+			route: v1.Route{
+				Path:    "/",
+				Splits:  splits,
+				Cutover: "promote",
 			},
-			expectedUpstreamNames: map[string]sets.Empty{
-				"upstream1": {},
+			msg: "promote",
+		},
+		{
+			route: v1.Route{
+				Path:    "/",
+				Splits:  splits,
+				Cutover: "rollback",
 			},
-			msg: "invalid value for subselector",
+			msg: "rollback",
 		},
 	}
 
-	isPlus := false
 	for _, test := range tests {
-		allErrs, resultUpstreamNames := validateUpstreams(test.upstreams, field.NewPath("upstreams"), isPlus)
-		if len(allErrs) == 0 {
-			t.Errorf("validateUpstreams() returned no errors for the case of %s", test.msg)
-		}
-		if !resultUpstreamNames.Equal(test.expectedUpstreamNames) {
-			t.Errorf("validateUpstreams() returned %v expected %v for the case of %s", resultUpstreamNames, test.expectedUpstreamNames, test.msg)
+		allErrs := validateCutover(test.route, field.NewPath("cutover"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateCutover() returned errors %v for valid input for the case of %s", allErrs, test.msg)
 		}
 	}
 }
 
-func TestValidateNextUpstream(t *testing.T) {
+func TestValidateCutoverFails(t *testing.T) {
 	tests := []struct {
-		inputS string
+		route v1.Route
+		msg   string
 	}{
 		{
-			inputS: "error timeout",
+			route: v1.Route{
+				Path: "/",
+				Splits: []v1.Split{
+					{
+						Weight: 90,
+						Action: &v1.Action{Pass: "test-1"},
+					},
+					{
+						Weight: 10,
+						Action: &v1.Action{Pass: "test-2"},
+					},
+				},
+				Cutover: "switch",
+			},
+			msg: "invalid cutover",
 		},
 		{
-			inputS: "http_404 timeout",
+			route: v1.Route{
+				Path:    "/",
+				Action:  &v1.Action{Pass: "test-1"},
+				Cutover: "promote",
+			},
+			msg: "cutover without splits",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Splits: []v1.Split{
+					{
+						Weight: 80,
+						Action: &v1.Action{Pass: "test-1"},
+					},
+					{
+						Weight: 10,
+						Action: &v1.Action{Pass: "test-2"},
+					},
+					{
+						Weight: 10,
+						Action: &v1.Action{Pass: "test-3"},
+					},
+				},
+				Cutover: "rollback",
+			},
+			msg: "cutover with 3 splits",
 		},
 	}
+
 	for _, test := range tests {
-		allErrs := validateNextUpstream(test.inputS, field.NewPath("next-upstreams"))
-		if len(allErrs) > 0 {
-			t.Errorf("validateNextUpstream(%q) returned errors %v for valid input.", test.inputS, allErrs)
+		allErrs := validateCutover(test.route, field.NewPath("cutover"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateCutover() returned no errors for invalid input for the case of %s", test.msg)
 		}
 	}
 }
 
-func TestValidateNextUpstreamFails(t *testing.T) {
-	tests := []struct {
-		inputS string
-	}{
+func TestValidateMaps(t *testing.T) {
+	maps := []v1.Map{
 		{
-			inputS: "error error",
+			Name:   "region",
+			Source: "$http_x_country",
+			Mappings: []v1.MapMapping{
+				{
+					Value:  "US",
+					Result: "us",
+				},
+				{
+					Value:  "default",
+					Result: "special",
+				},
+			},
+			Default: "eu",
 		},
 		{
-			inputS: "https_404",
+			Name:   "tenant",
+			Source: "$host",
+			Mappings: []v1.MapMapping{
+				{
+					Value:  "*.example.com",
+					Result: "example",
+				},
+				{
+					Value:  "~^(www\\.)?cafe\\.",
+					Result: "cafe$1",
+				},
+			},
 		},
 	}
-	for _, test := range tests {
-		allErrs := validateNextUpstream(test.inputS, field.NewPath("next-upstreams"))
-		if len(allErrs) == 0 {
-			t.Errorf("validateNextUpstream(%q) didn't return errors %v for invalid input.", test.inputS, allErrs)
-		}
-	}
-}
-
-func TestValidateDNS1035Label(t *testing.T) {
-	validNames := []string{
-		"test",
-		"test-123",
-	}
+	expectedMapNames := sets.NewString("region", "tenant")
 
-	for _, name := range validNames {
-		allErrs := validateDNS1035Label(name, field.NewPath("name"))
-		if len(allErrs) > 0 {
-			t.Errorf("validateDNS1035Label(%q) returned errors %v for valid input", name, allErrs)
-		}
+	allErrs, resultMapNames := validateMaps(maps, field.NewPath("maps"))
+	if len(allErrs) > 0 {
+		t.Errorf("validateMaps() returned errors %v for valid input", allErrs)
 	}
-
-	invalidNames := []string{
-		"",
-		"123",
-		"test.123",
+	if !resultMapNames.Equal(expectedMapNames) {
+		t.Errorf("validateMaps() returned %v expected %v", resultMapNames, expectedMapNames)
 	}
+}
 
-	for _, name := range invalidNames {
-		allErrs := validateDNS1035Label(name, field.NewPath("name"))
-		if len(allErrs) == 0 {
-			t.Errorf("validateDNS1035Label(%q) returned no errors for invalid input", name)
+func TestValidateMapsFails(t *testing.T) {
+	createMap := func(name string, source string, mappings []v1.MapMapping, defaultResult string) v1.Map {
+		return v1.Map{
+			Name:     name,
+			Source:   source,
+			Mappings: mappings,
+			Default:  defaultResult,
 		}
 	}
-}
+	validMappings := []v1.MapMapping{
+		{
+			Value:  "US",
+			Result: "us",
+		},
+	}
 
-func TestValidateVirtualServerRoutes(t *testing.T) {
 	tests := []struct {
-		routes        []v1.Route
-		upstreamNames sets.String
-		msg           string
+		maps []v1.Map
+		msg  string
 	}{
 		{
-			routes:        []v1.Route{},
-			upstreamNames: sets.String{},
-			msg:           "no routes",
+			maps: []v1.Map{createMap("", "$host", validMappings, "")},
+			msg:  "missing name",
 		},
 		{
-			routes: []v1.Route{
-				{
-					Path: "/",
-					Action: &v1.Action{
-						Pass: "test",
+			maps: []v1.Map{createMap("my-map", "$host", validMappings, "")},
+			msg:  "invalid name",
+		},
+		{
+			maps: []v1.Map{createMap("request_method", "$host", validMappings, "")},
+			msg:  "name of an NGINX variable",
+		},
+		{
+			maps: []v1.Map{
+				createMap("region", "$host", validMappings, ""),
+				createMap("region", "$uri", validMappings, ""),
+			},
+			msg: "duplicated name",
+		},
+		{
+			maps: []v1.Map{createMap("region", "", validMappings, "")},
+			msg:  "missing source",
+		},
+		{
+			maps: []v1.Map{createMap("region", "host", validMappings, "")},
+			msg:  "source without $",
+		},
+		{
+			maps: []v1.Map{createMap("region", "$request_id", validMappings, "")},
+			msg:  "source variable not allowed",
+		},
+		{
+			maps: []v1.Map{createMap("region", "$http_x-country", validMappings, "")},
+			msg:  "invalid header in the source",
+		},
+		{
+			maps: []v1.Map{createMap("region", "$host", nil, "")},
+			msg:  "missing mappings",
+		},
+		{
+			maps: []v1.Map{
+				createMap("region", "$host", []v1.MapMapping{
+					{
+						Value:  "US",
+						Result: "us",
 					},
-				},
+					{
+						Value:  "US",
+						Result: "usa",
+					},
+				}, ""),
 			},
-			upstreamNames: map[string]sets.Empty{
-				"test": {},
+			msg: "duplicated mapping values",
+		},
+		{
+			maps: []v1.Map{
+				createMap("region", "$host", []v1.MapMapping{
+					{
+						Value:  `"US`,
+						Result: "us",
+					},
+				}, ""),
 			},
-			msg: "valid route",
+			msg: "unescaped double quote in a value",
+		},
+		{
+			maps: []v1.Map{
+				createMap("region", "$host", []v1.MapMapping{
+					{
+						Value:  "US",
+						Result: "${request_uri}",
+					},
+				}, ""),
+			},
+			msg: "variable in a result",
+		},
+		{
+			maps: []v1.Map{createMap("region", "$host", validMappings, `eu\`)},
+			msg:  "unescaped backslash in the default",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateVirtualServerRoutes(test.routes, field.NewPath("routes"), test.upstreamNames)
-		if len(allErrs) > 0 {
-			t.Errorf("validateVirtualServerRoutes() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		allErrs, _ := validateMaps(test.maps, field.NewPath("maps"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateMaps() returned no errors for the case of %s", test.msg)
 		}
 	}
 }
 
-func TestValidateVirtualServerRoutesFails(t *testing.T) {
-	tests := []struct {
-		routes        []v1.Route
-		upstreamNames sets.String
-		msg           string
-	}{
-		{
-			routes: []v1.Route{
-				{
-					Path: "/test",
-					Action: &v1.Action{
-						Pass: "test-1",
-					},
-				},
-				{
-					Path: "/test",
-					Action: &v1.Action{
-						Pass: "test-2",
+func TestValidateUpstreamMaps(t *testing.T) {
+	spec := &v1.VirtualServerSpec{
+		Maps: []v1.Map{
+			{
+				Name:   "backend",
+				Source: "$http_x_backend",
+				Mappings: []v1.MapMapping{
+					{
+						Value:  "v2",
+						Result: "tea-v2",
 					},
 				},
+				Default: "tea-v1",
 			},
-			upstreamNames: map[string]sets.Empty{
-				"test-1": {},
-				"test-2": {},
+			{
+				Name:    "region",
+				Source:  "$http_x_country",
+				Default: "eu",
 			},
-			msg: "duplicated paths",
 		},
-
-		{
-			routes: []v1.Route{
-				{
-					Path:   "",
-					Action: nil,
+		Upstreams: []v1.Upstream{
+			{
+				Name: "tea-v1",
+			},
+			{
+				Name: "tea-v2",
+			},
+		},
+		Routes: []v1.Route{
+			{
+				Path: "/tea",
+				Action: &v1.Action{
+					Pass: "$backend",
 				},
 			},
-			upstreamNames: map[string]sets.Empty{},
-			msg:           "invalid route",
 		},
 	}
+	upstreamNames := sets.NewString("tea-v1", "tea-v2")
+	expectedUpstreamVariables := sets.NewString("$backend")
 
-	for _, test := range tests {
-		allErrs := validateVirtualServerRoutes(test.routes, field.NewPath("routes"), test.upstreamNames)
-		if len(allErrs) == 0 {
-			t.Errorf("validateVirtualServerRoutes() returned no errors for the case of %s", test.msg)
-		}
+	allErrs, resultUpstreamVariables := validateUpstreamMaps(spec, field.NewPath("maps"), upstreamNames)
+	if len(allErrs) > 0 {
+		t.Errorf("validateUpstreamMaps() returned errors %v for valid input", allErrs)
+	}
+	if !resultUpstreamVariables.Equal(expectedUpstreamVariables) {
+		t.Errorf("validateUpstreamMaps() returned %v expected %v", resultUpstreamVariables, expectedUpstreamVariables)
 	}
 }
 
-func TestValidateRoute(t *testing.T) {
-	tests := []struct {
-		route                 v1.Route
-		upstreamNames         sets.String
-		isRouteFieldForbidden bool
-		msg                   string
-	}{
-		{
-			route: v1.Route{
-
-				Path: "/",
-				Action: &v1.Action{
-					Pass: "test",
+func TestValidateUpstreamMapsFails(t *testing.T) {
+	createSpec := func(mappings []v1.MapMapping, defaultResult string, tlsV2 bool) *v1.VirtualServerSpec {
+		return &v1.VirtualServerSpec{
+			Maps: []v1.Map{
+				{
+					Name:     "backend",
+					Source:   "$http_x_backend",
+					Mappings: mappings,
+					Default:  defaultResult,
 				},
 			},
-			upstreamNames: map[string]sets.Empty{
-				"test": {},
-			},
-			isRouteFieldForbidden: false,
-			msg:                   "valid route with upstream",
-		},
-		{
-			route: v1.Route{
-				Path: "/",
-				Splits: []v1.Split{
-					{
-						Weight: 90,
-						Action: &v1.Action{
-							Pass: "test-1",
-						},
-					},
-					{
-						Weight: 10,
-						Action: &v1.Action{
-							Pass: "test-2",
-						},
-					},
+			Upstreams: []v1.Upstream{
+				{
+					Name: "tea-v1",
+				},
+				{
+					Name: "tea-v2",
+					TLS:  v1.UpstreamTLS{Enable: tlsV2},
 				},
 			},
-			upstreamNames: map[string]sets.Empty{
-				"test-1": {},
-				"test-2": {},
-			},
-			isRouteFieldForbidden: false,
-			msg:                   "valid upstream with splits",
-		},
-		{
-			route: v1.Route{
-				Path: "/",
-				Matches: []v1.Match{
-					{
-						Conditions: []v1.Condition{
-							{
-								Header: "x-version",
-								Value:  "test-1",
+			Routes: []v1.Route{
+				{
+					Path: "/tea",
+					Splits: []v1.Split{
+						{
+							Weight: 90,
+							Action: &v1.Action{
+								Proxy: &v1.ActionProxy{
+									Upstream: "$backend",
+								},
 							},
 						},
-						Action: &v1.Action{
-							Pass: "test-1",
+						{
+							Weight: 10,
+							Action: &v1.Action{
+								Pass: "tea-v1",
+							},
 						},
 					},
 				},
-				Action: &v1.Action{
-					Pass: "test-2",
-				},
 			},
-			upstreamNames: map[string]sets.Empty{
-				"test-1": {},
-				"test-2": {},
-			},
-			isRouteFieldForbidden: false,
-			msg:                   "valid action with matches",
+		}
+	}
+	upstreamNames := sets.NewString("tea-v1", "tea-v2")
+
+	specWithDifferentTLSProtocols := createSpec([]v1.MapMapping{{Value: "v2", Result: "tea-v2"}}, "tea-v1", true)
+	specWithDifferentTLSProtocols.Upstreams[0].TLS = v1.UpstreamTLS{Enable: true, Protocols: []string{"TLSv1.2"}}
+
+	tests := []struct {
+		spec *v1.VirtualServerSpec
+		msg  string
+	}{
+		{
+			spec: specWithDifferentTLSProtocols,
+			msg:  "upstreams with different TLS protocols",
 		},
 		{
-			route: v1.Route{
-
-				Path:  "/",
-				Route: "default/test",
-			},
-			upstreamNames:         map[string]sets.Empty{},
-			isRouteFieldForbidden: false,
-			msg:                   "valid route with route",
+			spec: createSpec([]v1.MapMapping{{Value: "v2", Result: "coffee"}}, "tea-v1", false),
+			msg:  "undeclared upstream in mappings",
+		},
+		{
+			spec: createSpec([]v1.MapMapping{{Value: "v2", Result: "tea-v2"}}, "coffee", false),
+			msg:  "undeclared upstream in default",
+		},
+		{
+			spec: createSpec([]v1.MapMapping{{Value: "v2", Result: "tea-v2"}}, "", false),
+			msg:  "empty default",
+		},
+		{
+			spec: createSpec([]v1.MapMapping{{Value: "v2", Result: "tea-v2"}}, "tea-v1", true),
+			msg:  "upstreams with different TLS configuration",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateRoute(test.route, field.NewPath("route"), test.upstreamNames, test.isRouteFieldForbidden)
+		allErrs, _ := validateUpstreamMaps(test.spec, field.NewPath("maps"), upstreamNames)
+		if len(allErrs) == 0 {
+			t.Errorf("validateUpstreamMaps() returned no errors for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateActionUpstream(t *testing.T) {
+	upstreamNames := sets.NewString("tea", "$backend")
+
+	for _, name := range []string{"tea", "$backend"} {
+		allErrs := validateActionUpstream(name, field.NewPath("pass"), upstreamNames)
 		if len(allErrs) > 0 {
-			t.Errorf("validateRoute() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+			t.Errorf("validateActionUpstream(%q) returned errors %v for valid input", name, allErrs)
+		}
+	}
+
+	for _, name := range []string{"coffee", "$region", "$", "-tea"} {
+		allErrs := validateActionUpstream(name, field.NewPath("pass"), upstreamNames)
+		if len(allErrs) == 0 {
+			t.Errorf("validateActionUpstream(%q) returned no errors for invalid input", name)
 		}
 	}
 }
 
-func TestValidateRouteFails(t *testing.T) {
+func TestValidateUpstreams(t *testing.T) {
 	tests := []struct {
-		route                 v1.Route
-		upstreamNames         sets.String
-		isRouteFieldForbidden bool
+		upstreams             []v1.Upstream
+		expectedUpstreamNames sets.String
 		msg                   string
 	}{
 		{
-			route: v1.Route{
-				Path: "",
-				Action: &v1.Action{
-					Pass: "test",
-				},
-			},
-			upstreamNames: map[string]sets.Empty{
-				"test": {},
-			},
-			isRouteFieldForbidden: false,
-			msg:                   "empty path",
+			upstreams:             []v1.Upstream{},
+			expectedUpstreamNames: sets.String{},
+			msg:                   "no upstreams",
 		},
 		{
-			route: v1.Route{
-				Path: "/test",
-				Action: &v1.Action{
-					Pass: "-test",
+			upstreams: []v1.Upstream{
+				{
+					Name:                     "upstream1",
+					Service:                  "test-1",
+					Port:                     80,
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "10s",
+					ProxyNextUpstreamTries:   5,
+					MaxConns:                 createPointerFromInt(16),
+					ConnectionLimitPolicy:    "shared-backend",
+				},
+				{
+					Name:                     "upstream2",
+					Subselector:              map[string]string{"version": "test"},
+					Service:                  "test-2",
+					Port:                     80,
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "10s",
+					ProxyNextUpstreamTries:   5,
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+				"upstream2": {},
+			},
+			msg: "2 valid upstreams",
+		},
+	}
+	isPlus := false
+	for _, test := range tests {
+		allErrs, resultUpstreamNames := validateUpstreams(test.upstreams, field.NewPath("upstreams"), isPlus)
+		if len(allErrs) > 0 {
+			t.Errorf("validateUpstreams() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
+		if !resultUpstreamNames.Equal(test.expectedUpstreamNames) {
+			t.Errorf("validateUpstreams() returned %v expected %v for the case of %s", resultUpstreamNames, test.expectedUpstreamNames, test.msg)
+		}
+	}
+}
+
+func TestValidateUpstreamsFails(t *testing.T) {
+	tests := []struct {
+		upstreams             []v1.Upstream
+		expectedUpstreamNames sets.String
+		msg                   string
+	}{
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:                     "@upstream1",
+					Service:                  "test-1",
+					Port:                     80,
+					ProxyNextUpstream:        "http_502",
+					ProxyNextUpstreamTimeout: "10s",
+					ProxyNextUpstreamTries:   5,
+				},
+			},
+			expectedUpstreamNames: sets.String{},
+			msg:                   "invalid upstream name",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:                     "upstream1",
+					Service:                  "@test-1",
+					Port:                     80,
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "10s",
+					ProxyNextUpstreamTries:   5,
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid service",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:                     "upstream1",
+					Service:                  "test-1",
+					Port:                     0,
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "10s",
+					ProxyNextUpstreamTries:   5,
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid port",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:                  "upstream1",
+					Service:               "test-1",
+					Port:                  80,
+					ConnectionLimitPolicy: "Shared_Backend",
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid connection limit policy",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:                     "upstream1",
+					Service:                  "test-1",
+					Port:                     80,
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "10s",
+					ProxyNextUpstreamTries:   5,
+				},
+				{
+					Name:                     "upstream1",
+					Service:                  "test-2",
+					Port:                     80,
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "10s",
+					ProxyNextUpstreamTries:   5,
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "duplicated upstreams",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:                     "upstream1",
+					Service:                  "test-1",
+					Port:                     80,
+					ProxyNextUpstream:        "https_504",
+					ProxyNextUpstreamTimeout: "10s",
+					ProxyNextUpstreamTries:   5,
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid next upstream syntax",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:                     "upstream1",
+					Service:                  "test-1",
+					Port:                     80,
+					ProxyNextUpstream:        "http_504",
+					ProxyNextUpstreamTimeout: "-2s",
+					ProxyNextUpstreamTries:   5,
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid upstream timeout value",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:                     "upstream1",
+					Service:                  "test-1",
+					Port:                     80,
+					ProxyNextUpstream:        "https_504",
+					ProxyNextUpstreamTimeout: "10s",
+					ProxyNextUpstreamTries:   -1,
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid upstream tries value",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:     "upstream1",
+					Service:  "test-1",
+					Port:     80,
+					MaxConns: createPointerFromInt(-1),
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "negative value for MaxConns",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:     "upstream1",
+					Service:  "test-1",
+					Port:     80,
+					MaxFails: createPointerFromInt(-1),
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "negative value for MaxFails",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:        "upstream1",
+					Service:     "test-1",
+					Port:        80,
+					FailTimeout: "10ms10",
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid value for FailTimeout",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:              "upstream1",
+					Service:           "test-1",
+					Port:              80,
+					ClientMaxBodySize: "7mins",
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid value for ClientMaxBodySize",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:    "upstream1",
+					Service: "test-1",
+					Port:    80,
+					ProxyBuffers: &v1.UpstreamBuffers{
+						Number: -1,
+						Size:   "1G",
+					},
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid value for ProxyBuffers",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:            "upstream1",
+					Service:         "test-1",
+					Port:            80,
+					ProxyBufferSize: "1G",
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid value for ProxyBufferSize",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:        "upstream1",
+					Service:     "test-1",
+					Subselector: map[string]string{"\\$invalidkey": "test"},
+					Port:        80,
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid key for subselector",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:        "upstream1",
+					Service:     "test-1",
+					Subselector: map[string]string{"version": "test=fail"},
+					Port:        80,
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "invalid value for subselector",
+		},
+	}
+
+	isPlus := false
+	for _, test := range tests {
+		allErrs, resultUpstreamNames := validateUpstreams(test.upstreams, field.NewPath("upstreams"), isPlus)
+		if len(allErrs) == 0 {
+			t.Errorf("validateUpstreams() returned no errors for the case of %s", test.msg)
+		}
+		if !resultUpstreamNames.Equal(test.expectedUpstreamNames) {
+			t.Errorf("validateUpstreams() returned %v expected %v for the case of %s", resultUpstreamNames, test.expectedUpstreamNames, test.msg)
+		}
+	}
+}
+
+func TestValidateNextUpstream(t *testing.T) {
+	tests := []struct {
+		inputS string
+	}{
+		{
+			inputS: "error timeout",
+		},
+		{
+			inputS: "http_404 timeout",
+		},
+	}
+	for _, test := range tests {
+		allErrs := validateNextUpstream(test.inputS, field.NewPath("next-upstreams"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateNextUpstream(%q) returned errors %v for valid input.", test.inputS, allErrs)
+		}
+	}
+}
+
+func TestValidateNextUpstreamFails(t *testing.T) {
+	tests := []struct {
+		inputS string
+	}{
+		{
+			inputS: "error error",
+		},
+		{
+			inputS: "https_404",
+		},
+	}
+	for _, test := range tests {
+		allErrs := validateNextUpstream(test.inputS, field.NewPath("next-upstreams"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateNextUpstream(%q) didn't return errors %v for invalid input.", test.inputS, allErrs)
+		}
+	}
+}
+
+func TestValidateAllowedMethods(t *testing.T) {
+	tests := [][]string{
+		nil,
+		{"GET"},
+		{"GET", "HEAD", "OPTIONS"},
+		{"POST", "PUT", "PATCH", "DELETE"},
+	}
+	for _, test := range tests {
+		allErrs := validateAllowedMethods(test, field.NewPath("allowedMethods"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateAllowedMethods(%v) returned errors %v for valid input.", test, allErrs)
+		}
+	}
+}
+
+func TestValidateAllowedMethodsFails(t *testing.T) {
+	tests := [][]string{
+		{""},
+		{"get"},
+		{"PURGE"},
+		{"GET", "GET"},
+	}
+	for _, test := range tests {
+		allErrs := validateAllowedMethods(test, field.NewPath("allowedMethods"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateAllowedMethods(%v) returned no errors for invalid input", test)
+		}
+	}
+}
+
+func TestValidateSubFilter(t *testing.T) {
+	tests := []*v1.SubFilter{
+		nil,
+		{
+			Substitutions: []v1.Substitution{
+				{
+					From: "http://backend.local",
+					To:   "https://example.com",
+				},
+			},
+		},
+		{
+			Substitutions: []v1.Substitution{
+				{
+					From: `\"title\"`,
+					To:   "",
+				},
+				{
+					From: "http://backend.local",
+					To:   "https://example.com",
+				},
+			},
+			Replace: "all",
+			Types:   []string{"text/css", "application/javascript"},
+		},
+	}
+	for _, test := range tests {
+		allErrs := validateSubFilter(test, field.NewPath("subFilter"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateSubFilter(%+v) returned errors %v for valid input", test, allErrs)
+		}
+	}
+}
+
+func TestValidateSubFilterFails(t *testing.T) {
+	tests := []struct {
+		subFilter *v1.SubFilter
+		msg       string
+	}{
+		{
+			subFilter: &v1.SubFilter{},
+			msg:       "no substitutions",
+		},
+		{
+			subFilter: &v1.SubFilter{
+				Substitutions: []v1.Substitution{
+					{
+						From: "",
+						To:   "https://example.com",
+					},
+				},
+			},
+			msg: "empty from",
+		},
+		{
+			subFilter: &v1.SubFilter{
+				Substitutions: []v1.Substitution{
+					{
+						From: "http://backend.local",
+						To:   "https://example.com",
+					},
+					{
+						From: "HTTP://backend.local",
+						To:   "https://example.org",
+					},
+				},
+			},
+			msg: "duplicate from",
+		},
+		{
+			subFilter: &v1.SubFilter{
+				Substitutions: []v1.Substitution{
+					{
+						From: "$host",
+						To:   "example.com",
+					},
+				},
+			},
+			msg: "variable in from",
+		},
+		{
+			subFilter: &v1.SubFilter{
+				Substitutions: []v1.Substitution{
+					{
+						From: "backend",
+						To:   `"example"`,
+					},
+				},
+			},
+			msg: "unescaped quotes in to",
+		},
+		{
+			subFilter: &v1.SubFilter{
+				Substitutions: []v1.Substitution{
+					{
+						From: "backend",
+						To:   "example",
+					},
+				},
+				Replace: "first",
+			},
+			msg: "invalid replace",
+		},
+		{
+			subFilter: &v1.SubFilter{
+				Substitutions: []v1.Substitution{
+					{
+						From: "backend",
+						To:   "example",
+					},
+				},
+				Types: []string{"text/css", "text/css"},
+			},
+			msg: "duplicate types",
+		},
+		{
+			subFilter: &v1.SubFilter{
+				Substitutions: []v1.Substitution{
+					{
+						From: "backend",
+						To:   "example",
+					},
+				},
+				Types: []string{"text/html"},
+			},
+			msg: "text/html type",
+		},
+		{
+			subFilter: &v1.SubFilter{
+				Substitutions: []v1.Substitution{
+					{
+						From: "backend",
+						To:   "example",
+					},
+				},
+				Types: []string{"css"},
+			},
+			msg: "invalid type",
+		},
+	}
+	for _, test := range tests {
+		allErrs := validateSubFilter(test.subFilter, field.NewPath("subFilter"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateSubFilter() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateDNS1035Label(t *testing.T) {
+	validNames := []string{
+		"test",
+		"test-123",
+	}
+
+	for _, name := range validNames {
+		allErrs := validateDNS1035Label(name, field.NewPath("name"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateDNS1035Label(%q) returned errors %v for valid input", name, allErrs)
+		}
+	}
+
+	invalidNames := []string{
+		"",
+		"123",
+		"test.123",
+	}
+
+	for _, name := range invalidNames {
+		allErrs := validateDNS1035Label(name, field.NewPath("name"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateDNS1035Label(%q) returned no errors for invalid input", name)
+		}
+	}
+}
+
+func TestValidateVirtualServerRoutes(t *testing.T) {
+	tests := []struct {
+		routes        []v1.Route
+		upstreamNames sets.String
+		msg           string
+	}{
+		{
+			routes:        []v1.Route{},
+			upstreamNames: sets.String{},
+			msg:           "no routes",
+		},
+		{
+			routes: []v1.Route{
+				{
+					Path: "/",
+					Action: &v1.Action{
+						Pass: "test",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			msg: "valid route",
+		},
+		{
+			routes: []v1.Route{
+				{
+					Path: "/coffee",
+					Splits: []v1.Split{
+						{
+							Weight: 90,
+							Route:  "coffee-v1",
+						},
+						{
+							Weight: 10,
+							Route:  "default/coffee-v2",
+						},
+					},
+				},
+				{
+					Path:  "/tea",
+					Route: "tea",
+				},
+			},
+			upstreamNames: sets.String{},
+			msg:           "route splits",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateVirtualServerRoutes(test.routes, field.NewPath("routes"), test.upstreamNames, sets.String{})
+		if len(allErrs) > 0 {
+			t.Errorf("validateVirtualServerRoutes() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateVirtualServerRoutesFails(t *testing.T) {
+	tests := []struct {
+		routes        []v1.Route
+		upstreamNames sets.String
+		msg           string
+	}{
+		{
+			routes: []v1.Route{
+				{
+					Path: "/test",
+					Action: &v1.Action{
+						Pass: "test-1",
+					},
+				},
+				{
+					Path: "/test",
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			msg: "duplicated paths",
+		},
+
+		{
+			routes: []v1.Route{
+				{
+					Path:   "",
+					Action: nil,
+				},
+			},
+			upstreamNames: map[string]sets.Empty{},
+			msg:           "invalid route",
+		},
+		{
+			routes: []v1.Route{
+				{
+					Path: "/coffee",
+					Splits: []v1.Split{
+						{
+							Weight: 90,
+							Route:  "coffee-v1",
+						},
+						{
+							Weight: 10,
+							Route:  "coffee-v2",
+						},
+					},
+				},
+				{
+					Path:  "/coffee/latte",
+					Route: "coffee-v2",
+				},
+			},
+			upstreamNames: sets.String{},
+			msg:           "VirtualServerRoute referenced in route splits and in another route",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateVirtualServerRoutes(test.routes, field.NewPath("routes"), test.upstreamNames, sets.String{})
+		if len(allErrs) == 0 {
+			t.Errorf("validateVirtualServerRoutes() returned no errors for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateRoute(t *testing.T) {
+	tests := []struct {
+		route                 v1.Route
+		upstreamNames         sets.String
+		isRouteFieldForbidden bool
+		msg                   string
+	}{
+		{
+			route: v1.Route{
+
+				Path: "/",
+				Action: &v1.Action{
+					Pass: "test",
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "valid route with upstream",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Splits: []v1.Split{
+					{
+						Weight: 90,
+						Action: &v1.Action{
+							Pass: "test-1",
+						},
+					},
+					{
+						Weight: 10,
+						Action: &v1.Action{
+							Pass: "test-2",
+						},
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "valid upstream with splits",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Matches: []v1.Match{
+					{
+						Conditions: []v1.Condition{
+							{
+								Header: "x-version",
+								Value:  "test-1",
+							},
+						},
+						Action: &v1.Action{
+							Pass: "test-1",
+						},
+					},
+				},
+				Action: &v1.Action{
+					Pass: "test-2",
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "valid action with matches",
+		},
+		{
+			route: v1.Route{
+
+				Path:  "/",
+				Route: "default/test",
+			},
+			upstreamNames:         map[string]sets.Empty{},
+			isRouteFieldForbidden: false,
+			msg:                   "valid route with route",
+		},
+		{
+			route: v1.Route{
+				Path: "/upload",
+				Action: &v1.Action{
+					Pass: "test",
+				},
+				RequestBuffering: createPointerFromBool(false),
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "valid route with disabled request buffering",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Matches: []v1.Match{
+					{
+						Conditions: []v1.Condition{
+							{
+								Variable: "$request_body",
+								Value:    "test",
+							},
+						},
+						Action: &v1.Action{
+							Pass: "test",
+						},
+					},
+				},
+				Action: &v1.Action{
+					Pass: "test",
+				},
+				RequestBuffering: createPointerFromBool(true),
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "valid route with enabled request buffering and the request body in a condition",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateRoute(test.route, field.NewPath("route"), test.upstreamNames, sets.String{}, test.isRouteFieldForbidden)
+		if len(allErrs) > 0 {
+			t.Errorf("validateRoute() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateRouteFails(t *testing.T) {
+	tests := []struct {
+		route                 v1.Route
+		upstreamNames         sets.String
+		isRouteFieldForbidden bool
+		msg                   string
+	}{
+		{
+			route: v1.Route{
+				Path: "",
+				Action: &v1.Action{
+					Pass: "test",
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "empty path",
+		},
+		{
+			route: v1.Route{
+				Path: "/test",
+				Action: &v1.Action{
+					Pass: "-test",
+				},
+			},
+			upstreamNames:         sets.String{},
+			isRouteFieldForbidden: false,
+			msg:                   "invalid pass action",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Action: &v1.Action{
+					Pass: "test",
+				},
+			},
+			upstreamNames:         sets.String{},
+			isRouteFieldForbidden: false,
+			msg:                   "non-existing upstream in pass action",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Action: &v1.Action{
+					Pass: "test",
+				},
+				Splits: []v1.Split{
+					{
+						Weight: 90,
+						Action: &v1.Action{
+							Pass: "test-1",
+						},
+					},
+					{
+						Weight: 10,
+						Action: &v1.Action{
+							Pass: "test-2",
+						},
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test":   {},
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "both action and splits exist",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Splits: []v1.Split{
+					{
+						Weight: 90,
+						Action: &v1.Action{
+							Pass: "test-1",
+						},
+					},
+					{
+						Weight: 10,
+						Action: &v1.Action{
+							Pass: "test-2",
+						},
+					},
+				},
+				Matches: []v1.Match{
+					{
+						Conditions: []v1.Condition{
+							{
+								Header: "x-version",
+								Value:  "test-1",
+							},
+						},
+						Action: &v1.Action{
+							Pass: "test-1",
+						},
+					},
+				},
+				Action: &v1.Action{
+					Pass: "test-2",
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "both splits and matches exist",
+		},
+		{
+			route: v1.Route{
+				Path:  "/",
+				Route: "default/test",
+			},
+			upstreamNames:         map[string]sets.Empty{},
+			isRouteFieldForbidden: true,
+			msg:                   "route field exists but is forbidden",
+		},
+		{
+			route: v1.Route{
+				Path:           "/",
+				Route:          "default/test",
+				AllowedMethods: []string{"GET"},
+			},
+			upstreamNames:         map[string]sets.Empty{},
+			isRouteFieldForbidden: false,
+			msg:                   "allowedMethods with route field",
+		},
+		{
+			route: v1.Route{
+				Path:             "/",
+				Route:            "default/test",
+				RequestBuffering: createPointerFromBool(false),
+			},
+			upstreamNames:         map[string]sets.Empty{},
+			isRouteFieldForbidden: false,
+			msg:                   "requestBuffering with route field",
+		},
+		{
+			route: v1.Route{
+				Path:             "/",
+				Route:            "default/test",
+				LocationSnippets: "add_header X-Test test;",
+			},
+			upstreamNames:         map[string]sets.Empty{},
+			isRouteFieldForbidden: false,
+			msg:                   "locationSnippets with route field",
+		},
+		{
+			route: v1.Route{
+				Path:  "/",
+				Route: "default/test",
+				Mirror: &v1.Mirror{
+					Upstream: "test",
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "mirror with route field",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Matches: []v1.Match{
+					{
+						Conditions: []v1.Condition{
+							{
+								Variable: "$request_body",
+								Value:    "test",
+							},
+						},
+						Action: &v1.Action{
+							Pass: "test",
+						},
+					},
+				},
+				Action: &v1.Action{
+					Pass: "test",
+				},
+				RequestBuffering: createPointerFromBool(false),
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "disabled request buffering with the request body in a condition",
+		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Action: &v1.Action{
+					Return: &v1.ActionReturn{
+						Body: "received ${request_body}",
+					},
+				},
+				RequestBuffering: createPointerFromBool(false),
+			},
+			upstreamNames:         map[string]sets.Empty{},
+			isRouteFieldForbidden: false,
+			msg:                   "disabled request buffering with the request body in a return action",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateRoute(test.route, field.NewPath("route"), test.upstreamNames, sets.String{}, test.isRouteFieldForbidden)
+		if len(allErrs) == 0 {
+			t.Errorf("validateRoute() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateAction(t *testing.T) {
+	upstreamNames := map[string]sets.Empty{
+		"test": {},
+	}
+	tests := []struct {
+		action *v1.Action
+		msg    string
+	}{
+		{
+			action: &v1.Action{
+				Pass: "test",
+			},
+			msg: "base pass action",
+		},
+		{
+			action: &v1.Action{
+				Redirect: &v1.ActionRedirect{
+					URL: "http://www.nginx.com",
+				},
+			},
+			msg: "base redirect action",
+		},
+		{
+			action: &v1.Action{
+				Redirect: &v1.ActionRedirect{
+					URL:  "http://www.nginx.com",
+					Code: 302,
+				},
+			},
+			msg: "redirect action with status code set",
+		},
+		{
+			action: &v1.Action{
+				Proxy: &v1.ActionProxy{
+					Upstream:    "test",
+					RewritePath: "/rewrite",
+					RequestHeaders: &v1.ProxyRequestHeaders{
+						Set: []v1.Header{
+							{
+								Name:  "Header-Name",
+								Value: "value",
+							},
+						},
+					},
+					ResponseHeaders: &v1.ProxyResponseHeaders{
+						Hide:   []string{"header-name"},
+						Pass:   []string{"header-name"},
+						Ignore: []string{"Expires"},
+						Add: []v1.AddHeader{
+							{
+								Header: v1.Header{
+									Name:  "Header-Name",
+									Value: "value",
+								},
+								Always: true,
+							},
+						},
+					},
+				},
+			},
+			msg: "proxy action with rewritePath, requestHeaders and responseHeaders",
+		},
+		{
+			action: &v1.Action{
+				Pass: "test",
+				ClientCertForwarding: &v1.ClientCertForwarding{
+					Headers: []v1.ClientCertHeader{
+						{
+							Name:     "X-SSL-Client-Cert",
+							Variable: "ssl_client_escaped_cert",
+						},
+					},
+				},
+			},
+			msg: "pass action with clientCertForwarding",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateAction(test.action, field.NewPath("action"), upstreamNames, "", false)
+		if len(allErrs) > 0 {
+			t.Errorf("validateAction() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateActionFails(t *testing.T) {
+	upstreamNames := map[string]sets.Empty{}
+
+	tests := []struct {
+		action *v1.Action
+		msg    string
+	}{
+
+		{
+			action: &v1.Action{},
+			msg:    "empty action",
+		},
+		{
+			action: &v1.Action{
+				Redirect: &v1.ActionRedirect{},
+			},
+			msg: "missing required field url",
+		},
+		{
+			action: &v1.Action{
+				Pass: "test",
+				Redirect: &v1.ActionRedirect{
+					URL: "http://www.nginx.com",
+				},
+			},
+			msg: "multiple actions defined",
+		},
+		{
+			action: &v1.Action{
+				Redirect: &v1.ActionRedirect{
+					URL:  "http://www.nginx.com",
+					Code: 305,
+				},
+			},
+			msg: "redirect action with invalid status code set",
+		},
+		{
+			action: &v1.Action{
+				Proxy: &v1.ActionProxy{
+					Upstream: "",
+				},
+			},
+			msg: "proxy action with missing upstream field",
+		},
+		{
+			action: &v1.Action{
+				Redirect: &v1.ActionRedirect{
+					URL: "http://www.nginx.com",
+				},
+				ClientCertForwarding: &v1.ClientCertForwarding{
+					Headers: []v1.ClientCertHeader{
+						{
+							Name:     "X-SSL-Client-Cert",
+							Variable: "ssl_client_escaped_cert",
+						},
+					},
+				},
+			},
+			msg: "redirect action with clientCertForwarding",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateAction(test.action, field.NewPath("action"), upstreamNames, "", false)
+		if len(allErrs) == 0 {
+			t.Errorf("validateAction() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateClientCertForwarding(t *testing.T) {
+	ccf := &v1.ClientCertForwarding{
+		Headers: []v1.ClientCertHeader{
+			{
+				Name:     "X-SSL-Client-Cert",
+				Variable: "ssl_client_escaped_cert",
+			},
+			{
+				Name:     "X-SSL-Client-Subject-DN",
+				Variable: "ssl_client_s_dn",
+			},
+			{
+				Name:     "X-SSL-Client-Verify",
+				Variable: "ssl_client_verify",
+			},
+		},
+	}
+
+	allErrs := validateClientCertForwarding(ccf, field.NewPath("clientCertForwarding"))
+	if len(allErrs) > 0 {
+		t.Errorf("validateClientCertForwarding() returned errors %v for valid input", allErrs)
+	}
+}
+
+func TestValidateClientCertForwardingFails(t *testing.T) {
+	tests := []struct {
+		ccf *v1.ClientCertForwarding
+		msg string
+	}{
+		{
+			ccf: &v1.ClientCertForwarding{},
+			msg: "no headers",
+		},
+		{
+			ccf: &v1.ClientCertForwarding{
+				Headers: []v1.ClientCertHeader{
+					{
+						Variable: "ssl_client_s_dn",
+					},
+				},
+			},
+			msg: "missing header name",
+		},
+		{
+			ccf: &v1.ClientCertForwarding{
+				Headers: []v1.ClientCertHeader{
+					{
+						Name:     "X SSL Client",
+						Variable: "ssl_client_s_dn",
+					},
+				},
+			},
+			msg: "invalid header name",
+		},
+		{
+			ccf: &v1.ClientCertForwarding{
+				Headers: []v1.ClientCertHeader{
+					{
+						Name:     "X-SSL-Client",
+						Variable: "ssl_client_s_dn",
+					},
+					{
+						Name:     "x-ssl-client",
+						Variable: "ssl_client_verify",
+					},
+				},
+			},
+			msg: "duplicate header names",
+		},
+		{
+			ccf: &v1.ClientCertForwarding{
+				Headers: []v1.ClientCertHeader{
+					{
+						Name: "X-SSL-Client",
+					},
+				},
+			},
+			msg: "missing variable",
+		},
+		{
+			ccf: &v1.ClientCertForwarding{
+				Headers: []v1.ClientCertHeader{
+					{
+						Name:     "X-SSL-Client-Cert",
+						Variable: "ssl_client_cert",
+					},
+				},
+			},
+			msg: "multi-line variable",
+		},
+		{
+			ccf: &v1.ClientCertForwarding{
+				Headers: []v1.ClientCertHeader{
+					{
+						Name:     "X-SSL-Client",
+						Variable: "remote_addr",
+					},
+				},
+			},
+			msg: "variable without client certificate details",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateClientCertForwarding(test.ccf, field.NewPath("clientCertForwarding"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateClientCertForwarding() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestCaptureVariables(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected []string
+	}{
+		{
+			"${scheme}://${host}",
+			[]string{"scheme", "host"},
+		},
+		{
+			"http://www.nginx.org",
+			nil,
+		},
+		{
+			"${}",
+			[]string{""},
+		},
+	}
+	for _, test := range tests {
+		result := captureVariables(test.s)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("captureVariables(%s) returned %v but expected %v", test.s, result, test.expected)
+		}
+	}
+}
+
+func TestValidateActionRedirect(t *testing.T) {
+	tests := []*v1.ActionRedirect{
+		{
+			URL: "https://cafe.example.com/menu?lang=en",
+		},
+		{
+			URL:          "https://cafe.example.com",
+			Code:         308,
+			PreservePath: true,
+		},
+		{
+			URL:           "${scheme}://${host}/menu",
+			PreservePath:  true,
+			PreserveQuery: true,
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateActionRedirect(test, field.NewPath("redirect"), validRedirectVariableNames)
+		if len(allErrs) != 0 {
+			t.Errorf("validateActionRedirect(%+v) returned errors for valid input: %v", test, allErrs)
+		}
+	}
+}
+
+func TestValidateActionRedirectFails(t *testing.T) {
+	tests := []*v1.ActionRedirect{
+		{
+			URL:  "https://cafe.example.com",
+			Code: 305,
+		},
+		{
+			URL:           "https://cafe.example.com/menu?lang=en",
+			PreserveQuery: true,
+		},
+		{
+			URL:          "https://cafe.example.com/menu?lang=en",
+			PreservePath: true,
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateActionRedirect(test, field.NewPath("redirect"), validRedirectVariableNames)
+		if len(allErrs) == 0 {
+			t.Errorf("validateActionRedirect(%+v) returned no errors for invalid input", test)
+		}
+	}
+}
+
+func TestValidateRedirectURL(t *testing.T) {
+	tests := []struct {
+		redirectURL string
+		msg         string
+	}{
+		{
+			redirectURL: "http://www.nginx.com",
+			msg:         "base redirect url",
+		},
+		{
+			redirectURL: "${scheme}://${host}/sorry",
+			msg:         "multi variable redirect url",
+		},
+		{
+			redirectURL: "${http_x_forwarded_proto}://${host}/sorry",
+			msg:         "x-forwarded-proto redirect url use case",
+		},
+		{
+			redirectURL: "http://${host}${request_uri}",
+			msg:         "use multi variables, no scheme set",
+		},
+		{
+			redirectURL: "${scheme}://www.${host}${request_uri}",
+			msg:         "use multi variables",
+		},
+		{
+			redirectURL: "http://example.com/redirect?source=abc",
+			msg:         "arg variable use",
+		},
+		{
+			redirectURL: `\"${scheme}://${host}\"`,
+			msg:         "url with escaped quotes",
+		},
+		{
+			redirectURL: "http://{abc}",
+			msg:         "url with curly braces with no $ prefix",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateRedirectURL(test.redirectURL, field.NewPath("url"), validRedirectVariableNames)
+		if len(allErrs) > 0 {
+			t.Errorf("validateRedirectURL(%s) returned errors %v for valid input for the case of %s", test.redirectURL, allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateRedirectURLFails(t *testing.T) {
+	tests := []struct {
+		redirectURL string
+		msg         string
+	}{
+
+		{
+			redirectURL: "",
+			msg:         "url is blank",
+		},
+		{
+			redirectURL: "/uri",
+			msg:         "url does not start with http://, https:// or ${scheme}://",
+		},
+		{
+			redirectURL: "$scheme://www.$host.com",
+			msg:         "usage of nginx variable in url without ${}",
+		},
+		{
+			redirectURL: "${scheme}://www.${invalid}.com",
+			msg:         "invalid nginx variable in url",
+		},
+		{
+			redirectURL: "${scheme}://www.${{host}.com",
+			msg:         "leading curly brace",
+		},
+		{
+			redirectURL: "${host.abc}.com",
+			msg:         "multi var in curly brace",
+		},
+		{
+			redirectURL: "${scheme}://www.${host{host}}.com",
+			msg:         "nested nginx vars",
+		},
+		{
+			redirectURL: `"${scheme}://${host}"`,
+			msg:         "url in unescaped quotes",
+		},
+		{
+			redirectURL: `"${scheme}://${host}`,
+			msg:         "url with unescaped quote prefix",
+		},
+		{
+			redirectURL: `\\"${scheme}://${host}\\"`,
+			msg:         "url with escaped backslash",
+		},
+		{
+			redirectURL: `${scheme}://${host}$`,
+			msg:         "url with ending $",
+		},
+		{
+			redirectURL: `http://${}`,
+			msg:         "url containing blank var",
+		},
+		{
+			redirectURL: `http://${abca`,
+			msg:         "url containing a var without ending }",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateRedirectURL(test.redirectURL, field.NewPath("action"), validRedirectVariableNames)
+		if len(allErrs) == 0 {
+			t.Errorf("validateRedirectURL(%s) returned no errors for invalid input for the case of %s", test.redirectURL, test.msg)
+		}
+	}
+}
+
+func TestValidateRouteField(t *testing.T) {
+	validRouteFields := []string{
+		"coffee",
+		"default/coffee",
+	}
+
+	for _, rf := range validRouteFields {
+		allErrs := validateRouteField(rf, field.NewPath("route"))
+		if len(allErrs) > 0 {
+			t.Errorf("validRouteField(%q) returned errors %v for valid input", rf, allErrs)
+		}
+	}
+
+	invalidRouteFields := []string{
+		"-",
+		"/coffee",
+		"-/coffee",
+	}
+
+	for _, rf := range invalidRouteFields {
+		allErrs := validateRouteField(rf, field.NewPath("route"))
+		if len(allErrs) == 0 {
+			t.Errorf("validRouteField(%q) returned no errors for invalid input", rf)
+		}
+	}
+}
+
+func TestValdateReferencedUpstream(t *testing.T) {
+	upstream := "test"
+	upstreamNames := map[string]sets.Empty{
+		"test": {},
+	}
+
+	allErrs := validateReferencedUpstream(upstream, field.NewPath("upstream"), upstreamNames)
+	if len(allErrs) > 0 {
+		t.Errorf("validateReferencedUpstream() returned errors %v for valid input", allErrs)
+	}
+}
+
+func TestValdateUpstreamFails(t *testing.T) {
+	tests := []struct {
+		upstream      string
+		upstreamNames sets.String
+		msg           string
+	}{
+		{
+			upstream:      "",
+			upstreamNames: map[string]sets.Empty{},
+			msg:           "empty upstream",
+		},
+		{
+			upstream:      "-test",
+			upstreamNames: map[string]sets.Empty{},
+			msg:           "invalid upstream",
+		},
+		{
+			upstream:      "test",
+			upstreamNames: map[string]sets.Empty{},
+			msg:           "non-existing upstream",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateReferencedUpstream(test.upstream, field.NewPath("upstream"), test.upstreamNames)
+		if len(allErrs) == 0 {
+			t.Errorf("validateReferencedUpstream() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateRegexPath(t *testing.T) {
+	tests := []struct {
+		regexPath string
+		msg       string
+	}{
+		{
+			regexPath: "~ ^/foo.*\\.jpg",
+			msg:       "case sensitive regexp",
+		},
+		{
+			regexPath: "~* ^/Bar.*\\.jpg",
+			msg:       "case insensitive regexp",
+		},
+		{
+			regexPath: `~ ^/f\"oo.*\\.jpg`,
+			msg:       "regexp with escaped double quotes",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateRegexPath(test.regexPath, field.NewPath("path"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateRegexPath(%v) returned errors for valid input for the case of %v", test.regexPath, test.msg)
+		}
+	}
+}
+
+func TestValidateRegexPathFails(t *testing.T) {
+	tests := []struct {
+		regexPath string
+		msg       string
+	}{
+		{
+			regexPath: "~ [{",
+			msg:       "invalid regexp",
+		},
+		{
+			regexPath: `~ /foo"`,
+			msg:       "unescaped double quotes",
+		},
+		{
+			regexPath: `~"`,
+			msg:       "empty regex",
+		},
+		{
+			regexPath: `~ /foo\`,
+			msg:       "ending in backslash",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateRegexPath(test.regexPath, field.NewPath("path"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateRegexPath(%v) returned no errors for invalid input for the case of %v", test.regexPath, test.msg)
+		}
+	}
+}
+
+func TestValidateRoutePath(t *testing.T) {
+	validPaths := []string{
+		"/",
+		"~ /^foo.*\\.jpg",
+		"~* /^Bar.*\\.jpg",
+		"=/exact/match",
+	}
+
+	for _, path := range validPaths {
+		allErrs := validateRoutePath(path, field.NewPath("path"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateRoutePath(%v) returned errors for valid input", path)
+		}
+	}
+
+	invalidPaths := []string{
+		"",
+		"invalid",
+	}
+
+	for _, path := range invalidPaths {
+		allErrs := validateRoutePath(path, field.NewPath("path"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateRoutePath(%v) returned no errors for invalid input", path)
+		}
+	}
+}
+
+func TestValidatePath(t *testing.T) {
+	validPaths := []string{
+		"/",
+		"/path",
+		"/a-1/_A/",
+	}
+
+	for _, path := range validPaths {
+		allErrs := validatePath(path, field.NewPath("path"))
+		if len(allErrs) > 0 {
+			t.Errorf("validatePath(%q) returned errors %v for valid input", path, allErrs)
+		}
+	}
+
+	invalidPaths := []string{
+		"",
+		" /",
+		"/ ",
+		"/{",
+		"/}",
+		"/abc;",
+	}
+
+	for _, path := range invalidPaths {
+		allErrs := validatePath(path, field.NewPath("path"))
+		if len(allErrs) == 0 {
+			t.Errorf("validatePath(%q) returned no errors for invalid input", path)
+		}
+	}
+}
+
+func TestValidateSplits(t *testing.T) {
+	splits := []v1.Split{
+		{
+			Weight: 90,
+			Action: &v1.Action{
+				Pass: "test-1",
+			},
+		},
+		{
+			Weight: 10,
+			Action: &v1.Action{
+				Proxy: &v1.ActionProxy{
+					Upstream: "test-2",
 				},
 			},
-			upstreamNames:         sets.String{},
-			isRouteFieldForbidden: false,
-			msg:                   "invalid pass action",
 		},
+	}
+	upstreamNames := map[string]sets.Empty{
+		"test-1": {},
+		"test-2": {},
+	}
+
+	allErrs := validateSplits(splits, field.NewPath("splits"), upstreamNames, "")
+	if len(allErrs) > 0 {
+		t.Errorf("validateSplits() returned errors %v for valid input", allErrs)
+	}
+}
+
+func TestValidateSplitsFails(t *testing.T) {
+	tests := []struct {
+		splits        []v1.Split
+		upstreamNames sets.String
+		msg           string
+	}{
+		{
+			splits: []v1.Split{
+				{
+					Weight: 90,
+					Action: &v1.Action{
+						Pass: "test-1",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+			},
+			msg: "only one split",
+		},
+		{
+			splits: []v1.Split{
+				{
+					Weight: 123,
+					Action: &v1.Action{
+						Pass: "test-1",
+					},
+				},
+				{
+					Weight: 10,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			msg: "invalid weight",
+		},
+		{
+			splits: []v1.Split{
+				{
+					Weight: 99,
+					Action: &v1.Action{
+						Pass: "test-1",
+					},
+				},
+				{
+					Weight: 99,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			msg: "invalid total weight",
+		},
+		{
+			splits: []v1.Split{
+				{
+					Weight: 90,
+					Action: &v1.Action{
+						Pass: "",
+					},
+				},
+				{
+					Weight: 10,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			msg: "invalid action",
+		},
+		{
+			splits: []v1.Split{
+				{
+					Weight: 90,
+					Action: &v1.Action{
+						Pass: "some-upstream",
+					},
+				},
+				{
+					Weight: 10,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			msg: "invalid action with non-existing upstream",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateSplits(test.splits, field.NewPath("splits"), test.upstreamNames, "")
+		if len(allErrs) == 0 {
+			t.Errorf("validateSplits() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateCondition(t *testing.T) {
+	tests := []struct {
+		condition v1.Condition
+		msg       string
+	}{
+		{
+			condition: v1.Condition{
+				Header: "x-version",
+				Value:  "v1",
+			},
+			msg: "valid header",
+		},
+		{
+			condition: v1.Condition{
+				Cookie: "my_cookie",
+				Value:  "",
+			},
+			msg: "valid cookie",
+		},
+		{
+			condition: v1.Condition{
+				Argument: "arg",
+				Value:    "yes",
+			},
+			msg: "valid argument",
+		},
+		{
+			condition: v1.Condition{
+				Variable: "$request_method",
+				Value:    "POST",
+			},
+			msg: "valid variable",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCondition(test.condition, field.NewPath("condition"), sets.String{})
+		if len(allErrs) > 0 {
+			t.Errorf("validateCondition() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateConditionFails(t *testing.T) {
+	tests := []struct {
+		condition v1.Condition
+		msg       string
+	}{
+		{
+			condition: v1.Condition{},
+			msg:       "empty condition",
+		},
+		{
+			condition: v1.Condition{
+				Header:   "x-version",
+				Cookie:   "user",
+				Argument: "answer",
+				Variable: "$request_method",
+				Value:    "something",
+			},
+			msg: "invalid condition",
+		},
+		{
+			condition: v1.Condition{
+				Header: "x_version",
+			},
+			msg: "invalid header",
+		},
+		{
+			condition: v1.Condition{
+				Cookie: "my-cookie",
+			},
+			msg: "invalid cookie",
+		},
+		{
+			condition: v1.Condition{
+				Argument: "my-arg",
+			},
+			msg: "invalid argument",
+		},
+		{
+			condition: v1.Condition{
+				Variable: "request_method",
+			},
+			msg: "invalid variable",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCondition(test.condition, field.NewPath("condition"), sets.String{})
+		if len(allErrs) == 0 {
+			t.Errorf("validateCondition() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestIsCookieName(t *testing.T) {
+	validCookieNames := []string{
+		"123",
+		"my_cookie",
+	}
+
+	for _, name := range validCookieNames {
+		errs := isCookieName(name)
+		if len(errs) > 0 {
+			t.Errorf("isCookieName(%q) returned errors %v for valid input", name, errs)
+		}
+	}
+
+	invalidCookieNames := []string{
+		"",
+		"my-cookie",
+		"cookie!",
+	}
+
+	for _, name := range invalidCookieNames {
+		errs := isCookieName(name)
+		if len(errs) == 0 {
+			t.Errorf("isCookieName(%q) returned no errors for invalid input", name)
+		}
+	}
+}
+
+func TestIsArgumentName(t *testing.T) {
+	validArgumentNames := []string{
+		"123",
+		"my_arg",
+	}
+
+	for _, name := range validArgumentNames {
+		errs := isArgumentName(name)
+		if len(errs) > 0 {
+			t.Errorf("isArgumentName(%q) returned errors %v for valid input", name, errs)
+		}
+	}
+
+	invalidArgumentNames := []string{
+		"",
+		"my-arg",
+		"arg!",
+	}
+
+	for _, name := range invalidArgumentNames {
+		errs := isArgumentName(name)
+		if len(errs) == 0 {
+			t.Errorf("isArgumentName(%q) returned no errors for invalid input", name)
+		}
+	}
+}
+
+func TestValidateVariableName(t *testing.T) {
+	validNames := []string{
+		"$request_method",
+	}
+
+	for _, name := range validNames {
+		allErrs := validateVariableName(name, field.NewPath("variable"), sets.String{})
+		if len(allErrs) > 0 {
+			t.Errorf("validateVariableName(%q) returned errors %v for valid input", name, allErrs)
+		}
+	}
+
+	invalidNames := []string{
+		"request_method",
+		"$request_id",
+	}
+
+	for _, name := range invalidNames {
+		allErrs := validateVariableName(name, field.NewPath("variable"), sets.String{})
+		if len(allErrs) == 0 {
+			t.Errorf("validateVariableName(%q) returned no errors for invalid input", name)
+		}
+	}
+}
+
+func TestValidateVariableNameWithMaps(t *testing.T) {
+	mapNames := sets.NewString("region")
+
+	allErrs := validateVariableName("$region", field.NewPath("variable"), mapNames)
+	if len(allErrs) > 0 {
+		t.Errorf("validateVariableName() returned errors %v for a variable of a map", allErrs)
+	}
+
+	allErrs = validateVariableName("$tenant", field.NewPath("variable"), mapNames)
+	if len(allErrs) == 0 {
+		t.Errorf("validateVariableName() returned no errors for a variable of a map that doesn't exist")
+	}
+}
+
+func TestValidateMatch(t *testing.T) {
+	tests := []struct {
+		match         v1.Match
+		upstreamNames sets.String
+		msg           string
+	}{
 		{
-			route: v1.Route{
-				Path: "/",
+			match: v1.Match{
+				Conditions: []v1.Condition{
+					{
+						Cookie: "version",
+						Value:  "v1",
+					},
+				},
 				Action: &v1.Action{
 					Pass: "test",
 				},
 			},
-			upstreamNames:         sets.String{},
-			isRouteFieldForbidden: false,
-			msg:                   "non-existing upstream in pass action",
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			msg: "valid match with action",
 		},
 		{
-			route: v1.Route{
-				Path: "/",
-				Action: &v1.Action{
-					Pass: "test",
+			match: v1.Match{
+				Conditions: []v1.Condition{
+					{
+						Cookie: "version",
+						Value:  "v1",
+					},
 				},
 				Splits: []v1.Split{
 					{
@@ -747,585 +3125,697 @@ func TestValidateRouteFails(t *testing.T) {
 				},
 			},
 			upstreamNames: map[string]sets.Empty{
-				"test":   {},
 				"test-1": {},
 				"test-2": {},
 			},
-			isRouteFieldForbidden: false,
-			msg:                   "both action and splits exist",
+			msg: "valid match with splits",
 		},
 		{
-			route: v1.Route{
-				Path: "/",
-				Splits: []v1.Split{
+			match: v1.Match{
+				Conditions: []v1.Condition{
 					{
-						Weight: 90,
-						Action: &v1.Action{
-							Pass: "test-1",
-						},
+						Header: "x-version",
+						Value:  "v2",
 					},
 					{
-						Weight: 10,
-						Action: &v1.Action{
-							Pass: "test-2",
-						},
+						Cookie: "x_version",
+						Value:  "beta",
 					},
-				},
-				Matches: []v1.Match{
 					{
-						Conditions: []v1.Condition{
-							{
-								Header: "x-version",
-								Value:  "test-1",
-							},
-						},
-						Action: &v1.Action{
-							Pass: "test-1",
-						},
+						Argument: "x_version",
+						Value:    "v2",
 					},
 				},
 				Action: &v1.Action{
-					Pass: "test-2",
+					Pass: "test",
 				},
 			},
 			upstreamNames: map[string]sets.Empty{
-				"test-1": {},
-				"test-2": {},
-			},
-			isRouteFieldForbidden: false,
-			msg:                   "both splits and matches exist",
-		},
-		{
-			route: v1.Route{
-				Path:  "/",
-				Route: "default/test",
+				"test": {},
 			},
-			upstreamNames:         map[string]sets.Empty{},
-			isRouteFieldForbidden: true,
-			msg:                   "route field exists but is forbidden",
+			msg: "valid match with multiple conditions of different sources",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateRoute(test.route, field.NewPath("route"), test.upstreamNames, test.isRouteFieldForbidden)
-		if len(allErrs) == 0 {
-			t.Errorf("validateRoute() returned no errors for invalid input for the case of %s", test.msg)
+		allErrs := validateMatch(test.match, field.NewPath("match"), test.upstreamNames, sets.String{}, "")
+		if len(allErrs) > 0 {
+			t.Errorf("validateMatch() returned errors %v for valid input for the case of %s", allErrs, test.msg)
 		}
 	}
 }
 
-func TestValidateAction(t *testing.T) {
-	upstreamNames := map[string]sets.Empty{
-		"test": {},
-	}
+func TestValidateMatchFails(t *testing.T) {
 	tests := []struct {
-		action *v1.Action
-		msg    string
+		match         v1.Match
+		upstreamNames sets.String
+		msg           string
 	}{
 		{
-			action: &v1.Action{
-				Pass: "test",
+			match: v1.Match{
+				Conditions: []v1.Condition{},
+				Action: &v1.Action{
+					Pass: "test",
+				},
 			},
-			msg: "base pass action",
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			msg: "invalid number of conditions",
 		},
 		{
-			action: &v1.Action{
-				Redirect: &v1.ActionRedirect{
-					URL: "http://www.nginx.com",
+			match: v1.Match{
+				Conditions: []v1.Condition{
+					{
+						Cookie: "version",
+						Value:  `v1"`,
+					},
+				},
+				Action: &v1.Action{
+					Pass: "test",
 				},
 			},
-			msg: "base redirect action",
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			msg: "invalid condition",
 		},
 		{
-			action: &v1.Action{
-				Redirect: &v1.ActionRedirect{
-					URL:  "http://www.nginx.com",
-					Code: 302,
+			match: v1.Match{
+				Conditions: []v1.Condition{
+					{
+						Cookie: "version",
+						Value:  "v1",
+					},
 				},
+				Action: &v1.Action{},
 			},
-			msg: "redirect action with status code set",
+			upstreamNames: map[string]sets.Empty{},
+			msg:           "invalid  action",
 		},
 		{
-			action: &v1.Action{
-				Proxy: &v1.ActionProxy{
-					Upstream:    "test",
-					RewritePath: "/rewrite",
-					RequestHeaders: &v1.ProxyRequestHeaders{
-						Set: []v1.Header{
-							{
-								Name:  "Header-Name",
-								Value: "value",
-							},
+			match: v1.Match{
+				Conditions: []v1.Condition{
+					{
+						Cookie: "version",
+						Value:  "v1",
+					},
+				},
+				Action: &v1.Action{
+					Pass: "test-1",
+				},
+				Splits: []v1.Split{
+					{
+						Weight: 90,
+						Action: &v1.Action{
+							Pass: "test-1",
 						},
 					},
-					ResponseHeaders: &v1.ProxyResponseHeaders{
-						Hide:   []string{"header-name"},
-						Pass:   []string{"header-name"},
-						Ignore: []string{"Expires"},
-						Add: []v1.AddHeader{
-							{
-								Header: v1.Header{
-									Name:  "Header-Name",
-									Value: "value",
-								},
-								Always: true,
-							},
+					{
+						Weight: 10,
+						Action: &v1.Action{
+							Pass: "test-2",
 						},
 					},
 				},
 			},
-			msg: "proxy action with rewritePath, requestHeaders and responseHeaders",
-		},
-	}
-
-	for _, test := range tests {
-		allErrs := validateAction(test.action, field.NewPath("action"), upstreamNames, "", false)
-		if len(allErrs) > 0 {
-			t.Errorf("validateAction() returned errors %v for valid input for the case of %s", allErrs, test.msg)
-		}
-	}
-}
-
-func TestValidateActionFails(t *testing.T) {
-	upstreamNames := map[string]sets.Empty{}
-
-	tests := []struct {
-		action *v1.Action
-		msg    string
-	}{
-
-		{
-			action: &v1.Action{},
-			msg:    "empty action",
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			msg: "both splits and action are set",
 		},
 		{
-			action: &v1.Action{
-				Redirect: &v1.ActionRedirect{},
+			match: v1.Match{
+				Conditions: []v1.Condition{
+					{
+						Header: "x-version",
+						Value:  "v1",
+					},
+					{
+						Header: "X_Version",
+						Value:  "v2",
+					},
+				},
+				Action: &v1.Action{
+					Pass: "test",
+				},
 			},
-			msg: "missing required field url",
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			msg: "duplicate header",
 		},
 		{
-			action: &v1.Action{
-				Pass: "test",
-				Redirect: &v1.ActionRedirect{
-					URL: "http://www.nginx.com",
+			match: v1.Match{
+				Conditions: []v1.Condition{
+					{
+						Cookie: "user",
+						Value:  "v1",
+					},
+					{
+						Cookie: "user",
+						Value:  "v2",
+					},
+				},
+				Action: &v1.Action{
+					Pass: "test",
 				},
 			},
-			msg: "multiple actions defined",
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			msg: "duplicate cookie",
 		},
 		{
-			action: &v1.Action{
-				Redirect: &v1.ActionRedirect{
-					URL:  "http://www.nginx.com",
-					Code: 305,
+			match: v1.Match{
+				Conditions: []v1.Condition{
+					{
+						Argument: "answer",
+						Value:    "v1",
+					},
+					{
+						Argument: "Answer",
+						Value:    "v2",
+					},
+				},
+				Action: &v1.Action{
+					Pass: "test",
 				},
 			},
-			msg: "redirect action with invalid status code set",
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			msg: "duplicate argument",
 		},
 		{
-			action: &v1.Action{
-				Proxy: &v1.ActionProxy{
-					Upstream: "",
+			match: v1.Match{
+				Conditions: []v1.Condition{
+					{
+						Variable: "$request_method",
+						Value:    "v1",
+					},
+					{
+						Variable: "$request_method",
+						Value:    "v2",
+					},
+				},
+				Action: &v1.Action{
+					Pass: "test",
 				},
 			},
-			msg: "proxy action with missing upstream field",
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			msg: "duplicate variable",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateAction(test.action, field.NewPath("action"), upstreamNames, "", false)
+		allErrs := validateMatch(test.match, field.NewPath("match"), test.upstreamNames, sets.String{}, "")
 		if len(allErrs) == 0 {
-			t.Errorf("validateAction() returned no errors for invalid input for the case of %s", test.msg)
+			t.Errorf("validateMatch() returned no errors for invalid input for the case of %s", test.msg)
 		}
 	}
 }
 
-func TestCaptureVariables(t *testing.T) {
-	tests := []struct {
-		s        string
-		expected []string
-	}{
-		{
-			"${scheme}://${host}",
-			[]string{"scheme", "host"},
-		},
-		{
-			"http://www.nginx.org",
-			nil,
-		},
-		{
-			"${}",
-			[]string{""},
-		},
+func TestIsValidMatchValue(t *testing.T) {
+	validValues := []string{
+		"abc",
+		"123",
+		`\"
+		abc\"`,
+		`\"`,
 	}
-	for _, test := range tests {
-		result := captureVariables(test.s)
-		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("captureVariables(%s) returned %v but expected %v", test.s, result, test.expected)
+
+	for _, value := range validValues {
+		errs := isValidMatchValue(value)
+		if len(errs) > 0 {
+			t.Errorf("isValidMatchValue(%q) returned errors %v for valid input", value, errs)
+		}
+	}
+
+	invalidValues := []string{
+		`"`,
+		`\`,
+		`abc"`,
+		`abc\\\`,
+		`a"b`,
+	}
+
+	for _, value := range invalidValues {
+		errs := isValidMatchValue(value)
+		if len(errs) == 0 {
+			t.Errorf("isValidMatchValue(%q) returned no errors for invalid input", value)
 		}
 	}
 }
 
-func TestValidateRedirectURL(t *testing.T) {
-	tests := []struct {
-		redirectURL string
-		msg         string
-	}{
-		{
-			redirectURL: "http://www.nginx.com",
-			msg:         "base redirect url",
-		},
-		{
-			redirectURL: "${scheme}://${host}/sorry",
-			msg:         "multi variable redirect url",
-		},
-		{
-			redirectURL: "${http_x_forwarded_proto}://${host}/sorry",
-			msg:         "x-forwarded-proto redirect url use case",
-		},
-		{
-			redirectURL: "http://${host}${request_uri}",
-			msg:         "use multi variables, no scheme set",
-		},
-		{
-			redirectURL: "${scheme}://www.${host}${request_uri}",
-			msg:         "use multi variables",
+func TestValidateVirtualServerRoute(t *testing.T) {
+	virtualServerRoute := v1.VirtualServerRoute{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "coffee",
+			Namespace: "default",
 		},
-		{
-			redirectURL: "http://example.com/redirect?source=abc",
-			msg:         "arg variable use",
+		Spec: v1.VirtualServerRouteSpec{
+			Host: "example.com",
+			Upstreams: []v1.Upstream{
+				{
+					Name:    "first",
+					Service: "service-1",
+					Port:    80,
+				},
+				{
+					Name:    "second",
+					Service: "service-2",
+					Port:    80,
+				},
+			},
+			Subroutes: []v1.Route{
+				{
+					Path: "/test/first",
+					Action: &v1.Action{
+						Pass: "first",
+					},
+				},
+				{
+					Path: "/test/second",
+					Action: &v1.Action{
+						Pass: "second",
+					},
+				},
+			},
 		},
-		{
-			redirectURL: `\"${scheme}://${host}\"`,
-			msg:         "url with escaped quotes",
+	}
+	isPlus := false
+	err := ValidateVirtualServerRoute(&virtualServerRoute, isPlus)
+	if err != nil {
+		t.Errorf("ValidateVirtualServerRoute() returned error %v for valid input %v", err, virtualServerRoute)
+	}
+}
+
+func TestValidateVirtualServerRouteForVirtualServer(t *testing.T) {
+	virtualServerRoute := v1.VirtualServerRoute{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "coffee",
+			Namespace: "default",
 		},
-		{
-			redirectURL: "http://{abc}",
-			msg:         "url with curly braces with no $ prefix",
+		Spec: v1.VirtualServerRouteSpec{
+			Host: "example.com",
+			Upstreams: []v1.Upstream{
+				{
+					Name:    "first",
+					Service: "service-1",
+					Port:    80,
+				},
+				{
+					Name:    "second",
+					Service: "service-2",
+					Port:    80,
+				},
+			},
+			Subroutes: []v1.Route{
+				{
+					Path: "/test/first",
+					Action: &v1.Action{
+						Pass: "first",
+					},
+				},
+				{
+					Path: "/test/second",
+					Action: &v1.Action{
+						Pass: "second",
+					},
+				},
+			},
 		},
 	}
+	virtualServerHost := "example.com"
+	pathPrefix := "/test"
 
-	for _, test := range tests {
-		allErrs := validateRedirectURL(test.redirectURL, field.NewPath("url"), validRedirectVariableNames)
-		if len(allErrs) > 0 {
-			t.Errorf("validateRedirectURL(%s) returned errors %v for valid input for the case of %s", test.redirectURL, allErrs, test.msg)
-		}
+	isPlus := false
+	err := ValidateVirtualServerRouteForVirtualServer(&virtualServerRoute, virtualServerHost, pathPrefix, isPlus)
+	if err != nil {
+		t.Errorf("ValidateVirtualServerRouteForVirtualServer() returned error %v for valid input %v", err, virtualServerRoute)
 	}
 }
 
-func TestValidateRedirectURLFails(t *testing.T) {
+func TestValidateRouteSplits(t *testing.T) {
+	route := v1.Route{
+		Path: "/coffee",
+		Splits: []v1.Split{
+			{
+				Weight: 90,
+				Route:  "coffee-v1",
+			},
+			{
+				Weight: 10,
+				Route:  "default/coffee-v2",
+			},
+		},
+	}
+
+	allErrs := validateRouteSplits(route, field.NewPath("route"))
+	if len(allErrs) > 0 {
+		t.Errorf("validateRouteSplits() returned errors %v for valid input", allErrs)
+	}
+}
+
+func TestValidateRouteSplitsFails(t *testing.T) {
 	tests := []struct {
-		redirectURL string
-		msg         string
+		route v1.Route
+		msg   string
 	}{
-
-		{
-			redirectURL: "",
-			msg:         "url is blank",
-		},
-		{
-			redirectURL: "/uri",
-			msg:         "url does not start with http://, https:// or ${scheme}://",
-		},
-		{
-			redirectURL: "$scheme://www.$host.com",
-			msg:         "usage of nginx variable in url without ${}",
-		},
-		{
-			redirectURL: "${scheme}://www.${invalid}.com",
-			msg:         "invalid nginx variable in url",
-		},
-		{
-			redirectURL: "${scheme}://www.${{host}.com",
-			msg:         "leading curly brace",
-		},
-		{
-			redirectURL: "${host.abc}.com",
-			msg:         "multi var in curly brace",
-		},
 		{
-			redirectURL: "${scheme}://www.${host{host}}.com",
-			msg:         "nested nginx vars",
+			route: v1.Route{
+				Path: "/coffee",
+				Splits: []v1.Split{
+					{
+						Weight: 90,
+						Route:  "coffee-v1",
+					},
+					{
+						Weight: 20,
+						Route:  "coffee-v2",
+					},
+				},
+			},
+			msg: "the sum of the weights is not 100",
 		},
 		{
-			redirectURL: `"${scheme}://${host}"`,
-			msg:         "url in unescaped quotes",
+			route: v1.Route{
+				Path: "/coffee",
+				Splits: []v1.Split{
+					{
+						Weight: 100,
+						Route:  "coffee-v1",
+					},
+				},
+			},
+			msg: "one split",
 		},
 		{
-			redirectURL: `"${scheme}://${host}`,
-			msg:         "url with unescaped quote prefix",
+			route: v1.Route{
+				Path: "/coffee",
+				Splits: []v1.Split{
+					{
+						Weight: 90,
+						Route:  "coffee-v1",
+					},
+					{
+						Weight: 10,
+						Action: &v1.Action{
+							Pass: "coffee",
+						},
+					},
+				},
+			},
+			msg: "split with an action",
 		},
 		{
-			redirectURL: `\\"${scheme}://${host}\\"`,
-			msg:         "url with escaped backslash",
+			route: v1.Route{
+				Path: "/coffee",
+				Splits: []v1.Split{
+					{
+						Weight: 50,
+						Route:  "coffee-v1",
+					},
+					{
+						Weight: 50,
+						Route:  "coffee-v1",
+					},
+				},
+			},
+			msg: "duplicate routes",
 		},
 		{
-			redirectURL: `${scheme}://${host}$`,
-			msg:         "url with ending $",
+			route: v1.Route{
+				Path: "/coffee",
+				Splits: []v1.Split{
+					{
+						Weight: 90,
+						Route:  "coffee-v1",
+					},
+					{
+						Weight: 10,
+						Route:  "default/coffee/v2",
+					},
+				},
+			},
+			msg: "invalid route",
 		},
 		{
-			redirectURL: `http://${}`,
-			msg:         "url containing blank var",
+			route: v1.Route{
+				Path: "~ ^/coffee",
+				Splits: []v1.Split{
+					{
+						Weight: 90,
+						Route:  "coffee-v1",
+					},
+					{
+						Weight: 10,
+						Route:  "coffee-v2",
+					},
+				},
+			},
+			msg: "regex path",
 		},
 		{
-			redirectURL: `http://${abca`,
-			msg:         "url containing a var without ending }",
+			route: v1.Route{
+				Path: "/coffee",
+				Splits: []v1.Split{
+					{
+						Weight: 90,
+						Route:  "coffee-v1",
+					},
+					{
+						Weight: 10,
+						Route:  "coffee-v2",
+					},
+				},
+				StickySplits: &v1.StickySplits{
+					Enable: true,
+				},
+			},
+			msg: "sticky splits",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateRedirectURL(test.redirectURL, field.NewPath("action"), validRedirectVariableNames)
-		if len(allErrs) == 0 {
-			t.Errorf("validateRedirectURL(%s) returned no errors for invalid input for the case of %s", test.redirectURL, test.msg)
-		}
-	}
-}
-
-func TestValidateRouteField(t *testing.T) {
-	validRouteFields := []string{
-		"coffee",
-		"default/coffee",
-	}
-
-	for _, rf := range validRouteFields {
-		allErrs := validateRouteField(rf, field.NewPath("route"))
-		if len(allErrs) > 0 {
-			t.Errorf("validRouteField(%q) returned errors %v for valid input", rf, allErrs)
-		}
-	}
-
-	invalidRouteFields := []string{
-		"-",
-		"/coffee",
-		"-/coffee",
-	}
-
-	for _, rf := range invalidRouteFields {
-		allErrs := validateRouteField(rf, field.NewPath("route"))
+		allErrs := validateRouteSplits(test.route, field.NewPath("route"))
 		if len(allErrs) == 0 {
-			t.Errorf("validRouteField(%q) returned no errors for invalid input", rf)
+			t.Errorf("validateRouteSplits() returned no errors for invalid input for the case of %s", test.msg)
 		}
 	}
 }
 
-func TestValdateReferencedUpstream(t *testing.T) {
-	upstream := "test"
-	upstreamNames := map[string]sets.Empty{
-		"test": {},
-	}
-
-	allErrs := validateReferencedUpstream(upstream, field.NewPath("upstream"), upstreamNames)
-	if len(allErrs) > 0 {
-		t.Errorf("validateReferencedUpstream() returned errors %v for valid input", allErrs)
-	}
-}
-
-func TestValdateUpstreamFails(t *testing.T) {
-	tests := []struct {
-		upstream      string
-		upstreamNames sets.String
-		msg           string
-	}{
-		{
-			upstream:      "",
-			upstreamNames: map[string]sets.Empty{},
-			msg:           "empty upstream",
-		},
+func TestValidateSplitsFailsWithRoute(t *testing.T) {
+	splits := []v1.Split{
 		{
-			upstream:      "-test",
-			upstreamNames: map[string]sets.Empty{},
-			msg:           "invalid upstream",
+			Weight: 90,
+			Route:  "coffee-v1",
 		},
 		{
-			upstream:      "test",
-			upstreamNames: map[string]sets.Empty{},
-			msg:           "non-existing upstream",
+			Weight: 10,
+			Route:  "coffee-v2",
 		},
 	}
 
-	for _, test := range tests {
-		allErrs := validateReferencedUpstream(test.upstream, field.NewPath("upstream"), test.upstreamNames)
-		if len(allErrs) == 0 {
-			t.Errorf("validateReferencedUpstream() returned no errors for invalid input for the case of %s", test.msg)
-		}
+	allErrs := validateSplits(splits, field.NewPath("splits"), sets.String{}, "/coffee")
+	if len(allErrs) == 0 {
+		t.Errorf("validateSplits() returned no errors for the splits that reference VirtualServerRoutes")
 	}
 }
 
-func TestValidateRegexPath(t *testing.T) {
-	tests := []struct {
-		regexPath string
-		msg       string
-	}{
-		{
-			regexPath: "~ ^/foo.*\\.jpg",
-			msg:       "case sensitive regexp",
-		},
-		{
-			regexPath: "~* ^/Bar.*\\.jpg",
-			msg:       "case insensitive regexp",
+func TestValidateVirtualServerRouteForRouteSplit(t *testing.T) {
+	virtualServerRoute := v1.VirtualServerRoute{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "coffee",
+			Namespace: "default",
 		},
-		{
-			regexPath: `~ ^/f\"oo.*\\.jpg`,
-			msg:       "regexp with escaped double quotes",
+		Spec: v1.VirtualServerRouteSpec{
+			Host: "example.com",
+			Upstreams: []v1.Upstream{
+				{
+					Name:    "first",
+					Service: "service-1",
+					Port:    80,
+				},
+			},
+			Subroutes: []v1.Route{
+				{
+					Path: "/test/first",
+					Action: &v1.Action{
+						Pass: "first",
+					},
+				},
+				{
+					Path: "=/test",
+					Action: &v1.Action{
+						Pass: "first",
+					},
+				},
+			},
 		},
 	}
 
-	for _, test := range tests {
-		allErrs := validateRegexPath(test.regexPath, field.NewPath("path"))
-		if len(allErrs) != 0 {
-			t.Errorf("validateRegexPath(%v) returned errors for valid input for the case of %v", test.regexPath, test.msg)
-		}
+	err := ValidateVirtualServerRouteForRouteSplit(&virtualServerRoute, "example.com", "/test", false)
+	if err != nil {
+		t.Errorf("ValidateVirtualServerRouteForRouteSplit() returned error %v for valid input %v", err, virtualServerRoute)
+	}
+
+	virtualServerRoute.Spec.Subroutes = append(virtualServerRoute.Spec.Subroutes, v1.Route{
+		Path: "~ \\.jpg$",
+		Action: &v1.Action{
+			Pass: "first",
+		},
+	})
+
+	err = ValidateVirtualServerRouteForRouteSplit(&virtualServerRoute, "example.com", "/test", false)
+	if err == nil {
+		t.Errorf("ValidateVirtualServerRouteForRouteSplit() returned no error for a subroute with a regex path")
 	}
 }
 
-func TestValidateRegexPathFails(t *testing.T) {
+func TestRejectSnippetsInVirtualServer(t *testing.T) {
 	tests := []struct {
-		regexPath string
-		msg       string
+		spec           v1.VirtualServerSpec
+		expectedErrors int
+		msg            string
 	}{
 		{
-			regexPath: "~ [{",
-			msg:       "invalid regexp",
-		},
-		{
-			regexPath: `~ /foo"`,
-			msg:       "unescaped double quotes",
+			spec: v1.VirtualServerSpec{
+				Host:   "example.com",
+				Routes: []v1.Route{{Path: "/"}},
+			},
+			expectedErrors: 0,
+			msg:            "no snippets",
 		},
 		{
-			regexPath: `~"`,
-			msg:       "empty regex",
+			spec: v1.VirtualServerSpec{
+				Host:           "example.com",
+				ServerSnippets: "deny all;",
+				Routes:         []v1.Route{{Path: "/"}},
+			},
+			expectedErrors: 1,
+			msg:            "server snippets",
 		},
 		{
-			regexPath: `~ /foo\`,
-			msg:       "ending in backslash",
+			spec: v1.VirtualServerSpec{
+				Host:           "example.com",
+				ServerSnippets: "deny all;",
+				Routes: []v1.Route{
+					{Path: "/"},
+					{Path: "/tea", LocationSnippets: "deny all;"},
+				},
+			},
+			expectedErrors: 2,
+			msg:            "server and location snippets",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateRegexPath(test.regexPath, field.NewPath("path"))
-		if len(allErrs) == 0 {
-			t.Errorf("validateRegexPath(%v) returned no errors for invalid input for the case of %v", test.regexPath, test.msg)
+		allErrs := RejectSnippetsInVirtualServer(&v1.VirtualServer{Spec: test.spec})
+		if len(allErrs) != test.expectedErrors {
+			t.Errorf("RejectSnippetsInVirtualServer() returned errors %v but expected %d errors for the case of %s", allErrs, test.expectedErrors, test.msg)
 		}
 	}
 }
 
-func TestValidateRoutePath(t *testing.T) {
-	validPaths := []string{
-		"/",
-		"~ /^foo.*\\.jpg",
-		"~* /^Bar.*\\.jpg",
-		"=/exact/match",
+func TestRejectSnippetsInVirtualServerRoute(t *testing.T) {
+	vsr := &v1.VirtualServerRoute{
+		Spec: v1.VirtualServerRouteSpec{
+			Host:      "example.com",
+			Subroutes: []v1.Route{{Path: "/tea"}},
+		},
 	}
 
-	for _, path := range validPaths {
-		allErrs := validateRoutePath(path, field.NewPath("path"))
-		if len(allErrs) != 0 {
-			t.Errorf("validateRoutePath(%v) returned errors for valid input", path)
-		}
+	err := RejectSnippetsInVirtualServerRoute(vsr)
+	if err != nil {
+		t.Errorf("RejectSnippetsInVirtualServerRoute() returned error %v for a VirtualServerRoute without snippets", err)
 	}
 
-	invalidPaths := []string{
-		"",
-		"invalid",
-	}
+	vsr.Spec.Subroutes = append(vsr.Spec.Subroutes, v1.Route{Path: "/tea/green", LocationSnippets: "deny all;"})
 
-	for _, path := range invalidPaths {
-		allErrs := validateRoutePath(path, field.NewPath("path"))
-		if len(allErrs) == 0 {
-			t.Errorf("validateRoutePath(%v) returned no errors for invalid input", path)
-		}
+	err = RejectSnippetsInVirtualServerRoute(vsr)
+	if err == nil {
+		t.Errorf("RejectSnippetsInVirtualServerRoute() returned no error for a VirtualServerRoute with location snippets")
 	}
 }
 
-func TestValidatePath(t *testing.T) {
-	validPaths := []string{
-		"/",
-		"/path",
-		"/a-1/_A/",
-	}
+func TestValidateVirtualServerRouteHost(t *testing.T) {
+	virtualServerHost := "example.com"
 
-	for _, path := range validPaths {
-		allErrs := validatePath(path, field.NewPath("path"))
-		if len(allErrs) > 0 {
-			t.Errorf("validatePath(%q) returned errors %v for valid input", path, allErrs)
-		}
-	}
+	validHost := "example.com"
 
-	invalidPaths := []string{
-		"",
-		" /",
-		"/ ",
-		"/{",
-		"/}",
-		"/abc;",
+	allErrs := validateVirtualServerRouteHost(validHost, virtualServerHost, field.NewPath("host"))
+	if len(allErrs) > 0 {
+		t.Errorf("validateVirtualServerRouteHost() returned errors %v for valid input", allErrs)
 	}
 
-	for _, path := range invalidPaths {
-		allErrs := validatePath(path, field.NewPath("path"))
-		if len(allErrs) == 0 {
-			t.Errorf("validatePath(%q) returned no errors for invalid input", path)
-		}
+	invalidHost := "foo.example.com"
+
+	allErrs = validateVirtualServerRouteHost(invalidHost, virtualServerHost, field.NewPath("host"))
+	if len(allErrs) == 0 {
+		t.Errorf("validateVirtualServerRouteHost() returned no errors for invalid input")
 	}
 }
 
-func TestValidateSplits(t *testing.T) {
-	splits := []v1.Split{
+func TestValidateVirtualServerRouteSubroutes(t *testing.T) {
+	tests := []struct {
+		routes        []v1.Route
+		upstreamNames sets.String
+		pathPrefix    string
+		msg           string
+	}{
 		{
-			Weight: 90,
-			Action: &v1.Action{
-				Pass: "test-1",
-			},
+			routes:        []v1.Route{},
+			upstreamNames: sets.String{},
+			pathPrefix:    "/",
+			msg:           "no routes",
 		},
 		{
-			Weight: 10,
-			Action: &v1.Action{
-				Proxy: &v1.ActionProxy{
-					Upstream: "test-2",
+			routes: []v1.Route{
+				{
+					Path: "/",
+					Action: &v1.Action{
+						Pass: "test",
+					},
 				},
 			},
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			pathPrefix: "/",
+			msg:        "valid route",
 		},
 	}
-	upstreamNames := map[string]sets.Empty{
-		"test-1": {},
-		"test-2": {},
-	}
 
-	allErrs := validateSplits(splits, field.NewPath("splits"), upstreamNames, "")
-	if len(allErrs) > 0 {
-		t.Errorf("validateSplits() returned errors %v for valid input", allErrs)
+	for _, test := range tests {
+		allErrs := validateVirtualServerRouteSubroutes(test.routes, field.NewPath("subroutes"), test.upstreamNames, test.pathPrefix)
+		if len(allErrs) > 0 {
+			t.Errorf("validateVirtualServerRouteSubroutes() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
 	}
 }
 
-func TestValidateSplitsFails(t *testing.T) {
+func TestValidateVirtualServerRouteSubroutesFails(t *testing.T) {
 	tests := []struct {
-		splits        []v1.Split
+		routes        []v1.Route
 		upstreamNames sets.String
+		pathPrefix    string
 		msg           string
 	}{
 		{
-			splits: []v1.Split{
-				{
-					Weight: 90,
-					Action: &v1.Action{
-						Pass: "test-1",
-					},
-				},
-			},
-			upstreamNames: map[string]sets.Empty{
-				"test-1": {},
-			},
-			msg: "only one split",
-		},
-		{
-			splits: []v1.Split{
+			routes: []v1.Route{
 				{
-					Weight: 123,
+					Path: "/test",
 					Action: &v1.Action{
 						Pass: "test-1",
 					},
 				},
 				{
-					Weight: 10,
+					Path: "/test",
 					Action: &v1.Action{
 						Pass: "test-2",
 					},
@@ -1335,1256 +3825,1388 @@ func TestValidateSplitsFails(t *testing.T) {
 				"test-1": {},
 				"test-2": {},
 			},
-			msg: "invalid weight",
+			pathPrefix: "/",
+			msg:        "duplicated paths",
 		},
 		{
-			splits: []v1.Split{
-				{
-					Weight: 99,
-					Action: &v1.Action{
-						Pass: "test-1",
-					},
-				},
+			routes: []v1.Route{
 				{
-					Weight: 99,
-					Action: &v1.Action{
-						Pass: "test-2",
-					},
+					Path:   "",
+					Action: nil,
 				},
 			},
-			upstreamNames: map[string]sets.Empty{
-				"test-1": {},
-				"test-2": {},
-			},
-			msg: "invalid total weight",
+			upstreamNames: map[string]sets.Empty{},
+			pathPrefix:    "",
+			msg:           "invalid route",
 		},
 		{
-			splits: []v1.Split{
-				{
-					Weight: 90,
-					Action: &v1.Action{
-						Pass: "",
-					},
-				},
+			routes: []v1.Route{
 				{
-					Weight: 10,
+					Path: "/",
 					Action: &v1.Action{
-						Pass: "test-2",
+						Pass: "test-1",
 					},
 				},
 			},
 			upstreamNames: map[string]sets.Empty{
 				"test-1": {},
-				"test-2": {},
 			},
-			msg: "invalid action",
+			pathPrefix: "/abc",
+			msg:        "invalid prefix",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateVirtualServerRouteSubroutes(test.routes, field.NewPath("subroutes"), test.upstreamNames, test.pathPrefix)
+		if len(allErrs) == 0 {
+			t.Errorf("validateVirtualServerRouteSubroutes() returned no errors for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateUpstreamLBMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		isPlus bool
+	}{
+		{
+			method: "round_robin",
+			isPlus: false,
 		},
 		{
-			splits: []v1.Split{
-				{
-					Weight: 90,
-					Action: &v1.Action{
-						Pass: "some-upstream",
-					},
-				},
-				{
-					Weight: 10,
-					Action: &v1.Action{
-						Pass: "test-2",
-					},
-				},
-			},
-			upstreamNames: map[string]sets.Empty{
-				"test-1": {},
-				"test-2": {},
-			},
-			msg: "invalid action with non-existing upstream",
+			method: "",
+			isPlus: false,
+		},
+		{
+			method: "ip_hash",
+			isPlus: true,
+		},
+		{
+			method: "",
+			isPlus: true,
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateUpstreamLBMethod(test.method, field.NewPath("lb-method"), test.isPlus)
+
+		if len(allErrs) != 0 {
+			t.Errorf("validateUpstreamLBMethod(%q, %v) returned errors for method %s", test.method, test.isPlus, test.method)
+		}
+	}
+}
+
+func TestValidateUpstreamLBMethodFails(t *testing.T) {
+	tests := []struct {
+		method string
+		isPlus bool
+	}{
+		{
+			method: "wrong",
+			isPlus: false,
+		},
+		{
+			method: "wrong",
+			isPlus: true,
 		},
 	}
 
-	for _, test := range tests {
-		allErrs := validateSplits(test.splits, field.NewPath("splits"), test.upstreamNames, "")
+	for _, test := range tests {
+		allErrs := validateUpstreamLBMethod(test.method, field.NewPath("lb-method"), test.isPlus)
+
 		if len(allErrs) == 0 {
-			t.Errorf("validateSplits() returned no errors for invalid input for the case of %s", test.msg)
+			t.Errorf("validateUpstreamLBMethod(%q, %v) returned no errors for method %s", test.method, test.isPlus, test.method)
 		}
 	}
 }
 
-func TestValidateCondition(t *testing.T) {
+func createPointerFromBool(b bool) *bool {
+	return &b
+}
+
+func createPointerFromInt(n int) *int {
+	return &n
+}
+
+func TestValidatePositiveIntOrZeroFromPointer(t *testing.T) {
 	tests := []struct {
-		condition v1.Condition
-		msg       string
+		number *int
+		msg    string
 	}{
 		{
-			condition: v1.Condition{
-				Header: "x-version",
-				Value:  "v1",
-			},
-			msg: "valid header",
-		},
-		{
-			condition: v1.Condition{
-				Cookie: "my_cookie",
-				Value:  "",
-			},
-			msg: "valid cookie",
+			number: nil,
+			msg:    "valid (nil)",
 		},
 		{
-			condition: v1.Condition{
-				Argument: "arg",
-				Value:    "yes",
-			},
-			msg: "valid argument",
+			number: createPointerFromInt(0),
+			msg:    "valid (0)",
 		},
 		{
-			condition: v1.Condition{
-				Variable: "$request_method",
-				Value:    "POST",
-			},
-			msg: "valid variable",
+			number: createPointerFromInt(1),
+			msg:    "valid (1)",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateCondition(test.condition, field.NewPath("condition"))
-		if len(allErrs) > 0 {
-			t.Errorf("validateCondition() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		allErrs := validatePositiveIntOrZeroFromPointer(test.number, field.NewPath("int-field"))
+
+		if len(allErrs) != 0 {
+			t.Errorf("validatePositiveIntOrZeroFromPointer returned errors for case: %v", test.msg)
 		}
 	}
 }
 
-func TestValidateConditionFails(t *testing.T) {
+func TestValidatePositiveIntOrZeroFromPointerFails(t *testing.T) {
+	number := createPointerFromInt(-1)
+	allErrs := validatePositiveIntOrZeroFromPointer(number, field.NewPath("int-field"))
+
+	if len(allErrs) == 0 {
+		t.Error("validatePositiveIntOrZeroFromPointer returned no errors for case: invalid (-1)")
+	}
+}
+
+func TestValidatePositiveIntOrZero(t *testing.T) {
 	tests := []struct {
-		condition v1.Condition
-		msg       string
+		number int
+		msg    string
 	}{
 		{
-			condition: v1.Condition{},
-			msg:       "empty condition",
-		},
-		{
-			condition: v1.Condition{
-				Header:   "x-version",
-				Cookie:   "user",
-				Argument: "answer",
-				Variable: "$request_method",
-				Value:    "something",
-			},
-			msg: "invalid condition",
+			number: 0,
+			msg:    "valid (0)",
 		},
 		{
-			condition: v1.Condition{
-				Header: "x_version",
-			},
-			msg: "invalid header",
+			number: 1,
+			msg:    "valid (1)",
 		},
+	}
+
+	for _, test := range tests {
+		allErrs := validatePositiveIntOrZero(test.number, field.NewPath("int-field"))
+
+		if len(allErrs) != 0 {
+			t.Errorf("validatePositiveIntOrZero returned errors for case: %v", test.msg)
+		}
+	}
+}
+
+func TestValidatePositiveIntOrZeroFails(t *testing.T) {
+	allErrs := validatePositiveIntOrZero(-1, field.NewPath("int-field"))
+
+	if len(allErrs) == 0 {
+		t.Error("validatePositiveIntOrZero returned no errors for case: invalid (-1)")
+	}
+}
+
+func TestValidateTime(t *testing.T) {
+	time := "1h 2s"
+	allErrs := validateTime(time, field.NewPath("time-field"))
+
+	if len(allErrs) != 0 {
+		t.Errorf("validateTime returned errors %v valid input %v", allErrs, time)
+	}
+}
+
+func TestValidateOffset(t *testing.T) {
+	var validInput = []string{"", "1", "10k", "11m", "1K", "100M", "5G"}
+	for _, test := range validInput {
+		allErrs := validateOffset(test, field.NewPath("offset-field"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateOffset(%q) returned an error for valid input", test)
+		}
+	}
+
+	var invalidInput = []string{"55mm", "2mG", "6kb", "-5k", "1L", "5Gb"}
+	for _, test := range invalidInput {
+		allErrs := validateOffset(test, field.NewPath("offset-field"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateOffset(%q) didn't return error for invalid input.", test)
+		}
+	}
+}
+
+func TestValidateBuffer(t *testing.T) {
+	validbuff := &v1.UpstreamBuffers{Number: 8, Size: "8k"}
+	allErrs := validateBuffer(validbuff, field.NewPath("buffers-field"))
+
+	if len(allErrs) != 0 {
+		t.Errorf("validateBuffer returned errors %v valid input %v", allErrs, validbuff)
+	}
+
+	invalidbuff := []*v1.UpstreamBuffers{
 		{
-			condition: v1.Condition{
-				Cookie: "my-cookie",
-			},
-			msg: "invalid cookie",
+			Number: -8,
+			Size:   "15m",
 		},
 		{
-			condition: v1.Condition{
-				Argument: "my-arg",
-			},
-			msg: "invalid argument",
+			Number: 8,
+			Size:   "15G",
 		},
 		{
-			condition: v1.Condition{
-				Variable: "request_method",
-			},
-			msg: "invalid variable",
+			Number: 8,
+			Size:   "",
 		},
 	}
-
-	for _, test := range tests {
-		allErrs := validateCondition(test.condition, field.NewPath("condition"))
+	for _, test := range invalidbuff {
+		allErrs = validateBuffer(test, field.NewPath("buffers-field"))
 		if len(allErrs) == 0 {
-			t.Errorf("validateCondition() returned no errors for invalid input for the case of %s", test.msg)
+			t.Errorf("validateBuffer didn't return error for invalid input %v.", test)
 		}
 	}
 }
 
-func TestIsCookieName(t *testing.T) {
-	validCookieNames := []string{
-		"123",
-		"my_cookie",
+func TestValidateSize(t *testing.T) {
+	var validInput = []string{"", "4k", "8K", "16m", "32M"}
+	for _, test := range validInput {
+		allErrs := validateSize(test, field.NewPath("size-field"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateSize(%q) returned an error for valid input", test)
+		}
 	}
 
-	for _, name := range validCookieNames {
-		errs := isCookieName(name)
-		if len(errs) > 0 {
-			t.Errorf("isCookieName(%q) returned errors %v for valid input", name, errs)
+	var invalidInput = []string{"55mm", "2mG", "6kb", "-5k", "1L", "5G"}
+	for _, test := range invalidInput {
+		allErrs := validateSize(test, field.NewPath("size-field"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateSize(%q) didn't return error for invalid input.", test)
 		}
 	}
+}
+
+func TestValidateTimeFails(t *testing.T) {
+	time := "invalid"
+	allErrs := validateTime(time, field.NewPath("time-field"))
+
+	if len(allErrs) == 0 {
+		t.Errorf("validateTime returned no errors for invalid input %v", time)
+	}
+}
+
+func TestValidateUpstreamHealthCheck(t *testing.T) {
+	hc := &v1.HealthCheck{
+		Enable:   true,
+		Path:     "/healthz",
+		Interval: "4s",
+		Jitter:   "2s",
+		Fails:    3,
+		Passes:   2,
+		Port:     8080,
+		TLS: &v1.UpstreamTLS{
+			Enable: true,
+		},
+		ConnectTimeout: "1s",
+		ReadTimeout:    "1s",
+		SendTimeout:    "1s",
+		Headers: []v1.Header{
+			{
+				Name:  "Host",
+				Value: "my.service",
+			},
+		},
+		StatusMatch: "! 500",
+	}
+
+	allErrs := validateUpstreamHealthCheck(hc, field.NewPath("healthCheck"))
+
+	if len(allErrs) != 0 {
+		t.Errorf("validateUpstreamHealthCheck() returned errors for valid input %v", hc)
+	}
+}
+
+func TestValidateUpstreamHealthCheckFails(t *testing.T) {
+	tests := []struct {
+		hc *v1.HealthCheck
+	}{
+		{
+			hc: &v1.HealthCheck{
+				Enable: true,
+				Path:   "/healthz//;",
+			},
+		},
+		{
+			hc: &v1.HealthCheck{
+				Enable: false,
+				Path:   "/healthz//;",
+			},
+		},
+		{
+			hc: &v1.HealthCheck{
+				Enable: true,
+				Port:   -1,
+			},
+		},
+		{
+			hc: &v1.HealthCheck{
+				Enable: true,
+				Port:   65536,
+			},
+		},
+		{
+			hc: &v1.HealthCheck{
+				Enable: true,
+				TLS: &v1.UpstreamTLS{
+					Enable:    true,
+					Protocols: []string{"TLSv1.3"},
+				},
+			},
+		},
+	}
 
-	invalidCookieNames := []string{
-		"",
-		"my-cookie",
-		"cookie!",
-	}
+	for _, test := range tests {
+		allErrs := validateUpstreamHealthCheck(test.hc, field.NewPath("healthCheck"))
 
-	for _, name := range invalidCookieNames {
-		errs := isCookieName(name)
-		if len(errs) == 0 {
-			t.Errorf("isCookieName(%q) returned no errors for invalid input", name)
+		if len(allErrs) == 0 {
+			t.Errorf("validateUpstreamHealthCheck() returned no errors for invalid input %v", test.hc)
 		}
 	}
 }
 
-func TestIsArgumentName(t *testing.T) {
-	validArgumentNames := []string{
-		"123",
-		"my_arg",
-	}
-
-	for _, name := range validArgumentNames {
-		errs := isArgumentName(name)
-		if len(errs) > 0 {
-			t.Errorf("isArgumentName(%q) returned errors %v for valid input", name, errs)
-		}
-	}
-
-	invalidArgumentNames := []string{
-		"",
-		"my-arg",
-		"arg!",
+func TestValidateUpstreamTLS(t *testing.T) {
+	sessionReuse := false
+	tests := []v1.UpstreamTLS{
+		{},
+		{
+			Enable: true,
+		},
+		{
+			Enable:       true,
+			SessionReuse: &sessionReuse,
+			Protocols:    []string{"TLSv1.2", "TLSv1.3"},
+			Ciphers:      "HIGH:!aNULL:!MD5",
+		},
+		{
+			Enable:  true,
+			Ciphers: "ECDHE-RSA-AES256-GCM-SHA384:@SECLEVEL=2",
+		},
 	}
 
-	for _, name := range invalidArgumentNames {
-		errs := isArgumentName(name)
-		if len(errs) == 0 {
-			t.Errorf("isArgumentName(%q) returned no errors for invalid input", name)
+	for _, test := range tests {
+		allErrs := validateUpstreamTLS(test, field.NewPath("tls"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateUpstreamTLS(%+v) returned errors for valid input: %v", test, allErrs)
 		}
 	}
 }
 
-func TestValidateVariableName(t *testing.T) {
-	validNames := []string{
-		"$request_method",
-	}
-
-	for _, name := range validNames {
-		allErrs := validateVariableName(name, field.NewPath("variable"))
-		if len(allErrs) > 0 {
-			t.Errorf("validateVariableName(%q) returned errors %v for valid input", name, allErrs)
-		}
-	}
-
-	invalidNames := []string{
-		"request_method",
-		"$request_id",
+func TestValidateUpstreamTLSFails(t *testing.T) {
+	tests := []v1.UpstreamTLS{
+		{
+			Enable:    true,
+			Protocols: []string{"TLSv1.4"},
+		},
+		{
+			Enable:    true,
+			Protocols: []string{"tlsv1.2"},
+		},
+		{
+			Enable:    true,
+			Protocols: []string{"TLSv1.2", "TLSv1.2"},
+		},
+		{
+			Enable:    true,
+			Protocols: []string{"TLSv1.2 TLSv1.3"},
+		},
+		{
+			Enable:  true,
+			Ciphers: "HIGH; proxy_pass http://example.com",
+		},
+		{
+			Enable:  true,
+			Ciphers: `"HIGH"`,
+		},
 	}
 
-	for _, name := range invalidNames {
-		allErrs := validateVariableName(name, field.NewPath("variable"))
+	for _, test := range tests {
+		allErrs := validateUpstreamTLS(test, field.NewPath("tls"))
 		if len(allErrs) == 0 {
-			t.Errorf("validateVariableName(%q) returned no errors for invalid input", name)
+			t.Errorf("validateUpstreamTLS(%+v) returned no errors for invalid input", test)
 		}
 	}
 }
 
-func TestValidateMatch(t *testing.T) {
+func TestValidateStatusMatch(t *testing.T) {
 	tests := []struct {
-		match         v1.Match
-		upstreamNames sets.String
-		msg           string
+		status string
 	}{
 		{
-			match: v1.Match{
-				Conditions: []v1.Condition{
-					{
-						Cookie: "version",
-						Value:  "v1",
-					},
-				},
-				Action: &v1.Action{
-					Pass: "test",
-				},
-			},
-			upstreamNames: map[string]sets.Empty{
-				"test": {},
-			},
-			msg: "valid match with action",
+			status: "200",
 		},
 		{
-			match: v1.Match{
-				Conditions: []v1.Condition{
-					{
-						Cookie: "version",
-						Value:  "v1",
-					},
-				},
-				Splits: []v1.Split{
-					{
-						Weight: 90,
-						Action: &v1.Action{
-							Pass: "test-1",
-						},
-					},
-					{
-						Weight: 10,
-						Action: &v1.Action{
-							Pass: "test-2",
-						},
-					},
-				},
-			},
-			upstreamNames: map[string]sets.Empty{
-				"test-1": {},
-				"test-2": {},
-			},
-			msg: "valid match with splits",
+			status: "! 500",
+		},
+		{
+			status: "200 204",
+		},
+		{
+			status: "! 301 302",
+		},
+		{
+			status: "200-399",
+		},
+		{
+			status: "! 400-599",
+		},
+		{
+			status: "301-303 307",
 		},
 	}
-
 	for _, test := range tests {
-		allErrs := validateMatch(test.match, field.NewPath("match"), test.upstreamNames, "")
-		if len(allErrs) > 0 {
-			t.Errorf("validateMatch() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		allErrs := validateStatusMatch(test.status, field.NewPath("statusMatch"))
+
+		if len(allErrs) != 0 {
+			t.Errorf("validateStatusMatch() returned errors %v for valid input %v", allErrs, test.status)
 		}
 	}
 }
 
-func TestValidateMatchFails(t *testing.T) {
+func TestValidateStatusMatchFails(t *testing.T) {
 	tests := []struct {
-		match         v1.Match
-		upstreamNames sets.String
-		msg           string
+		status string
+		msg    string
 	}{
 		{
-			match: v1.Match{
-				Conditions: []v1.Condition{},
-				Action: &v1.Action{
-					Pass: "test",
-				},
-			},
-			upstreamNames: map[string]sets.Empty{
-				"test": {},
-			},
-			msg: "invalid number of conditions",
+			status: "qwe",
+			msg:    "Invalid: no digits",
 		},
 		{
-			match: v1.Match{
-				Conditions: []v1.Condition{
-					{
-						Cookie: "version",
-						Value:  `v1"`,
-					},
-				},
-				Action: &v1.Action{
-					Pass: "test",
-				},
-			},
-			upstreamNames: map[string]sets.Empty{
-				"test": {},
-			},
-			msg: "invalid condition",
+			status: "!",
+			msg:    "Invalid: `!` character only",
 		},
 		{
-			match: v1.Match{
-				Conditions: []v1.Condition{
-					{
-						Cookie: "version",
-						Value:  "v1",
-					},
-				},
-				Action: &v1.Action{},
-			},
-			upstreamNames: map[string]sets.Empty{},
-			msg:           "invalid  action",
+			status: "!500",
+			msg:    "Invalid: no space after !",
 		},
 		{
-			match: v1.Match{
-				Conditions: []v1.Condition{
-					{
-						Cookie: "version",
-						Value:  "v1",
-					},
-				},
-				Action: &v1.Action{
-					Pass: "test-1",
-				},
-				Splits: []v1.Split{
-					{
-						Weight: 90,
-						Action: &v1.Action{
-							Pass: "test-1",
-						},
-					},
-					{
-						Weight: 10,
-						Action: &v1.Action{
-							Pass: "test-2",
-						},
-					},
-				},
-			},
-			upstreamNames: map[string]sets.Empty{
-				"test-1": {},
-				"test-2": {},
-			},
-			msg: "both splits and action are set",
+			status: "0",
+			msg:    "Invalid: status out of range (below 100)",
+		},
+		{
+			status: "1000",
+			msg:    "Invalid: status out of range (above 999)",
+		},
+		{
+			status: "20-600",
+			msg:    "Invalid: code in range is out of range",
+		},
+		{
+			status: "! 200 ! 500",
+			msg:    "Invalid: 2 exclamation symbols",
+		},
+		{
+			status: "200 - 500",
+			msg:    "Invalid: range with space around `-`",
+		},
+		{
+			status: "500-200",
+			msg:    "Invalid: range must be min < max",
+		},
+		{
+			status: "200-200-400",
+			msg:    "Invalid: range with more than 2 numbers",
 		},
 	}
-
 	for _, test := range tests {
-		allErrs := validateMatch(test.match, field.NewPath("match"), test.upstreamNames, "")
+		allErrs := validateStatusMatch(test.status, field.NewPath("statusMatch"))
+
 		if len(allErrs) == 0 {
-			t.Errorf("validateMatch() returned no errors for invalid input for the case of %s", test.msg)
+			t.Errorf("validateStatusMatch() returned no errors for case %v", test.msg)
 		}
 	}
 }
 
-func TestIsValidMatchValue(t *testing.T) {
-	validValues := []string{
-		"abc",
-		"123",
-		`\"
-		abc\"`,
-		`\"`,
-	}
-
-	for _, value := range validValues {
-		errs := isValidMatchValue(value)
-		if len(errs) > 0 {
-			t.Errorf("isValidMatchValue(%q) returned errors %v for valid input", value, errs)
-		}
+func TestValidateHeader(t *testing.T) {
+	tests := []struct {
+		header v1.Header
+	}{
+		{
+			header: v1.Header{
+				Name:  "Host",
+				Value: "my.service",
+			},
+		},
+		{
+			header: v1.Header{
+				Name:  "Host",
+				Value: `\"my.service\"`,
+			},
+		},
 	}
 
-	invalidValues := []string{
-		`"`,
-		`\`,
-		`abc"`,
-		`abc\\\`,
-		`a"b`,
-	}
+	for _, test := range tests {
+		allErrs := validateHeader(test.header, field.NewPath("headers"))
 
-	for _, value := range invalidValues {
-		errs := isValidMatchValue(value)
-		if len(errs) == 0 {
-			t.Errorf("isValidMatchValue(%q) returned no errors for invalid input", value)
+		if len(allErrs) != 0 {
+			t.Errorf("validateHeader() returned errors %v for valid input %v", allErrs, test.header)
 		}
 	}
 }
 
-func TestValidateVirtualServerRoute(t *testing.T) {
-	virtualServerRoute := v1.VirtualServerRoute{
-		ObjectMeta: meta_v1.ObjectMeta{
-			Name:      "coffee",
-			Namespace: "default",
-		},
-		Spec: v1.VirtualServerRouteSpec{
-			Host: "example.com",
-			Upstreams: []v1.Upstream{
-				{
-					Name:    "first",
-					Service: "service-1",
-					Port:    80,
-				},
-				{
-					Name:    "second",
-					Service: "service-2",
-					Port:    80,
-				},
+func TestValidateHeaderFails(t *testing.T) {
+	tests := []struct {
+		header v1.Header
+		msg    string
+	}{
+		{
+			header: v1.Header{
+				Name:  "12378 qwe ",
+				Value: "my.service",
 			},
-			Subroutes: []v1.Route{
-				{
-					Path: "/test/first",
-					Action: &v1.Action{
-						Pass: "first",
-					},
-				},
-				{
-					Path: "/test/second",
-					Action: &v1.Action{
-						Pass: "second",
-					},
-				},
+			msg: "Invalid name with spaces",
+		},
+		{
+			header: v1.Header{
+				Name:  "Host",
+				Value: `"my.service`,
 			},
+			msg: `Invalid value with unescaped '"'`,
 		},
-	}
-	isPlus := false
-	err := ValidateVirtualServerRoute(&virtualServerRoute, isPlus)
-	if err != nil {
-		t.Errorf("ValidateVirtualServerRoute() returned error %v for valid input %v", err, virtualServerRoute)
-	}
-}
-
-func TestValidateVirtualServerRouteForVirtualServer(t *testing.T) {
-	virtualServerRoute := v1.VirtualServerRoute{
-		ObjectMeta: meta_v1.ObjectMeta{
-			Name:      "coffee",
-			Namespace: "default",
+		{
+			header: v1.Header{
+				Name:  "Host",
+				Value: `my.service\`,
+			},
+			msg: "Invalid value with ending '\\'",
 		},
-		Spec: v1.VirtualServerRouteSpec{
-			Host: "example.com",
-			Upstreams: []v1.Upstream{
-				{
-					Name:    "first",
-					Service: "service-1",
-					Port:    80,
-				},
-				{
-					Name:    "second",
-					Service: "service-2",
-					Port:    80,
-				},
+		{
+			header: v1.Header{
+				Name:  "Host",
+				Value: "$my.service",
 			},
-			Subroutes: []v1.Route{
-				{
-					Path: "/test/first",
-					Action: &v1.Action{
-						Pass: "first",
-					},
-				},
-				{
-					Path: "/test/second",
-					Action: &v1.Action{
-						Pass: "second",
-					},
-				},
+			msg: "Invalid value with '$' character",
+		},
+		{
+			header: v1.Header{
+				Name:  "Host",
+				Value: "my.\\$service",
 			},
+			msg: "Invalid value with escaped '$' character",
 		},
 	}
-	virtualServerHost := "example.com"
-	pathPrefix := "/test"
+	for _, test := range tests {
+		allErrs := validateHeader(test.header, field.NewPath("headers"))
 
-	isPlus := false
-	err := ValidateVirtualServerRouteForVirtualServer(&virtualServerRoute, virtualServerHost, pathPrefix, isPlus)
-	if err != nil {
-		t.Errorf("ValidateVirtualServerRouteForVirtualServer() returned error %v for valid input %v", err, virtualServerRoute)
+		if len(allErrs) == 0 {
+			t.Errorf("validateHeader() returned no errors for case: %v", test.msg)
+		}
 	}
 }
 
-func TestValidateVirtualServerRouteHost(t *testing.T) {
-	virtualServerHost := "example.com"
-
-	validHost := "example.com"
+func TestValidateIntFromString(t *testing.T) {
+	input := "404"
+	_, errMsg := validateIntFromString(input)
 
-	allErrs := validateVirtualServerRouteHost(validHost, virtualServerHost, field.NewPath("host"))
-	if len(allErrs) > 0 {
-		t.Errorf("validateVirtualServerRouteHost() returned errors %v for valid input", allErrs)
+	if errMsg != "" {
+		t.Errorf("validateIntFromString() returned errors %v for valid input %v", errMsg, input)
 	}
+}
 
-	invalidHost := "foo.example.com"
+func TestValidateIntFromStringFails(t *testing.T) {
+	input := "not a number"
+	_, errMsg := validateIntFromString(input)
 
-	allErrs = validateVirtualServerRouteHost(invalidHost, virtualServerHost, field.NewPath("host"))
-	if len(allErrs) == 0 {
-		t.Errorf("validateVirtualServerRouteHost() returned no errors for invalid input")
+	if errMsg == "" {
+		t.Errorf("validateIntFromString() returned no errors for invalid input %v", input)
 	}
 }
 
-func TestValidateVirtualServerRouteSubroutes(t *testing.T) {
+func TestRejectPlusResourcesInOSS(t *testing.T) {
 	tests := []struct {
-		routes        []v1.Route
-		upstreamNames sets.String
-		pathPrefix    string
-		msg           string
+		upstream *v1.Upstream
 	}{
 		{
-			routes:        []v1.Route{},
-			upstreamNames: sets.String{},
-			pathPrefix:    "/",
-			msg:           "no routes",
+			upstream: &v1.Upstream{
+				SlowStart: "10s",
+			},
 		},
 		{
-			routes: []v1.Route{
-				{
-					Path: "/",
-					Action: &v1.Action{
-						Pass: "test",
-					},
-				},
-			},
-			upstreamNames: map[string]sets.Empty{
-				"test": {},
+			upstream: &v1.Upstream{
+				HealthCheck: &v1.HealthCheck{},
 			},
-			pathPrefix: "/",
-			msg:        "valid route",
 		},
-	}
-
-	for _, test := range tests {
-		allErrs := validateVirtualServerRouteSubroutes(test.routes, field.NewPath("subroutes"), test.upstreamNames, test.pathPrefix)
-		if len(allErrs) > 0 {
-			t.Errorf("validateVirtualServerRouteSubroutes() returned errors %v for valid input for the case of %s", allErrs, test.msg)
-		}
-	}
-}
-
-func TestValidateVirtualServerRouteSubroutesFails(t *testing.T) {
-	tests := []struct {
-		routes        []v1.Route
-		upstreamNames sets.String
-		pathPrefix    string
-		msg           string
-	}{
 		{
-			routes: []v1.Route{
-				{
-					Path: "/test",
-					Action: &v1.Action{
-						Pass: "test-1",
-					},
-				},
-				{
-					Path: "/test",
-					Action: &v1.Action{
-						Pass: "test-2",
-					},
-				},
-			},
-			upstreamNames: map[string]sets.Empty{
-				"test-1": {},
-				"test-2": {},
+			upstream: &v1.Upstream{
+				SessionCookie: &v1.SessionCookie{},
 			},
-			pathPrefix: "/",
-			msg:        "duplicated paths",
 		},
-		{
-			routes: []v1.Route{
-				{
-					Path:   "",
-					Action: nil,
-				},
+		{
+			upstream: &v1.Upstream{
+				Queue: &v1.UpstreamQueue{},
 			},
-			upstreamNames: map[string]sets.Empty{},
-			pathPrefix:    "",
-			msg:           "invalid route",
 		},
 		{
-			routes: []v1.Route{
-				{
-					Path: "/",
-					Action: &v1.Action{
-						Pass: "test-1",
-					},
-				},
+			upstream: &v1.Upstream{
+				SRV: &v1.UpstreamSRV{},
 			},
-			upstreamNames: map[string]sets.Empty{
-				"test-1": {},
+		},
+		{
+			upstream: &v1.Upstream{
+				ResolverValid: "10s",
 			},
-			pathPrefix: "/abc",
-			msg:        "invalid prefix",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateVirtualServerRouteSubroutes(test.routes, field.NewPath("subroutes"), test.upstreamNames, test.pathPrefix)
-		if len(allErrs) == 0 {
-			t.Errorf("validateVirtualServerRouteSubroutes() returned no errors for the case of %s", test.msg)
+		allErrsOSS := rejectPlusResourcesInOSS(*test.upstream, field.NewPath("upstreams"), false)
+
+		if len(allErrsOSS) == 0 {
+			t.Errorf("rejectPlusResourcesInOSS() returned no errors for upstream: %v", test.upstream)
+		}
+
+		allErrsPlus := rejectPlusResourcesInOSS(*test.upstream, field.NewPath("upstreams"), true)
+
+		if len(allErrsPlus) != 0 {
+			t.Errorf("rejectPlusResourcesInOSS() returned no errors for upstream: %v", test.upstream)
 		}
 	}
 }
 
-func TestValidateUpstreamLBMethod(t *testing.T) {
+func TestValidateQueue(t *testing.T) {
 	tests := []struct {
-		method string
-		isPlus bool
+		upstreamQueue *v1.UpstreamQueue
+		msg           string
 	}{
 		{
-			method: "round_robin",
-			isPlus: false,
-		},
-		{
-			method: "",
-			isPlus: false,
+			upstreamQueue: &v1.UpstreamQueue{Size: 10, Timeout: "10s"},
+			msg:           "valid upstream queue with size and timeout",
 		},
 		{
-			method: "ip_hash",
-			isPlus: true,
+			upstreamQueue: nil,
+			msg:           "upstream queue nil",
 		},
 		{
-			method: "",
-			isPlus: true,
+			upstreamQueue: nil,
+			msg:           "upstream queue nil in OSS",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateUpstreamLBMethod(test.method, field.NewPath("lb-method"), test.isPlus)
-
+		allErrs := validateQueue(test.upstreamQueue, field.NewPath("queue"))
 		if len(allErrs) != 0 {
-			t.Errorf("validateUpstreamLBMethod(%q, %v) returned errors for method %s", test.method, test.isPlus, test.method)
+			t.Errorf("validateQueue() returned errors %v for valid input for the case of %s", allErrs, test.msg)
 		}
 	}
 }
 
-func TestValidateUpstreamLBMethodFails(t *testing.T) {
+func TestValidateQueueFails(t *testing.T) {
 	tests := []struct {
-		method string
-		isPlus bool
+		upstreamQueue *v1.UpstreamQueue
+		msg           string
 	}{
 		{
-			method: "wrong",
-			isPlus: false,
+			upstreamQueue: &v1.UpstreamQueue{Size: -1, Timeout: "10s"},
+			msg:           "upstream queue with invalid size",
 		},
 		{
-			method: "wrong",
-			isPlus: true,
+			upstreamQueue: &v1.UpstreamQueue{Size: 10, Timeout: "-10"},
+			msg:           "upstream queue with invalid timeout",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateUpstreamLBMethod(test.method, field.NewPath("lb-method"), test.isPlus)
-
+		allErrs := validateQueue(test.upstreamQueue, field.NewPath("queue"))
 		if len(allErrs) == 0 {
-			t.Errorf("validateUpstreamLBMethod(%q, %v) returned no errors for method %s", test.method, test.isPlus, test.method)
+			t.Errorf("validateQueue() returned no errors for invalid input for the case of %s", test.msg)
 		}
 	}
 }
 
-func createPointerFromInt(n int) *int {
-	return &n
-}
-
-func TestValidatePositiveIntOrZeroFromPointer(t *testing.T) {
+func TestValidateUpstreamCache(t *testing.T) {
 	tests := []struct {
-		number *int
-		msg    string
+		cache *v1.UpstreamCache
+		msg   string
 	}{
 		{
-			number: nil,
-			msg:    "valid (nil)",
+			cache: nil,
+			msg:   "cache nil",
 		},
 		{
-			number: createPointerFromInt(0),
-			msg:    "valid (0)",
+			cache: &v1.UpstreamCache{},
+			msg:   "cache with default parameters",
 		},
 		{
-			number: createPointerFromInt(1),
-			msg:    "valid (1)",
+			cache: &v1.UpstreamCache{ZoneSize: "20m", CacheLock: true, CacheLockTimeout: "10s", BackgroundUpdate: true},
+			msg:   "cache with all parameters",
+		},
+		{
+			cache: &v1.UpstreamCache{CacheLock: true},
+			msg:   "cache lock without timeout",
+		},
+		{
+			cache: &v1.UpstreamCache{Valid: map[string]string{"200": "10m", "404": "1m", "any": "5s"}},
+			msg:   "cache with validity times",
+		},
+		{
+			cache: &v1.UpstreamCache{Key: "${scheme}${host}${request_uri}${http_authorization}"},
+			msg:   "cache with a key with variables",
+		},
+		{
+			cache: &v1.UpstreamCache{Key: "${request_method} ${proxy_host}${uri}${cookie_session}"},
+			msg:   "cache with a key with a space",
+		},
+		{
+			cache: &v1.UpstreamCache{IgnoreHeaders: []string{"Set-Cookie", "Vary"}},
+			msg:   "cache with ignored headers",
+		},
+		{
+			cache: &v1.UpstreamCache{Valid: map[string]string{"200": "10m"}, OverrideCacheControl: true},
+			msg:   "cache that overrides the cache control",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validatePositiveIntOrZeroFromPointer(test.number, field.NewPath("int-field"))
-
+		allErrs := validateUpstreamCache(test.cache, field.NewPath("cache"))
 		if len(allErrs) != 0 {
-			t.Errorf("validatePositiveIntOrZeroFromPointer returned errors for case: %v", test.msg)
+			t.Errorf("validateUpstreamCache() returned errors %v for valid input for the case of %s", allErrs, test.msg)
 		}
 	}
 }
 
-func TestValidatePositiveIntOrZeroFromPointerFails(t *testing.T) {
-	number := createPointerFromInt(-1)
-	allErrs := validatePositiveIntOrZeroFromPointer(number, field.NewPath("int-field"))
-
-	if len(allErrs) == 0 {
-		t.Error("validatePositiveIntOrZeroFromPointer returned no errors for case: invalid (-1)")
-	}
-}
-
-func TestValidatePositiveIntOrZero(t *testing.T) {
+func TestValidateUpstreamCacheFails(t *testing.T) {
 	tests := []struct {
-		number int
-		msg    string
+		cache *v1.UpstreamCache
+		msg   string
 	}{
 		{
-			number: 0,
-			msg:    "valid (0)",
+			cache: &v1.UpstreamCache{ZoneSize: "10z"},
+			msg:   "cache with invalid zone size",
 		},
 		{
-			number: 1,
-			msg:    "valid (1)",
+			cache: &v1.UpstreamCache{CacheLock: true, CacheLockTimeout: "-10"},
+			msg:   "cache with invalid lock timeout",
+		},
+		{
+			cache: &v1.UpstreamCache{CacheLockTimeout: "10s"},
+			msg:   "cache lock timeout without cache lock",
+		},
+		{
+			cache: &v1.UpstreamCache{Valid: map[string]string{"600": "10m"}},
+			msg:   "cache with an invalid status code",
+		},
+		{
+			cache: &v1.UpstreamCache{Valid: map[string]string{"+200": "10m"}},
+			msg:   "cache with a status code with a sign",
+		},
+		{
+			cache: &v1.UpstreamCache{Valid: map[string]string{"all": "10m"}},
+			msg:   "cache with an invalid code",
+		},
+		{
+			cache: &v1.UpstreamCache{Valid: map[string]string{"200": ""}},
+			msg:   "cache with an empty validity time",
+		},
+		{
+			cache: &v1.UpstreamCache{Valid: map[string]string{"200": "10 minutes"}},
+			msg:   "cache with an invalid validity time",
+		},
+		{
+			cache: &v1.UpstreamCache{Key: "${host}${upstream_addr}"},
+			msg:   "cache with a key with an unsupported variable",
+		},
+		{
+			cache: &v1.UpstreamCache{Key: "$host$request_uri"},
+			msg:   "cache with a key with variables without curly braces",
+		},
+		{
+			cache: &v1.UpstreamCache{Key: `${host}"${request_uri}`},
+			msg:   "cache with a key with an unescaped double quote",
+		},
+		{
+			cache: &v1.UpstreamCache{Key: "${host}${http_x-user}"},
+			msg:   "cache with a key with an invalid header variable",
+		},
+		{
+			cache: &v1.UpstreamCache{IgnoreHeaders: []string{"Set-Cookie", "Authorization"}},
+			msg:   "cache with an ignored header not supported by NGINX",
+		},
+		{
+			cache: &v1.UpstreamCache{OverrideCacheControl: true},
+			msg:   "cache that overrides the cache control without caching times",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validatePositiveIntOrZero(test.number, field.NewPath("int-field"))
-
-		if len(allErrs) != 0 {
-			t.Errorf("validatePositiveIntOrZero returned errors for case: %v", test.msg)
+		allErrs := validateUpstreamCache(test.cache, field.NewPath("cache"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateUpstreamCache() returned no errors for invalid input for the case of %s", test.msg)
 		}
 	}
 }
 
-func TestValidatePositiveIntOrZeroFails(t *testing.T) {
-	allErrs := validatePositiveIntOrZero(-1, field.NewPath("int-field"))
-
-	if len(allErrs) == 0 {
-		t.Error("validatePositiveIntOrZero returned no errors for case: invalid (-1)")
-	}
-}
-
-func TestValidateTime(t *testing.T) {
-	time := "1h 2s"
-	allErrs := validateTime(time, field.NewPath("time-field"))
+func TestValidateSessionCookie(t *testing.T) {
+	tests := []struct {
+		sc  *v1.SessionCookie
+		msg string
+	}{
+		{
+			sc:  &v1.SessionCookie{Enable: true, Name: "min"},
+			msg: "min valid config",
+		},
+		{
+			sc:  &v1.SessionCookie{Enable: true, Name: "test", Expires: "max"},
+			msg: "valid config with expires max",
+		},
+		{
+			sc: &v1.SessionCookie{
+				Enable: true, Name: "test", Path: "/tea", Expires: "1", Domain: ".example.com", HTTPOnly: false, Secure: true,
+			},
 
-	if len(allErrs) != 0 {
-		t.Errorf("validateTime returned errors %v valid input %v", allErrs, time)
+			msg: "max valid config",
+		},
 	}
-}
-
-func TestValidateOffset(t *testing.T) {
-	var validInput = []string{"", "1", "10k", "11m", "1K", "100M", "5G"}
-	for _, test := range validInput {
-		allErrs := validateOffset(test, field.NewPath("offset-field"))
+	for _, test := range tests {
+		allErrs := validateSessionCookie(test.sc, field.NewPath("sessionCookie"))
 		if len(allErrs) != 0 {
-			t.Errorf("validateOffset(%q) returned an error for valid input", test)
-		}
-	}
-
-	var invalidInput = []string{"55mm", "2mG", "6kb", "-5k", "1L", "5Gb"}
-	for _, test := range invalidInput {
-		allErrs := validateOffset(test, field.NewPath("offset-field"))
-		if len(allErrs) == 0 {
-			t.Errorf("validateOffset(%q) didn't return error for invalid input.", test)
+			t.Errorf("validateSessionCookie() returned errors %v for valid input for the case of: %s", allErrs, test.msg)
 		}
 	}
 }
 
-func TestValidateBuffer(t *testing.T) {
-	validbuff := &v1.UpstreamBuffers{Number: 8, Size: "8k"}
-	allErrs := validateBuffer(validbuff, field.NewPath("buffers-field"))
-
-	if len(allErrs) != 0 {
-		t.Errorf("validateBuffer returned errors %v valid input %v", allErrs, validbuff)
-	}
-
-	invalidbuff := []*v1.UpstreamBuffers{
+func TestValidateSessionCookieFails(t *testing.T) {
+	tests := []struct {
+		sc  *v1.SessionCookie
+		msg string
+	}{
 		{
-			Number: -8,
-			Size:   "15m",
+			sc:  &v1.SessionCookie{Enable: true},
+			msg: "missing required field: Name",
 		},
 		{
-			Number: 8,
-			Size:   "15G",
+			sc:  &v1.SessionCookie{Enable: false},
+			msg: "session cookie not enabled",
 		},
 		{
-			Number: 8,
-			Size:   "",
+			sc:  &v1.SessionCookie{Enable: true, Name: "$ecret-Name"},
+			msg: "invalid name format",
 		},
-	}
-	for _, test := range invalidbuff {
-		allErrs = validateBuffer(test, field.NewPath("buffers-field"))
-		if len(allErrs) == 0 {
-			t.Errorf("validateBuffer didn't return error for invalid input %v.", test)
-		}
-	}
-}
-
-func TestValidateSize(t *testing.T) {
-	var validInput = []string{"", "4k", "8K", "16m", "32M"}
-	for _, test := range validInput {
-		allErrs := validateSize(test, field.NewPath("size-field"))
-		if len(allErrs) != 0 {
-			t.Errorf("validateSize(%q) returned an error for valid input", test)
-		}
-	}
-
-	var invalidInput = []string{"55mm", "2mG", "6kb", "-5k", "1L", "5G"}
-	for _, test := range invalidInput {
-		allErrs := validateSize(test, field.NewPath("size-field"))
+		{
+			sc:  &v1.SessionCookie{Enable: true, Name: "test", Expires: "EGGS"},
+			msg: "invalid time format",
+		},
+		{
+			sc:  &v1.SessionCookie{Enable: true, Name: "test", Path: "/ coffee"},
+			msg: "invalid path format",
+		},
+	}
+	for _, test := range tests {
+		allErrs := validateSessionCookie(test.sc, field.NewPath("sessionCookie"))
 		if len(allErrs) == 0 {
-			t.Errorf("validateSize(%q) didn't return error for invalid input.", test)
+			t.Errorf("validateSessionCookie() returned no errors for invalid input for the case of: %v", test.msg)
 		}
 	}
 }
 
-func TestValidateTimeFails(t *testing.T) {
-	time := "invalid"
-	allErrs := validateTime(time, field.NewPath("time-field"))
-
-	if len(allErrs) == 0 {
-		t.Errorf("validateTime returned no errors for invalid input %v", time)
-	}
-}
-
-func TestValidateUpstreamHealthCheck(t *testing.T) {
-	hc := &v1.HealthCheck{
-		Enable:   true,
-		Path:     "/healthz",
-		Interval: "4s",
-		Jitter:   "2s",
-		Fails:    3,
-		Passes:   2,
-		Port:     8080,
-		TLS: &v1.UpstreamTLS{
-			Enable: true,
+func TestValidateUpstreamSRV(t *testing.T) {
+	tests := []struct {
+		srv *v1.UpstreamSRV
+		msg string
+	}{
+		{
+			srv: nil,
+			msg: "srv not set",
 		},
-		ConnectTimeout: "1s",
-		ReadTimeout:    "1s",
-		SendTimeout:    "1s",
-		Headers: []v1.Header{
-			{
-				Name:  "Host",
-				Value: "my.service",
-			},
+		{
+			srv: &v1.UpstreamSRV{Host: "tea-svc.default.svc.cluster.local", Service: "http"},
+			msg: "service name",
+		},
+		{
+			srv: &v1.UpstreamSRV{Host: "tea-svc.default.svc.cluster.local", Service: "_http._tcp"},
+			msg: "prefix of the SRV records",
 		},
-		StatusMatch: "! 500",
 	}
-
-	allErrs := validateUpstreamHealthCheck(hc, field.NewPath("healthCheck"))
-
-	if len(allErrs) != 0 {
-		t.Errorf("validateUpstreamHealthCheck() returned errors for valid input %v", hc)
+	for _, test := range tests {
+		allErrs := validateUpstreamSRV(test.srv, field.NewPath("srv"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateUpstreamSRV() returned errors %v for valid input for the case of: %s", allErrs, test.msg)
+		}
 	}
 }
 
-func TestValidateUpstreamHealthCheckFails(t *testing.T) {
+func TestValidateUpstreamSRVFails(t *testing.T) {
 	tests := []struct {
-		hc *v1.HealthCheck
+		srv *v1.UpstreamSRV
+		msg string
 	}{
 		{
-			hc: &v1.HealthCheck{
-				Enable: true,
-				Path:   "/healthz//;",
-			},
+			srv: &v1.UpstreamSRV{Service: "http"},
+			msg: "missing required field: Host",
 		},
 		{
-			hc: &v1.HealthCheck{
-				Enable: false,
-				Path:   "/healthz//;",
-			},
+			srv: &v1.UpstreamSRV{Host: "tea-svc.default.svc.cluster.local"},
+			msg: "missing required field: Service",
+		},
+		{
+			srv: &v1.UpstreamSRV{Host: "tea_svc", Service: "http"},
+			msg: "invalid host",
+		},
+		{
+			srv: &v1.UpstreamSRV{Host: "tea-svc.default.svc.cluster.local", Service: "_http.."},
+			msg: "invalid service",
+		},
+		{
+			srv: &v1.UpstreamSRV{Host: "tea-svc.default.svc.cluster.local", Service: "http resolve"},
+			msg: "service with a space",
 		},
 	}
-
 	for _, test := range tests {
-		allErrs := validateUpstreamHealthCheck(test.hc, field.NewPath("healthCheck"))
-
+		allErrs := validateUpstreamSRV(test.srv, field.NewPath("srv"))
 		if len(allErrs) == 0 {
-			t.Errorf("validateUpstreamHealthCheck() returned no errors for invalid input %v", test.hc)
+			t.Errorf("validateUpstreamSRV() returned no errors for invalid input for the case of: %v", test.msg)
 		}
 	}
 }
 
-func TestValidateStatusMatch(t *testing.T) {
+func createOIDC() *v1.OIDC {
+	return &v1.OIDC{
+		ClientID:      "nginx-plus",
+		ClientSecret:  "oidc-secret",
+		AuthEndpoint:  "https://idp.example.com/auth",
+		TokenEndpoint: "https://idp.example.com/token",
+		JWKSURI:       "https://idp.example.com/certs",
+	}
+}
+
+func TestValidateOIDC(t *testing.T) {
+	withScope := createOIDC()
+	withScope.Scope = "openid+profile+email"
+
+	withRedirectURI := createOIDC()
+	withRedirectURI.RedirectURI = "/oidc/callback"
+
+	withFailOpen := createOIDC()
+	withFailOpen.FailureMode = "open"
+
+	withFailClosed := createOIDC()
+	withFailClosed.FailureMode = "closed"
+
 	tests := []struct {
-		status string
+		oidc *v1.OIDC
+		msg  string
 	}{
 		{
-			status: "200",
-		},
-		{
-			status: "! 500",
+			oidc: nil,
+			msg:  "no oidc",
 		},
 		{
-			status: "200 204",
+			oidc: createOIDC(),
+			msg:  "required fields",
 		},
 		{
-			status: "! 301 302",
+			oidc: withScope,
+			msg:  "custom scope",
 		},
 		{
-			status: "200-399",
+			oidc: withRedirectURI,
+			msg:  "custom redirect uri",
 		},
 		{
-			status: "! 400-599",
+			oidc: withFailOpen,
+			msg:  "open failure mode",
 		},
 		{
-			status: "301-303 307",
+			oidc: withFailClosed,
+			msg:  "closed failure mode",
 		},
 	}
-	for _, test := range tests {
-		allErrs := validateStatusMatch(test.status, field.NewPath("statusMatch"))
 
+	for _, test := range tests {
+		allErrs := validateOIDC(test.oidc, field.NewPath("oidc"), true)
 		if len(allErrs) != 0 {
-			t.Errorf("validateStatusMatch() returned errors %v for valid input %v", allErrs, test.status)
+			t.Errorf("validateOIDC() returned errors %v for valid input for the case of %s", allErrs, test.msg)
 		}
 	}
 }
 
-func TestValidateStatusMatchFails(t *testing.T) {
+func TestValidateOIDCFails(t *testing.T) {
 	tests := []struct {
-		status string
+		modify func(oidc *v1.OIDC)
+		isPlus bool
 		msg    string
 	}{
 		{
-			status: "qwe",
-			msg:    "Invalid: no digits",
+			modify: func(oidc *v1.OIDC) {},
+			isPlus: false,
+			msg:    "oidc in OSS",
 		},
 		{
-			status: "!",
-			msg:    "Invalid: `!` character only",
+			modify: func(oidc *v1.OIDC) { oidc.ClientID = "" },
+			isPlus: true,
+			msg:    "missing client id",
 		},
 		{
-			status: "!500",
-			msg:    "Invalid: no space after !",
+			modify: func(oidc *v1.OIDC) { oidc.ClientID = "nginx plus" },
+			isPlus: true,
+			msg:    "client id with a space",
 		},
 		{
-			status: "0",
-			msg:    "Invalid: status out of range (below 100)",
+			modify: func(oidc *v1.OIDC) { oidc.ClientSecret = "" },
+			isPlus: true,
+			msg:    "missing client secret",
 		},
 		{
-			status: "1000",
-			msg:    "Invalid: status out of range (above 999)",
+			modify: func(oidc *v1.OIDC) { oidc.ClientSecret = "oidc_secret" },
+			isPlus: true,
+			msg:    "invalid client secret",
 		},
 		{
-			status: "20-600",
-			msg:    "Invalid: code in range is out of range",
+			modify: func(oidc *v1.OIDC) { oidc.AuthEndpoint = "" },
+			isPlus: true,
+			msg:    "missing auth endpoint",
 		},
 		{
-			status: "! 200 ! 500",
-			msg:    "Invalid: 2 exclamation symbols",
+			modify: func(oidc *v1.OIDC) { oidc.TokenEndpoint = "idp.example.com/token" },
+			isPlus: true,
+			msg:    "token endpoint without a scheme",
 		},
 		{
-			status: "200 - 500",
-			msg:    "Invalid: range with space around `-`",
+			modify: func(oidc *v1.OIDC) { oidc.JWKSURI = "https://idp.example.com/certs?$arg" },
+			isPlus: true,
+			msg:    "jwks uri with a variable",
 		},
 		{
-			status: "500-200",
-			msg:    "Invalid: range must be min < max",
+			modify: func(oidc *v1.OIDC) { oidc.Scope = "profile" },
+			isPlus: true,
+			msg:    "scope without openid",
 		},
 		{
-			status: "200-200-400",
-			msg:    "Invalid: range with more than 2 numbers",
+			modify: func(oidc *v1.OIDC) { oidc.Scope = "openid profile" },
+			isPlus: true,
+			msg:    "scopes separated with a space",
+		},
+		{
+			modify: func(oidc *v1.OIDC) { oidc.RedirectURI = "callback" },
+			isPlus: true,
+			msg:    "redirect uri without /",
+		},
+		{
+			modify: func(oidc *v1.OIDC) { oidc.RedirectURI = "/logout" },
+			isPlus: true,
+			msg:    "redirect uri of an oidc location",
+		},
+		{
+			modify: func(oidc *v1.OIDC) { oidc.FailureMode = "allow" },
+			isPlus: true,
+			msg:    "invalid failure mode",
 		},
 	}
+
 	for _, test := range tests {
-		allErrs := validateStatusMatch(test.status, field.NewPath("statusMatch"))
+		oidc := createOIDC()
+		test.modify(oidc)
 
+		allErrs := validateOIDC(oidc, field.NewPath("oidc"), test.isPlus)
 		if len(allErrs) == 0 {
-			t.Errorf("validateStatusMatch() returned no errors for case %v", test.msg)
+			t.Errorf("validateOIDC() returned no errors for invalid input for the case of %s", test.msg)
 		}
 	}
 }
 
-func TestValidateHeader(t *testing.T) {
+func TestValidateAccessLog(t *testing.T) {
 	tests := []struct {
-		header v1.Header
+		accessLog *v1.AccessLog
+		isPlus    bool
+		msg       string
 	}{
 		{
-			header: v1.Header{
-				Name:  "Host",
-				Value: "my.service",
+			accessLog: nil,
+			msg:       "no access log",
+		},
+		{
+			accessLog: &v1.AccessLog{
+				Fields: []v1.AccessLogField{
+					{Name: "user", Variable: "$http_x_user"},
+					{Name: "session", Variable: "$cookie_session"},
+					{Name: "cache", Variable: "$upstream_http_x_cache"},
+					{Name: "upstream_time", Variable: "$upstream_response_time"},
+				},
 			},
+			msg: "valid fields",
 		},
 		{
-			header: v1.Header{
-				Name:  "Host",
-				Value: `\"my.service\"`,
+			accessLog: &v1.AccessLog{
+				Fields: []v1.AccessLogField{
+					{Name: "jwt_sub", Variable: "$jwt_claim_sub"},
+				},
 			},
+			isPlus: true,
+			msg:    "jwt claim in NGINX Plus",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateHeader(test.header, field.NewPath("headers"))
-
+		allErrs := validateAccessLog(test.accessLog, field.NewPath("accessLog"), test.isPlus)
 		if len(allErrs) != 0 {
-			t.Errorf("validateHeader() returned errors %v for valid input %v", allErrs, test.header)
+			t.Errorf("validateAccessLog() returned errors %v for valid input for the case of %s", allErrs, test.msg)
 		}
 	}
 }
 
-func TestValidateHeaderFails(t *testing.T) {
+func TestValidateAccessLogFails(t *testing.T) {
 	tests := []struct {
-		header v1.Header
+		fields []v1.AccessLogField
+		isPlus bool
 		msg    string
 	}{
 		{
-			header: v1.Header{
-				Name:  "12378 qwe ",
-				Value: "my.service",
-			},
-			msg: "Invalid name with spaces",
+			fields: []v1.AccessLogField{{Variable: "$host"}},
+			msg:    "missing name",
 		},
 		{
-			header: v1.Header{
-				Name:  "Host",
-				Value: `"my.service`,
-			},
-			msg: `Invalid value with unescaped '"'`,
+			fields: []v1.AccessLogField{{Name: "1user", Variable: "$host"}},
+			msg:    "invalid name",
 		},
 		{
-			header: v1.Header{
-				Name:  "Host",
-				Value: `my.service\`,
-			},
-			msg: "Invalid value with ending '\\'",
+			fields: []v1.AccessLogField{{Name: "user", Variable: "$host"}, {Name: "user", Variable: "$uri"}},
+			msg:    "duplicated name",
 		},
 		{
-			header: v1.Header{
-				Name:  "Host",
-				Value: "$my.service",
-			},
-			msg: "Invalid value with '$' character",
+			fields: []v1.AccessLogField{{Name: "user"}},
+			msg:    "missing variable",
 		},
 		{
-			header: v1.Header{
-				Name:  "Host",
-				Value: "my.\\$service",
-			},
-			msg: "Invalid value with escaped '$' character",
+			fields: []v1.AccessLogField{{Name: "user", Variable: "host"}},
+			msg:    "variable without $",
+		},
+		{
+			fields: []v1.AccessLogField{{Name: "user", Variable: "$request_body"}},
+			msg:    "variable not allowed",
+		},
+		{
+			fields: []v1.AccessLogField{{Name: "user", Variable: "$http_x-user"}},
+			msg:    "invalid header variable",
+		},
+		{
+			fields: []v1.AccessLogField{{Name: "cache", Variable: "$sent_http_x-cache"}},
+			msg:    "invalid response header variable",
+		},
+		{
+			fields: []v1.AccessLogField{{Name: "jwt_sub", Variable: "$jwt_claim_sub"}},
+			isPlus: false,
+			msg:    "jwt claim in OSS",
+		},
+		{
+			fields: []v1.AccessLogField{{Name: "jwt_sub", Variable: "$jwt_claim_s-ub"}},
+			isPlus: true,
+			msg:    "invalid jwt claim",
 		},
 	}
-	for _, test := range tests {
-		allErrs := validateHeader(test.header, field.NewPath("headers"))
 
+	for _, test := range tests {
+		allErrs := validateAccessLog(&v1.AccessLog{Fields: test.fields}, field.NewPath("accessLog"), test.isPlus)
 		if len(allErrs) == 0 {
-			t.Errorf("validateHeader() returned no errors for case: %v", test.msg)
+			t.Errorf("validateAccessLog() returned no errors for invalid input for the case of %s", test.msg)
 		}
 	}
 }
 
-func TestValidateIntFromString(t *testing.T) {
-	input := "404"
-	_, errMsg := validateIntFromString(input)
-
-	if errMsg != "" {
-		t.Errorf("validateIntFromString() returned errors %v for valid input %v", errMsg, input)
+func TestValidateUpstreamServices(t *testing.T) {
+	tests := []struct {
+		upstream v1.Upstream
+		msg      string
+	}{
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80},
+			msg:      "no services",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Services: []string{"tea-v2-svc"}, Port: 80},
+			msg:      "one service",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Services: []string{"tea-v2-svc", "tea-v3-svc"}, Port: 80},
+			msg:      "several services",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Services: []string{"tea-v2-svc"}, Port: 80, Backup: "tea-v2-svc", BackupPort: 8080},
+			msg:      "backup is a service with a different port",
+		},
 	}
-}
-
-func TestValidateIntFromStringFails(t *testing.T) {
-	input := "not a number"
-	_, errMsg := validateIntFromString(input)
 
-	if errMsg == "" {
-		t.Errorf("validateIntFromString() returned no errors for invalid input %v", input)
+	for _, test := range tests {
+		allErrs := validateUpstreamServices(test.upstream, field.NewPath("upstreams").Index(0).Child("services"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateUpstreamServices() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
 	}
 }
 
-func TestRejectPlusResourcesInOSS(t *testing.T) {
+func TestValidateUpstreamServicesFails(t *testing.T) {
 	tests := []struct {
-		upstream *v1.Upstream
+		upstream v1.Upstream
+		msg      string
 	}{
 		{
-			upstream: &v1.Upstream{
-				SlowStart: "10s",
-			},
+			upstream: v1.Upstream{Service: "tea-svc", Services: []string{"tea_v2_svc"}, Port: 80},
+			msg:      "invalid service name",
 		},
 		{
-			upstream: &v1.Upstream{
-				HealthCheck: &v1.HealthCheck{},
-			},
+			upstream: v1.Upstream{Service: "tea-svc", Services: []string{"tea-svc"}, Port: 80},
+			msg:      "service of the upstream",
 		},
 		{
-			upstream: &v1.Upstream{
-				SessionCookie: &v1.SessionCookie{},
-			},
+			upstream: v1.Upstream{Service: "tea-svc", Services: []string{"tea-v2-svc", "tea-v2-svc"}, Port: 80},
+			msg:      "duplicated service",
 		},
 		{
-			upstream: &v1.Upstream{
-				Queue: &v1.UpstreamQueue{},
-			},
+			upstream: v1.Upstream{Service: "tea-svc", Services: []string{"tea-v2-svc"}, Port: 80, Backup: "tea-v2-svc", BackupPort: 80},
+			msg:      "backup is a service with the same port",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Services: []string{"tea-v2-svc"}, Port: 80, Subselector: map[string]string{"version": "v1"}},
+			msg:      "services with subselector",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Services: []string{"tea-v2-svc"}, Port: 80, SRV: &v1.UpstreamSRV{Host: "tea-svc.default.svc.cluster.local", Service: "http"}},
+			msg:      "services with srv",
 		},
 	}
 
 	for _, test := range tests {
-		allErrsOSS := rejectPlusResourcesInOSS(*test.upstream, field.NewPath("upstreams"), false)
-
-		if len(allErrsOSS) == 0 {
-			t.Errorf("rejectPlusResourcesInOSS() returned no errors for upstream: %v", test.upstream)
-		}
-
-		allErrsPlus := rejectPlusResourcesInOSS(*test.upstream, field.NewPath("upstreams"), true)
-
-		if len(allErrsPlus) != 0 {
-			t.Errorf("rejectPlusResourcesInOSS() returned no errors for upstream: %v", test.upstream)
+		allErrs := validateUpstreamServices(test.upstream, field.NewPath("upstreams").Index(0).Child("services"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateUpstreamServices() returned no errors for invalid input for the case of %s", test.msg)
 		}
 	}
 }
 
-func TestValidateQueue(t *testing.T) {
+func TestValidateUpstreamBackup(t *testing.T) {
 	tests := []struct {
-		upstreamQueue *v1.UpstreamQueue
-		msg           string
+		upstream v1.Upstream
+		msg      string
 	}{
 		{
-			upstreamQueue: &v1.UpstreamQueue{Size: 10, Timeout: "10s"},
-			msg:           "valid upstream queue with size and timeout",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80},
+			msg:      "no backup",
 		},
 		{
-			upstreamQueue: nil,
-			msg:           "upstream queue nil",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Backup: "backup-svc", BackupPort: 80},
+			msg:      "backup service",
 		},
 		{
-			upstreamQueue: nil,
-			msg:           "upstream queue nil in OSS",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Backup: "tea-svc", BackupPort: 8080},
+			msg:      "same service with a different port",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Backup: "backup-svc", BackupPort: 80, LBMethod: "least_conn"},
+			msg:      "backup service with a compatible lb method",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateQueue(test.upstreamQueue, field.NewPath("queue"))
+		allErrs := validateUpstreamBackup(test.upstream, field.NewPath("upstreams").Index(0))
 		if len(allErrs) != 0 {
-			t.Errorf("validateQueue() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+			t.Errorf("validateUpstreamBackup() returned errors %v for valid input for the case of %s", allErrs, test.msg)
 		}
 	}
 }
 
-func TestValidateQueueFails(t *testing.T) {
+func TestValidateUpstreamBackupFails(t *testing.T) {
 	tests := []struct {
-		upstreamQueue *v1.UpstreamQueue
-		msg           string
+		upstream v1.Upstream
+		msg      string
 	}{
 		{
-			upstreamQueue: &v1.UpstreamQueue{Size: -1, Timeout: "10s"},
-			msg:           "upstream queue with invalid size",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, BackupPort: 80},
+			msg:      "backup-port without backup",
 		},
 		{
-			upstreamQueue: &v1.UpstreamQueue{Size: 10, Timeout: "-10"},
-			msg:           "upstream queue with invalid timeout",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Backup: "backup-svc"},
+			msg:      "missing backup-port",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Backup: "backup_svc", BackupPort: 80},
+			msg:      "invalid backup service name",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Backup: "tea-svc", BackupPort: 80},
+			msg:      "backup is the primary service",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Backup: "backup-svc", BackupPort: 80, SRV: &v1.UpstreamSRV{Host: "tea-svc.default.svc.cluster.local", Service: "http"}},
+			msg:      "backup with srv",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Backup: "backup-svc", BackupPort: 80, LBMethod: "ip_hash"},
+			msg:      "backup with ip_hash",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Backup: "backup-svc", BackupPort: 80, LBMethod: "hash $request_uri consistent"},
+			msg:      "backup with hash",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Backup: "backup-svc", BackupPort: 80, LBMethod: "random"},
+			msg:      "backup with random",
 		},
 	}
 
 	for _, test := range tests {
-		allErrs := validateQueue(test.upstreamQueue, field.NewPath("queue"))
+		allErrs := validateUpstreamBackup(test.upstream, field.NewPath("upstreams").Index(0))
 		if len(allErrs) == 0 {
-			t.Errorf("validateQueue() returned no errors for invalid input for the case of %s", test.msg)
+			t.Errorf("validateUpstreamBackup() returned no errors for invalid input for the case of %s", test.msg)
 		}
 	}
 }
 
-func TestValidateSessionCookie(t *testing.T) {
+func TestValidateUpstreamDown(t *testing.T) {
 	tests := []struct {
-		sc  *v1.SessionCookie
-		msg string
+		upstream v1.Upstream
+		msg      string
 	}{
 		{
-			sc:  &v1.SessionCookie{Enable: true, Name: "min"},
-			msg: "min valid config",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80},
+			msg:      "no down",
 		},
 		{
-			sc:  &v1.SessionCookie{Enable: true, Name: "test", Expires: "max"},
-			msg: "valid config with expires max",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Down: []string{"10.0.0.1"}},
+			msg:      "ip",
 		},
 		{
-			sc: &v1.SessionCookie{
-				Enable: true, Name: "test", Path: "/tea", Expires: "1", Domain: ".example.com", HTTPOnly: false, Secure: true,
-			},
-
-			msg: "max valid config",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Down: []string{"10.0.0.1:8080", "10.0.0.2"}},
+			msg:      "ip with port and ip",
+		},
+		{
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Down: []string{"fd00::1", "[fd00::2]:80"}},
+			msg:      "ipv6",
 		},
 	}
+
 	for _, test := range tests {
-		allErrs := validateSessionCookie(test.sc, field.NewPath("sessionCookie"))
+		allErrs := validateUpstreamDown(test.upstream, field.NewPath("down"))
 		if len(allErrs) != 0 {
-			t.Errorf("validateSessionCookie() returned errors %v for valid input for the case of: %s", allErrs, test.msg)
+			t.Errorf("validateUpstreamDown() returned errors %v for valid input for the case of %s", allErrs, test.msg)
 		}
 	}
 }
 
-func TestValidateSessionCookieFails(t *testing.T) {
+func TestValidateUpstreamDownFails(t *testing.T) {
 	tests := []struct {
-		sc  *v1.SessionCookie
-		msg string
+		upstream v1.Upstream
+		msg      string
 	}{
 		{
-			sc:  &v1.SessionCookie{Enable: true},
-			msg: "missing required field: Name",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Down: []string{"tea-svc"}},
+			msg:      "hostname",
 		},
 		{
-			sc:  &v1.SessionCookie{Enable: false},
-			msg: "session cookie not enabled",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Down: []string{"10.0.0.1:http"}},
+			msg:      "non-numeric port",
 		},
 		{
-			sc:  &v1.SessionCookie{Enable: true, Name: "$ecret-Name"},
-			msg: "invalid name format",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Down: []string{"10.0.0.1:0"}},
+			msg:      "invalid port",
 		},
 		{
-			sc:  &v1.SessionCookie{Enable: true, Name: "test", Expires: "EGGS"},
-			msg: "invalid time format",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Down: []string{"10.0.0.1", "10.0.0.1"}},
+			msg:      "duplicate address",
 		},
 		{
-			sc:  &v1.SessionCookie{Enable: true, Name: "test", Path: "/ coffee"},
-			msg: "invalid path format",
+			upstream: v1.Upstream{Service: "tea-svc", Port: 80, Down: []string{"10.0.0.1"}, SRV: &v1.UpstreamSRV{Host: "tea-svc.default.svc.cluster.local", Service: "http"}},
+			msg:      "down with srv",
 		},
 	}
+
 	for _, test := range tests {
-		allErrs := validateSessionCookie(test.sc, field.NewPath("sessionCookie"))
+		allErrs := validateUpstreamDown(test.upstream, field.NewPath("down"))
 		if len(allErrs) == 0 {
-			t.Errorf("validateSessionCookie() returned no errors for invalid input for the case of: %v", test.msg)
+			t.Errorf("validateUpstreamDown() returned no errors for invalid input for the case of %s", test.msg)
 		}
 	}
 }
@@ -2831,6 +5453,23 @@ func TestValidateActionReturn(t *testing.T) {
 			Type: "application/json",
 			Body: "Hello World",
 		},
+		{
+			Code: 503,
+			Body: "Under maintenance",
+			Headers: []v1.Header{
+				{
+					Name:  "Retry-After",
+					Value: "3600",
+				},
+				{
+					Name:  "Cache-Control",
+					Value: `no-store, \"private\"`,
+				},
+			},
+		},
+		{
+			Body: strings.Repeat("a", maxActionReturnBodyLength),
+		},
 	}
 
 	for _, test := range tests {
@@ -2853,6 +5492,39 @@ func TestValidateActionReturnFails(t *testing.T) {
 			Type: `application/"json"`,
 			Body: "Hello World",
 		},
+		{
+			Code: 100,
+			Body: "Hello World",
+		},
+		{
+			Body: strings.Repeat("a", maxActionReturnBodyLength+1),
+		},
+		{
+			Body: "Hello World",
+			Headers: []v1.Header{
+				{
+					Name:  "Retry After",
+					Value: "3600",
+				},
+			},
+		},
+		{
+			Body: "Hello World",
+			Headers: []v1.Header{
+				{
+					Name:  "X-Upstream-Status",
+					Value: "${upstream_status}",
+				},
+			},
+		},
+		{
+			Body: "Hello World",
+			Headers: []v1.Header{
+				{
+					Value: "3600",
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -2936,6 +5608,95 @@ func TestValidateActionProxyRewritePathForRegexpFails(t *testing.T) {
 	}
 }
 
+func TestValidateActionProxyHostHeader(t *testing.T) {
+	tests := []*v1.ProxyHostHeader{
+		nil,
+		{},
+		{Mode: "preserve"},
+		{Mode: "upstream"},
+		{Mode: "literal", Value: "cafe.example.com"},
+		{Mode: "literal", Value: "cafe.example.com:8080"},
+		{Mode: "literal", Value: "10.0.0.1"},
+	}
+
+	for _, test := range tests {
+		allErrs := validateActionProxyHostHeader(test, field.NewPath("hostHeader"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateActionProxyHostHeader(%+v) returned errors for valid input: %v", test, allErrs)
+		}
+	}
+}
+
+func TestValidateActionProxyHostHeaderFails(t *testing.T) {
+	tests := []struct {
+		hostHeader *v1.ProxyHostHeader
+		msg        string
+	}{
+		{
+			hostHeader: &v1.ProxyHostHeader{Mode: "client"},
+			msg:        "invalid mode",
+		},
+		{
+			hostHeader: &v1.ProxyHostHeader{Mode: "upstream", Value: "cafe.example.com"},
+			msg:        "value with upstream mode",
+		},
+		{
+			hostHeader: &v1.ProxyHostHeader{Mode: "literal"},
+			msg:        "missing value with literal mode",
+		},
+		{
+			hostHeader: &v1.ProxyHostHeader{Mode: "literal", Value: "cafe_example.com"},
+			msg:        "invalid host",
+		},
+		{
+			hostHeader: &v1.ProxyHostHeader{Mode: "literal", Value: "cafe.example.com:99999"},
+			msg:        "invalid port",
+		},
+		{
+			hostHeader: &v1.ProxyHostHeader{Mode: "literal", Value: "$host"},
+			msg:        "variable",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateActionProxyHostHeader(test.hostHeader, field.NewPath("hostHeader"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateActionProxyHostHeader() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateActionProxySSLName(t *testing.T) {
+	tests := []string{
+		"",
+		"tea",
+		"tea.example.com",
+	}
+
+	for _, test := range tests {
+		allErrs := validateActionProxySSLName(test, field.NewPath("proxySslName"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateActionProxySSLName(%q) returned errors for valid input: %v", test, allErrs)
+		}
+	}
+}
+
+func TestValidateActionProxySSLNameFails(t *testing.T) {
+	tests := []string{
+		"tea_example.com",
+		"tea.example.com:443",
+		"*.example.com",
+		"$host",
+	}
+
+	for _, test := range tests {
+		allErrs := validateActionProxySSLName(test, field.NewPath("proxySslName"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateActionProxySSLName(%q) returned no errors for invalid input", test)
+		}
+	}
+}
+
 func TestValidateActionProxyRequestHeaders(t *testing.T) {
 	requestHeaders := &v1.ProxyRequestHeaders{
 		Set: []v1.Header{
@@ -3599,3 +6360,179 @@ func TestValidateErrorPageHeaderFails(t *testing.T) {
 		}
 	}
 }
+
+func TestValidatePolicyReferences(t *testing.T) {
+	validInput := [][]v1.PolicyReference{
+		nil,
+		{
+			{
+				Name: "policy-one",
+			},
+			{
+				Name:      "policy-two",
+				Namespace: "other-namespace",
+			},
+			{
+				Name:      "policy-one",
+				Namespace: "other-namespace",
+			},
+		},
+	}
+
+	for _, input := range validInput {
+		allErrs := validatePolicyReferences(input, field.NewPath("policies"))
+		if len(allErrs) > 0 {
+			t.Errorf("validatePolicyReferences(%+v) returned errors %v for valid input", input, allErrs)
+		}
+	}
+}
+
+func TestValidatePolicyReferencesFails(t *testing.T) {
+	tests := []struct {
+		policies []v1.PolicyReference
+		msg      string
+	}{
+		{
+			policies: []v1.PolicyReference{
+				{
+					Namespace: "default",
+				},
+			},
+			msg: "missing name",
+		},
+		{
+			policies: []v1.PolicyReference{
+				{
+					Name: "policy_one",
+				},
+			},
+			msg: "invalid name",
+		},
+		{
+			policies: []v1.PolicyReference{
+				{
+					Name:      "policy-one",
+					Namespace: "default.namespace",
+				},
+			},
+			msg: "invalid namespace",
+		},
+		{
+			policies: []v1.PolicyReference{
+				{
+					Name: "policy-one",
+				},
+				{
+					Name: "policy-one",
+				},
+			},
+			msg: "duplicated policies",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validatePolicyReferences(test.policies, field.NewPath("policies"))
+		if len(allErrs) == 0 {
+			t.Errorf("validatePolicyReferences() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateMirror(t *testing.T) {
+	upstreamNames := sets.NewString("tea-shadow")
+
+	validInput := []*v1.Mirror{
+		nil,
+		{
+			Upstream: "tea-shadow",
+		},
+		{
+			Upstream:   "tea-shadow",
+			Percentage: createPointerFromInt(10),
+		},
+		{
+			Upstream:   "tea-shadow",
+			Percentage: createPointerFromInt(100),
+		},
+	}
+
+	for _, input := range validInput {
+		allErrs := validateMirror(input, field.NewPath("mirror"), upstreamNames)
+		if len(allErrs) > 0 {
+			t.Errorf("validateMirror(%+v) returned errors %v for valid input", input, allErrs)
+		}
+	}
+}
+
+func TestValidateMirrorFails(t *testing.T) {
+	upstreamNames := sets.NewString("tea-shadow")
+
+	tests := []struct {
+		mirror *v1.Mirror
+		msg    string
+	}{
+		{
+			mirror: &v1.Mirror{},
+			msg:    "missing upstream",
+		},
+		{
+			mirror: &v1.Mirror{
+				Upstream: "coffee-shadow",
+			},
+			msg: "non-existing upstream",
+		},
+		{
+			mirror: &v1.Mirror{
+				Upstream:   "tea-shadow",
+				Percentage: createPointerFromInt(0),
+			},
+			msg: "zero percentage",
+		},
+		{
+			mirror: &v1.Mirror{
+				Upstream:   "tea-shadow",
+				Percentage: createPointerFromInt(101),
+			},
+			msg: "percentage over 100",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateMirror(test.mirror, field.NewPath("mirror"), upstreamNames)
+		if len(allErrs) == 0 {
+			t.Errorf("validateMirror() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateSecretReference(t *testing.T) {
+	validInput := []string{
+		"",
+		"tls-secret",
+		"shared-secrets/tls-secret",
+	}
+
+	for _, input := range validInput {
+		allErrs := validateSecretReference(input, field.NewPath("secret"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateSecretReference(%q) returned errors %v for valid input", input, allErrs)
+		}
+	}
+}
+
+func TestValidateSecretReferenceFails(t *testing.T) {
+	invalidInput := []string{
+		"tls_secret",
+		"shared-secrets/",
+		"/tls-secret",
+		"shared.secrets/tls-secret",
+		"shared-secrets/tls-secret/extra",
+	}
+
+	for _, input := range invalidInput {
+		allErrs := validateSecretReference(input, field.NewPath("secret"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateSecretReference(%q) returned no errors for invalid input", input)
+		}
+	}
+}
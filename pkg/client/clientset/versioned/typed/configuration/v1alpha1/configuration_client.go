@@ -11,6 +11,7 @@ import (
 type K8sV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	GlobalConfigurationsGetter
+	PoliciesGetter
 	TransportServersGetter
 }
 
@@ -23,6 +24,10 @@ func (c *K8sV1alpha1Client) GlobalConfigurations(namespace string) GlobalConfigu
 	return newGlobalConfigurations(c, namespace)
 }
 
+func (c *K8sV1alpha1Client) Policies(namespace string) PolicyInterface {
+	return newPolicies(c, namespace)
+}
+
 func (c *K8sV1alpha1Client) TransportServers(namespace string) TransportServerInterface {
 	return newTransportServers(c, namespace)
 }
@@ -10,6 +10,8 @@ import (
 type Interface interface {
 	// GlobalConfigurations returns a GlobalConfigurationInformer.
 	GlobalConfigurations() GlobalConfigurationInformer
+	// Policies returns a PolicyInformer.
+	Policies() PolicyInformer
 	// TransportServers returns a TransportServerInformer.
 	TransportServers() TransportServerInformer
 }
@@ -30,6 +32,11 @@ func (v *version) GlobalConfigurations() GlobalConfigurationInformer {
 	return &globalConfigurationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// Policies returns a PolicyInformer.
+func (v *version) Policies() PolicyInformer {
+	return &policyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // TransportServers returns a TransportServerInformer.
 func (v *version) TransportServers() TransportServerInformer {
 	return &transportServerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}